@@ -0,0 +1,67 @@
+"""
+Conditional GET (ETag / If-None-Match).
+
+Dashboards poll store lists, file lists and usage stats every few seconds.
+Their ETags are derived from version counters rather than the response
+body, so a match is answered with 304 before anything is computed:
+
+- store metadata and usage: ``AdminStore.get_version("stores")``, bumped on
+  every store create/update/delete and recorded search;
+- a store's indexed files: the query cache generation of the store (and the
+  global one), bumped on every index write or delete.
+
+ETags are weak (``W/"..."``): equal ETags mean the same data, not the same
+bytes (key order, compression). Responses carry ``Cache-Control: no-cache``
+so browsers revalidate on each poll instead of serving a stale copy. When a
+counter can't be read (Redis down) no ETag is sent and the full response is
+served.
+"""
+
+import hashlib
+import json
+from typing import Any, Optional
+
+from fastapi import Response
+
+ETAG_HEADER = "ETag"
+
+
+def make_etag(*parts: Any) -> Optional[str]:
+    """Weak ETag over version parts (None if any part is unknown)."""
+    if any(part is None for part in parts):
+        return None
+    digest = hashlib.sha1(json.dumps(parts, sort_keys=True, default=str).encode()).hexdigest()
+    return f'W/"{digest[:20]}"'
+
+
+def _opaque(tag: str) -> str:
+    tag = tag.strip()
+    return tag[2:] if tag.startswith("W/") else tag
+
+
+def etag_matches(if_none_match: Optional[str], etag: str) -> bool:
+    """Weak comparison against an ``If-None-Match`` list (RFC 9110 13.1.2)."""
+    if not if_none_match:
+        return False
+    if if_none_match.strip() == "*":
+        return True
+    return any(_opaque(tag) == _opaque(etag) for tag in if_none_match.split(",") if tag.strip())
+
+
+def conditional(response: Response, if_none_match: Optional[str], *parts: Any) -> Optional[Response]:
+    """
+    Tag ``response`` with the ETag of ``parts``.
+
+    Returns:
+        A 304 response to return instead when the client's copy is current,
+        otherwise None (build and return the full response as usual)
+    """
+    etag = make_etag(*parts)
+    if etag is None:
+        return None
+    headers = {ETAG_HEADER: etag, "Cache-Control": "no-cache"}
+    if etag_matches(if_none_match, etag):
+        return Response(status_code=304, headers=headers)
+    for name, value in headers.items():
+        response.headers[name] = value
+    return None
@@ -0,0 +1,205 @@
+"""
+Idempotency Keys.
+
+A client that times out on an upload or delete can't tell whether the
+server acted, and retrying blindly queues the file twice (or turns a
+successful delete into a 404). Index and delete endpoints therefore accept
+an ``Idempotency-Key`` header (any client-chosen token, typically a UUID
+per logical operation):
+
+- The first request with a key runs normally; its successful response is
+  kept in Redis for ``server.idempotency.ttl_seconds``.
+- A repeat with the same key and the same request gets the stored response
+  again, with ``Idempotent-Replayed: true``, without running the operation.
+- A repeat while the first is still running gets 409
+  (``idempotency_in_progress``); a key reused for a different request
+  (other parameters or file content) gets 422 (``idempotency_key_reused``).
+- Failed requests are not kept, so the client can retry them with the
+  same key.
+
+Keys are scoped to the caller and the operation. Without the header, or
+when Redis is unreachable, requests run as before.
+"""
+
+import asyncio
+import functools
+import hashlib
+import json
+import logging
+from typing import Any, Dict, Optional, Tuple
+
+import redis
+from fastapi import UploadFile
+from pydantic import BaseModel
+
+from src.core.config import settings
+from src.core.errors import AppError
+
+logger = logging.getLogger(__name__)
+
+IDEMPOTENCY_HEADER = "Idempotency-Key"
+REPLAYED_HEADER = "Idempotent-Replayed"
+
+IN_PROGRESS = "idempotency_in_progress"
+KEY_REUSED = "idempotency_key_reused"
+
+PENDING = "pending"
+DONE = "done"
+
+MAX_KEY_LENGTH = 255
+
+
+class IdempotencyStore:
+    """Claims on idempotency keys and the responses they produced."""
+
+    PREFIX = "rice:idempotency"
+
+    def __init__(self, redis_client=None):
+        self._redis = redis_client
+
+    @property
+    def redis(self) -> redis.Redis:
+        """Lazy Redis connection."""
+        if self._redis is None:
+            self._redis = redis.from_url(settings.REDIS_URL, decode_responses=True)
+        return self._redis
+
+    def _key(self, scope: str, key: str) -> str:
+        return f"{self.PREFIX}:{scope}:{key}"
+
+    def claim(self, scope: str, key: str, fingerprint: str) -> Tuple[bool, Optional[Dict[str, Any]]]:
+        """
+        Claim a key for a request.
+
+        Returns:
+            (True, None) when the caller should run the request, or
+            (False, record) with the record already holding the key
+        """
+        record = {"state": PENDING, "fingerprint": fingerprint}
+        # A claim left by a crashed request expires on its own
+        pending_ttl = int(settings.get("server.idempotency.pending_seconds", 300))
+        if self.redis.set(self._key(scope, key), json.dumps(record), nx=True, ex=pending_ttl):
+            return True, None
+        existing = self.redis.get(self._key(scope, key))
+        if existing is None:
+            # Expired between the two calls; claim again
+            return self.claim(scope, key, fingerprint)
+        return False, json.loads(existing)
+
+    def complete(self, scope: str, key: str, fingerprint: str, body: Any):
+        record = {"state": DONE, "fingerprint": fingerprint, "body": body}
+        ttl = int(settings.get("server.idempotency.ttl_seconds", 86400))
+        self.redis.set(self._key(scope, key), json.dumps(record, default=str), ex=ttl)
+
+    def release(self, scope: str, key: str):
+        self.redis.delete(self._key(scope, key))
+
+
+def _digest_upload(upload: UploadFile) -> str:
+    digest = hashlib.sha256()
+    upload.file.seek(0)
+    while block := upload.file.read(1024 * 1024):
+        digest.update(block)
+    upload.file.seek(0)
+    return f"{upload.filename}:{digest.hexdigest()}"
+
+
+def _fingerprint_value(value: Any) -> Any:
+    if isinstance(value, UploadFile):
+        return _digest_upload(value)
+    if isinstance(value, (list, tuple)):
+        return [_fingerprint_value(v) for v in value]
+    if isinstance(value, BaseModel):
+        return value.model_dump()
+    return value
+
+
+def request_fingerprint(operation: str, params: Dict[str, Any]) -> str:
+    """Hash of an operation's parameters (uploaded files by content)."""
+    values = {name: _fingerprint_value(value) for name, value in sorted(params.items())}
+    return hashlib.sha256(json.dumps([operation, values], sort_keys=True, default=str).encode()).hexdigest()
+
+
+def _caller(user: Optional[dict]) -> str:
+    user = user or {}
+    return str(user.get("sub") or user.get("id") or "anonymous")
+
+
+# Handler parameters that identify the caller or carry the response, not the request
+_NOT_FINGERPRINTED = {"user", "admin", "response", "idempotency_key", "x_connection_token"}
+
+
+def idempotent(operation: str):
+    """
+    Make an endpoint honour ``Idempotency-Key``.
+
+    The handler must declare ``idempotency_key: Optional[str] =
+    Header(None)`` and ``response: Response`` (and its caller as ``user``
+    or ``admin``); its other parameters form the request fingerprint.
+    Only dict and list results are stored; Response objects are not.
+    """
+    def decorator(handler):
+        @functools.wraps(handler)
+        async def wrapper(*args, **kwargs):
+            key = kwargs.get("idempotency_key")
+            if not key or not settings.get("server.idempotency.enabled", True):
+                return await handler(*args, **kwargs)
+            if len(key) > MAX_KEY_LENGTH:
+                raise AppError(400, f"{IDEMPOTENCY_HEADER} is longer than {MAX_KEY_LENGTH} characters")
+
+            params = {k: v for k, v in kwargs.items() if k not in _NOT_FINGERPRINTED}
+            fingerprint = await asyncio.to_thread(request_fingerprint, operation, params)
+            scope = f"{_caller(kwargs.get('user') or kwargs.get('admin'))}:{operation}"
+            store = get_idempotency_store()
+            try:
+                claimed, record = await asyncio.to_thread(store.claim, scope, key, fingerprint)
+            except Exception as e:
+                logger.warning(f"Idempotency check for {operation} failed, running without: {e}")
+                return await handler(*args, **kwargs)
+
+            if not claimed:
+                if record.get("fingerprint") != fingerprint:
+                    raise AppError(422, f"{IDEMPOTENCY_HEADER} was already used for a different request", KEY_REUSED)
+                if record.get("state") != DONE:
+                    raise AppError(409, "A request with this Idempotency-Key is still in progress", IN_PROGRESS)
+                response = kwargs.get("response")
+                if response is not None:
+                    response.headers[REPLAYED_HEADER] = "true"
+                return record.get("body")
+
+            try:
+                result = await handler(*args, **kwargs)
+            except BaseException:
+                await asyncio.to_thread(_release, store, scope, key)
+                raise
+            if not isinstance(result, (dict, list)):
+                # A Response object: nothing to replay
+                await asyncio.to_thread(_release, store, scope, key)
+                return result
+            try:
+                await asyncio.to_thread(store.complete, scope, key, fingerprint, result)
+            except Exception as e:
+                logger.warning(f"Could not keep {operation} result for its idempotency key: {e}")
+                await asyncio.to_thread(_release, store, scope, key)
+            return result
+
+        return wrapper
+    return decorator
+
+
+def _release(store: IdempotencyStore, scope: str, key: str):
+    try:
+        store.release(scope, key)
+    except Exception as e:
+        logger.warning(f"Could not release idempotency key {key}: {e}")
+
+
+# Singleton instance
+_idempotency_store: Optional[IdempotencyStore] = None
+
+def get_idempotency_store() -> IdempotencyStore:
+    """Get global idempotency store instance."""
+    global _idempotency_store
+    if _idempotency_store is None:
+        _idempotency_store = IdempotencyStore()
+    return _idempotency_store
@@ -0,0 +1,216 @@
+"""
+OpenAPI Document.
+
+FastAPI derives the spec from the routers; this module adds what it can't
+see and serves it:
+
+- ``GET /api/v1/openapi.json``: the OpenAPI 3 document, versioned with the
+  API revision (``Rice-Api-Version``), with the auth schemes the API accepts
+  (bearer token, or ``X-User-ID`` when auth is off) and the error envelope
+  (``ErrorResponse``, see ``src/core/errors.py``) as every operation's
+  default response
+- ``GET /api/v1/docs``: Swagger UI for that document
+
+Handlers that return plain dicts document their body with
+``responses={200: {"model": ...}}`` so the spec describes them without
+FastAPI filtering the response through the model.
+
+``validate_response`` checks a response body against the document; the
+contract tests use it to catch handlers drifting from their schema.
+"""
+
+import re
+from typing import Any, Dict, List, Optional
+
+from src.core.config import settings
+
+ERROR_SCHEMA = {
+    "title": "ErrorResponse",
+    "type": "object",
+    "properties": {
+        "detail": {"title": "Detail", "description": "Error message (or validation errors)"},
+        "code": {"title": "Code", "type": "string", "description": "Stable machine-readable error code"},
+        "request_id": {
+            "title": "Request Id",
+            "anyOf": [{"type": "string"}, {"type": "null"}],
+            "description": "Also sent as X-Request-ID; quoted in the server log",
+        },
+        "error_id": {"title": "Error Id", "type": "string", "description": "Set on 500s; quoted in the server log"},
+    },
+    "required": ["detail", "code"],
+}
+
+SECURITY_SCHEMES = {
+    "bearerAuth": {
+        "type": "http",
+        "scheme": "bearer",
+        "description": "JWT or API key (server.auth enabled)",
+    },
+    "userId": {
+        "type": "apiKey",
+        "in": "header",
+        "name": "X-User-ID",
+        "description": "Caller identity when auth is disabled",
+    },
+}
+
+
+def build_openapi(app) -> Dict[str, Any]:
+    """The app's OpenAPI document, generated once and cached on the app."""
+    if app.openapi_schema:
+        return app.openapi_schema
+
+    from fastapi.openapi.utils import get_openapi
+    from src.api.versioning import VERSION_HEADER, current_version
+
+    spec = get_openapi(
+        title=app.title,
+        version=current_version(),
+        description=(
+            f"Rice Search REST API. `info.version` is the API revision served; "
+            f"pin yours with the `{VERSION_HEADER}` request header "
+            f"(see `GET {settings.API_V1_STR}/changes`)."
+        ),
+        routes=app.routes,
+    )
+    components = spec.setdefault("components", {})
+    components.setdefault("schemas", {})["ErrorResponse"] = ERROR_SCHEMA
+    components["securitySchemes"] = SECURITY_SCHEMES
+    # Either scheme, or none for public endpoints
+    spec["security"] = [{"bearerAuth": []}, {"userId": []}, {}]
+
+    for operations in spec.get("paths", {}).values():
+        for operation in operations.values():
+            operation.setdefault("responses", {}).setdefault("default", {
+                "description": "Error",
+                "content": {"application/json": {"schema": {"$ref": "#/components/schemas/ErrorResponse"}}},
+            })
+
+    app.openapi_schema = spec
+    return spec
+
+
+def install_openapi(app):
+    """Serve the extended document and a Swagger UI page under the v1 prefix."""
+    from fastapi.openapi.docs import get_swagger_ui_html
+
+    app.openapi = lambda: build_openapi(app)
+
+    @app.get(f"{settings.API_V1_STR}/docs", include_in_schema=False)
+    def swagger_ui():
+        return get_swagger_ui_html(openapi_url=app.openapi_url, title=f"{app.title} - API")
+
+
+# ============== Response validation ==============
+
+def _path_pattern(template: str) -> re.Pattern:
+    parts = re.split(r"(\{[^}]+\})", template)
+    return re.compile("^" + "".join(
+        "[^/]+" if part.startswith("{") else re.escape(part) for part in parts
+    ) + "$")
+
+
+def find_operation(spec: Dict[str, Any], method: str, path: str) -> Optional[Dict[str, Any]]:
+    """The operation serving a concrete request path (``/api/v1/stores/abc``)."""
+    paths = spec.get("paths", {})
+    operations = paths.get(path)
+    if operations is None:
+        for template, candidate in paths.items():
+            if "{" in template and _path_pattern(template).match(path):
+                operations = candidate
+                break
+    return (operations or {}).get(method.lower())
+
+
+def response_schema(spec: Dict[str, Any], method: str, path: str, status: int) -> Optional[Dict[str, Any]]:
+    """JSON schema documented for a response, or None when the spec has none."""
+    operation = find_operation(spec, method, path)
+    if operation is None:
+        return None
+    responses = operation.get("responses", {})
+    response = (
+        responses.get(str(status))
+        or responses.get(f"{str(status)[0]}XX")
+        or responses.get("default")
+    )
+    content = (response or {}).get("content", {}).get("application/json")
+    return (content or {}).get("schema")
+
+
+def _resolve(spec: Dict[str, Any], schema: Dict[str, Any]) -> Dict[str, Any]:
+    while "$ref" in schema:
+        node: Any = spec
+        for part in schema["$ref"].lstrip("#/").split("/"):
+            node = node[part]
+        schema = node
+    return schema
+
+
+_TYPES = {
+    "object": lambda v: isinstance(v, dict),
+    "array": lambda v: isinstance(v, list),
+    "string": lambda v: isinstance(v, str),
+    "integer": lambda v: isinstance(v, int) and not isinstance(v, bool),
+    "number": lambda v: isinstance(v, (int, float)) and not isinstance(v, bool),
+    "boolean": lambda v: isinstance(v, bool),
+    "null": lambda v: v is None,
+}
+
+
+def validate(spec: Dict[str, Any], schema: Dict[str, Any], value: Any, where: str = "$") -> List[str]:
+    """
+    Errors validating ``value`` against ``schema`` (``$ref`` resolved in
+    ``spec``): the JSON Schema subset FastAPI emits - type, enum, const,
+    properties/required/additionalProperties, items, anyOf/oneOf/allOf.
+    """
+    schema = _resolve(spec, schema)
+    errors: List[str] = []
+
+    for sub in schema.get("allOf", []):
+        errors += validate(spec, sub, value, where)
+    for key in ("anyOf", "oneOf"):
+        if key in schema and not any(not validate(spec, sub, value, where) for sub in schema[key]):
+            errors.append(f"{where}: matches no schema in {key}")
+    if errors:
+        return errors
+
+    expected = schema.get("type")
+    if expected:
+        types = expected if isinstance(expected, list) else [expected]
+        if schema.get("nullable"):
+            types = types + ["null"]
+        if not any(_TYPES[t](value) for t in types if t in _TYPES):
+            return [f"{where}: expected {'/'.join(types)}, got {type(value).__name__}"]
+    if "enum" in schema and value not in schema["enum"]:
+        errors.append(f"{where}: {value!r} not in {schema['enum']}")
+    if "const" in schema and value != schema["const"]:
+        errors.append(f"{where}: expected {schema['const']!r}")
+
+    if isinstance(value, dict):
+        properties = schema.get("properties", {})
+        for name in schema.get("required", []):
+            if name not in value:
+                errors.append(f"{where}: missing required '{name}'")
+        extra = schema.get("additionalProperties", True)
+        for name, item in value.items():
+            if name in properties:
+                errors += validate(spec, properties[name], item, f"{where}.{name}")
+            elif extra is False:
+                errors.append(f"{where}: unexpected property '{name}'")
+            elif isinstance(extra, dict):
+                errors += validate(spec, extra, item, f"{where}.{name}")
+    if isinstance(value, list) and isinstance(schema.get("items"), dict):
+        for i, item in enumerate(value):
+            errors += validate(spec, schema["items"], item, f"{where}[{i}]")
+    return errors
+
+
+def validate_response(spec: Dict[str, Any], method: str, path: str, status: int, body: Any) -> List[str]:
+    """
+    Errors validating a response body against the spec; undocumented
+    operations are an error themselves, undocumented bodies are not.
+    """
+    if find_operation(spec, method, path) is None:
+        return [f"{method.upper()} {path} is not in the OpenAPI document"]
+    schema = response_schema(spec, method, path, status)
+    return validate(spec, schema, body) if schema else []
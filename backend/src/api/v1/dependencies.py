@@ -58,3 +58,96 @@ async def verify_admin(user: dict = Depends(get_current_user)):
          #    raise HTTPException(status_code=403, detail="Not authorized")
          pass 
     return user
+
+
+def is_admin(user: dict) -> bool:
+    return "admin" in user.get("realm_access", {}).get("roles", [])
+
+
+def user_key(user: dict) -> Optional[str]:
+    """Caller's identity: the token subject, or the X-User-ID user's id."""
+    return user.get("sub") or user.get("id")
+
+
+def authorize_connection(
+    user: dict,
+    connection_id: Optional[str],
+    token: Optional[str],
+    admin_override: bool = False
+):
+    """
+    Check a caller may index/delete as a CLI connection.
+
+    A claimed ``connection_id`` must come with its token (X-Connection-Token).
+    With ``auth.require_connection_token`` on, non-admins must claim one.
+    ``admin_override`` (admins only) lifts per-connection ownership checks.
+    """
+    from src.core.config import settings
+    from src.services.admin.admin_store import get_admin_store
+
+    if admin_override and not is_admin(user):
+        raise HTTPException(status_code=status.HTTP_403_FORBIDDEN, detail="admin_override requires the admin role")
+
+    if not connection_id:
+        if settings.get("auth.require_connection_token", False) and not is_admin(user):
+            raise HTTPException(
+                status_code=status.HTTP_401_UNAUTHORIZED,
+                detail="connection_id and X-Connection-Token are required"
+            )
+        return
+
+    store = get_admin_store()
+    caller = user.get("sub", "unknown")
+    connection = store.get_connections().get(connection_id)
+    if connection is None:
+        store.raise_alert("warning", f"connections.{connection_id}", f"Request from {caller} for unknown connection")
+        raise HTTPException(status_code=status.HTTP_403_FORBIDDEN, detail=f"Unknown connection: {connection_id}")
+    if connection.get("enabled") is False:
+        raise HTTPException(status_code=status.HTTP_403_FORBIDDEN, detail=f"Connection disabled: {connection_id}")
+    if not connection.get("token_hash"):
+        # Registered before tokens: the CLI claims its first token once
+        raise HTTPException(
+            status_code=status.HTTP_401_UNAUTHORIZED,
+            detail=f"Connection {connection_id} has no token yet; claim one with "
+                   f"POST /api/v1/admin/public/connections/{connection_id}/claim"
+        )
+    if not store.verify_connection_token(connection_id, token):
+        store.raise_alert("warning", f"connections.{connection_id}", f"Invalid or missing connection token from {caller}")
+        raise HTTPException(status_code=status.HTTP_401_UNAUTHORIZED, detail="Invalid or missing connection token")
+
+
+STORE_ACCESS_VERBS = {"read": "read", "write": "write to", "owner": "manage"}
+
+
+def authorize_store(user: dict, store_id: str, access: str, connection_id: Optional[str] = None):
+    """
+    Check the store's ACL lets the caller ``access`` it ("read", "write"
+    or "owner"); stores without an ACL are open. Stores in the trash are
+    404 for everyone until restored.
+
+    ``connection_id`` counts as a principal and must already be verified
+    (``authorize_connection`` or ``verified_connection``).
+    """
+    from src.services.admin.admin_store import get_admin_store
+    from src.services.admin.store_acl import can_access
+
+    if store_id in get_admin_store().get_trashed_stores():
+        raise HTTPException(status_code=status.HTTP_404_NOT_FOUND, detail=f"Store '{store_id}' is in the trash")
+    if not can_access(user, store_id, access, connection_id):
+        raise HTTPException(
+            status_code=status.HTTP_403_FORBIDDEN,
+            detail=f"Not allowed to {STORE_ACCESS_VERBS.get(access, access)} store '{store_id}'"
+        )
+
+
+def verified_connection(connection_id: Optional[str], token: Optional[str]) -> Optional[str]:
+    """``connection_id`` if ``token`` proves it, else None (no error)."""
+    if not connection_id or not token:
+        return None
+    from src.services.admin.admin_store import get_admin_store
+    try:
+        if get_admin_store().verify_connection_token(connection_id, token):
+            return connection_id
+    except Exception:
+        pass
+    return None
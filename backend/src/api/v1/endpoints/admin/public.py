@@ -4,7 +4,9 @@ Public Admin endpoints with Redis persistence.
 All state is persisted to Redis and survives restarts.
 """
 
-from fastapi import APIRouter, HTTPException, Depends
+import asyncio
+
+from fastapi import APIRouter, HTTPException, Depends, Request
 from pydantic import BaseModel
 from typing import Optional, List, Literal
 from uuid import uuid4
@@ -14,6 +16,8 @@ from datetime import datetime
 
 from src.core.config import settings
 from src.services.admin.admin_store import get_admin_store
+from src.services.inference.watchdog import get_inference_watchdog
+from src.services.search.query_cache import get_query_cache
 from src.api.deps import requires_role, get_current_user
 
 logger = logging.getLogger(__name__)
@@ -23,13 +27,30 @@ router = APIRouter()
 class ConnectionRegister(BaseModel):
     """CLI connection registration."""
     user_id: str
-    device_name: str
+    device_name: Optional[str] = None
     version: str = "1.0.0"
+    # Machine the CLI runs on
+    hostname: Optional[str] = None
+    os: Optional[str] = None
+    arch: Optional[str] = None
+    username: Optional[str] = None
 
 class ModelUpdate(BaseModel):
     """Model update request."""
     active: Optional[bool] = None
     gpu_enabled: Optional[bool] = None
+    precision: Optional[Literal["fp32", "fp16", "int8"]] = None
+
+
+class BenchmarkRequest(BaseModel):
+    """Precision benchmark request."""
+    precisions: Optional[List[Literal["fp32", "fp16", "int8"]]] = None
+    texts: Optional[List[str]] = None
+
+class ModelDownload(BaseModel):
+    """HuggingFace model download request."""
+    repo_id: str
+    revision: str = "main"
 
 class ConfigUpdate(BaseModel):
     """Configuration update request."""
@@ -85,6 +106,106 @@ async def list_models():
         return {"models": []}
 
 
+@router.get("/models/registry")
+async def list_model_registry():
+    """Configured models per type (embedding, sparse_embedding, reranker, ...)."""
+    store = get_admin_store()
+    return {"models": list(store.get_models().values())}
+
+
+@router.put("/models/{model_id}", dependencies=[Depends(requires_role("admin"))])
+async def update_model(model_id: str, update: ModelUpdate):
+    """Toggle a model's GPU use or active flag, or change its precision."""
+    from src.services.inference.quantization import MODEL_KINDS, reload_model
+
+    store = get_admin_store()
+    model = store.get_models().get(model_id)
+    if model is None:
+        raise HTTPException(status_code=404, detail="Model not found")
+    if update.active is not None:
+        model["active"] = update.active
+    if update.gpu_enabled is not None:
+        model["gpu_enabled"] = update.gpu_enabled
+    precision_changed = update.precision is not None and update.precision != model.get("precision")
+    if precision_changed:
+        if model.get("type") not in MODEL_KINDS:
+            raise HTTPException(
+                status_code=400,
+                detail=f"Precision applies to local models ({', '.join(sorted(MODEL_KINDS))}), not {model.get('type')}"
+            )
+        model["precision"] = update.precision
+    if not store.set_model(model_id, model):
+        raise HTTPException(status_code=500, detail="Failed to update model")
+    if precision_changed:
+        store.log_audit("model_precision", f"{model_id} precision set to {update.precision}", "admin")
+        try:
+            await asyncio.to_thread(reload_model, model["type"])
+        except Exception as e:
+            logger.error(f"Reloading {model_id} with {update.precision} failed: {e}")
+            raise HTTPException(status_code=500, detail=f"Precision saved but reload failed: {e}")
+    return {"model": model}
+
+
+@router.post("/models/{model_id}/benchmark", dependencies=[Depends(requires_role("admin"))])
+async def benchmark_model(model_id: str, request: Optional[BenchmarkRequest] = None):
+    """
+    Compare fp32/fp16/int8 latency and output quality of a local model.
+
+    Loads temporary copies of the model, so it takes a few seconds.
+    """
+    from src.services.inference.quantization import MODEL_KINDS, benchmark
+
+    model = get_admin_store().get_models().get(model_id)
+    if model is None:
+        raise HTTPException(status_code=404, detail="Model not found")
+    if model.get("type") not in MODEL_KINDS:
+        raise HTTPException(status_code=400, detail=f"No local precision variants for {model.get('type')} models")
+    request = request or BenchmarkRequest()
+    try:
+        result = await asyncio.to_thread(benchmark, model["type"], request.precisions, request.texts)
+    except Exception as e:
+        logger.error(f"Benchmark of {model_id} failed: {e}")
+        raise HTTPException(status_code=500, detail=f"Benchmark failed: {e}")
+    return {"model_id": model_id, **result}
+
+
+@router.post("/models/download", status_code=202, dependencies=[Depends(requires_role("admin"))])
+async def download_model(request: ModelDownload):
+    """
+    Download a HuggingFace model in the background (parallel, resumable,
+    checksum-verified). Track it with ``GET /models/downloads``.
+    """
+    from src.core.supervisor import ORDER_MODELS, get_supervisor
+    from src.services.inference.model_downloader import get_model_downloader
+
+    downloader = get_model_downloader()
+    current = next((p for p in downloader.status() if p["repo_id"] == request.repo_id), None)
+    if current and current["state"] == "downloading":
+        raise HTTPException(status_code=409, detail=f"{request.repo_id} is already downloading")
+    get_supervisor().spawn(
+        f"model-download:{request.repo_id}", downloader.download, request.repo_id, request.revision,
+        group="models", order=ORDER_MODELS,
+    )
+    get_admin_store().log_audit("model_download", f"Download of {request.repo_id}@{request.revision} started", "admin")
+    return {"status": "started", "repo_id": request.repo_id, "revision": request.revision}
+
+
+@router.get("/models/downloads")
+async def list_model_downloads():
+    """Progress of model downloads started by this API process."""
+    from src.services.inference.model_downloader import get_model_downloader
+    return {"downloads": get_model_downloader().status()}
+
+
+@router.post("/models/{model_id}/default", dependencies=[Depends(requires_role("admin"))])
+async def set_default_model(model_id: str):
+    """Make a model the active one for its type."""
+    store = get_admin_store()
+    if not store.set_default_model(model_id):
+        raise HTTPException(status_code=404, detail="Model not found")
+    return {"model": store.get_models()[model_id]}
+
+
 # ============== Config Endpoints ==============
 
 @router.get("/config")
@@ -276,32 +397,178 @@ async def delete_user(user_id: str):
 
 # ============== Connections Endpoints ==============
 
+def _public_connection(connection: dict) -> dict:
+    """Connection record without its token hash."""
+    return {k: v for k, v in connection.items() if k != "token_hash"}
+
 @router.get("/connections")
 async def list_connections():
     """List all active CLI connections."""
     store = get_admin_store()
     connections = store.get_connections()
-    return {"connections": list(connections.values())}
+    return {"connections": [_public_connection(c) for c in connections.values()]}
 
 @router.post("/connections/register", dependencies=[Depends(get_current_user)])
-async def register_connection(data: ConnectionRegister):
-    """Register a CLI connection."""
+async def register_connection(data: ConnectionRegister, request: Request):
+    """
+    Register a CLI connection.
+
+    CLIs register on first use and send the returned ID (and token) with
+    every upload and search.
+    """
     store = get_admin_store()
-    connection_id = f"conn-{uuid.uuid4().hex[:8]}"
+    connection_id = f"conn-{uuid4().hex[:8]}"
     
     connection = {
         "id": connection_id,
         "user_id": data.user_id,
-        "device_name": data.device_name,
+        "device_name": data.device_name or data.hostname or "unknown",
         "version": data.version,
+        "hostname": data.hostname,
+        "os": data.os,
+        "arch": data.arch,
+        "username": data.username,
         "last_seen": datetime.now().isoformat(),
-        "ip": "127.0.0.1" # Mock IP for now
+        "ip": request.client.host if request.client else None,
+        "indexed_files": 0,
+        "searches": 0,
+        "stores": []
     }
     
     store.set_connection(connection_id, connection)
     store.increment_counter("active_connections")
+    # Sent as X-Connection-Token on index/delete calls; not retrievable later
+    token = store.issue_connection_token(connection_id)
     
-    return {"message": "Connection registered", "connection": connection}
+    return {
+        "message": "Connection registered",
+        "connection": _public_connection(store.get_connections().get(connection_id, connection)),
+        "token": token
+    }
+
+@router.post("/connections/{connection_id}/claim")
+async def claim_connection_token(connection_id: str, user: dict = Depends(get_current_user)):
+    """
+    Issue the first token for a connection registered before connection
+    tokens existed, so its CLI keeps its ID and stats. Only the
+    connection's user or an admin may claim it, and only while it has no
+    token (later tokens come from the admin-only rotate endpoint).
+    """
+    store = get_admin_store()
+    connection = store.get_connections().get(connection_id)
+    if connection is None:
+        raise HTTPException(status_code=404, detail="Connection not found")
+    if connection.get("token_hash"):
+        raise HTTPException(status_code=409, detail="Connection already has a token; an admin can rotate it")
+    caller = user.get("id") or user.get("sub")
+    if user.get("role") != "admin" and caller != connection.get("user_id"):
+        raise HTTPException(status_code=403, detail="Only the connection's user or an admin can claim it")
+    token = store.issue_connection_token(connection_id)
+    if token is None:
+        raise HTTPException(status_code=500, detail="Failed to issue token")
+    store.log_audit("connection_token_claimed", f"First token for legacy connection {connection_id} issued to {caller}", caller)
+    return {"connection_id": connection_id, "token": token}
+
+@router.post("/connections/{connection_id}/token", dependencies=[Depends(requires_role("admin"))])
+async def rotate_connection_token(connection_id: str):
+    """Issue a new token for a connection (the old one stops working)."""
+    store = get_admin_store()
+    token = store.issue_connection_token(connection_id)
+    if token is None:
+        raise HTTPException(status_code=404, detail="Connection not found")
+    store.log_audit("connection_token_rotated", f"Token for connection {connection_id} rotated", "admin")
+    return {"connection_id": connection_id, "token": token}
+
+@router.post("/connections/backfill", dependencies=[Depends(requires_role("admin"))])
+async def backfill_connections(dry_run: bool = False):
+    """Rebuild connection file counts and stores from index payloads via Celery."""
+    from src.worker.celery_app import app as celery_app
+
+    store = get_admin_store()
+    store.log_audit("connections_backfill", f"Connection backfill triggered (dry_run={dry_run})", "admin")
+
+    task = celery_app.send_task(
+        "src.tasks.maintenance.backfill_connections_task",
+        kwargs={"dry_run": dry_run}
+    )
+    return {
+        "message": "Connection backfill triggered",
+        "status": "queued",
+        "task_id": str(task.id)
+    }
+
+@router.post("/languages/backfill", dependencies=[Depends(requires_role("admin"))])
+async def backfill_languages(org_id: Optional[str] = None, dry_run: bool = False):
+    """Detect the language of chunks indexed without one via Celery."""
+    from src.worker.celery_app import app as celery_app
+
+    store = get_admin_store()
+    store.log_audit(
+        "languages_backfill",
+        f"Language backfill triggered (store={org_id or 'all'}, dry_run={dry_run})",
+        "admin"
+    )
+
+    task = celery_app.send_task(
+        "src.tasks.maintenance.backfill_language_task",
+        kwargs={"org_id": org_id, "dry_run": dry_run}
+    )
+    return {
+        "message": "Language backfill triggered",
+        "status": "queued",
+        "task_id": str(task.id)
+    }
+
+@router.post("/stores/stats/rebuild", dependencies=[Depends(requires_role("admin"))])
+async def rebuild_store_stats(org_id: Optional[str] = None):
+    """Recount store file/chunk/byte/language stats from index payloads via Celery."""
+    from src.worker.celery_app import app as celery_app
+
+    store = get_admin_store()
+    store.log_audit("store_stats_rebuild", f"Store stats rebuild triggered (store={org_id or 'all'})", "admin")
+
+    task = celery_app.send_task(
+        "src.tasks.maintenance.rebuild_store_stats_task",
+        kwargs={"org_id": org_id}
+    )
+    return {
+        "message": "Store stats rebuild triggered",
+        "status": "queued",
+        "task_id": str(task.id)
+    }
+
+@router.delete("/connections/{connection_id}/chunks", dependencies=[Depends(requires_role("admin"))])
+async def delete_connection_chunks(connection_id: str, org_id: Optional[str] = None):
+    """Remove chunks a connection indexed (one store or all) via Celery."""
+    from src.worker.celery_app import app as celery_app
+
+    store = get_admin_store()
+    store.log_audit(
+        "connection_chunks_delete",
+        f"Delete of connection {connection_id} chunks from {org_id or 'all stores'} queued",
+        "admin"
+    )
+
+    task = celery_app.send_task(
+        "src.tasks.ingestion.delete_connection_chunks_task",
+        kwargs={"connection_id": connection_id, "org_id": org_id}
+    )
+    return {
+        "message": "Connection chunk cleanup triggered",
+        "status": "queued",
+        "task_id": str(task.id)
+    }
+
+class ConnectionEnabledUpdate(BaseModel):
+    enabled: bool
+
+@router.put("/connections/{connection_id}/enabled", dependencies=[Depends(requires_role("admin"))])
+async def set_connection_enabled(connection_id: str, update: ConnectionEnabledUpdate):
+    """Enable or disable a connection; disabled connections are refused on index/delete."""
+    store = get_admin_store()
+    if not store.set_connection_enabled(connection_id, update.enabled):
+        raise HTTPException(status_code=404, detail="Connection not found")
+    return {"connection": _public_connection(store.get_connections()[connection_id])}
 
 @router.delete("/connections/{connection_id}", dependencies=[Depends(requires_role("admin"))])
 async def delete_connection(connection_id: str):
@@ -313,6 +580,67 @@ async def delete_connection(connection_id: str):
     raise HTTPException(status_code=404, detail="Connection not found")
 
 
+# ============== Jobs Endpoints ==============
+
+def _job_entries(by_worker: Optional[dict], state: str) -> List[dict]:
+    jobs = []
+    for worker, tasks in (by_worker or {}).items():
+        for task in tasks:
+            # Scheduled (ETA) entries wrap the task in "request"
+            request = task.get("request", task)
+            jobs.append({
+                "id": request.get("id"),
+                "name": request.get("name"),
+                "state": state,
+                "worker": worker,
+                "args": request.get("args"),
+                "kwargs": request.get("kwargs"),
+                "started_at": request.get("time_start"),
+                "eta": task.get("eta"),
+            })
+    return jobs
+
+@router.get("/jobs", dependencies=[Depends(requires_role("admin"))])
+async def list_jobs():
+    """Celery tasks running, reserved or scheduled on the workers."""
+    from src.worker.celery_app import app as celery_app
+
+    inspect = celery_app.control.inspect(timeout=1.0)
+    try:
+        jobs = (
+            _job_entries(inspect.active(), "active")
+            + _job_entries(inspect.reserved(), "reserved")
+            + _job_entries(inspect.scheduled(), "scheduled")
+        )
+    except Exception as e:
+        logger.error(f"Failed to inspect workers: {e}")
+        raise HTTPException(status_code=503, detail="Workers unavailable")
+    return {"jobs": jobs}
+
+@router.get("/jobs/{task_id}", dependencies=[Depends(requires_role("admin"))])
+async def get_job(task_id: str):
+    """State and result of a Celery task (e.g. a task_id returned by a trigger endpoint)."""
+    from src.worker.celery_app import app as celery_app
+
+    result = celery_app.AsyncResult(task_id)
+    job = {"id": task_id, "state": result.state}
+    if result.ready():
+        job["result"] = result.result if result.successful() else str(result.result)
+    elif isinstance(result.info, dict):
+        # Progress reported by the task (step, current, total)
+        job["progress"] = result.info
+    return job
+
+@router.post("/jobs/{task_id}/cancel", dependencies=[Depends(requires_role("admin"))])
+async def cancel_job(task_id: str, terminate: bool = False):
+    """Revoke a task; ``terminate`` also kills it if already running."""
+    from src.worker.celery_app import app as celery_app
+
+    celery_app.control.revoke(task_id, terminate=terminate)
+    get_admin_store().log_audit("job_cancelled", f"Task {task_id} revoked (terminate={terminate})", "admin")
+    return {"id": task_id, "status": "revoked", "terminated": terminate}
+
+
 # ============== MCP Endpoints ==============
 
 @router.get("/mcp/status")
@@ -386,55 +714,50 @@ async def get_inference_status():
     return status
 
 
+# ============== Query Model Endpoints ==============
+
+class QueryModelUpdate(BaseModel):
+    enabled: bool
+
+
+@router.get("/query-model")
+async def get_query_model_status():
+    """
+    Query understanding model state: runtime switch, backend chain, last
+    warm-up, and how many queries each path (model/heuristic) served.
+    """
+    from src.services.search.query_analyzer import query_model_status
+    return query_model_status()
+
+
+@router.put("/query-model", dependencies=[Depends(requires_role("admin"))])
+async def set_query_model_enabled(update: QueryModelUpdate):
+    """Enable or disable the query model; disabled means heuristics only."""
+    store = get_admin_store()
+    if not store.set_config("query_model_enabled", update.enabled):
+        raise HTTPException(status_code=500, detail="Failed to update config")
+    return {"enabled": update.enabled}
+
+
+@router.post("/query-model/warmup", dependencies=[Depends(requires_role("admin"))])
+async def warm_up_query_model():
+    """Load the query model on its backend before queries need it."""
+    from src.services.search.query_analyzer import warm_up_query_model as warm_up
+    result = await warm_up()
+    if not result["ok"]:
+        raise HTTPException(status_code=503, detail="No query model backend answered")
+    return result
+
+
 # ============== System Endpoints ==============
 
 # Helper for consistent health checks
 def _get_component_health(store) -> dict:
-    components = {
-        "redis": "down",
-        "qdrant": "down",
-        "minio": "down",
-        "worker": "unknown"
-    }
-
-    # 1. Redis
-    try:
-        if store.redis.ping():
-             components["redis"] = "healthy"
-    except: pass
+    from src.services.admin.health_history import check_components, get_health_history
 
-    # 2. Qdrant
-    try:
-        import requests
-        q_res = requests.get(f"{settings.QDRANT_URL}/readyz", timeout=1)
-        if q_res.status_code == 200:
-            components["qdrant"] = "healthy"
-    except: pass
-
-    # 3. MinIO
-    try:
-        import requests
-        # Try internal docker name first, then localhost fallback
-        endpoints = [
-             f"http://minio:9000/minio/health/live",
-             f"{settings.MINIO_ENDPOINT.replace('minio:9000', 'localhost:9000')}/minio/health/live"
-        ]
-        
-        for ep in endpoints:
-            try:
-                m_res = requests.get(ep, timeout=1)
-                if m_res.status_code == 200:
-                    components["minio"] = "healthy"
-                    break
-            except: continue
-    except: pass
-
-    # 4. Worker
-    # Ideal: Check a heartbeat key. For now, assume if Redis is up, Worker is likely okay.
-    # Future: Worker writes "worker:heartbeat" every 30s.
-    if components["redis"] == "healthy":
-        components["worker"] = "healthy" # Placeholder
-        
+    components = check_components(store)
+    # Persist transitions so the dashboard can show uptime/incidents
+    get_health_history().record(components)
     return components
 
 
@@ -470,10 +793,20 @@ async def get_system_status():
         "models": {
             model_id: model.get("active", True) 
             for model_id, model in models.items()
-        }
+        },
+        "device": _get_device_info()
     }
 
 
+def _get_device_info() -> dict:
+    try:
+        from src.core.device import get_device_info
+        return get_device_info()
+    except Exception as e:
+        logger.warning(f"Failed to collect device info: {e}")
+        return {"device": "unknown", "error": str(e)}
+
+
 @router.post("/system/rebuild-index", dependencies=[Depends(requires_role("admin"))])
 async def rebuild_index():
     """Trigger index rebuild via Celery."""
@@ -498,6 +831,97 @@ async def rebuild_index():
         }
 
 
+@router.get("/system/tasks", dependencies=[Depends(requires_role("admin"))])
+async def get_background_tasks(include_finished: bool = True):
+    """
+    Background tasks of the API process (model TTL monitor, alert delivery,
+    bus transport), with state, run/error counts and shutdown order.
+    """
+    from src.core.supervisor import get_supervisor
+
+    supervisor = get_supervisor()
+    return {
+        "summary": supervisor.summary(),
+        "tasks": supervisor.tasks(include_finished=include_finished),
+    }
+
+
+@router.get("/system/sources", dependencies=[Depends(requires_role("admin"))])
+async def get_source_health(refresh: bool = False):
+    """
+    Reachability and credentials of the configured git sources (providers
+    and mapped repositories), with what to fix for each failing one.
+    ``refresh`` skips the cached result.
+    """
+    from src.services.webhooks.source_health import check_sources
+
+    sources = await asyncio.to_thread(check_sources, refresh)
+    down = [name for name, source in sources.items() if source["status"] != "up"]
+    return {"status": "degraded" if down else "healthy", "down": down, "sources": sources}
+
+
+@router.get("/server/mode")
+async def get_server_mode_state():
+    """The server mode (normal, read_only, maintenance) and its reason."""
+    from src.core.server_mode import get_server_mode
+    return await asyncio.to_thread(get_server_mode().get)
+
+
+class ServerModeUpdate(BaseModel):
+    mode: Literal["normal", "read_only", "maintenance"]
+    reason: Optional[str] = None
+
+
+@router.put("/server/mode")
+async def set_server_mode(update: ServerModeUpdate, user: dict = Depends(requires_role("admin"))):
+    """
+    Switch every API replica to read-only or maintenance mode (or back).
+    Answers in every mode, so maintenance can be turned off again.
+    """
+    from src.core.server_mode import get_server_mode
+    from src.services.events.bus import emit
+
+    caller = user.get("sub") or user.get("id") or "admin"
+    state = await asyncio.to_thread(get_server_mode().set, update.mode, update.reason, caller)
+    get_admin_store().log_audit(
+        "server_mode",
+        f"Server mode set to {state['mode']}" + (f" ({state['reason']})" if state["reason"] else ""),
+        caller
+    )
+    emit("server.mode.changed", mode=state["mode"], reason=state["reason"], set_by=caller)
+    return state
+
+
+@router.get("/system/tiering")
+async def get_tiering_status():
+    """Get hot/cold tier point counts and the last maintenance run."""
+    from src.services.search.tiering import get_tier_manager
+    return get_tier_manager().get_status()
+
+
+@router.post("/system/tiering/run", dependencies=[Depends(requires_role("admin"))])
+async def run_tiering(org_id: Optional[str] = None):
+    """Trigger hot/cold tier maintenance via Celery."""
+    from src.services.search.tiering import get_tier_manager
+    from src.worker.celery_app import app as celery_app
+
+    if not get_tier_manager().enabled:
+        raise HTTPException(status_code=400, detail="Tiering is disabled (search.tiering.enabled)")
+
+    store = get_admin_store()
+    store.log_audit("tier_maintenance", f"Tier maintenance triggered for {org_id or 'all stores'}", "admin")
+
+    task = celery_app.send_task(
+        "src.tasks.maintenance.tier_maintenance_task",
+        kwargs={"org_id": org_id}
+    )
+    return {
+        "message": "Tier maintenance triggered",
+        "status": "queued",
+        "task_id": str(task.id)
+    }
+
+
 @router.post("/system/clear-cache", dependencies=[Depends(requires_role("admin"))])
 async def clear_cache():
     """Clear Redis cache (actually clears cache keys)."""
@@ -559,16 +983,128 @@ async def get_metrics():
         "search_latency_p99_ms": int(latencies.get("p99", 0)),
         "index_rate_docs_per_sec": store.get_counter("indexed_docs"),
         "active_connections": store.get_counter("active_connections"),
+        "generated_files_skipped": store.get_counter("generated_files_skipped"),
+        "generated_files_marked": store.get_counter("generated_files_marked"),
         "gpu_memory_used_mb": gpu_used, # System
         "gpu_memory_total_mb": gpu_total,
         "gpu_memory_service_mb": service_gpu_mb, # Specific process (API)
         "gpu_utilization_percent": gpu_util,
         "cpu_usage_percent": int(cpu_percent),
         "memory_usage_mb": int(memory.used / 1024 / 1024),
-        "components": components
+        "components": components,
+        "inference": get_inference_watchdog().get_stats(),
+        "search_cache": get_query_cache().get_stats()
     }
 
 
+@router.get("/metrics/timeseries")
+async def get_metrics_timeseries(
+    time_range: str = "24h",
+    granularity: Optional[str] = None,
+    store: Optional[str] = None,
+    user: dict = Depends(requires_role("viewer")),
+):
+    """
+    Search and indexing activity over ``time_range`` (``1h``, ``24h``, ``7d``,
+    ``30d``) in ``granularity`` steps, for all stores or one.
+
+    Ranges are limited by ``metrics.timeseries.tiers`` retention; a
+    granularity finer than the tier covering the range is widened.
+    """
+    from src.services.admin import store_acl
+    from src.services.admin.timeseries import InvalidRange, get_time_series
+
+    if store and not store_acl.can_access(user, store, store_acl.READ):
+        raise HTTPException(status_code=403, detail=f"Not allowed to read store '{store}'")
+    try:
+        return await asyncio.to_thread(get_time_series().query, time_range, granularity, store)
+    except InvalidRange as e:
+        raise HTTPException(status_code=400, detail=str(e))
+
+
+@router.get("/alerts")
+async def get_alerts(limit: int = 50):
+    """Get recent operational alerts (e.g. inference watchdog resets)."""
+    store = get_admin_store()
+    return {"alerts": store.get_alerts(limit)}
+
+
+@router.get("/alerts/sinks")
+async def get_alert_sinks():
+    """Configured alert sinks with recent delivery counts (no URLs or credentials)."""
+    from src.services.admin.alert_sinks import get_alert_dispatcher
+    return {"sinks": get_alert_dispatcher().get_status()}
+
+
+@router.get("/alerts/deliveries")
+async def get_alert_deliveries(limit: int = 100):
+    """Recent alert delivery attempts (delivered, failed, suppressed)."""
+    from src.services.admin.alert_sinks import get_alert_dispatcher
+    return {"deliveries": get_alert_dispatcher().get_deliveries(limit)}
+
+
+class AlertTestRequest(BaseModel):
+    severity: str = "warning"
+    message: str = "Test alert from Rice Search"
+
+
+@router.post("/alerts/test", dependencies=[Depends(requires_role("admin"))])
+async def send_test_alert(request: AlertTestRequest):
+    """Raise a test alert to check sink routing and delivery."""
+    from src.services.admin.alert_sinks import SEVERITIES
+    if request.severity not in SEVERITIES:
+        raise HTTPException(status_code=400, detail=f"Invalid severity: {request.severity}")
+    store = get_admin_store()
+    # Unique message so the test is never deduplicated
+    store.raise_alert(request.severity, "alerts.test", f"{request.message} ({datetime.now().isoformat()})")
+    store.log_audit("alert_test", f"Test {request.severity} alert sent", "admin")
+    return {"status": "sent"}
+
+
+@router.post("/inference/{kind}/reset", dependencies=[Depends(requires_role("admin"))])
+async def reset_inference_handler(kind: str):
+    """Manually rebuild an inference handler (embed, sparse, rerank)."""
+    from src.services.inference.watchdog import KINDS
+    if kind not in KINDS:
+        raise HTTPException(status_code=404, detail=f"Unknown inference kind: {kind}")
+    get_inference_watchdog().reset(kind, reason="manual reset")
+    return {"status": "reset", "kind": kind}
+
+
+@router.get("/usage", dependencies=[Depends(requires_role("admin"))])
+async def get_usage(
+    month: Optional[str] = None,
+    key: Optional[str] = None,
+    format: Literal["json", "csv"] = "json"
+):
+    """
+    Monthly resource usage per API key and store (chargeback export).
+
+    ``month`` is YYYY-MM (default: current month, UTC); ``format=csv``
+    downloads one row per key and store.
+    """
+    from fastapi.responses import Response
+    from src.services.admin.usage import MONTH_PATTERN, get_usage_meter, report_csv
+
+    if month and not MONTH_PATTERN.match(month):
+        raise HTTPException(status_code=400, detail=f"Invalid month '{month}'; expected YYYY-MM")
+    meter = get_usage_meter()
+    try:
+        report = meter.report(month, key)
+    except Exception as e:
+        raise HTTPException(status_code=503, detail=f"Usage store unavailable: {e}")
+
+    if format == "csv":
+        filename = f"usage-{report['month']}.csv"
+        return Response(
+            report_csv(report),
+            media_type="text/csv",
+            headers={"Content-Disposition": f'attachment; filename="{filename}"'}
+        )
+    report["available_months"] = meter.months()
+    return report
+
+
 @router.get("/audit-log")
 async def get_audit_log(limit: int = 20):
     """Get recent audit log entries (persisted to Redis)."""
@@ -577,8 +1113,24 @@ async def get_audit_log(limit: int = 20):
     return {"logs": logs}
 
 
+@router.get("/exports/{token}", dependencies=[Depends(requires_role("admin"))])
+async def lookup_export(token: str):
+    """
+    Trace a search result export from a watermark token found in a leaked
+    file (``<request_id>.<signature>``) or a bare request id.
+    """
+    from src.services.admin.result_export import get_result_exporter
+    try:
+        export = get_result_exporter().lookup(token)
+    except Exception as e:
+        raise HTTPException(status_code=503, detail=f"Export records unavailable: {e}")
+    if export is None:
+        raise HTTPException(status_code=404, detail="No export with this id (unknown or expired)")
+    return export
+
+
 @router.get("/health-history")
 async def get_health_history(hours: int = 24):
-    """Get health check history."""
-    # For now, return empty - would need scheduled health checks
-    return {"history": [], "note": "Health history requires scheduled monitoring"}
+    """Get health check history (uptime, incidents, transitions)."""
+    from src.services.admin.health_history import get_health_history as _history
+    return _history().get_history(hours=hours)
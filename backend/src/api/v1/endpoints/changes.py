@@ -0,0 +1,48 @@
+"""
+API change log endpoint.
+"""
+
+from typing import Any, Dict, List, Optional
+
+from fastapi import APIRouter, HTTPException, Query
+from pydantic import BaseModel
+
+from src.api.versioning import (
+    VERSION_HEADER,
+    changes_since,
+    current_version,
+    list_deprecations,
+    minimum_version,
+    parse_version,
+)
+
+router = APIRouter()
+
+
+class ChangesResponse(BaseModel):
+    current: str
+    minimum: str
+    header: str
+    versions: List[Dict[str, Any]]
+    deprecations: List[Dict[str, Any]]
+
+
+@router.get("", response_model=ChangesResponse)
+async def api_changes(
+    since: Optional[str] = Query(None, description="Only revisions after this one (e.g. 1.0)"),
+):
+    """
+    Behavior changes per API revision, and current deprecations.
+
+    Clients pin the revision they were written against with the
+    ``Rice-Api-Version`` request header and read what changed since.
+    """
+    if since is not None and parse_version(since) is None:
+        raise HTTPException(status_code=400, detail=f"Invalid version '{since}'")
+    return {
+        "current": current_version(),
+        "minimum": minimum_version(),
+        "header": VERSION_HEADER,
+        "versions": changes_since(since),
+        "deprecations": list_deprecations(),
+    }
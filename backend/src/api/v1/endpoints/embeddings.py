@@ -0,0 +1,96 @@
+"""
+OpenAI-compatible embeddings endpoint.
+
+Point an OpenAI SDK at ``http://<host>:8000/api/v1`` and call
+``client.embeddings.create(model=..., input=[...])``.
+
+With ``store`` set, texts are embedded with a model that store has
+vectors for (its own by default), so the vectors can be compared with
+its chunks; ``model`` must then be one of the store's indexed models.
+"""
+
+from fastapi import APIRouter, HTTPException, Depends
+from pydantic import BaseModel
+from typing import Optional, Literal, List, Union
+
+from src.api.v1.dependencies import get_current_user, authorize_store, user_key
+from src.core.config import settings
+from src.services.admin.usage import record_usage, start_usage
+from src.services.inference.openai_compat import normalize_input, create_embeddings
+
+router = APIRouter()
+
+
+class EmbeddingRequest(BaseModel):
+    """OpenAI embeddings request."""
+    input: Union[str, List[str], List[int], List[List[int]]]
+    model: Optional[str] = None
+    encoding_format: Literal["float", "base64"] = "float"
+    dimensions: Optional[int] = None
+    user: Optional[str] = None
+    # Embed with a model indexed in this store (see GET /stores/{id}/models)
+    store: Optional[str] = None
+
+
+@router.post("")
+async def create_embedding(
+    request: EmbeddingRequest,
+    user: dict = Depends(get_current_user)
+):
+    """
+    Create embeddings (OpenAI `/v1/embeddings` compatible).
+
+    Inputs are embedded in batches with the configured embedding model
+    (or the requested Ollama model). Usage reports prompt tokens.
+    """
+    try:
+        texts = normalize_input(request.input)
+    except ValueError as e:
+        raise HTTPException(status_code=400, detail=str(e))
+
+    max_inputs = int(settings.get("embeddings_api.max_inputs", 2048))
+    if len(texts) > max_inputs:
+        raise HTTPException(
+            status_code=400,
+            detail=f"Too many inputs ({len(texts)}); maximum is {max_inputs}"
+        )
+
+    if request.dimensions is not None and request.dimensions < 1:
+        raise HTTPException(status_code=400, detail="dimensions must be positive")
+
+    model = request.model
+    if request.store:
+        from src.services.admin.admin_store import get_admin_store
+        from src.services.admin.store_acl import READ
+        from src.services.search.model_overrides import resolve_embedding_model
+        if request.store not in get_admin_store().get_stores():
+            raise HTTPException(status_code=404, detail="Store not found")
+        authorize_store(user, request.store, READ)
+        try:
+            target = resolve_embedding_model(request.store, request.model)
+        except ValueError as e:
+            raise HTTPException(status_code=400, detail=str(e))
+        # The store's own vectors come from the default inference model
+        model = target["model"] if target else None
+
+    start_usage(user_key(user), request.store or user.get("org_id"))
+    try:
+        response = await create_embeddings(
+            texts,
+            model=model,
+            dimensions=request.dimensions,
+            encoding_format=request.encoding_format,
+        )
+    except Exception as e:
+        raise HTTPException(status_code=502, detail=f"Embedding backend failed: {e}")
+
+    try:
+        from src.services.admin.admin_store import get_admin_store
+        store = get_admin_store()
+        store.increment_counter("embedding_requests")
+        store.increment_counter("embedding_tokens", response["usage"]["total_tokens"])
+    except Exception:
+        pass
+    record_usage("embed_tokens", response["usage"]["total_tokens"])
+
+    return response
@@ -0,0 +1,186 @@
+"""
+Event endpoints.
+
+Recent events as JSON, a live Server-Sent Events stream for watching
+indexing runs, alerts and admin actions as they happen, replay of
+journaled events onto the bus, and the dead-letter queue of failed requests.
+"""
+
+import asyncio
+import json
+import logging
+from typing import List, Optional, Union
+
+from fastapi import APIRouter, Depends, Header, HTTPException, Query
+from fastapi.responses import StreamingResponse
+from pydantic import BaseModel
+
+from src.api.deps import requires_role
+from src.core.draining import get_drain_state
+from src.services.admin.admin_store import get_admin_store
+from src.services.events.bus import get_event_bus, parse_topics
+from src.services.events.dead_letters import get_dead_letter_store
+from src.services.events.journal import get_event_journal
+from src.services.search.filters import parse_time
+
+logger = logging.getLogger(__name__)
+
+router = APIRouter(dependencies=[Depends(requires_role("viewer"))])
+
+# How long one blocking read waits before sending a keepalive
+STREAM_BLOCK_MS = 15000
+
+
+@router.get("")
+async def list_events(
+    topics: Optional[str] = Query(None, description="Comma-separated topic patterns, e.g. index.*,alert.*"),
+    limit: int = Query(100, ge=1, le=1000),
+):
+    """Most recent events (oldest first)."""
+    bus = get_event_bus()
+    try:
+        events = await asyncio.to_thread(bus.recent, parse_topics(topics), limit)
+    except Exception as e:
+        logger.error(f"Failed to read events: {e}")
+        raise HTTPException(status_code=503, detail="Event bus unavailable")
+    return {"events": events}
+
+
+@router.get("/topics")
+async def list_topics():
+    """Topics seen among recent events (for filter suggestions)."""
+    try:
+        return {"topics": await asyncio.to_thread(get_event_bus().topics)}
+    except Exception as e:
+        logger.error(f"Failed to read event topics: {e}")
+        raise HTTPException(status_code=503, detail="Event bus unavailable")
+
+
+@router.get("/stream")
+async def stream_events(
+    topics: Optional[str] = Query(None, description="Comma-separated topic patterns"),
+    since: Optional[str] = Query(None, description="Replay events after this id first"),
+    last_event_id: Optional[str] = Header(None, alias="Last-Event-ID"),
+):
+    """
+    Live events as Server-Sent Events.
+
+    Each event is sent with its id, so browsers reconnecting with
+    ``Last-Event-ID`` pick up where they left off.
+    """
+    bus = get_event_bus()
+    patterns = parse_topics(topics)
+    start = last_event_id or since or "$"
+
+    async def generate():
+        last_id = start
+        yield "retry: 3000\n\n"
+        # Ends when the server starts draining; the client reconnects elsewhere
+        while not get_drain_state().draining:
+            try:
+                last_id, events = await asyncio.to_thread(
+                    bus.read, last_id, patterns, STREAM_BLOCK_MS
+                )
+            except Exception as e:
+                logger.warning(f"Event stream read failed: {e}")
+                yield f"event: error\ndata: {json.dumps({'error': 'Event bus unavailable'})}\n\n"
+                await asyncio.sleep(5)
+                continue
+            if not events:
+                yield ": keepalive\n\n"
+                continue
+            for event in events:
+                yield f"id: {event['id']}\ndata: {json.dumps(event, default=str)}\n\n"
+
+    return StreamingResponse(
+        generate(),
+        media_type="text/event-stream",
+        headers={"Cache-Control": "no-cache", "X-Accel-Buffering": "no"},
+    )
+
+
+class ReplayRequest(BaseModel):
+    """Time range (epoch seconds or ISO 8601) and topics to replay."""
+    since: Optional[Union[float, str]] = None
+    until: Optional[Union[float, str]] = None
+    topics: Optional[List[str]] = None
+    limit: Optional[int] = None
+    dry_run: bool = False
+
+
+@router.post("/replay", dependencies=[Depends(requires_role("admin"))])
+async def replay_events(request: ReplayRequest):
+    """
+    Republish journaled events from a time range onto the bus.
+
+    Replayed events keep their ``event_id`` and are marked ``replayed``, so
+    consumers guarded by ``EventBus.claim`` skip events they already handled.
+    """
+    try:
+        since = parse_time(request.since)
+        until = parse_time(request.until)
+    except ValueError:
+        raise HTTPException(status_code=400, detail="since/until must be epoch seconds or ISO 8601")
+    if since is not None and until is not None and since > until:
+        raise HTTPException(status_code=400, detail="since must not be after until")
+    if request.limit is not None and request.limit < 1:
+        raise HTTPException(status_code=400, detail="limit must be positive")
+
+    try:
+        result = await asyncio.to_thread(
+            get_event_bus().replay, since, until, request.topics, request.limit, request.dry_run
+        )
+    except Exception as e:
+        logger.error(f"Event replay failed: {e}")
+        raise HTTPException(status_code=503, detail="Event bus unavailable")
+
+    if not request.dry_run:
+        get_admin_store().log_audit(
+            "events_replayed",
+            f"Replayed {result['events']} events ({request.since or 'start'} - {request.until or 'now'})",
+            "admin"
+        )
+    return result
+
+
+@router.get("/dlq")
+async def list_dead_letters(
+    topics: Optional[str] = Query(None, description="Comma-separated topic patterns"),
+    limit: int = Query(100, ge=1, le=1000),
+):
+    """Failed bus requests and index tasks, newest first."""
+    try:
+        return await asyncio.to_thread(get_dead_letter_store().list, parse_topics(topics), limit)
+    except Exception as e:
+        logger.error(f"Failed to read dead letters: {e}")
+        raise HTTPException(status_code=503, detail="Dead-letter store unavailable")
+
+
+@router.post("/dlq/{entry_id}/requeue", dependencies=[Depends(requires_role("admin"))])
+async def requeue_dead_letter(entry_id: str):
+    """
+    Run a dead-lettered request or index task again.
+
+    Requests are sent to a worker and removed once it succeeds (a repeat
+    failure keeps the entry with its new error); index tasks are removed
+    once dispatched.
+    """
+    result = await asyncio.to_thread(get_dead_letter_store().requeue, entry_id)
+    if result is None:
+        raise HTTPException(status_code=404, detail=f"Dead letter not found: {entry_id}")
+    get_admin_store().log_audit("dead_letter_requeued", f"Requeued dead letter {entry_id}: {result['status']}", "admin")
+    return result
+
+
+@router.delete("/dlq/{entry_id}", dependencies=[Depends(requires_role("admin"))])
+async def delete_dead_letter(entry_id: str):
+    """Drop a dead letter without running it."""
+    if not await asyncio.to_thread(get_dead_letter_store().remove, entry_id):
+        raise HTTPException(status_code=404, detail=f"Dead letter not found: {entry_id}")
+    return {"status": "deleted", "id": entry_id}
+
+
+@router.get("/journal")
+async def journal_stats():
+    """Event journal segments and size."""
+    return await asyncio.to_thread(get_event_journal().get_stats)
@@ -1,8 +1,11 @@
-from fastapi import APIRouter, HTTPException, Query
+from fastapi import APIRouter, Depends, Header, HTTPException, Query, Response
 from typing import List, Optional
 from pydantic import BaseModel
 
+from src.api.conditional import conditional
+from src.api.v1.dependencies import authorize_store, get_current_user
 from src.services.mcp.tools import handle_list_files, handle_read_file
+from src.services.search.query_cache import index_version
 
 router = APIRouter()
 
@@ -17,12 +20,23 @@ class FileContentResponse(BaseModel):
 
 @router.get("/list", response_model=FileListResponse)
 async def list_files(
+    response: Response,
     org_id: str = "public",
-    pattern: Optional[str] = None
+    pattern: Optional[str] = None,
+    if_none_match: Optional[str] = Header(None),
+    user: dict = Depends(get_current_user),
 ):
     """
     List all indexed files.
+
+    Tagged with the store's index generation; answers 304 to a current
+    ``If-None-Match`` without scanning the index. Stores with an ACL list
+    files to their readers only.
     """
+    authorize_store(user, org_id, "read")
+    not_modified = conditional(response, if_none_match, "files", org_id, pattern, index_version(org_id))
+    if not_modified:
+        return not_modified
     files = await handle_list_files(org_id=org_id, pattern=pattern)
     return {
         "files": files,
@@ -32,11 +46,22 @@ async def list_files(
 @router.get("/content", response_model=FileContentResponse)
 async def get_file_content(
     path: str = Query(..., description="Full path to file"),
-    org_id: str = "public"
+    org_id: str = "public",
+    user: dict = Depends(get_current_user),
 ):
     """
     Get content of a specific file.
+
+    Not available for stores in privacy mode (content is never stored),
+    nor to callers a store's ACL doesn't name.
     """
+    authorize_store(user, org_id, "read")
+    from src.services.ingestion.privacy import is_private_store
+    if is_private_store(org_id):
+        raise HTTPException(
+            status_code=404,
+            detail=f"Store '{org_id}' is in privacy mode; read {path} from the local checkout"
+        )
     content = await handle_read_file(file_path=path, org_id=org_id)
     
     if content.startswith("File not found") or content.startswith("Error reading"):
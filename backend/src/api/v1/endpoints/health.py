@@ -0,0 +1,39 @@
+"""
+Health history and ML worker endpoints.
+"""
+
+import asyncio
+
+from fastapi import APIRouter, Query
+
+from src.services.admin.health_history import get_health_history
+from src.services.inference.ml_workers import list_ml_workers, ml_worker_health
+
+router = APIRouter()
+
+
+@router.get("/history")
+async def health_history(
+    hours: float = Query(24, gt=0, le=24 * 30, description="Window size in hours"),
+    buckets: int = Query(24, ge=1, le=288, description="Timeline buckets across the window"),
+):
+    """
+    Component health over a time window.
+
+    Returns uptime percentage, incidents (unhealthy periods) and a bucketed
+    up/down timeline per component, plus the raw state transitions.
+    """
+    return get_health_history().get_history(hours=hours, buckets=buckets)
+
+
+@router.get("/ml-workers")
+async def ml_workers():
+    """
+    Remote ML workers seen on the event bus.
+
+    One entry per worker (latest heartbeat): topics served, device, request
+    and error counts, and whether it is still live.
+    """
+    workers = await asyncio.to_thread(list_ml_workers)
+    health = await asyncio.to_thread(ml_worker_health)
+    return {"health": health, "workers": workers}
@@ -1,11 +1,21 @@
 import shutil
 import os
 import uuid
-from fastapi import APIRouter, UploadFile, File, HTTPException, Depends, Form
-from typing import Dict, Optional
+from fastapi import APIRouter, UploadFile, File, HTTPException, Depends, Form, Header, Query, Response
+from fastapi.concurrency import run_in_threadpool
+from typing import Dict, List, Optional
+from pydantic import BaseModel
 from src.tasks.ingestion import ingest_file_task
-from src.api.v1.dependencies import verify_admin
+from src.api.v1.dependencies import verify_admin, authorize_connection, authorize_store, is_admin, user_key
 from src.core.config import settings
+from src.api.idempotency import idempotent
+from src.core.errors import QUEUE_FULL, AppError
+from src.services.admin.admin_store import get_admin_store
+from src.services.admin.usage import record_usage, start_usage
+from src.db.qdrant import get_qdrant_client
+from src.services.ingestion.indexer import Indexer
+from src.services.ingestion.exclusion import get_exclusion_policy
+from src.services.ingestion.pipeline import check_admission
 
 router = APIRouter()
 
@@ -13,18 +23,96 @@ TEMP_DIR = settings.TEMP_DIR
 os.makedirs(TEMP_DIR, exist_ok=True)
 
 @router.post("/file", status_code=202)
+@idempotent("ingest.file")
 async def upload_file(
+    response: Response,
     file: UploadFile = File(...),
     org_id: Optional[str] = Form("public"),
+    connection_id: Optional[str] = Form(None),
+    language: Optional[str] = Form(None),
+    admin_override: bool = Form(False),
+    x_connection_token: Optional[str] = Header(None),
+    idempotency_key: Optional[str] = Header(None),
     admin: dict = Depends(verify_admin)
 ) -> Dict:
     """
     Upload a file to ingest into the Vector DB.
+
+    ``language`` is optional; when omitted the indexer detects it from the
+    file name, shebang and content.
+
+    Uploads for a ``connection_id`` need its X-Connection-Token and may only
+    replace files that connection (or no connection) indexed, unless an
+    admin sets ``admin_override``.
+
+    Paths excluded by the store's policy are not queued; the worker checks
+    size and content against the policy before indexing.
+
+    While more than ``indexing.pipeline.max_queued_files`` files wait for
+    a worker the upload is refused with 429 and Retry-After.
+
+    Stores with an ACL only take uploads from their owner and writers.
+
+    With an ``Idempotency-Key`` header a retried upload returns the first
+    upload's response instead of queueing the file again.
+    """
+    authorize_connection(admin, connection_id, x_connection_token, admin_override)
+
+    # Original path from client (sent as filename in multipart)
+    original_path = file.filename or "unknown"
+
+    # Get org_id from form or authenticated user
+    effective_org_id = org_id or admin.get("org_id", "public")
+    authorize_store(admin, effective_org_id, "write", connection_id)
+    if connection_id:
+        get_admin_store().touch_connection(connection_id, store=effective_org_id)
+
+    enforce_owner = bool(connection_id) and not admin_override
+    if enforce_owner:
+        await _check_owner(original_path, effective_org_id, connection_id)
+
+    return await queue_upload(
+        file.file, original_path, effective_org_id, admin,
+        connection_id=connection_id, language=language, enforce_owner=enforce_owner
+    )
+
+
+async def queue_upload(
+    source,
+    original_path: str,
+    org_id: str,
+    user: dict,
+    connection_id: Optional[str] = None,
+    language: Optional[str] = None,
+    enforce_owner: bool = False,
+    admit: bool = True,
+) -> Dict:
+    """
+    Queue an uploaded file (a readable binary ``source``) for indexing.
+
+    Paths excluded by the store's policy are dropped instead. ``admit``
+    checks queue backpressure first (429 with Retry-After when full);
+    batch uploads check once for the whole batch.
     """
+    # Excluded paths are refused before queueing (and dropped if still indexed)
+    excluded = get_exclusion_policy(org_id).check_path(original_path)
+    if excluded:
+        removed = await run_in_threadpool(
+            Indexer(get_qdrant_client()).delete_file, original_path, org_id
+        )
+        return {"status": "excluded", "reason": excluded, "file": original_path, "chunks_removed": removed}
+
+    # Backpressure: too many files already waiting for a worker
+    retry_after = await run_in_threadpool(check_admission) if admit else None
+    if retry_after is not None:
+        raise AppError(
+            status_code=429,
+            detail=f"Index queue is full, retry in {retry_after}s",
+            code=QUEUE_FULL,
+            headers={"Retry-After": str(retry_after)},
+        )
+
     try:
-        # Original path from client (sent as filename in multipart)
-        original_path = file.filename or "unknown"
-        
         # Create unique temp path for processing
         file_id = str(uuid.uuid4())
         ext = os.path.splitext(original_path)[1]
@@ -36,17 +124,20 @@ async def upload_file(
         
         # Save file to temp location
         with open(temp_path, "wb") as buffer:
-            shutil.copyfileobj(file.file, buffer)
+            shutil.copyfileobj(source, buffer)
 
-        # Get org_id from form or authenticated user
-        effective_org_id = org_id or admin.get("org_id", "public")
+        start_usage(user_key(user), org_id)
+        record_usage("storage_bytes", os.path.getsize(temp_path))
 
         # Dispatch Celery Task with ORIGINAL path for metadata
         task = ingest_file_task.delay(
             temp_path,           # actual file location for reading
             original_path,       # original client path for metadata
             repo_name="default",
-            org_id=effective_org_id
+            org_id=org_id,
+            connection_id=connection_id,
+            language=language.lower() if language else None,
+            enforce_owner=enforce_owner
         )
         
         return {"status": "queued", "task_id": str(task.id), "file": original_path}
@@ -54,3 +145,108 @@ async def upload_file(
     except Exception as e:
         raise HTTPException(status_code=500, detail=str(e))
 
+
+class HashedFile(BaseModel):
+    # Path the file would be uploaded under
+    path: str
+    # Normalized content hash (src.services.ingestion.hashing.file_hash)
+    hash: str
+
+
+class CheckHashesRequest(BaseModel):
+    files: List[HashedFile]
+    org_id: Optional[str] = None
+    hash_version: Optional[int] = None
+    connection_id: Optional[str] = None
+
+
+@router.post("/check-hashes")
+async def check_hashes(
+    request: CheckHashesRequest,
+    x_connection_token: Optional[str] = Header(None),
+    admin: dict = Depends(verify_admin)
+) -> Dict:
+    """
+    Pre-flight for a scan: which files need uploading.
+
+    The client sends path and normalized hash pairs; ``upload`` lists the
+    paths that are new or changed (everything else would be skipped by the
+    indexer anyway) and ``excluded`` the paths the store's exclusion policy
+    refuses. At most ``indexing.check_hashes.max_files`` per call.
+    A client hashing with another ``hash_version`` gets 400 and should
+    upload everything.
+    """
+    from src.services.ingestion.hashing import HASH_VERSION
+    from src.services.ingestion.reindex_plan import get_reindex_planner
+
+    authorize_connection(admin, request.connection_id, x_connection_token)
+    if request.hash_version is not None and request.hash_version != HASH_VERSION:
+        raise HTTPException(
+            status_code=400,
+            detail=f"hash_version {request.hash_version} is not supported (server uses {HASH_VERSION})"
+        )
+    max_files = int(settings.get("indexing.check_hashes.max_files", 5000))
+    if len(request.files) > max_files:
+        raise HTTPException(status_code=400, detail=f"At most {max_files} files per request")
+
+    effective_org_id = request.org_id or admin.get("org_id", "public")
+    authorize_store(admin, effective_org_id, "write", request.connection_id)
+    files = [f.dict() for f in request.files]
+    try:
+        return await run_in_threadpool(get_reindex_planner().check_hashes, effective_org_id, files)
+    except Exception as e:
+        raise HTTPException(status_code=503, detail=f"Hash check failed: {e}")
+
+
+@router.delete("/file")
+@idempotent("ingest.delete")
+async def delete_file(
+    response: Response,
+    path: str = Query(..., description="Path the file was indexed under"),
+    org_id: Optional[str] = Query(None, description="Store (default: caller's org)"),
+    connection_id: Optional[str] = Query(None, description="Connection deleting the file"),
+    admin_override: bool = Query(False, description="Delete regardless of owner (admins only)"),
+    x_connection_token: Optional[str] = Header(None),
+    idempotency_key: Optional[str] = Header(None),
+    admin: dict = Depends(verify_admin)
+) -> Dict:
+    """
+    Remove a file from the index.
+
+    A connection only removes the chunks it indexed, and is refused if the
+    file belongs to another connection. Without a connection the caller
+    must be an admin. With an ``Idempotency-Key`` header a retried delete
+    returns the first delete's response instead of 404.
+    """
+    authorize_connection(admin, connection_id, x_connection_token, admin_override)
+    effective_org_id = org_id or admin.get("org_id", "public")
+    authorize_store(admin, effective_org_id, "write", connection_id)
+
+    if not connection_id and not is_admin(admin):
+        raise HTTPException(status_code=403, detail="Deleting files requires a connection or admin")
+
+    if connection_id and not admin_override:
+        await _check_owner(path, effective_org_id, connection_id)
+
+    indexer = Indexer(get_qdrant_client())
+    removed = await run_in_threadpool(
+        indexer.delete_file, path, effective_org_id, None if admin_override else connection_id
+    )
+    if not removed:
+        raise HTTPException(status_code=404, detail=f"File not indexed: {path}")
+    return {"status": "deleted", "file": path, "store": effective_org_id, "chunks_removed": removed}
+
+
+async def _check_owner(path: str, org_id: str, connection_id: str):
+    """403 if another connection owns chunks of the file."""
+    indexer = Indexer(get_qdrant_client())
+    try:
+        foreign = await run_in_threadpool(indexer.foreign_owners, path, org_id, connection_id)
+    except Exception as e:
+        raise HTTPException(status_code=503, detail=f"Ownership check failed: {e}")
+    if foreign:
+        raise HTTPException(
+            status_code=403,
+            detail=f"{path} is owned by another connection ({', '.join(foreign)})"
+        )
+
@@ -10,6 +10,7 @@ import time
 
 from src.services.admin.admin_store import get_admin_store
 from src.db.qdrant import get_qdrant_client
+from src.services.search.query_cache import get_query_cache
 from src.core.config import settings
 
 router = APIRouter()
@@ -61,6 +62,55 @@ def get_metrics_text() -> str:
     lines.append("# TYPE rice_search_latency_p99_seconds gauge")
     lines.append(f"rice_search_latency_p99_seconds {latencies.get('p99', 0) / 1000:.4f}")
     
+    # Inference timeouts and watchdog resets
+    lines.append("# HELP rice_search_inference_timeouts_total Inference calls that exceeded their timeout")
+    lines.append("# TYPE rice_search_inference_timeouts_total counter")
+    for kind in ("embed", "sparse", "rerank"):
+        lines.append(f'rice_search_inference_timeouts_total{{kind="{kind}"}} {store.get_counter(f"inference_timeouts_{kind}")}')
+
+    lines.append("# HELP rice_search_inference_resets_total Inference handler resets by the watchdog")
+    lines.append("# TYPE rice_search_inference_resets_total counter")
+    for kind in ("embed", "sparse", "rerank"):
+        lines.append(f'rice_search_inference_resets_total{{kind="{kind}"}} {store.get_counter(f"inference_resets_{kind}")}')
+
+
+    # Query analysis path (query model vs heuristics)
+    from src.services.search.query_analyzer import FALLBACK_REASONS
+    lines.append("# HELP rice_search_query_analysis_total Analyzed queries by the path that served them")
+    lines.append("# TYPE rice_search_query_analysis_total counter")
+    for path in ("model", "heuristic"):
+        lines.append(f'rice_search_query_analysis_total{{path="{path}"}} {store.get_counter(f"query_analysis_{path}")}')
+
+    lines.append("# HELP rice_search_query_analysis_fallbacks_total Queries served by heuristics, by reason")
+    lines.append("# TYPE rice_search_query_analysis_fallbacks_total counter")
+    for reason in FALLBACK_REASONS:
+        lines.append(f'rice_search_query_analysis_fallbacks_total{{reason="{reason}"}} {store.get_counter(f"query_analysis_fallback_{reason}")}')
+
+    # Query cache (this API process)
+    cache_stats = get_query_cache().get_stats()
+    lines.append("# HELP rice_search_query_cache_hits_total Searches served from the query cache")
+    lines.append("# TYPE rice_search_query_cache_hits_total counter")
+    lines.append(f"rice_search_query_cache_hits_total {cache_stats['hits']}")
+    lines.append("# HELP rice_search_query_cache_misses_total Searches that missed the query cache")
+    lines.append("# TYPE rice_search_query_cache_misses_total counter")
+    lines.append(f"rice_search_query_cache_misses_total {cache_stats['misses']}")
+    
+    # Per-route latency and status (this API process)
+    from src.core.request_middleware import get_route_metrics
+    lines.extend(get_route_metrics().prometheus_lines())
+
+    # Index queue and pipeline stage depths (all workers)
+    from src.services.ingestion.pipeline import prometheus_lines as pipeline_lines
+    lines.extend(pipeline_lines())
+
+    # Dependency circuit breakers (this API process)
+    from src.core.circuit_breaker import get_breakers
+    lines.extend(get_breakers().prometheus_lines())
+
+    # Qdrant client pools and retries (this API process)
+    from src.db.qdrant_pool import prometheus_lines as qdrant_pool_lines
+    lines.extend(qdrant_pool_lines())
+
     # Index size (from Qdrant)
     try:
         qdrant = get_qdrant_client()
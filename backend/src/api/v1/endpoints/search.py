@@ -1,10 +1,33 @@
-from fastapi import APIRouter, HTTPException, Depends, Query
-from pydantic import BaseModel
-from typing import Optional, Literal, List
+import asyncio
+import json
+import logging
+import time
+from fastapi import APIRouter, HTTPException, Depends, Header, Query, Request, Response, WebSocket, WebSocketDisconnect
+from fastapi.responses import StreamingResponse
+from pydantic import BaseModel, ConfigDict
+from typing import Any, Optional, Literal, List, Dict, Union
 from src.services.search.retriever import Retriever
+from src.services.search import degradation
+from src.services.search.filters import SearchFilters, parse_time
+from src.services.search.query_syntax import parse as parse_query_syntax
+from src.services.search.deadline import SearchTimeoutError
+from src.services.search.chunks import get_chunk, preview_result
+from src.services.search.context import expand_context
+from src.services.search.facets import compute_facets
+from src.services.search.live import LiveSearchSession
+from src.services.search.streaming import SearchStream, rerank_enabled
+from src.services.search.query_analyzer import analyze_for_search
+from src.services.search.reranker import rerank_with_model
 from src.services.rag.engine import RAGEngine
-from src.api.v1.dependencies import get_current_user
+from src.api.v1.dependencies import get_current_user, authorize_store, is_admin, verified_connection, user_key
+from src.api.versioning import mark_deprecated
 from src.core.config import settings
+from src.services.events.bus import emit
+from src.services.admin.usage import start_usage
+from src.services.admin.timeseries import get_time_series
+from src.services.admin.search_quality import get_search_quality
+
+logger = logging.getLogger(__name__)
 
 router = APIRouter()
 
@@ -17,7 +40,45 @@ class SearchRequest(BaseModel):
     use_bm25: bool = True
     use_splade: bool = True
     use_bm42: bool = True
-    # Legacy
+    # Store to search (defaults to the caller's org)
+    store: Optional[str] = None
+    # Attach per-result score composition (debugging)
+    explain: bool = False
+    # Only chunks defining one of these symbols (also inline: symbol:Name)
+    symbols: Optional[List[str]] = None
+    # Path globs to include/exclude (e.g. "src/**", "**/test/**")
+    paths: Optional[List[str]] = None
+    exclude_paths: Optional[List[str]] = None
+    # File extensions ("go", ".py")
+    extensions: Optional[List[str]] = None
+    # Only chunks indexed since (epoch seconds or ISO 8601)
+    modified_since: Optional[Union[float, str]] = None
+    # Docs written in these languages (ISO 639-1: "en", "de", "ja")
+    content_languages: Optional[List[str]] = None
+    # Programming languages ("python", "go") and uploading connections
+    languages: Optional[List[str]] = None
+    connections: Optional[List[str]] = None
+    # Search deadline in seconds (default: search.timeout.default_seconds)
+    timeout: Optional[float] = None
+    # Page through results (offset into a stable result window)
+    offset: Optional[int] = None
+    # False: short previews instead of chunk text (see GET /chunks/{id})
+    include_content: bool = True
+    # Also return the store's A/B experiment variants side by side
+    experiment: bool = False
+    # Analyze the query with patterns only, never the query model
+    force_heuristic: bool = False
+    # Count language/directory/connection facets over the candidates
+    facets: bool = False
+    # Lines of surrounding code and the file's imports per result (0: off)
+    context_lines: int = 0
+    # Embedding model / reranker for this query instead of the store's
+    # (see GET /stores/{id}/models)
+    model_id: Optional[str] = None
+    rerank_model_id: Optional[str] = None
+    # Blend of code and docstring vectors ({"code": 1.0, "doc": 0.5})
+    vector_weights: Optional[Dict[str, float]] = None
+    # Deprecated: maps to use_splade (see GET /api/v1/changes)
     hybrid: Optional[bool] = None
 
     def __init__(self, **data):
@@ -28,13 +89,66 @@ class SearchRequest(BaseModel):
             self.limit = settings.DEFAULT_SEARCH_LIMIT
 
 
-@router.post("/query")
+class SearchHit(BaseModel):
+    """One search result: the chunk's payload plus scores (documentation only)."""
+    model_config = ConfigDict(extra="allow")
+
+    chunk_id: str
+    score: float
+    full_path: Optional[str] = None
+    text: Optional[str] = None
+    preview: Optional[str] = None
+    start_line: Optional[int] = None
+    end_line: Optional[int] = None
+    language: Optional[str] = None
+    symbols: Optional[List[str]] = None
+    # One-sentence LLM summary (stores with summaries on)
+    summary: Optional[str] = None
+    explanation: Optional[Dict[str, Any]] = None
+    context: Optional[Dict[str, Any]] = None
+
+
+class SearchPage(BaseModel):
+    offset: int
+    limit: int
+    has_more: bool
+
+
+class SearchQueryResponse(BaseModel):
+    """Body of /search/query: ``results`` in search mode, ``answer`` and ``sources`` in RAG mode."""
+    model_config = ConfigDict(extra="allow")
+
+    mode: Literal["search", "rag"]
+    query_id: Optional[str] = None
+    results: Optional[List[SearchHit]] = None
+    filters: Optional[Dict[str, Any]] = None
+    parsed_query: Optional[Dict[str, Any]] = None
+    did_you_mean: Optional[str] = None
+    retrievers: Optional[Dict[str, bool]] = None
+    models: Optional[Dict[str, str]] = None
+    degraded: Optional[bool] = None
+    degraded_reasons: Optional[List[str]] = None
+    page: Optional[SearchPage] = None
+    facets: Optional[Dict[str, Any]] = None
+    answer: Optional[str] = None
+    sources: Optional[List[Dict[str, Any]]] = None
+
+
+@router.post("/query", responses={200: {"model": SearchQueryResponse}})
 async def search_post(
     request: SearchRequest,
-    user: dict = Depends(get_current_user)
+    response: Response,
+    user: dict = Depends(get_current_user),
+    x_connection_id: Optional[str] = Header(None),
+    x_connection_token: Optional[str] = Header(None)
 ):
     """
     Search or RAG query endpoint (POST).
+
+    CLIs send their connection ID and token (X-Connection-ID,
+    X-Connection-Token) so searches show up in per-connection stats and
+    the connection counts for store ACLs. An ID without its token is
+    ignored.
     
     Args:
         query: Search query
@@ -43,7 +157,32 @@ async def search_post(
         use_bm25: Enable BM25 retrieval (default: true)
         use_splade: Enable SPLADE retrieval (default: true)
         use_bm42: Enable BM42 retrieval (default: true)
+        store: Store to search (default: caller's org)
+        explain: Include per-result score explanation
+        symbols: Restrict to chunks defining these symbols
+        paths: Path globs to include
+        exclude_paths: Path globs to exclude
+        extensions: File extensions to include
+        modified_since: Only chunks indexed since (epoch seconds or ISO 8601)
+        content_languages: Only docs chunks written in these languages
+        languages: Only files in these programming languages
+        connections: Only files uploaded by these connections
+        timeout: Search deadline in seconds (504 when exceeded)
+        offset: Skip this many results (paging; see response ``page``)
+        include_content: False for previews instead of full chunk text
+        experiment: Add both variants of the store's A/B model experiment
+        force_heuristic: Skip the query model (pattern-based analysis only)
+        facets: Add language, directory and connection counts (``facets``)
+        context_lines: Add N surrounding lines and the file's imports (``context``)
+        model_id: Embedding model indexed in the store to search with (400 if not indexed)
+        rerank_model_id: Reranker to use instead of the configured one
+        vector_weights: Code/doc vector blend, e.g. {"code": 1.0, "doc": 0.5}
     """
+    if request.hybrid is not None:
+        mark_deprecated(response, "search-hybrid")
+    connection_id = verified_connection(x_connection_id, x_connection_token)
+    if connection_id:
+        _record_connection_search(connection_id, request.store)
     return await _perform_search(
         query=request.query,
         mode=request.mode,
@@ -52,11 +191,29 @@ async def search_post(
         use_splade=request.use_splade,
         use_bm42=request.use_bm42,
         hybrid=request.hybrid,
-        user=user
+        user=user,
+        store=request.store,
+        explain=request.explain,
+        filters=_build_filters(
+            request.symbols, request.paths, request.exclude_paths,
+            request.extensions, request.modified_since, request.content_languages,
+            request.languages, request.connections
+        ),
+        timeout=request.timeout,
+        offset=request.offset,
+        include_content=request.include_content,
+        experiment=request.experiment,
+        force_heuristic=request.force_heuristic,
+        facets=request.facets,
+        context_lines=request.context_lines,
+        model_id=request.model_id,
+        rerank_model_id=request.rerank_model_id,
+        vector_weights=request.vector_weights,
+        connection_id=connection_id
     )
 
 
-@router.get("/query")
+@router.get("/query", responses={200: {"model": SearchQueryResponse}})
 async def search_get(
     query: str = Query(..., description="Search query"),
     mode: Literal["search", "rag"] = Query(None, description="search or rag"),
@@ -64,6 +221,25 @@ async def search_get(
     use_bm25: bool = Query(True, description="Enable BM25 retrieval"),
     use_splade: bool = Query(True, description="Enable SPLADE retrieval"),
     use_bm42: bool = Query(True, description="Enable BM42 retrieval"),
+    store: Optional[str] = Query(None, description="Store to search (default: caller's org)"),
+    explain: bool = Query(False, description="Include per-result score explanation"),
+    symbol: Optional[List[str]] = Query(None, description="Restrict to chunks defining this symbol (repeatable)"),
+    path: Optional[List[str]] = Query(None, description="Path glob to include (repeatable)"),
+    exclude_path: Optional[List[str]] = Query(None, description="Path glob to exclude (repeatable)"),
+    ext: Optional[List[str]] = Query(None, description="File extension to include (repeatable)"),
+    modified_since: Optional[str] = Query(None, description="Only chunks indexed since (epoch seconds or ISO 8601)"),
+    lang: Optional[List[str]] = Query(None, description="Docs content language, ISO 639-1 (repeatable)"),
+    language: Optional[List[str]] = Query(None, description="Programming language (repeatable)"),
+    connection: Optional[List[str]] = Query(None, description="Uploading connection (repeatable)"),
+    timeout: Optional[float] = Query(None, description="Search deadline in seconds"),
+    offset: Optional[int] = Query(None, ge=0, description="Skip this many results (paging)"),
+    include_content: bool = Query(True, description="False for previews instead of chunk text"),
+    experiment: bool = Query(False, description="Add the store's A/B experiment variants"),
+    force_heuristic: bool = Query(False, description="Skip the query model (pattern-based analysis only)"),
+    facets: bool = Query(False, description="Add language, directory and connection facet counts"),
+    context_lines: int = Query(0, ge=0, description="Surrounding lines and file imports per result"),
+    model_id: Optional[str] = Query(None, description="Embedding model indexed in the store to search with"),
+    rerank_model_id: Optional[str] = Query(None, description="Reranker to use instead of the configured one"),
     user: dict = Depends(get_current_user)
 ):
     """
@@ -75,6 +251,12 @@ async def search_get(
         /query?query=test - Uses all retrievers
         /query?query=test&use_bm25=false - Excludes BM25
         /query?query=test&use_splade=false&use_bm42=false - BM25 only
+        /query?query=config symbol:ParseConfig - Only chunks defining ParseConfig
+        /query?query=lang:go path:cmd/ "NewServer" -vendor - Inline filters (see ``parsed_query``)
+        /query?query=test&path=src/**&exclude_path=**/test/** - Path globs
+        /query?query=install&lang=de - German docs only
+        /query?query=retry&facets=true&language=go - Go files, with facets
+        /query?query=retry&model_id=baai-bge-m3 - Search the store's bge-m3 vectors
     """
     # Apply defaults from settings if not provided
     if mode is None:
@@ -90,7 +272,184 @@ async def search_get(
         use_splade=use_splade,
         use_bm42=use_bm42,
         hybrid=None,
-        user=user
+        user=user,
+        store=store,
+        explain=explain,
+        filters=_build_filters(symbol, path, exclude_path, ext, modified_since, lang, language, connection),
+        timeout=timeout,
+        offset=offset,
+        include_content=include_content,
+        experiment=experiment,
+        force_heuristic=force_heuristic,
+        facets=facets,
+        context_lines=context_lines,
+        model_id=model_id,
+        rerank_model_id=rerank_model_id
+    )
+
+
+@router.websocket("/live")
+async def search_live(websocket: WebSocket):
+    """
+    Search as you type (WebSocket).
+
+    Send ``{"seq": 1, "query": "pars", ...}`` on every keystroke; options
+    are the POST /query fields (store, limit, filters, retriever flags,
+    include_content). Replies are ``{"type": "results", "seq", "query",
+    "took_ms", "results", ...}`` or ``{"type": "error", "seq", "status",
+    "detail"}``. Typing is debounced server-side and a newer query cancels
+    the older one, so not every seq gets a reply. Browsers can't set
+    headers on a WebSocket; pass the bearer token as ``?token=``.
+    """
+    if not settings.get("search.live.enabled", True):
+        await websocket.close(code=1008, reason="Live search is disabled")
+        return
+    try:
+        user = await get_current_user(
+            token=websocket.query_params.get("token"),
+            x_user_id=websocket.headers.get("x-user-id"),
+        )
+    except HTTPException as e:
+        await websocket.close(code=1008, reason=str(e.detail))
+        return
+
+    await websocket.accept()
+    session = LiveSearchSession(
+        search=lambda message: _live_search(message, user),
+        send=websocket.send_json,
+    )
+    try:
+        while True:
+            text = await websocket.receive_text()
+            try:
+                message = json.loads(text)
+                if not isinstance(message, dict):
+                    raise ValueError("expected an object")
+            except ValueError as e:
+                await websocket.send_json({"type": "error", "seq": None, "status": 400, "detail": f"Invalid message: {e}"})
+                continue
+            await session.submit(message)
+    except WebSocketDisconnect:
+        pass
+    finally:
+        await session.close()
+
+
+@router.post("/stream")
+async def search_stream(
+    request: SearchRequest,
+    raw_request: Request,
+    user: dict = Depends(get_current_user)
+):
+    """
+    Search with results streamed as NDJSON (search mode only).
+
+    Takes the POST /query fields. Sends ``{"type": "candidates", ...}``
+    with the fused results, then ``{"type": "rerank", "patches": [...]}``
+    as reranking progresses, then ``{"type": "done", "order": [...]}``.
+    Closing the connection stops reranking, so readers that only need the
+    top candidates don't pay for the rest.
+    """
+    org_id = _resolve_store(user, request.store)
+    rerank_model = _resolve_models(org_id, request.model_id, request.rerank_model_id).get("rerank")
+    _check_vector_weights(request.vector_weights)
+    _record_store_search(org_id)
+    start_usage(user_key(user), org_id)
+
+    parsed = parse_query_syntax(request.query, _build_filters(
+        request.symbols, request.paths, request.exclude_paths,
+        request.extensions, request.modified_since, request.content_languages,
+        request.languages, request.connections
+    ))
+    query, filters = parsed.text, parsed.filters
+    analysis = await _analyze(query, request.force_heuristic)
+    if analysis:
+        query = analysis.processed_query
+
+    stream = SearchStream(
+        search=lambda: Retriever.search(
+            query=query,
+            limit=request.limit,
+            org_id=org_id,
+            use_bm25=request.use_bm25,
+            use_splade=request.use_splade,
+            use_bm42=request.use_bm42,
+            hybrid=request.hybrid,
+            rerank=False,
+            filters=None if filters.is_empty() else filters,
+            timeout=request.timeout,
+            model_id=request.model_id,
+            vector_weights=request.vector_weights,
+        ),
+        query=query,
+        rerank=rerank_enabled(org_id),
+        scorer=(lambda q, texts: rerank_with_model(q, texts, rerank_model)) if rerank_model else None,
+        present=None if request.include_content else preview_result,
+        is_disconnected=raw_request.is_disconnected,
+    )
+
+    async def body():
+        started = time.perf_counter()
+        async for line in stream.ndjson():
+            yield line
+        _emit_search(org_id, "stream", started, stream.count, request.query)
+
+    return StreamingResponse(body(), media_type="application/x-ndjson")
+
+
+@router.post("/inspect")
+async def search_inspect(
+    request: SearchRequest,
+    user: dict = Depends(get_current_user)
+):
+    """
+    Debug a query (``rice-search inspect --query``).
+
+    Returns the parsed query and filters, query analysis (intent,
+    expansions), per-encoder and per-retriever timings, and each
+    retriever's raw top ``limit`` candidates before fusion and reranking.
+    Not cached and not counted as a search.
+    """
+    from src.services.search.inspection import get_search_inspector
+
+    org_id = _resolve_store(user, request.store)
+    filters = _build_filters(
+        request.symbols, request.paths, request.exclude_paths,
+        request.extensions, request.modified_since, request.content_languages,
+        request.languages, request.connections
+    )
+    return await get_search_inspector().inspect_query(
+        request.query, org_id, limit=request.limit, filters=filters,
+        force_heuristic=request.force_heuristic
+    )
+
+
+async def _live_search(message: Dict, user: dict) -> Dict:
+    """One live search: POST /query fields, search mode, previews by default."""
+    max_limit = int(settings.get("search.max_limit", 150))
+    limit = min(int(message.get("limit") or settings.get("search.live.limit", 10)), max_limit)
+    return await _perform_search(
+        query=message["query"],
+        mode="search",
+        limit=limit,
+        use_bm25=message.get("use_bm25", True),
+        use_splade=message.get("use_splade", True),
+        use_bm42=message.get("use_bm42", True),
+        hybrid=None,
+        user=user,
+        store=message.get("store"),
+        filters=_build_filters(
+            message.get("symbols"), message.get("paths"), message.get("exclude_paths"),
+            message.get("extensions"), message.get("modified_since"), message.get("content_languages"),
+            message.get("languages"), message.get("connections")
+        ),
+        timeout=message.get("timeout"),
+        include_content=bool(message.get("include_content", False)),
+        force_heuristic=bool(message.get("force_heuristic", False)),
+        facets=bool(message.get("facets", False)),
+        model_id=message.get("model_id"),
+        rerank_model_id=message.get("rerank_model_id"),
+        vector_weights=message.get("vector_weights"),
     )
 
 
@@ -102,41 +461,590 @@ async def _perform_search(
     use_splade: bool,
     use_bm42: bool,
     hybrid: Optional[bool],
-    user: dict
+    user: dict,
+    store: Optional[str] = None,
+    explain: bool = False,
+    filters: Optional[SearchFilters] = None,
+    timeout: Optional[float] = None,
+    offset: Optional[int] = None,
+    include_content: bool = True,
+    experiment: bool = False,
+    force_heuristic: bool = False,
+    facets: bool = False,
+    context_lines: int = 0,
+    model_id: Optional[str] = None,
+    rerank_model_id: Optional[str] = None,
+    vector_weights: Optional[Dict[str, float]] = None,
+    connection_id: Optional[str] = None
 ):
     """Shared search logic for GET and POST."""
-    try:
-        org_id = user.get("org_id", "public")
+    org_id = _resolve_store(user, store, connection_id)
+    models = _resolve_models(org_id, model_id, rerank_model_id)
+    _check_vector_weights(vector_weights)
+    _record_store_search(org_id)
+    start_usage(user_key(user), org_id)
+    window = _page_window(limit, offset)
+    candidates = _facet_candidates(window or limit) if facets else None
+    # Quality tracking counts the query as typed, not the rewritten one
+    raw_query = query
+    started = time.perf_counter()
 
+    try:
         if mode == "search":
-            results = await Retriever.search(
-                query=query,
-                limit=limit,
-                org_id=org_id,
-                use_bm25=use_bm25,
-                use_splade=use_splade,
-                use_bm42=use_bm42,
-                hybrid=hybrid
-            )
-            return {
+            parsed = parse_query_syntax(query, filters)
+            query, filters = parsed.text, parsed.filters
+            analysis = await _analyze(query, force_heuristic)
+            if analysis:
+                query = analysis.processed_query
+            with degradation.track() as degraded:
+                results = await Retriever.search(
+                    query=query,
+                    limit=candidates or window or limit,
+                    org_id=org_id,
+                    use_bm25=use_bm25,
+                    use_splade=use_splade,
+                    use_bm42=use_bm42,
+                    hybrid=hybrid,
+                    explain=explain,
+                    filters=None if filters.is_empty() else filters,
+                    timeout=timeout,
+                    include_content=include_content,
+                    model_id=model_id,
+                    rerank_model_id=rerank_model_id,
+                    vector_weights=vector_weights
+                )
+            facet_counts = compute_facets(results) if facets else None
+            page = None
+            if window:
+                page = {
+                    "offset": offset,
+                    "limit": limit,
+                    "has_more": len(results) > offset + limit,
+                }
+                results = results[offset:offset + limit]
+            elif candidates:
+                results = results[:limit]
+            if context_lines and context_lines > 0:
+                results = await asyncio.to_thread(expand_context, results, org_id, context_lines)
+            if not include_content:
+                results = [preview_result(r) for r in results]
+            response = {
                 "mode": "search",
+                "query_id": _record_impression(org_id, results),
                 "results": results,
+                "filters": filters.to_dict(),
+                "parsed_query": parsed.to_dict(),
                 "retrievers": {
                     "bm25": use_bm25,
                     "splade": use_splade,
                     "bm42": use_bm42
-                }
+                },
+                "degraded": bool(degraded)
             }
+            if models:
+                response["models"] = models
+            if degraded:
+                response["degraded_reasons"] = list(degraded)
+            if analysis:
+                response["query_analysis"] = analysis.to_metadata()
+            if page:
+                response["page"] = page
+            if facet_counts is not None:
+                response["facets"] = facet_counts
+            if not offset:
+                suggestion = await _did_you_mean(org_id, raw_query, len(results))
+                if suggestion:
+                    response["did_you_mean"] = suggestion
+            if experiment:
+                response["experiment"] = await _compare_variants(
+                    query, org_id, limit, filters, use_bm25, use_splade, use_bm42
+                )
+            _emit_search(org_id, "search", started, len(results), raw_query)
+            return response
         
         elif mode == "rag":
+            from src.services.ingestion.privacy import is_private_store
+            if is_private_store(org_id):
+                raise HTTPException(
+                    status_code=400,
+                    detail=f"Store '{org_id}' is in privacy mode (no content stored); RAG is unavailable"
+                )
             engine = RAGEngine()
             response = await engine.ask(query, org_id=org_id)
+            _emit_search(org_id, "rag", started, len(response.get("sources") or []), raw_query)
             return {"mode": "rag", **response}
-            
+
+    except SearchTimeoutError as e:
+        raise HTTPException(status_code=504, detail=e.to_dict())
+    except HTTPException:
+        raise
     except Exception as e:
         raise HTTPException(status_code=500, detail=str(e))
 
 
+async def _analyze(query: str, force_heuristic: bool):
+    """
+    Query analysis for a search: the query model when configured and
+    enabled, otherwise the heuristics. None when analysis is off or failed.
+    """
+    if not settings.get("search.query_analysis.enabled", True):
+        return None
+    try:
+        return await analyze_for_search(query, force_heuristic)
+    except Exception as e:
+        logger.warning(f"Query analysis failed, using raw query: {e}")
+        return None
+
+
+async def _compare_variants(
+    query: str,
+    org_id: str,
+    limit: int,
+    filters: SearchFilters,
+    use_bm25: bool,
+    use_splade: bool,
+    use_bm42: bool
+) -> Dict:
+    """Both variants of the store's A/B experiment plus overlap metrics (400 without one)."""
+    from src.services.search.experiments import get_experiment_runner, get_store_experiment
+
+    if not get_store_experiment(org_id):
+        raise HTTPException(status_code=400, detail=f"Store '{org_id}' has no active experiment")
+    return await get_experiment_runner().compare(
+        query, org_id, limit, None if filters.is_empty() else filters,
+        use_bm25=use_bm25, use_splade=use_splade, use_bm42=use_bm42
+    )
+
+
+def _page_window(limit: int, offset: Optional[int]) -> Optional[int]:
+    """
+    Results to retrieve when paging (None when not paging).
+
+    Every page of a query is cut from the same window of
+    ``search.pagination.window`` results, so pages don't overlap or shift
+    and later pages are served from the query cache. Paging stops at
+    ``search.max_limit``.
+    """
+    if offset is None:
+        return None
+    max_limit = int(settings.get("search.max_limit", 150))
+    if offset < 0 or offset + limit > max_limit:
+        raise HTTPException(
+            status_code=400,
+            detail=f"offset + limit must be between 0 and {max_limit}"
+        )
+    window = int(settings.get("search.pagination.window", 100))
+    # One extra result tells whether another page exists
+    return min(max(window, offset + limit + 1), max_limit + 1)
+
+
+def _facet_candidates(wanted: int) -> int:
+    """
+    Results to retrieve when counting facets.
+
+    Facets describe the top ``search.facets.candidates`` matches rather
+    than just the returned page, so narrowing shows what else is there.
+    """
+    max_limit = int(settings.get("search.max_limit", 150))
+    candidates = int(settings.get("search.facets.candidates", 100))
+    return min(max(candidates, wanted), max_limit + 1)
+
+
+def _resolve_models(org_id: str, model_id: Optional[str], rerank_model_id: Optional[str]) -> Dict[str, str]:
+    """Overridden models by role ("embedding", "rerank"); 400 if the store can't use one."""
+    from src.services.search.model_overrides import resolve_embedding_model, resolve_rerank_model
+    try:
+        target = resolve_embedding_model(org_id, model_id)
+        rerank_model = resolve_rerank_model(org_id, rerank_model_id)
+    except ValueError as e:
+        raise HTTPException(status_code=400, detail=str(e))
+    models = {}
+    if target:
+        models["embedding"] = target["model"]
+    if rerank_model:
+        models["rerank"] = rerank_model
+    return models
+
+
+def _check_vector_weights(vector_weights: Optional[Dict[str, float]]):
+    """400 on unknown or negative code/doc vector weights."""
+    from src.services.ingestion.doc_vectors import vector_weights as resolve_vector_weights
+    if not vector_weights:
+        return
+    try:
+        resolve_vector_weights(override=vector_weights)
+    except (TypeError, ValueError) as e:
+        raise HTTPException(status_code=400, detail=f"Invalid vector_weights: {e}")
+
+
+def _build_filters(
+    symbols: Optional[List[str]],
+    paths: Optional[List[str]],
+    exclude_paths: Optional[List[str]],
+    extensions: Optional[List[str]],
+    modified_since: Optional[Union[float, str]],
+    content_languages: Optional[List[str]] = None,
+    languages: Optional[List[str]] = None,
+    connections: Optional[List[str]] = None
+) -> SearchFilters:
+    """Search filters from request fields (400 on an unparseable time)."""
+    try:
+        since = parse_time(modified_since)
+    except ValueError:
+        raise HTTPException(status_code=400, detail=f"Invalid modified_since: {modified_since}")
+    return SearchFilters(
+        symbols=symbols or [],
+        include_paths=paths or [],
+        exclude_paths=exclude_paths or [],
+        extensions=extensions or [],
+        modified_since=since,
+        content_languages=content_languages or [],
+        languages=languages or [],
+        connections=connections or [],
+    )
+
+
+async def _did_you_mean(org_id: str, query: str, results: int) -> Optional[str]:
+    """Spelling-corrected query when a search found few results (never fails the request)."""
+    if not settings.get("search.suggest.enabled", True):
+        return None
+    if results >= int(settings.get("search.suggest.did_you_mean_below", 3)):
+        return None
+    try:
+        from src.services.search.suggestions import get_query_suggester
+        correction = await asyncio.to_thread(get_query_suggester().correct, org_id, query)
+    except Exception as e:
+        logger.debug(f"Spelling suggestion for {org_id} failed: {e}")
+        return None
+    return correction["suggestion"]
+
+
+def _emit_search(org_id: str, mode: str, started: float, results: int, query: Optional[str] = None):
+    """
+    Count the search in the metrics time series and search quality
+    tracking (when ``query`` is given) and publish a search.query event.
+    """
+    latency_ms = round((time.perf_counter() - started) * 1000, 1)
+    get_time_series().record_search(org_id, latency_ms)
+    if query is not None:
+        get_search_quality().record(org_id, query, results, latency_ms)
+    if not settings.get("events.search_queries", True):
+        return
+    emit("search.query", org_id=org_id, mode=mode, results=results, latency_ms=latency_ms)
+
+
+def _resolve_store(user: dict, store: Optional[str], connection_id: Optional[str] = None) -> str:
+    """
+    Pick the store to search.
+
+    Callers may only search outside their own org when auth is disabled,
+    they hold the admin role or the store's ACL names them. A store with
+    an ACL is closed to everyone it doesn't name, its own org included.
+    ``connection_id`` must be verified.
+    """
+    from src.services.admin.store_acl import READ, store_acl
+
+    user_org = user.get("org_id", "public")
+    target = store or user_org
+    if target != user_org and settings.AUTH_ENABLED and not is_admin(user):
+        try:
+            restricted = bool(store_acl(target))
+        except Exception:
+            restricted = False
+        if not restricted:
+            raise HTTPException(status_code=403, detail=f"Not allowed to search store '{target}'")
+    authorize_store(user, target, READ, connection_id)
+    return target
+
+
+def _record_connection_search(connection_id: str, store: Optional[str]):
+    """Count a search against the CLI connection that sent it."""
+    try:
+        from src.services.admin.admin_store import get_admin_store
+        get_admin_store().touch_connection(connection_id, store=store, searches=1)
+    except Exception as e:
+        logger.debug(f"Failed to record search for connection {connection_id}: {e}")
+
+
+def _record_store_search(store_id: str):
+    """Track search volume per store (never fails the request)."""
+    try:
+        from src.services.admin.admin_store import get_admin_store
+        get_admin_store().record_store_search(store_id)
+    except Exception:
+        pass
+
+
+def _record_impression(org_id: str, results: list) -> Optional[str]:
+    """Remember returned results so feedback can be attributed (never fails the request)."""
+    try:
+        from src.services.search.fusion_tuning import get_fusion_tuner
+        return get_fusion_tuner().record_impression(org_id, results)
+    except Exception:
+        return None
+
+
+class BatchSearchRequest(BaseModel):
+    queries: List[str]
+    limit: int = None
+    use_bm25: bool = True
+    use_splade: bool = True
+    use_bm42: bool = True
+    store: Optional[str] = None
+    rerank: Optional[bool] = None
+    explain: bool = False
+    # Filters applied to every query (inline query syntax is per query)
+    symbols: Optional[List[str]] = None
+    paths: Optional[List[str]] = None
+    exclude_paths: Optional[List[str]] = None
+    extensions: Optional[List[str]] = None
+    modified_since: Optional[Union[float, str]] = None
+    content_languages: Optional[List[str]] = None
+    languages: Optional[List[str]] = None
+    connections: Optional[List[str]] = None
+    # Deadline per query in seconds (default: search.timeout.default_seconds)
+    timeout: Optional[float] = None
+
+
+@router.post("/batch")
+async def search_batch(
+    request: BatchSearchRequest,
+    user: dict = Depends(get_current_user)
+):
+    """
+    Run up to ``search.batch.max_queries`` queries against one store.
+
+    Queries are embedded in a single ML batch and searched concurrently, so
+    agents issuing many related queries pay the encoding and HTTP overhead
+    once. Results come back per query, in request order; a failed query
+    carries an ``error`` instead of failing the batch (``timed_out`` is
+    set when it ran past its deadline).
+    """
+    if not request.queries:
+        raise HTTPException(status_code=400, detail="No queries provided")
+    max_queries = int(settings.get("search.batch.max_queries", 50))
+    if len(request.queries) > max_queries:
+        raise HTTPException(
+            status_code=400,
+            detail=f"Too many queries ({len(request.queries)}); the limit is {max_queries}"
+        )
+
+    org_id = _resolve_store(user, request.store)
+    start_usage(user_key(user), org_id)
+    shared = _build_filters(
+        request.symbols, request.paths, request.exclude_paths,
+        request.extensions, request.modified_since, request.content_languages,
+        request.languages, request.connections
+    )
+    parsed = [parse_query_syntax(q, shared) for q in request.queries]
+    limit = request.limit or settings.DEFAULT_SEARCH_LIMIT
+
+    start = time.perf_counter()
+    outcomes = await Retriever.search_batch(
+        [p.text for p in parsed],
+        limit=limit,
+        org_id=org_id,
+        filters=[None if p.filters.is_empty() else p.filters for p in parsed],
+        use_bm25=request.use_bm25,
+        use_splade=request.use_splade,
+        use_bm42=request.use_bm42,
+        rerank=request.rerank,
+        explain=request.explain,
+        timeout=request.timeout,
+    )
+
+    responses = []
+    for query, parsed_query, outcome in zip(request.queries, parsed, outcomes):
+        _record_store_search(org_id)
+        # Failed queries aren't zero-result queries
+        failed = isinstance(outcome, Exception)
+        _emit_search(org_id, "batch", start, 0 if failed else len(outcome), None if failed else query)
+        if isinstance(outcome, SearchTimeoutError):
+            responses.append({"query": query, "error": str(outcome), "timed_out": True, "results": []})
+            continue
+        if isinstance(outcome, Exception):
+            responses.append({"query": query, "error": str(outcome), "results": []})
+            continue
+        responses.append({
+            "query": query,
+            "query_id": _record_impression(org_id, outcome),
+            "results": outcome,
+            "filters": parsed_query.filters.to_dict(),
+            "parsed_query": parsed_query.to_dict(),
+        })
+
+    return {
+        "mode": "search",
+        "store": org_id,
+        "count": len(responses),
+        "failed": sum(1 for r in responses if "error" in r),
+        "took_ms": round((time.perf_counter() - start) * 1000, 1),
+        "responses": responses,
+    }
+
+
+class ExportRequest(SearchRequest):
+    format: Literal["csv", "json"] = "json"
+
+
+@router.post("/export")
+async def export_results(
+    request: ExportRequest,
+    user: dict = Depends(get_current_user)
+):
+    """
+    Download search results as CSV or JSON.
+
+    Takes the same fields as ``POST /query`` (search mode only). Each export
+    is logged in the audit trail under a request id; with
+    ``exports.watermark.enabled`` the file carries a signed watermark on
+    every row. Counts against ``exports.rate_limit``.
+    """
+    from src.services.admin.rate_limit import get_rate_limiter
+    from src.services.admin.result_export import get_result_exporter
+
+    user_id = user.get("id") or user.get("sub") or "anonymous"
+    allowed, retry_after = get_rate_limiter().hit(
+        f"export:{user_id}",
+        int(settings.get("exports.rate_limit.requests", 10)),
+        int(settings.get("exports.rate_limit.window_seconds", 3600))
+    )
+    if not allowed:
+        raise HTTPException(
+            status_code=429,
+            detail="Export rate limit exceeded",
+            headers={"Retry-After": str(retry_after)}
+        )
+
+    response = await search_post(request.copy(update={"mode": "search"}), Response(), user)
+    exported = get_result_exporter().export(
+        response["results"],
+        request.format,
+        user=user_id,
+        store=_resolve_store(user, request.store),
+        query=request.query,
+    )
+    filename = exported["filename"]
+    download = Response(
+        exported["content"],
+        media_type=exported["media_type"],
+        headers={
+            "Content-Disposition": f'attachment; filename="{filename}"',
+            "X-Export-Id": exported["request_id"],
+        }
+    )
+    if request.hybrid is not None:
+        mark_deprecated(download, "search-hybrid")
+    return download
+
+
+@router.get("/chunks/{chunk_id}")
+async def get_chunk_content(
+    chunk_id: str,
+    store: Optional[str] = Query(None, description="Store (default: caller's org)"),
+    user: dict = Depends(get_current_user)
+):
+    """
+    Full content of a search result.
+
+    Pairs with ``include_content=false`` searches: clients render previews
+    and fetch a chunk's text when it is opened.
+    """
+    org_id = _resolve_store(user, store)
+    chunk = await asyncio.to_thread(get_chunk, chunk_id, org_id)
+    if chunk is None:
+        raise HTTPException(status_code=404, detail=f"Chunk not found: {chunk_id}")
+    return chunk
+
+
+class SearchFeedback(BaseModel):
+    query_id: str
+    result_id: str
+    action: Literal["click", "ignore"]
+
+
+@router.post("/feedback", status_code=202)
+async def search_feedback(
+    feedback: SearchFeedback,
+    user: dict = Depends(get_current_user)
+):
+    """
+    Report a click or ignore on a search result.
+
+    Feedback is attributed to the retrievers that surfaced the result and
+    periodically used to tune the store's fusion weights.
+    """
+    from src.services.search.fusion_tuning import get_fusion_tuner
+    from src.services.admin.admin_store import get_admin_store
+
+    applied = get_fusion_tuner().record_feedback(feedback.query_id, feedback.result_id, feedback.action)
+    if applied is None:
+        raise HTTPException(status_code=404, detail="Unknown or expired query_id/result_id")
+
+    get_admin_store().increment_counter(f"search_feedback_{feedback.action}")
+    return {"status": "accepted", **applied}
+
+
+@router.get("/weights")
+async def get_fusion_weights(
+    store: Optional[str] = Query(None, description="Store (default: caller's org)"),
+    user: dict = Depends(get_current_user)
+):
+    """Current fusion weights and feedback stats for a store."""
+    from src.services.search.fusion_tuning import get_fusion_tuner
+
+    org_id = _resolve_store(user, store)
+    tuner = get_fusion_tuner()
+    return {
+        "store": org_id,
+        "tuning_enabled": tuner.enabled,
+        "weights": tuner.get_weights(org_id),
+        "diversity_lambda": tuner.get_diversity_lambda(org_id),
+        "feedback": tuner.get_stats(org_id),
+    }
+
+
+class EvalQuery(BaseModel):
+    query: str
+    # File paths, or path -> graded relevance
+    relevant: Union[List[str], Dict[str, float]]
+
+
+class EvalRequest(BaseModel):
+    queries: List[EvalQuery]
+    store: Optional[str] = None
+    k: int = 10
+    use_bm25: bool = True
+    use_splade: bool = True
+    use_bm42: bool = True
+    rerank: Optional[bool] = None
+
+
+@router.post("/eval")
+async def evaluate_relevance(
+    request: EvalRequest,
+    user: dict = Depends(get_current_user)
+):
+    """
+    Run a labeled query set against a store and return NDCG@k, MRR and recall@k.
+    """
+    from src.services.search.evaluation import run_eval
+
+    if not request.queries:
+        raise HTTPException(status_code=400, detail="No queries provided")
+
+    org_id = _resolve_store(user, request.store)
+    return await run_eval(
+        [q.dict() for q in request.queries],
+        org_id=org_id,
+        k=request.k,
+        use_bm25=request.use_bm25,
+        use_splade=request.use_splade,
+        use_bm42=request.use_bm42,
+        rerank=request.rerank,
+    )
+
+
 @router.get("/config")
 async def get_search_config(user: dict = Depends(get_current_user)):
     """Get current search configuration."""
@@ -149,5 +1057,10 @@ async def get_search_config(user: dict = Depends(get_current_user)):
             "bm25_enabled": settings.BM25_ENABLED,
             "splade_enabled": settings.SPLADE_ENABLED,
             "bm42_enabled": settings.BM42_ENABLED
+        },
+        "rerank_sampling": {
+            "max_candidates": settings.get("models.reranker.max_candidates", 50),
+            "strategy": settings.get("models.reranker.sampling.strategy", "smart"),
+            "top_per_retriever": settings.get("models.reranker.sampling.top_per_retriever", 10),
         }
     }
@@ -0,0 +1,36 @@
+"""
+Search quality stats endpoints.
+"""
+
+import asyncio
+from typing import Optional
+
+from fastapi import APIRouter, Depends, HTTPException, Query
+
+from src.api.deps import requires_role
+from src.services.admin import store_acl
+from src.services.admin.search_quality import get_search_quality
+
+router = APIRouter()
+
+
+@router.get("/quality")
+async def search_quality(
+    store: Optional[str] = None,
+    limit: int = Query(10, ge=1, le=100, description="Queries listed per section"),
+    user: dict = Depends(requires_role("viewer")),
+):
+    """
+    Zero-result rate, latency percentiles (p50/p95/p99), the most frequent
+    zero-result queries and the recent queries at or above p95, for one
+    store or all stores (with a per-store breakdown).
+
+    Frequent zero-result queries are the quickest way to find what the
+    index doesn't cover. The all-stores view lists query text from every
+    store, so it is admin-only.
+    """
+    if not store and not store_acl.is_admin(user):
+        raise HTTPException(status_code=403, detail="Only admins can view all stores; pass a store")
+    if store and not store_acl.can_access(user, store, store_acl.READ):
+        raise HTTPException(status_code=403, detail=f"Not allowed to read store '{store}'")
+    return await asyncio.to_thread(get_search_quality().report, store, limit)
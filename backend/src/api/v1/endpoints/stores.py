@@ -1,12 +1,30 @@
-from fastapi import APIRouter, HTTPException, Body
-from typing import List, Dict, Optional
-from pydantic import BaseModel
+import asyncio
+import json
+import logging
+
+from fastapi import APIRouter, HTTPException, Body, Query, Depends, Header, Response, UploadFile, File, Form
+from fastapi.responses import StreamingResponse
+from typing import List, Dict, Optional, Literal, Union
+from pydantic import BaseModel, Field
 from datetime import datetime
 
 from src.services.admin.admin_store import get_admin_store
+from src.services.events.bus import emit, get_event_bus
+from src.api.conditional import conditional
+from src.api.deps import get_current_user, requires_role
+from src.api.v1.dependencies import authorize_store, user_key
+from src.core.config import settings
+from src.api.idempotency import idempotent
+from src.core.errors import FAILED_PRECONDITION, QUEUE_FULL, AppError
 from src.db.qdrant import get_qdrant_client
+from src.services.ingestion.migration import configured_dimension, configured_model, store_collection
+from src.services.search.query_cache import index_version, invalidate_store
+from src.services.admin import store_acl, store_trash
+from src.services.admin.store_stats import get_store_stats
 from qdrant_client.models import Filter, FieldCondition, MatchValue
 
+logger = logging.getLogger(__name__)
+
 router = APIRouter()
 
 class Store(BaseModel):
@@ -16,61 +34,239 @@ class Store(BaseModel):
     description: Optional[str] = None
     created_at: Optional[str] = None
     doc_count: Optional[int] = 0
+    search_count: Optional[int] = 0
+    last_searched_at: Optional[str] = None
+    # Search defaults applied by the tuning assistant
+    search: Optional[Dict] = None
+    # Sparse retriever: "splade" (neural) or "bm25" (inverted index, no model)
+    sparse_backend: Optional[str] = None
+    # Active A/B model experiment (kind, model_a, model_b, started_at)
+    experiment: Optional[Dict] = None
+    # Index vectors and line ranges only, never chunk text
+    privacy_mode: bool = False
+    # Embedding model/dimension the store's vectors were built with
+    embedding_model: Optional[str] = None
+    embedding_dim: Optional[int] = None
+    # Per-store collection after an embedding migration (None: shared collection)
+    collection: Optional[str] = None
+    migration: Optional[Dict] = None
+    # Qdrant shard/replication/payload/HNSW settings for the store's own collection
+    collection_config: Optional[Dict] = None
+    # Index exclusion policy overrides (see PUT /{store_id}/exclusion)
+    exclusion: Optional[Dict] = None
+    # Query-time boosting rules (see PUT /{store_id}/boosts)
+    boosts: Optional[List[Dict]] = None
+    # Recency ranking half-life and weight (see PUT /{store_id}/recency)
+    recency: Optional[Dict] = None
+    # LLM chunk summaries at index time (see PUT /{store_id}/summaries)
+    summaries: Optional[Dict] = None
+    # Owner, readers and writers (see PUT /{store_id}/acl); None: open store
+    acl: Optional[Dict] = None
+    # Files, chunks, bytes and language breakdown (see GET /{store_id}/stats)
+    stats: Optional[Dict] = None
+    # Repository indexed by POST /{store_id}/ingest/git (url, branch, sha, state)
+    git: Optional[Dict] = None
+
+class StoreACL(BaseModel):
+    # key:<user id> or connection:<connection id>; default: the caller
+    owner: Optional[str] = None
+    readers: List[str] = []
+    writers: List[str] = []
+
+class CollectionConfig(BaseModel):
+    shard_number: Optional[int] = Field(None, ge=1)
+    replication_factor: Optional[int] = Field(None, ge=1)
+    on_disk_payload: Optional[bool] = None
+    hnsw_m: Optional[int] = Field(None, ge=0)
+    hnsw_ef_construct: Optional[int] = Field(None, ge=4)
 
 class StoreCreate(BaseModel):
     id: str
     name: str
     type: str = "production"
     description: Optional[str] = None
+    sparse_backend: Optional[Literal["splade", "bm25"]] = None
+    privacy_mode: bool = False
+    # Creates a dedicated collection with these settings
+    collection_config: Optional[CollectionConfig] = None
+    # Restrict the store from the start
+    acl: Optional[StoreACL] = None
 
 @router.get("/", response_model=List[Store])
-async def list_stores():
+async def list_stores(
+    response: Response,
+    sort: Literal["usage", "name", "created"] = Query("usage", description="Sort order"),
+    if_none_match: Optional[str] = Header(None),
+    user: dict = Depends(get_current_user),
+):
     """
     List all configured stores.
+
+    Defaults to most used first (by search volume) so dropdowns can
+    show the stores people actually search at the top. Answers 304 to a
+    current ``If-None-Match``. Stores whose ACL doesn't name the caller
+    are left out.
     """
     admin_store = get_admin_store()
+    not_modified = conditional(
+        response, if_none_match, "stores", admin_store.get_version("stores"), sort, user.get("id")
+    )
+    if not_modified:
+        return not_modified
     stores_data = admin_store.get_stores()
+    usage = admin_store.get_store_usage()
     
     results = []
     # Optionally enrich with Qdrant stats (can be slow if many stores)
     # For now, just return metadata. 
     # Detailed stats can be fetched via /stores/{id}
     for sid, data in stores_data.items():
-        results.append(Store(**data))
+        if not store_acl.can_access(user, sid, store_acl.READ, stores=stores_data):
+            continue
+        results.append(Store(**{**data, **usage.get(sid, {})}))
+
+    if sort == "usage":
+        results.sort(key=lambda s: (-(s.search_count or 0), s.name.lower()))
+    elif sort == "name":
+        results.sort(key=lambda s: s.name.lower())
+    elif sort == "created":
+        results.sort(key=lambda s: s.created_at or "", reverse=True)
         
     return results
 
+
+@router.get("/usage/top")
+async def top_stores(
+    response: Response,
+    limit: int = Query(5, ge=1, le=50),
+    if_none_match: Optional[str] = Header(None),
+    user: dict = Depends(get_current_user),
+):
+    """
+    Most searched stores the caller may read, for the dashboard.
+    """
+    admin_store = get_admin_store()
+    not_modified = conditional(
+        response, if_none_match, "usage", admin_store.get_version("stores"), limit, user.get("id")
+    )
+    if not_modified:
+        return not_modified
+    stores_data = admin_store.get_stores()
+    usage = admin_store.get_store_usage()
+
+    top = []
+    for sid, stats in usage.items():
+        if sid not in stores_data:
+            continue
+        if not store_acl.can_access(user, sid, store_acl.READ, stores=stores_data):
+            continue
+        top.append({
+            "id": sid,
+            "name": stores_data[sid].get("name", sid),
+            **stats
+        })
+        if len(top) >= limit:
+            break
+
+    return {"stores": top}
+
 @router.post("/", response_model=Store)
-async def create_store(store: StoreCreate):
+async def create_store(store: StoreCreate, user: dict = Depends(get_current_user)):
     """
     Create a new store (logical index).
+
+    With ``acl`` the store is restricted from the start; its owner
+    defaults to the caller.
     """
     admin_store = get_admin_store()
     stores = admin_store.get_stores()
     
     if store.id in stores:
         raise HTTPException(status_code=400, detail="Store ID already exists")
+    if store.id in admin_store.get_trashed_stores():
+        # The old store's chunks would show up in the new one
+        raise AppError(409, f"Store {store.id} is in the trash; restore or purge it first", FAILED_PRECONDITION)
     
     new_store = store.dict()
+    if store.acl is not None:
+        new_store["acl"] = _validated_acl(store.acl, user)
     new_store["created_at"] = datetime.now().isoformat()
+    new_store["embedding_model"] = configured_model()
+    new_store["embedding_dim"] = configured_dimension()
+
+    if store.collection_config is not None:
+        from src.services.ingestion.collection_config import create_collection, dedicated_collection
+
+        config = store.collection_config.dict(exclude_none=True)
+        new_store["collection_config"] = config
+        new_store["collection"] = dedicated_collection(store.id)
+        try:
+            await asyncio.to_thread(
+                create_collection, get_qdrant_client(), new_store["collection"],
+                new_store["embedding_dim"], config
+            )
+        except Exception as e:
+            raise HTTPException(status_code=503, detail=f"Could not create collection: {e}")
     
     if admin_store.set_store(store.id, new_store):
+        get_store_stats().mark_new(store.id)
+        # Lets web UIs drop cached store lists
+        emit("store.created", store_id=store.id, name=new_store.get("name"))
         return Store(**new_store)
     else:
         raise HTTPException(status_code=500, detail="Failed to create store")
 
+@router.get("/trash")
+async def list_trashed_stores(user: dict = Depends(get_current_user)):
+    """
+    Deleted stores awaiting purge that the caller could restore, most
+    recently deleted first.
+    """
+    trash = get_admin_store().get_trashed_stores()
+    stores = [
+        {
+            "id": sid,
+            "name": data.get("name", sid),
+            "type": data.get("type"),
+            "description": data.get("description"),
+            "deleted_at": data.get("deleted_at"),
+            "deleted_by": data.get("deleted_by"),
+            "purge_after": data.get("purge_after"),
+        }
+        for sid, data in trash.items()
+        if store_acl.can_access(user, sid, store_acl.OWNER, stores=trash)
+    ]
+    stores.sort(key=lambda s: s["deleted_at"] or "", reverse=True)
+    return {"stores": stores, "retention_days": store_trash.retention_days()}
+
 @router.get("/{store_id}", response_model=Store)
-async def get_store(store_id: str):
+async def get_store(
+    store_id: str,
+    response: Response,
+    if_none_match: Optional[str] = Header(None),
+    user: dict = Depends(get_current_user),
+):
     """
     Get store details including document count.
+
+    The ETag covers store metadata and the store's index, so a 304 skips
+    the Qdrant count.
     """
     admin_store = get_admin_store()
     stores = admin_store.get_stores()
     
     if store_id not in stores:
         raise HTTPException(status_code=404, detail="Store not found")
+    authorize_store(user, store_id, store_acl.READ)
+
+    not_modified = conditional(
+        response, if_none_match,
+        "store", store_id, admin_store.get_version("stores"), index_version(store_id),
+    )
+    if not_modified:
+        return not_modified
     
-    store_data = stores[store_id]
+    store_data = {**stores[store_id], **admin_store.get_store_usage().get(store_id, {})}
     
     # Fetch real stats from Qdrant
     try:
@@ -85,24 +281,1342 @@ async def get_store(store_id: str):
             ]
         )
         count_res = qdrant.count(
-            collection_name="rice_chunks",
+            collection_name=store_collection(store_id),
             count_filter=count_filter,
             exact=False # Approximate count is faster
         )
         store_data["doc_count"] = count_res.count
     except Exception:
         store_data["doc_count"] = -1
+    store_data["stats"] = get_store_stats().get(store_id)
         
     return Store(**store_data)
 
-@router.delete("/{store_id}")
-async def delete_store(store_id: str):
+@router.get("/{store_id}/stats")
+async def get_store_stats_summary(
+    store_id: str,
+    response: Response,
+    if_none_match: Optional[str] = Header(None),
+    user: dict = Depends(get_current_user),
+):
+    """
+    Indexed files, chunks and bytes of a store, in total and per language.
+
+    Kept up to date by the indexer, so this never scans the index.
+    ``complete`` is false for stores indexed before the counts existed
+    until an admin rebuilds them (``POST /api/v1/admin/public/stores/stats/rebuild``).
+    """
+    if store_id not in get_admin_store().get_stores():
+        raise HTTPException(status_code=404, detail="Store not found")
+    authorize_store(user, store_id, store_acl.READ)
+
+    not_modified = conditional(response, if_none_match, "store-stats", store_id, index_version(store_id))
+    if not_modified:
+        return not_modified
+    return get_store_stats().get(store_id)
+
+@router.post("/{store_id}/upload", status_code=202)
+@idempotent("store.upload")
+async def upload_to_store(
+    store_id: str,
+    response: Response,
+    files: List[UploadFile] = File(...),
+    idempotency_key: Optional[str] = Header(None),
+    user: dict = Depends(get_current_user),
+):
+    """
+    Queue a batch of files for indexing (the dashboard's quick-index upload).
+
+    Each part's filename is the path stored with the file (e.g. its path
+    relative to a picked directory). Larger sets are sent as several
+    batches of at most ``indexing.upload.max_files`` files and
+    ``indexing.upload.max_batch_mb`` MB (the request body limit). Paths
+    excluded by the store's policy are skipped; the worker checks size and
+    content (binaries) as for any upload. Progress per file comes from
+    ``GET /api/v1/admin/public/jobs/{task_id}``.
+    """
+    from src.api.v1.endpoints.ingest import queue_upload
+    from src.services.ingestion.pipeline import check_admission
+
+    if store_id not in get_admin_store().get_stores():
+        raise HTTPException(status_code=404, detail="Store not found")
+    authorize_store(user, store_id, store_acl.WRITE)
+
+    max_files = int(settings.get("indexing.upload.max_files", 100))
+    if len(files) > max_files:
+        raise HTTPException(status_code=413, detail=f"At most {max_files} files per upload batch")
+
+    # Backpressure is checked once so a batch is queued whole or not at all
+    retry_after = await asyncio.to_thread(check_admission)
+    if retry_after is not None:
+        raise AppError(
+            status_code=429,
+            detail=f"Index queue is full, retry in {retry_after}s",
+            code=QUEUE_FULL,
+            headers={"Retry-After": str(retry_after)},
+        )
+
+    results = []
+    for upload in files:
+        path = upload.filename or "unknown"
+        try:
+            results.append(await queue_upload(upload.file, path, store_id, user, admit=False))
+        except HTTPException as e:
+            results.append({"status": "error", "file": path, "error": e.detail})
+    counts = {status: sum(1 for r in results if r["status"] == status) for status in ("queued", "excluded", "error")}
+    return {"store": store_id, **counts, "files": results}
+
+class GitIngestRequest(BaseModel):
+    url: str = Field(..., description="https URL of the repository")
+    branch: Optional[str] = Field(None, description="Branch to index (default: the remote's default branch)")
+    token: Optional[str] = Field(None, description="Access token for private repositories (not stored)")
+    full: bool = Field(False, description="Index every file even if an earlier commit was indexed")
+
+
+@router.post("/{store_id}/ingest/git", status_code=202)
+@idempotent("store.ingest_git")
+async def ingest_git_repository(
+    store_id: str,
+    request: GitIngestRequest,
+    response: Response,
+    idempotency_key: Optional[str] = Header(None),
+    user: dict = Depends(get_current_user),
+):
+    """
+    Clone a git repository on the worker and index it into the store.
+
+    The first run indexes every supported file; later runs for the same URL
+    and branch only index what changed since the recorded commit. Progress
+    comes from ``GET /api/v1/admin/public/jobs/{task_id}``; the store's
+    ``git`` field holds the indexed commit and the last run's outcome.
+    """
+    from uuid import uuid4
+    from src.services.ingestion.git_source import GitIngestBusy, GitIngestError, get_git_ingestion
+    from src.worker.celery_app import app as celery_app
+
+    if store_id not in get_admin_store().get_stores():
+        raise HTTPException(status_code=404, detail="Store not found")
+    authorize_store(user, store_id, store_acl.WRITE)
+    if not settings.get("indexing.git.enabled", True):
+        raise HTTPException(status_code=403, detail="Git ingestion is disabled (indexing.git.enabled)")
+
+    task_id = str(uuid4())
+    ingestion = get_git_ingestion()
+    try:
+        plan = await asyncio.to_thread(
+            ingestion.start, store_id, request.url, task_id,
+            branch=request.branch, token=request.token, full=request.full
+        )
+    except GitIngestBusy as e:
+        raise HTTPException(status_code=409, detail=str(e))
+    except GitIngestError as e:
+        raise HTTPException(status_code=400, detail=str(e))
+
+    try:
+        celery_app.send_task("src.tasks.ingestion.git_ingest_task", kwargs={"store_id": store_id}, task_id=task_id)
+    except Exception as e:
+        await asyncio.to_thread(ingestion.fail, store_id, f"Could not queue: {e}")
+        raise HTTPException(status_code=503, detail=f"Could not queue git ingest: {e}")
+
+    get_admin_store().log_audit(
+        "store_git_ingest",
+        f"Git ingest of {plan['repository']} into {store_id} queued ({plan['mode']})",
+        user.get("id", "admin")
+    )
+    return {"status": "queued", "task_id": task_id, "store": store_id, **plan}
+
+@router.post("/{store_id}/ingest/archive", status_code=202)
+@idempotent("store.ingest_archive")
+async def ingest_archive_upload(
+    store_id: str,
+    response: Response,
+    file: UploadFile = File(...),
+    prefix: Optional[str] = Form(None),
+    idempotency_key: Optional[str] = Header(None),
+    user: dict = Depends(get_current_user),
+):
+    """
+    Index a zip, tar or tar.gz upload (e.g. a CI build artifact) as one batch.
+
+    The worker extracts the supported files with path traversal and size
+    checks and indexes them as ``<prefix>/<path in archive>``. Progress
+    comes from ``GET /api/v1/admin/public/jobs/{task_id}``.
+    """
+    import os
+    from uuid import uuid4
+    from src.services.admin.usage import record_usage, start_usage
+    from src.services.ingestion.archive import MB, ArchiveError, archive_format, normalize_prefix
+    from src.services.ingestion.pipeline import check_admission
+    from src.worker.celery_app import app as celery_app
+
+    if store_id not in get_admin_store().get_stores():
+        raise HTTPException(status_code=404, detail="Store not found")
+    authorize_store(user, store_id, store_acl.WRITE)
+    try:
+        prefix = normalize_prefix(prefix)
+    except ArchiveError as e:
+        raise HTTPException(status_code=400, detail=str(e))
+    if archive_format(await file.read(512)) is None:
+        raise HTTPException(status_code=400, detail="Not a zip, tar or tar.gz archive")
+    await file.seek(0)
+
+    retry_after = await asyncio.to_thread(check_admission)
+    if retry_after is not None:
+        raise AppError(
+            status_code=429,
+            detail=f"Index queue is full, retry in {retry_after}s",
+            code=QUEUE_FULL,
+            headers={"Retry-After": str(retry_after)},
+        )
+
+    # Use shared temp dir accessible by both API and Worker
+    base_tmp = os.getenv("SHARED_TMP_DIR", "/tmp/ingest")
+    os.makedirs(base_tmp, exist_ok=True)
+    archive_path = os.path.join(base_tmp, f"{uuid4()}.archive")
+    max_bytes = int(settings.get("indexing.archive.max_upload_mb", 512)) * MB
+
+    def save() -> Optional[int]:
+        """Copy the upload, stopping (None) once it passes the limit."""
+        size = 0
+        with open(archive_path, "wb") as out:
+            while block := file.file.read(MB):
+                size += len(block)
+                if size > max_bytes:
+                    return None
+                out.write(block)
+        return size
+
+    size = await asyncio.to_thread(save)
+    if size is None:
+        os.remove(archive_path)
+        raise HTTPException(status_code=413, detail=f"Archive exceeds {max_bytes // MB} MB (indexing.archive.max_upload_mb)")
+
+    task_id = str(uuid4())
+    try:
+        celery_app.send_task(
+            "src.tasks.ingestion.archive_ingest_task",
+            kwargs={"archive_path": archive_path, "store_id": store_id, "prefix": prefix, "filename": file.filename},
+            task_id=task_id
+        )
+    except Exception as e:
+        os.remove(archive_path)
+        raise HTTPException(status_code=503, detail=f"Could not queue archive ingest: {e}")
+
+    start_usage(user_key(user), store_id)
+    record_usage("storage_bytes", size)
+    return {"status": "queued", "task_id": task_id, "store": store_id, "file": file.filename, "bytes": size, "prefix": prefix}
+
+@router.get("/{store_id}/symbols")
+async def list_store_symbols(
+    store_id: str,
+    prefix: str = Query("", description="Typed text to complete"),
+    limit: int = Query(20, ge=1, le=200, description="Maximum suggestions"),
+    user: dict = Depends(get_current_user),
+):
+    """
+    Autocomplete function/class names indexed in a store.
+
+    Use the suggestions as ``symbol:Name`` search filters.
+    """
+    from src.services.search.symbols import get_symbol_suggester
+
+    if store_id not in get_admin_store().get_stores():
+        raise HTTPException(status_code=404, detail="Store not found")
+    authorize_store(user, store_id, store_acl.READ)
+    try:
+        symbols = get_symbol_suggester().suggest(store_id, prefix, limit)
+    except Exception as e:
+        raise HTTPException(status_code=503, detail=f"Symbol lookup failed: {e}")
+    return {"store": store_id, "prefix": prefix, "symbols": symbols}
+
+@router.get("/{store_id}/suggest")
+async def suggest_queries(
+    store_id: str,
+    q: str = Query(..., description="Query as typed so far"),
+    limit: int = Query(10, ge=1, le=50, description="Maximum completions"),
+    user: dict = Depends(get_current_user),
+):
+    """
+    Search box suggestions from the store's vocabulary.
+
+    ``completions`` finish the last word (known terms, most used first,
+    then symbol names), each with the completed ``query``.
+    ``did_you_mean`` is the query with misspelled words corrected, or
+    None. Filter tokens such as ``lang:go`` are neither completed nor
+    corrected.
+    """
+    from src.services.search.suggestions import get_query_suggester
+    from src.services.search.symbols import get_symbol_suggester
+
+    if store_id not in get_admin_store().get_stores():
+        raise HTTPException(status_code=404, detail="Store not found")
+    authorize_store(user, store_id, store_acl.READ)
+    if not settings.get("search.suggest.enabled", True):
+        return {"store": store_id, "query": q, "completions": [], "did_you_mean": None, "corrections": []}
+
+    suggester = get_query_suggester()
+    head, _, last = q.rpartition(" ")
+    completable = bool(last) and ":" not in last and not last.startswith(("-", '"'))
+    completions = []
+    try:
+        if completable:
+            completions = await asyncio.to_thread(suggester.complete, store_id, last, limit)
+        correction = await asyncio.to_thread(suggester.correct, store_id, q)
+    except Exception as e:
+        raise HTTPException(status_code=503, detail=f"Suggestion lookup failed: {e}")
+    if completable and len(completions) < limit:
+        try:
+            symbols = await asyncio.to_thread(
+                get_symbol_suggester().suggest, store_id, last, limit - len(completions)
+            )
+            completions += [{"text": s["symbol"], "kind": "symbol", "count": s["chunks"]} for s in symbols]
+        except Exception as e:
+            # Terms are enough to answer with; symbols come from Qdrant
+            logger.debug(f"Symbol completions for {store_id} skipped: {e}")
+    for completion in completions:
+        completion["query"] = f"{head} {completion['text']}".lstrip()
+    return {
+        "store": store_id,
+        "query": q,
+        "completions": completions,
+        "did_you_mean": correction["suggestion"],
+        "corrections": correction["corrections"],
+    }
+
+
+@router.post("/{store_id}/suggest/rebuild", status_code=202, dependencies=[Depends(requires_role("admin"))])
+async def rebuild_suggestions(store_id: str):
+    """
+    Recount the store's suggestion vocabulary on the worker (for stores
+    indexed before suggestions existed, or after large deletes).
+    """
+    from uuid import uuid4
+    from src.worker.celery_app import app as celery_app
+
+    admin_store = get_admin_store()
+    if store_id not in admin_store.get_stores():
+        raise HTTPException(status_code=404, detail="Store not found")
+
+    task_id = str(uuid4())
+    try:
+        celery_app.send_task(
+            "src.tasks.maintenance.suggest_vocabulary_task",
+            kwargs={"store_id": store_id},
+            task_id=task_id
+        )
+    except Exception as e:
+        raise HTTPException(status_code=503, detail=f"Could not queue vocabulary rebuild: {e}")
+
+    admin_store.log_audit("store_suggest", f"Suggestion vocabulary rebuild for {store_id} queued", "admin")
+    return {"status": "queued", "task_id": task_id}
+
+@router.get("/{store_id}/models")
+async def get_store_models(
+    store_id: str,
+    user: dict = Depends(get_current_user),
+):
+    """
+    Models a search of this store can pick per request.
+
+    ``embedding`` lists the models the store has vectors for (``model_id``
+    on searches and ``store`` + ``model`` on /embeddings); ``rerank`` the
+    rerankers for ``rerank_model_id``. Either a model's ``id`` or its name
+    works.
+    """
+    from src.services.search.model_overrides import available_models
+
+    if store_id not in get_admin_store().get_stores():
+        raise HTTPException(status_code=404, detail="Store not found")
+    authorize_store(user, store_id, store_acl.READ)
+    return available_models(store_id)
+
+@router.get("/{store_id}/inspect")
+async def inspect_store_file(
+    store_id: str,
+    path: str = Query(..., description="Path the file was indexed under (full_path)"),
+    user: dict = Depends(get_current_user),
+):
     """
-    Delete a store configuration. 
-    Note: Does NOT delete indexed data for safety.
+    A file's chunks as indexed: line ranges, chunk type, symbols, token
+    estimates and per-vector size and norm (``rice-search inspect``).
     """
+    from src.services.search.inspection import get_search_inspector
+
+    if store_id not in get_admin_store().get_stores():
+        raise HTTPException(status_code=404, detail="Store not found")
+    authorize_store(user, store_id, store_acl.READ)
+    try:
+        report = await asyncio.to_thread(get_search_inspector().inspect_file, store_id, path)
+    except Exception as e:
+        raise HTTPException(status_code=503, detail=f"Inspection failed: {e}")
+    if not report["chunks"]:
+        raise HTTPException(status_code=404, detail=f"File not indexed: {path}")
+    return report
+
+@router.get("/{store_id}/export/vectors")
+def export_store_vectors(
+    store_id: str,
+    format: Literal["ndjson", "arrow"] = Query("ndjson", description="ndjson or arrow (Arrow IPC stream)"),
+    vectors: str = Query("dense,splade,bm42", description="Comma-separated vector names; empty for none"),
+    include_payload: bool = Query(True, description="Include chunk payload (text, path, metadata)"),
+    limit: Optional[int] = Query(None, ge=1, description="Stop after this many chunks"),
+    user: dict = Depends(requires_role("member"))
+):
+    """
+    Stream a store's chunk ids, payloads and vectors.
+
+    For offline analysis and building training data. Members may export
+    their own organization's store and stores whose ACL names them; admins
+    any store. Rate limited per user (``exports.rate_limit``).
+    """
+    from src.services.admin.rate_limit import get_rate_limiter
+    from src.services.admin.vector_export import VECTOR_NAMES, arrow_available, get_vector_exporter
+
     admin_store = get_admin_store()
-    if admin_store.delete_store(store_id):
-        return {"status": "success", "message": f"Store {store_id} deleted"}
+    stores = admin_store.get_stores()
+    if user.get("role") != "admin" and user.get("org_id") != store_id and not store_acl.store_acl(store_id, stores):
+        raise HTTPException(status_code=403, detail="Not allowed to export this store")
+    if store_id not in stores:
+        raise HTTPException(status_code=404, detail="Store not found")
+    authorize_store(user, store_id, store_acl.READ)
+
+    names = [v.strip() for v in vectors.split(",") if v.strip()]
+    unknown = [v for v in names if v not in VECTOR_NAMES]
+    if unknown:
+        raise HTTPException(
+            status_code=400,
+            detail=f"Unknown vectors {unknown}; expected any of {list(VECTOR_NAMES)}"
+        )
+    if format == "arrow" and not arrow_available():
+        raise HTTPException(status_code=501, detail="Arrow export requires pyarrow on the server")
+
+    allowed, retry_after = get_rate_limiter().hit(
+        f"export:{user.get('id', 'anonymous')}",
+        int(settings.get("exports.rate_limit.requests", 10)),
+        int(settings.get("exports.rate_limit.window_seconds", 3600))
+    )
+    if not allowed:
+        raise HTTPException(
+            status_code=429,
+            detail="Export rate limit exceeded",
+            headers={"Retry-After": str(retry_after)}
+        )
+
+    admin_store.log_audit(
+        "vectors_exported",
+        f"Vector export of store {store_id} ({format}, vectors={','.join(names) or 'none'}) by {user.get('id')}",
+        user.get("id", "admin")
+    )
+
+    exporter = get_vector_exporter()
+    if format == "arrow":
+        body = exporter.arrow(store_id, names, include_payload, limit)
+        media_type = "application/vnd.apache.arrow.stream"
+        extension = "arrows"
     else:
+        body = exporter.ndjson(store_id, names, include_payload, limit)
+        media_type = "application/x-ndjson"
+        extension = "ndjson"
+    return StreamingResponse(
+        body,
+        media_type=media_type,
+        headers={"Content-Disposition": f'attachment; filename="{store_id}-vectors.{extension}"'}
+    )
+
+@router.get("/{store_id}/metrics/stream")
+async def stream_store_metrics(store_id: str, user: dict = Depends(requires_role("viewer"))):
+    """
+    Live store metrics as Server-Sent Events.
+
+    Sends a ``metrics`` event every ``stores.live_metrics.interval_seconds``
+    (search rate and latency over the last window, index outcome counts,
+    recent index events) and an ``index`` event for each indexing event of
+    the store as it happens. Built from the event bus.
+    """
+    from src.services.admin.store_metrics import METRIC_TOPICS, StoreMetricsWindow
+
+    admin_store = get_admin_store()
+    stores = admin_store.get_stores()
+    if user.get("role") != "admin" and user.get("org_id") != store_id and not store_acl.store_acl(store_id, stores):
+        raise HTTPException(status_code=403, detail="Not allowed to view this store")
+    if store_id not in stores:
         raise HTTPException(status_code=404, detail="Store not found")
+    authorize_store(user, store_id, store_acl.READ)
+
+    bus = get_event_bus()
+    window = StoreMetricsWindow(store_id, int(settings.get("stores.live_metrics.window_seconds", 60)))
+    interval_ms = int(float(settings.get("stores.live_metrics.interval_seconds", 2)) * 1000)
+
+    async def generate():
+        yield "retry: 3000\n\n"
+        try:
+            window.seed(await asyncio.to_thread(bus.recent, METRIC_TOPICS, 500))
+        except Exception as e:
+            logger.warning(f"Could not load recent events for {store_id}: {e}")
+        last_id = "$"
+        while True:
+            usage = await asyncio.to_thread(admin_store.get_store_usage)
+            snapshot = window.snapshot(total_searches=usage.get(store_id, {}).get("search_count", 0))
+            yield f"event: metrics\ndata: {json.dumps(snapshot, default=str)}\n\n"
+            try:
+                last_id, events = await asyncio.to_thread(bus.read, last_id, METRIC_TOPICS, interval_ms)
+            except Exception as e:
+                logger.warning(f"Store metrics stream read failed: {e}")
+                yield f"event: error\ndata: {json.dumps({'error': 'Event bus unavailable'})}\n\n"
+                await asyncio.sleep(5)
+                continue
+            for event in events:
+                if window.add(event) and event["topic"].startswith("index."):
+                    yield f"event: index\ndata: {json.dumps(event, default=str)}\n\n"
+
+    return StreamingResponse(
+        generate(),
+        media_type="text/event-stream",
+        headers={"Cache-Control": "no-cache", "X-Accel-Buffering": "no"},
+    )
+
+@router.delete("/{store_id}")
+@idempotent("store.delete")
+async def delete_store(
+    store_id: str,
+    response: Response,
+    idempotency_key: Optional[str] = Header(None),
+    user: dict = Depends(get_current_user),
+):
+    """
+    Move a store to the trash.
+
+    The store disappears from listings and search, but its index is kept
+    until ``purge_after`` (``stores.trash.retention_days``) so it can be
+    restored (``POST /trash/{store_id}/restore``) or purged sooner
+    (``DELETE /trash/{store_id}``).
+    Stores with an ACL can only be deleted by their owner (or an admin).
+    """
+    authorize_store(user, store_id, store_acl.OWNER)
+    record = store_trash.trash_store(store_id, user.get("id", "system"))
+    if record is None:
+        raise HTTPException(status_code=404, detail="Store not found")
+    emit("store.deleted", store_id=store_id)
+    return {
+        "status": "success",
+        "message": f"Store {store_id} moved to trash",
+        "purge_after": record["purge_after"],
+    }
+
+
+def _trashed_store(store_id: str, user: dict) -> Dict:
+    """A trashed store the caller owns (404 if not in the trash, 403 if not theirs)."""
+    trash = get_admin_store().get_trashed_stores()
+    if store_id not in trash:
+        raise HTTPException(status_code=404, detail="Store not in trash")
+    if not store_acl.can_access(user, store_id, store_acl.OWNER, stores=trash):
+        raise HTTPException(status_code=403, detail=f"Not allowed to manage store '{store_id}'")
+    return trash[store_id]
+
+
+@router.post("/trash/{store_id}/restore", response_model=Store)
+async def restore_store(store_id: str, user: dict = Depends(get_current_user)):
+    """Bring a store back from the trash with its index (409 if the id was reused)."""
+    _trashed_store(store_id, user)
+    try:
+        restored = get_admin_store().restore_store(store_id, user.get("id", "system"))
+    except ValueError as e:
+        raise AppError(409, str(e))
+    if restored is None:
+        raise HTTPException(status_code=404, detail="Store not in trash")
+    invalidate_store(store_id)
+    emit("store.restored", store_id=store_id)
+    return Store(**restored)
+
+
+@router.delete("/trash/{store_id}")
+@idempotent("store.purge")
+async def purge_store(
+    store_id: str,
+    response: Response,
+    idempotency_key: Optional[str] = Header(None),
+    user: dict = Depends(get_current_user),
+):
+    """Permanently delete a trashed store and its index, without waiting for ``purge_after``."""
+    _trashed_store(store_id, user)
+    if not await asyncio.to_thread(store_trash.purge_store, store_id, user.get("id", "system")):
+        raise HTTPException(status_code=404, detail="Store not in trash")
+    emit("store.purged", store_id=store_id)
+    return {"status": "success", "message": f"Store {store_id} purged"}
+
+
+@router.delete("/{store_id}/index", status_code=202, dependencies=[Depends(requires_role("admin"))])
+@idempotent("store.delete_index")
+async def delete_store_index(
+    store_id: str,
+    response: Response,
+    connection_id: str = Query(..., description="Remove chunks uploaded by this connection"),
+    idempotency_key: Optional[str] = Header(None),
+    user: dict = Depends(get_current_user),
+):
+    """
+    Remove all chunks a connection contributed to this store.
+
+    Used to purge content indexed from a device that should no longer be
+    represented in a shared store (e.g. private branches on a departed
+    employee's laptop). Runs on the worker; returns the task id.
+    """
+    from src.worker.celery_app import app as celery_app
+
+    admin_store = get_admin_store()
+    if store_id not in admin_store.get_stores():
+        raise HTTPException(status_code=404, detail="Store not found")
+
+    # Approximate size so the caller knows what was queued
+    try:
+        count_res = get_qdrant_client().count(
+            collection_name=store_collection(store_id),
+            count_filter=Filter(
+                must=[
+                    FieldCondition(key="org_id", match=MatchValue(value=store_id)),
+                    FieldCondition(key="connection_id", match=MatchValue(value=connection_id))
+                ]
+            ),
+            exact=False
+        )
+        matched = count_res.count
+    except Exception:
+        matched = -1
+
+    task = celery_app.send_task(
+        "src.tasks.ingestion.delete_connection_chunks_task",
+        kwargs={"connection_id": connection_id, "org_id": store_id}
+    )
+    admin_store.log_audit(
+        "connection_chunks_delete",
+        f"Delete of connection {connection_id} chunks from store {store_id} queued",
+        "admin"
+    )
+    return {"status": "queued", "task_id": str(task.id), "matched_chunks": matched}
+
+
+def _validated_acl(update: StoreACL, user: dict) -> Dict:
+    """Normalized ACL from a request (400 on a bad principal)."""
+    owner = update.owner or store_acl.user_principal(user)
+    if not owner:
+        raise HTTPException(status_code=400, detail="owner is required")
+    try:
+        return store_acl.normalize_acl(owner, update.readers, update.writers)
+    except store_acl.InvalidACL as e:
+        raise HTTPException(status_code=400, detail=str(e))
+
+
+@router.get("/{store_id}/acl")
+async def get_store_acl(store_id: str, user: dict = Depends(get_current_user)):
+    """The store's ACL (None: open) and what the caller may do with the store."""
+    stores = get_admin_store().get_stores()
+    if store_id not in stores:
+        raise HTTPException(status_code=404, detail="Store not found")
+    authorize_store(user, store_id, store_acl.READ)
+    acl = store_acl.store_acl(store_id, stores)
+    return {"store": store_id, **store_acl.describe(acl, store_acl.principals(user), store_acl.is_admin(user))}
+
+
+@router.put("/{store_id}/acl")
+async def set_store_acl(store_id: str, update: StoreACL, user: dict = Depends(get_current_user)):
+    """
+    Restrict a store to an owner, readers and writers (owner or admin only).
+
+    Entries are ``key:<user id>`` or ``connection:<connection id>``; the
+    owner defaults to the caller. Handing the store to another owner is
+    allowed, after which only that owner (or an admin) can change the ACL.
+    An open store can only be restricted by an admin or a member of the
+    store's organization.
+    """
+    admin_store = get_admin_store()
+    stores = admin_store.get_stores()
+    if store_id not in stores:
+        raise HTTPException(status_code=404, detail="Store not found")
+    authorize_store(user, store_id, store_acl.OWNER)
+    if not store_acl.store_acl(store_id, stores) and not store_acl.can_claim(user, store_id):
+        raise HTTPException(
+            status_code=403,
+            detail=f"Only admins or members of organization '{store_id}' can restrict this store"
+        )
+
+    acl = _validated_acl(update, user)
+    if not admin_store.set_store(store_id, {**stores[store_id], "acl": acl}):
+        raise HTTPException(status_code=500, detail="Failed to update store")
+    # Cached results were computed for whoever asked
+    from src.services.search.query_cache import invalidate_store
+    invalidate_store(store_id)
+    admin_store.log_audit(
+        "store_acl",
+        f"ACL of store {store_id} set to {json.dumps(acl, sort_keys=True)}",
+        user.get("id", "admin")
+    )
+    emit("store.acl.updated", store_id=store_id, restricted=True)
+    return {"store": store_id, **store_acl.describe(acl, store_acl.principals(user), store_acl.is_admin(user))}
+
+
+@router.delete("/{store_id}/acl")
+async def delete_store_acl(store_id: str, user: dict = Depends(get_current_user)):
+    """Remove the store's ACL, opening it again (owner or admin only)."""
+    admin_store = get_admin_store()
+    stores = admin_store.get_stores()
+    if store_id not in stores:
+        raise HTTPException(status_code=404, detail="Store not found")
+    authorize_store(user, store_id, store_acl.OWNER)
+
+    updated = {k: v for k, v in stores[store_id].items() if k != "acl"}
+    if not admin_store.set_store(store_id, updated):
+        raise HTTPException(status_code=500, detail="Failed to update store")
+    admin_store.log_audit("store_acl", f"ACL of store {store_id} removed", user.get("id", "admin"))
+    emit("store.acl.updated", store_id=store_id, restricted=False)
+    return {"store": store_id, **store_acl.describe(None, store_acl.principals(user), store_acl.is_admin(user))}
+
+
+@router.get("/{store_id}/migration")
+async def get_store_migration_status(store_id: str):
+    """
+    Whether the store's vectors match the default embedding model, and the
+    state of its last migration.
+    """
+    from src.services.ingestion.migration import embedding_status
+
+    if store_id not in get_admin_store().get_stores():
+        raise HTTPException(status_code=404, detail="Store not found")
+    return await asyncio.to_thread(embedding_status, store_id, get_qdrant_client())
+
+
+@router.post("/{store_id}/migrate", status_code=202, dependencies=[Depends(requires_role("admin"))])
+async def migrate_store(store_id: str):
+    """
+    Re-embed the store's chunks with the default embedding model.
+
+    Chunks are re-embedded from their stored text into a new collection on
+    the worker; the store switches to it when done. Track progress with
+    ``GET /api/v1/admin/public/jobs/{task_id}``.
+    """
+    from uuid import uuid4
+    from src.services.ingestion.migration import FAILED, get_store_migration
+    from src.worker.celery_app import app as celery_app
+
+    admin_store = get_admin_store()
+    if store_id not in admin_store.get_stores():
+        raise HTTPException(status_code=404, detail="Store not found")
+
+    task_id = str(uuid4())
+    migration = get_store_migration()
+    try:
+        plan = await asyncio.to_thread(migration.start, store_id, task_id)
+    except ValueError as e:
+        raise HTTPException(status_code=400, detail=str(e))
+
+    try:
+        celery_app.send_task(
+            "src.tasks.ingestion.migrate_store_task",
+            kwargs={"store_id": store_id},
+            task_id=task_id
+        )
+    except Exception as e:
+        store = admin_store.get_stores()[store_id]
+        admin_store.set_store(store_id, {**store, "migration": {**store["migration"], "state": FAILED, "error": str(e)}})
+        raise HTTPException(status_code=503, detail=f"Could not queue migration: {e}")
+
+    admin_store.log_audit(
+        "store_migration",
+        f"Migration of {store_id} to {plan['model']} queued ({plan['total']} chunks)",
+        "admin"
+    )
+    return {"status": "queued", "task_id": task_id, **plan}
+
+
+@router.get("/{store_id}/duplicates")
+async def get_duplicate_report(store_id: str, user: dict = Depends(get_current_user)):
+    """
+    The store's last near-duplicate code report: clusters of similar
+    chunks across files and the file pairs sharing them (``state`` is
+    None until one is built with ``POST /{store_id}/duplicates``).
+    """
+    from src.services.admin.duplicates import get_duplicate_reports
+
+    if store_id not in get_admin_store().get_stores():
+        raise HTTPException(status_code=404, detail="Store not found")
+    authorize_store(user, store_id, store_acl.READ)
+    return await asyncio.to_thread(get_duplicate_reports().get, store_id)
+
+
+@router.post("/{store_id}/duplicates", status_code=202, dependencies=[Depends(requires_role("admin"))])
+async def build_duplicate_report(store_id: str):
+    """
+    Queue a near-duplicate scan of the store on the worker. The previous
+    report stays readable until it finishes.
+    """
+    from uuid import uuid4
+    from src.services.admin.duplicates import FAILED, get_duplicate_reports
+    from src.worker.celery_app import app as celery_app
+
+    admin_store = get_admin_store()
+    if store_id not in admin_store.get_stores():
+        raise HTTPException(status_code=404, detail="Store not found")
+
+    task_id = str(uuid4())
+    reports = get_duplicate_reports()
+    try:
+        report = await asyncio.to_thread(reports.start, store_id, task_id)
+    except ValueError as e:
+        raise AppError(409, str(e), FAILED_PRECONDITION)
+
+    try:
+        celery_app.send_task(
+            "src.tasks.maintenance.duplicate_report_task",
+            kwargs={"store_id": store_id},
+            task_id=task_id
+        )
+    except Exception as e:
+        reports._save(store_id, {**report, "state": FAILED, "error": str(e)})
+        raise HTTPException(status_code=503, detail=f"Could not queue duplicate report: {e}")
+
+    admin_store.log_audit("store_duplicates", f"Duplicate report for {store_id} queued", "admin")
+    return {"status": "queued", "task_id": task_id}
+
+
+@router.get("/{store_id}/collection-config", dependencies=[Depends(requires_role("admin"))])
+async def get_collection_config(store_id: str):
+    """The store's collection config and its collection's live settings."""
+    from src.services.ingestion.collection_config import live_config
+
+    stores = get_admin_store().get_stores()
+    if store_id not in stores:
+        raise HTTPException(status_code=404, detail="Store not found")
+    collection = store_collection(store_id)
+    return {
+        "store": store_id,
+        "collection": collection,
+        "shared": collection == settings.COLLECTION_PREFIX,
+        "collection_config": stores[store_id].get("collection_config") or {},
+        "live": await asyncio.to_thread(live_config, get_qdrant_client(), collection),
+    }
+
+
+@router.put("/{store_id}/collection-config", dependencies=[Depends(requires_role("admin"))])
+async def update_collection_config(store_id: str, update: CollectionConfig):
+    """
+    Change a store's shard, replication, on-disk payload or HNSW settings.
+
+    Applied to the store's own collection through Qdrant's collection update
+    (HNSW changes rebuild the index in the background). Shard count changes,
+    and any change for a store on the shared collection, are saved and take
+    effect when the store is migrated to a new collection.
+    """
+    from src.services.ingestion.collection_config import update_collection
+
+    admin_store = get_admin_store()
+    stores = admin_store.get_stores()
+    if store_id not in stores:
+        raise HTTPException(status_code=404, detail="Store not found")
+    changes = update.dict(exclude_none=True)
+    if not changes:
+        raise HTTPException(status_code=400, detail="No settings given")
+
+    collection = store_collection(store_id)
+    shared = collection == settings.COLLECTION_PREFIX
+    applied = []
+    if not shared:
+        try:
+            applied = await asyncio.to_thread(update_collection, get_qdrant_client(), collection, changes)
+        except Exception as e:
+            raise HTTPException(status_code=502, detail=f"Qdrant rejected the update: {e}")
+    pending = [k for k in changes if k not in applied]
+
+    config = {**(stores[store_id].get("collection_config") or {}), **changes}
+    if not admin_store.set_store(store_id, {**stores[store_id], "collection_config": config}):
+        raise HTTPException(status_code=500, detail="Failed to update store")
+    admin_store.log_audit(
+        "store_collection_config",
+        f"Collection config of store {store_id} set to {changes} (applied: {applied or 'none'})",
+        "admin"
+    )
+    return {
+        "store": store_id,
+        "collection": collection,
+        "collection_config": config,
+        "applied": applied,
+        "pending": pending,
+        "migration_required": bool(pending),
+    }
+
+
+class SparseBackendUpdate(BaseModel):
+    sparse_backend: Literal["splade", "bm25"]
+
+
+@router.put("/{store_id}/sparse-backend", dependencies=[Depends(requires_role("admin"))])
+async def set_sparse_backend(store_id: str, update: SparseBackendUpdate):
+    """
+    Switch a store between the SPLADE and BM25 sparse backends.
+
+    Only files indexed after the switch get the new representation, so
+    re-index the store afterwards. Switching to SPLADE drops the store's
+    BM25 inverted index.
+    """
+    from src.services.retrieval.bm25_index import SPLADE, get_bm25_index
+
+    admin_store = get_admin_store()
+    stores = admin_store.get_stores()
+    if store_id not in stores:
+        raise HTTPException(status_code=404, detail="Store not found")
+
+    previous = stores[store_id].get("sparse_backend")
+    if not admin_store.set_store(store_id, {**stores[store_id], "sparse_backend": update.sparse_backend}):
+        raise HTTPException(status_code=500, detail="Failed to update store")
+
+    if update.sparse_backend == SPLADE and previous != SPLADE:
+        get_bm25_index().clear(store_id)
+
+    return {
+        "store": store_id,
+        "sparse_backend": update.sparse_backend,
+        "previous": previous,
+        "reindex_required": previous != update.sparse_backend,
+    }
+
+
+class PrivacyUpdate(BaseModel):
+    privacy_mode: bool
+
+
+@router.put("/{store_id}/privacy", dependencies=[Depends(requires_role("admin"))])
+async def set_privacy_mode(store_id: str, update: PrivacyUpdate):
+    """
+    Turn a store's privacy mode on or off.
+
+    Turning it on removes the text already stored for the store's chunks
+    (search keeps working from vectors; results carry paths and lines).
+    Turning it off only affects files indexed afterwards, so re-index the
+    store to get content back.
+    """
+    from src.services.ingestion.privacy import purge_store_content
+    from src.services.search.query_cache import invalidate_store
+
+    admin_store = get_admin_store()
+    stores = admin_store.get_stores()
+    if store_id not in stores:
+        raise HTTPException(status_code=404, detail="Store not found")
+
+    previous = bool(stores[store_id].get("privacy_mode"))
+    if not admin_store.set_store(store_id, {**stores[store_id], "privacy_mode": update.privacy_mode}):
+        raise HTTPException(status_code=500, detail="Failed to update store")
+    if update.privacy_mode and not previous:
+        await asyncio.to_thread(purge_store_content, get_qdrant_client(), store_id)
+        # Cached results still carry text
+        invalidate_store(store_id)
+    admin_store.log_audit(
+        "store_privacy",
+        f"Privacy mode {'enabled' if update.privacy_mode else 'disabled'} for store {store_id}"
+    )
+    return {
+        "store": store_id,
+        "privacy_mode": update.privacy_mode,
+        "previous": previous,
+        "reindex_required": previous and not update.privacy_mode,
+    }
+
+
+class ExclusionUpdate(BaseModel):
+    # Unset fields follow indexing.exclusion
+    exclude_paths: Optional[List[str]] = None
+    max_file_bytes: Optional[int] = Field(None, ge=0)
+    binary: Optional[bool] = None
+    content_markers: Optional[List[str]] = None
+    marker_bytes: Optional[int] = Field(None, ge=1)
+    generated: Optional[Literal["skip", "mark", "off"]] = None
+
+
+@router.get("/{store_id}/exclusion", dependencies=[Depends(requires_role("admin"))])
+async def get_exclusion(store_id: str):
+    """The store's exclusion overrides and the policy the indexer applies."""
+    from src.services.ingestion.exclusion import get_exclusion_policy
+
+    stores = get_admin_store().get_stores()
+    if store_id not in stores:
+        raise HTTPException(status_code=404, detail="Store not found")
+    return {
+        "store": store_id,
+        "overrides": stores[store_id].get("exclusion") or {},
+        "effective": get_exclusion_policy(store_id).to_dict(),
+    }
+
+
+@router.put("/{store_id}/exclusion", dependencies=[Depends(requires_role("admin"))])
+async def set_exclusion(store_id: str, update: ExclusionUpdate):
+    """
+    Replace a store's exclusion overrides (an empty body restores the defaults).
+
+    The policy applies to uploads from now on. Indexed files it excludes
+    are removed on their next upload; the next CLI scan asks for them
+    again through the hash pre-flight for that reason.
+    """
+    from src.services.ingestion.exclusion import get_exclusion_policy
+
+    admin_store = get_admin_store()
+    stores = admin_store.get_stores()
+    if store_id not in stores:
+        raise HTTPException(status_code=404, detail="Store not found")
+
+    overrides = {k: v for k, v in update.dict().items() if v is not None}
+    if not admin_store.set_store(store_id, {**stores[store_id], "exclusion": overrides}):
+        raise HTTPException(status_code=500, detail="Failed to update store")
+    admin_store.log_audit(
+        "store_exclusion",
+        f"Exclusion policy for store {store_id} set to {json.dumps(overrides, sort_keys=True)}"
+    )
+    return {
+        "store": store_id,
+        "overrides": overrides,
+        "effective": get_exclusion_policy(store_id).to_dict(),
+    }
+
+
+class BoostRule(BaseModel):
+    type: Literal["path_prefix", "path_glob", "language", "query_language"]
+    # Path prefix, glob or language; unused for query_language
+    value: Optional[str] = None
+    # Score multiplier: >1 boosts, <1 demotes
+    factor: float
+
+
+class BoostsUpdate(BaseModel):
+    rules: List[BoostRule] = []
+
+
+@router.get("/{store_id}/boosts", dependencies=[Depends(requires_role("admin"))])
+async def get_boosts(store_id: str):
+    """The store's query-time boosting rules."""
+    stores = get_admin_store().get_stores()
+    if store_id not in stores:
+        raise HTTPException(status_code=404, detail="Store not found")
+    return {"store": store_id, "rules": stores[store_id].get("boosts") or []}
+
+
+@router.put("/{store_id}/boosts", dependencies=[Depends(requires_role("admin"))])
+async def set_boosts(store_id: str, update: BoostsUpdate):
+    """
+    Replace a store's boosting rules (an empty list removes them).
+
+    Rules scale fused scores at query time, so they apply to the next
+    search without reindexing.
+    """
+    from src.services.search.boosting import InvalidRule, normalize_rules
+
+    admin_store = get_admin_store()
+    stores = admin_store.get_stores()
+    if store_id not in stores:
+        raise HTTPException(status_code=404, detail="Store not found")
+    try:
+        rules = normalize_rules([rule.dict() for rule in update.rules])
+    except InvalidRule as e:
+        raise HTTPException(status_code=400, detail=str(e))
+
+    if not admin_store.set_store(store_id, {**stores[store_id], "boosts": rules}):
+        raise HTTPException(status_code=500, detail="Failed to update store")
+    # Cached results were ranked with the old rules
+    invalidate_store(store_id)
+    admin_store.log_audit(
+        "store_boosts",
+        f"Boosting rules for store {store_id} set to {json.dumps(rules, sort_keys=True)}"
+    )
+    return {"store": store_id, "rules": rules}
+
+
+class RecencyUpdate(BaseModel):
+    # Age at which a chunk gets half the boost; None turns recency off
+    half_life_days: Optional[float] = None
+    # Boost of a chunk indexed just now (1 + weight); default search.recency.weight
+    weight: Optional[float] = None
+
+
+@router.get("/{store_id}/recency", dependencies=[Depends(requires_role("admin"))])
+async def get_recency(store_id: str):
+    """The store's recency ranking config (None: off)."""
+    stores = get_admin_store().get_stores()
+    if store_id not in stores:
+        raise HTTPException(status_code=404, detail="Store not found")
+    return {"store": store_id, "recency": stores[store_id].get("recency")}
+
+
+@router.put("/{store_id}/recency", dependencies=[Depends(requires_role("admin"))])
+async def set_recency(store_id: str, update: RecencyUpdate):
+    """
+    Favour recently indexed chunks in a store's searches (no
+    ``half_life_days``: rank without regard to age).
+    """
+    from src.services.search.boosting import InvalidRule, normalize_recency
+
+    admin_store = get_admin_store()
+    stores = admin_store.get_stores()
+    if store_id not in stores:
+        raise HTTPException(status_code=404, detail="Store not found")
+    try:
+        recency = normalize_recency(update.half_life_days, update.weight)
+    except InvalidRule as e:
+        raise HTTPException(status_code=400, detail=str(e))
+
+    if not admin_store.set_store(store_id, {**stores[store_id], "recency": recency}):
+        raise HTTPException(status_code=500, detail="Failed to update store")
+    invalidate_store(store_id)
+    admin_store.log_audit("store_recency", f"Recency ranking for store {store_id} set to {json.dumps(recency)}")
+    return {"store": store_id, "recency": recency}
+
+
+class SummariesUpdate(BaseModel):
+    enabled: bool
+    # LLM calls in flight per file; default indexing.summaries.max_concurrency
+    max_concurrency: Optional[int] = Field(None, ge=1, le=32)
+
+
+@router.get("/{store_id}/summaries", dependencies=[Depends(requires_role("admin"))])
+async def get_summaries(store_id: str):
+    """The store's chunk summary settings and the LLM backends that write them."""
+    from src.services.ingestion.summaries import get_chunk_summarizer, store_summaries
+
+    stores = get_admin_store().get_stores()
+    if store_id not in stores:
+        raise HTTPException(status_code=404, detail="Store not found")
+    return {
+        "store": store_id,
+        "summaries": stores[store_id].get("summaries"),
+        "effective": store_summaries(store_id),
+        "backends": get_chunk_summarizer().llm.status(),
+    }
+
+
+@router.put("/{store_id}/summaries", dependencies=[Depends(requires_role("admin"))])
+async def set_summaries(store_id: str, update: SummariesUpdate):
+    """
+    Turn LLM chunk summaries on or off for a store.
+
+    Summaries are written when files are indexed, so re-index the store to
+    summarize (or drop the summaries of) files indexed before. Privacy-mode
+    stores are never summarized.
+    """
+    from src.services.ingestion.summaries import store_summaries
+
+    admin_store = get_admin_store()
+    stores = admin_store.get_stores()
+    if store_id not in stores:
+        raise HTTPException(status_code=404, detail="Store not found")
+    if update.enabled and stores[store_id].get("privacy_mode"):
+        raise HTTPException(status_code=400, detail="Privacy-mode stores can't be summarized")
+
+    summaries = {k: v for k, v in update.dict().items() if v is not None}
+    previous = bool(store_summaries(store_id)["enabled"])
+    if not admin_store.set_store(store_id, {**stores[store_id], "summaries": summaries}):
+        raise HTTPException(status_code=500, detail="Failed to update store")
+    admin_store.log_audit(
+        "store_summaries",
+        f"Chunk summaries {'enabled' if update.enabled else 'disabled'} for store {store_id}"
+    )
+    return {
+        "store": store_id,
+        "summaries": summaries,
+        "effective": store_summaries(store_id),
+        "reindex_required": previous != update.enabled,
+    }
+
+
+class ExperimentStart(BaseModel):
+    kind: Literal["embedding", "rerank"]
+    # Candidate model (variant B); variant A is the current model
+    model: str
+
+
+@router.get("/{store_id}/experiment", dependencies=[Depends(requires_role("admin"))])
+async def get_experiment(store_id: str):
+    """A store's A/B experiment and its backfill progress."""
+    from src.services.search.experiments import get_experiment_runner
+
+    stores = get_admin_store().get_stores()
+    if store_id not in stores:
+        raise HTTPException(status_code=404, detail="Store not found")
+    return {
+        "store": store_id,
+        "experiment": stores[store_id].get("experiment"),
+        "backfill": get_experiment_runner().backfills.get(store_id),
+    }
+
+
+@router.put("/{store_id}/experiment", dependencies=[Depends(requires_role("admin"))])
+async def start_experiment(store_id: str, request: ExperimentStart):
+    """
+    Compare a candidate embedding or rerank model against the current one.
+
+    Embedding experiments index chunks with both models from now on (run
+    ``POST /{store_id}/experiment/backfill`` for files already indexed).
+    Searches with ``experiment: true`` then return both variants.
+    """
+    from src.services.search.experiments import get_experiment_runner
+
+    admin_store = get_admin_store()
+    stores = admin_store.get_stores()
+    if store_id not in stores:
+        raise HTTPException(status_code=404, detail="Store not found")
+    try:
+        experiment = await asyncio.to_thread(get_experiment_runner().start, store_id, request.kind, request.model)
+    except ValueError as e:
+        raise HTTPException(status_code=400, detail=str(e))
+    except Exception as e:
+        raise HTTPException(status_code=502, detail=f"Could not load model {request.model}: {e}")
+
+    if not admin_store.set_store(store_id, {**stores[store_id], "experiment": experiment}):
+        raise HTTPException(status_code=500, detail="Failed to update store")
+    admin_store.log_audit(
+        "experiment_started",
+        f"{request.kind} experiment on {store_id}: {experiment['model_a']} vs {experiment['model_b']}"
+    )
+    return {"store": store_id, "experiment": experiment}
+
+
+@router.post("/{store_id}/experiment/backfill", status_code=202, dependencies=[Depends(requires_role("admin"))])
+async def backfill_experiment(store_id: str):
+    """Embed the store's already indexed chunks with the experiment model (background)."""
+    from src.core.supervisor import get_supervisor
+    from src.services.search.experiments import EMBEDDING, get_experiment_runner, get_store_experiment
+
+    experiment = get_store_experiment(store_id)
+    if not experiment or experiment.get("kind") != EMBEDDING:
+        raise HTTPException(status_code=400, detail="Store has no embedding experiment")
+    runner = get_experiment_runner()
+    if (runner.backfills.get(store_id) or {}).get("state") == "running":
+        raise HTTPException(status_code=409, detail="Backfill already running")
+    get_supervisor().spawn(f"experiment-backfill-{store_id}", runner.backfill, store_id, group="experiments")
+    return {"store": store_id, "status": "started"}
+
+
+@router.delete("/{store_id}/experiment", dependencies=[Depends(requires_role("admin"))])
+async def stop_experiment(store_id: str):
+    """End a store's experiment and drop its variant B vectors."""
+    from src.services.search.experiments import get_experiment_runner
+
+    admin_store = get_admin_store()
+    stores = admin_store.get_stores()
+    if store_id not in stores:
+        raise HTTPException(status_code=404, detail="Store not found")
+    experiment = stores[store_id].get("experiment")
+    if not experiment:
+        raise HTTPException(status_code=404, detail="No active experiment")
+
+    store = {k: v for k, v in stores[store_id].items() if k != "experiment"}
+    if not admin_store.set_store(store_id, store):
+        raise HTTPException(status_code=500, detail="Failed to update store")
+    await asyncio.to_thread(get_experiment_runner().drop, store_id)
+    admin_store.log_audit("experiment_stopped", f"{experiment['kind']} experiment on {store_id} ended")
+    return {"store": store_id, "stopped": experiment}
+
+
+class ReindexFile(BaseModel):
+    path: str
+    # Normalized content hash (src.services.ingestion.hashing.content_hash)
+    hash: str
+    # Bytes, used to estimate chunks for new files
+    size: Optional[int] = None
+
+
+class ReindexRequest(BaseModel):
+    # The complete file list; indexed paths not listed count as removed
+    files: List[ReindexFile]
+    hash_version: Optional[int] = None
+    # Only report what would change
+    preview: bool = True
+    # Applying deletes indexed files missing from the list only when set
+    delete_missing: bool = False
+
+
+@router.post("/{store_id}/reindex", dependencies=[Depends(requires_role("admin"))])
+async def reindex_store(store_id: str, request: ReindexRequest):
+    """
+    Diff a store against a client's file list before reindexing.
+
+    With ``preview`` (the default) nothing changes: the response counts the
+    files and chunks that would be added, removed and changed, and ``noop``
+    says whether the reindex can be skipped. Without ``preview``,
+    ``upload`` lists the paths the client still needs to send, and with
+    ``delete_missing`` files missing from the (non-empty) list are deleted.
+    """
+    from src.services.ingestion.hashing import HASH_VERSION
+    from src.services.ingestion.reindex_plan import get_reindex_planner
+
+    if store_id not in get_admin_store().get_stores():
+        raise HTTPException(status_code=404, detail="Store not found")
+    if request.hash_version is not None and request.hash_version != HASH_VERSION:
+        raise HTTPException(
+            status_code=400,
+            detail=f"hash_version {request.hash_version} is not supported (server uses {HASH_VERSION})"
+        )
+
+    planner = get_reindex_planner()
+    files = [f.dict() for f in request.files]
+    if request.preview:
+        return {**planner.plan(store_id, files), "preview": True}
+
+    if request.delete_missing and not files:
+        raise HTTPException(status_code=400, detail="delete_missing needs a non-empty file list")
+    plan = planner.plan(store_id, files, max_paths=None)
+    applied = {"files_removed": 0, "chunks_removed": 0}
+    if request.delete_missing:
+        try:
+            applied = planner.apply(store_id, plan)
+        except ValueError as e:
+            raise HTTPException(status_code=400, detail=str(e))
+    get_admin_store().log_audit(
+        "store_reindex",
+        f"Reindex of {store_id}: {plan['files']['added']} added, {plan['files']['changed']} changed, "
+        f"{applied['files_removed']} removed"
+    )
+    return {
+        **plan,
+        "preview": False,
+        "applied": applied,
+        "upload": plan["paths"]["added"] + plan["paths"]["changed"],
+    }
+
+
+class TuneQuery(BaseModel):
+    query: str
+    # File paths, or path -> graded relevance
+    relevant: Union[List[str], Dict[str, float]]
+
+
+class TuneRequest(BaseModel):
+    queries: List[TuneQuery]
+    k: int = 10
+    metric: Literal["ndcg", "mrr", "recall"] = "ndcg"
+    # Save the best configuration as the store's search defaults
+    apply: bool = False
+
+
+@router.post("/{store_id}/tune", dependencies=[Depends(requires_role("admin"))])
+async def tune_store(store_id: str, request: TuneRequest):
+    """
+    Grid-search fusion weights, RRF k and reranking for a store.
+
+    Runs the labeled queries against every candidate configuration and
+    proposes the one with the best metric. With ``apply`` the best
+    configuration becomes the store's search defaults, but only if it
+    beats the current configuration.
+    """
+    from src.services.search.tuning_assistant import get_tuning_assistant
+
+    if not request.queries:
+        raise HTTPException(status_code=400, detail="No queries provided")
+    if store_id not in get_admin_store().get_stores():
+        raise HTTPException(status_code=404, detail="Store not found")
+
+    assistant = get_tuning_assistant()
+    proposal = await assistant.propose(
+        store_id,
+        [q.dict() for q in request.queries],
+        k=request.k,
+        metric=request.metric,
+    )
+
+    proposal["applied"] = None
+    if request.apply and proposal["best"] and proposal["improvement"] > 0:
+        proposal["applied"] = assistant.apply(store_id, proposal["best"]["config"])
+    return proposal
@@ -0,0 +1,95 @@
+"""
+Webhook endpoints.
+
+Receives GitHub/GitLab push events and queues incremental indexing.
+"""
+
+import json
+import logging
+from dataclasses import asdict
+
+from fastapi import APIRouter, HTTPException, Request
+
+from src.core.config import settings
+from src.services.admin.admin_store import get_admin_store
+from src.services.webhooks.git import (
+    WebhookError,
+    detect_provider,
+    verify_request,
+    is_push_event,
+    parse_push,
+    resolve_store,
+    should_index_branch,
+    is_indexable,
+)
+
+logger = logging.getLogger(__name__)
+
+router = APIRouter()
+
+
+@router.post("/git", status_code=202)
+async def git_webhook(request: Request):
+    """
+    Handle a GitHub or GitLab push webhook.
+
+    Changed files are fetched from the provider API and re-indexed into the
+    store mapped to the repository; removed files are deleted from the index.
+    """
+    if not settings.get("webhooks.git.enabled", False):
+        raise HTTPException(status_code=404, detail="Git webhooks are disabled")
+
+    body = await request.body()
+    headers = dict(request.headers)
+
+    try:
+        provider = detect_provider(headers)
+        verify_request(provider, headers, body, settings.get("webhooks.git.secret", ""))
+    except WebhookError as e:
+        raise HTTPException(status_code=e.status_code, detail=str(e))
+
+    if not is_push_event(provider, headers):
+        return {"status": "ignored", "reason": "not a push event"}
+
+    try:
+        payload = json.loads(body)
+    except ValueError:
+        raise HTTPException(status_code=400, detail="Invalid JSON payload")
+
+    event = parse_push(provider, payload)
+    if not event.repository or not event.sha:
+        raise HTTPException(status_code=400, detail="Push payload missing repository or commit")
+
+    if not should_index_branch(event):
+        return {"status": "ignored", "reason": f"branch {event.branch} not indexed"}
+
+    store_id = resolve_store(event.repository)
+    if not store_id:
+        return {"status": "ignored", "reason": f"no store configured for {event.repository}"}
+
+    skipped = [p for p in event.changed if not is_indexable(p)]
+    event.changed = [p for p in event.changed if is_indexable(p)]
+
+    if not event.changed and not event.removed:
+        return {"status": "ignored", "reason": "no indexable changes", "skipped": skipped}
+
+    from src.tasks.webhooks import git_push_task
+    task = git_push_task.delay(asdict(event), store_id)
+
+    get_admin_store().log_audit(
+        "git_webhook",
+        f"{provider} push {event.repository}@{event.sha[:8]}: "
+        f"{len(event.changed)} changed, {len(event.removed)} removed -> {store_id}",
+        provider
+    )
+
+    return {
+        "status": "queued",
+        "task_id": str(task.id),
+        "provider": provider,
+        "repository": event.repository,
+        "store": store_id,
+        "changed": len(event.changed),
+        "removed": len(event.removed),
+        "skipped": skipped,
+    }
@@ -0,0 +1,157 @@
+"""
+API Versioning and Deprecations.
+
+The URL prefix (``/api/v1``) only changes for breaking redesigns. Behavior
+changes within v1 are recorded as numbered revisions (``VERSIONS``), listed
+by ``GET /api/v1/changes``:
+
+- Every API response carries ``Rice-Api-Version`` with the revision served.
+- Clients may send ``Rice-Api-Version`` with the revision they were written
+  against. Unknown revisions, and revisions older than
+  ``app.min_api_version``, are refused with 400. An older (still supported)
+  revision gets a ``Link`` to the changes since then.
+- Deprecated endpoints and parameters answer with ``Deprecation`` (RFC 9745,
+  ``@<epoch seconds>``), ``Sunset`` (RFC 8594, HTTP date) and a ``Link`` to
+  the deprecation notice, so clients can log them before they are removed.
+"""
+
+import logging
+from datetime import datetime, timezone
+from email.utils import format_datetime
+from typing import Any, Dict, List, Optional, Tuple
+
+from fastapi import Response
+
+from src.core.config import settings
+
+logger = logging.getLogger(__name__)
+
+VERSION_HEADER = "Rice-Api-Version"
+
+# Revisions of the v1 API, oldest first; the last one is served
+VERSIONS: List[Dict[str, Any]] = [
+    {"version": "1.0", "changes": []},
+    {
+        "version": "1.1",
+        "changes": [
+            {
+                "endpoints": ["POST /search/query", "GET /search/query"],
+                "description": "Searches past their deadline (timeout or search.timeout) fail with 504 "
+                               "instead of running to completion.",
+            },
+            {
+                "endpoints": ["POST /search/query", "GET /search/query"],
+                "description": "With reranking on, only the first models.reranker.max_candidates results "
+                               "are reranked; the rest follow them in fused order.",
+            },
+            {
+                "endpoints": ["POST /search/query"],
+                "description": "RAG queries against privacy-mode stores are refused with 400.",
+            },
+            {
+                "endpoints": ["POST /ingest/file", "DELETE /ingest/file"],
+                "description": "A connection can no longer overwrite or delete files indexed by another "
+                               "connection (403).",
+            },
+            {
+                "endpoints": ["POST /search/query", "POST /search/export"],
+                "description": "The hybrid request field is deprecated; use use_splade.",
+                "deprecation": "search-hybrid",
+            },
+        ],
+    },
+]
+
+# Deprecated endpoints and parameters, by id
+DEPRECATIONS: Dict[str, Dict[str, Any]] = {
+    "search-hybrid": {
+        "endpoints": ["POST /search/query", "POST /search/export"],
+        "description": "The hybrid request field; set use_splade instead.",
+        "deprecated": "2026-10-16",
+        "sunset": "2027-04-16",
+    },
+}
+
+
+def current_version() -> str:
+    return VERSIONS[-1]["version"]
+
+
+def parse_version(value: str) -> Optional[Tuple[int, ...]]:
+    try:
+        return tuple(int(part) for part in value.strip().split("."))
+    except (AttributeError, ValueError):
+        return None
+
+
+def minimum_version() -> str:
+    return str(settings.get("app.min_api_version", VERSIONS[0]["version"]))
+
+
+def negotiate(requested: Optional[str]) -> Optional[str]:
+    """
+    Check a client's ``Rice-Api-Version``.
+
+    Returns:
+        An error message, or None when the revision is served (or none was
+        requested)
+    """
+    if not requested:
+        return None
+    known = [v["version"] for v in VERSIONS]
+    if requested.strip() not in known:
+        return f"Unknown API version '{requested}'; supported: {', '.join(known)}"
+    if parse_version(requested) < (parse_version(minimum_version()) or (1, 0)):
+        return (
+            f"API version {requested} is no longer supported (minimum {minimum_version()}); "
+            f"see {settings.API_V1_STR}/changes"
+        )
+    return None
+
+
+def changes_since(version: Optional[str] = None) -> List[Dict[str, Any]]:
+    """Revisions after ``version`` (all of them when None)."""
+    after = parse_version(version) if version else None
+    return [v for v in VERSIONS if after is None or parse_version(v["version"]) > after]
+
+
+def version_headers(requested: Optional[str]) -> Dict[str, str]:
+    """Response headers for a served request."""
+    headers = {VERSION_HEADER: current_version()}
+    if requested and parse_version(requested) < parse_version(current_version()):
+        headers["Link"] = f'<{settings.API_V1_STR}/changes?since={requested.strip()}>; rel="version-history"'
+    return headers
+
+
+def _date(value: str) -> datetime:
+    return datetime.strptime(value, "%Y-%m-%d").replace(tzinfo=timezone.utc)
+
+
+def deprecation_headers(deprecation_id: str) -> Dict[str, str]:
+    """``Deprecation``/``Sunset``/``Link`` headers for a deprecation."""
+    deprecation = DEPRECATIONS[deprecation_id]
+    headers = {
+        "Deprecation": f"@{int(_date(deprecation['deprecated']).timestamp())}",
+        "Link": f'<{settings.API_V1_STR}/changes#{deprecation_id}>; rel="deprecation"',
+    }
+    if deprecation.get("sunset"):
+        headers["Sunset"] = format_datetime(_date(deprecation["sunset"]), usegmt=True)
+    return headers
+
+
+def add_headers(response: Response, headers: Dict[str, str]):
+    """Set response headers, appending to an existing ``Link``."""
+    for name, value in headers.items():
+        if name == "Link" and "link" in response.headers:
+            value = f"{response.headers['link']}, {value}"
+        response.headers[name] = value
+
+
+def mark_deprecated(response: Response, deprecation_id: str):
+    """Flag a response as using a deprecated endpoint or parameter."""
+    add_headers(response, deprecation_headers(deprecation_id))
+    logger.debug(f"Deprecated API use: {deprecation_id}")
+
+
+def list_deprecations() -> List[Dict[str, Any]]:
+    return [{"id": key, **value} for key, value in DEPRECATIONS.items()]
@@ -0,0 +1,245 @@
+"""
+Rice Search Client admin commands.
+
+Server administration from the terminal (``ricesearch admin ...``): runtime
+settings, models, connections, background jobs and usage, over the admin
+REST API. Requires an admin user (``ricesearch config set user_id <id>``)
+when the backend has auth enabled.
+"""
+
+import csv
+import json
+from typing import Any, List, Optional
+
+import typer
+import yaml
+from rich.console import Console
+from rich.table import Table
+
+from src.cli.ricesearch.api_client import APIError, get_api_client
+
+console = Console()
+
+ADMIN = "/api/v1/admin/public"
+
+admin_app = typer.Typer(help="Server administration (settings, models, connections, jobs, usage)", no_args_is_help=True)
+settings_app = typer.Typer(help="Runtime settings", no_args_is_help=True)
+models_app = typer.Typer(help="Model management", no_args_is_help=True)
+connections_app = typer.Typer(help="CLI connections", no_args_is_help=True)
+jobs_app = typer.Typer(help="Background jobs (Celery tasks)", no_args_is_help=True)
+
+admin_app.add_typer(settings_app, name="settings")
+admin_app.add_typer(models_app, name="models")
+admin_app.add_typer(connections_app, name="connections")
+admin_app.add_typer(jobs_app, name="jobs")
+
+USAGE_METRICS = ["requests", "embed_tokens", "rerank_pairs", "qdrant_reads", "storage_bytes"]
+
+
+def _call(method: str, path: str, **kwargs) -> Any:
+    """Call the backend, exiting with the error message on failure."""
+    try:
+        return get_api_client().request(method, path, **kwargs)
+    except APIError as e:
+        console.print(f"[red]Error:[/red] {e}")
+        raise typer.Exit(1)
+
+
+def _parse_value(value: str) -> Any:
+    """Parse a command-line value as YAML (so true, 10 and [a, b] keep their types)."""
+    try:
+        return yaml.safe_load(value)
+    except yaml.YAMLError:
+        return value
+
+
+def _format(value: Any) -> str:
+    return json.dumps(value) if isinstance(value, (dict, list)) else str(value)
+
+
+def _table(title: str, columns: List[str], rows: List[List[Any]]):
+    table = Table(title=title)
+    for column in columns:
+        table.add_column(column)
+    for row in rows:
+        table.add_row(*["" if v is None else _format(v) for v in row])
+    console.print(table)
+
+
+# ============== Settings ==============
+
+@settings_app.command("get")
+def settings_get(key: str = typer.Argument(..., help="Setting key, e.g. search.hybrid.rrf_k")):
+    """Show a setting."""
+    data = _call("GET", f"/api/v1/settings/{key}")
+    console.print(f"{data['key']} = {_format(data['value'])}")
+
+
+@settings_app.command("set")
+def settings_set(
+    key: str = typer.Argument(..., help="Setting key"),
+    value: str = typer.Argument(..., help="New value (parsed as YAML: true, 10, [a, b])"),
+):
+    """Change a setting at runtime (persisted to settings.yaml)."""
+    data = _call("PUT", f"/api/v1/settings/{key}", json={"value": _parse_value(value)})
+    console.print(f"[green]Set {data['key']} = {_format(data['value'])}[/green] (version {data.get('version')})")
+
+
+@settings_app.command("list")
+def settings_list(prefix: Optional[str] = typer.Argument(None, help="Only keys under this prefix")):
+    """List settings."""
+    data = _call("GET", "/api/v1/settings/", params={"prefix": prefix} if prefix else None)
+    for key, value in sorted(data["settings"].items()):
+        console.print(f"{key} = {_format(value)}")
+
+
+# ============== Models ==============
+
+@models_app.command("list")
+def models_list():
+    """List configured models."""
+    models = _call("GET", f"{ADMIN}/models/registry")["models"]
+    _table("Models", ["ID", "Type", "Name", "Active", "GPU", "Precision"], [
+        [m.get("id"), m.get("type"), m.get("name"), m.get("active"), m.get("gpu_enabled"), m.get("precision")]
+        for m in models
+    ])
+
+
+@models_app.command("set-default")
+def models_set_default(model_id: str = typer.Argument(..., help="Model ID (see models list)")):
+    """Make a model the active one for its type."""
+    model = _call("POST", f"{ADMIN}/models/{model_id}/default")["model"]
+    console.print(f"[green]{model['id']} is now the default {model.get('type')} model[/green]")
+
+
+@models_app.command("toggle-gpu")
+def models_toggle_gpu(
+    model_id: str = typer.Argument(..., help="Model ID"),
+    enable: Optional[bool] = typer.Option(None, "--on/--off", help="Set explicitly instead of toggling"),
+):
+    """Toggle GPU use for a model."""
+    if enable is None:
+        models = {m["id"]: m for m in _call("GET", f"{ADMIN}/models/registry")["models"]}
+        if model_id not in models:
+            console.print(f"[red]Error:[/red] Unknown model {model_id}")
+            raise typer.Exit(1)
+        enable = not models[model_id].get("gpu_enabled", False)
+    model = _call("PUT", f"{ADMIN}/models/{model_id}", json={"gpu_enabled": enable})["model"]
+    console.print(f"[green]GPU {'enabled' if model['gpu_enabled'] else 'disabled'} for {model_id}[/green]")
+
+
+@models_app.command("set-precision")
+def models_set_precision(
+    model_id: str = typer.Argument(..., help="Model ID (sparse_embedding or reranker)"),
+    precision: str = typer.Argument(..., help="fp32, fp16 or int8"),
+):
+    """Run a local model at another precision (int8 speeds up CPU-only servers)."""
+    if precision not in ("fp32", "fp16", "int8"):
+        console.print("[red]Error:[/red] precision must be fp32, fp16 or int8")
+        raise typer.Exit(1)
+    model = _call("PUT", f"{ADMIN}/models/{model_id}", json={"precision": precision})["model"]
+    console.print(f"[green]{model_id} now runs at {model['precision']}[/green]")
+
+
+@models_app.command("benchmark")
+def models_benchmark(model_id: str = typer.Argument(..., help="Model ID (sparse_embedding or reranker)")):
+    """Compare latency and quality of fp32, fp16 and int8 for a local model."""
+    with console.status("Benchmarking (loads a copy of the model per precision)..."):
+        data = _call("POST", f"{ADMIN}/models/{model_id}/benchmark", timeout=600)
+    _table(f"{model_id} on {data['device']} (configured: {data['configured']})",
+           ["Precision", "Runs as", "Latency (ms)", "Speedup", "Quality"], [
+        [r["precision"], r["effective_precision"], r["latency_ms"], r["speedup"], r["quality"]]
+        for r in data["results"]
+    ])
+    console.print("[dim]Quality: 1.0 = identical to fp32 output[/dim]")
+
+
+# ============== Connections ==============
+
+@connections_app.command("list")
+def connections_list():
+    """List CLI connections."""
+    connections = _call("GET", f"{ADMIN}/connections")["connections"]
+    _table("Connections", ["ID", "User", "Device", "Enabled", "Last seen", "Files"], [
+        [c.get("id"), c.get("user_id"), c.get("device_name"), c.get("enabled", True),
+         c.get("last_seen"), c.get("indexed_files")]
+        for c in connections
+    ])
+
+
+def _set_connection_enabled(connection_id: str, enabled: bool):
+    _call("PUT", f"{ADMIN}/connections/{connection_id}/enabled", json={"enabled": enabled})
+    console.print(f"[green]Connection {connection_id} {'enabled' if enabled else 'disabled'}[/green]")
+
+
+@connections_app.command("enable")
+def connections_enable(connection_id: str = typer.Argument(..., help="Connection ID")):
+    """Allow a connection to index again."""
+    _set_connection_enabled(connection_id, True)
+
+
+@connections_app.command("disable")
+def connections_disable(connection_id: str = typer.Argument(..., help="Connection ID")):
+    """Refuse index/delete requests from a connection."""
+    _set_connection_enabled(connection_id, False)
+
+
+# ============== Jobs ==============
+
+@jobs_app.command("list")
+def jobs_list():
+    """List running, reserved and scheduled jobs."""
+    jobs = _call("GET", f"{ADMIN}/jobs")["jobs"]
+    if not jobs:
+        console.print("[dim]No jobs running or queued on workers[/dim]")
+        return
+    _table("Jobs", ["ID", "Task", "State", "Worker", "ETA"], [
+        [j.get("id"), j.get("name"), j.get("state"), j.get("worker"), j.get("eta")]
+        for j in jobs
+    ])
+
+
+@jobs_app.command("status")
+def jobs_status(task_id: str = typer.Argument(..., help="Task ID")):
+    """Show a job's state and result."""
+    job = _call("GET", f"{ADMIN}/jobs/{task_id}")
+    console.print(f"{job['id']}: [bold]{job['state']}[/bold]")
+    if "result" in job:
+        console.print(_format(job["result"]))
+
+
+@jobs_app.command("cancel")
+def jobs_cancel(
+    task_id: str = typer.Argument(..., help="Task ID"),
+    terminate: bool = typer.Option(False, "--terminate", help="Also kill the job if it is running"),
+):
+    """Cancel a queued (or, with --terminate, running) job."""
+    _call("POST", f"{ADMIN}/jobs/{task_id}/cancel", params={"terminate": terminate})
+    console.print(f"[green]Job {task_id} cancelled[/green]")
+
+
+# ============== Usage ==============
+
+@admin_app.command("usage")
+def usage(
+    month: Optional[str] = typer.Option(None, "--month", "-m", help="YYYY-MM (default: current month)"),
+    key: Optional[str] = typer.Option(None, "--key", "-k", help="Only this API key"),
+    output: Optional[str] = typer.Option(None, "--csv", help="Write the rows to this CSV file"),
+):
+    """Monthly resource usage per API key and store (chargeback)."""
+    params = {k: v for k, v in {"month": month, "key": key}.items() if v}
+    report = _call("GET", f"{ADMIN}/usage", params=params)
+    if output:
+        with open(output, "w", newline="") as f:
+            writer = csv.writer(f)
+            writer.writerow(["month", "key", "store", *USAGE_METRICS])
+            for row in report["rows"]:
+                writer.writerow([report["month"], row["key"], row["store"], *(row[m] for m in USAGE_METRICS)])
+        console.print(f"[green]Wrote {len(report['rows'])} rows to {output}[/green]")
+        return
+    if not report["rows"]:
+        console.print(f"[dim]No usage recorded for {report['month']}[/dim]")
+        return
+    _table(f"Usage {report['month']}", ["Key", "Store", *USAGE_METRICS], [
+        [r["key"], r["store"], *(r[m] for m in USAGE_METRICS)] for r in report["rows"]
+    ] + [["total", "", *(report["totals"][m] for m in USAGE_METRICS)]])
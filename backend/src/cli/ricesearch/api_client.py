@@ -1,15 +1,46 @@
 """
 API client for Rice Search backend.
 
-Handles HTTP communication with the backend for indexing and search.
+The CLI's side of the backend: ``src.client.RiceSearchClient`` configured
+from the active profile, plus connection registration and the CLI's
+error handling (uploads and searches report failures instead of raising).
 """
 
-import httpx
+import getpass
+import platform
+import socket
+import sys
 from pathlib import Path
 from typing import List, Dict, Any, Optional
-import base64
 
 from src.cli.ricesearch.config import get_config
+from src.client import RiceSearchClient, RiceSearchError
+
+CLIENT_VERSION = "0.1.0"
+
+# Times an upload is resent while the server's index queue is full (429)
+MAX_BUSY_RETRIES = 10
+
+def machine_info(user_id: str) -> Dict[str, str]:
+    """Connection registration body describing this machine."""
+    hostname = socket.gethostname()
+    try:
+        username = getpass.getuser()
+    except Exception:
+        username = None
+    return {
+        "user_id": user_id,
+        "device_name": hostname,
+        "version": CLIENT_VERSION,
+        "hostname": hostname,
+        "os": platform.system(),
+        "arch": platform.machine(),
+        "username": username,
+    }
+
+
+class APIError(RiceSearchError):
+    """Backend returned an error (or could not be reached)."""
 
 
 class APIClient:
@@ -19,29 +50,97 @@ class APIClient:
         config = get_config()
         self.base_url = base_url or config.backend_url
         self.timeout = 30.0
+        self._registration_failed = False
     
-    def _get_client(self) -> httpx.Client:
-        """Get HTTP client."""
+    def _get_client(self, connection: Optional[Dict[str, str]] = None, **kwargs) -> RiceSearchClient:
+        """SDK client for the active profile (as ``connection``, if given)."""
         config = get_config()
-        headers = {"X-User-ID": str(config.user_id)}
-        return httpx.Client(base_url=self.base_url, timeout=self.timeout, headers=headers)
+        return RiceSearchClient(
+            self.base_url,
+            api_key=config.api_key,
+            user_id=str(config.user_id),
+            timeout=self.timeout,
+            verify=config.tls_verify,
+            connection_id=connection["id"] if connection else None,
+            connection_token=connection["token"] if connection else None,
+            **kwargs
+        )
     
+    def request(self, method: str, path: str, **kwargs) -> Any:
+        """
+        Call a backend endpoint and return the JSON body.
+
+        Raises:
+            APIError: Connection failure or non-2xx response (with the detail)
+        """
+        try:
+            with self._get_client() as client:
+                return client.request(method, path, **kwargs)
+        except RiceSearchError as e:
+            raise APIError(str(e), e.status, e.detail, e.code, e.request_id) from e
+
+    def connection(self) -> Optional[Dict[str, str]]:
+        """
+        This CLI's connection ID and token, registering on first use.
+
+        They are saved in the config (per profile, so per server) and sent
+        with uploads and searches for per-connection stats and filters.
+        None when ``register_connection`` is off or registration failed.
+        """
+        config = get_config()
+        if self._registration_failed or not config.get("register_connection", True):
+            return None
+        connection_id = config.get("connection_id")
+        token = config.get("connection_token")
+        if connection_id and token:
+            return {"id": connection_id, "token": token}
+        if connection_id:
+            # Registered before connection tokens: claim one, keeping the ID and its stats
+            try:
+                token = self.request("POST", f"/api/v1/admin/public/connections/{connection_id}/claim")["token"]
+                config.set("connection_token", token)
+                return {"id": connection_id, "token": token}
+            except (APIError, KeyError, TypeError):
+                pass
+
+        try:
+            data = self.request("POST", "/api/v1/admin/public/connections/register", json=machine_info(config.user_id))
+            connection_id, token = data["connection"]["id"], data["token"]
+        except (APIError, KeyError, TypeError) as e:
+            # Older backends: keep working without a connection
+            self._registration_failed = True
+            print(f"Connection registration failed, continuing without one: {e}", file=sys.stderr)
+            return None
+        config.set("connection_id", connection_id)
+        config.set("connection_token", token)
+        return {"id": connection_id, "token": token}
+
+    def forget_connection(self):
+        """Drop the saved connection (e.g. deleted on the server); the next call re-registers."""
+        config = get_config()
+        config.set("connection_id", None)
+        config.set("connection_token", None)
+
     def health_check(self) -> bool:
         """Check backend health."""
         try:
             with self._get_client() as client:
-                resp = client.get("/health")
-                return resp.status_code == 200
-        except Exception as e:
+                client.health()
+                return True
+        except Exception:
             return False
     
     def index_file(
         self,
         file_path: Path,
-        org_id: str = "public"
+        org_id: str = "public",
+        _retry: bool = True
     ) -> Dict[str, Any]:
         """
-        Index a file via the backend API.
+        Index a file via the backend API (as this CLI's connection).
+
+        While the server's index queue is full (429) the upload waits for
+        Retry-After and is sent again, up to ``MAX_BUSY_RETRIES`` times.
         
         Args:
             file_path: Path to file to index
@@ -50,18 +149,17 @@ class APIClient:
         Returns:
             API response dict
         """
+        connection = self.connection()
         try:
-            with self._get_client() as client:
-                with open(file_path, 'rb') as f:
-                    files = {'file': (file_path.name, f)}
-                    data = {'org_id': org_id}
-                    resp = client.post(
-                        "/api/v1/ingest/file",
-                        files=files,
-                        data=data
-                    )
-                    return resp.json()
-        except Exception as e:
+            with self._get_client(connection, max_retries=MAX_BUSY_RETRIES) as client:
+                return client.index_file(file_path, store=org_id, display_path=file_path.name).raw
+        except RiceSearchError as e:
+            # Connection removed by an admin: register again once
+            if connection and _retry and e.status == 403 and "Unknown connection" in str(e.detail):
+                self.forget_connection()
+                return self.index_file(file_path, org_id, _retry=False)
+            return {"status": "error", "message": str(e)}
+        except OSError as e:
             return {"status": "error", "message": str(e)}
     
     def search(
@@ -69,7 +167,8 @@ class APIClient:
         query: str,
         limit: int = 10,
         org_id: str = "public",
-        hybrid: bool = True
+        hybrid: bool = True,
+        filters: Optional[Dict[str, Any]] = None
     ) -> List[Dict[str, Any]]:
         """
         Search indexed content.
@@ -79,30 +178,25 @@ class APIClient:
             limit: Max results
             org_id: Organization ID
             hybrid: Use hybrid search
+            filters: Search filters (paths, exclude_paths, extensions, modified_since)
             
         Returns:
             List of search results
         """
+        # The token lets store ACLs that name this connection admit it
+        connection = self.connection()
         try:
-            with self._get_client() as client:
-                resp = client.post(
-                    "/api/v1/search/query",
-                    json={
-                        "query": query,
-                        "mode": "search",
-                        "hybrid": hybrid
-                    }
+            with self._get_client(connection) as client:
+                response = client.search(
+                    query,
+                    limit=limit,
+                    hybrid=hybrid,
+                    **{k: v for k, v in (filters or {}).items() if v}
                 )
-                if resp.status_code != 200:
-                    print(f"Backend Error ({resp.status_code}): {resp.text}")
-                    return []
-                
-                data = resp.json()
-                return data.get("results", [])[:limit]
-        except Exception as e:
-            print(f"API Client Error: {e}")
+        except RiceSearchError as e:
+            print(f"Backend Error: {e}")
             return []
-
+        return [result.raw for result in response.results][:limit]
 
 # Singleton instance
 _client: Optional[APIClient] = None
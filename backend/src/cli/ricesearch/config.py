@@ -2,14 +2,34 @@
 Rice Search Client CLI configuration management.
 
 Handles loading/saving CLI configuration from ~/.ricesearch/config.yaml
+
+Top-level keys are the defaults. Named profiles under ``profiles`` override
+them per server (address, default store, user, API key, TLS), e.g.
+
+    active_profile: team
+    profiles:
+      local:
+        backend_url: http://localhost:8000
+      team:
+        backend_url: https://rice.example.com
+        org_id: platform
+        api_key: rs_...
+        ca_cert: ~/certs/team-ca.pem
+
+The profile in use is ``--profile``, else ``RICESEARCH_PROFILE``, else
+``active_profile`` (``ricesearch profile use``).
 """
 
 import os
 from pathlib import Path
-from typing import Optional
+from typing import Optional, Union
 import yaml
 
 
+class ProfileError(ValueError):
+    """Unknown profile."""
+
+
 class RicesearchConfig:
     """CLI configuration manager."""
     
@@ -24,8 +44,9 @@ class RicesearchConfig:
         "hybrid_search": True
     }
     
-    def __init__(self):
+    def __init__(self, profile: Optional[str] = None):
         self.config = self._load()
+        self.profile = profile or os.environ.get("RICESEARCH_PROFILE") or self.config.get("active_profile")
     
     def _load(self) -> dict:
         """Load config from file or create with defaults."""
@@ -46,18 +67,67 @@ class RicesearchConfig:
         with open(self.CONFIG_FILE, 'w') as f:
             yaml.safe_dump(config, f, default_flow_style=False)
     
+    def _values(self) -> dict:
+        """Top-level defaults with the current profile applied."""
+        values = {k: v for k, v in self.config.items() if k not in ("profiles", "active_profile")}
+        if self.profile:
+            values.update(self.profiles.get(self.profile) or {})
+        return values
+
     def get(self, key: str, default=None):
-        """Get config value."""
-        return self.config.get(key, default)
+        """Get config value (from the current profile, then the defaults)."""
+        return self._values().get(key, default)
     
     def set(self, key: str, value):
-        """Set config value and persist."""
-        self.config[key] = value
+        """Set config value in the current profile (or the defaults) and persist."""
+        if self.profile:
+            self.config.setdefault("profiles", {}).setdefault(self.profile, {})[key] = value
+        else:
+            self.config[key] = value
         self._save(self.config)
     
     def show(self) -> dict:
-        """Return full config."""
-        return self.config.copy()
+        """Return the effective config (secrets masked)."""
+        values = self._values()
+        for key in ("api_key", "connection_token"):
+            if values.get(key):
+                values[key] = f"{str(values[key])[:4]}..."
+        return {"profile": self.profile or "(none)", **values}
+
+    # ============== Profiles ==============
+
+    @property
+    def profiles(self) -> dict:
+        return self.config.get("profiles") or {}
+
+    def select(self, name: Optional[str]):
+        """Use a profile for this invocation only."""
+        if name and name not in self.profiles:
+            raise ProfileError(f"Unknown profile '{name}' (see: ricesearch profile list)")
+        self.profile = name or self.profile
+
+    def use_profile(self, name: str):
+        """Make a profile the default for later invocations."""
+        self.select(name)
+        self.config["active_profile"] = name
+        self._save(self.config)
+
+    def add_profile(self, name: str, values: dict):
+        """Create or update a profile (None values are left unchanged)."""
+        profile = self.config.setdefault("profiles", {}).setdefault(name, {})
+        profile.update({k: v for k, v in values.items() if v is not None})
+        self._save(self.config)
+
+    def remove_profile(self, name: str):
+        """Delete a profile (and unset it if active)."""
+        if name not in self.profiles:
+            raise ProfileError(f"Unknown profile '{name}' (see: ricesearch profile list)")
+        del self.config["profiles"][name]
+        if self.config.get("active_profile") == name:
+            self.config.pop("active_profile")
+        if self.profile == name:
+            self.profile = None
+        self._save(self.config)
     
     @property
     def backend_url(self) -> str:
@@ -80,6 +150,18 @@ class RicesearchConfig:
         val = self.get("user_id")
         return val if val else "admin-1"
 
+    @property
+    def api_key(self) -> Optional[str]:
+        return self.get("api_key")
+
+    @property
+    def tls_verify(self) -> Union[bool, str]:
+        """httpx ``verify``: a CA bundle path, or whether to verify at all."""
+        ca_cert = self.get("ca_cert")
+        if ca_cert:
+            return os.path.expanduser(ca_cert)
+        return bool(self.get("verify_tls", True))
+
 
 # Singleton instance
 _config: Optional[RicesearchConfig] = None
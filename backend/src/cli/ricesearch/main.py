@@ -5,10 +5,13 @@ A command-line tool for indexing and searching code with Rice Search backend.
 """
 
 import typer
-from typing import Optional
+from typing import List, Optional
 from rich.console import Console
 
-from src.cli.ricesearch.config import get_config
+from src.cli.ricesearch.admin import admin_app
+from src.cli.ricesearch.api_client import CLIENT_VERSION
+from src.cli.ricesearch.config import ProfileError, get_config
+from src.cli.ricesearch.profile import profile_app
 from src.cli.ricesearch.search import search_command
 from src.cli.ricesearch.watch import watch_command
 
@@ -19,6 +22,23 @@ app = typer.Typer(
 )
 console = Console()
 
+app.add_typer(admin_app, name="admin")
+app.add_typer(profile_app, name="profile")
+
+
+@app.callback()
+def select_profile(
+    profile: Optional[str] = typer.Option(
+        None, "--profile", "-P", envvar="RICESEARCH_PROFILE", help="Server profile to use (see: ricesearch profile list)"
+    )
+):
+    """Rice Search Client - local code search and indexing"""
+    try:
+        get_config().select(profile)
+    except ProfileError as e:
+        console.print(f"[red]Error:[/red] {e}")
+        raise typer.Exit(1)
+
 
 @app.command()
 def search(
@@ -26,18 +46,28 @@ def search(
     limit: int = typer.Option(10, "--limit", "-n", help="Max number of results"),
     org_id: Optional[str] = typer.Option(None, "--org-id", "-o", help="Organization ID"),
     hybrid: bool = typer.Option(True, "--hybrid/--no-hybrid", help="Use hybrid search"),
+    path: Optional[List[str]] = typer.Option(None, "--path", "-p", help="Path glob to include (repeatable)"),
+    exclude_path: Optional[List[str]] = typer.Option(None, "--exclude-path", "-x", help="Path glob to exclude (repeatable)"),
+    ext: Optional[List[str]] = typer.Option(None, "--ext", help="File extension to include (repeatable)"),
+    since: Optional[str] = typer.Option(None, "--since", help="Only files indexed since (ISO date or epoch seconds)"),
     no_color: bool = typer.Option(False, "--no-color", help="Disable colored output")
 ):
     """
     Search indexed code and documents.
     
     Output format: file:line:content
+
+    Example: ricesearch search "retry logic" --path "src/**" --exclude-path "**/test/**"
     """
     search_command(
         query=query,
         limit=limit,
         org_id=org_id,
         hybrid=hybrid,
+        paths=path,
+        exclude_paths=exclude_path,
+        extensions=ext,
+        modified_since=since,
         no_color=no_color
     )
 
@@ -73,7 +103,9 @@ def config(
     Examples:
         ricesearch config show
         ricesearch config set backend_url http://localhost:8000
-        ricesearch config set org_id myorg
+        ricesearch --profile team config set org_id myorg
+
+    With a profile in use, `set` changes that profile.
     """
     cfg = get_config()
     
@@ -98,7 +130,7 @@ def config(
 @app.command()
 def version():
     """Show version information."""
-    console.print("Rice Search Client v0.1.0")
+    console.print(f"Rice Search Client v{CLIENT_VERSION}")
     console.print("[dim]Part of the Rice Search platform[/dim]")
 
 
@@ -0,0 +1,92 @@
+"""
+Rice Search Client profile commands.
+
+Named server profiles (``ricesearch profile ...``) so switching between,
+say, a local dev server and a shared team server is one command (or one
+``--profile`` flag) instead of re-setting the backend URL each time.
+"""
+
+from typing import Optional
+
+import typer
+from rich.console import Console
+from rich.table import Table
+
+from src.cli.ricesearch.config import ProfileError, get_config
+
+console = Console()
+
+profile_app = typer.Typer(help="Server profiles (address, default store, API key, TLS)", no_args_is_help=True)
+
+
+@profile_app.command("list")
+def profile_list():
+    """List profiles (* marks the one in use)."""
+    cfg = get_config()
+    if not cfg.profiles:
+        console.print("No profiles. Add one with: ricesearch profile add <name> --url <backend_url>")
+        return
+
+    table = Table(title="Profiles")
+    for column in ["", "Name", "Backend URL", "Store", "API key", "TLS"]:
+        table.add_column(column)
+    for name, values in sorted(cfg.profiles.items()):
+        values = values or {}
+        tls = values.get("ca_cert") or ("off" if values.get("verify_tls") is False else "")
+        table.add_row(
+            "*" if name == cfg.profile else "",
+            name,
+            values.get("backend_url") or cfg.config.get("backend_url", ""),
+            values.get("org_id") or cfg.config.get("org_id", ""),
+            "yes" if values.get("api_key") else "",
+            tls,
+        )
+    console.print(table)
+
+
+@profile_app.command("use")
+def profile_use(name: str = typer.Argument(..., help="Profile name")):
+    """Use a profile by default from now on."""
+    try:
+        get_config().use_profile(name)
+    except ProfileError as e:
+        console.print(f"[red]Error:[/red] {e}")
+        raise typer.Exit(1)
+    console.print(f"[green]Using profile {name}[/green]")
+
+
+@profile_app.command("add")
+def profile_add(
+    name: str = typer.Argument(..., help="Profile name"),
+    url: Optional[str] = typer.Option(None, "--url", "-u", help="Backend URL"),
+    store: Optional[str] = typer.Option(None, "--store", "-s", help="Default store (org ID)"),
+    user_id: Optional[str] = typer.Option(None, "--user-id", help="User ID sent as X-User-ID"),
+    api_key: Optional[str] = typer.Option(None, "--api-key", help="API key sent as a bearer token"),
+    ca_cert: Optional[str] = typer.Option(None, "--ca-cert", help="CA bundle for a self-signed server certificate"),
+    insecure: Optional[bool] = typer.Option(None, "--insecure/--verify-tls", help="Skip TLS certificate verification"),
+):
+    """
+    Create or update a profile.
+
+    Example: ricesearch profile add team --url https://rice.example.com --store platform --api-key rs_...
+    """
+    get_config().add_profile(name, {
+        "backend_url": url,
+        "org_id": store,
+        "user_id": user_id,
+        "api_key": api_key,
+        "ca_cert": ca_cert,
+        "verify_tls": None if insecure is None else not insecure,
+    })
+    console.print(f"[green]Saved profile {name}[/green] (switch with: ricesearch profile use {name})")
+
+
+@profile_app.command("remove")
+def profile_remove(name: str = typer.Argument(..., help="Profile name")):
+    """Delete a profile."""
+    try:
+        get_config().remove_profile(name)
+    except ProfileError as e:
+        console.print(f"[red]Error:[/red] {e}")
+        raise typer.Exit(1)
+    console.print(f"Removed profile {name}")
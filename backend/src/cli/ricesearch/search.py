@@ -4,7 +4,7 @@ Rice Search Client search command implementation.
 Provides grep-like search output from Rice Search backend.
 """
 
-from typing import Optional
+from typing import List, Optional
 from rich.console import Console
 from rich.text import Text
 
@@ -14,11 +14,32 @@ from src.cli.ricesearch.config import get_config
 console = Console()
 
 
+def _read_local_lines(result: dict) -> str:
+    """
+    Lines of a result read from the local checkout, for stores in privacy
+    mode (the server returns locations but no content).
+    """
+    path = result.get("full_path") or result.get("metadata", {}).get("file_path")
+    start, end = result.get("start_line"), result.get("end_line")
+    if not path or not start:
+        return ""
+    try:
+        with open(path, encoding="utf-8", errors="replace") as f:
+            lines = f.read().splitlines()
+    except OSError:
+        return ""
+    return "\n".join(lines[start - 1:end or start])
+
+
 def search_command(
     query: str,
     limit: int = 10,
     org_id: Optional[str] = None,
     hybrid: Optional[bool] = None,
+    paths: Optional[List[str]] = None,
+    exclude_paths: Optional[List[str]] = None,
+    extensions: Optional[List[str]] = None,
+    modified_since: Optional[str] = None,
     no_color: bool = False
 ):
     """
@@ -29,6 +50,10 @@ def search_command(
         limit: Max results
         org_id: Organization ID (default from config)
         hybrid: Use hybrid search (default from config)
+        paths: Path globs to include
+        exclude_paths: Path globs to exclude
+        extensions: File extensions to include
+        modified_since: Only files indexed since (ISO date or epoch seconds)
         no_color: Disable colored output
     """
     config = get_config()
@@ -52,7 +77,13 @@ def search_command(
         query=query,
         limit=limit,
         org_id=org_id,
-        hybrid=hybrid
+        hybrid=hybrid,
+        filters={
+            "paths": paths,
+            "exclude_paths": exclude_paths,
+            "extensions": extensions,
+            "modified_since": modified_since,
+        }
     )
     
     if not results:
@@ -63,6 +94,8 @@ def search_command(
     console.print()
     for result in results:
         text = result.get("text", "")
+        if not text and result.get("content_stored") is False:
+            text = _read_local_lines(result)
         metadata = result.get("metadata", {})
         score = result.get("score", 0.0)
         
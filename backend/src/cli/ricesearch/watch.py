@@ -5,7 +5,6 @@ Monitors directories for changes and indexes files via Rice Search backend.
 """
 
 import time
-import hashlib
 from pathlib import Path
 from typing import Optional, Set
 from watchdog.observers import Observer
@@ -16,15 +15,15 @@ from rich.progress import Progress, SpinnerColumn, TextColumn
 from src.cli.ricesearch.api_client import get_api_client
 from src.cli.ricesearch.config import get_config
 from src.cli.ricesearch.ignore import IgnoreRules
+from src.services.ingestion.hashing import file_hash
 
 console = Console()
 
 
 def compute_hash(file_path: Path) -> str:
-    """Compute SHA256 hash of file content."""
+    """Compute SHA256 hash of file content (line-ending/whitespace insensitive)."""
     try:
-        with open(file_path, 'rb') as f:
-            return hashlib.sha256(f.read()).hexdigest()
+        return file_hash(str(file_path))
     except Exception:
         return ""
 
@@ -0,0 +1 @@
+# Rice Search Server admin CLI package
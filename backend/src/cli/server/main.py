@@ -0,0 +1,72 @@
+"""
+rice-search-server - Rice Search Server admin CLI.
+
+Commands that run next to the backend services (Qdrant, Redis) rather than
+through the HTTP API.
+"""
+
+import typer
+from rich.console import Console
+
+from src.datasets import DATASETS
+from src.services.ingestion.seed import DEFAULT_STORE, seed_dataset
+
+app = typer.Typer(
+    name="rice-search-server",
+    help="Rice Search Server - admin commands",
+    add_completion=False
+)
+console = Console()
+
+
+@app.command()
+def seed(
+    dataset: str = typer.Option("go-stdlib-sample", "--dataset", "-d", help="Bundled dataset to load"),
+    store: str = typer.Option(DEFAULT_STORE, "--store", "-s", help="Store to index into")
+):
+    """
+    Load a bundled sample corpus into a demo store.
+
+    Examples:
+        rice-search-server seed --dataset go-stdlib-sample
+        rice-search-server seed --dataset go-stdlib-sample --store playground
+    """
+    if dataset not in DATASETS:
+        console.print(f"[red]Unknown dataset:[/red] {dataset}")
+        console.print(f"Available: {', '.join(DATASETS)}")
+        raise typer.Exit(1)
+
+    with console.status(f"Indexing {dataset} into '{store}'..."):
+        result = seed_dataset(dataset, store)
+
+    if result["store_created"]:
+        console.print(f"[green]Created store '{store}'[/green]")
+    console.print(
+        f"Indexed {result['files_indexed']} files "
+        f"({result['chunks_indexed']} chunks) into '{store}'"
+    )
+    if result["files_skipped"]:
+        console.print(f"[dim]Skipped {result['files_skipped']} generated/empty files[/dim]")
+    for failure in result["failed"]:
+        console.print(f"[red]Failed:[/red] {failure['path']}: {failure['error']}")
+    if result["queries"]:
+        console.print(f"\n[dim]Evaluate with: ricesearch eval {result['queries']} --store {store}[/dim]")
+
+    if result["failed"] and not result["files_indexed"]:
+        raise typer.Exit(1)
+
+
+@app.command()
+def datasets():
+    """List bundled datasets."""
+    for name, info in DATASETS.items():
+        console.print(f"[bold]{name}[/bold]  {info['description']}")
+
+
+def main():
+    """CLI entry point."""
+    app()
+
+
+if __name__ == "__main__":
+    main()
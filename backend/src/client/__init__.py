@@ -0,0 +1,27 @@
+"""
+Rice Search Python client.
+
+    from src.client import RiceSearchClient
+"""
+
+from src.client.client import (
+    NotFoundError,
+    RateLimitedError,
+    RiceSearchClient,
+    RiceSearchError,
+    ServerModeError,
+)
+from src.client.types import IndexResult, Model, SearchResponse, SearchResult, Store
+
+__all__ = [
+    "RiceSearchClient",
+    "RiceSearchError",
+    "NotFoundError",
+    "RateLimitedError",
+    "ServerModeError",
+    "SearchResponse",
+    "SearchResult",
+    "Store",
+    "IndexResult",
+    "Model",
+]
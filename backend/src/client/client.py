@@ -0,0 +1,397 @@
+"""
+Rice Search Client.
+
+A typed client for the REST API that other Python services can embed
+instead of copying the CLI's internals or hand-writing requests:
+
+    from src.client import RiceSearchClient
+
+    with RiceSearchClient("http://rice:8000", api_key="...") as rice:
+        for result in rice.search("retry backoff", store="backend").results:
+            print(result.path, result.start_line, result.score)
+
+One pooled HTTP connection set is shared by every call on a client (and is
+safe to share across threads). Requests that fail with a connection error
+or a 429/502/503/504 are retried with exponential backoff, honouring
+``Retry-After``; uploads and deletes send an ``Idempotency-Key`` so a
+retry after a lost response is not applied twice. A 503 from a read-only
+or maintenance-mode server raises ``ServerModeError`` without retrying.
+Other errors raise ``RiceSearchError`` with the server's status, code and
+detail.
+"""
+
+import json
+import logging
+import random
+import time
+import uuid
+from pathlib import Path
+from typing import Any, Dict, Iterable, Iterator, List, Optional, Union
+
+import httpx
+
+from src.client.types import IndexResult, Model, SearchResponse, Store
+
+logger = logging.getLogger(__name__)
+
+RETRY_STATUSES = {429, 502, 503, 504}
+
+
+class RiceSearchError(Exception):
+    """
+    A failed API call: ``status`` is None when the server was not reached.
+
+    ``code`` is the server's machine-readable error code (``not_found``,
+    ``queue_full``, ...) and ``request_id`` the ID to look up in its logs.
+    """
+
+    def __init__(
+        self,
+        message: str,
+        status: Optional[int] = None,
+        detail: Any = None,
+        code: Optional[str] = None,
+        request_id: Optional[str] = None,
+    ):
+        super().__init__(message)
+        self.status = status
+        self.detail = detail
+        self.code = code
+        self.request_id = request_id
+
+
+class NotFoundError(RiceSearchError):
+    """404: unknown store, chunk or job."""
+
+
+class RateLimitedError(RiceSearchError):
+    """429 after the retries ran out (rate limit or full index queue)."""
+
+
+class ServerModeError(RiceSearchError):
+    """503 from a read-only server (``code`` ``read_only``) or one in maintenance."""
+
+
+SERVER_MODE_CODES = {"read_only", "maintenance"}
+
+
+def _mode_refusal(resp: httpx.Response) -> bool:
+    """A 503 for the server's mode: retrying right away won't help."""
+    if resp.status_code != 503:
+        return False
+    try:
+        body = resp.json()
+    except ValueError:
+        return False
+    return isinstance(body, dict) and body.get("code") in SERVER_MODE_CODES
+
+
+def _error(resp: httpx.Response) -> RiceSearchError:
+    try:
+        body = resp.json()
+    except ValueError:
+        body = None
+    if not isinstance(body, dict):
+        body = {"detail": resp.text}
+    detail = body.get("detail", resp.text)
+    request_id = body.get("request_id") or resp.headers.get("X-Request-ID")
+    message = f"{resp.status_code}: {detail}" + (f" (request {request_id})" if request_id else "")
+    error_class = {404: NotFoundError, 429: RateLimitedError}.get(resp.status_code, RiceSearchError)
+    if body.get("code") in SERVER_MODE_CODES:
+        error_class = ServerModeError
+    return error_class(message, resp.status_code, detail, body.get("code"), request_id)
+
+
+def _idempotency(key: Optional[str]) -> Dict[str, str]:
+    """Idempotency-Key header shared by every retry of one call."""
+    return {"Idempotency-Key": key or uuid.uuid4().hex}
+
+
+class RiceSearchClient:
+    """Client for one Rice Search server."""
+
+    def __init__(
+        self,
+        base_url: str = "http://localhost:8000",
+        api_key: Optional[str] = None,
+        user_id: Optional[str] = None,
+        timeout: float = 30.0,
+        max_retries: int = 3,
+        backoff_seconds: float = 0.5,
+        max_connections: int = 10,
+        verify: Union[bool, str] = True,
+        connection_id: Optional[str] = None,
+        connection_token: Optional[str] = None,
+        transport: Optional[httpx.BaseTransport] = None,
+    ):
+        """
+        Args:
+            base_url: Server URL (without ``/api/v1``)
+            api_key: Bearer token (JWT or API key)
+            user_id: Sent as X-User-ID when auth is disabled
+            max_retries: Retries for connection errors and 429/502/503/504
+            backoff_seconds: First retry delay, doubled per attempt
+            max_connections: Size of the connection pool
+            verify: TLS verification (False, or a CA bundle path)
+            connection_id / connection_token: A registered CLI connection to
+                attribute uploads and searches to
+            transport: Custom httpx transport (tests, proxies)
+        """
+        self.base_url = base_url.rstrip("/")
+        self.max_retries = max_retries
+        self.backoff_seconds = backoff_seconds
+        self.connection_id = connection_id
+        self.connection_token = connection_token
+        headers = {}
+        if user_id:
+            headers["X-User-ID"] = str(user_id)
+        if api_key:
+            headers["Authorization"] = f"Bearer {api_key}"
+        self._http = httpx.Client(
+            base_url=self.base_url,
+            headers=headers,
+            timeout=timeout,
+            verify=verify,
+            limits=httpx.Limits(max_connections=max_connections, max_keepalive_connections=max_connections),
+            transport=transport,
+        )
+
+    def close(self):
+        """Close pooled connections."""
+        self._http.close()
+
+    def __enter__(self) -> "RiceSearchClient":
+        return self
+
+    def __exit__(self, *exc):
+        self.close()
+
+    # ============== Transport ==============
+
+    def _delay(self, attempt: int, resp: Optional[httpx.Response]) -> float:
+        if resp is not None and resp.headers.get("Retry-After"):
+            try:
+                return max(0.0, float(resp.headers["Retry-After"]))
+            except ValueError:
+                pass
+        delay = self.backoff_seconds * (2 ** attempt)
+        return delay + random.uniform(0, delay / 2)
+
+    def _send(self, method: str, path: str, retry: bool = True, **kwargs) -> httpx.Response:
+        attempts = self.max_retries + 1 if retry else 1
+        for attempt in range(attempts):
+            resp = None
+            try:
+                resp = self._http.request(method, path, **kwargs)
+            except httpx.TransportError as e:
+                if attempt + 1 >= attempts:
+                    raise RiceSearchError(f"Cannot reach {self.base_url}: {e}")
+            else:
+                if resp.status_code not in RETRY_STATUSES or attempt + 1 >= attempts or _mode_refusal(resp):
+                    return resp
+            delay = self._delay(attempt, resp)
+            logger.debug(f"{method} {path} failed, retrying in {delay:.1f}s")
+            time.sleep(delay)
+        raise RiceSearchError(f"{method} {path} failed")  # not reached
+
+    def request(self, method: str, path: str, retry: bool = True, **kwargs) -> Any:
+        """
+        Call any endpoint and return the JSON body.
+
+        Use for endpoints without a typed method; ``path`` starts with
+        ``/api/v1``. Uploads with file objects are not retried (the stream
+        can't be replayed).
+
+        Raises:
+            RiceSearchError: transport failure or non-2xx response
+        """
+        resp = self._send(method, path, retry=retry, **kwargs)
+        if resp.status_code >= 400:
+            raise _error(resp)
+        return resp.json() if resp.content else {}
+
+    def _connection_headers(self) -> Dict[str, str]:
+        if not (self.connection_id and self.connection_token):
+            return {}
+        return {"X-Connection-ID": self.connection_id, "X-Connection-Token": self.connection_token}
+
+    # ============== Search ==============
+
+    def search(self, query: str, store: Optional[str] = None, limit: int = 10, **options) -> SearchResponse:
+        """
+        Search a store (default: the caller's).
+
+        ``query`` may use the inline syntax (``lang:go path:cmd/ "exact
+        phrase" -vendor``); ``parsed_query`` on the response shows how the
+        server read it. ``options`` are the other ``POST /search/query`` fields: ``paths``,
+        ``languages``, ``symbols``, ``include_content``, ``context_lines``,
+        ``offset``, ``facets``, ``timeout``, ``model_id``,
+        ``rerank_model_id`` (see ``models``), ``vector_weights``, ...
+        """
+        body = {"query": query, "mode": "search", "limit": limit, "store": store, **options}
+        data = self.request(
+            "POST", "/api/v1/search/query",
+            json={k: v for k, v in body.items() if v is not None},
+            headers=self._connection_headers(),
+        )
+        return SearchResponse.from_json(query, data)
+
+    def suggest(self, store_id: str, query: str, limit: int = 10) -> Dict[str, Any]:
+        """Completions for the last word of ``query`` and a ``did_you_mean`` spelling fix."""
+        return self.request(
+            "GET", f"/api/v1/stores/{store_id}/suggest", params={"q": query, "limit": limit}
+        )
+
+    def models(self, store_id: str) -> Dict[str, Any]:
+        """Embedding models and rerankers a search of the store can pick."""
+        return self.request("GET", f"/api/v1/stores/{store_id}/models")
+
+    def search_batch(
+        self, queries: List[str], store: Optional[str] = None, limit: int = 10, **options
+    ) -> List[SearchResponse]:
+        """Several queries in one request; failed queries carry ``error``."""
+        body = {"queries": queries, "limit": limit, "store": store, **options}
+        data = self.request("POST", "/api/v1/search/batch", json={k: v for k, v in body.items() if v is not None})
+        return [
+            SearchResponse.from_json(query, response)
+            for query, response in zip(queries, data.get("results") or [])
+        ]
+
+    def search_stream(self, query: str, store: Optional[str] = None, limit: int = 10, **options) -> Iterator[Dict[str, Any]]:
+        """
+        Stream a search (``POST /search/stream``): yields the ``candidates``,
+        ``rerank`` and ``done`` events as they arrive. Stop iterating to
+        cancel the rest of the rerank.
+        """
+        body = {"query": query, "limit": limit, "store": store, **options}
+        with self._http.stream(
+            "POST", "/api/v1/search/stream",
+            json={k: v for k, v in body.items() if v is not None},
+        ) as resp:
+            if resp.status_code >= 400:
+                resp.read()
+                raise _error(resp)
+            for line in resp.iter_lines():
+                if line.strip():
+                    yield json.loads(line)
+
+    # ============== Indexing ==============
+
+    def index_file(
+        self,
+        path: Union[str, Path],
+        store: str = "default",
+        display_path: Optional[str] = None,
+        language: Optional[str] = None,
+        idempotency_key: Optional[str] = None,
+    ) -> IndexResult:
+        """
+        Upload one file for indexing (``POST /ingest/file``).
+
+        ``display_path`` is the path stored and shown in results (default:
+        ``path``). Queued uploads return a ``job_id`` for ``wait_for_job``.
+        Retries reuse one ``Idempotency-Key`` (generated unless given), so
+        a retried upload is never queued twice.
+        """
+        path = Path(path)
+        data = {"org_id": store}
+        if language:
+            data["language"] = language
+        if self.connection_id:
+            data["connection_id"] = self.connection_id
+        content = path.read_bytes()
+        # Bytes (not a file handle) so busy-queue retries can resend the upload
+        return IndexResult.from_json(self.request(
+            "POST", "/api/v1/ingest/file",
+            files={"file": (display_path or str(path), content)},
+            data=data,
+            headers={**self._connection_headers(), **_idempotency(idempotency_key)},
+        ))
+
+    def index_files(
+        self, paths: Iterable[Union[str, Path]], store: str = "default", idempotency_key: Optional[str] = None
+    ) -> Dict[str, Any]:
+        """Upload several files in one request (``POST /stores/{id}/upload``)."""
+        files = [("files", (str(p), Path(p).read_bytes())) for p in paths]
+        return self.request(
+            "POST", f"/api/v1/stores/{store}/upload", files=files, headers=_idempotency(idempotency_key)
+        )
+
+    def job(self, job_id: str) -> Dict[str, Any]:
+        """State of a background job (indexing, reindex, git/archive ingest)."""
+        return self.request("GET", f"/api/v1/admin/public/jobs/{job_id}")
+
+    def wait_for_job(self, job_id: str, timeout: float = 300.0, poll_seconds: float = 1.0) -> Dict[str, Any]:
+        """
+        Poll a job until it finishes.
+
+        Raises:
+            RiceSearchError: the job failed or ``timeout`` passed
+        """
+        deadline = time.monotonic() + timeout
+        while True:
+            job = self.job(job_id)
+            state = str(job.get("status") or job.get("state") or "").upper()
+            if state == "SUCCESS":
+                return job
+            if state in ("FAILURE", "REVOKED"):
+                raise RiceSearchError(f"Job {job_id} {state.lower()}: {job.get('error') or job.get('result')}", detail=job)
+            if time.monotonic() >= deadline:
+                raise RiceSearchError(f"Job {job_id} still {state.lower() or 'pending'} after {timeout}s", detail=job)
+            time.sleep(poll_seconds)
+
+    # ============== Stores ==============
+
+    def list_stores(self) -> List[Store]:
+        return [Store.from_json(s) for s in self.request("GET", "/api/v1/stores/")]
+
+    def get_store(self, store_id: str) -> Store:
+        return Store.from_json(self.request("GET", f"/api/v1/stores/{store_id}"))
+
+    def create_store(self, store_id: str, name: Optional[str] = None, **options) -> Store:
+        """Create a store; ``options`` are the other store fields (``description``, ``privacy_mode``, ``acl``, ...)."""
+        body = {"id": store_id, "name": name or store_id, **options}
+        return Store.from_json(self.request("POST", "/api/v1/stores/", json=body, retry=False))
+
+    def delete_store(self, store_id: str, idempotency_key: Optional[str] = None) -> Dict[str, Any]:
+        """Move a store to the trash (its index is kept until ``purge_after``)."""
+        return self.request("DELETE", f"/api/v1/stores/{store_id}", headers=_idempotency(idempotency_key))
+
+    def list_trash(self) -> List[Dict[str, Any]]:
+        """Deleted stores that can still be restored."""
+        return self.request("GET", "/api/v1/stores/trash").get("stores", [])
+
+    def restore_store(self, store_id: str) -> Store:
+        return Store.from_json(self.request("POST", f"/api/v1/stores/trash/{store_id}/restore", retry=False))
+
+    def purge_store(self, store_id: str, idempotency_key: Optional[str] = None) -> Dict[str, Any]:
+        """Permanently delete a trashed store and its index."""
+        return self.request("DELETE", f"/api/v1/stores/trash/{store_id}", headers=_idempotency(idempotency_key))
+
+    def store_stats(self, store_id: str) -> Dict[str, Any]:
+        return self.request("GET", f"/api/v1/stores/{store_id}/stats")
+
+    def duplicate_report(self, store_id: str) -> Dict[str, Any]:
+        """Last near-duplicate code report of a store."""
+        return self.request("GET", f"/api/v1/stores/{store_id}/duplicates")
+
+    def build_duplicate_report(self, store_id: str) -> Dict[str, Any]:
+        """Queue a near-duplicate scan; returns the ``task_id`` (see ``wait_for_job``)."""
+        return self.request("POST", f"/api/v1/stores/{store_id}/duplicates", retry=False)
+
+    # ============== Models & health ==============
+
+    def server_mode(self) -> Dict[str, Any]:
+        """``mode`` (normal, read_only, maintenance) and ``reason``."""
+        return self.request("GET", "/api/v1/admin/public/server/mode")
+
+    def set_server_mode(self, mode: str, reason: Optional[str] = None) -> Dict[str, Any]:
+        """Switch the server to read-only or maintenance mode, or back to normal (admin)."""
+        return self.request("PUT", "/api/v1/admin/public/server/mode", json={"mode": mode, "reason": reason})
+
+    def list_models(self) -> List[Model]:
+        return [Model.from_json(m) for m in self.request("GET", "/api/v1/admin/public/models").get("models", [])]
+
+    def health(self) -> Dict[str, Any]:
+        """Server health (``GET /health``)."""
+        return self.request("GET", "/health", retry=False)
@@ -0,0 +1,128 @@
+"""
+Typed responses of the Rice Search client.
+
+Each type keeps the full JSON it was built from in ``raw``, so fields the
+server adds later are still reachable without a client upgrade.
+"""
+
+from dataclasses import dataclass, field
+from typing import Any, Dict, List, Optional
+
+
+@dataclass
+class SearchResult:
+    chunk_id: str
+    score: float
+    path: Optional[str]
+    text: Optional[str]
+    start_line: int = 0
+    end_line: int = 0
+    language: Optional[str] = None
+    symbols: List[str] = field(default_factory=list)
+    # Surrounding lines and imports (search with context_lines)
+    context: Optional[Dict[str, Any]] = None
+    # One-sentence LLM summary (stores with chunk summaries on)
+    summary: Optional[str] = None
+    raw: Dict[str, Any] = field(default_factory=dict, repr=False)
+
+    @classmethod
+    def from_json(cls, data: Dict[str, Any]) -> "SearchResult":
+        return cls(
+            chunk_id=str(data.get("chunk_id") or data.get("id") or ""),
+            score=float(data.get("score") or 0.0),
+            path=data.get("full_path") or data.get("file_path") or data.get("path"),
+            # Previews (include_content=False) carry ``preview`` instead of ``text``
+            text=data.get("text", data.get("preview")),
+            start_line=int(data.get("start_line") or 0),
+            end_line=int(data.get("end_line") or 0),
+            language=data.get("language"),
+            symbols=list(data.get("symbols") or []),
+            context=data.get("context"),
+            summary=data.get("summary"),
+            raw=data,
+        )
+
+
+@dataclass
+class SearchResponse:
+    query: str
+    results: List[SearchResult]
+    query_id: Optional[str] = None
+    degraded: bool = False
+    page: Optional[Dict[str, Any]] = None
+    facets: Optional[Dict[str, Any]] = None
+    # How the inline query syntax was read: text, filters, tokens
+    parsed_query: Optional[Dict[str, Any]] = None
+    # Spelling-corrected query, sent when few results were found
+    did_you_mean: Optional[str] = None
+    # Models used instead of the store's defaults ("embedding", "rerank")
+    models: Optional[Dict[str, str]] = None
+    # Per-query failure inside a batch (the batch itself succeeded)
+    error: Optional[str] = None
+    raw: Dict[str, Any] = field(default_factory=dict, repr=False)
+
+    @classmethod
+    def from_json(cls, query: str, data: Dict[str, Any]) -> "SearchResponse":
+        return cls(
+            query=data.get("query", query),
+            results=[SearchResult.from_json(r) for r in data.get("results") or []],
+            query_id=data.get("query_id"),
+            degraded=bool(data.get("degraded")),
+            page=data.get("page"),
+            facets=data.get("facets"),
+            parsed_query=data.get("parsed_query"),
+            did_you_mean=data.get("did_you_mean"),
+            models=data.get("models"),
+            error=data.get("error"),
+            raw=data,
+        )
+
+
+@dataclass
+class Store:
+    id: str
+    name: str
+    description: Optional[str] = None
+    privacy_mode: bool = False
+    stats: Optional[Dict[str, Any]] = None
+    raw: Dict[str, Any] = field(default_factory=dict, repr=False)
+
+    @classmethod
+    def from_json(cls, data: Dict[str, Any]) -> "Store":
+        return cls(
+            id=data["id"],
+            name=data.get("name", data["id"]),
+            description=data.get("description"),
+            privacy_mode=bool(data.get("privacy_mode")),
+            stats=data.get("stats"),
+            raw=data,
+        )
+
+
+@dataclass
+class IndexResult:
+    """An accepted upload: queued for the worker (poll ``job_id``) or answered directly."""
+    status: str
+    path: Optional[str] = None
+    job_id: Optional[str] = None
+    raw: Dict[str, Any] = field(default_factory=dict, repr=False)
+
+    @classmethod
+    def from_json(cls, data: Dict[str, Any]) -> "IndexResult":
+        return cls(
+            status=str(data.get("status", "")),
+            path=data.get("file") or data.get("path"),
+            job_id=data.get("task_id") or data.get("job_id"),
+            raw=data,
+        )
+
+
+@dataclass
+class Model:
+    id: str
+    name: str
+    raw: Dict[str, Any] = field(default_factory=dict, repr=False)
+
+    @classmethod
+    def from_json(cls, data: Dict[str, Any]) -> "Model":
+        return cls(id=data.get("id") or data.get("name", ""), name=data.get("name", ""), raw=data)
@@ -0,0 +1,178 @@
+"""
+Circuit Breakers.
+
+One breaker per search dependency (``embed``, ``sparse``, ``rerank``,
+``qdrant``). After ``failure_threshold`` consecutive failures a breaker
+opens and calls fail fast with ``CircuitOpenError`` instead of waiting on a
+dependency that is down; the search path then degrades (skips reranking,
+searches sparse-only, or answers with a ``degraded`` flag) rather than
+erroring. After ``reset_seconds`` one call is let through as a probe: its
+success closes the breaker, its failure opens it again.
+
+Settings live under ``resilience.circuit_breaker``; any key can be set per
+dependency (``resilience.circuit_breaker.rerank.failure_threshold``).
+"""
+
+import logging
+import threading
+import time
+from typing import Any, Dict, Optional
+
+from src.core.config import settings
+
+logger = logging.getLogger(__name__)
+
+CLOSED = "closed"
+OPEN = "open"
+HALF_OPEN = "half_open"
+
+# Gauge values for /metrics
+STATE_VALUES = {CLOSED: 0, HALF_OPEN: 1, OPEN: 2}
+
+
+class CircuitOpenError(Exception):
+    """A dependency's breaker is open; the call was not made."""
+
+    def __init__(self, name: str, retry_in: float):
+        super().__init__(f"{name} circuit open (retry in {retry_in:.0f}s)")
+        self.name = name
+        self.retry_in = retry_in
+
+
+class CircuitBreaker:
+    """Consecutive-failure breaker for one dependency."""
+
+    def __init__(self, name: str):
+        self.name = name
+        self._lock = threading.Lock()
+        self.state = CLOSED
+        self.failures = 0
+        self.opened_at: Optional[float] = None
+        self.probe_started: Optional[float] = None
+        self.last_error: Optional[str] = None
+        self.trips = 0
+
+    def _setting(self, key: str, default: Any) -> Any:
+        value = settings.get(f"resilience.circuit_breaker.{self.name}.{key}")
+        if value is None:
+            value = settings.get(f"resilience.circuit_breaker.{key}", default)
+        return value
+
+    @property
+    def enabled(self) -> bool:
+        return bool(self._setting("enabled", True))
+
+    @property
+    def failure_threshold(self) -> int:
+        return max(1, int(self._setting("failure_threshold", 5)))
+
+    @property
+    def reset_seconds(self) -> float:
+        return float(self._setting("reset_seconds", 30))
+
+    def before_call(self):
+        """
+        Raise ``CircuitOpenError`` unless a call may go through now.
+
+        An open breaker lets one probe through every ``reset_seconds``.
+        """
+        if not self.enabled:
+            return
+        now = time.monotonic()
+        with self._lock:
+            if self.state == CLOSED:
+                return
+            since = self.probe_started if self.state == HALF_OPEN else self.opened_at
+            waited = now - (since or now)
+            if waited < self.reset_seconds:
+                raise CircuitOpenError(self.name, self.reset_seconds - waited)
+            # Probe (again, if an earlier probe never reported back)
+            self.state = HALF_OPEN
+            self.probe_started = now
+        logger.info(f"{self.name} circuit half-open, probing")
+
+    def record_success(self):
+        with self._lock:
+            recovered = self.state != CLOSED
+            self.state = CLOSED
+            self.failures = 0
+            self.opened_at = self.probe_started = None
+        if recovered:
+            logger.warning(f"{self.name} circuit closed, dependency recovered")
+
+    def record_failure(self, error: Exception):
+        with self._lock:
+            self.failures += 1
+            self.last_error = str(error)
+            trips = self.state == HALF_OPEN or (
+                self.state == CLOSED and self.failures >= self.failure_threshold
+            )
+            if trips:
+                self.state = OPEN
+                self.opened_at = time.monotonic()
+                self.probe_started = None
+                self.trips += 1
+        if trips:
+            logger.error(f"{self.name} circuit open after {self.failures} failure(s): {error}")
+
+    @property
+    def is_open(self) -> bool:
+        """Open (or probing): calls are being refused."""
+        return self.enabled and self.state != CLOSED
+
+    def snapshot(self) -> Dict[str, Any]:
+        with self._lock:
+            retry_in = None
+            if self.state == OPEN and self.opened_at is not None:
+                retry_in = round(max(0.0, self.reset_seconds - (time.monotonic() - self.opened_at)), 1)
+            return {
+                "state": self.state,
+                "consecutive_failures": self.failures,
+                "last_error": self.last_error,
+                "trips": self.trips,
+                "retry_in_seconds": retry_in,
+            }
+
+
+class BreakerRegistry:
+    """Breakers by dependency name, created on first use."""
+
+    def __init__(self):
+        self._lock = threading.Lock()
+        self._breakers: Dict[str, CircuitBreaker] = {}
+
+    def get(self, name: str) -> CircuitBreaker:
+        with self._lock:
+            if name not in self._breakers:
+                self._breakers[name] = CircuitBreaker(name)
+            return self._breakers[name]
+
+    def snapshot(self) -> Dict[str, Dict[str, Any]]:
+        with self._lock:
+            breakers = dict(self._breakers)
+        return {name: breaker.snapshot() for name, breaker in sorted(breakers.items())}
+
+    def open_names(self):
+        with self._lock:
+            breakers = list(self._breakers.values())
+        return sorted(b.name for b in breakers if b.is_open)
+
+    def prometheus_lines(self):
+        lines = [
+            "# HELP rice_search_circuit_breaker_state Dependency circuit breaker (0 closed, 1 half-open, 2 open)",
+            "# TYPE rice_search_circuit_breaker_state gauge",
+        ]
+        for name, snapshot in self.snapshot().items():
+            lines.append(f'rice_search_circuit_breaker_state{{dependency="{name}"}} {STATE_VALUES[snapshot["state"]]}')
+        return lines
+
+
+# Singleton instance
+_breakers: Optional[BreakerRegistry] = None
+
+def get_breakers() -> BreakerRegistry:
+    """Get global circuit breaker registry."""
+    global _breakers
+    if _breakers is None:
+        _breakers = BreakerRegistry()
+    return _breakers
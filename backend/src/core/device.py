@@ -1,10 +1,26 @@
+"""
+Compute device selection and telemetry.
+
+Picks CUDA or CPU for local models, remembers why a component ended up on
+CPU when a GPU was wanted (no CUDA build, no visible device, out of memory
+while loading), and reports device and VRAM usage for /health and the
+admin dashboard.
+"""
+import logging
+import subprocess
+from datetime import datetime
+from typing import Any, Dict, List, Optional
 
 import torch
+
 from src.core.config import settings
-import logging
 
 logger = logging.getLogger(__name__)
 
+# component -> {"reason", "at"}; per process
+_fallbacks: Dict[str, Dict[str, str]] = {}
+
+
 def get_device() -> str:
     """
     Get the compute device (cuda or cpu) based on availability and settings.
@@ -17,8 +33,122 @@ def get_device() -> str:
             logger.info("GPU enforced and available. Using CUDA.")
             return "cuda"
         else:
-            logger.warning("GPU enforced but CUDA not available. Falling back to CPU.")
+            record_fallback("default", cuda_unavailable_reason() or "CUDA not available")
             return "cpu"
-    
+
     # Default behavior if not forced
     return "cuda" if torch.cuda.is_available() else "cpu"
+
+
+def cuda_unavailable_reason() -> Optional[str]:
+    """Why CUDA can't be used in this process (None if it can)."""
+    try:
+        if torch.cuda.is_available():
+            return None
+        if not getattr(torch.version, "cuda", None):
+            return "PyTorch was built without CUDA support"
+        if torch.cuda.device_count() == 0:
+            return "No CUDA device visible (driver or container GPU runtime missing)"
+        return "CUDA initialization failed"
+    except Exception as e:
+        return f"CUDA check failed: {e}"
+
+
+def record_fallback(component: str, reason: str):
+    """Note that a component runs on CPU although a GPU was requested."""
+    logger.warning(f"{component} falling back to CPU: {reason}")
+    _fallbacks[component] = {"reason": reason, "at": datetime.now().isoformat()}
+
+
+def clear_fallback(component: str):
+    _fallbacks.pop(component, None)
+
+
+def get_fallbacks() -> List[Dict[str, str]]:
+    return [{"component": c, **f} for c, f in sorted(_fallbacks.items())]
+
+
+def get_gpu_memory() -> Optional[Dict[str, Any]]:
+    """
+    VRAM usage of the first GPU (None without CUDA).
+
+    System-wide numbers come from nvidia-smi when present; ``process_mb`` is
+    what this process has reserved through PyTorch.
+    """
+    if not torch.cuda.is_available():
+        return None
+    memory: Dict[str, Any] = {
+        "used_mb": None,
+        "total_mb": None,
+        "utilization_percent": None,
+        "process_mb": int(torch.cuda.memory_reserved() / 1024 / 1024),
+    }
+    try:
+        result = subprocess.run(
+            ["nvidia-smi", "--query-gpu=memory.used,memory.total,utilization.gpu", "--format=csv,noheader,nounits"],
+            capture_output=True, text=True, timeout=2
+        )
+        if result.returncode == 0:
+            used, total, util = [int(p) for p in result.stdout.strip().splitlines()[0].split(", ")[:3]]
+            memory.update(used_mb=used, total_mb=total, utilization_percent=util)
+    except Exception as e:
+        logger.debug(f"nvidia-smi unavailable: {e}")
+    if memory["total_mb"] is None:
+        try:
+            props = torch.cuda.get_device_properties(torch.cuda.current_device())
+            memory["total_mb"] = int(props.total_memory / 1024 / 1024)
+        except Exception:
+            pass
+    return memory
+
+
+def _component_devices() -> Dict[str, str]:
+    """Devices of the models loaded in this process."""
+    devices = {}
+    try:
+        from src.services.retrieval import splade_encoder
+        if splade_encoder._splade_encoder is not None:
+            devices["splade"] = str(splade_encoder._splade_encoder.device)
+    except Exception:
+        pass
+    try:
+        from src.services.inference import local_reranker
+        reranker = local_reranker._local_reranker
+        if reranker is not None and reranker.model is not None:
+            model = reranker.model
+            devices["reranker"] = str(getattr(model, "device", None) or model.model.device)
+    except Exception:
+        pass
+    return devices
+
+
+def get_device_info() -> Dict[str, Any]:
+    """
+    Device telemetry for health and stats.
+
+    Returns:
+        Dict with the device in use, GPU name, whether a GPU was requested,
+        why CUDA is unavailable, per-component fallbacks, devices of loaded
+        models and VRAM usage
+    """
+    cuda = torch.cuda.is_available()
+    name = None
+    if cuda:
+        try:
+            name = torch.cuda.get_device_name(torch.cuda.current_device())
+        except Exception:
+            pass
+    reason = cuda_unavailable_reason()
+    requested = bool(settings.FORCE_GPU)
+    return {
+        "device": "cuda" if cuda else "cpu",
+        "name": name or ("CPU" if not cuda else "CUDA device"),
+        "cuda_available": cuda,
+        "cuda_version": getattr(torch.version, "cuda", None),
+        "gpu_count": torch.cuda.device_count() if cuda else 0,
+        "gpu_requested": requested,
+        "fallback_reason": reason if requested else None,
+        "fallbacks": get_fallbacks(),
+        "components": _component_devices(),
+        "vram": get_gpu_memory(),
+    }
@@ -0,0 +1,178 @@
+"""
+Graceful shutdown: readiness, request draining and a hard stop.
+
+On SIGTERM (or the first Ctrl-C) the API:
+
+1. flips ``/readyz`` to 503 ``draining`` so load balancers stop routing
+   here, and keeps serving for ``server.shutdown.ready_grace_seconds``
+   while they notice
+2. waits up to ``server.shutdown.drain_seconds`` for in-flight requests
+   (uploads, streamed searches, exports) to finish. Event streams end on
+   their own once draining starts, so clients reconnect elsewhere
+3. hands the signal to uvicorn, which stops accepting connections and
+   waits for the rest. After ``server.shutdown.hard_deadline_seconds`` the
+   stop is forced and the requests still running are logged as aborted
+
+A second signal while draining skips straight to uvicorn's own handling.
+"""
+
+import logging
+import signal
+import threading
+import time
+from typing import Callable, Dict, List, Optional, Tuple
+
+from src.core.config import settings
+from src.core.supervisor import ORDER_TRANSPORT, get_supervisor
+
+logger = logging.getLogger(__name__)
+
+# Probes are not work to drain
+UNTRACKED_PATHS = ("/readyz", "/readyz/read", "/readyz/write", "/health", "/healthz")
+
+
+class DrainState:
+    """Readiness flag and the requests currently in flight."""
+
+    def __init__(self):
+        self._lock = threading.Lock()
+        self._idle = threading.Condition(self._lock)
+        self._draining_since: Optional[float] = None
+        self._next_token = 0
+        # token -> (method, path, started)
+        self._active: Dict[int, Tuple[str, str, float]] = {}
+
+    @property
+    def draining(self) -> bool:
+        return self._draining_since is not None
+
+    def start_draining(self) -> bool:
+        """Mark the server not ready. False if it already was."""
+        with self._lock:
+            if self._draining_since is not None:
+                return False
+            self._draining_since = time.time()
+            return True
+
+    def begin(self, method: str, path: str) -> int:
+        with self._lock:
+            self._next_token += 1
+            self._active[self._next_token] = (method, path, time.monotonic())
+            return self._next_token
+
+    def end(self, token: int):
+        with self._lock:
+            self._active.pop(token, None)
+            if not self._active:
+                self._idle.notify_all()
+
+    def active(self) -> List[Dict]:
+        """In-flight requests, longest-running first."""
+        now = time.monotonic()
+        with self._lock:
+            entries = sorted(self._active.values(), key=lambda e: e[2])
+        return [
+            {"method": method, "path": path, "seconds": round(now - started, 1)}
+            for method, path, started in entries
+        ]
+
+    def wait_idle(self, timeout: float) -> bool:
+        """Wait for in-flight requests to finish. False on timeout."""
+        with self._lock:
+            return self._idle.wait_for(lambda: not self._active, timeout=max(timeout, 0))
+
+    def status(self) -> Dict:
+        return {
+            "status": "draining" if self.draining else "ready",
+            "draining_since": self._draining_since,
+            "active_requests": len(self._active),
+        }
+
+
+class DrainMiddleware:
+    """Tracks in-flight requests for draining (streams count until their last byte)."""
+
+    def __init__(self, app):
+        self.app = app
+
+    async def __call__(self, scope, receive, send):
+        if scope["type"] != "http" or scope["path"] in UNTRACKED_PATHS:
+            await self.app(scope, receive, send)
+            return
+
+        state = get_drain_state()
+        token = state.begin(scope["method"], scope["path"])
+        try:
+            await self.app(scope, receive, send)
+        finally:
+            state.end(token)
+
+
+def log_aborted(state: "DrainState", when: str):
+    """Log requests still running at ``when``; they will not complete."""
+    for request in state.active():
+        logger.warning(
+            f"Aborted at {when}: {request['method']} {request['path']} "
+            f"(running {request['seconds']}s)"
+        )
+
+
+def drain_then_stop(previous: Callable, signum: int, frame=None):
+    """Drain, then pass the signal on to ``previous`` (uvicorn's handler)."""
+    state = get_drain_state()
+    grace = float(settings.get("server.shutdown.ready_grace_seconds", 5))
+    drain = float(settings.get("server.shutdown.drain_seconds", 30))
+    deadline = float(settings.get("server.shutdown.hard_deadline_seconds", 10))
+
+    logger.info(f"Draining: not ready, serving for {grace:g}s then waiting up to {drain:g}s for requests")
+    time.sleep(grace)
+    if not state.wait_idle(drain):
+        logger.warning(f"Drain period over with {len(state.active())} request(s) still running")
+
+    previous(signum, frame)
+
+    if not state.wait_idle(deadline):
+        log_aborted(state, "hard deadline")
+        # uvicorn's Server: stop waiting on open connections and tasks
+        server = getattr(previous, "__self__", None)
+        if server is not None and hasattr(server, "force_exit"):
+            server.force_exit = True
+
+
+def install_signal_handlers():
+    """
+    Put draining in front of the server's SIGTERM/SIGINT handlers.
+
+    Call at startup, after uvicorn installed its own handlers. Does nothing
+    outside the main thread (signals can only be handled there).
+    """
+    if threading.current_thread() is not threading.main_thread():
+        return
+
+    for signum in (signal.SIGTERM, signal.SIGINT):
+        previous = signal.getsignal(signum)
+        if not callable(previous):
+            continue
+
+        def handler(sig, frame, previous=previous):
+            if not get_drain_state().start_draining():
+                previous(sig, frame)
+                return
+            # Joined last: it hands the signal on to the server shutting it down
+            get_supervisor().spawn(
+                "shutdown-drain", drain_then_stop, previous, sig, frame,
+                group="shutdown", order=ORDER_TRANSPORT,
+            )
+
+        signal.signal(signum, handler)
+
+
+# Singleton instance
+_drain_state: Optional[DrainState] = None
+
+def get_drain_state() -> DrainState:
+    """Get global drain state instance."""
+    global _drain_state
+    if _drain_state is None:
+        _drain_state = DrainState()
+    return _drain_state
@@ -0,0 +1,138 @@
+"""
+API Error Envelope.
+
+Every error response, whether raised by a handler, refused by a
+middleware or rejected during request validation, has one shape:
+
+    {
+        "detail": "Store 'docs' not found",   # message (validation errors: list)
+        "code": "not_found",                  # stable machine-readable code
+        "request_id": "9f1c2e..."             # also in X-Request-ID and server logs
+    }
+
+``detail`` stays as FastAPI shapes it, so existing clients keep working;
+``code`` is what clients should branch on. Codes default from the HTTP
+status and follow the canonical gRPC status names where one fits
+(``invalid_argument``, ``permission_denied``, ``deadline_exceeded``, ...).
+Handlers that need a more specific code raise ``AppError``.
+
+The request ID is taken from the client's ``X-Request-ID`` (when it is a
+plain token of at most 64 characters) or generated, echoed on every
+response and written in the logs of failed and slow requests.
+"""
+
+import re
+import uuid
+from contextvars import ContextVar
+from typing import Any, Dict, Optional
+
+from fastapi import HTTPException
+
+REQUEST_ID_HEADER = "X-Request-ID"
+
+INVALID_ARGUMENT = "invalid_argument"
+UNAUTHENTICATED = "unauthenticated"
+PERMISSION_DENIED = "permission_denied"
+NOT_FOUND = "not_found"
+METHOD_NOT_ALLOWED = "method_not_allowed"
+ALREADY_EXISTS = "already_exists"
+FAILED_PRECONDITION = "failed_precondition"
+PAYLOAD_TOO_LARGE = "payload_too_large"
+RATE_LIMITED = "rate_limited"
+INTERNAL = "internal"
+UNIMPLEMENTED = "unimplemented"
+UNAVAILABLE = "unavailable"
+DEADLINE_EXCEEDED = "deadline_exceeded"
+# More specific codes raised with AppError
+UNSUPPORTED_API_VERSION = "unsupported_api_version"
+QUEUE_FULL = "queue_full"
+READ_ONLY = "read_only"
+MAINTENANCE = "maintenance"
+
+STATUS_CODES = {
+    400: INVALID_ARGUMENT,
+    401: UNAUTHENTICATED,
+    403: PERMISSION_DENIED,
+    404: NOT_FOUND,
+    405: METHOD_NOT_ALLOWED,
+    409: ALREADY_EXISTS,
+    412: FAILED_PRECONDITION,
+    413: PAYLOAD_TOO_LARGE,
+    415: INVALID_ARGUMENT,
+    422: INVALID_ARGUMENT,
+    429: RATE_LIMITED,
+    500: INTERNAL,
+    501: UNIMPLEMENTED,
+    502: UNAVAILABLE,
+    503: UNAVAILABLE,
+    504: DEADLINE_EXCEEDED,
+}
+
+_REQUEST_ID = re.compile(r"^[\w.:-]{1,64}$")
+
+_request_id: ContextVar[Optional[str]] = ContextVar("request_id", default=None)
+
+
+class AppError(HTTPException):
+    """An HTTPException with an explicit error ``code``."""
+
+    def __init__(self, status_code: int, detail: Any, code: Optional[str] = None, headers: Optional[Dict[str, str]] = None):
+        super().__init__(status_code=status_code, detail=detail, headers=headers)
+        self.code = code or code_for_status(status_code)
+
+
+def code_for_status(status: int) -> str:
+    if status in STATUS_CODES:
+        return STATUS_CODES[status]
+    return INTERNAL if status >= 500 else INVALID_ARGUMENT
+
+
+def new_request_id(incoming: Optional[str] = None) -> str:
+    """The client's request ID when usable, else a fresh one."""
+    if incoming and _REQUEST_ID.match(incoming):
+        return incoming
+    return uuid.uuid4().hex
+
+
+def set_request_id(request_id: Optional[str]):
+    return _request_id.set(request_id)
+
+
+def reset_request_id(token):
+    _request_id.reset(token)
+
+
+def current_request_id() -> Optional[str]:
+    """ID of the request being handled (None outside a request)."""
+    return _request_id.get()
+
+
+def error_body(status: int, detail: Any, code: Optional[str] = None) -> Dict[str, Any]:
+    """The error envelope for a response."""
+    return {
+        "detail": detail,
+        "code": code or code_for_status(status),
+        "request_id": current_request_id(),
+    }
+
+
+def install_error_handlers(app):
+    """Answer HTTP and validation errors with the envelope."""
+    from fastapi.exceptions import RequestValidationError
+    from fastapi.responses import JSONResponse, Response
+    from starlette.exceptions import HTTPException as StarletteHTTPException
+
+    @app.exception_handler(StarletteHTTPException)
+    async def http_error(request, exc: StarletteHTTPException):
+        if exc.status_code in (204, 304):
+            return Response(status_code=exc.status_code, headers=getattr(exc, "headers", None))
+        return JSONResponse(
+            status_code=exc.status_code,
+            content=error_body(exc.status_code, exc.detail, getattr(exc, "code", None)),
+            headers=getattr(exc, "headers", None),
+        )
+
+    @app.exception_handler(RequestValidationError)
+    async def validation_error(request, exc: RequestValidationError):
+        from fastapi.encoders import jsonable_encoder
+        return JSONResponse(status_code=422, content=error_body(422, jsonable_encoder(exc.errors())))
@@ -0,0 +1,247 @@
+"""
+HTTP transport middleware.
+
+``CompressionMiddleware`` compresses responses with gzip or deflate
+(``Accept-Encoding``). Search responses carrying chunk content can be
+hundreds of KB of JSON. Only text-like types are compressed, bodies under
+``server.compression.minimum_size`` bytes are sent as they are, and
+server-sent event streams are never touched (buffering would stall them).
+Streamed bodies (vector export) are flushed chunk by chunk.
+
+``BodyLimitMiddleware`` caps request bodies before handlers read them:
+``indexing.file.max_size_mb`` for uploads (``/ingest``),
+``indexing.archive.max_upload_mb`` for archive uploads
+(``/stores/{id}/ingest/archive``), ``indexing.upload.max_batch_mb`` for
+batch uploads (``/stores/{id}/upload``), ``server.limits.webhook_body_mb``
+for ``/webhooks`` and ``server.limits.body_mb`` for everything else. A declared
+``Content-Length`` over the limit is refused up front. Chunked bodies are
+counted as they arrive and cut off at the limit. Either way the client gets
+a 413 with the limit in the message.
+
+Both are plain ASGI middleware so streaming responses keep streaming.
+"""
+
+import json
+import logging
+import re
+import zlib
+from typing import Dict, Optional
+
+from fastapi import HTTPException
+from starlette.datastructures import Headers, MutableHeaders
+
+from src.core.config import settings
+from src.core.errors import error_body
+
+logger = logging.getLogger(__name__)
+
+MB = 1024 * 1024
+
+# Content types worth compressing (prefix match)
+COMPRESSIBLE_TYPES = (
+    "application/json", "application/x-ndjson", "application/javascript",
+    "application/xml", "text/",
+)
+# Never compressed: each event must reach the client when it is sent
+STREAMING_TYPES = ("text/event-stream",)
+
+# zlib window bits: gzip container, zlib container (HTTP "deflate")
+WBITS = {"gzip": 31, "deflate": 15}
+
+
+def choose_encoding(accept_encoding: str) -> Optional[str]:
+    """gzip or deflate from an ``Accept-Encoding`` header (q-values honored)."""
+    weights: Dict[str, float] = {}
+    for part in accept_encoding.split(","):
+        name, _, params = part.strip().partition(";")
+        q = 1.0
+        for param in params.split(";"):
+            key, _, value = param.strip().partition("=")
+            if key == "q":
+                try:
+                    q = float(value)
+                except ValueError:
+                    q = 0.0
+        if name:
+            weights[name.strip().lower()] = q
+    candidates = [(weights.get(enc, weights.get("*", 0.0)), enc) for enc in ("gzip", "deflate")]
+    q, encoding = max(candidates, key=lambda c: c[0])  # gzip wins ties
+    return encoding if q > 0 else None
+
+
+def compressible(content_type: Optional[str]) -> bool:
+    if not content_type:
+        return False
+    content_type = content_type.lower()
+    if content_type.startswith(STREAMING_TYPES):
+        return False
+    return content_type.startswith(COMPRESSIBLE_TYPES)
+
+
+class _CompressingSend:
+    """``send`` wrapper deciding on the first body message whether to compress."""
+
+    def __init__(self, send, encoding: str, minimum_size: int, level: int):
+        self.send = send
+        self.encoding = encoding
+        self.minimum_size = minimum_size
+        self.level = level
+        self.start = None
+        self.compressor = None
+        self.passthrough = False
+
+    async def __call__(self, message):
+        if message["type"] == "http.response.start":
+            self.start = message
+            return
+        if message["type"] != "http.response.body" or self.passthrough:
+            await self.send(message)
+            return
+
+        body = message.get("body", b"")
+        more_body = message.get("more_body", False)
+
+        if self.start is not None:
+            start, self.start = self.start, None
+            headers = MutableHeaders(scope=start)
+            if (
+                "content-encoding" in headers
+                or not compressible(headers.get("content-type"))
+                or (not more_body and len(body) < self.minimum_size)
+            ):
+                self.passthrough = True
+                await self.send(start)
+                await self.send(message)
+                return
+            self.compressor = zlib.compressobj(self.level, zlib.DEFLATED, WBITS[self.encoding])
+            headers["Content-Encoding"] = self.encoding
+            headers.add_vary_header("Accept-Encoding")
+            if more_body:
+                del headers["Content-Length"]
+            else:
+                body = self.compressor.compress(body) + self.compressor.flush()
+                headers["Content-Length"] = str(len(body))
+                await self.send(start)
+                await self.send({"type": "http.response.body", "body": body})
+                return
+            await self.send(start)
+
+        if more_body:
+            chunk = self.compressor.compress(body) + self.compressor.flush(zlib.Z_SYNC_FLUSH)
+        else:
+            chunk = self.compressor.compress(body) + self.compressor.flush()
+        await self.send({"type": "http.response.body", "body": chunk, "more_body": more_body})
+
+
+class CompressionMiddleware:
+    """gzip/deflate response compression (``server.compression``)."""
+
+    def __init__(self, app):
+        self.app = app
+
+    async def __call__(self, scope, receive, send):
+        if scope["type"] != "http" or not settings.get("server.compression.enabled", True):
+            await self.app(scope, receive, send)
+            return
+        encoding = choose_encoding(Headers(scope=scope).get("accept-encoding", ""))
+        if encoding is None:
+            await self.app(scope, receive, send)
+            return
+        wrapped = _CompressingSend(
+            send,
+            encoding,
+            minimum_size=int(settings.get("server.compression.minimum_size", 1024)),
+            level=int(settings.get("server.compression.level", 6)),
+        )
+        await self.app(scope, receive, wrapped)
+
+
+STORE_ROUTE = re.compile(r"^/stores/[^/]+(/.+?)/?$")
+
+# Store routes taking file uploads -> (limit setting, default MB)
+STORE_UPLOAD_LIMITS = {
+    "/ingest/archive": ("indexing.archive.max_upload_mb", 512),
+    "/upload": ("indexing.upload.max_batch_mb", 512),
+}
+
+
+def body_limit(path: str) -> int:
+    """Maximum request body in bytes for a path."""
+    prefix = settings.API_V1_STR
+    store_route = STORE_ROUTE.match(path[len(prefix):]) if path.startswith(prefix) else None
+    upload_limit = STORE_UPLOAD_LIMITS.get(store_route.group(1)) if store_route else None
+    if upload_limit:
+        mb = settings.get(*upload_limit)
+    elif path.startswith(f"{prefix}/ingest"):
+        mb = settings.get("indexing.file.max_size_mb", 100)
+    elif path.startswith(f"{prefix}/webhooks"):
+        mb = settings.get("server.limits.webhook_body_mb", 25)
+    else:
+        mb = settings.get("server.limits.body_mb", 16)
+    return int(float(mb) * MB)
+
+
+def _too_large(limit: int) -> HTTPException:
+    return HTTPException(
+        status_code=413,
+        detail=f"Request body too large (limit {limit / MB:g} MB)",
+    )
+
+
+class BodyLimitMiddleware:
+    """413 for request bodies over the path's limit."""
+
+    def __init__(self, app):
+        self.app = app
+
+    async def __call__(self, scope, receive, send):
+        if scope["type"] != "http" or scope["method"] in ("GET", "HEAD", "OPTIONS"):
+            await self.app(scope, receive, send)
+            return
+
+        limit = body_limit(scope["path"])
+        declared = Headers(scope=scope).get("content-length")
+        if declared and declared.isdigit() and int(declared) > limit:
+            logger.info(f"Refused {scope['method']} {scope['path']}: {declared} bytes over {limit}")
+            await self._reject(send, _too_large(limit))
+            return
+
+        received = 0
+        started = False
+
+        async def limited_receive():
+            nonlocal received
+            message = await receive()
+            if message["type"] == "http.request":
+                received += len(message.get("body", b""))
+                if received > limit:
+                    # FastAPI re-raises HTTPExceptions from body parsing as-is
+                    raise _too_large(limit)
+            return message
+
+        async def tracking_send(message):
+            nonlocal started
+            if message["type"] == "http.response.start":
+                started = True
+            await send(message)
+
+        try:
+            await self.app(scope, limited_receive, tracking_send)
+        except HTTPException as e:
+            if e.status_code != 413 or started:
+                raise
+            await self._reject(send, e)
+
+    @staticmethod
+    async def _reject(send, error: HTTPException):
+        body = json.dumps(error_body(413, error.detail)).encode()
+        await send({
+            "type": "http.response.start",
+            "status": 413,
+            "headers": [
+                (b"content-type", b"application/json"),
+                (b"content-length", str(len(body)).encode()),
+                (b"connection", b"close"),
+            ],
+        })
+        await send({"type": "http.response.body", "body": body})
@@ -0,0 +1,282 @@
+"""
+Request handling middleware.
+
+The chain every API request goes through, outermost first:
+
+- ``RequestIdMiddleware``: assigns the request ID (the client's
+  ``X-Request-ID`` or a new one) that error bodies and logs carry and
+  echoes it on the response (see ``src/core/errors.py``)
+- ``RequestMetricsMiddleware``: per-route latency histogram and status
+  counts (``rice_search_http_*`` on ``/metrics``), a warning for requests
+  slower than ``server.slow_request_ms`` and an error log for 5xx
+- ``RecoveryMiddleware``: an exception escaping a handler is logged with
+  its route, request ID and an error ID and answered with a JSON 500. A handler that
+  fails after its response started (a stream) is logged and the stream is
+  cut off, so the client sees an incomplete response rather than a
+  truncated one that looks complete
+- ``RateLimitMiddleware``: fixed-window limit per caller
+  (``server.rate_limit``), keyed by the bearer token's verified subject
+  (X-User-ID only while auth is off, since any client can send it) or the
+  client address; 429 with Retry-After when exceeded
+
+Authentication stays in the route dependencies (``get_current_user``,
+``requires_role``). All four are plain ASGI middleware so streaming
+responses keep streaming.
+"""
+
+import json
+import logging
+import threading
+import time
+from typing import Dict, List, Optional, Tuple
+from uuid import uuid4
+
+from fastapi import HTTPException
+from starlette.datastructures import Headers, MutableHeaders
+
+from src.core.config import settings
+from src.core.errors import (
+    RATE_LIMITED,
+    REQUEST_ID_HEADER,
+    current_request_id,
+    error_body,
+    new_request_id,
+    reset_request_id,
+    set_request_id,
+)
+from src.core.security import token_subject
+
+logger = logging.getLogger(__name__)
+
+# Latency histogram buckets (seconds)
+BUCKETS = (0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1.0, 2.5, 5.0, 10.0, 30.0)
+
+# Requests that matched no route share one label (keeps scanners from adding series)
+UNMATCHED = "unmatched"
+
+
+def route_template(scope) -> str:
+    """Route path template (``/api/v1/stores/{store_id}``) a request matched."""
+    route = scope.get("route")
+    return getattr(route, "path", None) or UNMATCHED
+
+
+class RouteMetrics:
+    """In-process per-route latency histograms and status counts."""
+
+    def __init__(self):
+        self._lock = threading.Lock()
+        # (method, route) -> [bucket counts..., count, sum]
+        self._latency: Dict[Tuple[str, str], List[float]] = {}
+        self._status: Dict[Tuple[str, str, int], int] = {}
+
+    def observe(self, method: str, route: str, status: int, seconds: float):
+        with self._lock:
+            series = self._latency.setdefault((method, route), [0] * len(BUCKETS) + [0, 0.0])
+            for i, bound in enumerate(BUCKETS):
+                if seconds <= bound:
+                    series[i] += 1
+            series[-2] += 1
+            series[-1] += seconds
+            key = (method, route, status)
+            self._status[key] = self._status.get(key, 0) + 1
+
+    def prometheus_lines(self) -> List[str]:
+        """Prometheus exposition lines for ``/metrics``."""
+        with self._lock:
+            latency = {k: list(v) for k, v in self._latency.items()}
+            status = dict(self._status)
+
+        lines = [
+            "# HELP rice_search_http_request_duration_seconds API request latency by route",
+            "# TYPE rice_search_http_request_duration_seconds histogram",
+        ]
+        for (method, route), series in sorted(latency.items()):
+            labels = f'method="{method}",route="{route}"'
+            for bound, count in zip(BUCKETS, series):
+                lines.append(f'rice_search_http_request_duration_seconds_bucket{{{labels},le="{bound:g}"}} {count}')
+            lines.append(f'rice_search_http_request_duration_seconds_bucket{{{labels},le="+Inf"}} {series[-2]}')
+            lines.append(f"rice_search_http_request_duration_seconds_count{{{labels}}} {series[-2]}")
+            lines.append(f"rice_search_http_request_duration_seconds_sum{{{labels}}} {series[-1]:.6f}")
+
+        lines.append("# HELP rice_search_http_responses_total API responses by route and status")
+        lines.append("# TYPE rice_search_http_responses_total counter")
+        for (method, route, code), count in sorted(status.items()):
+            lines.append(f'rice_search_http_responses_total{{method="{method}",route="{route}",status="{code}"}} {count}')
+        return lines
+
+
+async def _send_json(send, status: int, payload: dict, headers: Optional[List[Tuple[bytes, bytes]]] = None):
+    body = json.dumps(payload).encode()
+    await send({
+        "type": "http.response.start",
+        "status": status,
+        "headers": [
+            (b"content-type", b"application/json"),
+            (b"content-length", str(len(body)).encode()),
+            *(headers or []),
+        ],
+    })
+    await send({"type": "http.response.body", "body": body})
+
+
+class RequestIdMiddleware:
+    """Request ID for correlating responses with server logs."""
+
+    def __init__(self, app):
+        self.app = app
+
+    async def __call__(self, scope, receive, send):
+        if scope["type"] != "http":
+            await self.app(scope, receive, send)
+            return
+
+        request_id = new_request_id(Headers(scope=scope).get(REQUEST_ID_HEADER))
+        token = set_request_id(request_id)
+
+        async def tagging_send(message):
+            if message["type"] == "http.response.start":
+                MutableHeaders(scope=message)[REQUEST_ID_HEADER] = request_id
+            await send(message)
+
+        try:
+            await self.app(scope, receive, tagging_send)
+        finally:
+            reset_request_id(token)
+
+
+class RequestMetricsMiddleware:
+    """Per-route latency and status metrics, slow-request and 5xx logging."""
+
+    def __init__(self, app):
+        self.app = app
+
+    async def __call__(self, scope, receive, send):
+        if scope["type"] != "http":
+            await self.app(scope, receive, send)
+            return
+
+        started = time.perf_counter()
+        status = 500
+
+        async def recording_send(message):
+            nonlocal status
+            if message["type"] == "http.response.start":
+                status = message["status"]
+            await send(message)
+
+        try:
+            await self.app(scope, receive, recording_send)
+        finally:
+            # Streams are measured to their last byte
+            elapsed = time.perf_counter() - started
+            method, route = scope["method"], route_template(scope)
+            get_route_metrics().observe(method, route, status, elapsed)
+            where = f"{method} {scope['path']} ({route}) -> {status} in {elapsed * 1000:.0f}ms"
+            if status >= 500:
+                logger.error(f"{where}, request {current_request_id()}")
+            elif elapsed * 1000 >= float(settings.get("server.slow_request_ms", 5000)):
+                logger.warning(f"Slow request: {where}, request {current_request_id()}")
+
+
+class RecoveryMiddleware:
+    """JSON 500 (with an error ID in the log) for exceptions escaping handlers."""
+
+    def __init__(self, app):
+        self.app = app
+
+    async def __call__(self, scope, receive, send):
+        if scope["type"] != "http":
+            await self.app(scope, receive, send)
+            return
+
+        response_started = False
+
+        async def tracking_send(message):
+            nonlocal response_started
+            if message["type"] == "http.response.start":
+                response_started = True
+            await send(message)
+
+        try:
+            await self.app(scope, receive, tracking_send)
+        except HTTPException as e:
+            if response_started:
+                raise
+            await _send_json(send, e.status_code, error_body(e.status_code, e.detail, getattr(e, "code", None)))
+        except Exception:
+            error_id = uuid4().hex[:12]
+            where = f"{scope['method']} {scope['path']} ({route_template(scope)}), request {current_request_id()}"
+            if response_started:
+                # Returning without finishing the body aborts the stream
+                logger.exception(f"Handler failed mid-response for {where}, error {error_id}; stream cut off")
+                return
+            logger.exception(f"Unhandled error in {where}, error {error_id}")
+            await _send_json(send, 500, {**error_body(500, "Internal server error"), "error_id": error_id})
+
+
+async def rate_limit_key(scope) -> str:
+    """
+    Caller identity for rate limiting: the subject of a valid bearer token
+    (X-User-ID instead while auth is off), else the client address. An
+    unverified identity would let a client pick a fresh key per request.
+    """
+    headers = Headers(scope=scope)
+    if not settings.AUTH_ENABLED:
+        user = headers.get("x-user-id")
+        if user:
+            return f"user:{user}"
+    else:
+        scheme, _, token = (headers.get("authorization") or "").partition(" ")
+        if scheme.lower() == "bearer" and token.strip():
+            subject = await token_subject(token.strip())
+            if subject:
+                return f"user:{subject}"
+    client = scope.get("client")
+    return f"ip:{client[0] if client else 'unknown'}"
+
+
+class RateLimitMiddleware:
+    """Fixed-window per-caller request limit (``server.rate_limit``)."""
+
+    def __init__(self, app):
+        self.app = app
+
+    async def __call__(self, scope, receive, send):
+        if (
+            scope["type"] != "http"
+            or scope["method"] == "OPTIONS"
+            or not settings.get("server.rate_limit.enabled", False)
+            or any(scope["path"].startswith(p) for p in settings.get("server.rate_limit.exempt_paths", []) or [])
+        ):
+            await self.app(scope, receive, send)
+            return
+
+        from src.services.admin.rate_limit import get_rate_limiter
+
+        key = await rate_limit_key(scope)
+        allowed, retry_after = get_rate_limiter().hit(
+            f"api:{key}",
+            int(settings.get("server.rate_limit.requests", 600)),
+            int(settings.get("server.rate_limit.window_seconds", 60)),
+        )
+        if not allowed:
+            logger.info(f"Rate limited {key}: {scope['method']} {scope['path']}")
+            await _send_json(
+                send, 429,
+                error_body(429, f"Rate limit exceeded, retry in {retry_after}s", RATE_LIMITED),
+                [(b"retry-after", str(retry_after).encode())],
+            )
+            return
+        await self.app(scope, receive, send)
+
+
+# Singleton instance
+_route_metrics: Optional[RouteMetrics] = None
+
+def get_route_metrics() -> RouteMetrics:
+    """Get global route metrics instance."""
+    global _route_metrics
+    if _route_metrics is None:
+        _route_metrics = RouteMetrics()
+    return _route_metrics
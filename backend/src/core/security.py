@@ -4,6 +4,7 @@ from jwt.algorithms import RSAAlgorithm
 import json
 from fastapi import HTTPException, status
 import os
+from typing import Optional
 
 # Settings
 KEYCLOAK_URL = os.getenv("KEYCLOAK_URL", "http://keycloak:8080")
@@ -79,3 +80,11 @@ def verify_token(token: str, jwks: dict) -> dict:
             detail=f"Invalid token: {str(e)}",
             headers={"WWW-Authenticate": "Bearer"},
         )
+
+
+async def token_subject(token: str) -> Optional[str]:
+    """Subject of a token that verifies, None otherwise (never raises)."""
+    try:
+        return verify_token(token, await get_public_keys()).get("sub")
+    except Exception:
+        return None
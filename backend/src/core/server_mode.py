@@ -0,0 +1,207 @@
+"""
+Server Modes.
+
+Switches for migrations and Qdrant upgrades, without stopping the API:
+
+- ``normal``: everything is served
+- ``read_only``: searches and other reads are served; anything that
+  would change the index or server state (uploads, deletes, store and
+  settings changes) gets 503 ``read_only`` with the reason
+- ``maintenance``: everything but health checks gets 503 ``maintenance``
+
+The mode set with ``PUT /api/v1/admin/public/server/mode`` is kept in
+Redis so every API replica follows it; ``server.mode.default`` (and
+``server.mode.reason``) apply until one is set. Each process re-reads it
+at most every ``server.mode.cache_seconds``.
+
+``/readyz`` reports the mode; ``/readyz/read`` and ``/readyz/write`` let a
+load balancer route reads and writes separately.
+"""
+
+import json
+import logging
+import threading
+import time
+from datetime import datetime
+from typing import Any, Dict, Optional
+
+import redis
+
+from src.core.config import settings
+from src.core.errors import MAINTENANCE as MAINTENANCE_CODE, READ_ONLY as READ_ONLY_CODE, error_body
+
+logger = logging.getLogger(__name__)
+
+NORMAL = "normal"
+READ_ONLY = "read_only"
+MAINTENANCE = "maintenance"
+
+MODES = (NORMAL, READ_ONLY, MAINTENANCE)
+
+MUTATING_METHODS = ("POST", "PUT", "PATCH", "DELETE")
+
+# Health checks and the switch itself answer in every mode
+ALWAYS_ALLOWED = (
+    "/health",
+    "/healthz",
+    "/readyz",
+    "/metrics",
+    "/api/v1/health",
+    "/api/v1/admin/public/server/mode",
+)
+
+# POSTs that only read (search bodies, embedding, hash pre-flight);
+# search feedback is a write
+READ_ONLY_POSTS = (
+    "/api/v1/search/query",
+    "/api/v1/search/stream",
+    "/api/v1/search/batch",
+    "/api/v1/search/inspect",
+    "/api/v1/search/export",
+    "/api/v1/search/eval",
+    "/api/v1/embeddings",
+    "/api/v1/ingest/check-hashes",
+)
+
+
+def _matches(path: str, prefixes) -> bool:
+    return any(path == p or path.startswith(p.rstrip("/") + "/") for p in prefixes)
+
+
+def refused(mode: str, method: str, path: str) -> bool:
+    """Whether a request is refused in ``mode``."""
+    if mode == NORMAL or method == "OPTIONS" or _matches(path, ALWAYS_ALLOWED):
+        return False
+    if mode == MAINTENANCE:
+        return True
+    if method not in MUTATING_METHODS:
+        return False
+    allowed = READ_ONLY_POSTS + tuple(settings.get("server.mode.read_only_allow", []) or [])
+    return not (method == "POST" and _matches(path, allowed))
+
+
+class ServerMode:
+    """The current mode, shared through Redis and cached per process."""
+
+    KEY = "rice:server:mode"
+
+    def __init__(self, redis_client=None):
+        self._redis = redis_client
+        self._lock = threading.Lock()
+        self._cached: Optional[Dict[str, Any]] = None
+        self._cached_at = 0.0
+
+    @property
+    def redis(self) -> redis.Redis:
+        """Lazy Redis connection."""
+        if self._redis is None:
+            self._redis = redis.from_url(settings.REDIS_URL, decode_responses=True)
+        return self._redis
+
+    def _default(self) -> Dict[str, Any]:
+        mode = settings.get("server.mode.default", NORMAL)
+        return {
+            "mode": mode if mode in MODES else NORMAL,
+            "reason": settings.get("server.mode.reason") or None,
+            "since": None,
+            "set_by": None,
+        }
+
+    def get(self) -> Dict[str, Any]:
+        """``mode``, ``reason``, ``since`` and ``set_by``."""
+        ttl = float(settings.get("server.mode.cache_seconds", 2))
+        with self._lock:
+            if self._cached is not None and time.monotonic() - self._cached_at < ttl:
+                return self._cached
+        try:
+            data = self.redis.get(self.KEY)
+            state = json.loads(data) if data else self._default()
+        except Exception as e:
+            # Keep the last known mode while Redis is away
+            logger.warning(f"Could not read server mode: {e}")
+            return self._cached or self._default()
+        with self._lock:
+            self._cached, self._cached_at = state, time.monotonic()
+        return state
+
+    @property
+    def mode(self) -> str:
+        return self.get()["mode"]
+
+    def set(self, mode: str, reason: Optional[str] = None, user: str = "system") -> Dict[str, Any]:
+        """
+        Switch every replica to ``mode``.
+
+        Raises:
+            ValueError: unknown mode
+        """
+        if mode not in MODES:
+            raise ValueError(f"mode must be one of {', '.join(MODES)}")
+        state = {
+            "mode": mode,
+            "reason": (reason or "").strip() or None,
+            "since": datetime.now().isoformat(),
+            "set_by": user,
+        }
+        self.redis.set(self.KEY, json.dumps(state))
+        with self._lock:
+            self._cached, self._cached_at = state, time.monotonic()
+        return state
+
+    def readiness(self, traffic: Optional[str] = None) -> bool:
+        """
+        Whether to route ``traffic`` here: ``read``, ``write`` or (None)
+        any. Draining is handled by ``/readyz`` itself.
+        """
+        mode = self.mode
+        if mode == MAINTENANCE:
+            return False
+        if traffic == "write":
+            return mode == NORMAL
+        return True
+
+
+def refusal_detail(state: Dict[str, Any]) -> str:
+    what = "in maintenance" if state["mode"] == MAINTENANCE else "read-only"
+    return f"Server is {what}" + (f": {state['reason']}" if state.get("reason") else "")
+
+
+class ServerModeMiddleware:
+    """Refuses requests the current mode doesn't allow (503 with the reason)."""
+
+    def __init__(self, app):
+        self.app = app
+
+    async def __call__(self, scope, receive, send):
+        if scope["type"] == "websocket" and get_server_mode().mode == MAINTENANCE:
+            # Live search sockets are reads: only refused in maintenance (1013: try again later)
+            await send({"type": "websocket.close", "code": 1013})
+            return
+        if scope["type"] != "http":
+            await self.app(scope, receive, send)
+            return
+
+        state = get_server_mode().get()
+        if not refused(state["mode"], scope["method"], scope["path"]):
+            await self.app(scope, receive, send)
+            return
+
+        from src.core.request_middleware import _send_json
+        code = MAINTENANCE_CODE if state["mode"] == MAINTENANCE else READ_ONLY_CODE
+        retry_after = str(int(settings.get("server.mode.retry_after_seconds", 60)))
+        await _send_json(
+            send, 503,
+            {**error_body(503, refusal_detail(state), code), "mode": state},
+            [(b"retry-after", retry_after.encode())],
+        )
+
+
+# Singleton instance
+_server_mode: Optional[ServerMode] = None
+
+def get_server_mode() -> ServerMode:
+    """Get global server mode instance."""
+    global _server_mode
+    if _server_mode is None:
+        _server_mode = ServerMode()
+    return _server_mode
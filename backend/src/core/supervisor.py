@@ -0,0 +1,280 @@
+"""
+Background task supervisor.
+
+Every long-lived or fire-and-forget thread in a process (model TTL monitor,
+alert delivery, metrics flush, shutdown drain, event bus transport, ML
+worker consumers) is started through
+the supervisor instead of a bare ``threading.Thread``, so that:
+
+- each task is named, grouped and listed with its state, run count and last
+  error (``GET /api/v1/admin/public/system/tasks``);
+- a crashing task is logged with its name rather than dying silently;
+- shutdown stops tasks group by group in a fixed order (consumers before
+  the transport they read from) and waits for each within a timeout.
+
+Tasks get a ``threading.Event`` that is set when they should stop; loops
+started with ``spawn_loop`` wait on it between runs.
+"""
+import logging
+import threading
+import time
+import uuid
+from collections import deque
+from datetime import datetime
+from typing import Any, Callable, Deque, Dict, List, Optional
+
+from src.core.config import settings
+
+logger = logging.getLogger(__name__)
+
+# Shutdown order: lower stops first
+ORDER_CONSUMERS = 10   # stop taking new work
+ORDER_DEFAULT = 50
+ORDER_MODELS = 80
+ORDER_TRANSPORT = 100  # bus connections other tasks may still be using
+
+RUNNING = "running"
+STOPPING = "stopping"
+FINISHED = "finished"
+FAILED = "failed"
+STOPPED = "stopped"
+
+
+class SupervisedTask:
+    """A background thread owned by the supervisor."""
+
+    def __init__(
+        self,
+        name: str,
+        group: str,
+        order: int,
+        on_stop: Optional[Callable[[], None]] = None,
+    ):
+        self.id = uuid.uuid4().hex[:12]
+        self.name = name
+        self.group = group
+        self.order = order
+        self.on_stop = on_stop
+        self.stop_event = threading.Event()
+        self.state = RUNNING
+        self.started_at = datetime.now().isoformat()
+        self.finished_at: Optional[str] = None
+        self.runs = 0
+        self.errors = 0
+        self.last_error: Optional[str] = None
+        self.last_run_at: Optional[str] = None
+        self.thread: Optional[threading.Thread] = None
+
+    @property
+    def alive(self) -> bool:
+        return self.thread is not None and self.thread.is_alive()
+
+    def stop(self):
+        """Ask the task to stop (does not wait)."""
+        if self.state == RUNNING:
+            self.state = STOPPING
+        self.stop_event.set()
+        if self.on_stop:
+            try:
+                self.on_stop()
+            except Exception as e:
+                logger.warning(f"Stop hook of task {self.name} failed: {e}")
+
+    def join(self, timeout: Optional[float] = None) -> bool:
+        """Wait for the thread; True if it has exited."""
+        if self.thread is not None:
+            self.thread.join(timeout)
+        return not self.alive
+
+    def to_dict(self) -> Dict[str, Any]:
+        return {
+            "id": self.id,
+            "name": self.name,
+            "group": self.group,
+            "order": self.order,
+            "state": self.state,
+            "alive": self.alive,
+            "started_at": self.started_at,
+            "finished_at": self.finished_at,
+            "runs": self.runs,
+            "errors": self.errors,
+            "last_error": self.last_error,
+            "last_run_at": self.last_run_at,
+        }
+
+
+class TaskSupervisor:
+    """Tracks background tasks and shuts them down in order."""
+
+    def __init__(self, history: Optional[int] = None):
+        self._tasks: Dict[str, SupervisedTask] = {}
+        self._finished: Deque[SupervisedTask] = deque(
+            maxlen=history or int(settings.get("supervisor.history", 100))
+        )
+        self._lock = threading.Lock()
+        self._shutting_down = False
+
+    def spawn(
+        self,
+        name: str,
+        target: Callable[..., Any],
+        *args,
+        group: str = "default",
+        order: int = ORDER_DEFAULT,
+        pass_stop: bool = False,
+        on_stop: Optional[Callable[[], None]] = None,
+        **kwargs,
+    ) -> SupervisedTask:
+        """
+        Run ``target(*args, **kwargs)`` in a supervised thread.
+
+        Args:
+            name: Task name shown in diagnostics and thread dumps
+            group: Group the task belongs to (e.g. "alerts", "bus")
+            order: Shutdown order; lower groups are stopped and joined first
+            pass_stop: Pass the task's stop event to the target as ``stop=``
+            on_stop: Called when the task is asked to stop, for targets that
+                block on something other than the stop event
+
+        Returns:
+            The task (already started)
+        """
+        task = SupervisedTask(name, group, order, on_stop)
+        if pass_stop:
+            kwargs["stop"] = task.stop_event
+
+        def run():
+            task.runs = 1
+            task.last_run_at = datetime.now().isoformat()
+            try:
+                target(*args, **kwargs)
+                self._finish(task, STOPPED if task.stop_event.is_set() else FINISHED)
+            except Exception as e:
+                logger.exception(f"Task {name} crashed: {e}")
+                task.errors += 1
+                task.last_error = str(e)
+                self._finish(task, FAILED)
+
+        return self._start(task, run)
+
+    def spawn_loop(
+        self,
+        name: str,
+        fn: Callable[[], Any],
+        interval: float,
+        group: str = "default",
+        order: int = ORDER_DEFAULT,
+        run_first: bool = False,
+    ) -> SupervisedTask:
+        """
+        Call ``fn()`` every ``interval`` seconds until stopped.
+
+        Errors are logged and counted; the loop keeps running. Stopping
+        interrupts the wait, not a run in progress.
+        """
+        task = SupervisedTask(name, group, order)
+
+        def run():
+            if not run_first and task.stop_event.wait(interval):
+                self._finish(task, STOPPED)
+                return
+            while True:
+                task.runs += 1
+                task.last_run_at = datetime.now().isoformat()
+                try:
+                    fn()
+                except Exception as e:
+                    logger.error(f"Error in task {name}: {e}")
+                    task.errors += 1
+                    task.last_error = str(e)
+                if task.stop_event.wait(interval):
+                    break
+            self._finish(task, STOPPED)
+
+        return self._start(task, run)
+
+    def _start(self, task: SupervisedTask, run: Callable[[], None]) -> SupervisedTask:
+        if self._shutting_down:
+            # Late spawns during shutdown would outlive the ordered stop
+            logger.warning(f"Not starting task {task.name}: shutting down")
+            task.stop_event.set()
+            task.state = STOPPED
+            task.finished_at = datetime.now().isoformat()
+            return task
+        task.thread = threading.Thread(target=run, name=task.name, daemon=True)
+        with self._lock:
+            self._tasks[task.id] = task
+        task.thread.start()
+        return task
+
+    def _finish(self, task: SupervisedTask, state: str):
+        task.state = state
+        task.finished_at = datetime.now().isoformat()
+        with self._lock:
+            if self._tasks.pop(task.id, None) is not None:
+                self._finished.append(task)
+
+    def tasks(self, include_finished: bool = True) -> List[Dict[str, Any]]:
+        """Running tasks (and recently finished ones), by shutdown order."""
+        with self._lock:
+            tasks = list(self._tasks.values())
+            if include_finished:
+                tasks += list(self._finished)
+        return [t.to_dict() for t in sorted(tasks, key=lambda t: (t.order, t.group, t.name, t.started_at))]
+
+    def summary(self) -> Dict[str, Any]:
+        with self._lock:
+            running = list(self._tasks.values())
+            finished = list(self._finished)
+        groups: Dict[str, int] = {}
+        for task in running:
+            groups[task.group] = groups.get(task.group, 0) + 1
+        return {
+            "running": len(running),
+            "failed": sum(1 for t in finished if t.state == FAILED),
+            "groups": groups,
+            "shutting_down": self._shutting_down,
+        }
+
+    def shutdown(self, timeout: Optional[float] = None) -> List[str]:
+        """
+        Stop all tasks, lowest order first.
+
+        Each order level is signalled together, then joined within
+        ``timeout`` seconds (``supervisor.shutdown_timeout_seconds``) before
+        the next level is stopped.
+
+        Returns:
+            Names of tasks still alive after their timeout
+        """
+        if timeout is None:
+            timeout = float(settings.get("supervisor.shutdown_timeout_seconds", 10))
+        self._shutting_down = True
+        with self._lock:
+            tasks = list(self._tasks.values())
+
+        leftover = []
+        for order in sorted({t.order for t in tasks}):
+            level = [t for t in tasks if t.order == order]
+            for task in level:
+                task.stop()
+            deadline = time.monotonic() + timeout
+            for task in level:
+                if not task.join(max(0.0, deadline - time.monotonic())):
+                    logger.warning(f"Task {task.name} ({task.group}) did not stop within {timeout}s")
+                    leftover.append(task.name)
+        if tasks:
+            logger.info(f"Supervisor stopped {len(tasks) - len(leftover)}/{len(tasks)} tasks")
+        return leftover
+
+
+# Singleton instance
+_supervisor: Optional[TaskSupervisor] = None
+
+
+def get_supervisor() -> TaskSupervisor:
+    """Get or create the process-wide supervisor."""
+    global _supervisor
+    if _supervisor is None:
+        _supervisor = TaskSupervisor()
+    return _supervisor
@@ -0,0 +1,36 @@
+"""
+Bundled demo datasets.
+
+Small corpora shipped with the server so a fresh install can be searched
+(and evaluated) without indexing a private repository first. Each dataset
+is a directory of source files plus an optional ``queries.yaml`` labeled
+query set for ``ricesearch eval``.
+"""
+
+from pathlib import Path
+from typing import Dict, List
+
+DATASETS_DIR = Path(__file__).parent
+
+DATASETS: Dict[str, Dict[str, str]] = {
+    "go-stdlib-sample": {
+        "dir": "go_stdlib_sample",
+        "description": "Simplified Go standard library packages (strings, sync, net/http, ...)",
+    },
+}
+
+
+def dataset_path(name: str) -> Path:
+    """Directory of a bundled dataset."""
+    if name not in DATASETS:
+        raise KeyError(f"Unknown dataset '{name}' (available: {', '.join(DATASETS)})")
+    return DATASETS_DIR / DATASETS[name]["dir"]
+
+
+def dataset_files(name: str) -> List[Path]:
+    """Source files of a dataset, excluding its query set."""
+    root = dataset_path(name)
+    return sorted(
+        p for p in root.rglob("*")
+        if p.is_file() and p.name != "queries.yaml" and not p.name.endswith(".pyc")
+    )
@@ -0,0 +1,81 @@
+// Package bytes implements functions for the manipulation of byte slices.
+package bytes
+
+import (
+	"errors"
+	"io"
+)
+
+// ErrTooLarge is passed to panic if memory cannot be allocated to store data
+// in a buffer.
+var ErrTooLarge = errors.New("bytes.Buffer: too large")
+
+// A Buffer is a variable-sized buffer of bytes with Read and Write methods.
+// The zero value for Buffer is an empty buffer ready to use.
+type Buffer struct {
+	buf []byte // contents are the bytes buf[off : len(buf)]
+	off int    // read at &buf[off], write at &buf[len(buf)]
+}
+
+// NewBuffer creates and initializes a new Buffer using buf as its initial
+// contents.
+func NewBuffer(buf []byte) *Buffer { return &Buffer{buf: buf} }
+
+// Bytes returns a slice holding the unread portion of the buffer.
+func (b *Buffer) Bytes() []byte { return b.buf[b.off:] }
+
+// String returns the contents of the unread portion of the buffer as a string.
+func (b *Buffer) String() string {
+	if b == nil {
+		return "<nil>"
+	}
+	return string(b.buf[b.off:])
+}
+
+// Len returns the number of bytes of the unread portion of the buffer.
+func (b *Buffer) Len() int { return len(b.buf) - b.off }
+
+// Reset resets the buffer to be empty, but it retains the underlying storage
+// for use by future writes.
+func (b *Buffer) Reset() {
+	b.buf = b.buf[:0]
+	b.off = 0
+}
+
+// Write appends the contents of p to the buffer, growing the buffer as
+// needed.
+func (b *Buffer) Write(p []byte) (n int, err error) {
+	b.buf = append(b.buf, p...)
+	return len(p), nil
+}
+
+// Read reads the next len(p) bytes from the buffer or until the buffer is
+// drained. If the buffer has no data to return, err is io.EOF.
+func (b *Buffer) Read(p []byte) (n int, err error) {
+	if b.off >= len(b.buf) {
+		b.Reset()
+		if len(p) == 0 {
+			return 0, nil
+		}
+		return 0, io.EOF
+	}
+	n = copy(p, b.buf[b.off:])
+	b.off += n
+	return n, nil
+}
+
+// ReadFrom reads data from r until EOF and appends it to the buffer.
+func (b *Buffer) ReadFrom(r io.Reader) (n int64, err error) {
+	tmp := make([]byte, 512)
+	for {
+		m, e := r.Read(tmp)
+		b.buf = append(b.buf, tmp[:m]...)
+		n += int64(m)
+		if e == io.EOF {
+			return n, nil
+		}
+		if e != nil {
+			return n, e
+		}
+	}
+}
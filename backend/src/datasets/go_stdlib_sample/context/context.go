@@ -0,0 +1,82 @@
+// Package context defines the Context type, which carries deadlines,
+// cancellation signals, and other request-scoped values across API
+// boundaries and between processes.
+package context
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// A Context carries a deadline, a cancellation signal, and other values
+// across API boundaries.
+type Context interface {
+	Deadline() (deadline time.Time, ok bool)
+	Done() <-chan struct{}
+	Err() error
+	Value(key any) any
+}
+
+// Canceled is the error returned by Context.Err when the context is canceled.
+var Canceled = errors.New("context canceled")
+
+// DeadlineExceeded is the error returned by Context.Err when the context's
+// deadline passes.
+var DeadlineExceeded = errors.New("context deadline exceeded")
+
+// A CancelFunc tells an operation to abandon its work.
+type CancelFunc func()
+
+type cancelCtx struct {
+	Context
+	mu   sync.Mutex
+	done chan struct{}
+	err  error
+}
+
+func (c *cancelCtx) Done() <-chan struct{} { return c.done }
+
+func (c *cancelCtx) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+func (c *cancelCtx) cancel(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return
+	}
+	c.err = err
+	close(c.done)
+}
+
+// WithCancel returns a copy of parent with a new Done channel. The returned
+// context's Done channel is closed when the returned cancel function is
+// called or when the parent context's Done channel is closed.
+func WithCancel(parent Context) (Context, CancelFunc) {
+	c := &cancelCtx{Context: parent, done: make(chan struct{})}
+	go func() {
+		select {
+		case <-parent.Done():
+			c.cancel(parent.Err())
+		case <-c.done:
+		}
+	}()
+	return c, func() { c.cancel(Canceled) }
+}
+
+// WithTimeout returns WithDeadline(parent, time.Now().Add(timeout)).
+func WithTimeout(parent Context, timeout time.Duration) (Context, CancelFunc) {
+	ctx, cancel := WithCancel(parent)
+	go func() {
+		select {
+		case <-time.After(timeout):
+			ctx.(*cancelCtx).cancel(DeadlineExceeded)
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, cancel
+}
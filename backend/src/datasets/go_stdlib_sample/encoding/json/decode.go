@@ -0,0 +1,89 @@
+package json
+
+import (
+	"errors"
+	"reflect"
+)
+
+// Unmarshaler is the interface implemented by types that can unmarshal a
+// JSON description of themselves.
+type Unmarshaler interface {
+	UnmarshalJSON([]byte) error
+}
+
+// A SyntaxError is a description of a JSON syntax error.
+type SyntaxError struct {
+	msg    string // description of error
+	Offset int64  // error occurred after reading Offset bytes
+}
+
+func (e *SyntaxError) Error() string { return e.msg }
+
+// An InvalidUnmarshalError describes an invalid argument passed to
+// Unmarshal. (The argument to Unmarshal must be a non-nil pointer.)
+type InvalidUnmarshalError struct {
+	Type reflect.Type
+}
+
+func (e *InvalidUnmarshalError) Error() string {
+	if e.Type == nil {
+		return "json: Unmarshal(nil)"
+	}
+	return "json: Unmarshal(non-pointer " + e.Type.String() + ")"
+}
+
+// Unmarshal parses the JSON-encoded data and stores the result in the value
+// pointed to by v. If v is nil or not a pointer, Unmarshal returns an
+// InvalidUnmarshalError.
+func Unmarshal(data []byte, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return &InvalidUnmarshalError{reflect.TypeOf(v)}
+	}
+	if !Valid(data) {
+		return &SyntaxError{msg: "invalid character looking for beginning of value"}
+	}
+	d := &decodeState{data: data}
+	return d.value(rv.Elem())
+}
+
+// Valid reports whether data is a valid JSON encoding.
+func Valid(data []byte) bool {
+	depth := 0
+	inString := false
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		switch {
+		case inString && c == '\\':
+			i++
+		case c == '"':
+			inString = !inString
+		case inString:
+		case c == '{' || c == '[':
+			depth++
+		case c == '}' || c == ']':
+			depth--
+			if depth < 0 {
+				return false
+			}
+		}
+	}
+	return depth == 0 && !inString
+}
+
+type decodeState struct {
+	data []byte
+	off  int
+}
+
+var errUnexpectedEnd = errors.New("json: unexpected end of JSON input")
+
+func (d *decodeState) value(v reflect.Value) error {
+	if d.off >= len(d.data) {
+		return errUnexpectedEnd
+	}
+	if u, ok := v.Addr().Interface().(Unmarshaler); ok {
+		return u.UnmarshalJSON(d.data[d.off:])
+	}
+	return d.literal(v)
+}
@@ -0,0 +1,101 @@
+// Package json implements encoding and decoding of JSON as defined in
+// RFC 7159.
+package json
+
+import (
+	"bytes"
+	"reflect"
+	"strconv"
+)
+
+// Marshaler is the interface implemented by types that can marshal
+// themselves into valid JSON.
+type Marshaler interface {
+	MarshalJSON() ([]byte, error)
+}
+
+// An UnsupportedTypeError is returned by Marshal when attempting to encode
+// an unsupported value type.
+type UnsupportedTypeError struct {
+	Type reflect.Type
+}
+
+func (e *UnsupportedTypeError) Error() string {
+	return "json: unsupported type: " + e.Type.String()
+}
+
+// Marshal returns the JSON encoding of v.
+//
+// Struct values encode as JSON objects. Each exported struct field becomes a
+// member of the object, using the field name as the object key, unless the
+// field is omitted via a `json:"-"` struct tag.
+func Marshal(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := encodeValue(&buf, reflect.ValueOf(v)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func encodeValue(buf *bytes.Buffer, v reflect.Value) error {
+	if m, ok := v.Interface().(Marshaler); ok {
+		b, err := m.MarshalJSON()
+		if err != nil {
+			return err
+		}
+		buf.Write(b)
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.Bool:
+		buf.WriteString(strconv.FormatBool(v.Bool()))
+	case reflect.Int, reflect.Int64, reflect.Int32:
+		buf.WriteString(strconv.FormatInt(v.Int(), 10))
+	case reflect.Float64:
+		buf.WriteString(strconv.FormatFloat(v.Float(), 'g', -1, 64))
+	case reflect.String:
+		buf.WriteString(strconv.Quote(v.String()))
+	case reflect.Slice:
+		buf.WriteByte('[')
+		for i := 0; i < v.Len(); i++ {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := encodeValue(buf, v.Index(i)); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	case reflect.Struct:
+		return encodeStruct(buf, v)
+	default:
+		return &UnsupportedTypeError{v.Type()}
+	}
+	return nil
+}
+
+func encodeStruct(buf *bytes.Buffer, v reflect.Value) error {
+	buf.WriteByte('{')
+	first := true
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		name := field.Tag.Get("json")
+		if name == "-" || !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		if !first {
+			buf.WriteByte(',')
+		}
+		first = false
+		buf.WriteString(strconv.Quote(name))
+		buf.WriteByte(':')
+		if err := encodeValue(buf, v.Field(i)); err != nil {
+			return err
+		}
+	}
+	buf.WriteByte('}')
+	return nil
+}
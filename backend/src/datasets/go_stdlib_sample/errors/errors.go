@@ -0,0 +1,53 @@
+// Package errors implements functions to manipulate errors.
+package errors
+
+// New returns an error that formats as the given text. Each call to New
+// returns a distinct error value even if the text is identical.
+func New(text string) error {
+	return &errorString{text}
+}
+
+type errorString struct {
+	s string
+}
+
+func (e *errorString) Error() string {
+	return e.s
+}
+
+// Unwrap returns the result of calling the Unwrap method on err, if err's
+// type contains an Unwrap method returning error. Otherwise, Unwrap returns
+// nil.
+func Unwrap(err error) error {
+	u, ok := err.(interface{ Unwrap() error })
+	if !ok {
+		return nil
+	}
+	return u.Unwrap()
+}
+
+// Is reports whether any error in err's tree matches target.
+func Is(err, target error) bool {
+	for err != nil {
+		if err == target {
+			return true
+		}
+		if x, ok := err.(interface{ Is(error) bool }); ok && x.Is(target) {
+			return true
+		}
+		err = Unwrap(err)
+	}
+	return false
+}
+
+// As finds the first error in err's tree that matches target, and if one is
+// found, sets target to that error value and returns true.
+func As(err error, target func(error) bool) bool {
+	for err != nil {
+		if target(err) {
+			return true
+		}
+		err = Unwrap(err)
+	}
+	return false
+}
@@ -0,0 +1,89 @@
+// Package http provides HTTP client and server implementations.
+package http
+
+import (
+	"errors"
+	"time"
+)
+
+// A Client is an HTTP client. Its zero value is a usable client that uses
+// DefaultTransport.
+type Client struct {
+	// Transport specifies the mechanism by which individual HTTP requests
+	// are made. If nil, DefaultTransport is used.
+	Transport RoundTripper
+
+	// CheckRedirect specifies the policy for handling redirects.
+	CheckRedirect func(req *Request, via []*Request) error
+
+	// Timeout specifies a time limit for requests made by this Client. The
+	// timeout includes connection time, any redirects, and reading the
+	// response body. A Timeout of zero means no timeout.
+	Timeout time.Duration
+}
+
+// DefaultClient is the default Client and is used by Get, Head, and Post.
+var DefaultClient = &Client{}
+
+// ErrUseLastResponse can be returned by Client.CheckRedirect hooks to control
+// how redirects are processed.
+var ErrUseLastResponse = errors.New("net/http: use last response")
+
+const maxRedirects = 10
+
+// Do sends an HTTP request and returns an HTTP response, following policy
+// (such as redirects, cookies, auth) as configured on the client.
+func (c *Client) Do(req *Request) (*Response, error) {
+	var via []*Request
+	for {
+		resp, err := c.send(req)
+		if err != nil {
+			return nil, err
+		}
+		loc := resp.Header.Get("Location")
+		if !isRedirect(resp.StatusCode) || loc == "" {
+			return resp, nil
+		}
+		via = append(via, req)
+		if len(via) >= maxRedirects {
+			return nil, errors.New("stopped after 10 redirects")
+		}
+		if c.CheckRedirect != nil {
+			if err := c.CheckRedirect(req, via); err != nil {
+				if err == ErrUseLastResponse {
+					return resp, nil
+				}
+				return nil, err
+			}
+		}
+		req = req.withURL(loc)
+	}
+}
+
+// Get issues a GET to the specified URL.
+func (c *Client) Get(url string) (*Response, error) {
+	req, err := NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.Do(req)
+}
+
+func (c *Client) send(req *Request) (*Response, error) {
+	rt := c.Transport
+	if rt == nil {
+		rt = DefaultTransport
+	}
+	if c.Timeout > 0 {
+		req = req.withDeadline(time.Now().Add(c.Timeout))
+	}
+	return rt.RoundTrip(req)
+}
+
+func isRedirect(code int) bool {
+	switch code {
+	case 301, 302, 303, 307, 308:
+		return true
+	}
+	return false
+}
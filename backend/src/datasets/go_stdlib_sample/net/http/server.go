@@ -0,0 +1,94 @@
+package http
+
+import (
+	"net"
+	"sync"
+)
+
+// A Handler responds to an HTTP request.
+type Handler interface {
+	ServeHTTP(ResponseWriter, *Request)
+}
+
+// The HandlerFunc type is an adapter to allow the use of ordinary functions
+// as HTTP handlers.
+type HandlerFunc func(ResponseWriter, *Request)
+
+// ServeHTTP calls f(w, r).
+func (f HandlerFunc) ServeHTTP(w ResponseWriter, r *Request) {
+	f(w, r)
+}
+
+// ServeMux is an HTTP request multiplexer. It matches the URL of each
+// incoming request against a list of registered patterns and calls the
+// handler for the pattern that most closely matches the URL.
+type ServeMux struct {
+	mu sync.RWMutex
+	m  map[string]Handler
+}
+
+// NewServeMux allocates and returns a new ServeMux.
+func NewServeMux() *ServeMux { return &ServeMux{m: make(map[string]Handler)} }
+
+// Handle registers the handler for the given pattern.
+func (mux *ServeMux) Handle(pattern string, handler Handler) {
+	mux.mu.Lock()
+	defer mux.mu.Unlock()
+	if _, exists := mux.m[pattern]; exists {
+		panic("http: multiple registrations for " + pattern)
+	}
+	mux.m[pattern] = handler
+}
+
+// HandleFunc registers the handler function for the given pattern.
+func (mux *ServeMux) HandleFunc(pattern string, handler func(ResponseWriter, *Request)) {
+	mux.Handle(pattern, HandlerFunc(handler))
+}
+
+// ServeHTTP dispatches the request to the handler whose pattern most closely
+// matches the request URL, longest prefix first.
+func (mux *ServeMux) ServeHTTP(w ResponseWriter, r *Request) {
+	mux.mu.RLock()
+	var best Handler
+	bestLen := -1
+	for pattern, h := range mux.m {
+		if len(pattern) > bestLen && hasPathPrefix(r.URL.Path, pattern) {
+			best, bestLen = h, len(pattern)
+		}
+	}
+	mux.mu.RUnlock()
+	if best == nil {
+		NotFound(w, r)
+		return
+	}
+	best.ServeHTTP(w, r)
+}
+
+// A Server defines parameters for running an HTTP server.
+type Server struct {
+	Addr    string
+	Handler Handler
+}
+
+// ListenAndServe listens on the TCP network address srv.Addr and then calls
+// Serve to handle requests on incoming connections.
+func (srv *Server) ListenAndServe() error {
+	ln, err := net.Listen("tcp", srv.Addr)
+	if err != nil {
+		return err
+	}
+	return srv.Serve(ln)
+}
+
+// Serve accepts incoming connections on the Listener l, creating a new
+// service goroutine for each.
+func (srv *Server) Serve(l net.Listener) error {
+	defer l.Close()
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return err
+		}
+		go srv.serveConn(conn)
+	}
+}
@@ -0,0 +1,75 @@
+// Package sort provides primitives for sorting slices and user-defined
+// collections.
+package sort
+
+// An implementation of Interface can be sorted by the routines in this
+// package. The methods refer to elements of the underlying collection by
+// integer index.
+type Interface interface {
+	// Len is the number of elements in the collection.
+	Len() int
+	// Less reports whether the element with index i must sort before the
+	// element with index j.
+	Less(i, j int) bool
+	// Swap swaps the elements with indexes i and j.
+	Swap(i, j int)
+}
+
+// Sort sorts data in ascending order as determined by the Less method.
+// The sort is not guaranteed to be stable.
+func Sort(data Interface) {
+	quickSort(data, 0, data.Len()-1)
+}
+
+func quickSort(data Interface, lo, hi int) {
+	if lo >= hi {
+		return
+	}
+	p := lo
+	for i := lo; i < hi; i++ {
+		if data.Less(i, hi) {
+			data.Swap(i, p)
+			p++
+		}
+	}
+	data.Swap(p, hi)
+	quickSort(data, lo, p-1)
+	quickSort(data, p+1, hi)
+}
+
+// Stable sorts data in ascending order as determined by the Less method,
+// while keeping the original order of equal elements.
+func Stable(data Interface) {
+	n := data.Len()
+	for i := 1; i < n; i++ {
+		for j := i; j > 0 && data.Less(j, j-1); j-- {
+			data.Swap(j, j-1)
+		}
+	}
+}
+
+// Search uses binary search to find and return the smallest index i in
+// [0, n) at which f(i) is true.
+func Search(n int, f func(int) bool) int {
+	i, j := 0, n
+	for i < j {
+		h := int(uint(i+j) >> 1)
+		if !f(h) {
+			i = h + 1
+		} else {
+			j = h
+		}
+	}
+	return i
+}
+
+// IntSlice attaches the methods of Interface to []int, sorting in
+// increasing order.
+type IntSlice []int
+
+func (x IntSlice) Len() int           { return len(x) }
+func (x IntSlice) Less(i, j int) bool { return x[i] < x[j] }
+func (x IntSlice) Swap(i, j int)      { x[i], x[j] = x[j], x[i] }
+
+// Ints sorts a slice of ints in increasing order.
+func Ints(x []int) { Sort(IntSlice(x)) }
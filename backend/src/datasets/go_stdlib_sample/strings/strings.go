@@ -0,0 +1,87 @@
+// Package strings implements simple functions to manipulate UTF-8 encoded strings.
+package strings
+
+// Index returns the index of the first instance of substr in s, or -1 if
+// substr is not present in s.
+func Index(s, substr string) int {
+	n := len(substr)
+	switch {
+	case n == 0:
+		return 0
+	case n > len(s):
+		return -1
+	}
+	for i := 0; i+n <= len(s); i++ {
+		if s[i:i+n] == substr {
+			return i
+		}
+	}
+	return -1
+}
+
+// Contains reports whether substr is within s.
+func Contains(s, substr string) bool {
+	return Index(s, substr) >= 0
+}
+
+// HasPrefix reports whether the string s begins with prefix.
+func HasPrefix(s, prefix string) bool {
+	return len(s) >= len(prefix) && s[:len(prefix)] == prefix
+}
+
+// HasSuffix reports whether the string s ends with suffix.
+func HasSuffix(s, suffix string) bool {
+	return len(s) >= len(suffix) && s[len(s)-len(suffix):] == suffix
+}
+
+// Split slices s into all substrings separated by sep and returns a slice of
+// the substrings between those separators.
+func Split(s, sep string) []string {
+	if sep == "" {
+		out := make([]string, 0, len(s))
+		for _, r := range s {
+			out = append(out, string(r))
+		}
+		return out
+	}
+	var out []string
+	for {
+		i := Index(s, sep)
+		if i < 0 {
+			break
+		}
+		out = append(out, s[:i])
+		s = s[i+len(sep):]
+	}
+	return append(out, s)
+}
+
+// Join concatenates the elements of elems to create a single string. The
+// separator string sep is placed between elements in the resulting string.
+func Join(elems []string, sep string) string {
+	var b Builder
+	for i, e := range elems {
+		if i > 0 {
+			b.WriteString(sep)
+		}
+		b.WriteString(e)
+	}
+	return b.String()
+}
+
+// TrimSpace returns a slice of the string s, with all leading and trailing
+// white space removed.
+func TrimSpace(s string) string {
+	start, end := 0, len(s)
+	for start < end && isSpace(s[start]) {
+		start++
+	}
+	for end > start && isSpace(s[end-1]) {
+		end--
+	}
+	return s[start:end]
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\v' || c == '\f'
+}
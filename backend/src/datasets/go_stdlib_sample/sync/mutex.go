@@ -0,0 +1,41 @@
+// Package sync provides basic synchronization primitives such as mutual
+// exclusion locks.
+package sync
+
+import "sync/atomic"
+
+// A Mutex is a mutual exclusion lock. The zero value for a Mutex is an
+// unlocked mutex. A Mutex must not be copied after first use.
+type Mutex struct {
+	state int32
+	sema  chan struct{}
+}
+
+// Locker represents an object that can be locked and unlocked.
+type Locker interface {
+	Lock()
+	Unlock()
+}
+
+// Lock locks m. If the lock is already in use, the calling goroutine blocks
+// until the mutex is available.
+func (m *Mutex) Lock() {
+	for !atomic.CompareAndSwapInt32(&m.state, 0, 1) {
+		runtimeYield()
+	}
+}
+
+// TryLock tries to lock m and reports whether it succeeded.
+func (m *Mutex) TryLock() bool {
+	return atomic.CompareAndSwapInt32(&m.state, 0, 1)
+}
+
+// Unlock unlocks m. It is a run-time error if m is not locked on entry to
+// Unlock.
+func (m *Mutex) Unlock() {
+	if atomic.AddInt32(&m.state, -1) != 0 {
+		panic("sync: unlock of unlocked mutex")
+	}
+}
+
+func runtimeYield() {}
@@ -0,0 +1,51 @@
+package sync
+
+import "sync/atomic"
+
+// A WaitGroup waits for a collection of goroutines to finish. The main
+// goroutine calls Add to set the number of goroutines to wait for. Then each
+// of the goroutines runs and calls Done when finished. At the same time, Wait
+// can be used to block until all goroutines have finished.
+type WaitGroup struct {
+	counter int64
+}
+
+// Add adds delta, which may be negative, to the WaitGroup counter. If the
+// counter becomes zero, all goroutines blocked on Wait are released.
+func (wg *WaitGroup) Add(delta int) {
+	if atomic.AddInt64(&wg.counter, int64(delta)) < 0 {
+		panic("sync: negative WaitGroup counter")
+	}
+}
+
+// Done decrements the WaitGroup counter by one.
+func (wg *WaitGroup) Done() {
+	wg.Add(-1)
+}
+
+// Wait blocks until the WaitGroup counter is zero.
+func (wg *WaitGroup) Wait() {
+	for atomic.LoadInt64(&wg.counter) != 0 {
+		runtimeYield()
+	}
+}
+
+// Once is an object that will perform exactly one action.
+type Once struct {
+	done uint32
+	m    Mutex
+}
+
+// Do calls the function f if and only if Do is being called for the first
+// time for this instance of Once.
+func (o *Once) Do(f func()) {
+	if atomic.LoadUint32(&o.done) == 1 {
+		return
+	}
+	o.m.Lock()
+	defer o.m.Unlock()
+	if o.done == 0 {
+		defer atomic.StoreUint32(&o.done, 1)
+		f()
+	}
+}
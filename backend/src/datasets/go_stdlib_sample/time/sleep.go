@@ -0,0 +1,47 @@
+package time
+
+// The Timer type represents a single event. When the Timer expires, the
+// current time will be sent on C.
+type Timer struct {
+	C <-chan Time
+	c chan Time
+	d Duration
+}
+
+// NewTimer creates a new Timer that will send the current time on its
+// channel after at least duration d.
+func NewTimer(d Duration) *Timer {
+	c := make(chan Time, 1)
+	t := &Timer{C: c, c: c, d: d}
+	startTimer(t)
+	return t
+}
+
+// Stop prevents the Timer from firing. It returns true if the call stops the
+// timer, false if the timer has already expired or been stopped.
+func (t *Timer) Stop() bool {
+	return stopTimer(t)
+}
+
+// After waits for the duration to elapse and then sends the current time on
+// the returned channel.
+func After(d Duration) <-chan Time {
+	return NewTimer(d).C
+}
+
+// A Ticker holds a channel that delivers ticks of a clock at intervals.
+type Ticker struct {
+	C <-chan Time
+}
+
+// NewTicker returns a new Ticker containing a channel that will send the
+// current time on the channel after each tick. The period of the ticks is
+// specified by the duration argument.
+func NewTicker(d Duration) *Ticker {
+	if d <= 0 {
+		panic("non-positive interval for NewTicker")
+	}
+	c := make(chan Time, 1)
+	startTicker(c, d)
+	return &Ticker{C: c}
+}
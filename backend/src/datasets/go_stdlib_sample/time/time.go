@@ -0,0 +1,53 @@
+// Package time provides functionality for measuring and displaying time.
+package time
+
+// A Duration represents the elapsed time between two instants as an int64
+// nanosecond count.
+type Duration int64
+
+// Common durations.
+const (
+	Nanosecond  Duration = 1
+	Microsecond          = 1000 * Nanosecond
+	Millisecond          = 1000 * Microsecond
+	Second               = 1000 * Millisecond
+	Minute               = 60 * Second
+	Hour                 = 60 * Minute
+)
+
+// Seconds returns the duration as a floating point number of seconds.
+func (d Duration) Seconds() float64 {
+	sec := d / Second
+	nsec := d % Second
+	return float64(sec) + float64(nsec)/1e9
+}
+
+// A Time represents an instant in time with nanosecond precision.
+type Time struct {
+	sec  int64
+	nsec int32
+}
+
+// Add returns the time t+d.
+func (t Time) Add(d Duration) Time {
+	total := int64(t.nsec) + int64(d%Second)
+	t.sec += int64(d/Second) + total/1e9
+	t.nsec = int32(total % 1e9)
+	return t
+}
+
+// Sub returns the duration t-u.
+func (t Time) Sub(u Time) Duration {
+	return Duration(t.sec-u.sec)*Second + Duration(t.nsec-u.nsec)
+}
+
+// Before reports whether the time instant t is before u.
+func (t Time) Before(u Time) bool {
+	return t.sec < u.sec || t.sec == u.sec && t.nsec < u.nsec
+}
+
+// Since returns the time elapsed since t. It is shorthand for
+// time.Now().Sub(t).
+func Since(t Time) Duration {
+	return Now().Sub(t)
+}
@@ -0,0 +1,123 @@
+"""
+Chunk Content Store.
+
+Chunk text normally lives in the vector store payload (``text``), where
+Qdrant keeps it in memory next to the vectors. With
+``infrastructure.content_store.enabled`` the indexer writes the text to
+local disk blobs instead (``infrastructure.content_store.path``), keyed by
+the SHA256 of the text, and the payload only carries ``content_ref`` and
+``content_length``. Identical chunks share one blob.
+
+Reads go through the store: search hydrates ``text`` for the results it
+returns when content is requested (and for rerank candidates), previews read
+only the first characters, and chunk lookups hydrate the payload. Chunks
+indexed before the store was enabled keep their inline text and still work;
+disabling it again only affects new indexing.
+
+The API and the worker must share the directory. Blobs are never removed
+when chunks are deleted, since other chunks may reference them.
+"""
+
+import hashlib
+import logging
+import os
+import tempfile
+from pathlib import Path
+from typing import Any, Dict, List, Optional
+
+from src.core.config import settings
+
+logger = logging.getLogger(__name__)
+
+
+def content_store_enabled() -> bool:
+    return bool(settings.get("infrastructure.content_store.enabled", False))
+
+
+def content_ref(text: str) -> str:
+    """Blob key of a chunk's text."""
+    return hashlib.sha256(text.encode("utf-8")).hexdigest()
+
+
+def _is_ref(ref: Any) -> bool:
+    return isinstance(ref, str) and len(ref) == 64 and all(c in "0123456789abcdef" for c in ref)
+
+
+class ContentStore:
+    """Content-addressed chunk text on local disk."""
+
+    def __init__(self, root: Optional[str] = None):
+        self.root = Path(root or settings.get("infrastructure.content_store.path", "data/content"))
+
+    def _path(self, ref: str) -> Path:
+        return self.root / ref[:2] / ref
+
+    def put(self, text: str) -> str:
+        """Store text (once per distinct text) and return its ref."""
+        ref = content_ref(text)
+        path = self._path(ref)
+        if path.exists():
+            return ref
+        path.parent.mkdir(parents=True, exist_ok=True)
+        # Write then rename, so readers never see a partial blob
+        fd, tmp = tempfile.mkstemp(dir=path.parent, prefix=".tmp-")
+        try:
+            with os.fdopen(fd, "w", encoding="utf-8", newline="") as f:
+                f.write(text)
+            os.replace(tmp, path)
+        except BaseException:
+            if os.path.exists(tmp):
+                os.unlink(tmp)
+            raise
+        return ref
+
+    def get(self, ref: str, max_chars: Optional[int] = None) -> Optional[str]:
+        """Text of a ref (the first ``max_chars`` only, if given); None if missing."""
+        if not _is_ref(ref):
+            return None
+        try:
+            with open(self._path(ref), encoding="utf-8", newline="") as f:
+                return f.read(max_chars) if max_chars is not None else f.read()
+        except FileNotFoundError:
+            logger.warning(f"Content blob {ref} is missing")
+            return None
+        except OSError as e:
+            logger.warning(f"Failed to read content blob {ref}: {e}")
+            return None
+
+
+def payload_text(payload: Dict[str, Any], max_chars: Optional[int] = None) -> str:
+    """A payload's chunk text, inline or from the content store."""
+    text = payload.get("text")
+    if text:
+        return text[:max_chars] if max_chars is not None else text
+    ref = payload.get("content_ref")
+    if not ref:
+        return ""
+    return get_content_store().get(ref, max_chars) or ""
+
+
+def hydrate(results: List[Dict[str, Any]]) -> List[Dict[str, Any]]:
+    """
+    Results with ``text`` filled in from the content store.
+
+    Results that already have text (or no ref) are returned as they are;
+    hydrated ones are copies, so cached results stay small.
+    """
+    hydrated = []
+    for result in results:
+        if not result.get("text") and result.get("content_ref"):
+            result = {**result, "text": payload_text(result)}
+        hydrated.append(result)
+    return hydrated
+
+
+# Singleton instance
+_content_store: Optional[ContentStore] = None
+
+def get_content_store() -> ContentStore:
+    """Get global content store instance."""
+    global _content_store
+    if _content_store is None:
+        _content_store = ContentStore()
+    return _content_store
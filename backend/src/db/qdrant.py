@@ -1,14 +1,47 @@
+from typing import Any, Dict
+
 from qdrant_client import QdrantClient
 from src.core.config import settings
+from src.db.vector_store import VectorStore, create_vector_store
+
+MB = 1024 * 1024
+
+
+def grpc_options() -> Dict[str, int]:
+    """gRPC channel limits from ``infrastructure.qdrant.grpc_max_{send,recv}_mb``."""
+    return {
+        "grpc.max_send_message_length": int(float(settings.get("infrastructure.qdrant.grpc_max_send_mb", 16)) * MB),
+        "grpc.max_receive_message_length": int(float(settings.get("infrastructure.qdrant.grpc_max_recv_mb", 16)) * MB),
+    }
+
+
+def client_kwargs() -> Dict[str, Any]:
+    """QdrantClient arguments (REST by default, gRPC with ``prefer_grpc``)."""
+    kwargs: Dict[str, Any] = {"url": settings.QDRANT_URL}
+    if settings.get("infrastructure.qdrant.prefer_grpc", False):
+        kwargs.update(
+            prefer_grpc=True,
+            grpc_port=int(settings.get("infrastructure.qdrant.grpc_port", 6334)),
+            grpc_options=grpc_options(),
+        )
+    return kwargs
+
+
+class QdrantStore(QdrantClient, VectorStore):
+    """One Qdrant client (the client implements the interface as-is); pooled by ``PooledQdrantStore``."""
+
+    name = "qdrant"
+
 
 class QdrantConnector:
     _instance = None
 
     @classmethod
-    def get_client(cls) -> QdrantClient:
+    def get_client(cls) -> VectorStore:
         if cls._instance is None:
-            cls._instance = QdrantClient(url=settings.QDRANT_URL)
+            cls._instance = create_vector_store()
         return cls._instance
 
-def get_qdrant_client():
+def get_qdrant_client() -> VectorStore:
+    """The configured vector store (``infrastructure.vector_store.backend``)."""
     return QdrantConnector.get_client()
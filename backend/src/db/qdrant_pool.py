@@ -0,0 +1,368 @@
+"""
+Pooled Qdrant Store.
+
+The ``qdrant`` vector store backend keeps a small pool of Qdrant clients per
+operation class instead of one shared client, so a slow bulk upsert or
+collection rebuild does not hold up searches and each class gets its own
+timeout budget:
+
+- ``search``: query_points, retrieve, scroll, count, facet
+- ``write``: upsert, delete, set_payload, delete_payload
+- ``admin``: collections and payload indexes
+
+A call waits up to ``infrastructure.qdrant.pool.acquire_timeout_seconds``
+for a free client of its class (``QdrantPoolExhausted`` after that).
+Idempotent calls that fail with a transient error (connection refused or
+reset, gRPC UNAVAILABLE / DEADLINE_EXCEEDED, HTTP 429/502/503/504) are
+retried with jittered exponential backoff, within the class's timeout
+budget. Creating and dropping collections is never retried.
+
+Pool use and retries are exported on ``/metrics`` (this process only).
+"""
+
+import logging
+import random
+import threading
+import time
+from collections import defaultdict
+from contextlib import contextmanager
+from typing import Any, Callable, Dict, List, Optional
+
+from src.core.config import settings
+from src.db.vector_store import VectorStore
+
+logger = logging.getLogger(__name__)
+
+SEARCH = "search"
+WRITE = "write"
+ADMIN = "admin"
+POOLS = (SEARCH, WRITE, ADMIN)
+
+OPERATION_POOLS = {
+    "query_points": SEARCH,
+    "retrieve": SEARCH,
+    "scroll": SEARCH,
+    "count": SEARCH,
+    "facet": SEARCH,
+    "upsert": WRITE,
+    "delete": WRITE,
+    "set_payload": WRITE,
+    "delete_payload": WRITE,
+}
+
+# Safe to repeat: reads, and writes keyed by point id / filter
+IDEMPOTENT = {
+    "query_points", "retrieve", "scroll", "count", "facet",
+    "upsert", "delete", "set_payload", "delete_payload",
+    "get_collection", "get_collections", "collection_exists",
+    "update_collection", "create_payload_index",
+}
+
+DEFAULT_SIZES = {SEARCH: 8, WRITE: 4, ADMIN: 1}
+DEFAULT_TIMEOUTS = {SEARCH: 10, WRITE: 60, ADMIN: 120}
+
+TRANSIENT_GRPC_CODES = {"UNAVAILABLE", "DEADLINE_EXCEEDED", "RESOURCE_EXHAUSTED", "ABORTED"}
+TRANSIENT_HTTP_STATUSES = {429, 502, 503, 504}
+# httpx transport errors, matched by name so this module doesn't need httpx
+TRANSIENT_ERROR_NAMES = {
+    "ConnectError", "ConnectTimeout", "ReadTimeout", "WriteTimeout", "PoolTimeout",
+    "ReadError", "WriteError", "RemoteProtocolError",
+}
+
+
+class QdrantPoolExhausted(Exception):
+    """No client of the pool came free in time."""
+
+    def __init__(self, pool: str, waited: float):
+        super().__init__(f"Qdrant {pool} pool exhausted (waited {waited:g}s)")
+        self.pool = pool
+        self.waited = waited
+
+
+def is_transient(error: BaseException) -> bool:
+    """Whether a failed call may succeed if repeated."""
+    seen = set()
+    while error is not None and id(error) not in seen:
+        seen.add(id(error))
+        if isinstance(error, (ConnectionError, TimeoutError)):
+            return True
+        if any(cls.__name__ in TRANSIENT_ERROR_NAMES for cls in type(error).__mro__):
+            return True
+        code = getattr(error, "code", None)
+        if callable(code):
+            try:
+                if getattr(code(), "name", None) in TRANSIENT_GRPC_CODES:
+                    return True
+            except Exception:
+                pass
+        if getattr(error, "status_code", None) in TRANSIENT_HTTP_STATUSES:
+            return True
+        # qdrant_client's ResponseHandlingException wraps the transport error
+        error = getattr(error, "source", None) or error.__cause__
+    return False
+
+
+class ClientPool:
+    """Up to ``size`` clients of one operation class, created on demand."""
+
+    def __init__(self, name: str, factory: Callable[[], Any], size: int, acquire_timeout: float):
+        self.name = name
+        self.size = size
+        self.acquire_timeout = acquire_timeout
+        self._factory = factory
+        self._lock = threading.Lock()
+        self._slots = threading.BoundedSemaphore(size)
+        self._idle: List[Any] = []
+        self.created = 0
+        self.in_use = 0
+        self.waiting = 0
+        self.waits_total = 0
+        self.exhausted_total = 0
+
+    @contextmanager
+    def lease(self):
+        """
+        A client for one call.
+
+        Raises:
+            QdrantPoolExhausted: every client stayed busy for ``acquire_timeout``
+        """
+        if not self._slots.acquire(blocking=False):
+            with self._lock:
+                self.waiting += 1
+                self.waits_total += 1
+            acquired = self._slots.acquire(timeout=self.acquire_timeout)
+            with self._lock:
+                self.waiting -= 1
+                if not acquired:
+                    self.exhausted_total += 1
+            if not acquired:
+                raise QdrantPoolExhausted(self.name, self.acquire_timeout)
+
+        try:
+            with self._lock:
+                client = self._idle.pop() if self._idle else None
+                self.in_use += 1
+            if client is None:
+                client = self._factory()
+                with self._lock:
+                    self.created += 1
+        except Exception:
+            with self._lock:
+                self.in_use -= 1
+            self._slots.release()
+            raise
+
+        try:
+            yield client
+        finally:
+            with self._lock:
+                self.in_use -= 1
+                self._idle.append(client)
+            self._slots.release()
+
+    def stats(self) -> Dict[str, int]:
+        with self._lock:
+            return {
+                "size": self.size,
+                "created": self.created,
+                "in_use": self.in_use,
+                "waiting": self.waiting,
+                "waits_total": self.waits_total,
+                "exhausted_total": self.exhausted_total,
+            }
+
+
+def pool_timeout(pool: str) -> float:
+    """Timeout budget of an operation class (seconds)."""
+    default = settings.get("infrastructure.qdrant.timeout", DEFAULT_TIMEOUTS[pool])
+    return float(settings.get(f"infrastructure.qdrant.pool.{pool}.timeout_seconds", default))
+
+
+def _default_factory(pool: str, client_kwargs: Dict[str, Any]) -> Callable[[], Any]:
+    def create():
+        from src.db.qdrant import QdrantStore, client_kwargs as configured
+        return QdrantStore(**{**configured(), "timeout": int(pool_timeout(pool)), **client_kwargs})
+    return create
+
+
+class PooledQdrantStore(VectorStore):
+    """Qdrant server backend: pooled clients, timeout budgets and retries."""
+
+    name = "qdrant"
+
+    def __init__(
+        self,
+        factory: Optional[Callable[[str], Callable[[], Any]]] = None,
+        sleep: Callable[[float], None] = time.sleep,
+        **client_kwargs: Any,
+    ):
+        """
+        Args:
+            factory: ``factory(pool)`` returns the pool's client constructor
+                (default: ``QdrantStore`` with the pool's timeout)
+            sleep: Backoff sleep (tests)
+            client_kwargs: Overrides for every ``QdrantClient``
+        """
+        self._factory = factory or (lambda pool: _default_factory(pool, client_kwargs))
+        self._sleep = sleep
+        self._lock = threading.Lock()
+        self._pools: Dict[str, ClientPool] = {}
+        self.retries: Dict[str, int] = defaultdict(int)
+        self.retry_failures: Dict[str, int] = defaultdict(int)
+
+    def pool(self, name: str) -> ClientPool:
+        with self._lock:
+            if name not in self._pools:
+                size = int(settings.get(f"infrastructure.qdrant.pool.{name}.size", DEFAULT_SIZES[name]))
+                acquire = float(settings.get("infrastructure.qdrant.pool.acquire_timeout_seconds", 5))
+                self._pools[name] = ClientPool(name, self._factory(name), max(1, size), acquire)
+            return self._pools[name]
+
+    def _call(self, operation: str, *args, **kwargs):
+        pool = self.pool(OPERATION_POOLS.get(operation, ADMIN))
+        attempts = int(settings.get("infrastructure.qdrant.retry.max_attempts", 3)) if operation in IDEMPOTENT else 1
+        backoff = float(settings.get("infrastructure.qdrant.retry.backoff_seconds", 0.2))
+        max_backoff = float(settings.get("infrastructure.qdrant.retry.max_backoff_seconds", 2))
+        deadline = time.monotonic() + pool_timeout(pool.name)
+
+        attempt = 1
+        while True:
+            try:
+                with pool.lease() as client:
+                    return getattr(client, operation)(*args, **kwargs)
+            except Exception as e:
+                if not is_transient(e) or attempt >= attempts:
+                    if attempt > 1:
+                        with self._lock:
+                            self.retry_failures[operation] += 1
+                    raise
+                # Full jitter; stop retrying once the budget is spent
+                delay = random.uniform(0, min(max_backoff, backoff * (2 ** (attempt - 1))))
+                if time.monotonic() + delay >= deadline:
+                    with self._lock:
+                        self.retry_failures[operation] += 1
+                    raise
+                with self._lock:
+                    self.retries[operation] += 1
+                logger.warning(f"Qdrant {operation} failed ({e}), retry {attempt}/{attempts - 1} in {delay:.2f}s")
+                self._sleep(delay)
+                attempt += 1
+
+    def __getattr__(self, operation: str):
+        # Other QdrantClient methods (snapshots, aliases, ...) go through the admin pool
+        if operation.startswith("_"):
+            raise AttributeError(operation)
+        return lambda *args, **kwargs: self._call(operation, *args, **kwargs)
+
+    def stats(self) -> Dict[str, Any]:
+        with self._lock:
+            pools = dict(self._pools)
+            retries, failures = dict(self.retries), dict(self.retry_failures)
+        return {
+            "pools": {name: pool.stats() for name, pool in pools.items()},
+            "retries": retries,
+            "retry_failures": failures,
+        }
+
+    def prometheus_lines(self) -> List[str]:
+        stats = self.stats()
+        lines = []
+        gauges = [
+            ("size", "gauge", "Qdrant clients allowed per pool"),
+            ("in_use", "gauge", "Qdrant clients in use per pool"),
+            ("waiting", "gauge", "Calls waiting for a free Qdrant client"),
+            ("waits_total", "counter", "Calls that had to wait for a free Qdrant client"),
+            ("exhausted_total", "counter", "Calls refused after waiting for a Qdrant client"),
+        ]
+        for field, kind, help_text in gauges:
+            lines.append(f"# HELP rice_search_qdrant_pool_{field} {help_text}")
+            lines.append(f"# TYPE rice_search_qdrant_pool_{field} {kind}")
+            for name, pool in stats["pools"].items():
+                lines.append(f'rice_search_qdrant_pool_{field}{{pool="{name}"}} {pool[field]}')
+        for field, help_text in (
+            ("retries", "Qdrant calls retried after a transient error"),
+            ("retry_failures", "Qdrant calls that failed after retrying"),
+        ):
+            lines.append(f"# HELP rice_search_qdrant_{field}_total {help_text}")
+            lines.append(f"# TYPE rice_search_qdrant_{field}_total counter")
+            for operation, count in sorted(stats[field].items()):
+                lines.append(f'rice_search_qdrant_{field}_total{{operation="{operation}"}} {count}')
+        return lines
+
+    # ============== Collections ==============
+
+    def get_collections(self):
+        return self._call("get_collections")
+
+    def get_collection(self, collection_name: str):
+        return self._call("get_collection", collection_name)
+
+    def create_collection(self, collection_name: str, vectors_config, sparse_vectors_config=None, **params) -> bool:
+        return self._call(
+            "create_collection", collection_name,
+            vectors_config=vectors_config, sparse_vectors_config=sparse_vectors_config, **params
+        )
+
+    def recreate_collection(self, collection_name: str, vectors_config, sparse_vectors_config=None, **params) -> bool:
+        return self._call(
+            "recreate_collection", collection_name,
+            vectors_config=vectors_config, sparse_vectors_config=sparse_vectors_config, **params
+        )
+
+    def update_collection(self, collection_name: str, **params) -> bool:
+        return self._call("update_collection", collection_name, **params)
+
+    def delete_collection(self, collection_name: str) -> bool:
+        return self._call("delete_collection", collection_name)
+
+    def create_payload_index(self, collection_name: str, field_name: str, field_schema=None, **kwargs):
+        return self._call(
+            "create_payload_index", collection_name, field_name=field_name, field_schema=field_schema, **kwargs
+        )
+
+    # ============== Points ==============
+
+    def upsert(self, collection_name: str, points: List[Any], **kwargs):
+        return self._call("upsert", collection_name, points=points, **kwargs)
+
+    def retrieve(self, collection_name: str, ids: List[Any], with_payload=True, with_vectors=False, **kwargs) -> List[Any]:
+        return self._call("retrieve", collection_name, ids=ids, with_payload=with_payload, with_vectors=with_vectors, **kwargs)
+
+    def scroll(self, collection_name: str, scroll_filter=None, limit: int = 10, offset=None,
+               with_payload=True, with_vectors=False, **kwargs):
+        return self._call(
+            "scroll", collection_name, scroll_filter=scroll_filter, limit=limit, offset=offset,
+            with_payload=with_payload, with_vectors=with_vectors, **kwargs
+        )
+
+    def count(self, collection_name: str, count_filter=None, exact: bool = True, **kwargs):
+        return self._call("count", collection_name, count_filter=count_filter, exact=exact, **kwargs)
+
+    def delete(self, collection_name: str, points_selector, **kwargs):
+        return self._call("delete", collection_name, points_selector=points_selector, **kwargs)
+
+    def set_payload(self, collection_name: str, payload: Dict[str, Any], points, **kwargs):
+        return self._call("set_payload", collection_name, payload=payload, points=points, **kwargs)
+
+    def delete_payload(self, collection_name: str, keys: List[str], points, **kwargs):
+        return self._call("delete_payload", collection_name, keys=keys, points=points, **kwargs)
+
+    # ============== Search ==============
+
+    def query_points(self, collection_name: str, query=None, using: Optional[str] = None, prefetch=None,
+                     query_filter=None, limit: int = 10, with_payload=True, with_vectors=False, **kwargs):
+        return self._call(
+            "query_points", collection_name, query=query, using=using, prefetch=prefetch,
+            query_filter=query_filter, limit=limit, with_payload=with_payload, with_vectors=with_vectors, **kwargs
+        )
+
+    def facet(self, collection_name: str, key: str, facet_filter=None, limit: int = 10, exact: bool = False, **kwargs):
+        return self._call("facet", collection_name, key=key, facet_filter=facet_filter, limit=limit, exact=exact, **kwargs)
+
+
+def prometheus_lines() -> List[str]:
+    """Pool and retry metrics of this process's Qdrant store (none for other backends)."""
+    from src.db.qdrant import QdrantConnector
+    store = QdrantConnector._instance
+    return store.prometheus_lines() if isinstance(store, PooledQdrantStore) else []
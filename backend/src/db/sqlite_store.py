@@ -0,0 +1,565 @@
+"""
+Embedded SQLite Vector Store.
+
+``infrastructure.vector_store.backend: sqlite`` keeps collections, payloads
+and vectors in one SQLite file instead of a Qdrant server, for small
+self-hosted installs. The API and worker processes open the same file (WAL
+mode, so searches don't block on indexing).
+
+Searches score every point of the collection that passes the filter (the
+``org_id`` condition is applied in SQL, the rest on the payload): dense
+vectors by cosine similarity (normalized on write, like Qdrant), sparse
+vectors by dot product, and prefetch fusion with RRF. Payload indexes,
+shards, replication and HNSW settings are recorded but not used.
+"""
+
+import heapq
+import json
+import logging
+import math
+import os
+import sqlite3
+import threading
+from array import array
+from types import SimpleNamespace
+from typing import Any, Dict, Iterable, List, Optional, Tuple
+
+from qdrant_client import models
+
+from src.core.config import settings
+from src.db.vector_store import VectorStore
+
+logger = logging.getLogger(__name__)
+
+# Qdrant's default RRF constant (score = sum of 1 / (k + 0-based rank))
+RRF_K = 2
+
+SCHEMA = """
+CREATE TABLE IF NOT EXISTS collections (
+    name TEXT PRIMARY KEY,
+    config TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS points (
+    collection TEXT NOT NULL,
+    id TEXT NOT NULL,
+    org_id TEXT,
+    payload TEXT NOT NULL,
+    PRIMARY KEY (collection, id)
+);
+CREATE INDEX IF NOT EXISTS points_org ON points (collection, org_id);
+CREATE TABLE IF NOT EXISTS vectors (
+    collection TEXT NOT NULL,
+    id TEXT NOT NULL,
+    name TEXT NOT NULL,
+    dense BLOB,
+    sparse TEXT,
+    PRIMARY KEY (collection, id, name)
+);
+"""
+
+
+def _dump(value: Any) -> Any:
+    """Plain JSON-able form of a qdrant model (or value)."""
+    if hasattr(value, "model_dump"):
+        return value.model_dump(exclude_none=True, mode="json")
+    if hasattr(value, "__dict__") and not isinstance(value, type):
+        return {k: _dump(v) for k, v in vars(value).items() if v is not None and k != "args"}
+    return value
+
+
+def _as_list(value: Any) -> List[Any]:
+    if value is None:
+        return []
+    return list(value) if isinstance(value, (list, tuple)) else [value]
+
+
+def _out_id(point_id: str):
+    """Ids are stored as text; integer ids come back as ints."""
+    return int(point_id) if point_id.isdigit() else point_id
+
+
+def _lookup(payload: Dict[str, Any], key: str) -> Tuple[bool, Any]:
+    """(present, value) for a possibly dotted payload key."""
+    value: Any = payload
+    for part in key.split("."):
+        if not isinstance(value, dict) or part not in value:
+            return False, None
+        value = value[part]
+    return True, value
+
+
+def _values(payload: Dict[str, Any], key: str) -> List[Any]:
+    present, value = _lookup(payload, key)
+    if not present or value is None:
+        return []
+    return value if isinstance(value, list) else [value]
+
+
+def _match(values: List[Any], match) -> bool:
+    if isinstance(match, models.MatchValue):
+        return match.value in values
+    if isinstance(match, models.MatchAny):
+        return any(v in values for v in match.any)
+    if isinstance(match, models.MatchExcept):
+        excluded = getattr(match, "except_", None) or getattr(match, "except", None) or []
+        return not any(v in excluded for v in values)
+    if isinstance(match, models.MatchText):
+        return any(match.text in str(v) for v in values)
+    raise ValueError(f"Unsupported match {type(match).__name__}")
+
+
+def _in_range(values: List[Any], rng) -> bool:
+    def ok(v):
+        if not isinstance(v, (int, float)) or isinstance(v, bool):
+            return False
+        return all([
+            getattr(rng, "gt", None) is None or v > rng.gt,
+            getattr(rng, "gte", None) is None or v >= rng.gte,
+            getattr(rng, "lt", None) is None or v < rng.lt,
+            getattr(rng, "lte", None) is None or v <= rng.lte,
+        ])
+    return any(ok(v) for v in values)
+
+
+def _condition(payload: Dict[str, Any], point_id: str, condition) -> bool:
+    if isinstance(condition, models.Filter):
+        return matches_filter(payload, point_id, condition)
+    if isinstance(condition, models.IsEmptyCondition):
+        return not _values(payload, condition.is_empty.key)
+    if isinstance(condition, models.IsNullCondition):
+        present, value = _lookup(payload, condition.is_null.key)
+        return present and value is None
+    if isinstance(condition, models.HasIdCondition):
+        return point_id in {str(i) for i in condition.has_id}
+    if isinstance(condition, models.FieldCondition):
+        values = _values(payload, condition.key)
+        if getattr(condition, "match", None) is not None and not _match(values, condition.match):
+            return False
+        if getattr(condition, "range", None) is not None and not _in_range(values, condition.range):
+            return False
+        return True
+    raise ValueError(f"Unsupported filter condition {type(condition).__name__}")
+
+
+def matches_filter(payload: Dict[str, Any], point_id: str, flt) -> bool:
+    """Whether a point passes a ``models.Filter`` (None passes everything)."""
+    if flt is None:
+        return True
+    if not all(_condition(payload, point_id, c) for c in _as_list(getattr(flt, "must", None))):
+        return False
+    should = _as_list(getattr(flt, "should", None))
+    if should and not any(_condition(payload, point_id, c) for c in should):
+        return False
+    return not any(_condition(payload, point_id, c) for c in _as_list(getattr(flt, "must_not", None)))
+
+
+def _org_hint(flt) -> Optional[str]:
+    """An ``org_id`` match every result must have, to narrow rows in SQL."""
+    for condition in _as_list(getattr(flt, "must", None)):
+        if isinstance(condition, models.Filter):
+            hint = _org_hint(condition)
+            if hint is not None:
+                return hint
+        elif (
+            isinstance(condition, models.FieldCondition)
+            and condition.key == "org_id"
+            and isinstance(getattr(condition, "match", None), models.MatchValue)
+        ):
+            return condition.match.value
+    return None
+
+
+def _is_cosine(params: Dict[str, Any]) -> bool:
+    return params["distance"].lower().endswith("cosine")
+
+
+def _normalize(vector: List[float]) -> List[float]:
+    norm = math.sqrt(sum(v * v for v in vector))
+    return [v / norm for v in vector] if norm else list(vector)
+
+
+def _dense_scores(query: List[float], blobs: List[bytes]) -> List[float]:
+    if not blobs:
+        return []
+    try:
+        import numpy as np
+        matrix = np.frombuffer(b"".join(blobs), dtype=np.float32).reshape(len(blobs), len(query))
+        return (matrix @ np.asarray(query, dtype=np.float32)).tolist()
+    except ImportError:
+        scores = []
+        for blob in blobs:
+            vector = array("f")
+            vector.frombytes(blob)
+            scores.append(sum(a * b for a, b in zip(vector, query)))
+        return scores
+
+
+def _sparse_score(query: Dict[int, float], stored: Dict[str, List]) -> float:
+    return sum(query.get(i, 0.0) * v for i, v in zip(stored["indices"], stored["values"]))
+
+
+class SqliteStore(VectorStore):
+    """Collections, payloads and vectors in a local SQLite file."""
+
+    name = "sqlite"
+
+    def __init__(self, path: Optional[str] = None):
+        self.path = path or settings.get("infrastructure.vector_store.sqlite.path", "data/vectors.db")
+        self._local = threading.local()
+
+    @property
+    def conn(self) -> sqlite3.Connection:
+        """Per-thread connection (services call the store from worker threads)."""
+        conn = getattr(self._local, "conn", None)
+        if conn is None:
+            directory = os.path.dirname(self.path)
+            if directory:
+                os.makedirs(directory, exist_ok=True)
+            conn = sqlite3.connect(self.path, timeout=30)
+            conn.execute("PRAGMA journal_mode=WAL")
+            conn.execute("PRAGMA synchronous=NORMAL")
+            conn.executescript(SCHEMA)
+            self._local.conn = conn
+        return conn
+
+    # ============== Collections ==============
+
+    def _config(self, collection_name: str) -> Dict[str, Any]:
+        row = self.conn.execute(
+            "SELECT config FROM collections WHERE name = ?", (collection_name,)
+        ).fetchone()
+        if row is None:
+            raise ValueError(f"Collection {collection_name} not found")
+        return json.loads(row[0])
+
+    def _save_config(self, collection_name: str, config: Dict[str, Any]):
+        with self.conn:
+            self.conn.execute(
+                "UPDATE collections SET config = ? WHERE name = ?", (json.dumps(config), collection_name)
+            )
+
+    def get_collections(self):
+        names = [row[0] for row in self.conn.execute("SELECT name FROM collections ORDER BY name")]
+        return models.CollectionsResponse(collections=[models.CollectionDescription(name=n) for n in names])
+
+    def get_collection(self, collection_name: str):
+        config = self._config(collection_name)
+        vectors = {name: SimpleNamespace(**params) for name, params in config["vectors"].items()}
+        params = config.get("params", {})
+        hnsw = config.get("hnsw", {})
+        return SimpleNamespace(
+            status="green",
+            points_count=self.count(collection_name).count,
+            payload_schema=dict(config.get("payload_schema", {})),
+            config=SimpleNamespace(
+                params=SimpleNamespace(
+                    vectors=vectors.get("") if list(vectors) == [""] else vectors,
+                    sparse_vectors={name: SimpleNamespace() for name in config.get("sparse_vectors", [])},
+                    shard_number=params.get("shard_number", 1),
+                    replication_factor=params.get("replication_factor", 1),
+                    on_disk_payload=params.get("on_disk_payload", False),
+                ),
+                hnsw_config=SimpleNamespace(m=hnsw.get("m"), ef_construct=hnsw.get("ef_construct")),
+            ),
+        )
+
+    def create_collection(self, collection_name: str, vectors_config, sparse_vectors_config=None, **params) -> bool:
+        if not isinstance(vectors_config, dict):
+            vectors_config = {"": vectors_config}
+        config = {
+            "vectors": {
+                name: {
+                    "size": int(vp.size),
+                    "distance": str(getattr(vp.distance, "value", vp.distance) or "Cosine"),
+                }
+                for name, vp in vectors_config.items()
+            },
+            "sparse_vectors": list(sparse_vectors_config or {}),
+            "params": {k: _dump(v) for k, v in params.items() if k != "hnsw_config" and v is not None},
+            "hnsw": _dump(params.get("hnsw_config")) or {},
+            "payload_schema": {},
+        }
+        try:
+            with self.conn:
+                self.conn.execute(
+                    "INSERT INTO collections (name, config) VALUES (?, ?)", (collection_name, json.dumps(config))
+                )
+        except sqlite3.IntegrityError:
+            raise ValueError(f"Collection {collection_name} already exists")
+        return True
+
+    def recreate_collection(self, collection_name: str, vectors_config, sparse_vectors_config=None, **params) -> bool:
+        self.delete_collection(collection_name)
+        return self.create_collection(collection_name, vectors_config, sparse_vectors_config, **params)
+
+    def update_collection(self, collection_name: str, **params) -> bool:
+        config = self._config(collection_name)
+        if params.get("hnsw_config") is not None:
+            config["hnsw"] = {**config.get("hnsw", {}), **_dump(params["hnsw_config"])}
+        if params.get("collection_params") is not None:
+            config["params"] = {**config.get("params", {}), **_dump(params["collection_params"])}
+        self._save_config(collection_name, config)
+        return True
+
+    def delete_collection(self, collection_name: str) -> bool:
+        with self.conn:
+            deleted = self.conn.execute("DELETE FROM collections WHERE name = ?", (collection_name,)).rowcount
+            self.conn.execute("DELETE FROM points WHERE collection = ?", (collection_name,))
+            self.conn.execute("DELETE FROM vectors WHERE collection = ?", (collection_name,))
+        return bool(deleted)
+
+    def create_payload_index(self, collection_name: str, field_name: str, field_schema=None, **kwargs):
+        config = self._config(collection_name)
+        config.setdefault("payload_schema", {})[field_name] = str(getattr(field_schema, "value", field_schema))
+        self._save_config(collection_name, config)
+        return self._updated()
+
+    # ============== Points ==============
+
+    @staticmethod
+    def _updated():
+        return models.UpdateResult(operation_id=0, status=models.UpdateStatus.COMPLETED)
+
+    def _vector_rows(self, config: Dict[str, Any], point_id: str, vector) -> List[Tuple]:
+        if vector is None:
+            return []
+        if not isinstance(vector, dict):
+            vector = {"": vector}
+        rows = []
+        for name, value in vector.items():
+            if name in config["sparse_vectors"]:
+                sparse = {"indices": list(value.indices), "values": list(value.values)}
+                rows.append((name, None, json.dumps(sparse)))
+                continue
+            params = config["vectors"].get(name)
+            if params is None:
+                raise ValueError(f"Wrong input: Not existing vector name: {name}")
+            if len(value) != params["size"]:
+                raise ValueError(
+                    f"Wrong input: Vector dimension error: expected dim: {params['size']}, got {len(value)}"
+                )
+            if _is_cosine(params):
+                value = _normalize(value)
+            rows.append((name, array("f", value).tobytes(), None))
+        return rows
+
+    def upsert(self, collection_name: str, points: List[Any], **kwargs):
+        config = self._config(collection_name)
+        with self.conn:
+            for point in points:
+                point_id = str(point.id)
+                payload = point.payload or {}
+                rows = self._vector_rows(config, point_id, point.vector)
+                self.conn.execute(
+                    "INSERT OR REPLACE INTO points (collection, id, org_id, payload) VALUES (?, ?, ?, ?)",
+                    (collection_name, point_id, payload.get("org_id"), json.dumps(payload)),
+                )
+                self.conn.execute(
+                    "DELETE FROM vectors WHERE collection = ? AND id = ?", (collection_name, point_id)
+                )
+                self.conn.executemany(
+                    "INSERT INTO vectors (collection, id, name, dense, sparse) VALUES (?, ?, ?, ?, ?)",
+                    [(collection_name, point_id, name, dense, sparse) for name, dense, sparse in rows],
+                )
+        return self._updated()
+
+    def _rows(self, collection_name: str, flt=None, ids: Optional[Iterable[str]] = None, after: Optional[str] = None):
+        """(id, payload) of points passing a filter, in id order."""
+        self._config(collection_name)
+        sql = "SELECT id, payload FROM points WHERE collection = ?"
+        args: List[Any] = [collection_name]
+        org_id = _org_hint(flt)
+        if org_id is not None:
+            sql += " AND org_id = ?"
+            args.append(org_id)
+        if after is not None:
+            sql += " AND id >= ?"
+            args.append(after)
+        if ids is not None:
+            ids = list(ids)
+            if not ids:
+                return
+            sql += f" AND id IN ({','.join('?' * len(ids))})"
+            args.extend(ids)
+        for point_id, payload in self.conn.execute(sql + " ORDER BY id", args):
+            payload = json.loads(payload)
+            if matches_filter(payload, point_id, flt):
+                yield point_id, payload
+
+    def _vectors(self, collection_name: str, point_ids: List[str], with_vectors) -> Dict[str, Any]:
+        if not with_vectors or not point_ids:
+            return {}
+        names = None if with_vectors is True else set(with_vectors)
+        out: Dict[str, Dict[str, Any]] = {}
+        for start in range(0, len(point_ids), 500):
+            batch = point_ids[start:start + 500]
+            for point_id, name, dense, sparse in self.conn.execute(
+                f"SELECT id, name, dense, sparse FROM vectors WHERE collection = ? "
+                f"AND id IN ({','.join('?' * len(batch))})",
+                [collection_name, *batch],
+            ):
+                if names is not None and name not in names:
+                    continue
+                if sparse is not None:
+                    data = json.loads(sparse)
+                    value = models.SparseVector(indices=data["indices"], values=data["values"])
+                else:
+                    vector = array("f")
+                    vector.frombytes(dense)
+                    value = vector.tolist()
+                out.setdefault(point_id, {})[name] = value
+        # Unnamed single vectors come back as a plain list
+        return {pid: (v[""] if list(v) == [""] else v) for pid, v in out.items()}
+
+    @staticmethod
+    def _select_payload(payload: Dict[str, Any], with_payload) -> Optional[Dict[str, Any]]:
+        if not with_payload:
+            return None
+        if with_payload is True:
+            return payload
+        return {k: v for k, v in payload.items() if k in set(with_payload)}
+
+    def _records(self, collection_name: str, rows: List[Tuple[str, Dict]], with_payload, with_vectors):
+        vectors = self._vectors(collection_name, [pid for pid, _ in rows], with_vectors)
+        return [
+            models.Record(
+                id=_out_id(pid),
+                payload=self._select_payload(payload, with_payload),
+                vector=vectors.get(pid) if with_vectors else None,
+            )
+            for pid, payload in rows
+        ]
+
+    def retrieve(self, collection_name: str, ids: List[Any], with_payload=True, with_vectors=False, **kwargs):
+        rows = list(self._rows(collection_name, ids=[str(i) for i in ids]))
+        return self._records(collection_name, rows, with_payload, with_vectors)
+
+    def scroll(self, collection_name: str, scroll_filter=None, limit: int = 10, offset=None,
+               with_payload=True, with_vectors=False, **kwargs):
+        rows = []
+        next_offset = None
+        after = str(offset) if offset is not None else None
+        for point_id, payload in self._rows(collection_name, scroll_filter, after=after):
+            if len(rows) == limit:
+                next_offset = _out_id(point_id)
+                break
+            rows.append((point_id, payload))
+        return self._records(collection_name, rows, with_payload, with_vectors), next_offset
+
+    def count(self, collection_name: str, count_filter=None, exact: bool = True, **kwargs):
+        return models.CountResult(count=sum(1 for _ in self._rows(collection_name, count_filter)))
+
+    def _selected_ids(self, collection_name: str, selector) -> List[str]:
+        if isinstance(selector, (list, tuple)):
+            return [str(i) for i in selector]
+        if isinstance(selector, models.PointIdsList):
+            return [str(i) for i in selector.points]
+        if isinstance(selector, models.FilterSelector):
+            selector = selector.filter
+        return [pid for pid, _ in self._rows(collection_name, selector)]
+
+    def delete(self, collection_name: str, points_selector, **kwargs):
+        ids = self._selected_ids(collection_name, points_selector)
+        with self.conn:
+            for start in range(0, len(ids), 500):
+                batch = ids[start:start + 500]
+                marks = ",".join("?" * len(batch))
+                self.conn.execute(f"DELETE FROM points WHERE collection = ? AND id IN ({marks})", [collection_name, *batch])
+                self.conn.execute(f"DELETE FROM vectors WHERE collection = ? AND id IN ({marks})", [collection_name, *batch])
+        return self._updated()
+
+    def _rewrite_payloads(self, collection_name: str, points, update):
+        ids = self._selected_ids(collection_name, points)
+        rows = list(self._rows(collection_name, ids=ids))
+        with self.conn:
+            for point_id, payload in rows:
+                payload = update(payload)
+                self.conn.execute(
+                    "UPDATE points SET payload = ?, org_id = ? WHERE collection = ? AND id = ?",
+                    (json.dumps(payload), payload.get("org_id"), collection_name, point_id),
+                )
+        return self._updated()
+
+    def set_payload(self, collection_name: str, payload: Dict[str, Any], points, **kwargs):
+        return self._rewrite_payloads(collection_name, points, lambda p: {**p, **payload})
+
+    def delete_payload(self, collection_name: str, keys: List[str], points, **kwargs):
+        return self._rewrite_payloads(
+            collection_name, points, lambda p: {k: v for k, v in p.items() if k not in keys}
+        )
+
+    # ============== Search ==============
+
+    def _score(self, collection_name: str, query, using: Optional[str], flt, limit: int,
+               candidates: Optional[List[str]] = None) -> List[Tuple[float, str]]:
+        """Best (score, id) pairs for a dense or sparse query."""
+        config = self._config(collection_name)
+        name = using or ""
+        allowed = {pid for pid, _ in self._rows(collection_name, flt, ids=candidates)}
+        if not allowed:
+            return []
+        rows = [
+            (pid, dense, sparse)
+            for pid, dense, sparse in self.conn.execute(
+                "SELECT id, dense, sparse FROM vectors WHERE collection = ? AND name = ?", (collection_name, name)
+            )
+            if pid in allowed
+        ]
+        if isinstance(query, models.SparseVector):
+            terms = dict(zip(query.indices, query.values))
+            scored = [(_sparse_score(terms, json.loads(sparse)), pid) for pid, _, sparse in rows]
+            # Points sharing no term with the query are not matches
+            scored = [(s, pid) for s, pid in scored if s > 0]
+        else:
+            params = config["vectors"].get(name)
+            if params is None:
+                raise ValueError(f"Wrong input: Not existing vector name: {name}")
+            vector = list(query)
+            if _is_cosine(params):
+                vector = _normalize(vector)
+            scores = _dense_scores(vector, [dense for _, dense, _ in rows])
+            scored = list(zip(scores, (pid for pid, _, _ in rows)))
+        return heapq.nlargest(limit, scored, key=lambda s: (s[0], s[1]))
+
+    def _fuse(self, collection_name: str, prefetch, query_filter, limit: int) -> List[Tuple[float, str]]:
+        fused: Dict[str, float] = {}
+        for pf in _as_list(prefetch):
+            flt = pf.filter if query_filter is None else models.Filter(must=[f for f in (pf.filter, query_filter) if f])
+            ranked = self._score(collection_name, pf.query, pf.using, flt, pf.limit or limit)
+            for rank, (_, pid) in enumerate(ranked):
+                fused[pid] = fused.get(pid, 0.0) + 1.0 / (RRF_K + rank)
+        return heapq.nlargest(limit, ((s, pid) for pid, s in fused.items()), key=lambda s: (s[0], s[1]))
+
+    def query_points(self, collection_name: str, query=None, using: Optional[str] = None, prefetch=None,
+                     query_filter=None, limit: int = 10, with_payload=True, with_vectors=False,
+                     score_threshold: Optional[float] = None, **kwargs):
+        if isinstance(query, models.FusionQuery):
+            scored = self._fuse(collection_name, prefetch, query_filter, limit)
+        elif prefetch:
+            candidates = [pid for _, pid in self._fuse(collection_name, prefetch, query_filter, limit * 10)]
+            scored = self._score(collection_name, query, using, query_filter, limit, candidates)
+        else:
+            scored = self._score(collection_name, query, using, query_filter, limit)
+        if score_threshold is not None:
+            scored = [(s, pid) for s, pid in scored if s >= score_threshold]
+
+        payloads = dict(self._rows(collection_name, ids=[pid for _, pid in scored]))
+        vectors = self._vectors(collection_name, [pid for _, pid in scored], with_vectors)
+        return models.QueryResponse(points=[
+            models.ScoredPoint(
+                id=_out_id(pid),
+                version=0,
+                score=score,
+                payload=self._select_payload(payloads.get(pid, {}), with_payload),
+                vector=vectors.get(pid) if with_vectors else None,
+            )
+            for score, pid in scored
+        ])
+
+    def facet(self, collection_name: str, key: str, facet_filter=None, limit: int = 10, exact: bool = False, **kwargs):
+        counts: Dict[Any, int] = {}
+        for _, payload in self._rows(collection_name, facet_filter):
+            for value in set(_values(payload, key)):
+                counts[value] = counts.get(value, 0) + 1
+        top = sorted(counts.items(), key=lambda item: (-item[1], str(item[0])))[:limit]
+        return models.FacetResponse(hits=[models.FacetValueHit(value=v, count=c) for v, c in top])
@@ -0,0 +1,138 @@
+"""
+Vector Store Backends.
+
+Chunk vectors and payloads live behind ``VectorStore``, selected per
+deployment with ``infrastructure.vector_store.backend``:
+
+- ``qdrant`` (default): a Qdrant server (``infrastructure.qdrant``), through
+  pooled clients with per-operation timeouts and retries (``qdrant_pool``).
+- ``sqlite``: an embedded store in one SQLite file
+  (``infrastructure.vector_store.sqlite.path``, see ``sqlite_store``). No
+  separate container; the API and the worker share the file. Searches scan
+  the store's vectors, which is fine for a few repositories (tens of
+  thousands of chunks) but not for large deployments.
+
+The interface is the subset of the Qdrant client API the services use, with
+the same arguments, ``qdrant_client.models`` filters/points and result
+shapes, so callers work unchanged on either backend. Cluster settings
+(shards, replication, HNSW) are accepted and recorded by ``sqlite`` but have
+no effect there.
+"""
+
+import logging
+from typing import Any, Dict, List, Optional, Tuple
+
+from src.core.config import settings
+
+logger = logging.getLogger(__name__)
+
+BACKENDS = ("qdrant", "sqlite")
+
+
+class VectorStore:
+    """Storage interface for chunk vectors and payloads (Qdrant client API)."""
+
+    name = "base"
+
+    # ============== Collections ==============
+
+    def get_collections(self):
+        """All collections (``.collections[i].name``)."""
+        raise NotImplementedError
+
+    def get_collection(self, collection_name: str):
+        """Collection info (``config.params``, ``config.hnsw_config``,
+        ``points_count``, ``payload_schema``); raises if it doesn't exist."""
+        raise NotImplementedError
+
+    def create_collection(self, collection_name: str, vectors_config, sparse_vectors_config=None, **params) -> bool:
+        """Create a collection with named dense/sparse vectors."""
+        raise NotImplementedError
+
+    def recreate_collection(self, collection_name: str, vectors_config, sparse_vectors_config=None, **params) -> bool:
+        """Drop (if present) and create a collection."""
+        raise NotImplementedError
+
+    def update_collection(self, collection_name: str, **params) -> bool:
+        """Change collection parameters (``collection_params``, ``hnsw_config``)."""
+        raise NotImplementedError
+
+    def delete_collection(self, collection_name: str) -> bool:
+        """Drop a collection and its points."""
+        raise NotImplementedError
+
+    def create_payload_index(self, collection_name: str, field_name: str, field_schema=None, **kwargs):
+        """Index a payload field for filtering."""
+        raise NotImplementedError
+
+    # ============== Points ==============
+
+    def upsert(self, collection_name: str, points: List[Any], **kwargs):
+        """Insert or replace points (``PointStruct``)."""
+        raise NotImplementedError
+
+    def retrieve(self, collection_name: str, ids: List[Any], with_payload=True, with_vectors=False, **kwargs) -> List[Any]:
+        """Points by id (missing ids are skipped)."""
+        raise NotImplementedError
+
+    def scroll(
+        self,
+        collection_name: str,
+        scroll_filter=None,
+        limit: int = 10,
+        offset=None,
+        with_payload=True,
+        with_vectors=False,
+        **kwargs,
+    ) -> Tuple[List[Any], Optional[Any]]:
+        """A page of points in id order and the offset of the next page (None at the end)."""
+        raise NotImplementedError
+
+    def count(self, collection_name: str, count_filter=None, exact: bool = True, **kwargs):
+        """Number of matching points (``.count``)."""
+        raise NotImplementedError
+
+    def delete(self, collection_name: str, points_selector, **kwargs):
+        """Delete points by ids or filter."""
+        raise NotImplementedError
+
+    def set_payload(self, collection_name: str, payload: Dict[str, Any], points, **kwargs):
+        """Merge fields into the payload of points selected by ids or filter."""
+        raise NotImplementedError
+
+    def delete_payload(self, collection_name: str, keys: List[str], points, **kwargs):
+        """Remove payload fields from points selected by ids or filter."""
+        raise NotImplementedError
+
+    # ============== Search ==============
+
+    def query_points(
+        self,
+        collection_name: str,
+        query=None,
+        using: Optional[str] = None,
+        prefetch=None,
+        query_filter=None,
+        limit: int = 10,
+        with_payload=True,
+        with_vectors=False,
+        **kwargs,
+    ):
+        """Nearest points to a dense or sparse query, or RRF fusion of prefetches (``.points``)."""
+        raise NotImplementedError
+
+    def facet(self, collection_name: str, key: str, facet_filter=None, limit: int = 10, exact: bool = False, **kwargs):
+        """Most common values of a payload field (``.hits[i].value/.count``)."""
+        raise NotImplementedError
+
+
+def create_vector_store(name: Optional[str] = None, **kwargs: Any) -> VectorStore:
+    """Backend by name (default ``infrastructure.vector_store.backend``)."""
+    name = (name or settings.get("infrastructure.vector_store.backend", "qdrant")).lower()
+    if name == "sqlite":
+        from src.db.sqlite_store import SqliteStore
+        return SqliteStore(**kwargs)
+    if name != "qdrant":
+        logger.warning(f"Unknown infrastructure.vector_store.backend '{name}', using qdrant")
+    from src.db.qdrant_pool import PooledQdrantStore
+    return PooledQdrantStore(**kwargs)
@@ -1,4 +1,5 @@
 from fastapi import FastAPI, Request
+from fastapi.responses import JSONResponse
 from fastapi.middleware.cors import CORSMiddleware
 import time
 import os
@@ -17,7 +18,7 @@ app = FastAPI(
 # setup_telemetry(app, "rice-search-api", os.getenv("OTEL_EXPORTER_OTLP_ENDPOINT", "http://jaeger:4317"))
 
 from src.api.v1.endpoints import ingest, search, files, stores, settings as settings_api
-from src.api.v1.endpoints import metrics
+from src.api.v1.endpoints import metrics, webhooks, health, embeddings, events, changes, stats
 from src.api.v1.endpoints.admin import config as admin_config
 from src.api.v1.endpoints.admin import public as admin_public
 
@@ -28,8 +29,22 @@ app.include_router(stores.router, prefix=f"{settings.API_V1_STR}/stores", tags=[
 app.include_router(settings_api.router, prefix=f"{settings.API_V1_STR}/settings", tags=["settings"])
 app.include_router(admin_config.router, prefix=f"{settings.API_V1_STR}/admin", tags=["admin"])
 app.include_router(admin_public.router, prefix=f"{settings.API_V1_STR}/admin/public", tags=["admin-public"])
+app.include_router(embeddings.router, prefix=f"{settings.API_V1_STR}/embeddings", tags=["embeddings"])
+app.include_router(health.router, prefix=f"{settings.API_V1_STR}/health", tags=["health"])
+app.include_router(webhooks.router, prefix=f"{settings.API_V1_STR}/webhooks", tags=["webhooks"])
+app.include_router(events.router, prefix=f"{settings.API_V1_STR}/events", tags=["events"])
+app.include_router(changes.router, prefix=f"{settings.API_V1_STR}/changes", tags=["versioning"])
+app.include_router(stats.router, prefix=f"{settings.API_V1_STR}/stats", tags=["stats"])
 app.include_router(metrics.router, tags=["metrics"])
 
+# Error envelope with stable codes and request IDs (see src/core/errors.py)
+from src.core.errors import install_error_handlers
+install_error_handlers(app)
+
+# OpenAPI document and Swagger UI under /api/v1 (see src/api/openapi.py)
+from src.api.openapi import install_openapi
+install_openapi(app)
+
 # Request timing middleware
 @app.middleware("http")
 async def timing_middleware(request: Request, call_next):
@@ -55,15 +70,104 @@ async def timing_middleware(request: Request, call_next):
     response.headers["X-Response-Time-Ms"] = f"{duration_ms:.2f}"
     return response
 
-# CORS
+# API revision negotiation (see src/api/versioning.py)
+@app.middleware("http")
+async def api_version_middleware(request: Request, call_next):
+    if not request.url.path.startswith(settings.API_V1_STR):
+        return await call_next(request)
+
+    from src.api.versioning import VERSION_HEADER, add_headers, negotiate, version_headers
+    requested = request.headers.get(VERSION_HEADER)
+    error = negotiate(requested)
+    if error:
+        from src.core.errors import UNSUPPORTED_API_VERSION, error_body
+        return JSONResponse(
+            status_code=400,
+            content=error_body(400, error, UNSUPPORTED_API_VERSION),
+            headers=version_headers(None),
+        )
+
+    response = await call_next(request)
+    add_headers(response, version_headers(requested))
+    return response
+
+# Response compression and request body limits (see src/core/http_middleware.py)
+from src.core.http_middleware import BodyLimitMiddleware, CompressionMiddleware
+app.add_middleware(CompressionMiddleware)
+app.add_middleware(BodyLimitMiddleware)
+
+# Rate limiting, panic recovery and per-route metrics (see src/core/request_middleware.py)
+from src.core.request_middleware import RateLimitMiddleware, RecoveryMiddleware, RequestMetricsMiddleware
+app.add_middleware(RateLimitMiddleware)
+app.add_middleware(RecoveryMiddleware)
+app.add_middleware(RequestMetricsMiddleware)
+
+# Read-only and maintenance modes (see src/core/server_mode.py)
+from src.core.server_mode import ServerModeMiddleware
+app.add_middleware(ServerModeMiddleware)
+
+# In-flight request tracking for graceful shutdown (see src/core/draining.py)
+from src.core.draining import DrainMiddleware
+app.add_middleware(DrainMiddleware)
+
+# Request IDs, outside everything that logs or answers errors
+from src.core.request_middleware import RequestIdMiddleware
+app.add_middleware(RequestIdMiddleware)
+
+# CORS (outermost, so 413s still carry CORS headers)
 app.add_middleware(
     CORSMiddleware,
     allow_origins=settings.BACKEND_CORS_ORIGINS,
     allow_credentials=True,
     allow_methods=["*"],
     allow_headers=["*"],
+    expose_headers=["Rice-Api-Version", "Deprecation", "Sunset", "Link", "ETag", "X-Request-ID"],
 )
 
+@app.on_event("startup")
+def install_drain_handlers():
+    """Drain before stopping on SIGTERM (see src/core/draining.py)."""
+    from src.core.draining import install_signal_handlers
+    install_signal_handlers()
+
+@app.on_event("shutdown")
+def shutdown_background_tasks():
+    """Stop supervised background tasks in order (see src/core/supervisor.py)."""
+    from src.core.draining import get_drain_state, log_aborted
+    log_aborted(get_drain_state(), "shutdown")
+    from src.core.supervisor import get_supervisor
+    get_supervisor().shutdown()
+
+def _readiness(traffic=None):
+    from src.core.draining import get_drain_state
+    from src.core.server_mode import get_server_mode
+    status = get_drain_state().status()
+    server_mode = get_server_mode()
+    status["mode"] = server_mode.get()
+    if status["status"] == "ready" and not server_mode.readiness(traffic):
+        status["status"] = status["mode"]["mode"]
+    if status["status"] != "ready":
+        return JSONResponse(status_code=503, content=status)
+    return status
+
+@app.get("/readyz")
+def readiness_check():
+    """
+    Readiness for load balancers: 503 once shutdown has started draining
+    or in maintenance mode. Read-only servers are ready.
+    """
+    return _readiness()
+
+@app.get("/readyz/read")
+def read_readiness_check():
+    """Ready for searches and other reads (same as ``/readyz``)."""
+    return _readiness("read")
+
+@app.get("/readyz/write")
+def write_readiness_check():
+    """Ready for uploads and other writes: 503 in read-only mode too."""
+    return _readiness("write")
+
 @app.get("/health")
 def health_check():
     """
@@ -89,6 +193,49 @@ def health_check():
         status["components"]["celery"] = {"status": "down", "error": str(e)}
         status["status"] = "degraded"
 
+    # Compute device (reported, not a pass/fail check)
+    try:
+        from src.core.device import get_device_info
+        status["device"] = get_device_info()
+    except Exception as e:
+        status["device"] = {"error": str(e)}
+
+    # Models loaded in this process (lazy loading / idle unload)
+    try:
+        from src.services.model_manager import get_model_manager
+        status["models"] = get_model_manager().get_health()
+    except Exception as e:
+        status["models"] = {"status": "down", "error": str(e)}
+
+    # Check remote ML workers (heartbeats on the event bus)
+    try:
+        from src.services.inference.ml_workers import ml_worker_health
+        ml_health = ml_worker_health()
+    except Exception as e:
+        ml_health = {"status": "down", "error": str(e)}
+    if ml_health["status"] != "disabled":
+        status["components"]["ml_workers"] = ml_health
+        if ml_health["status"] != "up":
+            status["status"] = "degraded"
+
+    # Git providers and repositories the webhook integration fetches from
+    try:
+        from src.services.webhooks.source_health import check_sources
+        sources = check_sources()
+    except Exception as e:
+        sources = {"source:git": {"status": "down", "message": f"Source check failed: {e}"}}
+    for name, source in sources.items():
+        status["components"][name] = source
+        if source["status"] != "up":
+            status["status"] = "degraded"
+
+    # Circuit breakers (an open breaker means searches are degraded)
+    from src.core.circuit_breaker import get_breakers
+    breakers = get_breakers()
+    status["breakers"] = breakers.snapshot()
+    if breakers.open_names():
+        status["status"] = "degraded"
+
     return status
 
 @app.get("/")
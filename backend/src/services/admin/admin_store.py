@@ -4,8 +4,11 @@ Redis-backed admin store for persistent state.
 Provides persistence for admin configuration, models, users, and audit logging.
 """
 
+import hashlib
+import hmac
 import json
 import logging
+import secrets
 from datetime import datetime
 from typing import Dict, List, Any, Optional
 import redis
@@ -23,9 +26,12 @@ class AdminStore:
     CONFIG_KEY = "rice:admin:config"
     USERS_KEY = "rice:admin:users"
     STORES_KEY = "rice:admin:stores"
+    TRASH_KEY = "rice:admin:stores_trash"
     CONNECTIONS_KEY = "rice:admin:connections"
     AUDIT_KEY = "rice:admin:audit"
     METRICS_KEY = "rice:admin:metrics"
+    ALERTS_KEY = "rice:admin:alerts"
+    VERSIONS_KEY = "rice:admin:versions"
     
     # File persistence directory
     PERSIST_DIR = "data/admin"
@@ -36,6 +42,7 @@ class AdminStore:
         "rice:admin:config": "config.json", 
         "rice:admin:users": "users.json",
         "rice:admin:stores": "stores.json",
+        "rice:admin:stores_trash": "stores_trash.json",
     }
     
     def __init__(self):
@@ -194,6 +201,12 @@ class AdminStore:
                     }
                     self.redis.set(self.STORES_KEY, json.dumps(default_stores))
                     self._persist_to_file(self.STORES_KEY, default_stores)
+
+            # Deleted stores awaiting purge
+            if not self.redis.exists(self.TRASH_KEY):
+                file_trash = self._load_from_file(self.TRASH_KEY)
+                if file_trash:
+                    self.redis.set(self.TRASH_KEY, json.dumps(file_trash))
             
             self._initialized = True
         except Exception as e:
@@ -262,6 +275,24 @@ class AdminStore:
             logger.error(f"Failed to set model: {e}")
             return False
     
+    def set_default_model(self, model_id: str) -> bool:
+        """Make a model the active one for its type (others of the type are deactivated)."""
+        try:
+            models = self.get_models()
+            model = models.get(model_id)
+            if model is None:
+                return False
+            for other in models.values():
+                if other.get("type") == model.get("type"):
+                    other["active"] = other is model
+            self.redis.set(self.MODELS_KEY, json.dumps(models))
+            self._persist_to_file(self.MODELS_KEY, models)
+            self.log_audit("model_default_set", f"Model {model_id} set as default {model.get('type')}")
+            return True
+        except Exception as e:
+            logger.error(f"Failed to set default model: {e}")
+            return False
+
     def delete_model(self, model_id: str) -> bool:
         """Delete a model and persist to file."""
         try:
@@ -325,6 +356,7 @@ class AdminStore:
             "rerank_enabled": overrides.get("rerank_enabled", settings.RERANK_ENABLED),
             "rerank_model": settings.RERANK_MODEL,
             "query_analysis_enabled": overrides.get("query_analysis_enabled", getattr(settings, "QUERY_ANALYSIS_ENABLED", False)),
+            "query_model_enabled": overrides.get("query_model_enabled", True),
             "worker_pool": overrides.get("worker_pool", "threads"),
             "worker_concurrency": overrides.get("worker_concurrency", 10),
             "model_ttl_seconds": overrides.get("model_ttl_seconds", settings.MODEL_TTL_SECONDS),
@@ -453,12 +485,29 @@ class AdminStore:
             stores[store_id] = store
             self.redis.set(self.STORES_KEY, json.dumps(stores))
             self._persist_to_file(self.STORES_KEY, stores)
+            self.bump_version("stores")
             self.log_audit("store_updated", f"Store {store_id} updated")
             return True
         except Exception as e:
             logger.error(f"Failed to set store: {e}")
             return False
 
+    def get_store_search_config(self, store_id: str) -> dict:
+        """Per-store search defaults (weights, rrf_k, rerank), empty if unset."""
+        return self.get_stores().get(store_id, {}).get("search") or {}
+
+    def set_store_search_config(self, store_id: str, config: dict) -> bool:
+        """Save per-store search defaults."""
+        stores = self.get_stores()
+        if store_id not in stores:
+            return False
+        if not self.set_store(store_id, {**stores[store_id], "search": config}):
+            return False
+        # Cached results were ranked with the old defaults
+        from src.services.search.query_cache import invalidate_store
+        invalidate_store(store_id)
+        return True
+
     def delete_store(self, store_id: str) -> bool:
         """Delete a store and persist to file."""
         try:
@@ -467,13 +516,155 @@ class AdminStore:
                 del stores[store_id]
                 self.redis.set(self.STORES_KEY, json.dumps(stores))
                 self._persist_to_file(self.STORES_KEY, stores)
+                self.redis.zrem(f"{self.METRICS_KEY}:store_searches", store_id)
+                self.redis.hdel(f"{self.METRICS_KEY}:store_last_search", store_id)
+                self.bump_version("stores")
                 self.log_audit("store_deleted", f"Store {store_id} deleted")
                 return True
             return False
         except Exception as e:
             logger.error(f"Failed to delete store: {e}")
             return False
+
+    # ============== Store Trash ==============
+
+    def get_trashed_stores(self) -> Dict[str, dict]:
+        """Soft-deleted stores (config plus ``deleted_at``/``deleted_by``/``purge_after``)."""
+        self._ensure_defaults()
+        try:
+            data = self.redis.get(self.TRASH_KEY)
+            return json.loads(data) if data else {}
+        except Exception as e:
+            logger.error(f"Failed to get trashed stores: {e}")
+            return {}
+
+    def _save_trash(self, trash: Dict[str, dict]):
+        self.redis.set(self.TRASH_KEY, json.dumps(trash))
+        self._persist_to_file(self.TRASH_KEY, trash)
+
+    def trash_store(self, store_id: str, user: str = "system", purge_after: str = None) -> Optional[dict]:
+        """
+        Move a store to the trash; its index is kept until it is purged.
+
+        Returns:
+            The trashed record, or None if the store doesn't exist
+        """
+        try:
+            stores = self.get_stores()
+            if store_id not in stores:
+                return None
+            record = {
+                **stores.pop(store_id),
+                "deleted_at": datetime.now().isoformat(),
+                "deleted_by": user,
+                "purge_after": purge_after,
+            }
+            trash = self.get_trashed_stores()
+            trash[store_id] = record
+            self._save_trash(trash)
+            self.redis.set(self.STORES_KEY, json.dumps(stores))
+            self._persist_to_file(self.STORES_KEY, stores)
+            self.bump_version("stores")
+            self.log_audit("store_trashed", f"Store {store_id} moved to trash", user)
+            return record
+        except Exception as e:
+            logger.error(f"Failed to trash store: {e}")
+            return None
+
+    def restore_store(self, store_id: str, user: str = "system") -> Optional[dict]:
+        """
+        Bring a store back from the trash.
+
+        Returns:
+            The restored config, or None if it isn't in the trash
+
+        Raises:
+            ValueError: A store with the same id was created since
+        """
+        trash = self.get_trashed_stores()
+        if store_id not in trash:
+            return None
+        stores = self.get_stores()
+        if store_id in stores:
+            raise ValueError(f"Store {store_id} already exists")
+        store = {
+            k: v for k, v in trash.pop(store_id).items()
+            if k not in ("deleted_at", "deleted_by", "purge_after")
+        }
+        stores[store_id] = store
+        self.redis.set(self.STORES_KEY, json.dumps(stores))
+        self._persist_to_file(self.STORES_KEY, stores)
+        self._save_trash(trash)
+        self.bump_version("stores")
+        self.log_audit("store_restored", f"Store {store_id} restored from trash", user)
+        return store
+
+    def remove_trashed_store(self, store_id: str) -> bool:
+        """Forget a trashed store once its index is purged."""
+        try:
+            trash = self.get_trashed_stores()
+            if store_id not in trash:
+                return False
+            del trash[store_id]
+            self._save_trash(trash)
+            self.redis.zrem(f"{self.METRICS_KEY}:store_searches", store_id)
+            self.redis.hdel(f"{self.METRICS_KEY}:store_last_search", store_id)
+            self.bump_version("stores")
+            return True
+        except Exception as e:
+            logger.error(f"Failed to remove trashed store: {e}")
+            return False
             
+    # ============== Store Usage ==============
+
+    def record_store_search(self, store_id: str):
+        """Count a search against a store and stamp the time."""
+        try:
+            self.redis.zincrby(f"{self.METRICS_KEY}:store_searches", 1, store_id)
+            self.redis.hset(
+                f"{self.METRICS_KEY}:store_last_search",
+                store_id,
+                datetime.now().isoformat()
+            )
+            self.bump_version("stores")
+        except Exception as e:
+            logger.error(f"Failed to record store search: {e}")
+
+    def get_store_usage(self) -> Dict[str, dict]:
+        """Get search counts per store, most used first."""
+        try:
+            counts = self.redis.zrevrange(
+                f"{self.METRICS_KEY}:store_searches", 0, -1, withscores=True
+            )
+            last_search = self.redis.hgetall(f"{self.METRICS_KEY}:store_last_search") or {}
+            return {
+                store_id: {
+                    "search_count": int(score),
+                    "last_searched_at": last_search.get(store_id)
+                }
+                for store_id, score in counts
+            }
+        except Exception as e:
+            logger.error(f"Failed to get store usage: {e}")
+            return {}
+
+    # ============== Versions ==============
+
+    def bump_version(self, name: str):
+        """Increment a change counter (``stores``); clients' ETags derive from it."""
+        try:
+            self.redis.hincrby(self.VERSIONS_KEY, name, 1)
+        except Exception as e:
+            logger.warning(f"Failed to bump {name} version: {e}")
+
+    def get_version(self, name: str) -> Optional[int]:
+        """Current change counter, or None if Redis is unavailable."""
+        try:
+            return int(self.redis.hget(self.VERSIONS_KEY, name) or 0)
+        except Exception as e:
+            logger.debug(f"Failed to read {name} version: {e}")
+            return None
+
     # ============== Connections ==============
 
     def get_connections(self) -> Dict[str, dict]:
@@ -501,6 +692,63 @@ class AdminStore:
             logger.error(f"Failed to set connection: {e}")
             return False
 
+    def touch_connection(self, connection_id: str, store: Optional[str] = None, searches: int = 0) -> bool:
+        """Record connection activity: last seen, stores used and search count."""
+        connection = self.get_connections().get(connection_id)
+        if connection is None:
+            return False
+        connection["last_seen"] = datetime.now().isoformat()
+        if store and store not in connection.setdefault("stores", []):
+            connection["stores"].append(store)
+        if searches:
+            connection["searches"] = connection.get("searches", 0) + searches
+        return self.set_connection(connection_id, connection)
+
+    @staticmethod
+    def _hash_token(token: str) -> str:
+        return hashlib.sha256(token.encode()).hexdigest()
+
+    def issue_connection_token(self, connection_id: str) -> Optional[str]:
+        """
+        Issue a new API token for a connection, replacing any previous one.
+
+        Only the hash is stored; the token is returned once.
+        """
+        connections = self.get_connections()
+        connection = connections.get(connection_id)
+        if connection is None:
+            return None
+        token = secrets.token_urlsafe(32)
+        connection["token_hash"] = self._hash_token(token)
+        connection["token_issued_at"] = datetime.now().isoformat()
+        if not self.set_connection(connection_id, connection):
+            return None
+        return token
+
+    def verify_connection_token(self, connection_id: str, token: Optional[str]) -> bool:
+        """Whether a token belongs to a connection."""
+        if not token:
+            return False
+        connection = self.get_connections().get(connection_id)
+        expected = (connection or {}).get("token_hash")
+        if not expected:
+            return False
+        return hmac.compare_digest(expected, self._hash_token(token))
+
+    def set_connection_enabled(self, connection_id: str, enabled: bool) -> bool:
+        """Enable or disable a connection (disabled connections can't index)."""
+        connection = self.get_connections().get(connection_id)
+        if connection is None:
+            return False
+        connection["enabled"] = enabled
+        if not self.set_connection(connection_id, connection):
+            return False
+        self.log_audit(
+            "connection_enabled" if enabled else "connection_disabled",
+            f"Connection {connection_id} {'enabled' if enabled else 'disabled'}"
+        )
+        return True
+
     def delete_connection(self, connection_id: str) -> bool:
         """Delete a connection."""
         try:
@@ -532,6 +780,9 @@ class AdminStore:
             self.redis.ltrim(self.AUDIT_KEY, 0, 999)
         except Exception as e:
             logger.error(f"Failed to log audit: {e}")
+
+        from src.services.events import emit
+        emit(f"audit.{action}", user=user, details=details)
     
     def get_audit_log(self, limit: int = 20) -> List[dict]:
         """Get recent audit log entries."""
@@ -542,6 +793,42 @@ class AdminStore:
             logger.error(f"Failed to get audit log: {e}")
             return []
     
+    # ============== Alerts ==============
+
+    def raise_alert(self, severity: str, source: str, message: str):
+        """Record an operational alert (most recent first) and route it to sinks."""
+        entry = {
+            "timestamp": datetime.now().isoformat(),
+            "severity": severity,
+            "source": source,
+            "message": message
+        }
+        try:
+            self.redis.lpush(self.ALERTS_KEY, json.dumps(entry))
+            # Keep only last 500 alerts
+            self.redis.ltrim(self.ALERTS_KEY, 0, 499)
+            logger.warning(f"ALERT [{severity}] {source}: {message}")
+        except Exception as e:
+            logger.error(f"Failed to raise alert: {e}")
+
+        from src.services.events import emit
+        emit(f"alert.{severity}", source=source, message=message)
+
+        try:
+            from src.services.admin.alert_sinks import get_alert_dispatcher
+            get_alert_dispatcher().dispatch(entry)
+        except Exception as e:
+            logger.error(f"Failed to dispatch alert: {e}")
+
+    def get_alerts(self, limit: int = 50) -> List[dict]:
+        """Get recent alerts."""
+        try:
+            entries = self.redis.lrange(self.ALERTS_KEY, 0, limit - 1)
+            return [json.loads(e) for e in entries]
+        except Exception as e:
+            logger.error(f"Failed to get alerts: {e}")
+            return []
+
     # ============== Metrics ==============
     
     def record_request_latency(self, latency_ms: float):
@@ -576,8 +863,13 @@ class AdminStore:
             return {"p50": 0, "p95": 0, "p99": 0}
     
     def increment_counter(self, name: str, amount: int = 1):
-        """Increment a counter."""
+        """Increment a counter (in SQLite with ``metrics.persistence: sqlite``)."""
         try:
+            from src.services.admin.metrics_store import get_metrics_store
+            metrics_store = get_metrics_store()
+            if metrics_store is not None:
+                metrics_store.increment(name, amount)
+                return
             self.redis.incrby(f"{self.METRICS_KEY}:counter:{name}", amount)
         except Exception as e:
             logger.error(f"Failed to increment counter: {e}")
@@ -585,6 +877,10 @@ class AdminStore:
     def get_counter(self, name: str) -> int:
         """Get a counter value."""
         try:
+            from src.services.admin.metrics_store import get_metrics_store
+            metrics_store = get_metrics_store()
+            if metrics_store is not None:
+                return metrics_store.get_counter(name)
             val = self.redis.get(f"{self.METRICS_KEY}:counter:{name}")
             return int(val) if val else 0
         except Exception as e:
@@ -0,0 +1,246 @@
+"""
+Alert Sinks.
+
+Operational and connection security alerts (``AdminStore.raise_alert``) are
+stored for the admin UI and, when sinks are configured under
+``alerts.sinks``, delivered to:
+
+- ``webhook``: JSON POST of the alert to any URL
+- ``slack``: Slack incoming webhook message
+- ``email``: SMTP mail (server settings under ``alerts.smtp``)
+
+Each sink receives alerts at or above its ``min_severity`` (info < warning
+< critical), optionally only from sources starting with one of its
+``sources`` prefixes. The same alert (severity, source, message) is
+delivered at most once per ``alerts.dedup_window_seconds``; repeats are
+recorded as suppressed. Every delivery attempt is kept for the status page.
+"""
+
+import hashlib
+import json
+import logging
+import smtplib
+from datetime import datetime
+from email.message import EmailMessage
+from typing import Any, Dict, List, Optional
+
+import httpx
+import redis
+
+from src.core.config import settings
+from src.core.supervisor import get_supervisor
+
+logger = logging.getLogger(__name__)
+
+SEVERITIES = {"info": 0, "warning": 1, "critical": 2}
+SINK_TYPES = ("webhook", "slack", "email")
+
+DELIVERED = "delivered"
+FAILED = "failed"
+SUPPRESSED = "suppressed"
+
+SLACK_COLORS = {"info": "#3b82f6", "warning": "#f59e0b", "critical": "#ef4444"}
+
+
+def severity_rank(severity: str) -> int:
+    return SEVERITIES.get((severity or "").lower(), 0)
+
+
+def sink_matches(sink: Dict[str, Any], alert: Dict[str, Any]) -> bool:
+    """Whether a sink routes an alert (severity threshold and source prefixes)."""
+    if not sink.get("enabled", True):
+        return False
+    if severity_rank(alert.get("severity")) < severity_rank(sink.get("min_severity", "warning")):
+        return False
+    sources = sink.get("sources") or []
+    return not sources or any(alert.get("source", "").startswith(s) for s in sources)
+
+
+def alert_fingerprint(alert: Dict[str, Any]) -> str:
+    key = f"{alert.get('severity')}|{alert.get('source')}|{alert.get('message')}"
+    return hashlib.sha1(key.encode()).hexdigest()
+
+
+def slack_payload(alert: Dict[str, Any]) -> Dict[str, Any]:
+    severity = alert.get("severity", "info")
+    return {
+        "text": f"[{severity.upper()}] {alert.get('source')}: {alert.get('message')}",
+        "attachments": [{
+            "color": SLACK_COLORS.get(severity, SLACK_COLORS["info"]),
+            "fields": [
+                {"title": "Source", "value": alert.get("source"), "short": True},
+                {"title": "Severity", "value": severity, "short": True},
+            ],
+            "footer": "Rice Search",
+            "ts": int(datetime.fromisoformat(alert["timestamp"]).timestamp()) if alert.get("timestamp") else None,
+        }],
+    }
+
+
+class AlertDispatcher:
+    """Routes alerts to the configured sinks and records deliveries."""
+
+    DEDUP_KEY = "rice:alerts:dedup"
+    DELIVERIES_KEY = "rice:alerts:deliveries"
+    MAX_DELIVERIES = 500
+
+    def __init__(self, redis_client=None):
+        self._redis = redis_client
+
+    @property
+    def redis(self) -> redis.Redis:
+        """Lazy Redis connection."""
+        if self._redis is None:
+            self._redis = redis.from_url(settings.REDIS_URL, decode_responses=True)
+        return self._redis
+
+    @property
+    def timeout(self) -> float:
+        return float(settings.get("alerts.timeout_seconds", 10.0))
+
+    @property
+    def dedup_window(self) -> int:
+        return int(settings.get("alerts.dedup_window_seconds", 300))
+
+    def sinks(self) -> List[Dict[str, Any]]:
+        """Configured sinks (unknown types are skipped)."""
+        sinks = []
+        for i, sink in enumerate(settings.get("alerts.sinks", []) or []):
+            if sink.get("type") not in SINK_TYPES:
+                logger.warning(f"Ignoring alert sink with unknown type: {sink.get('type')}")
+                continue
+            sinks.append({"name": sink.get("name") or f"{sink['type']}-{i}", **sink})
+        return sinks
+
+    def dispatch(self, alert: Dict[str, Any], background: bool = True):
+        """Deliver an alert to every matching sink (in a background thread by default)."""
+        targets = [s for s in self.sinks() if sink_matches(s, alert)]
+        if not targets:
+            return
+        if not self._first_occurrence(alert):
+            for sink in targets:
+                self._record(sink, alert, SUPPRESSED)
+            return
+        if background:
+            get_supervisor().spawn("alert-delivery", self._deliver_all, targets, alert, group="alerts")
+        else:
+            self._deliver_all(targets, alert)
+
+    def _first_occurrence(self, alert: Dict[str, Any]) -> bool:
+        """False if the same alert went out within the dedup window."""
+        if self.dedup_window <= 0:
+            return True
+        try:
+            key = f"{self.DEDUP_KEY}:{alert_fingerprint(alert)}"
+            return bool(self.redis.set(key, "1", nx=True, ex=self.dedup_window))
+        except Exception as e:
+            # Better a duplicate than a lost alert
+            logger.warning(f"Alert dedup check failed: {e}")
+            return True
+
+    def _deliver_all(self, sinks: List[Dict[str, Any]], alert: Dict[str, Any]):
+        for sink in sinks:
+            try:
+                self.deliver(sink, alert)
+                self._record(sink, alert, DELIVERED)
+            except Exception as e:
+                logger.error(f"Alert delivery to {sink['name']} failed: {e}")
+                self._record(sink, alert, FAILED, str(e))
+
+    def deliver(self, sink: Dict[str, Any], alert: Dict[str, Any]):
+        """Send one alert to one sink (raises on failure)."""
+        kind = sink["type"]
+        if kind == "webhook":
+            response = httpx.post(
+                sink["url"], json=alert, headers=sink.get("headers") or {}, timeout=self.timeout
+            )
+            response.raise_for_status()
+        elif kind == "slack":
+            response = httpx.post(sink["url"], json=slack_payload(alert), timeout=self.timeout)
+            response.raise_for_status()
+        elif kind == "email":
+            self._send_email(sink, alert)
+
+    def _send_email(self, sink: Dict[str, Any], alert: Dict[str, Any]):
+        recipients = sink.get("to") or []
+        if isinstance(recipients, str):
+            recipients = [recipients]
+        if not recipients:
+            raise ValueError("email sink has no recipients")
+
+        message = EmailMessage()
+        message["Subject"] = f"[Rice Search {alert.get('severity', 'info').upper()}] {alert.get('source')}"
+        message["From"] = settings.get("alerts.smtp.from", "rice-search@localhost")
+        message["To"] = ", ".join(recipients)
+        message.set_content(
+            f"{alert.get('message')}\n\n"
+            f"Severity: {alert.get('severity')}\n"
+            f"Source: {alert.get('source')}\n"
+            f"Time: {alert.get('timestamp')}\n"
+        )
+
+        host = settings.get("alerts.smtp.host", "localhost")
+        port = int(settings.get("alerts.smtp.port", 587))
+        with smtplib.SMTP(host, port, timeout=self.timeout) as smtp:
+            if settings.get("alerts.smtp.starttls", True):
+                smtp.starttls()
+            username = settings.get("alerts.smtp.username", "")
+            if username:
+                smtp.login(username, settings.get("alerts.smtp.password", ""))
+            smtp.send_message(message)
+
+    def _record(self, sink: Dict[str, Any], alert: Dict[str, Any], status: str, error: str = None):
+        entry = {
+            "timestamp": datetime.now().isoformat(),
+            "sink": sink["name"],
+            "type": sink["type"],
+            "status": status,
+            "severity": alert.get("severity"),
+            "source": alert.get("source"),
+            "message": alert.get("message"),
+            "error": error,
+        }
+        try:
+            self.redis.lpush(self.DELIVERIES_KEY, json.dumps(entry))
+            self.redis.ltrim(self.DELIVERIES_KEY, 0, self.MAX_DELIVERIES - 1)
+        except Exception as e:
+            logger.error(f"Failed to record alert delivery: {e}")
+
+    def get_deliveries(self, limit: int = 100) -> List[Dict[str, Any]]:
+        """Recent delivery attempts (most recent first)."""
+        try:
+            return [json.loads(e) for e in self.redis.lrange(self.DELIVERIES_KEY, 0, limit - 1)]
+        except Exception as e:
+            logger.error(f"Failed to get alert deliveries: {e}")
+            return []
+
+    def get_status(self) -> List[Dict[str, Any]]:
+        """Configured sinks (no secrets) with counts over recent deliveries."""
+        deliveries = self.get_deliveries(self.MAX_DELIVERIES)
+        status = []
+        for sink in self.sinks():
+            mine = [d for d in deliveries if d["sink"] == sink["name"]]
+            last = next((d for d in mine if d["status"] != SUPPRESSED), None)
+            status.append({
+                "name": sink["name"],
+                "type": sink["type"],
+                "enabled": sink.get("enabled", True),
+                "min_severity": sink.get("min_severity", "warning"),
+                "sources": sink.get("sources") or [],
+                "counts": {s: sum(1 for d in mine if d["status"] == s) for s in (DELIVERED, FAILED, SUPPRESSED)},
+                "last_status": last["status"] if last else None,
+                "last_error": last["error"] if last else None,
+                "last_attempt": last["timestamp"] if last else None,
+            })
+        return status
+
+
+# Singleton instance
+_dispatcher: Optional[AlertDispatcher] = None
+
+def get_alert_dispatcher() -> AlertDispatcher:
+    """Get global alert dispatcher instance."""
+    global _dispatcher
+    if _dispatcher is None:
+        _dispatcher = AlertDispatcher()
+    return _dispatcher
@@ -0,0 +1,154 @@
+"""
+Connection stats backfill.
+
+Rebuilds per-connection ``indexed_files`` counts and store associations by
+scanning chunk payloads in Qdrant. Used to recover connection state after
+``data/admin`` or Redis is lost, or when connections are enabled on a
+deployment that already has an index.
+"""
+
+import logging
+from datetime import datetime
+from typing import Dict, Iterable, List, Optional, Any
+
+
+logger = logging.getLogger(__name__)
+
+PAYLOAD_FIELDS = ["connection_id", "org_id", "full_path"]
+
+
+def aggregate_payloads(payloads: Iterable[dict]) -> Dict[str, Dict[str, Any]]:
+    """
+    Group chunk payloads by connection.
+
+    Chunks without a connection_id (indexed before connections were
+    tracked, or uploaded without one) are skipped.
+
+    Returns:
+        Dict of connection_id -> {"indexed_files": int, "stores": sorted list}
+    """
+    files: Dict[str, set] = {}
+    stores: Dict[str, set] = {}
+    for payload in payloads:
+        connection_id = (payload or {}).get("connection_id")
+        if not connection_id:
+            continue
+        org_id = payload.get("org_id", "public")
+        path = payload.get("full_path") or payload.get("file_path")
+        if path:
+            files.setdefault(connection_id, set()).add((org_id, path))
+        stores.setdefault(connection_id, set()).add(org_id)
+
+    return {
+        connection_id: {
+            "indexed_files": len(files.get(connection_id, ())),
+            "stores": sorted(org_ids),
+        }
+        for connection_id, org_ids in stores.items()
+    }
+
+
+class ConnectionBackfill:
+    """Scans Qdrant payloads and writes recovered stats to the admin store."""
+
+    def __init__(self, qdrant_client=None, admin_store=None):
+        self._qdrant = qdrant_client
+        self._store = admin_store
+
+    @property
+    def qdrant(self):
+        """Lazy Qdrant client."""
+        if self._qdrant is None:
+            from src.db.qdrant import get_qdrant_client
+            self._qdrant = get_qdrant_client()
+        return self._qdrant
+
+    @property
+    def store(self):
+        """Lazy admin store."""
+        if self._store is None:
+            from src.services.admin.admin_store import get_admin_store
+            self._store = get_admin_store()
+        return self._store
+
+    def collections(self) -> List[str]:
+        """Chunk collections to scan (every store's hot and cold tiers)."""
+        from src.services.search.tiering import tier_collections
+        return tier_collections(self.qdrant)
+
+    def iter_payloads(self, collection_name: str, batch_size: int = 512):
+        """Scroll a collection yielding only the payload fields we need."""
+        offset = None
+        while True:
+            points, offset = self.qdrant.scroll(
+                collection_name=collection_name,
+                limit=batch_size,
+                offset=offset,
+                with_payload=PAYLOAD_FIELDS,
+                with_vectors=False
+            )
+            for point in points:
+                yield point.payload or {}
+            if offset is None or not points:
+                break
+
+    def scan(self) -> Dict[str, Dict[str, Any]]:
+        """Aggregate connection stats across all chunk collections."""
+        payloads = []
+        for name in self.collections():
+            try:
+                payloads.extend(self.iter_payloads(name))
+            except Exception as e:
+                logger.warning(f"Backfill scan of {name} failed: {e}")
+        return aggregate_payloads(payloads)
+
+    def apply(self, stats: Dict[str, Dict[str, Any]]) -> Dict[str, int]:
+        """
+        Write recovered stats onto connection records.
+
+        Unknown connection ids are recreated as placeholder records marked
+        ``recovered`` so their contributions stay visible and revocable.
+        """
+        connections = self.store.get_connections()
+        updated = 0
+        recovered = 0
+        for connection_id, data in stats.items():
+            connection = connections.get(connection_id)
+            if connection is None:
+                connection = {
+                    "id": connection_id,
+                    "user_id": "unknown",
+                    "device_name": "recovered",
+                    "version": "unknown",
+                    "last_seen": None,
+                    "ip": None,
+                    "recovered": True,
+                }
+                recovered += 1
+            else:
+                updated += 1
+            connection["indexed_files"] = data["indexed_files"]
+            connection["stores"] = data["stores"]
+            connection["backfilled_at"] = datetime.now().isoformat()
+            self.store.set_connection(connection_id, connection)
+        return {"updated": updated, "recovered": recovered}
+
+    def run(self, dry_run: bool = False) -> Dict[str, Any]:
+        """Scan payloads and (unless dry_run) apply the result."""
+        stats = self.scan()
+        result = {
+            "connections": len(stats),
+            "indexed_files": sum(s["indexed_files"] for s in stats.values()),
+            "dry_run": dry_run,
+        }
+        if dry_run:
+            result["stats"] = stats
+        else:
+            result.update(self.apply(stats))
+            self.store.log_audit(
+                "connections_backfilled",
+                f"Rebuilt stats for {len(stats)} connections from index payloads",
+                "admin"
+            )
+        logger.info(f"Connection backfill: {result}")
+        return result
@@ -0,0 +1,297 @@
+"""
+Duplicate Code Report.
+
+Search collapses chunks of the same file into one result; this finds the
+opposite case offline: near-identical chunks in *different* files (copied
+helpers, vendored code, generated clients). Every chunk of the store is
+looked up against the store's own dense vectors (the same cosine
+similarity search uses), and pairs at or above
+``stores.duplicates.threshold`` are grouped into clusters and file pairs:
+
+    rice:duplicates:<store>  {"state", "task_id", "clusters", "file_pairs", ...}
+
+Reports are built by a Celery task (``POST /stores/{id}/duplicates``) and
+read with ``GET /stores/{id}/duplicates``. Chunks shorter than
+``min_lines`` (imports, license headers) are skipped, and only the first
+``max_chunks`` chunks of a store are scanned. Chunks moved to the cold
+tier are not included.
+"""
+
+import json
+import logging
+from datetime import datetime
+from typing import Any, Dict, Iterable, List, Optional, Tuple
+
+import redis
+from qdrant_client.models import FieldCondition, Filter, MatchValue
+
+from src.core.config import settings
+
+logger = logging.getLogger(__name__)
+
+QUEUED = "queued"
+RUNNING = "running"
+DONE = "done"
+FAILED = "failed"
+
+PAYLOAD_FIELDS = ["full_path", "file_path", "start_line", "end_line", "language"]
+
+
+def _setting(name: str, default):
+    return type(default)(settings.get(f"stores.duplicates.{name}", default))
+
+
+def chunk_info(point_id: str, payload: Dict[str, Any]) -> Dict[str, Any]:
+    """The parts of a chunk a report shows."""
+    return {
+        "chunk_id": point_id,
+        "path": (payload.get("full_path") or payload.get("file_path") or "").replace("\\", "/"),
+        "start_line": payload.get("start_line"),
+        "end_line": payload.get("end_line"),
+        "language": payload.get("language"),
+    }
+
+
+def long_enough(chunk: Dict[str, Any], min_lines: int) -> bool:
+    """Chunks without line ranges (old payloads) are always kept."""
+    if chunk["start_line"] is None or chunk["end_line"] is None:
+        return True
+    return chunk["end_line"] - chunk["start_line"] + 1 >= min_lines
+
+
+def cluster_pairs(
+    pairs: Dict[Tuple[str, str], float], chunks: Dict[str, Dict[str, Any]]
+) -> List[Dict[str, Any]]:
+    """
+    Group similar chunk pairs into clusters (connected components).
+
+    Returns:
+        Clusters, largest first: size, files, max/min similarity and chunks
+    """
+    parent: Dict[str, str] = {}
+
+    def find(x: str) -> str:
+        parent.setdefault(x, x)
+        while parent[x] != x:
+            parent[x] = parent[parent[x]]
+            x = parent[x]
+        return x
+
+    for a, b in pairs:
+        parent[find(a)] = find(b)
+
+    members: Dict[str, List[str]] = {}
+    for chunk_id in parent:
+        members.setdefault(find(chunk_id), []).append(chunk_id)
+
+    clusters = []
+    for ids in members.values():
+        ids = set(ids)
+        scores = [score for (a, b), score in pairs.items() if a in ids]
+        entries = sorted((chunks[i] for i in ids), key=lambda c: (c["path"], c["start_line"] or 0))
+        clusters.append({
+            "size": len(entries),
+            "files": len({c["path"] for c in entries}),
+            "max_similarity": round(max(scores), 4),
+            "min_similarity": round(min(scores), 4),
+            "chunks": entries,
+        })
+    clusters.sort(key=lambda c: (-c["size"], -c["max_similarity"], c["chunks"][0]["path"]))
+    for i, cluster in enumerate(clusters, start=1):
+        cluster["id"] = i
+    return clusters
+
+
+def file_pairs(
+    pairs: Dict[Tuple[str, str], float], chunks: Dict[str, Dict[str, Any]]
+) -> List[Dict[str, Any]]:
+    """
+    Similar chunk pairs rolled up per pair of files.
+
+    Returns:
+        File pairs with the most shared chunks first
+    """
+    rollup: Dict[Tuple[str, str], List[float]] = {}
+    for (a, b), score in pairs.items():
+        key = tuple(sorted((chunks[a]["path"], chunks[b]["path"])))
+        rollup.setdefault(key, []).append(score)
+    result = [
+        {
+            "file_a": a,
+            "file_b": b,
+            "chunks": len(scores),
+            "max_similarity": round(max(scores), 4),
+            "mean_similarity": round(sum(scores) / len(scores), 4),
+        }
+        for (a, b), scores in rollup.items()
+    ]
+    result.sort(key=lambda p: (-p["chunks"], -p["max_similarity"], p["file_a"], p["file_b"]))
+    return result
+
+
+class DuplicateReports:
+    """Builds and keeps per-store near-duplicate chunk reports."""
+
+    KEY_PREFIX = "rice:duplicates"
+
+    def __init__(self, redis_client=None, qdrant_client=None):
+        self._redis = redis_client
+        self._qdrant = qdrant_client
+
+    @property
+    def redis(self) -> redis.Redis:
+        """Lazy Redis connection."""
+        if self._redis is None:
+            self._redis = redis.from_url(settings.REDIS_URL, decode_responses=True)
+        return self._redis
+
+    @property
+    def qdrant(self):
+        """Lazy Qdrant client."""
+        if self._qdrant is None:
+            from src.db.qdrant import get_qdrant_client
+            self._qdrant = get_qdrant_client()
+        return self._qdrant
+
+    def _key(self, store_id: str) -> str:
+        return f"{self.KEY_PREFIX}:{store_id}"
+
+    def get(self, store_id: str) -> Dict[str, Any]:
+        """The store's last report (``state`` None if none was built)."""
+        data = self.redis.get(self._key(store_id))
+        return json.loads(data) if data else {"store": store_id, "state": None}
+
+    def _save(self, store_id: str, report: Dict[str, Any]):
+        self.redis.set(self._key(store_id), json.dumps(report))
+
+    def start(self, store_id: str, task_id: str) -> Dict[str, Any]:
+        """
+        Record a queued report, keeping the last finished one readable.
+
+        Raises:
+            ValueError: a report for the store is already being built
+        """
+        previous = self.get(store_id)
+        if previous.get("state") in (QUEUED, RUNNING):
+            raise ValueError(f"A duplicate report for {store_id} is already {previous['state']}")
+        report = {
+            **previous,
+            "store": store_id,
+            "state": QUEUED,
+            "task_id": task_id,
+            "queued_at": datetime.now().isoformat(),
+            "error": None,
+        }
+        self._save(store_id, report)
+        return report
+
+    def drop(self, store_id: str):
+        """Forget a purged store's report."""
+        self.redis.delete(self._key(store_id))
+
+    def _scan(self, collection: str, store_filter: Filter, max_chunks: int) -> Iterable[Any]:
+        offset = None
+        seen = 0
+        while seen < max_chunks:
+            points, offset = self.qdrant.scroll(
+                collection_name=collection,
+                scroll_filter=store_filter,
+                limit=min(256, max_chunks - seen),
+                offset=offset,
+                with_payload=PAYLOAD_FIELDS,
+                with_vectors=["dense"],
+            )
+            for point in points:
+                yield point
+            seen += len(points)
+            if offset is None or not points:
+                return
+
+    def find_pairs(self, store_id: str) -> Tuple[Dict[Tuple[str, str], float], Dict[str, Dict[str, Any]], bool]:
+        """
+        Similar chunk pairs across files.
+
+        Returns:
+            (chunk id pair -> similarity, chunk id -> chunk info, truncated)
+        """
+        from src.services.ingestion.migration import store_collection
+
+        threshold = _setting("threshold", 0.95)
+        neighbors = _setting("neighbors", 10)
+        min_lines = _setting("min_lines", 3)
+        max_chunks = _setting("max_chunks", 20000)
+        collection = store_collection(store_id)
+        store_filter = Filter(must=[FieldCondition(key="org_id", match=MatchValue(value=store_id))])
+
+        chunks: Dict[str, Dict[str, Any]] = {}
+        pairs: Dict[Tuple[str, str], float] = {}
+        scanned = 0
+        for point in self._scan(collection, store_filter, max_chunks):
+            scanned += 1
+            chunk = chunk_info(str(point.id), point.payload or {})
+            vector = (point.vector or {}).get("dense") if isinstance(point.vector, dict) else point.vector
+            if vector is None or not chunk["path"] or not long_enough(chunk, min_lines):
+                continue
+            hits = self.qdrant.query_points(
+                collection_name=collection,
+                query=vector,
+                using="dense",
+                query_filter=store_filter,
+                score_threshold=threshold,
+                limit=neighbors + 1,
+                with_payload=PAYLOAD_FIELDS,
+            ).points
+            for hit in hits:
+                other = chunk_info(str(hit.id), hit.payload or {})
+                if other["chunk_id"] == chunk["chunk_id"] or other["path"] == chunk["path"]:
+                    continue
+                if not long_enough(other, min_lines):
+                    continue
+                chunks[chunk["chunk_id"]] = chunk
+                chunks[other["chunk_id"]] = other
+                key = tuple(sorted((chunk["chunk_id"], other["chunk_id"])))
+                pairs[key] = max(pairs.get(key, 0.0), float(hit.score))
+        return pairs, chunks, scanned >= max_chunks
+
+    def run(self, store_id: str) -> Dict[str, Any]:
+        """Build the store's report and save it."""
+        report = {**self.get(store_id), "store": store_id, "state": RUNNING, "started_at": datetime.now().isoformat()}
+        self._save(store_id, report)
+        try:
+            pairs, chunks, truncated = self.find_pairs(store_id)
+            clusters = cluster_pairs(pairs, chunks)
+            files = file_pairs(pairs, chunks)
+        except Exception as e:
+            logger.error(f"Duplicate report for {store_id} failed: {e}")
+            self._save(store_id, {**report, "state": FAILED, "error": str(e)})
+            raise
+
+        max_clusters = _setting("max_clusters", 100)
+        max_file_pairs = _setting("max_file_pairs", 200)
+        report = {
+            **report,
+            "state": DONE,
+            "finished_at": datetime.now().isoformat(),
+            "threshold": _setting("threshold", 0.95),
+            "truncated": truncated,
+            "duplicate_chunks": len(chunks),
+            "total_clusters": len(clusters),
+            "total_file_pairs": len(files),
+            "clusters": clusters[:max_clusters],
+            "file_pairs": files[:max_file_pairs],
+            "error": None,
+        }
+        self._save(store_id, report)
+        logger.info(f"Duplicate report for {store_id}: {len(clusters)} clusters across {len(files)} file pairs")
+        return report
+
+
+# Singleton instance
+_duplicate_reports: Optional[DuplicateReports] = None
+
+def get_duplicate_reports() -> DuplicateReports:
+    """Get global duplicate reports instance."""
+    global _duplicate_reports
+    if _duplicate_reports is None:
+        _duplicate_reports = DuplicateReports()
+    return _duplicate_reports
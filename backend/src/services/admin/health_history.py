@@ -0,0 +1,288 @@
+"""
+Component health history.
+
+Persists health state transitions (healthy -> down and back) to Redis so the
+dashboard can show uptime and incidents over a time window, instead of only
+the status at the moment of the request.
+"""
+
+import json
+import time
+import logging
+from typing import Dict, List, Optional, Any
+
+from src.core.config import settings
+
+logger = logging.getLogger(__name__)
+
+HEALTHY = "healthy"
+UNKNOWN = "unknown"
+
+
+def check_components(store) -> Dict[str, str]:
+    """
+    Probe infrastructure components.
+
+    Args:
+        store: AdminStore (used for its Redis connection)
+
+    Returns:
+        Dict of component name -> "healthy" | "down" | "unknown"
+    """
+    import requests
+
+    components = {
+        "redis": "down",
+        "qdrant": "down",
+        "minio": "down",
+        "worker": UNKNOWN
+    }
+
+    # 1. Redis
+    try:
+        if store.redis.ping():
+            components["redis"] = HEALTHY
+    except Exception:
+        pass
+
+    # 2. Qdrant
+    try:
+        q_res = requests.get(f"{settings.QDRANT_URL}/readyz", timeout=1)
+        if q_res.status_code == 200:
+            components["qdrant"] = HEALTHY
+    except Exception:
+        pass
+
+    # 3. MinIO (internal docker name first, then localhost fallback)
+    endpoints = [
+        "http://minio:9000/minio/health/live",
+        f"{settings.MINIO_ENDPOINT.replace('minio:9000', 'localhost:9000')}/minio/health/live"
+    ]
+    for ep in endpoints:
+        try:
+            m_res = requests.get(ep, timeout=1)
+            if m_res.status_code == 200:
+                components["minio"] = HEALTHY
+                break
+        except Exception:
+            continue
+
+    # 4. Worker
+    # Ideal: Check a heartbeat key. For now, assume if Redis is up, Worker is likely okay.
+    if components["redis"] == HEALTHY:
+        components["worker"] = HEALTHY
+
+    # 5. Remote ML workers (only tracked when inference is sent to them)
+    try:
+        from src.services.inference.ml_workers import ml_worker_health
+        ml_status = ml_worker_health()["status"]
+    except Exception:
+        ml_status = "down"
+    if ml_status != "disabled":
+        components["ml_workers"] = HEALTHY if ml_status == "up" else "down"
+
+    # 6. Git sources (only with webhooks.git configured; cached between probes)
+    try:
+        from src.services.webhooks.source_health import check_sources
+        sources = check_sources()
+    except Exception:
+        sources = {}
+    for name, source in sources.items():
+        components[name] = HEALTHY if source["status"] == "up" else "down"
+
+    return components
+
+
+def summarize(
+    transitions: List[dict],
+    start: float,
+    end: float,
+    buckets: int = 24,
+) -> Dict[str, dict]:
+    """
+    Compute uptime, incidents and a bucketed timeline per component.
+
+    Args:
+        transitions: Transition dicts (component, from, to, timestamp), any order
+        start: Window start (epoch seconds)
+        end: Window end (epoch seconds)
+        buckets: Number of timeline buckets across the window
+
+    Returns:
+        Dict of component -> {status, uptime_percent, incidents, timeline}
+    """
+    by_component: Dict[str, List[dict]] = {}
+    for t in sorted(transitions, key=lambda t: t["timestamp"]):
+        by_component.setdefault(t["component"], []).append(t)
+
+    bucket_size = (end - start) / buckets if buckets else 0
+    result = {}
+
+    for component, items in by_component.items():
+        # State at window start is the last transition before it
+        state = None
+        for t in items:
+            if t["timestamp"] <= start:
+                state = t["to"]
+
+        # Build (from, to, state) segments across the window
+        segments = []
+        cursor = start
+        for t in items:
+            if t["timestamp"] <= start or t["timestamp"] > end:
+                continue
+            segments.append((cursor, t["timestamp"], state))
+            state = t["to"]
+            cursor = t["timestamp"]
+        segments.append((cursor, end, state))
+
+        known = sum(b - a for a, b, s in segments if s not in (None, UNKNOWN))
+        healthy = sum(b - a for a, b, s in segments if s == HEALTHY)
+
+        # Merge consecutive unhealthy segments into incidents
+        incidents = []
+        for a, b, s in segments:
+            if s in (None, UNKNOWN, HEALTHY) or b <= a:
+                continue
+            if incidents and incidents[-1]["_end"] == a:
+                incidents[-1]["_end"] = b
+            else:
+                incidents.append({"start": a, "_end": b, "status": s})
+        for inc in incidents:
+            ongoing = inc["_end"] >= end and state != HEALTHY
+            inc["end"] = None if ongoing else inc["_end"]
+            inc["duration_seconds"] = round(inc.pop("_end") - inc["start"], 1)
+
+        timeline = []
+        for i in range(buckets):
+            b_start = start + i * bucket_size
+            b_end = b_start + bucket_size
+            up = down = 0.0
+            for a, b, s in segments:
+                overlap = min(b, b_end) - max(a, b_start)
+                if overlap <= 0 or s in (None, UNKNOWN):
+                    continue
+                if s == HEALTHY:
+                    up += overlap
+                else:
+                    down += overlap
+            if up and down:
+                timeline.append("degraded")
+            elif up:
+                timeline.append("up")
+            elif down:
+                timeline.append("down")
+            else:
+                timeline.append("unknown")
+
+        result[component] = {
+            "status": state or UNKNOWN,
+            "uptime_percent": round(healthy / known * 100, 2) if known else None,
+            "incidents": incidents,
+            "timeline": timeline,
+        }
+
+    return result
+
+
+class HealthHistory:
+    """Redis-backed store of component health transitions."""
+
+    STATE_KEY = "rice:admin:health:state"
+    TRANSITIONS_KEY = "rice:admin:health:transitions"
+
+    def __init__(self, redis_client=None):
+        self._redis = redis_client
+
+    @property
+    def redis(self):
+        """Reuse the admin store's Redis connection."""
+        if self._redis is None:
+            from src.services.admin.admin_store import get_admin_store
+            self._redis = get_admin_store().redis
+        return self._redis
+
+    @property
+    def retention_seconds(self) -> float:
+        return float(settings.get("health.history.retention_days", 7)) * 86400
+
+    def record(self, components: Dict[str, str], now: float = None) -> List[dict]:
+        """
+        Record a health sample, persisting only state changes.
+
+        Returns:
+            Transitions written for this sample
+        """
+        now = now if now is not None else time.time()
+        written = []
+        try:
+            current = self.redis.hgetall(self.STATE_KEY) or {}
+            for component, status in components.items():
+                previous = json.loads(current[component])["status"] if component in current else None
+                if previous == status:
+                    continue
+                transition = {
+                    "component": component,
+                    "from": previous,
+                    "to": status,
+                    "timestamp": now,
+                }
+                self.redis.zadd(self.TRANSITIONS_KEY, {json.dumps(transition): now})
+                self.redis.hset(self.STATE_KEY, component, json.dumps({"status": status, "since": now}))
+                written.append(transition)
+
+            if written:
+                self._trim(now)
+        except Exception as e:
+            logger.error(f"Failed to record health history: {e}")
+        return written
+
+    def _trim(self, now: float):
+        """
+        Drop transitions past retention, keeping each component's last one
+        before the cutoff so the window's starting state can still be derived.
+        """
+        cutoff = now - self.retention_seconds
+        latest: Dict[str, str] = {}
+        stale = []
+        # Oldest first: every earlier transition of a component is stale
+        for entry in self.redis.zrangebyscore(self.TRANSITIONS_KEY, "-inf", cutoff):
+            component = json.loads(entry)["component"]
+            if component in latest:
+                stale.append(latest[component])
+            latest[component] = entry
+        if stale:
+            self.redis.zrem(self.TRANSITIONS_KEY, *stale)
+
+    def get_transitions(self, since: float = None) -> List[dict]:
+        """Transitions newest first, optionally since a timestamp."""
+        try:
+            entries = self.redis.zrevrangebyscore(
+                self.TRANSITIONS_KEY, "+inf", since if since is not None else "-inf"
+            )
+            return [json.loads(e) for e in entries]
+        except Exception as e:
+            logger.error(f"Failed to get health transitions: {e}")
+            return []
+
+    def get_history(self, hours: float = 24, buckets: int = 24, now: float = None) -> Dict[str, Any]:
+        """Uptime, incidents and timeline per component over the window."""
+        now = now if now is not None else time.time()
+        start = now - hours * 3600
+        all_transitions = self.get_transitions()
+        return {
+            "window": {"start": start, "end": now, "hours": hours, "buckets": buckets},
+            "components": summarize(all_transitions, start, now, buckets),
+            "transitions": [t for t in all_transitions if t["timestamp"] >= start],
+        }
+
+
+# Singleton instance
+_history: Optional[HealthHistory] = None
+
+def get_health_history() -> HealthHistory:
+    """Get global health history instance."""
+    global _history
+    if _history is None:
+        _history = HealthHistory()
+    return _history
@@ -0,0 +1,137 @@
+"""
+Language backfill.
+
+Fills in ``language`` on chunks indexed before the pipeline detected it
+(clients rarely send one), so language filters match existing content
+without a full re-index. Detection uses the file path and the text of the
+file's first chunk.
+"""
+
+import logging
+from typing import Any, Dict, List, Optional
+
+from qdrant_client.models import Filter, FieldCondition, MatchValue, IsEmptyCondition, IsNullCondition, PayloadField
+
+from src.db.content_store import payload_text
+from src.services.ingestion.language import detect_language
+
+logger = logging.getLogger(__name__)
+
+PAYLOAD_FIELDS = ["full_path", "file_path", "text", "chunk_index"]
+
+
+def missing_language_filter(org_id: Optional[str] = None) -> Filter:
+    """Chunks whose language is absent or null, optionally in one store."""
+    must = []
+    if org_id:
+        must.append(FieldCondition(key="org_id", match=MatchValue(value=org_id)))
+    return Filter(
+        must=must,
+        should=[
+            IsEmptyCondition(is_empty=PayloadField(key="language")),
+            IsNullCondition(is_null=PayloadField(key="language")),
+        ]
+    )
+
+
+class LanguageBackfill:
+    """Detects and writes missing chunk languages in Qdrant."""
+
+    def __init__(self, qdrant_client=None):
+        self._qdrant = qdrant_client
+
+    @property
+    def qdrant(self):
+        """Lazy Qdrant client."""
+        if self._qdrant is None:
+            from src.db.qdrant import get_qdrant_client
+            self._qdrant = get_qdrant_client()
+        return self._qdrant
+
+    def collections(self, org_id: Optional[str] = None) -> List[str]:
+        """Chunk collections to scan (hot tiers plus cold tiers if present)."""
+        from src.services.search.tiering import tier_collections
+        return tier_collections(self.qdrant, org_id)
+
+    def scan(self, collection_name: str, org_id: Optional[str] = None, batch_size: int = 512) -> Dict[str, Dict[str, Any]]:
+        """
+        Group chunks missing a language by file.
+
+        Returns:
+            Dict of path -> {"ids": [...], "text": first chunk's text}
+        """
+        files: Dict[str, Dict[str, Any]] = {}
+        offset = None
+        while True:
+            points, offset = self.qdrant.scroll(
+                collection_name=collection_name,
+                scroll_filter=missing_language_filter(org_id),
+                limit=batch_size,
+                offset=offset,
+                with_payload=PAYLOAD_FIELDS,
+                with_vectors=False
+            )
+            for point in points:
+                payload = point.payload or {}
+                path = payload.get("full_path") or payload.get("file_path")
+                if not path:
+                    continue
+                entry = files.setdefault(path, {"ids": [], "text": "", "chunk_index": None})
+                entry["ids"].append(point.id)
+                index = payload.get("chunk_index", 0)
+                if entry["chunk_index"] is None or index < entry["chunk_index"]:
+                    entry["chunk_index"] = index
+                    entry["text"] = payload_text(payload)
+            if offset is None or not points:
+                break
+        return files
+
+    def run(self, org_id: Optional[str] = None, dry_run: bool = False) -> Dict[str, Any]:
+        """
+        Detect and (unless dry_run) write languages for unlabeled chunks.
+
+        Args:
+            org_id: Restrict to one store (default: every store)
+            dry_run: Report what would be written without changing payloads
+
+        Returns:
+            Dict with file/chunk counts and per-language file counts
+        """
+        languages: Dict[str, int] = {}
+        files = chunks = 0
+        undetected: List[str] = []
+
+        for collection_name in self.collections(org_id):
+            try:
+                missing = self.scan(collection_name, org_id)
+            except Exception as e:
+                logger.warning(f"Language backfill scan of {collection_name} failed: {e}")
+                continue
+
+            for path, entry in missing.items():
+                language = detect_language(path, entry["text"])
+                if not language:
+                    undetected.append(path)
+                    continue
+                languages[language] = languages.get(language, 0) + 1
+                files += 1
+                chunks += len(entry["ids"])
+                if not dry_run:
+                    self.qdrant.set_payload(
+                        collection_name=collection_name,
+                        payload={"language": language},
+                        points=entry["ids"]
+                    )
+
+        result = {
+            "dry_run": dry_run,
+            "files": files,
+            "chunks_updated": 0 if dry_run else chunks,
+            "chunks_matched": chunks,
+            "languages": languages,
+            "undetected": len(undetected),
+        }
+        if dry_run:
+            result["undetected_files"] = sorted(undetected)[:100]
+        logger.info(f"Language backfill: {result}")
+        return result
@@ -0,0 +1,221 @@
+"""
+SQLite Metrics Persistence.
+
+Metric counters (``AdminStore.increment_counter``) and the metrics time
+series live in Redis, which most deployments run without persistence, so
+dashboards start from zero after a restart. With
+``metrics.persistence: sqlite`` they are kept in a SQLite file instead
+(``metrics.sqlite.path``, default ``data/metrics/metrics.db``):
+
+- Writes are buffered in memory and flushed every
+  ``metrics.sqlite.flush_interval_seconds`` (and at exit) as
+  ``value = value + delta`` upserts, so the API and worker processes can
+  share the file.
+- Reads come from the file plus this process's unflushed deltas, so
+  counters and series pick up where they were after a restart.
+- Compaction (every ``metrics.sqlite.compact_interval_seconds``) drops
+  series buckets past their tier's retention and reclaims the space.
+
+``metrics.persistence: redis`` (the default) keeps the Redis behaviour.
+"""
+
+import atexit
+import logging
+import os
+import sqlite3
+import threading
+import time
+from typing import Dict, Iterable, List, Optional, Tuple
+
+from src.core.config import settings
+from src.core.supervisor import SupervisedTask, get_supervisor
+
+logger = logging.getLogger(__name__)
+
+REDIS = "redis"
+SQLITE = "sqlite"
+
+SCHEMA = """
+CREATE TABLE IF NOT EXISTS counters (
+    name TEXT PRIMARY KEY,
+    value INTEGER NOT NULL
+);
+CREATE TABLE IF NOT EXISTS series (
+    bucket_seconds INTEGER NOT NULL,
+    scope TEXT NOT NULL,
+    start INTEGER NOT NULL,
+    field TEXT NOT NULL,
+    value REAL NOT NULL,
+    PRIMARY KEY (bucket_seconds, scope, start, field)
+);
+"""
+
+SeriesKey = Tuple[int, str, int, str]
+
+
+def persistence_backend() -> str:
+    """``redis`` or ``sqlite`` (``metrics.persistence``)."""
+    backend = str(settings.get("metrics.persistence", REDIS)).lower()
+    if backend not in (REDIS, SQLITE):
+        logger.warning(f"Unknown metrics.persistence '{backend}', using redis")
+        return REDIS
+    return backend
+
+
+class SqliteMetricsStore:
+    """Buffered metric counters and time series buckets in a SQLite file."""
+
+    def __init__(self, path: Optional[str] = None, autoflush: bool = True):
+        self.path = path or settings.get("metrics.sqlite.path", "data/metrics/metrics.db")
+        self.flush_interval = float(settings.get("metrics.sqlite.flush_interval_seconds", 10))
+        self.compact_interval = float(settings.get("metrics.sqlite.compact_interval_seconds", 3600))
+        self._local = threading.local()
+        self._lock = threading.Lock()
+        self._counters: Dict[str, int] = {}
+        self._series: Dict[SeriesKey, float] = {}
+        self._last_compact = time.monotonic()
+        self._task: Optional[SupervisedTask] = None
+        if autoflush:
+            self._task = get_supervisor().spawn_loop(
+                "metrics-flush", self._tick, interval=self.flush_interval, group="metrics"
+            )
+            atexit.register(self.close)
+
+    @property
+    def conn(self) -> sqlite3.Connection:
+        """Per-thread connection (the flush thread writes while requests read)."""
+        conn = getattr(self._local, "conn", None)
+        if conn is None:
+            directory = os.path.dirname(self.path)
+            if directory:
+                os.makedirs(directory, exist_ok=True)
+            conn = sqlite3.connect(self.path, timeout=30)
+            conn.execute("PRAGMA journal_mode=WAL")
+            conn.execute("PRAGMA synchronous=NORMAL")
+            conn.executescript(SCHEMA)
+            self._local.conn = conn
+        return conn
+
+    # ============== Writes ==============
+
+    def increment(self, name: str, amount: int = 1):
+        with self._lock:
+            self._counters[name] = self._counters.get(name, 0) + int(amount)
+
+    def add_series(self, bucket_seconds: int, scope: str, start: int, counts: Dict[str, float]):
+        with self._lock:
+            for field, amount in counts.items():
+                key = (bucket_seconds, scope, start, field)
+                self._series[key] = self._series.get(key, 0) + amount
+
+    def flush(self) -> int:
+        """Write buffered deltas; returns how many rows were touched."""
+        # Held while writing so readers never miss deltas between buffer and file
+        with self._lock:
+            if not self._counters and not self._series:
+                return 0
+            try:
+                with self.conn:
+                    self.conn.executemany(
+                        "INSERT INTO counters (name, value) VALUES (?, ?) "
+                        "ON CONFLICT(name) DO UPDATE SET value = value + excluded.value",
+                        self._counters.items()
+                    )
+                    self.conn.executemany(
+                        "INSERT INTO series (bucket_seconds, scope, start, field, value) VALUES (?, ?, ?, ?, ?) "
+                        "ON CONFLICT(bucket_seconds, scope, start, field) DO UPDATE SET value = value + excluded.value",
+                        [(*key, value) for key, value in self._series.items()]
+                    )
+            except Exception as e:
+                # Deltas stay buffered for the next flush
+                logger.error(f"Failed to flush metrics to {self.path}: {e}")
+                return 0
+            written = len(self._counters) + len(self._series)
+            self._counters, self._series = {}, {}
+        return written
+
+    # ============== Reads ==============
+
+    def get_counter(self, name: str) -> int:
+        with self._lock:
+            row = self.conn.execute("SELECT value FROM counters WHERE name = ?", (name,)).fetchone()
+            return int(row[0] if row else 0) + self._counters.get(name, 0)
+
+    def read_series(self, bucket_seconds: int, scope: str, starts: List[int]) -> List[Dict[str, float]]:
+        """Bucket fields for each of ``starts`` (empty dicts for missing buckets)."""
+        if not starts:
+            return []
+        buckets: Dict[int, Dict[str, float]] = {start: {} for start in starts}
+        with self._lock:
+            rows = self.conn.execute(
+                "SELECT start, field, value FROM series "
+                "WHERE bucket_seconds = ? AND scope = ? AND start BETWEEN ? AND ?",
+                (bucket_seconds, scope, min(starts), max(starts))
+            )
+            for start, field, value in rows:
+                if start in buckets:
+                    buckets[start][field] = value
+            for (bucket, key_scope, start, field), value in self._series.items():
+                if bucket == bucket_seconds and key_scope == scope and start in buckets:
+                    buckets[start][field] = buckets[start].get(field, 0) + value
+        return [buckets[start] for start in starts]
+
+    # ============== Compaction ==============
+
+    def compact(self, retention: Iterable[Tuple[int, int]], now: Optional[float] = None) -> int:
+        """
+        Drop series buckets past retention and reclaim the space.
+
+        Args:
+            retention: (bucket_seconds, retention_seconds) per tier; buckets
+                of tiers no longer configured are dropped too
+        """
+        now = time.time() if now is None else now
+        retention = list(retention)
+        removed = 0
+        with self.conn:
+            for bucket_seconds, retention_seconds in retention:
+                removed += self.conn.execute(
+                    "DELETE FROM series WHERE bucket_seconds = ? AND start < ?",
+                    (bucket_seconds, int(now - retention_seconds - bucket_seconds))
+                ).rowcount
+            tiers = [bucket for bucket, _ in retention]
+            if tiers:
+                removed += self.conn.execute(
+                    f"DELETE FROM series WHERE bucket_seconds NOT IN ({', '.join('?' * len(tiers))})", tiers
+                ).rowcount
+        if removed:
+            self.conn.execute("PRAGMA wal_checkpoint(TRUNCATE)")
+            self.conn.execute("VACUUM")
+            logger.info(f"Compacted metrics: removed {removed} expired series rows")
+        return removed
+
+    def _tick(self):
+        from src.services.admin.timeseries import configured_tiers
+
+        self.flush()
+        if time.monotonic() - self._last_compact >= self.compact_interval:
+            self._last_compact = time.monotonic()
+            try:
+                self.compact((t.bucket_seconds, t.retention_seconds) for t in configured_tiers())
+            except Exception as e:
+                logger.error(f"Metrics compaction failed: {e}")
+
+    def close(self):
+        """Stop the flush task and write what is buffered."""
+        if self._task is not None:
+            self._task.stop()
+        self.flush()
+
+
+# Singleton instance
+_metrics_store: Optional[SqliteMetricsStore] = None
+
+def get_metrics_store() -> Optional[SqliteMetricsStore]:
+    """The SQLite metrics store, or None when metrics persist to Redis."""
+    global _metrics_store
+    if persistence_backend() != SQLITE:
+        return None
+    if _metrics_store is None:
+        _metrics_store = SqliteMetricsStore()
+    return _metrics_store
@@ -0,0 +1,66 @@
+"""
+Rate Limiting.
+
+Fixed-window request counters in Redis, shared by every API process.
+Used for expensive endpoints (e.g. vector export) rather than globally.
+"""
+
+import logging
+import time
+from typing import Optional, Tuple
+
+import redis
+
+from src.core.config import settings
+
+logger = logging.getLogger(__name__)
+
+
+class RateLimiter:
+    """Counts hits per key and window."""
+
+    PREFIX = "rice:ratelimit"
+
+    def __init__(self, redis_client=None):
+        self._redis = redis_client
+
+    @property
+    def redis(self) -> redis.Redis:
+        """Lazy Redis connection."""
+        if self._redis is None:
+            self._redis = redis.from_url(settings.REDIS_URL, decode_responses=True)
+        return self._redis
+
+    def hit(self, key: str, limit: int, window_seconds: int) -> Tuple[bool, int]:
+        """
+        Record a request against ``key``.
+
+        Returns:
+            (allowed, seconds until the window resets). A limit of 0 or less
+            disables limiting; Redis errors allow the request.
+        """
+        if limit <= 0:
+            return True, 0
+        window = int(time.time() // window_seconds)
+        retry_after = int((window + 1) * window_seconds - time.time()) + 1
+        redis_key = f"{self.PREFIX}:{key}:{window}"
+        try:
+            pipe = self.redis.pipeline()
+            pipe.incr(redis_key)
+            pipe.expire(redis_key, window_seconds)
+            count, _ = pipe.execute()
+        except Exception as e:
+            logger.warning(f"Rate limit check for {key} failed, allowing: {e}")
+            return True, 0
+        return count <= limit, retry_after
+
+
+# Singleton instance
+_rate_limiter: Optional[RateLimiter] = None
+
+def get_rate_limiter() -> RateLimiter:
+    """Get global rate limiter instance."""
+    global _rate_limiter
+    if _rate_limiter is None:
+        _rate_limiter = RateLimiter()
+    return _rate_limiter
@@ -0,0 +1,194 @@
+"""
+Search Result Exports.
+
+Search results can be downloaded as CSV or JSON (``POST /api/v1/search/export``).
+Every export gets a request id, is written to the audit trail and kept as an
+export record (user, store, query, format, result count) for
+``exports.watermark.retention_days``.
+
+With ``exports.watermark.enabled`` the download also carries a watermark: the
+request id, user and time in the file header, and a signed token
+(``<request_id>.<signature>``) on every row, so even an excerpt of a leaked
+dump can be traced. ``GET /api/v1/admin/public/exports/{token}`` resolves a
+token (or bare request id) to its export record and checks the signature
+(HMAC-SHA256 with ``exports.watermark.secret``, default the JWT secret).
+"""
+
+import csv
+import hashlib
+import hmac
+import io
+import json
+import logging
+import uuid
+from datetime import datetime, timezone
+from typing import Any, Dict, List, Optional
+
+import redis
+
+from src.core.config import settings
+
+logger = logging.getLogger(__name__)
+
+FORMATS = ("csv", "json")
+
+# Result fields written to CSV (JSON keeps whole results)
+CSV_COLUMNS = ["rank", "score", "full_path", "start_line", "end_line", "language", "chunk_id", "text"]
+
+
+def watermark_enabled() -> bool:
+    return bool(settings.get("exports.watermark.enabled", False))
+
+
+def _secret() -> bytes:
+    secret = settings.get("exports.watermark.secret") or settings.get("auth.jwt_secret", "")
+    return str(secret).encode()
+
+
+def sign(request_id: str, user: str, issued_at: str) -> str:
+    """Short HMAC over the watermark fields."""
+    message = f"{request_id}|{user}|{issued_at}".encode()
+    return hmac.new(_secret(), message, hashlib.sha256).hexdigest()[:16]
+
+
+def token(watermark: Dict[str, Any]) -> str:
+    """Per-row form of a watermark."""
+    return f"{watermark['request_id']}.{watermark['signature']}"
+
+
+def render_json(export: Dict[str, Any], results: List[Dict[str, Any]], watermark: Optional[Dict[str, Any]]) -> str:
+    document = {
+        "request_id": export["request_id"],
+        "exported_at": export["issued_at"],
+        "store": export["store"],
+        "query": export["query"],
+        "results": [{**r, "watermark": token(watermark)} if watermark else r for r in results],
+    }
+    if watermark:
+        document["watermark"] = watermark
+    return json.dumps(document, default=str, indent=2)
+
+
+def render_csv(export: Dict[str, Any], results: List[Dict[str, Any]], watermark: Optional[Dict[str, Any]]) -> str:
+    out = io.StringIO()
+    if watermark:
+        out.write(
+            f"# Exported by {watermark['user']} at {watermark['issued_at']} "
+            f"(request {watermark['request_id']}, signature {watermark['signature']})\n"
+        )
+    writer = csv.writer(out)
+    writer.writerow(CSV_COLUMNS + (["watermark"] if watermark else []))
+    for rank, result in enumerate(results, start=1):
+        row = [rank] + [result.get(column, "") for column in CSV_COLUMNS[1:]]
+        writer.writerow(row + ([token(watermark)] if watermark else []))
+    return out.getvalue()
+
+
+class ResultExporter:
+    """Builds export files and keeps the record of each export."""
+
+    PREFIX = "rice:exports"
+
+    def __init__(self, redis_client=None):
+        self._redis = redis_client
+
+    @property
+    def redis(self) -> redis.Redis:
+        """Lazy Redis connection."""
+        if self._redis is None:
+            self._redis = redis.from_url(settings.REDIS_URL, decode_responses=True)
+        return self._redis
+
+    def export(
+        self,
+        results: List[Dict[str, Any]],
+        fmt: str,
+        user: str,
+        store: str,
+        query: str,
+    ) -> Dict[str, Any]:
+        """
+        Render results and record the export.
+
+        Returns:
+            Dict with ``request_id``, ``content``, ``media_type``, ``filename``
+            and ``watermark`` (None when watermarking is off)
+        """
+        if fmt not in FORMATS:
+            raise ValueError(f"Unknown export format '{fmt}'; expected one of {list(FORMATS)}")
+        request_id = uuid.uuid4().hex
+        issued_at = datetime.now(timezone.utc).isoformat()
+        export = {
+            "request_id": request_id,
+            "user": user,
+            "store": store,
+            "query": query,
+            "format": fmt,
+            "results": len(results),
+            "issued_at": issued_at,
+        }
+        watermark = None
+        if watermark_enabled():
+            watermark = {
+                "request_id": request_id,
+                "user": user,
+                "issued_at": issued_at,
+                "signature": sign(request_id, user, issued_at),
+            }
+        export["watermarked"] = watermark is not None
+
+        self._record(export)
+        from src.services.admin.admin_store import get_admin_store
+        get_admin_store().log_audit(
+            "search_results_exported",
+            f"Search export {request_id} of {len(results)} results from store {store} "
+            f"({fmt}{', watermarked' if watermark else ''}) for query {query!r}",
+            user,
+        )
+
+        render = render_csv if fmt == "csv" else render_json
+        return {
+            "request_id": request_id,
+            "content": render(export, results, watermark),
+            "media_type": "text/csv" if fmt == "csv" else "application/json",
+            "filename": f"search-{store}-{request_id[:8]}.{fmt}",
+            "watermark": watermark,
+        }
+
+    def _record(self, export: Dict[str, Any]):
+        ttl = int(float(settings.get("exports.watermark.retention_days", 365)) * 86400)
+        try:
+            self.redis.set(f"{self.PREFIX}:{export['request_id']}", json.dumps(export), ex=ttl)
+        except Exception as e:
+            # The audit entry still records the export
+            logger.warning(f"Failed to store export record {export['request_id']}: {e}")
+
+    def lookup(self, value: str) -> Optional[Dict[str, Any]]:
+        """
+        Export record for a row token or request id.
+
+        Returns:
+            The record plus ``signature_valid`` (None when no signature was
+            given), or None if unknown or expired
+        """
+        request_id, _, signature = value.strip().partition(".")
+        data = self.redis.get(f"{self.PREFIX}:{request_id}")
+        if not data:
+            return None
+        export = json.loads(data)
+        valid = None
+        if signature:
+            expected = sign(export["request_id"], export["user"], export["issued_at"])
+            valid = hmac.compare_digest(signature, expected)
+        return {**export, "signature_valid": valid}
+
+
+# Singleton instance
+_result_exporter: Optional[ResultExporter] = None
+
+def get_result_exporter() -> ResultExporter:
+    """Get global result exporter instance."""
+    global _result_exporter
+    if _result_exporter is None:
+        _result_exporter = ResultExporter()
+    return _result_exporter
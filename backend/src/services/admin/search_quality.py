@@ -0,0 +1,214 @@
+"""
+Search Quality Telemetry.
+
+Tracks the two signals that point at index coverage gaps and slow paths,
+per store and across all stores:
+
+- Zero-result queries: how often each (normalized) query found nothing
+  and when it was last seen. Only the ``metrics.quality.max_queries`` most
+  frequent are kept.
+- Slow queries: the last ``metrics.quality.sample_size`` searches with
+  their latency, from which p50/p95/p99 are computed and the queries at or
+  above p95 are listed.
+
+    rice:search_quality:<scope>:counts     searches, zero_results
+    rice:search_quality:<scope>:zero       zset query -> zero-result count
+    rice:search_quality:<scope>:zero_seen  hash query -> last seen (ISO)
+    rice:search_quality:<scope>:samples    list of recent {query, latency_ms, results, at}
+
+``<scope>`` is ``_all`` or a store id.
+"""
+
+import json
+import logging
+from datetime import datetime
+from typing import Any, Dict, List, Optional
+
+import redis
+
+from src.core.config import settings
+
+logger = logging.getLogger(__name__)
+
+ALL_STORES = "_all"
+MAX_QUERY_LENGTH = 200
+
+
+def normalize_query(query: str) -> str:
+    """Lowercased, whitespace-collapsed query so trivial variants count together."""
+    return " ".join(str(query or "").lower().split())[:MAX_QUERY_LENGTH]
+
+
+def percentiles(latencies: List[float]) -> Dict[str, float]:
+    """p50/p95/p99 of ``latencies`` (zeros when empty)."""
+    values = sorted(latencies)
+    n = len(values)
+    if not n:
+        return {"p50": 0, "p95": 0, "p99": 0}
+    return {
+        "p50": values[min(int(n * 0.5), n - 1)],
+        "p95": values[min(int(n * 0.95), n - 1)],
+        "p99": values[min(int(n * 0.99), n - 1)],
+    }
+
+
+def slow_queries(samples: List[Dict[str, Any]], p95: float, p99: float, limit: int) -> List[Dict[str, Any]]:
+    """Queries at or above p95, slowest first, one entry per query."""
+    slowest: Dict[str, Dict[str, Any]] = {}
+    for sample in samples:
+        latency = float(sample.get("latency_ms", 0))
+        if not p95 or latency < p95:
+            continue
+        entry = slowest.setdefault(sample["query"], {**sample, "count": 0})
+        entry["count"] += 1
+        if latency > entry["latency_ms"]:
+            entry.update(sample)
+    ranked = sorted(slowest.values(), key=lambda s: -s["latency_ms"])[:limit]
+    for entry in ranked:
+        entry["percentile"] = "p99" if entry["latency_ms"] >= p99 else "p95"
+    return ranked
+
+
+class SearchQuality:
+    """Zero-result and slow-query tracking in Redis."""
+
+    KEY_PREFIX = "rice:search_quality"
+
+    def __init__(self, redis_client=None):
+        self._redis = redis_client
+
+    @property
+    def redis(self) -> redis.Redis:
+        """Lazy Redis connection."""
+        if self._redis is None:
+            self._redis = redis.from_url(settings.REDIS_URL, decode_responses=True)
+        return self._redis
+
+    @property
+    def enabled(self) -> bool:
+        return bool(settings.get("metrics.quality.enabled", True))
+
+    def _key(self, scope: str, name: str) -> str:
+        return f"{self.KEY_PREFIX}:{scope}:{name}"
+
+    def record(self, store_id: Optional[str], query: str, results: int, latency_ms: float):
+        """Count a finished search."""
+        query = normalize_query(query)
+        if not self.enabled or not query:
+            return
+        sample_size = int(settings.get("metrics.quality.sample_size", 1000))
+        sample = json.dumps({
+            "query": query,
+            "store": store_id,
+            "latency_ms": float(latency_ms),
+            "results": int(results),
+            "at": datetime.now().isoformat(),
+        })
+        scopes = [ALL_STORES] + ([store_id] if store_id else [])
+        try:
+            pipe = self.redis.pipeline()
+            if store_id:
+                pipe.sadd(f"{self.KEY_PREFIX}:stores", store_id)
+            for scope in scopes:
+                pipe.hincrby(self._key(scope, "counts"), "searches", 1)
+                pipe.lpush(self._key(scope, "samples"), sample)
+                pipe.ltrim(self._key(scope, "samples"), 0, sample_size - 1)
+                if results == 0:
+                    pipe.hincrby(self._key(scope, "counts"), "zero_results", 1)
+                    pipe.zincrby(self._key(scope, "zero"), 1, query)
+                    pipe.hset(self._key(scope, "zero_seen"), query, datetime.now().isoformat())
+            pipe.execute()
+            if results == 0:
+                for scope in scopes:
+                    self._trim_zero(scope)
+        except Exception as e:
+            logger.debug(f"Failed to record search quality: {e}")
+
+    def _trim_zero(self, scope: str):
+        """Keep the ``max_queries`` most frequent zero-result queries."""
+        max_queries = int(settings.get("metrics.quality.max_queries", 500))
+        excess = self.redis.zcard(self._key(scope, "zero")) - max_queries
+        if excess <= 0:
+            return
+        dropped = self.redis.zrange(self._key(scope, "zero"), 0, excess - 1)
+        if dropped:
+            pipe = self.redis.pipeline()
+            pipe.zrem(self._key(scope, "zero"), *dropped)
+            pipe.hdel(self._key(scope, "zero_seen"), *dropped)
+            pipe.execute()
+
+    def _samples(self, scope: str) -> List[Dict[str, Any]]:
+        samples = []
+        for raw in self.redis.lrange(self._key(scope, "samples"), 0, -1):
+            try:
+                samples.append(json.loads(raw))
+            except (TypeError, ValueError):
+                continue
+        return samples
+
+    def _summary(self, scope: str, samples: List[Dict[str, Any]]) -> Dict[str, Any]:
+        counts = self.redis.hgetall(self._key(scope, "counts")) or {}
+        searches = int(counts.get("searches", 0))
+        zero_results = int(counts.get("zero_results", 0))
+        return {
+            "searches": searches,
+            "zero_results": zero_results,
+            "zero_result_rate": round(zero_results / searches, 4) if searches else 0.0,
+            "latency_ms": {
+                **percentiles([float(s.get("latency_ms", 0)) for s in samples]),
+                "samples": len(samples),
+            },
+        }
+
+    def report(self, store_id: Optional[str] = None, limit: int = 10) -> Dict[str, Any]:
+        """
+        Zero-result rate, latency percentiles, the top zero-result queries
+        and the slowest recent queries for a store (or all stores, with a
+        per-store breakdown).
+        """
+        scope = store_id or ALL_STORES
+        try:
+            samples = self._samples(scope)
+            summary = self._summary(scope, samples)
+            latency = summary["latency_ms"]
+
+            top = self.redis.zrevrange(self._key(scope, "zero"), 0, limit - 1, withscores=True)
+            seen = self.redis.hmget(self._key(scope, "zero_seen"), [q for q, _ in top]) if top else []
+            report = {
+                "store": store_id,
+                **summary,
+                "top_zero_result_queries": [
+                    {"query": query, "count": int(count), "last_seen": last_seen}
+                    for (query, count), last_seen in zip(top, seen)
+                ],
+                "slow_queries": slow_queries(samples, latency["p95"], latency["p99"], limit),
+            }
+            if store_id is None:
+                report["stores"] = []
+                for store in sorted(self.redis.smembers(f"{self.KEY_PREFIX}:stores") or []):
+                    per_store = self._summary(store, self._samples(store))
+                    report["stores"].append({"store": store, **per_store})
+                report["stores"].sort(key=lambda s: -s["zero_results"])
+            return report
+        except Exception as e:
+            logger.error(f"Failed to read search quality: {e}")
+            return {
+                "store": store_id,
+                "searches": 0,
+                "zero_results": 0,
+                "zero_result_rate": 0.0,
+                "latency_ms": {**percentiles([]), "samples": 0},
+                "top_zero_result_queries": [],
+                "slow_queries": [],
+            }
+
+
+# Singleton instance
+_search_quality: Optional[SearchQuality] = None
+
+def get_search_quality() -> SearchQuality:
+    """Get global search quality tracker instance."""
+    global _search_quality
+    if _search_quality is None:
+        _search_quality = SearchQuality()
+    return _search_quality
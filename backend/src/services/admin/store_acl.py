@@ -0,0 +1,150 @@
+"""
+Store Access Control Lists.
+
+A store may carry an ACL that limits who can use it, e.g. to keep an
+``hr-payroll`` store private on a shared team server:
+
+    {"owner": "key:alice",
+     "readers": ["key:bob"],
+     "writers": ["connection:conn_1a2b3c"]}
+
+Principals are API keys (``key:<user id>``, the caller's token subject or
+X-User-ID) and CLI connections (``connection:<id>``, counted only when the
+request proves the connection with its X-Connection-Token). Readers search
+the store and read its files and details; writers also index into it and
+delete files; the owner also edits the ACL and deletes the store. Admins
+are not restricted.
+
+Stores without an ACL stay open to everyone who could use them before.
+Only admins and members of the store's organization (``org_id``) may put
+the first ACL on an open store; otherwise anyone could claim another
+organization's store and lock it out.
+"""
+
+import logging
+from typing import Any, Dict, Iterable, List, Optional, Set
+
+logger = logging.getLogger(__name__)
+
+READ = "read"
+WRITE = "write"
+OWNER = "owner"
+
+KEY_PREFIX = "key:"
+CONNECTION_PREFIX = "connection:"
+
+
+class InvalidACL(ValueError):
+    """An ACL entry is not a ``key:`` or ``connection:`` principal."""
+
+
+def _principal(entry: Any) -> str:
+    value = str(entry or "").strip()
+    for prefix in (KEY_PREFIX, CONNECTION_PREFIX):
+        if value.startswith(prefix) and len(value) > len(prefix):
+            return value
+    raise InvalidACL(f"Invalid principal '{entry}'; expected key:<user id> or connection:<connection id>")
+
+
+def normalize_acl(owner: str, readers: Iterable[str] = (), writers: Iterable[str] = ()) -> Dict[str, Any]:
+    """
+    A validated ACL; duplicates are dropped and writers are not repeated as readers.
+
+    Raises:
+        InvalidACL: an entry is not a principal
+    """
+    owner = _principal(owner)
+    writer_list: List[str] = []
+    for entry in writers:
+        principal = _principal(entry)
+        if principal != owner and principal not in writer_list:
+            writer_list.append(principal)
+    reader_list: List[str] = []
+    for entry in readers:
+        principal = _principal(entry)
+        if principal != owner and principal not in writer_list and principal not in reader_list:
+            reader_list.append(principal)
+    return {"owner": owner, "readers": reader_list, "writers": writer_list}
+
+
+def user_principal(user: dict) -> Optional[str]:
+    """The caller's ``key:`` principal (token subject or admin user id)."""
+    user_id = user.get("sub") or user.get("id")
+    return f"{KEY_PREFIX}{user_id}" if user_id else None
+
+
+def principals(user: dict, connection_id: Optional[str] = None) -> Set[str]:
+    """
+    Principals a request acts as.
+
+    ``connection_id`` must already be verified against its token.
+    """
+    found = set()
+    key = user_principal(user)
+    if key:
+        found.add(key)
+    if connection_id:
+        found.add(f"{CONNECTION_PREFIX}{connection_id}")
+    return found
+
+
+def is_admin(user: dict) -> bool:
+    """Admin in either auth scheme (token realm roles or admin users)."""
+    return user.get("role") == "admin" or "admin" in user.get("realm_access", {}).get("roles", [])
+
+
+def grants(acl: Optional[Dict[str, Any]], caller: Set[str], access: str) -> bool:
+    """Whether ``acl`` lets any of ``caller`` have ``access`` (no ACL allows all)."""
+    if not acl:
+        return True
+    if acl.get("owner") in caller:
+        return True
+    if access == OWNER:
+        return False
+    if caller & set(acl.get("writers") or []):
+        return True
+    return access == READ and bool(caller & set(acl.get("readers") or []))
+
+
+def store_acl(store_id: str, stores: Optional[Dict[str, dict]] = None) -> Optional[Dict[str, Any]]:
+    """A store's ACL, or None when it has none (or doesn't exist)."""
+    if stores is None:
+        from src.services.admin.admin_store import get_admin_store
+        stores = get_admin_store().get_stores()
+    return (stores.get(store_id) or {}).get("acl") or None
+
+
+def can_access(
+    user: dict,
+    store_id: str,
+    access: str,
+    connection_id: Optional[str] = None,
+    stores: Optional[Dict[str, dict]] = None,
+) -> bool:
+    """Whether the caller may use ``store_id`` for ``access`` (read, write, owner)."""
+    if is_admin(user):
+        return True
+    try:
+        acl = store_acl(store_id, stores)
+    except Exception as e:
+        # Fail closed: an unreadable admin store must not open private stores
+        logger.error(f"Could not read ACL of store {store_id}: {e}")
+        return False
+    return grants(acl, principals(user, connection_id), access)
+
+
+def can_claim(user: dict, store_id: str) -> bool:
+    """Whether the caller may restrict an open store (admin or the store's org)."""
+    return is_admin(user) or user.get("org_id") == store_id
+
+
+def describe(acl: Optional[Dict[str, Any]], caller: Set[str], admin: bool) -> Dict[str, Any]:
+    """ACL plus what the caller may do with the store."""
+    return {
+        "acl": acl,
+        "restricted": bool(acl),
+        "access": {
+            access: admin or grants(acl, caller, access)
+            for access in (READ, WRITE, OWNER)
+        },
+    }
@@ -0,0 +1,88 @@
+"""
+Live Store Metrics.
+
+Builds per-store live metrics from the event bus: searches
+(``search.query``) and indexing events (``index.*``) for one store are
+folded into a rolling window that the store detail page streams over SSE.
+"""
+import time
+from collections import deque
+from datetime import datetime
+from typing import Any, Deque, Dict, List, Optional
+
+# Bus topics that feed store metrics
+METRIC_TOPICS = ["search.query", "index"]
+
+
+class StoreMetricsWindow:
+    """Rolling search rate and recent index events for a single store."""
+
+    def __init__(self, store_id: str, window_seconds: int = 60, recent: int = 20):
+        self.store_id = store_id
+        self.window_seconds = window_seconds
+        self._searches: Deque[float] = deque()
+        self._latencies: Deque[tuple] = deque()
+        self.recent_index: Deque[Dict[str, Any]] = deque(maxlen=recent)
+        self.index_counts: Dict[str, int] = {}
+
+    def matches(self, event: Dict[str, Any]) -> bool:
+        return (event.get("payload") or {}).get("org_id") == self.store_id
+
+    @staticmethod
+    def _timestamp(event: Dict[str, Any]) -> float:
+        try:
+            return datetime.fromisoformat(event["timestamp"]).timestamp()
+        except (KeyError, TypeError, ValueError):
+            return time.time()
+
+    def add(self, event: Dict[str, Any]) -> bool:
+        """Fold in a bus event; False if it is not about this store or replayed."""
+        # Replays republish past events; they are not live activity
+        if event.get("replayed") or not self.matches(event):
+            return False
+        topic = event.get("topic", "")
+        at = self._timestamp(event)
+        if topic == "search.query":
+            self._searches.append(at)
+            latency = (event.get("payload") or {}).get("latency_ms")
+            if latency is not None:
+                self._latencies.append((at, float(latency)))
+        elif topic.startswith("index."):
+            self.recent_index.appendleft({
+                "id": event.get("id"),
+                "topic": topic,
+                "timestamp": event.get("timestamp"),
+                "path": event["payload"].get("path"),
+                "chunks": event["payload"].get("chunks_indexed", event["payload"].get("chunks_removed")),
+            })
+            status = topic.rsplit(".", 1)[-1]
+            self.index_counts[status] = self.index_counts.get(status, 0) + 1
+        return True
+
+    def _trim(self, now: float):
+        cutoff = now - self.window_seconds
+        while self._searches and self._searches[0] < cutoff:
+            self._searches.popleft()
+        while self._latencies and self._latencies[0][0] < cutoff:
+            self._latencies.popleft()
+
+    def snapshot(self, now: Optional[float] = None, total_searches: Optional[int] = None) -> Dict[str, Any]:
+        now = time.time() if now is None else now
+        self._trim(now)
+        latencies = [l for _, l in self._latencies]
+        return {
+            "store_id": self.store_id,
+            "window_seconds": self.window_seconds,
+            "searches": len(self._searches),
+            "qps": round(len(self._searches) / self.window_seconds, 3),
+            "avg_latency_ms": round(sum(latencies) / len(latencies), 1) if latencies else None,
+            "total_searches": total_searches,
+            "index_counts": dict(self.index_counts),
+            "recent_index_events": list(self.recent_index),
+            "at": datetime.fromtimestamp(now).isoformat(),
+        }
+
+    def seed(self, events: List[Dict[str, Any]]):
+        """Fold in recent history (oldest first) so the first snapshot isn't empty."""
+        for event in events:
+            self.add(event)
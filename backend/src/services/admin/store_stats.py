@@ -0,0 +1,252 @@
+"""
+Store Stats.
+
+Per-store aggregates (files, chunks, bytes, and the same per language)
+kept up to date by the indexer instead of being recounted from the index
+on every stats page refresh. Each indexed file's contribution is recorded
+next to the totals, so replacing or deleting a file subtracts exactly what
+it added:
+
+    rice:store_stats:<store>        files, chunks, bytes, files:<lang>, ...
+    rice:store_stats:<store>:files  <path> -> {"language", "chunks", "bytes"}
+
+Stores indexed before the aggregates existed (or after Redis was lost)
+report ``complete: false`` until a rebuild scans their chunk payloads
+(``POST /api/v1/admin/public/stores/stats/rebuild``).
+"""
+
+import json
+import logging
+from datetime import datetime
+from typing import Any, Dict, Iterable, List, Optional
+
+import redis
+
+from src.core.config import settings
+from src.db.content_store import payload_text
+
+logger = logging.getLogger(__name__)
+
+UNKNOWN_LANGUAGE = "unknown"
+COUNTERS = ("files", "chunks", "bytes")
+
+PAYLOAD_FIELDS = ["full_path", "file_path", "language", "file_bytes", "text", "content_ref", "content_length"]
+
+
+def _language(language: Optional[str]) -> str:
+    return language or UNKNOWN_LANGUAGE
+
+
+def aggregate_payloads(payloads: Iterable[dict]) -> Dict[str, Dict[str, Any]]:
+    """
+    Per-file contributions from chunk payloads.
+
+    Files indexed before ``file_bytes`` was stored are sized by their chunk
+    text, which overcounts overlapping chunks slightly.
+
+    Returns:
+        Dict of path -> {"language", "chunks", "bytes"}
+    """
+    files: Dict[str, Dict[str, Any]] = {}
+    text_bytes: Dict[str, int] = {}
+    for payload in payloads:
+        payload = payload or {}
+        path = payload.get("full_path") or payload.get("file_path")
+        if not path:
+            continue
+        entry = files.setdefault(path, {"language": None, "chunks": 0, "bytes": None})
+        entry["chunks"] += 1
+        entry["language"] = entry["language"] or payload.get("language")
+        if payload.get("file_bytes") is not None:
+            entry["bytes"] = int(payload["file_bytes"])
+        length = payload.get("content_length")
+        if length is None:
+            length = len(payload_text(payload).encode("utf-8"))
+        text_bytes[path] = text_bytes.get(path, 0) + int(length)
+    for path, entry in files.items():
+        if entry["bytes"] is None:
+            entry["bytes"] = text_bytes[path]
+        entry["language"] = _language(entry["language"])
+    return files
+
+
+def summarize(totals: Dict[str, str]) -> Dict[str, Any]:
+    """Turn the Redis totals hash into the stats response."""
+    languages: Dict[str, Dict[str, int]] = {}
+    for field, value in totals.items():
+        counter, _, language = field.partition(":")
+        if language and counter in COUNTERS:
+            languages.setdefault(language, {c: 0 for c in COUNTERS})[counter] = int(value)
+    breakdown = [
+        {"language": language, **counts}
+        for language, counts in languages.items()
+        if counts["files"] > 0
+    ]
+    breakdown.sort(key=lambda l: (-l["files"], l["language"]))
+    return {
+        **{counter: max(0, int(totals.get(counter, 0))) for counter in COUNTERS},
+        "languages": breakdown,
+        "complete": "built_at" in totals,
+        "built_at": totals.get("built_at"),
+    }
+
+
+class StoreStats:
+    """Incremental per-store file, chunk, byte and language counts in Redis."""
+
+    KEY_PREFIX = "rice:store_stats"
+
+    def __init__(self, redis_client=None, qdrant_client=None):
+        self._redis = redis_client
+        self._qdrant = qdrant_client
+
+    @property
+    def redis(self) -> redis.Redis:
+        """Lazy Redis connection."""
+        if self._redis is None:
+            self._redis = redis.from_url(settings.REDIS_URL, decode_responses=True)
+        return self._redis
+
+    @property
+    def qdrant(self):
+        """Lazy Qdrant client."""
+        if self._qdrant is None:
+            from src.db.qdrant import get_qdrant_client
+            self._qdrant = get_qdrant_client()
+        return self._qdrant
+
+    def _totals_key(self, store_id: str) -> str:
+        return f"{self.KEY_PREFIX}:{store_id}"
+
+    def _files_key(self, store_id: str) -> str:
+        return f"{self.KEY_PREFIX}:{store_id}:files"
+
+    def _apply(self, pipe, store_id: str, entry: Dict[str, Any], sign: int):
+        key = self._totals_key(store_id)
+        language = _language(entry.get("language"))
+        amounts = {"files": 1, "chunks": int(entry.get("chunks", 0)), "bytes": int(entry.get("bytes", 0))}
+        for counter, amount in amounts.items():
+            pipe.hincrby(key, counter, sign * amount)
+            pipe.hincrby(key, f"{counter}:{language}", sign * amount)
+
+    def _previous(self, store_id: str, path: str) -> Optional[Dict[str, Any]]:
+        data = self.redis.hget(self._files_key(store_id), path)
+        return json.loads(data) if data else None
+
+    def record_file(self, store_id: str, path: str, chunks: int, size: int, language: Optional[str] = None):
+        """Count an indexed file, replacing what an earlier copy contributed."""
+        entry = {"language": _language(language), "chunks": int(chunks), "bytes": int(size)}
+        try:
+            previous = self._previous(store_id, path)
+            pipe = self.redis.pipeline()
+            if previous:
+                self._apply(pipe, store_id, previous, -1)
+            self._apply(pipe, store_id, entry, 1)
+            pipe.hset(self._files_key(store_id), path, json.dumps(entry))
+            pipe.execute()
+        except Exception as e:
+            logger.warning(f"Failed to record stats for {path} in {store_id}: {e}")
+
+    def remove_file(self, store_id: str, path: str):
+        """Subtract a deleted file's contribution (no-op for unknown files)."""
+        try:
+            previous = self._previous(store_id, path)
+            if not previous:
+                return
+            pipe = self.redis.pipeline()
+            self._apply(pipe, store_id, previous, -1)
+            pipe.hdel(self._files_key(store_id), path)
+            pipe.execute()
+        except Exception as e:
+            logger.warning(f"Failed to remove stats for {path} in {store_id}: {e}")
+
+    def reset(self, store_id: str, files: Optional[Dict[str, Dict[str, Any]]] = None):
+        """
+        Replace a store's aggregates with ``files`` (path -> contribution)
+        and mark them complete.
+        """
+        files = files or {}
+        pipe = self.redis.pipeline()
+        pipe.delete(self._totals_key(store_id), self._files_key(store_id))
+        for path, entry in files.items():
+            self._apply(pipe, store_id, entry, 1)
+            pipe.hset(self._files_key(store_id), path, json.dumps(entry))
+        pipe.hset(self._totals_key(store_id), "built_at", datetime.now().isoformat())
+        pipe.execute()
+
+    def drop(self, store_id: str):
+        """Forget a purged store's aggregates."""
+        self.redis.delete(self._totals_key(store_id), self._files_key(store_id))
+
+    def mark_new(self, store_id: str):
+        """
+        Mark a freshly created store's (empty) aggregates complete.
+
+        Aggregates left behind by an earlier store of the same id are
+        kept as they are: only purging a store removes its chunks.
+        """
+        key = self._totals_key(store_id)
+        try:
+            if not self.redis.exists(key):
+                self.redis.hset(key, "built_at", datetime.now().isoformat())
+        except Exception as e:
+            logger.warning(f"Failed to start stats for {store_id}: {e}")
+
+    def get(self, store_id: str) -> Dict[str, Any]:
+        """Totals and language breakdown for a store (zeros if never counted)."""
+        try:
+            totals = self.redis.hgetall(self._totals_key(store_id)) or {}
+        except Exception as e:
+            logger.error(f"Failed to read stats for {store_id}: {e}")
+            totals = {}
+        return {"store": store_id, **summarize(totals)}
+
+    def scan(self, store_id: str, batch_size: int = 512) -> Dict[str, Dict[str, Any]]:
+        """Per-file contributions of a store, from its chunk payloads."""
+        from qdrant_client.models import Filter, FieldCondition, MatchValue
+        from src.services.ingestion.migration import store_collection
+        from src.services.search.tiering import store_cold_collection
+
+        store_filter = Filter(must=[FieldCondition(key="org_id", match=MatchValue(value=store_id))])
+        payloads: List[dict] = []
+        for name in (store_collection(store_id), store_cold_collection(store_id)):
+            offset = None
+            try:
+                while True:
+                    points, offset = self.qdrant.scroll(
+                        collection_name=name,
+                        scroll_filter=store_filter,
+                        limit=batch_size,
+                        offset=offset,
+                        with_payload=PAYLOAD_FIELDS,
+                        with_vectors=False
+                    )
+                    payloads.extend(point.payload or {} for point in points)
+                    if offset is None or not points:
+                        break
+            except Exception as e:
+                # The cold collection only exists once tiering has run
+                logger.debug(f"Stats scan of {name} for {store_id} failed: {e}")
+        return aggregate_payloads(payloads)
+
+    def rebuild(self, store_id: str) -> Dict[str, Any]:
+        """Recount a store from the index and replace its aggregates."""
+        from src.services.search.query_cache import invalidate_store
+
+        self.reset(store_id, self.scan(store_id))
+        # Store and stats ETags follow the index version
+        invalidate_store(store_id)
+        stats = self.get(store_id)
+        logger.info(f"Rebuilt stats for {store_id}: {stats['files']} files, {stats['chunks']} chunks")
+        return stats
+
+
+# Singleton instance
+_store_stats: Optional[StoreStats] = None
+
+def get_store_stats() -> StoreStats:
+    """Get global store stats instance."""
+    global _store_stats
+    if _store_stats is None:
+        _store_stats = StoreStats()
+    return _store_stats
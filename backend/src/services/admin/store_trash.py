@@ -0,0 +1,142 @@
+"""
+Store trash.
+
+Deleting a store moves its config to the trash instead of dropping it:
+the store disappears from listings, search and uploads, but its chunks
+stay in Qdrant for ``stores.trash.retention_days`` so an accidental delete
+can be undone. Purging (by hand, or by the maintenance task once the
+retention window has passed) removes the store's chunks, dedicated
+collections and derived state for good.
+"""
+
+import logging
+from datetime import datetime, timedelta
+from typing import Any, Dict, List, Optional
+
+from qdrant_client.models import FieldCondition, Filter, MatchValue
+
+from src.core.config import settings
+
+logger = logging.getLogger(__name__)
+
+
+def retention_days() -> int:
+    """Days a trashed store is kept before it may be purged automatically."""
+    return int(settings.get("stores.trash.retention_days", 30))
+
+
+def purge_after(deleted_at: Optional[datetime] = None) -> str:
+    """When a store deleted at ``deleted_at`` (default: now) becomes purgeable."""
+    return ((deleted_at or datetime.now()) + timedelta(days=retention_days())).isoformat()
+
+
+def expired(record: Dict[str, Any], now: Optional[datetime] = None) -> bool:
+    """Whether a trashed store is past its retention window."""
+    deadline = record.get("purge_after")
+    if not deadline:
+        return False
+    try:
+        return datetime.fromisoformat(deadline) <= (now or datetime.now())
+    except ValueError:
+        logger.warning(f"Trashed store has an invalid purge_after: {deadline}")
+        return False
+
+
+def trash_store(store_id: str, user: str = "system") -> Optional[Dict[str, Any]]:
+    """Soft-delete a store; None if it doesn't exist."""
+    from src.services.admin.admin_store import get_admin_store
+    from src.services.search.query_cache import invalidate_store
+
+    record = get_admin_store().trash_store(store_id, user, purge_after())
+    if record is not None:
+        # Cached results must not outlive the store
+        invalidate_store(store_id)
+    return record
+
+
+def _collections(store_id: str, record: Dict[str, Any]) -> List[str]:
+    """Dedicated collections holding only this store's vectors."""
+    from src.services.search.tiering import cold_collection_for
+
+    names = [record.get("collection"), (record.get("migration") or {}).get("collection")]
+    names = [n for n in dict.fromkeys(names) if n and n != settings.COLLECTION_PREFIX]
+    # Each dedicated collection has its own cold tier
+    return names + [cold_collection_for(n) for n in names]
+
+
+def purge_index(qdrant, store_id: str, record: Dict[str, Any]):
+    """Remove a store's chunks and everything derived from them."""
+    from src.services.admin.duplicates import get_duplicate_reports
+    from src.services.admin.store_stats import get_store_stats
+    from src.services.ingestion.chunk_redirects import get_chunk_redirects
+    from src.services.retrieval.bm25_index import get_bm25_index
+    from src.services.search.experiments import get_experiment_runner
+    from src.services.search.fusion_tuning import get_fusion_tuner
+    from src.services.search.query_cache import invalidate_store
+    from src.services.search.suggestions import get_query_suggester
+    from src.services.search.tiering import get_cold_collection_name
+
+    for name in _collections(store_id, record):
+        try:
+            qdrant.delete_collection(name)
+        except Exception as e:
+            logger.debug(f"No collection {name} to drop for {store_id}: {e}")
+
+    # Chunks in the shared hot and cold collections
+    store_filter = Filter(must=[FieldCondition(key="org_id", match=MatchValue(value=store_id))])
+    for name in (settings.COLLECTION_PREFIX, get_cold_collection_name()):
+        try:
+            qdrant.delete(collection_name=name, points_selector=store_filter)
+        except Exception as e:
+            logger.debug(f"Purge of {store_id} in {name} skipped: {e}")
+
+    for name, cleanup in (
+        ("experiment", lambda: get_experiment_runner().drop(store_id)),
+        ("bm25", lambda: get_bm25_index().clear(store_id)),
+        ("fusion weights", lambda: get_fusion_tuner().reset(store_id)),
+        ("stats", lambda: get_store_stats().drop(store_id)),
+        ("duplicate report", lambda: get_duplicate_reports().drop(store_id)),
+        ("chunk redirects", lambda: get_chunk_redirects().drop(store_id)),
+        ("suggestion vocabulary", lambda: get_query_suggester().drop(store_id)),
+    ):
+        try:
+            cleanup()
+        except Exception as e:
+            logger.warning(f"Failed to clear {name} of purged store {store_id}: {e}")
+    invalidate_store(store_id)
+
+
+def purge_store(store_id: str, user: str = "system") -> bool:
+    """
+    Permanently delete a trashed store and its index.
+
+    Returns:
+        False if the store isn't in the trash
+    """
+    from src.db.qdrant import get_qdrant_client
+    from src.services.admin.admin_store import get_admin_store
+
+    admin_store = get_admin_store()
+    record = admin_store.get_trashed_stores().get(store_id)
+    if record is None:
+        return False
+    purge_index(get_qdrant_client(), store_id, record)
+    admin_store.remove_trashed_store(store_id)
+    admin_store.log_audit("store_purged", f"Store {store_id} and its index purged", user)
+    return True
+
+
+def purge_expired(now: Optional[datetime] = None) -> List[str]:
+    """Purge every trashed store past its retention window; returns their ids."""
+    from src.services.admin.admin_store import get_admin_store
+
+    purged = []
+    for store_id, record in sorted(get_admin_store().get_trashed_stores().items()):
+        if not expired(record, now):
+            continue
+        try:
+            if purge_store(store_id):
+                purged.append(store_id)
+        except Exception as e:
+            logger.error(f"Failed to purge trashed store {store_id}: {e}")
+    return purged
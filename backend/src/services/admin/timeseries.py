@@ -0,0 +1,271 @@
+"""
+Metrics Time Series.
+
+Search and indexing activity bucketed over time, so dashboards and Grafana
+panels can ask for any window (``time_range=7d``) at any step
+(``granularity=1h``) instead of reading fixed in-memory buckets.
+
+Every event is added to one bucket per resolution tier. Tiers trade detail
+for retention (``metrics.timeseries.tiers``, default 1m kept 24h, 15m kept
+7d, 1h kept 30d), so coarse history is downsampled at write time and each
+tier expires on its own. A query reads a tier that still covers the range
+and whose buckets divide the requested granularity, then sums its buckets
+into granularity-sized steps.
+
+Buckets are Redis hashes, one per tier, scope (``_all`` or a store id) and
+bucket start, expiring after the tier's retention:
+
+    rice:metrics:ts:<bucket seconds>:<scope>:<bucket start>
+        searches, latency_ms_sum, index_<status>, chunks_indexed
+
+With ``metrics.persistence: sqlite`` the same buckets are rows of the
+metrics SQLite file instead (see ``metrics_store``).
+"""
+
+import logging
+import re
+import time
+from dataclasses import dataclass
+from datetime import datetime, timezone
+from typing import Any, Dict, List, Optional
+
+import redis
+
+from src.core.config import settings
+
+logger = logging.getLogger(__name__)
+
+ALL_STORES = "_all"
+
+DURATION_PATTERN = re.compile(r"^(\d+)([smhd])$")
+UNIT_SECONDS = {"s": 1, "m": 60, "h": 3600, "d": 86400}
+
+DEFAULT_TIERS = [
+    {"bucket": "1m", "retention": "24h"},
+    {"bucket": "15m", "retention": "7d"},
+    {"bucket": "1h", "retention": "30d"},
+]
+
+# Index outcomes counted per bucket (index.file.<status> events)
+INDEX_STATUSES = ("success", "unchanged", "skipped", "forbidden", "error")
+
+
+class InvalidRange(ValueError):
+    """A time_range/granularity that can't be parsed or answered."""
+
+
+def parse_duration(value: str) -> int:
+    """Seconds in a duration like ``30s``, ``5m``, ``24h`` or ``7d``."""
+    match = DURATION_PATTERN.match(str(value or "").strip())
+    if not match or int(match.group(1)) == 0:
+        raise InvalidRange(f"Invalid duration '{value}'; expected e.g. 5m, 1h, 7d")
+    return int(match.group(1)) * UNIT_SECONDS[match.group(2)]
+
+
+def format_duration(seconds: int) -> str:
+    """The shortest ``<n><unit>`` spelling of a duration."""
+    for unit in ("d", "h", "m"):
+        if seconds % UNIT_SECONDS[unit] == 0:
+            return f"{seconds // UNIT_SECONDS[unit]}{unit}"
+    return f"{seconds}s"
+
+
+@dataclass(frozen=True)
+class Tier:
+    bucket_seconds: int
+    retention_seconds: int
+
+
+def configured_tiers() -> List[Tier]:
+    """Resolution tiers, finest first."""
+    tiers = [
+        Tier(parse_duration(t["bucket"]), parse_duration(t["retention"]))
+        for t in (settings.get("metrics.timeseries.tiers") or DEFAULT_TIERS)
+    ]
+    return sorted(tiers, key=lambda t: t.bucket_seconds)
+
+
+def choose_tier(tiers: List[Tier], range_seconds: int, granularity_seconds: int) -> Tier:
+    """
+    The coarsest tier that covers ``range_seconds`` and whose buckets divide
+    ``granularity_seconds`` (fewest reads), else the finest covering tier.
+
+    Raises:
+        InvalidRange: no tier keeps data that long
+    """
+    covering = [t for t in tiers if t.retention_seconds >= range_seconds]
+    if not covering:
+        longest = format_duration(max(t.retention_seconds for t in tiers))
+        raise InvalidRange(f"time_range exceeds the longest retention ({longest})")
+    fitting = [t for t in covering if granularity_seconds % t.bucket_seconds == 0]
+    return max(fitting, key=lambda t: t.bucket_seconds) if fitting else covering[0]
+
+
+def _point(start: int, fields: Dict[str, float]) -> Dict[str, Any]:
+    searches = int(fields.get("searches", 0))
+    latency_sum = fields.get("latency_ms_sum", 0.0)
+    return {
+        "time": datetime.fromtimestamp(start, tz=timezone.utc).isoformat(),
+        "timestamp": start,
+        "searches": searches,
+        "avg_latency_ms": round(latency_sum / searches, 1) if searches else None,
+        "files_indexed": int(fields.get("index_success", 0)),
+        "index_errors": int(fields.get("index_error", 0)),
+        "index": {status: int(fields.get(f"index_{status}", 0)) for status in INDEX_STATUSES},
+        "chunks_indexed": int(fields.get("chunks_indexed", 0)),
+    }
+
+
+class MetricsTimeSeries:
+    """Redis-backed search and index activity per time bucket."""
+
+    KEY_PREFIX = "rice:metrics:ts"
+
+    def __init__(self, redis_client=None, metrics_store=None):
+        self._redis = redis_client
+        self._sqlite = metrics_store
+
+    @property
+    def redis(self) -> redis.Redis:
+        """Lazy Redis connection."""
+        if self._redis is None:
+            self._redis = redis.from_url(settings.REDIS_URL, decode_responses=True)
+        return self._redis
+
+    @property
+    def enabled(self) -> bool:
+        return bool(settings.get("metrics.timeseries.enabled", True))
+
+    def _metrics_store(self):
+        """The SQLite metrics store, or None when buckets live in Redis."""
+        if self._sqlite is not None:
+            return self._sqlite
+        from src.services.admin.metrics_store import get_metrics_store
+        return get_metrics_store()
+
+    def _key(self, tier: Tier, scope: str, start: int) -> str:
+        return f"{self.KEY_PREFIX}:{tier.bucket_seconds}:{scope}:{start}"
+
+    def _add(self, store_id: Optional[str], counts: Dict[str, float], now: Optional[float] = None):
+        if not self.enabled:
+            return
+        now = time.time() if now is None else now
+        scopes = [ALL_STORES] + ([store_id] if store_id else [])
+        metrics_store = self._metrics_store()
+        if metrics_store is not None:
+            for tier in configured_tiers():
+                start = int(now // tier.bucket_seconds) * tier.bucket_seconds
+                for scope in scopes:
+                    metrics_store.add_series(tier.bucket_seconds, scope, start, counts)
+            return
+        try:
+            pipe = self.redis.pipeline()
+            for tier in configured_tiers():
+                start = int(now // tier.bucket_seconds) * tier.bucket_seconds
+                for scope in scopes:
+                    key = self._key(tier, scope, start)
+                    for field, amount in counts.items():
+                        if isinstance(amount, float):
+                            pipe.hincrbyfloat(key, field, amount)
+                        else:
+                            pipe.hincrby(key, field, amount)
+                    pipe.expire(key, tier.retention_seconds + tier.bucket_seconds)
+            pipe.execute()
+        except Exception as e:
+            logger.debug(f"Failed to record metrics time series: {e}")
+
+    def _read(self, tier: Tier, scope: str, starts: List[int]) -> List[Dict[str, Any]]:
+        metrics_store = self._metrics_store()
+        if metrics_store is not None:
+            return metrics_store.read_series(tier.bucket_seconds, scope, starts)
+        pipe = self.redis.pipeline()
+        for start in starts:
+            pipe.hgetall(self._key(tier, scope, start))
+        return pipe.execute()
+
+    def record_search(self, store_id: Optional[str], latency_ms: float, now: Optional[float] = None):
+        """Count a search and its latency."""
+        self._add(store_id, {"searches": 1, "latency_ms_sum": float(latency_ms)}, now)
+
+    def record_index(self, store_id: Optional[str], status: str, chunks: int = 0, now: Optional[float] = None):
+        """Count an indexed file by outcome (success, unchanged, skipped, error, ...)."""
+        counts = {f"index_{status}": 1}
+        if chunks:
+            counts["chunks_indexed"] = int(chunks)
+        self._add(store_id, counts, now)
+
+    def query(
+        self,
+        time_range: str = "24h",
+        granularity: Optional[str] = None,
+        store_id: Optional[str] = None,
+        now: Optional[float] = None,
+    ) -> Dict[str, Any]:
+        """
+        Points covering the last ``time_range``, one per ``granularity``.
+
+        Without a granularity the chosen tier's bucket size is used. A
+        granularity finer than any tier covering the range is widened to
+        that tier's bucket; the response reports the effective value.
+
+        Raises:
+            InvalidRange: unparseable values, a range past retention or too many points
+        """
+        range_seconds = parse_duration(time_range)
+        tiers = configured_tiers()
+        requested = parse_duration(granularity) if granularity else None
+        tier = choose_tier(tiers, range_seconds, requested or 1)
+        step = requested or tier.bucket_seconds
+        if step % tier.bucket_seconds:
+            step = -(-step // tier.bucket_seconds) * tier.bucket_seconds
+
+        max_points = int(settings.get("metrics.timeseries.max_points", 2000))
+        if range_seconds / step > max_points:
+            raise InvalidRange(f"time_range/granularity gives more than {max_points} points")
+
+        # Steps run from the one holding ``now - range`` to the one holding ``now``
+        now = time.time() if now is None else now
+        first = int((now - range_seconds) // step) * step
+        end = (int(now // step) + 1) * step
+        scope = store_id or ALL_STORES
+
+        starts = list(range(first, end, tier.bucket_seconds))
+        try:
+            buckets = self._read(tier, scope, starts)
+        except Exception as e:
+            logger.error(f"Failed to read metrics time series: {e}")
+            buckets = [{} for _ in starts]
+
+        steps: Dict[int, Dict[str, float]] = {s: {} for s in range(first, end, step)}
+        for start, fields in zip(starts, buckets):
+            merged = steps[first + ((start - first) // step) * step]
+            for field, value in (fields or {}).items():
+                merged[field] = merged.get(field, 0) + float(value)
+
+        points = [_point(s, fields) for s, fields in steps.items()]
+        return {
+            "store": store_id,
+            "time_range": format_duration(range_seconds),
+            "granularity": format_duration(step),
+            "tier": format_duration(tier.bucket_seconds),
+            "from": points[0]["time"] if points else None,
+            "to": datetime.fromtimestamp(end, tz=timezone.utc).isoformat(),
+            "totals": {
+                "searches": sum(p["searches"] for p in points),
+                "files_indexed": sum(p["files_indexed"] for p in points),
+                "index_errors": sum(p["index_errors"] for p in points),
+                "chunks_indexed": sum(p["chunks_indexed"] for p in points),
+            },
+            "points": points,
+        }
+
+
+# Singleton instance
+_time_series: Optional[MetricsTimeSeries] = None
+
+def get_time_series() -> MetricsTimeSeries:
+    """Get global metrics time series instance."""
+    global _time_series
+    if _time_series is None:
+        _time_series = MetricsTimeSeries()
+    return _time_series
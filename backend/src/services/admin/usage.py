@@ -0,0 +1,169 @@
+"""
+Resource Usage Accounting.
+
+Monthly per-caller counters for internal chargeback of the shared search
+cluster. Endpoints call ``start_usage(key, store)`` for the caller (the
+token subject, or the X-User-ID user without tokens) and store; the
+search, embedding and ingestion paths call ``record_usage`` for what they
+consume. Counters live in Redis, one hash per month.
+
+Metrics:
+- requests: API calls made under the scope
+- embed_tokens: dense embedding input tokens (reported or estimated)
+- rerank_pairs: (query, document) pairs scored by the reranker
+- qdrant_reads: points read from Qdrant
+- storage_bytes: bytes uploaded for indexing
+"""
+
+import csv
+import io
+import logging
+import re
+from contextlib import contextmanager
+from contextvars import ContextVar
+from datetime import datetime, timezone
+from typing import Any, Dict, List, Optional, Tuple
+
+import redis
+
+from src.core.config import settings
+
+logger = logging.getLogger(__name__)
+
+METRICS = ["requests", "embed_tokens", "rerank_pairs", "qdrant_reads", "storage_bytes"]
+
+MONTH_PATTERN = re.compile(r"^\d{4}-(0[1-9]|1[0-2])$")
+
+# (key, store) of the request being served
+_scope: ContextVar[Optional[Tuple[str, str]]] = ContextVar("usage_scope", default=None)
+
+
+def current_month(at: Optional[datetime] = None) -> str:
+    """Billing month (UTC) as YYYY-MM."""
+    return (at or datetime.now(timezone.utc)).strftime("%Y-%m")
+
+
+def start_usage(key: Optional[str], store: Optional[str]):
+    """
+    Attribute the rest of the current request's usage to ``key`` and ``store``.
+
+    Each request runs in its own task (and context), so the scope ends with
+    the request; work started from it (gathered tasks, ``asyncio.to_thread``)
+    inherits it.
+    """
+    _scope.set((key or "anonymous", store or "public"))
+    record_usage("requests")
+
+
+@contextmanager
+def usage_scope(key: Optional[str], store: Optional[str]):
+    """Attribute usage recorded inside the block to ``key`` and ``store``."""
+    token = _scope.set(None)
+    try:
+        start_usage(key, store)
+        yield
+    finally:
+        _scope.reset(token)
+
+
+def record_usage(metric: str, amount: int = 1):
+    """Add to the current scope's counter (no-op outside a scope, e.g. in workers)."""
+    scope = _scope.get()
+    if scope is None or amount <= 0 or not settings.get("usage.enabled", True):
+        return
+    get_usage_meter().add(scope[0], scope[1], metric, amount)
+
+
+class UsageMeter:
+    """Monthly usage counters per (key, store, metric)."""
+
+    PREFIX = "rice:usage"
+
+    def __init__(self, redis_client=None):
+        self._redis = redis_client
+
+    @property
+    def redis(self) -> redis.Redis:
+        """Lazy Redis connection."""
+        if self._redis is None:
+            self._redis = redis.from_url(settings.REDIS_URL, decode_responses=True)
+        return self._redis
+
+    def _key(self, month: str) -> str:
+        return f"{self.PREFIX}:{month}"
+
+    def add(self, key: str, store: str, metric: str, amount: int, month: Optional[str] = None):
+        """Increment a counter; Redis errors are logged, never raised."""
+        if metric not in METRICS:
+            raise ValueError(f"Unknown usage metric: {metric}")
+        redis_key = self._key(month or current_month())
+        retention_days = int(settings.get("usage.retention_months", 13)) * 31
+        try:
+            pipe = self.redis.pipeline()
+            pipe.hincrby(redis_key, f"{key}|{store}|{metric}", int(amount))
+            pipe.expire(redis_key, retention_days * 86400)
+            pipe.execute()
+        except Exception as e:
+            logger.warning(f"Failed to record usage {metric} for {key}: {e}")
+
+    def report(self, month: Optional[str] = None, key: Optional[str] = None) -> Dict[str, Any]:
+        """
+        Usage for a month, one row per (key, store), plus per-key and overall totals.
+        """
+        month = month or current_month()
+        raw = self.redis.hgetall(self._key(month)) or {}
+
+        rows: Dict[Tuple[str, str], Dict[str, Any]] = {}
+        for field, value in raw.items():
+            try:
+                row_key, store, metric = field.rsplit("|", 2)
+            except ValueError:
+                continue
+            if key and row_key != key:
+                continue
+            row = rows.setdefault((row_key, store), {"key": row_key, "store": store, **{m: 0 for m in METRICS}})
+            if metric in METRICS:
+                row[metric] += int(value)
+
+        by_key: Dict[str, Dict[str, int]] = {}
+        totals = {m: 0 for m in METRICS}
+        for row in rows.values():
+            key_totals = by_key.setdefault(row["key"], {m: 0 for m in METRICS})
+            for m in METRICS:
+                key_totals[m] += row[m]
+                totals[m] += row[m]
+
+        return {
+            "month": month,
+            "metrics": METRICS,
+            "rows": [rows[k] for k in sorted(rows)],
+            "keys": by_key,
+            "totals": totals,
+        }
+
+    def months(self) -> List[str]:
+        """Months with recorded usage, newest first."""
+        prefix = f"{self.PREFIX}:"
+        found = [k[len(prefix):] for k in self.redis.scan_iter(match=f"{prefix}*")]
+        return sorted((m for m in found if MONTH_PATTERN.match(m)), reverse=True)
+
+
+def report_csv(report: Dict[str, Any]) -> str:
+    """A usage report as CSV (month, key, store, one column per metric)."""
+    out = io.StringIO()
+    writer = csv.writer(out)
+    writer.writerow(["month", "key", "store", *METRICS])
+    for row in report["rows"]:
+        writer.writerow([report["month"], row["key"], row["store"], *(row[m] for m in METRICS)])
+    return out.getvalue()
+
+
+# Singleton instance
+_usage_meter: Optional[UsageMeter] = None
+
+def get_usage_meter() -> UsageMeter:
+    """Get global usage meter instance."""
+    global _usage_meter
+    if _usage_meter is None:
+        _usage_meter = UsageMeter()
+    return _usage_meter
@@ -0,0 +1,182 @@
+"""
+Vector Export.
+
+Streams a store's chunks (id, payload and stored vectors) for offline
+analysis and training data creation. Points are scrolled from Qdrant in
+batches, so exports of any size run in constant memory.
+
+Formats:
+- ``ndjson``: one JSON object per line; sparse vectors as
+  ``{"indices": [...], "values": [...]}``
+- ``arrow``: Arrow IPC stream (requires ``pyarrow``); payload as a JSON
+  string column, dense vectors as float lists, sparse vectors as
+  ``<name>_indices`` / ``<name>_values`` list columns
+"""
+
+import io
+import json
+import logging
+from typing import Any, Dict, Iterator, List, Optional
+
+from qdrant_client.models import Filter, FieldCondition, MatchValue
+
+from src.core.config import settings
+
+logger = logging.getLogger(__name__)
+
+DENSE_VECTORS = ("dense",)
+SPARSE_VECTORS = ("splade", "bm42")
+VECTOR_NAMES = DENSE_VECTORS + SPARSE_VECTORS
+FORMATS = ("ndjson", "arrow")
+
+
+def serialize_vector(vector: Any) -> Any:
+    """JSON-friendly vector (dense list, or sparse indices/values)."""
+    if hasattr(vector, "indices"):
+        return {"indices": list(vector.indices), "values": list(vector.values)}
+    return list(vector) if vector is not None else None
+
+
+def arrow_available() -> bool:
+    try:
+        import pyarrow  # noqa: F401
+        return True
+    except ImportError:
+        return False
+
+
+class VectorExporter:
+    """Scrolls a store's points out of the hot (and cold) collections."""
+
+    def __init__(self, qdrant_client=None):
+        self._qdrant = qdrant_client
+
+    @property
+    def qdrant(self):
+        """Lazy Qdrant client."""
+        if self._qdrant is None:
+            from src.db.qdrant import get_qdrant_client
+            self._qdrant = get_qdrant_client()
+        return self._qdrant
+
+    @property
+    def batch_size(self) -> int:
+        return int(settings.get("exports.batch_size", 256))
+
+    def collections(self, org_id: Optional[str] = None) -> List[str]:
+        """The store's hot collection plus its cold tier when it exists."""
+        from src.services.search.tiering import tier_collections
+        return tier_collections(self.qdrant, org_id)
+
+    def iter_points(
+        self,
+        org_id: str,
+        vectors: List[str],
+        include_payload: bool = True,
+        limit: Optional[int] = None
+    ) -> Iterator[Dict[str, Any]]:
+        """
+        Yield ``{"id", "payload", "vectors"}`` for every chunk in a store.
+
+        Args:
+            org_id: Store
+            vectors: Vector names to include (empty: none)
+            include_payload: Include the chunk payload (text included)
+            limit: Stop after this many points
+        """
+        store_filter = Filter(must=[FieldCondition(key="org_id", match=MatchValue(value=org_id))])
+        exported = 0
+        for collection_name in self.collections(org_id):
+            offset = None
+            while True:
+                points, offset = self.qdrant.scroll(
+                    collection_name=collection_name,
+                    scroll_filter=store_filter,
+                    limit=self.batch_size,
+                    offset=offset,
+                    with_payload=include_payload,
+                    with_vectors=list(vectors) if vectors else False
+                )
+                for point in points:
+                    stored = point.vector if isinstance(point.vector, dict) else {}
+                    record = {"id": str(point.id)}
+                    if include_payload:
+                        record["payload"] = point.payload or {}
+                    record["vectors"] = {
+                        name: serialize_vector(stored.get(name)) for name in vectors
+                    }
+                    yield record
+                    exported += 1
+                    if limit is not None and exported >= limit:
+                        return
+                if offset is None or not points:
+                    break
+
+    def ndjson(self, *args: Any, **kwargs: Any) -> Iterator[bytes]:
+        """Points as NDJSON lines."""
+        for record in self.iter_points(*args, **kwargs):
+            yield (json.dumps(record, default=str) + "\n").encode()
+
+    def arrow(self, org_id: str, vectors: List[str], include_payload: bool = True, limit: Optional[int] = None) -> Iterator[bytes]:
+        """Points as an Arrow IPC stream, one record batch per scroll batch."""
+        import pyarrow as pa
+
+        fields = [pa.field("id", pa.string())]
+        if include_payload:
+            fields.append(pa.field("payload", pa.string()))
+        for name in vectors:
+            if name in SPARSE_VECTORS:
+                fields.append(pa.field(f"{name}_indices", pa.list_(pa.uint32())))
+                fields.append(pa.field(f"{name}_values", pa.list_(pa.float32())))
+            else:
+                fields.append(pa.field(name, pa.list_(pa.float32())))
+        schema = pa.schema(fields)
+
+        sink = io.BytesIO()
+        writer = pa.ipc.new_stream(sink, schema)
+
+        def flush(rows: List[Dict[str, Any]]) -> bytes:
+            columns: Dict[str, List[Any]] = {f.name: [] for f in fields}
+            for row in rows:
+                columns["id"].append(row["id"])
+                if include_payload:
+                    columns["payload"].append(json.dumps(row["payload"], default=str))
+                for name in vectors:
+                    vector = row["vectors"].get(name)
+                    if name in SPARSE_VECTORS:
+                        columns[f"{name}_indices"].append(vector["indices"] if vector else None)
+                        columns[f"{name}_values"].append(vector["values"] if vector else None)
+                    else:
+                        columns[name].append(vector)
+            writer.write_batch(pa.RecordBatch.from_pydict(columns, schema=schema))
+            return _drain(sink)
+
+        rows: List[Dict[str, Any]] = []
+        for record in self.iter_points(org_id, vectors, include_payload, limit):
+            rows.append(record)
+            if len(rows) >= self.batch_size:
+                yield flush(rows)
+                rows = []
+        if rows:
+            yield flush(rows)
+        writer.close()
+        yield _drain(sink)
+
+
+def _drain(sink: io.BytesIO) -> bytes:
+    """Bytes written to the buffer since the last drain."""
+    data = sink.getvalue()
+    sink.seek(0)
+    sink.truncate()
+    return data
+
+
+# Singleton instance
+_exporter: Optional[VectorExporter] = None
+
+def get_vector_exporter() -> VectorExporter:
+    """Get global vector exporter instance."""
+    global _exporter
+    if _exporter is None:
+        _exporter = VectorExporter()
+    return _exporter
@@ -0,0 +1,5 @@
+"""Event bus services package."""
+
+from src.services.events.bus import EventBus, emit, get_event_bus
+
+__all__ = ["EventBus", "emit", "get_event_bus"]
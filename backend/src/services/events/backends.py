@@ -0,0 +1,318 @@
+"""
+Event Bus Backends.
+
+Transports behind ``EventBus``, selected with ``events.backend``:
+
+- ``redis`` (default): Redis Streams for events, Redis lists for
+  request/reply work queues. Needs nothing beyond the Redis we already run.
+- ``nats``: NATS JetStream for events, core NATS queue groups for
+  request/reply (requires ``nats-py``). Suits deployments where ML workers
+  run on separate GPU machines that should not reach Redis.
+
+Both store events with ordered ids a reader can resume from, and both
+deliver each request to exactly one of the workers serving its topic.
+"""
+
+import asyncio
+import json
+import logging
+import threading
+import time
+import uuid
+from typing import Any, Callable, Dict, List, Optional, Tuple
+
+import redis
+
+from src.core.config import settings
+from src.core.supervisor import ORDER_TRANSPORT, get_supervisor
+
+logger = logging.getLogger(__name__)
+
+BACKENDS = ("redis", "nats")
+
+# (id, fields) as stored on the stream
+Entry = Tuple[str, Dict[str, str]]
+
+
+class BusBackend:
+    """Transport interface used by ``EventBus``."""
+
+    name = "base"
+
+    def add(self, topic: str, fields: Dict[str, str], max_len: int) -> str:
+        """Append an event; returns its id."""
+        raise NotImplementedError
+
+    def latest_id(self) -> str:
+        """Id of the newest event (a start position for ``read_after``)."""
+        raise NotImplementedError
+
+    def rev_range(self, max_id: str, count: int) -> List[Entry]:
+        """Up to ``count`` events at or before ``max_id`` ("+": newest), newest first."""
+        raise NotImplementedError
+
+    def read_after(self, last_id: str, count: int, block_ms: int) -> List[Entry]:
+        """Events after ``last_id``, waiting up to ``block_ms`` for the first one."""
+        raise NotImplementedError
+
+    def set_once(self, key: str, ttl_seconds: int) -> bool:
+        """Set ``key`` unless it exists; True if this call set it."""
+        raise NotImplementedError
+
+    def request(self, topic: str, data: str, timeout: float) -> str:
+        """Send a request to one worker serving ``topic``; returns its reply."""
+        raise NotImplementedError
+
+    def serve(self, topic: str, handler: Callable[[str], str], stop: threading.Event):
+        """Answer requests on ``topic`` until ``stop`` is set (blocks)."""
+        raise NotImplementedError
+
+
+class RedisStreamsBackend(BusBackend):
+    """Events on a capped Redis stream; requests on Redis lists."""
+
+    name = "redis"
+    STREAM_KEY = "rice:events"
+    RPC_PREFIX = "rice:rpc"
+
+    def __init__(self, redis_client=None):
+        self._redis = redis_client
+
+    @property
+    def redis(self) -> redis.Redis:
+        """Lazy Redis connection."""
+        if self._redis is None:
+            self._redis = redis.from_url(settings.REDIS_URL, decode_responses=True)
+        return self._redis
+
+    def add(self, topic: str, fields: Dict[str, str], max_len: int) -> str:
+        return self.redis.xadd(self.STREAM_KEY, fields, maxlen=max_len, approximate=True)
+
+    def latest_id(self) -> str:
+        latest = self.redis.xrevrange(self.STREAM_KEY, count=1)
+        return latest[0][0] if latest else "0-0"
+
+    def rev_range(self, max_id: str, count: int) -> List[Entry]:
+        return self.redis.xrevrange(self.STREAM_KEY, max=max_id, min="-", count=count)
+
+    def read_after(self, last_id: str, count: int, block_ms: int) -> List[Entry]:
+        response = self.redis.xread({self.STREAM_KEY: last_id}, count=count, block=block_ms)
+        return [entry for _stream, entries in response or [] for entry in entries]
+
+    def set_once(self, key: str, ttl_seconds: int) -> bool:
+        return bool(self.redis.set(key, "1", nx=True, ex=ttl_seconds))
+
+    def request(self, topic: str, data: str, timeout: float) -> str:
+        reply_key = f"{self.RPC_PREFIX}:reply:{uuid.uuid4().hex}"
+        envelope = {"reply_to": reply_key, "deadline": time.time() + timeout, "data": data}
+        self.redis.lpush(f"{self.RPC_PREFIX}:{topic}", json.dumps(envelope))
+        response = self.redis.blpop([reply_key], timeout=max(1, int(timeout)))
+        if response is None:
+            raise TimeoutError(f"No worker answered {topic} within {timeout}s")
+        return response[1]
+
+    def serve(self, topic: str, handler: Callable[[str], str], stop: threading.Event):
+        queue = f"{self.RPC_PREFIX}:{topic}"
+        while not stop.is_set():
+            try:
+                item = self.redis.brpop([queue], timeout=1)
+            except redis.RedisError as e:
+                logger.warning(f"Request queue {topic} unavailable: {e}")
+                stop.wait(5)
+                continue
+            if item is None:
+                continue
+            envelope = json.loads(item[1])
+            if envelope.get("deadline", 0) < time.time():
+                # The caller already gave up
+                continue
+            reply = handler(envelope["data"])
+            pipe = self.redis.pipeline()
+            pipe.lpush(envelope["reply_to"], reply)
+            pipe.expire(envelope["reply_to"], 60)
+            pipe.execute()
+
+
+class NatsBackend(BusBackend):
+    """
+    Events on a NATS JetStream stream; requests via core NATS queue groups.
+
+    nats-py is asyncio-only, so calls run on a private event loop thread and
+    the synchronous interface waits for them.
+    """
+
+    name = "nats"
+
+    def __init__(self, url: Optional[str] = None):
+        self.url = url or settings.get("events.nats.url", "nats://nats:4222")
+        self.stream = settings.get("events.nats.stream", "RICE_EVENTS")
+        self.subject_prefix = settings.get("events.nats.subject_prefix", "rice")
+        self._loop: Optional[asyncio.AbstractEventLoop] = None
+        self._nc = None
+        self._js = None
+        self._kv = None
+        self._stream_ready = False
+        self._lock = threading.Lock()
+
+    def _run(self, coro, timeout: Optional[float] = 30):
+        with self._lock:
+            if self._loop is None:
+                self._loop = asyncio.new_event_loop()
+                loop = self._loop
+                get_supervisor().spawn(
+                    "nats-bus", loop.run_forever, group="bus", order=ORDER_TRANSPORT,
+                    on_stop=lambda: loop.call_soon_threadsafe(loop.stop),
+                )
+        return asyncio.run_coroutine_threadsafe(coro, self._loop).result(timeout)
+
+    async def _connect(self):
+        if self._nc is None or self._nc.is_closed:
+            import nats
+
+            self._nc = await nats.connect(self.url, name="rice-search")
+            self._js = self._nc.jetstream()
+        return self._nc, self._js
+
+    def _event_subject(self, topic: str) -> str:
+        # Subjects are dot-separated tokens; keep topics usable as tokens
+        token = "".join(c if c.isalnum() or c in "._-" else "_" for c in topic) or "_"
+        return f"{self.subject_prefix}.events.{token}"
+
+    async def _ensure_stream(self, max_len: int):
+        if self._stream_ready:
+            return
+        from nats.js.api import StreamConfig
+
+        _, js = await self._connect()
+        config = StreamConfig(
+            name=self.stream,
+            subjects=[f"{self.subject_prefix}.events.>"],
+            max_msgs=max_len,
+        )
+        try:
+            await js.add_stream(config)
+        except Exception:
+            await js.update_stream(config)
+        self._stream_ready = True
+
+    @staticmethod
+    def _seq(event_id: str) -> int:
+        # Accept Redis-style ids ("0-0") from clients that switched backends
+        try:
+            return int(str(event_id).split("-", 1)[0])
+        except ValueError:
+            return 0
+
+    def add(self, topic: str, fields: Dict[str, str], max_len: int) -> str:
+        async def _add():
+            await self._ensure_stream(max_len)
+            ack = await self._js.publish(self._event_subject(topic), json.dumps(fields).encode())
+            return str(ack.seq)
+        return self._run(_add())
+
+    def latest_id(self) -> str:
+        async def _latest():
+            _, js = await self._connect()
+            try:
+                info = await js.stream_info(self.stream)
+            except Exception:
+                return "0"
+            return str(info.state.last_seq)
+        return self._run(_latest())
+
+    def rev_range(self, max_id: str, count: int) -> List[Entry]:
+        async def _range():
+            _, js = await self._connect()
+            try:
+                info = await js.stream_info(self.stream)
+            except Exception:
+                return []
+            seq = info.state.last_seq if max_id == "+" else min(self._seq(max_id), info.state.last_seq)
+            entries = []
+            while seq >= max(info.state.first_seq, 1) and len(entries) < count:
+                try:
+                    msg = await js.get_msg(self.stream, seq)
+                    entries.append((str(seq), json.loads(msg.data)))
+                except Exception:
+                    # Deleted or expired message
+                    pass
+                seq -= 1
+            return entries
+        return self._run(_range())
+
+    def read_after(self, last_id: str, count: int, block_ms: int) -> List[Entry]:
+        async def _read():
+            from nats.errors import TimeoutError as NatsTimeoutError
+            from nats.js.api import AckPolicy, ConsumerConfig, DeliverPolicy
+
+            _, js = await self._connect()
+            config = ConsumerConfig(
+                deliver_policy=DeliverPolicy.BY_START_SEQUENCE,
+                opt_start_seq=self._seq(last_id) + 1,
+                ack_policy=AckPolicy.NONE,
+                inactive_threshold=60.0,
+            )
+            sub = await js.pull_subscribe(f"{self.subject_prefix}.events.>", stream=self.stream, config=config)
+            try:
+                msgs = await sub.fetch(count, timeout=max(block_ms, 1) / 1000)
+            except NatsTimeoutError:
+                msgs = []
+            finally:
+                await sub.unsubscribe()
+            return [(str(m.metadata.sequence.stream), json.loads(m.data)) for m in msgs]
+        return self._run(_read(), timeout=block_ms / 1000 + 30)
+
+    def set_once(self, key: str, ttl_seconds: int) -> bool:
+        async def _set():
+            from nats.js.errors import KeyWrongLastSequenceError
+
+            _, js = await self._connect()
+            if self._kv is None:
+                bucket = settings.get("events.nats.claims_bucket", "rice_claims")
+                try:
+                    self._kv = await js.key_value(bucket)
+                except Exception:
+                    self._kv = await js.create_key_value(bucket=bucket, ttl=ttl_seconds)
+            try:
+                await self._kv.create(key.replace(":", "."), b"1")
+                return True
+            except KeyWrongLastSequenceError:
+                return False
+        return self._run(_set())
+
+    def request(self, topic: str, data: str, timeout: float) -> str:
+        async def _request():
+            from nats.errors import NoRespondersError, TimeoutError as NatsTimeoutError
+
+            nc, _ = await self._connect()
+            try:
+                msg = await nc.request(f"{self.subject_prefix}.rpc.{topic}", data.encode(), timeout=timeout)
+            except (NatsTimeoutError, NoRespondersError) as e:
+                raise TimeoutError(f"No worker answered {topic} within {timeout}s") from e
+            return msg.data.decode()
+        return self._run(_request(), timeout=timeout + 5)
+
+    def serve(self, topic: str, handler: Callable[[str], str], stop: threading.Event):
+        async def _subscribe():
+            nc, _ = await self._connect()
+
+            async def on_request(msg):
+                reply = await asyncio.to_thread(handler, msg.data.decode())
+                await msg.respond(reply.encode())
+
+            # Queue group: each request goes to one worker
+            return await nc.subscribe(f"{self.subject_prefix}.rpc.{topic}", queue=f"workers.{topic}", cb=on_request)
+
+        sub = self._run(_subscribe())
+        stop.wait()
+        self._run(sub.unsubscribe())
+
+
+def create_backend(name: Optional[str] = None, **kwargs: Any) -> BusBackend:
+    """Backend by name (default ``events.backend``)."""
+    name = (name or settings.get("events.backend", "redis")).lower()
+    if name == "nats":
+        return NatsBackend(**kwargs)
+    if name != "redis":
+        logger.warning(f"Unknown events.backend '{name}', using redis")
+    return RedisStreamsBackend(**kwargs)
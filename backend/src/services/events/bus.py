@@ -0,0 +1,288 @@
+"""
+Event Bus.
+
+Indexing, alerts and audit entries are published as events on a shared
+stream so the API can show them live, whichever process (API or worker)
+produced them. Topics are dotted names (``index.file.success``,
+``alert.warning``, ``audit.store_created``); readers filter with glob
+patterns (``index.*``).
+
+The stream keeps the last ``events.max_len`` events; ids are stream ids
+(Redis stream ids, or JetStream sequence numbers), so a reader can resume from the last id it saw. Every event also has a
+stable ``event_id`` and is written to the on-disk journal, from which a time
+range can be replayed. Replayed events keep their ``event_id`` and carry
+``replayed: true``; consumers that must act once per event guard with
+``EventBus.claim``.
+
+The transport is pluggable (``events.backend``: Redis Streams or NATS, see
+``backends``). The same bus carries request/reply work topics
+(``inference.embed``, ``inference.rerank``) so ML workers can run on
+separate GPU machines; failed requests are retried and then dead-lettered
+(``dead_letters``).
+"""
+
+import fnmatch
+import json
+import logging
+import threading
+import uuid
+from datetime import datetime
+from typing import Any, Callable, Dict, List, Optional, Tuple
+
+from src.core.config import settings
+from src.services.events.backends import BusBackend, RedisStreamsBackend, create_backend
+
+logger = logging.getLogger(__name__)
+
+
+def parse_topics(topics: Optional[str]) -> List[str]:
+    """Comma-separated topic patterns (empty: every topic)."""
+    return [t.strip() for t in (topics or "").split(",") if t.strip()]
+
+
+def topic_matches(topic: str, patterns: List[str]) -> bool:
+    if not patterns:
+        return True
+    return any(fnmatch.fnmatchcase(topic, p) or topic.startswith(p + ".") for p in patterns)
+
+
+class EventBus:
+    """Publishes and reads events on a capped stream."""
+
+    def __init__(self, redis_client=None, backend: Optional[BusBackend] = None):
+        if backend is None and redis_client is not None:
+            backend = RedisStreamsBackend(redis_client)
+        self._backend = backend
+
+    @property
+    def backend(self) -> BusBackend:
+        """Lazy transport (``events.backend``)."""
+        if self._backend is None:
+            self._backend = create_backend()
+        return self._backend
+
+    @property
+    def enabled(self) -> bool:
+        return bool(settings.get("events.enabled", True))
+
+    @property
+    def max_len(self) -> int:
+        return int(settings.get("events.max_len", 10000))
+
+    def publish(self, topic: str, payload: Optional[Dict[str, Any]] = None) -> Optional[str]:
+        """Append an event and journal it; returns its stream id (None if disabled)."""
+        if not self.enabled:
+            return None
+        event = {
+            "event_id": uuid.uuid4().hex,
+            "topic": topic,
+            "timestamp": datetime.now().isoformat(),
+            "payload": payload or {},
+        }
+        try:
+            from src.services.events.journal import get_event_journal
+            get_event_journal().append(event)
+        except Exception as e:
+            logger.warning(f"Failed to journal event {topic}: {e}")
+        return self._add(event)
+
+    def _add(self, event: Dict[str, Any], replayed: bool = False) -> str:
+        fields = {
+            "event_id": event["event_id"],
+            "topic": event["topic"],
+            "timestamp": event["timestamp"],
+            "payload": json.dumps(event.get("payload") or {}, default=str),
+        }
+        if replayed:
+            fields["replayed"] = "1"
+        return self.backend.add(event["topic"], fields, self.max_len)
+
+    def replay(
+        self,
+        since: Optional[float] = None,
+        until: Optional[float] = None,
+        topics: Optional[List[str]] = None,
+        limit: Optional[int] = None,
+        dry_run: bool = False
+    ) -> Dict[str, Any]:
+        """
+        Republish journaled events from a time range onto the stream.
+
+        Args:
+            since/until: Epoch seconds (inclusive; None: unbounded)
+            topics: Topic patterns (default: all)
+            limit: Max events (default ``events.replay.max_events``)
+            dry_run: Count matching events without publishing
+
+        Returns:
+            Dict with counts per topic and whether the limit cut the range short
+        """
+        from src.services.events.journal import get_event_journal
+
+        if limit is None:
+            limit = int(settings.get("events.replay.max_events", 10000))
+        counts: Dict[str, int] = {}
+        replayed = 0
+        truncated = False
+        for event in get_event_journal().read(since, until):
+            if not topic_matches(event.get("topic", ""), topics or []):
+                continue
+            if replayed >= limit:
+                truncated = True
+                break
+            if not dry_run:
+                self._add(event, replayed=True)
+            counts[event["topic"]] = counts.get(event["topic"], 0) + 1
+            replayed += 1
+        return {"dry_run": dry_run, "events": replayed, "topics": counts, "truncated": truncated}
+
+    def claim(self, consumer: str, event: Dict[str, Any]) -> bool:
+        """
+        Idempotency guard: True the first time ``consumer`` sees an event.
+
+        Replays re-deliver events with their original ``event_id``; consumers
+        with side effects call this and skip events it returns False for.
+        Claims expire after ``events.idempotency_ttl_seconds``.
+        """
+        event_id = event.get("event_id") or event.get("id")
+        if not event_id:
+            return True
+        ttl = int(settings.get("events.idempotency_ttl_seconds", 7 * 24 * 3600))
+        return self.backend.set_once(f"rice:events:claimed:{consumer}:{event_id}", ttl)
+
+    @staticmethod
+    def _decode(event_id: str, fields: Dict[str, str]) -> Dict[str, Any]:
+        try:
+            payload = json.loads(fields.get("payload") or "{}")
+        except ValueError:
+            payload = {"raw": fields.get("payload")}
+        event = {
+            "id": event_id,
+            "event_id": fields.get("event_id") or event_id,
+            "topic": fields.get("topic", ""),
+            "timestamp": fields.get("timestamp"),
+            "payload": payload,
+        }
+        if fields.get("replayed"):
+            event["replayed"] = True
+        return event
+
+    def recent(self, topics: Optional[List[str]] = None, limit: int = 100) -> List[Dict[str, Any]]:
+        """Most recent events matching ``topics``, oldest first."""
+        events = []
+        last_id = "+"
+        # Page backwards until enough matches (filters may skip most events)
+        while len(events) < limit:
+            batch = self.backend.rev_range(last_id, max(limit, 100))
+            if not batch:
+                break
+            if last_id != "+":
+                batch = batch[1:]
+                if not batch:
+                    break
+            for event_id, fields in batch:
+                event = self._decode(event_id, fields)
+                if topic_matches(event["topic"], topics or []):
+                    events.append(event)
+                    if len(events) >= limit:
+                        break
+            last_id = batch[-1][0]
+        return list(reversed(events))
+
+    def read(
+        self,
+        last_id: str = "$",
+        topics: Optional[List[str]] = None,
+        block_ms: int = 5000,
+        count: int = 100
+    ) -> Tuple[str, List[Dict[str, Any]]]:
+        """
+        Block until events newer than ``last_id`` arrive (or ``block_ms`` passes).
+
+        Returns:
+            (id to continue from, matching events)
+        """
+        if last_id == "$":
+            # Resolve now so nothing published between calls is missed
+            last_id = self.backend.latest_id()
+        events = []
+        for event_id, fields in self.backend.read_after(last_id, count, block_ms):
+            last_id = event_id
+            event = self._decode(event_id, fields)
+            if topic_matches(event["topic"], topics or []):
+                events.append(event)
+        return last_id, events
+
+    def topics(self, sample: int = 1000) -> List[str]:
+        """Distinct topics among the most recent events."""
+        entries = self.backend.rev_range("+", sample)
+        return sorted({fields.get("topic", "") for _id, fields in entries})
+
+    def request(self, topic: str, payload: Dict[str, Any], timeout: float = 60) -> Dict[str, Any]:
+        """
+        Send a work request to one worker serving ``topic`` and wait for the reply.
+
+        Raises:
+            TimeoutError: No worker answered in time
+            RuntimeError: The worker failed the request
+        """
+        reply = json.loads(self.backend.request(topic, json.dumps(payload, default=str), timeout))
+        if "error" in reply:
+            raise RuntimeError(f"{topic} worker failed: {reply['error']}")
+        return reply.get("result") or {}
+
+    def serve(
+        self,
+        topic: str,
+        handler: Callable[[Dict[str, Any]], Dict[str, Any]],
+        stop: Optional[threading.Event] = None
+    ):
+        """
+        Answer work requests on ``topic`` until ``stop`` is set (blocks).
+
+        Failing requests are retried under the topic's retry policy, then
+        dead-lettered (see ``dead_letters``) and answered with the error.
+        Requeued dead letters are claimed per attempt, so each runs once.
+        """
+        from src.services.events.dead_letters import (
+            KIND_REQUEST, REDELIVERY_FIELD, RetriesExhausted, call_with_retry, get_dead_letter_store,
+        )
+
+        def handle(data: str) -> str:
+            request = json.loads(data)
+            redelivery = request.pop(REDELIVERY_FIELD, None)
+            # A requeue attempt runs once, even if the transport delivers it twice
+            if redelivery and not self.claim(f"serve:{topic}", {"event_id": redelivery}):
+                logger.info(f"Skipping duplicate {topic} redelivery {redelivery}")
+                return json.dumps({"error": "Duplicate redelivery"})
+            try:
+                reply = {"result": call_with_retry(topic, lambda: handler(request))}
+            except RetriesExhausted as e:
+                logger.error(f"Handling {topic} request failed: {e}")
+                if not redelivery:
+                    # A requeued request's entry is updated by whoever requeued it
+                    get_dead_letter_store().add(KIND_REQUEST, topic, request, str(e.error), e.attempts)
+                reply = {"error": str(e.error)}
+            return json.dumps(reply, default=str)
+
+        logger.info(f"Serving {topic} requests over {self.backend.name}")
+        self.backend.serve(topic, handle, stop or threading.Event())
+
+
+# Singleton instance
+_event_bus: Optional[EventBus] = None
+
+def get_event_bus() -> EventBus:
+    """Get global event bus instance."""
+    global _event_bus
+    if _event_bus is None:
+        _event_bus = EventBus()
+    return _event_bus
+
+
+def emit(topic: str, **payload: Any):
+    """Publish an event (never raises; events are best effort)."""
+    try:
+        get_event_bus().publish(topic, payload)
+    except Exception as e:
+        logger.debug(f"Failed to publish event {topic}: {e}")
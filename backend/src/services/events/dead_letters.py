@@ -0,0 +1,235 @@
+"""
+Retries and Dead Letters.
+
+Bus request handlers (ML workers serving ``inference.*``) are retried with
+exponential backoff before failing a request: a model that is still loading
+or a Qdrant blip does not fail the caller. Policies are per topic
+(``events.retry``); the default is 3 attempts, 0.5s doubling up to 10s.
+
+What still fails is kept in a dead-letter store in Redis instead of being
+lost: failed bus requests (topic and payload) and index tasks that ended in
+an error (the upload's task arguments). Admins browse them at
+``/api/v1/events/dlq`` and requeue them once the dependency is back:
+requests are sent to a worker again, index tasks are dispatched again.
+"""
+
+import fnmatch
+import json
+import logging
+import time
+import uuid
+from dataclasses import dataclass
+from datetime import datetime
+from typing import Any, Callable, Dict, List, Optional
+
+import redis
+
+from src.core.config import settings
+
+logger = logging.getLogger(__name__)
+
+# Dead-letter kinds (how an entry is requeued)
+KIND_REQUEST = "request"
+KIND_INDEX_TASK = "index_task"
+
+INDEX_TOPIC = "index.file"
+
+# Marks a requeued request, so a repeat failure updates its entry instead of adding one
+REDELIVERY_FIELD = "_dlq_id"
+
+
+@dataclass
+class RetryPolicy:
+    max_attempts: int = 3
+    backoff_seconds: float = 0.5
+    max_backoff_seconds: float = 10.0
+
+    def delay(self, attempt: int) -> float:
+        """Wait after the ``attempt``-th failure (1-based)."""
+        return min(self.backoff_seconds * (2 ** (attempt - 1)), self.max_backoff_seconds)
+
+
+def retry_policy(topic: str) -> RetryPolicy:
+    """
+    The retry policy for a topic.
+
+    ``events.retry.topics`` entries (``{topic: "inference.embed",
+    max_attempts: 5}``, glob patterns allowed) override the defaults; the
+    first match wins.
+    """
+    policy = RetryPolicy(
+        max_attempts=int(settings.get("events.retry.max_attempts", 3)),
+        backoff_seconds=float(settings.get("events.retry.backoff_seconds", 0.5)),
+        max_backoff_seconds=float(settings.get("events.retry.max_backoff_seconds", 10)),
+    )
+    for override in settings.get("events.retry.topics", []) or []:
+        if fnmatch.fnmatchcase(topic, str(override.get("topic", ""))):
+            return RetryPolicy(
+                max_attempts=int(override.get("max_attempts", policy.max_attempts)),
+                backoff_seconds=float(override.get("backoff_seconds", policy.backoff_seconds)),
+                max_backoff_seconds=float(override.get("max_backoff_seconds", policy.max_backoff_seconds)),
+            )
+    return policy
+
+
+class RetriesExhausted(Exception):
+    """Every attempt failed; ``attempts`` were made and ``error`` was the last."""
+
+    def __init__(self, topic: str, attempts: int, error: Exception):
+        super().__init__(f"{topic} failed after {attempts} attempt(s): {error}")
+        self.topic = topic
+        self.attempts = attempts
+        self.error = error
+
+
+def call_with_retry(topic: str, fn: Callable[[], Any], sleep: Callable[[float], None] = time.sleep) -> Any:
+    """
+    Call ``fn`` under the topic's retry policy.
+
+    Raises:
+        RetriesExhausted: the last attempt failed too
+    """
+    policy = retry_policy(topic)
+    attempts = max(1, policy.max_attempts)
+    for attempt in range(1, attempts + 1):
+        try:
+            return fn()
+        except Exception as e:
+            if attempt == attempts:
+                raise RetriesExhausted(topic, attempt, e) from e
+            delay = policy.delay(attempt)
+            logger.warning(f"{topic} attempt {attempt}/{attempts} failed ({e}), retrying in {delay:g}s")
+            sleep(delay)
+
+
+class DeadLetterStore:
+    """Failed requests and index tasks, newest first, capped at ``events.dlq.max_entries``."""
+
+    KEY = "rice:events:dlq"
+    INDEX_KEY = "rice:events:dlq:index"
+
+    def __init__(self, redis_client=None):
+        self._redis = redis_client
+
+    @property
+    def redis(self) -> redis.Redis:
+        """Lazy Redis connection."""
+        if self._redis is None:
+            self._redis = redis.from_url(settings.REDIS_URL, decode_responses=True)
+        return self._redis
+
+    @property
+    def enabled(self) -> bool:
+        return bool(settings.get("events.dlq.enabled", True))
+
+    def add(self, kind: str, topic: str, payload: Dict[str, Any], error: str, attempts: int) -> Optional[Dict[str, Any]]:
+        """Store a failure; returns the entry (None when the DLQ is off or unreachable)."""
+        if not self.enabled:
+            return None
+        entry = {
+            "id": uuid.uuid4().hex[:16],
+            "kind": kind,
+            "topic": topic,
+            "payload": payload,
+            "error": error,
+            "attempts": attempts,
+            "failed_at": datetime.now().isoformat(),
+            "requeues": 0,
+        }
+        try:
+            self._save(entry, time.time())
+            self._trim()
+        except Exception as e:
+            logger.error(f"Failed to dead-letter {topic}: {e}")
+            return None
+        logger.warning(f"Dead-lettered {topic} ({entry['id']}) after {attempts} attempt(s): {error}")
+        from src.services.events.bus import emit
+        emit("events.dlq.added", dlq_id=entry["id"], kind=kind, dead_topic=topic, error=error)
+        return entry
+
+    def _save(self, entry: Dict[str, Any], score: float):
+        pipe = self.redis.pipeline()
+        pipe.hset(self.KEY, entry["id"], json.dumps(entry, default=str))
+        pipe.zadd(self.INDEX_KEY, {entry["id"]: score})
+        pipe.execute()
+
+    def _trim(self):
+        max_entries = int(settings.get("events.dlq.max_entries", 10000))
+        excess = self.redis.zcard(self.INDEX_KEY) - max_entries
+        if excess > 0:
+            oldest = self.redis.zrange(self.INDEX_KEY, 0, excess - 1)
+            pipe = self.redis.pipeline()
+            pipe.hdel(self.KEY, *oldest)
+            pipe.zrem(self.INDEX_KEY, *oldest)
+            pipe.execute()
+
+    def get(self, entry_id: str) -> Optional[Dict[str, Any]]:
+        data = self.redis.hget(self.KEY, entry_id)
+        return json.loads(data) if data else None
+
+    def list(self, topics: Optional[List[str]] = None, limit: int = 100) -> Dict[str, Any]:
+        """Entries matching topic patterns, newest first, and the total stored."""
+        from src.services.events.bus import topic_matches
+
+        entries = []
+        for entry_id in self.redis.zrevrange(self.INDEX_KEY, 0, -1):
+            entry = self.get(entry_id)
+            if entry and topic_matches(entry["topic"], topics or []):
+                entries.append(entry)
+                if len(entries) >= limit:
+                    break
+        return {"entries": entries, "total": self.redis.zcard(self.INDEX_KEY)}
+
+    def remove(self, entry_id: str) -> bool:
+        pipe = self.redis.pipeline()
+        pipe.hdel(self.KEY, entry_id)
+        pipe.zrem(self.INDEX_KEY, entry_id)
+        removed, _ = pipe.execute()
+        return bool(removed)
+
+    def requeue(self, entry_id: str) -> Optional[Dict[str, Any]]:
+        """
+        Run a dead-lettered request or index task again.
+
+        A request that succeeds, and an index task once dispatched, leave
+        the store; a request that fails again stays with its new error.
+
+        Returns:
+            None if there is no such entry, else ``{"status": "requeued" |
+            "failed", ...}``
+        """
+        entry = self.get(entry_id)
+        if entry is None:
+            return None
+
+        if entry["kind"] == KIND_INDEX_TASK:
+            from src.tasks.ingestion import ingest_file_task
+            task = ingest_file_task.apply_async(kwargs=entry["payload"])
+            self.remove(entry_id)
+            return {"status": "requeued", "id": entry_id, "task_id": str(task.id)}
+
+        from src.services.events.bus import get_event_bus
+        timeout = float(settings.get("inference.remote.timeout_seconds", 60))
+        try:
+            # One redelivery id per attempt: workers claim it, so an attempt runs once
+            redelivery = f"{entry_id}:{entry['requeues']}"
+            get_event_bus().request(entry["topic"], {**entry["payload"], REDELIVERY_FIELD: redelivery}, timeout)
+        except Exception as e:
+            entry["requeues"] += 1
+            entry["error"] = str(e)
+            entry["failed_at"] = datetime.now().isoformat()
+            self._save(entry, time.time())
+            return {"status": "failed", "id": entry_id, "error": str(e)}
+        self.remove(entry_id)
+        return {"status": "requeued", "id": entry_id}
+
+
+# Singleton instance
+_dead_letter_store: Optional[DeadLetterStore] = None
+
+def get_dead_letter_store() -> DeadLetterStore:
+    """Get global dead-letter store instance."""
+    global _dead_letter_store
+    if _dead_letter_store is None:
+        _dead_letter_store = DeadLetterStore()
+    return _dead_letter_store
@@ -0,0 +1,183 @@
+"""
+Event Journal.
+
+The Redis event stream only keeps the most recent events. The journal keeps
+every published event on disk as JSON lines in size-rotated segments under
+``events.journal.dir``, so a time range can be replayed onto the bus later
+(to rebuild metrics, or re-drive a consumer that failed).
+
+Segments are named ``events-<start time>-<seq>.jsonl`` and rotate once they
+reach ``events.journal.segment_mb``; the oldest are deleted beyond
+``events.journal.max_segments``. API and worker processes append to the same
+directory (share it as a volume), serialized with a lock file.
+"""
+
+import fcntl
+import json
+import logging
+import os
+from contextlib import contextmanager
+from datetime import datetime
+from typing import Any, Dict, Iterator, List, Optional
+
+from src.core.config import settings
+
+logger = logging.getLogger(__name__)
+
+MB = 1024 * 1024
+SEGMENT_PREFIX = "events-"
+SEGMENT_SUFFIX = ".jsonl"
+
+
+def event_time(event: Dict[str, Any]) -> Optional[float]:
+    """Epoch seconds of an event's timestamp (None if unparseable)."""
+    try:
+        return datetime.fromisoformat(event["timestamp"]).timestamp()
+    except (KeyError, TypeError, ValueError):
+        return None
+
+
+class EventJournal:
+    """Append-only, segment-rotated event log on disk."""
+
+    def __init__(self, directory: Optional[str] = None):
+        self._directory = directory
+
+    @property
+    def directory(self) -> str:
+        return self._directory or settings.get("events.journal.dir", "data/events")
+
+    @property
+    def enabled(self) -> bool:
+        return bool(settings.get("events.journal.enabled", True))
+
+    @property
+    def segment_bytes(self) -> int:
+        return int(float(settings.get("events.journal.segment_mb", 16)) * MB)
+
+    @property
+    def max_segments(self) -> int:
+        return int(settings.get("events.journal.max_segments", 50))
+
+    @contextmanager
+    def _locked(self):
+        os.makedirs(self.directory, exist_ok=True)
+        with open(os.path.join(self.directory, "journal.lock"), "w") as lock:
+            fcntl.flock(lock, fcntl.LOCK_EX)
+            try:
+                yield
+            finally:
+                fcntl.flock(lock, fcntl.LOCK_UN)
+
+    def segments(self) -> List[str]:
+        """Segment file names, oldest first."""
+        try:
+            names = os.listdir(self.directory)
+        except FileNotFoundError:
+            return []
+        return sorted(n for n in names if n.startswith(SEGMENT_PREFIX) and n.endswith(SEGMENT_SUFFIX))
+
+    @staticmethod
+    def _segment_start(name: str) -> Optional[float]:
+        stamp = name[len(SEGMENT_PREFIX):].split("-", 1)[0]
+        try:
+            return datetime.strptime(stamp, "%Y%m%dT%H%M%S").timestamp()
+        except ValueError:
+            return None
+
+    def _new_segment(self, existing: List[str]) -> str:
+        seq = len(existing)
+        if existing:
+            try:
+                seq = int(existing[-1][:-len(SEGMENT_SUFFIX)].rsplit("-", 1)[1]) + 1
+            except (IndexError, ValueError):
+                pass
+        return f"{SEGMENT_PREFIX}{datetime.now().strftime('%Y%m%dT%H%M%S')}-{seq:06d}{SEGMENT_SUFFIX}"
+
+    def _current_segment(self) -> str:
+        """Segment to append to, rotating (and pruning) when it is full."""
+        segments = self.segments()
+        if segments:
+            path = os.path.join(self.directory, segments[-1])
+            if os.path.getsize(path) < self.segment_bytes:
+                return path
+        name = self._new_segment(segments)
+        segments.append(name)
+        for old in segments[:-self.max_segments] if self.max_segments > 0 else []:
+            try:
+                os.remove(os.path.join(self.directory, old))
+            except OSError as e:
+                logger.warning(f"Failed to prune event journal segment {old}: {e}")
+        return os.path.join(self.directory, name)
+
+    def append(self, event: Dict[str, Any]):
+        """Write one event (no-op when disabled)."""
+        if not self.enabled:
+            return
+        line = json.dumps(event, default=str) + "\n"
+        with self._locked():
+            with open(self._current_segment(), "a", encoding="utf-8") as f:
+                f.write(line)
+
+    def read(self, since: Optional[float] = None, until: Optional[float] = None) -> Iterator[Dict[str, Any]]:
+        """
+        Journaled events in [since, until] (epoch seconds), oldest first.
+
+        Segments that end before ``since`` are skipped without being read.
+        """
+        segments = self.segments()
+        for i, name in enumerate(segments):
+            if until is not None:
+                start = self._segment_start(name)
+                if start is not None and start > until:
+                    break
+            if since is not None and i + 1 < len(segments):
+                next_start = self._segment_start(segments[i + 1])
+                if next_start is not None and next_start < since:
+                    continue
+            try:
+                with open(os.path.join(self.directory, name), encoding="utf-8") as f:
+                    for line in f:
+                        try:
+                            event = json.loads(line)
+                        except ValueError:
+                            # Torn write from a crash mid-append
+                            continue
+                        at = event_time(event)
+                        if at is None:
+                            continue
+                        if since is not None and at < since:
+                            continue
+                        if until is not None and at > until:
+                            continue
+                        yield event
+            except FileNotFoundError:
+                # Pruned while reading
+                continue
+
+    def get_stats(self) -> Dict[str, Any]:
+        segments = self.segments()
+        size = 0
+        for name in segments:
+            try:
+                size += os.path.getsize(os.path.join(self.directory, name))
+            except OSError:
+                pass
+        return {
+            "enabled": self.enabled,
+            "directory": self.directory,
+            "segments": len(segments),
+            "size_bytes": size,
+            "oldest_segment": segments[0] if segments else None,
+        }
+
+
+# Singleton instance
+_journal: Optional[EventJournal] = None
+
+def get_event_journal() -> EventJournal:
+    """Get global event journal instance."""
+    global _journal
+    if _journal is None:
+        _journal = EventJournal()
+    return _journal
@@ -4,12 +4,17 @@ Local Cross-Encoder Reranker.
 Uses sentence-transformers cross-encoder for fast, accurate reranking.
 Much better than LLM-based reranking.
 """
+import asyncio
 import logging
+import time
 from typing import List, Dict, Any, Optional
 
 from sentence_transformers import CrossEncoder
 
 from src.core.config import settings
+from src.services.model_manager import touch_model, track_loaded_model, warm_up_model
+from src.services.inference.quantization import apply_precision, configured_precision, effective_precision
+from src.services.inference.tokenizer_loader import tokenizer_args
 
 logger = logging.getLogger(__name__)
 
@@ -21,14 +26,18 @@ class LocalReranker:
     Uses ms-marco-MiniLM-L-12-v2 by default - fast and accurate.
     """
 
-    def __init__(self, model_name: str = None):
+    def __init__(self, model_name: str = None, precision: str = None, managed: bool = False):
         """
         Initialize cross-encoder reranker.
 
         Args:
             model_name: Cross-encoder model (default: ms-marco-MiniLM-L-12-v2)
+            precision: fp32, fp16 or int8 (default: model registry/settings)
+            managed: Warm up on load and let the model manager unload it when idle
         """
         self.model_name = model_name or "cross-encoder/ms-marco-MiniLM-L-12-v2"
+        self.precision = precision or configured_precision("reranker")
+        self.managed = managed
         self.model: Optional[CrossEncoder] = None
         logger.info(f"LocalReranker initialized with model: {self.model_name}")
 
@@ -36,8 +45,22 @@ class LocalReranker:
         """Lazy load the model."""
         if self.model is None:
             logger.info(f"Loading cross-encoder model: {self.model_name}")
-            self.model = CrossEncoder(self.model_name)
-            logger.info("Cross-encoder model loaded successfully")
+            started = time.perf_counter()
+            model = CrossEncoder(self.model_name, tokenizer_args=tokenizer_args(self.model_name))
+            device = str(model.model.device).split(":")[0]
+            model.model = apply_precision(model.model, self.precision, device)
+            self.model = model
+            logger.info(
+                f"Cross-encoder model loaded successfully ({effective_precision(self.precision, device)})"
+            )
+            if self.managed:
+                load_seconds = time.perf_counter() - started
+                warmup_ms = warm_up_model(self.model_name, lambda text: model.predict([[text, text]]))
+                track_loaded_model(self.model_name, "reranker", self, self._unload, load_seconds, warmup_ms)
+
+    def _unload(self):
+        """Drop the model; the next rerank loads it again."""
+        self.model = None
 
     async def rerank(
         self,
@@ -58,12 +81,13 @@ class LocalReranker:
         """
         try:
             self._load_model()
+            touch_model(self.model_name)
 
             # Create query-document pairs
             pairs = [[query, doc] for doc in documents]
 
-            # Get relevance scores
-            scores = self.model.predict(pairs)
+            # Get relevance scores (off the event loop so callers can time out)
+            scores = await asyncio.to_thread(self.model.predict, pairs)
 
             # Create results with scores
             results = [
@@ -96,5 +120,22 @@ def get_local_reranker() -> LocalReranker:
     """Get singleton local reranker."""
     global _local_reranker
     if _local_reranker is None:
-        _local_reranker = LocalReranker()
+        _local_reranker = LocalReranker(managed=True)
     return _local_reranker
+
+
+def reload_local_reranker() -> LocalReranker:
+    """Drop the loaded model so the next rerank loads it with current settings."""
+    global _local_reranker
+    _local_reranker = None
+    return get_local_reranker()
+
+
+_named_rerankers: Dict[str, LocalReranker] = {}
+
+
+def get_named_reranker(model_name: str) -> LocalReranker:
+    """Reranker for a model other than the configured one (experiments, per-request overrides)."""
+    if model_name not in _named_rerankers:
+        _named_rerankers[model_name] = LocalReranker(model_name=model_name, managed=True)
+    return _named_rerankers[model_name]
@@ -0,0 +1,79 @@
+"""
+ML Worker Health.
+
+ML workers (``src.worker.ml_worker``) publish ``ml.worker.heartbeat`` events
+on the bus with the topics they serve, their device and request counts. The
+API reads them back to list workers and report whether every topic sent to
+remote workers has at least one live worker.
+"""
+import logging
+from datetime import datetime
+from typing import Any, Dict, List
+
+from src.core.config import settings
+from src.services.events.bus import get_event_bus
+
+logger = logging.getLogger(__name__)
+
+HEARTBEAT_TOPIC = "ml.worker.heartbeat"
+REMOTE_TASKS = ("embed", "sparse", "rerank")
+
+
+def heartbeat_interval() -> float:
+    return float(settings.get("inference.remote.heartbeat_seconds", 15))
+
+
+def remote_topics() -> List[str]:
+    """Topics this deployment sends to remote workers."""
+    return [
+        f"inference.{task}" for task in REMOTE_TASKS
+        if settings.get(f"inference.remote.{task}", False)
+    ]
+
+
+def list_ml_workers(limit: int = 500) -> List[Dict[str, Any]]:
+    """Latest heartbeat per worker, with its age and whether it is live."""
+    latest: Dict[str, Dict[str, Any]] = {}
+    for event in get_event_bus().recent([HEARTBEAT_TOPIC], limit):
+        payload = event.get("payload") or {}
+        if payload.get("worker_id"):
+            latest[payload["worker_id"]] = {**payload, "last_seen": event.get("timestamp")}
+
+    now = datetime.now()
+    stale_after = 3 * heartbeat_interval()
+    workers = []
+    for worker in latest.values():
+        try:
+            age = (now - datetime.fromisoformat(worker["last_seen"])).total_seconds()
+        except (TypeError, ValueError):
+            age = None
+        worker["age_seconds"] = round(age, 1) if age is not None else None
+        worker["live"] = (
+            age is not None and age <= stale_after and not worker.get("stopping")
+        )
+        workers.append(worker)
+    return sorted(workers, key=lambda w: w["worker_id"])
+
+
+def ml_worker_health() -> Dict[str, Any]:
+    """
+    Health of remote inference.
+
+    ``down`` when a topic sent to remote workers has no live worker,
+    ``disabled`` when all inference runs in-process.
+    """
+    topics = remote_topics()
+    if not topics:
+        return {"status": "disabled", "workers": 0}
+    workers = list_ml_workers()
+    live = [w for w in workers if w["live"]]
+    serving = {t: sum(1 for w in live if t in (w.get("topics") or [])) for t in topics}
+    missing = [t for t, count in serving.items() if count == 0]
+    health = {
+        "status": "down" if missing else "up",
+        "workers": len(live),
+        "topics": serving,
+    }
+    if missing:
+        health["error"] = f"No live ML worker for {', '.join(missing)}"
+    return health
@@ -0,0 +1,362 @@
+"""
+Model Downloader.
+
+Fetches HuggingFace model repositories into ``models.download.dir`` before
+they are loaded, instead of letting ``from_pretrained`` stream them one file
+at a time with no integrity check:
+
+- Files download in parallel; large files are split into ranged chunks that
+  download in parallel too.
+- Interrupted downloads resume from ``*.part`` files with HTTP Range
+  requests.
+- Every file is verified against the hub metadata (SHA256 for LFS files,
+  git blob SHA1 for the rest) before it replaces the real file, so a corrupt
+  download fails here rather than when the model is loaded.
+- Partial files are removed when they fail verification or get older than
+  ``models.download.partial_ttl_hours``.
+
+A completed snapshot is recorded in ``.download.json`` and reused while the
+hub revision is unchanged (or unreachable).
+"""
+import fnmatch
+import hashlib
+import json
+import logging
+import os
+import threading
+import time
+from concurrent.futures import ThreadPoolExecutor
+from datetime import datetime
+from pathlib import Path
+from typing import Any, Dict, List, Optional, Tuple
+from urllib.parse import quote
+
+import httpx
+
+from src.core.config import settings
+
+logger = logging.getLogger(__name__)
+
+MANIFEST = ".download.json"
+
+# Weights in formats the loaders here never read
+IGNORE_PATTERNS = (
+    "*.onnx", "onnx/*", "*.msgpack", "*.h5", "*.ot", "*.tflite",
+    "openvino/*", "coreml/*", "*.md", ".gitattributes",
+)
+
+
+class DownloadError(Exception):
+    """A model could not be downloaded."""
+
+
+class ChecksumError(DownloadError):
+    """A downloaded file does not match the hub metadata."""
+
+
+def git_blob_sha1(path: Path) -> str:
+    """Git object id of a file (what the hub reports for non-LFS files)."""
+    digest = hashlib.sha1(f"blob {path.stat().st_size}\0".encode())
+    with open(path, "rb") as f:
+        for block in iter(lambda: f.read(1 << 20), b""):
+            digest.update(block)
+    return digest.hexdigest()
+
+
+def sha256(path: Path) -> str:
+    digest = hashlib.sha256()
+    with open(path, "rb") as f:
+        for block in iter(lambda: f.read(1 << 20), b""):
+            digest.update(block)
+    return digest.hexdigest()
+
+
+def select_files(siblings: List[Dict[str, Any]]) -> List[Dict[str, Any]]:
+    """
+    Files worth downloading from a repo listing (``?blobs=true`` siblings).
+
+    Skips formats nobody here loads, and ``*.bin`` weights when safetensors
+    are available.
+
+    Returns:
+        Dicts with ``path``, ``size`` and ``sha256`` (LFS) or ``blob_id``
+    """
+    names = [s["rfilename"] for s in siblings]
+    has_safetensors = any(n.endswith(".safetensors") for n in names)
+    files = []
+    for sibling in siblings:
+        name = sibling["rfilename"]
+        if any(fnmatch.fnmatch(name, p) for p in IGNORE_PATTERNS):
+            continue
+        if has_safetensors and name.endswith(".bin") and "model" in os.path.basename(name):
+            continue
+        lfs = sibling.get("lfs") or {}
+        files.append({
+            "path": name,
+            "size": lfs.get("size", sibling.get("size")),
+            "sha256": lfs.get("sha256"),
+            "blob_id": None if lfs else sibling.get("blobId"),
+        })
+    return files
+
+
+class ModelDownloader:
+    """Parallel, resumable, verified HuggingFace downloads."""
+
+    def __init__(self, root: Optional[str] = None, endpoint: Optional[str] = None):
+        self.root = Path(root or settings.get("models.download.dir", "data/models"))
+        self.endpoint = (endpoint or settings.get("models.download.endpoint", "https://huggingface.co")).rstrip("/")
+        self.workers = max(1, int(settings.get("models.download.workers", 4)))
+        self.chunk_bytes = int(settings.get("models.download.chunk_mb", 64)) * 1024 * 1024
+        self.timeout = float(settings.get("models.download.timeout_seconds", 60))
+        self.partial_ttl = float(settings.get("models.download.partial_ttl_hours", 24)) * 3600
+        self.progress: Dict[str, Dict[str, Any]] = {}
+        self._lock = threading.Lock()
+
+    # ============== HTTP ==============
+
+    def _client(self) -> httpx.Client:
+        headers = {}
+        token = os.getenv("HF_TOKEN") or settings.get("models.download.hf_token", "")
+        if token:
+            headers["Authorization"] = f"Bearer {token}"
+        return httpx.Client(timeout=self.timeout, follow_redirects=True, headers=headers)
+
+    def repo_files(self, repo_id: str, revision: str = "main") -> Tuple[str, List[Dict[str, Any]]]:
+        """Commit sha and files of a repo revision."""
+        url = f"{self.endpoint}/api/models/{repo_id}/revision/{quote(revision, safe='')}"
+        with self._client() as client:
+            resp = client.get(url, params={"blobs": "true"})
+        if resp.status_code in (401, 403, 404):
+            raise DownloadError(f"{repo_id}@{revision} not found or not accessible ({resp.status_code})")
+        resp.raise_for_status()
+        data = resp.json()
+        return data.get("sha") or revision, select_files(data.get("siblings") or [])
+
+    def _file_url(self, repo_id: str, sha: str, path: str) -> str:
+        return f"{self.endpoint}/{repo_id}/resolve/{sha}/{quote(path)}"
+
+    def _fetch(
+        self,
+        client: httpx.Client,
+        url: str,
+        part: Path,
+        repo_id: str,
+        start: int = 0,
+        end: Optional[int] = None,
+    ):
+        """
+        Download bytes ``start..end`` (inclusive; to EOF if None) into
+        ``part``, resuming from what it already holds.
+        """
+        have = part.stat().st_size if part.exists() else 0
+        if end is not None:
+            wanted = end - start + 1
+            if have == wanted:
+                self._advance(repo_id, have)
+                return
+            if have > wanted:
+                part.unlink()
+                have = 0
+        first = start + have
+        headers = {"Range": f"bytes={first}-{'' if end is None else end}"} if first or end is not None else {}
+
+        with client.stream("GET", url, headers=headers) as resp:
+            if resp.status_code == 416 and end is None:
+                # Nothing left past what we have
+                self._advance(repo_id, have)
+                return
+            resp.raise_for_status()
+            if headers and resp.status_code != 206:
+                if end is not None:
+                    raise DownloadError(f"Server ignored range request for {url}")
+                # Whole file came back: start over
+                have = 0
+            self._advance(repo_id, have)
+            with open(part, "ab" if have else "wb") as f:
+                for block in resp.iter_bytes(1 << 20):
+                    f.write(block)
+                    self._advance(repo_id, len(block))
+
+    def _supports_ranges(self, client: httpx.Client, url: str) -> bool:
+        try:
+            resp = client.head(url)
+            return resp.headers.get("accept-ranges", "").lower() == "bytes"
+        except httpx.HTTPError:
+            return False
+
+    # ============== Files ==============
+
+    def _verify(self, path: Path, file: Dict[str, Any]) -> bool:
+        if file.get("size") is not None and path.stat().st_size != file["size"]:
+            return False
+        if file.get("sha256"):
+            return sha256(path) == file["sha256"]
+        if file.get("blob_id"):
+            return git_blob_sha1(path) == file["blob_id"]
+        return True
+
+    def _download_file(self, repo_id: str, sha: str, file: Dict[str, Any], target: Path):
+        dest = target / file["path"]
+        dest.parent.mkdir(parents=True, exist_ok=True)
+        if dest.exists() and self._verify(dest, file):
+            self._advance(repo_id, dest.stat().st_size, files=1)
+            return
+
+        url = self._file_url(repo_id, sha, file["path"])
+        part = dest.with_name(dest.name + ".part")
+        size = file.get("size") or 0
+        with self._client() as client:
+            if size >= 2 * self.chunk_bytes and self._supports_ranges(client, url):
+                self._download_chunked(client, url, part, size, repo_id)
+            else:
+                self._fetch(client, url, part, repo_id)
+
+        if not self._verify(part, file):
+            part.unlink(missing_ok=True)
+            raise ChecksumError(f"{repo_id}/{file['path']} failed verification (size or hash mismatch)")
+        os.replace(part, dest)
+        self._advance(repo_id, 0, files=1)
+
+    def _download_chunked(self, client: httpx.Client, url: str, part: Path, size: int, repo_id: str):
+        """Fetch a large file as parallel ranged chunks, then join them."""
+        ranges = [(s, min(s + self.chunk_bytes, size) - 1) for s in range(0, size, self.chunk_bytes)]
+        chunks = [part.with_name(f"{part.name}.{i}") for i in range(len(ranges))]
+        with ThreadPoolExecutor(max_workers=self.workers, thread_name_prefix="model-chunk") as pool:
+            futures = [
+                pool.submit(self._fetch, client, url, chunk, repo_id, start, end)
+                for chunk, (start, end) in zip(chunks, ranges)
+            ]
+            for future in futures:
+                future.result()
+        with open(part, "wb") as out:
+            for chunk in chunks:
+                with open(chunk, "rb") as f:
+                    for block in iter(lambda: f.read(1 << 20), b""):
+                        out.write(block)
+        for chunk in chunks:
+            chunk.unlink(missing_ok=True)
+
+    def cleanup_partials(self, max_age: Optional[float] = None) -> int:
+        """Remove ``*.part`` files (and chunks) older than ``max_age`` seconds."""
+        max_age = self.partial_ttl if max_age is None else max_age
+        if not self.root.exists():
+            return 0
+        removed = 0
+        cutoff = time.time() - max_age
+        for path in self.root.rglob("*.part*"):
+            try:
+                if path.is_file() and path.stat().st_mtime < cutoff:
+                    path.unlink()
+                    removed += 1
+            except OSError as e:
+                logger.warning(f"Could not remove partial download {path}: {e}")
+        if removed:
+            logger.info(f"Removed {removed} stale partial model downloads")
+        return removed
+
+    # ============== Snapshots ==============
+
+    def local_dir(self, repo_id: str) -> Path:
+        return self.root / repo_id.replace("/", "--")
+
+    def _read_manifest(self, target: Path) -> Optional[Dict[str, Any]]:
+        try:
+            with open(target / MANIFEST) as f:
+                return json.load(f)
+        except (OSError, ValueError):
+            return None
+
+    def _advance(self, repo_id: str, nbytes: int, files: int = 0):
+        with self._lock:
+            entry = self.progress.get(repo_id)
+            if entry:
+                entry["bytes_done"] += nbytes
+                entry["files_done"] += files
+
+    def download(self, repo_id: str, revision: str = "main") -> str:
+        """
+        Make a verified local copy of a repo revision.
+
+        Returns:
+            Local directory to pass to ``from_pretrained``
+
+        Raises:
+            ChecksumError: A file failed verification (its partial was removed)
+            DownloadError: The hub is unreachable and there is no local copy,
+                or the repo does not exist
+        """
+        target = self.local_dir(repo_id)
+        manifest = self._read_manifest(target)
+        try:
+            sha, files = self.repo_files(repo_id, revision)
+        except httpx.HTTPError as e:
+            if manifest and manifest.get("revision") == revision:
+                logger.info(f"Hub unreachable ({e}); using local copy of {repo_id}")
+                return str(target)
+            raise DownloadError(f"Cannot reach {self.endpoint} for {repo_id}: {e}")
+
+        if manifest and manifest.get("sha") == sha and all((target / f["path"]).exists() for f in files):
+            return str(target)
+
+        self.cleanup_partials()
+        with self._lock:
+            self.progress[repo_id] = {
+                "repo_id": repo_id,
+                "revision": revision,
+                "sha": sha,
+                "state": "downloading",
+                "files": len(files),
+                "files_done": 0,
+                "bytes_total": sum(f.get("size") or 0 for f in files),
+                "bytes_done": 0,
+                "started_at": datetime.now().isoformat(),
+                "finished_at": None,
+                "error": None,
+            }
+        logger.info(f"Downloading {repo_id}@{sha[:8]}: {len(files)} files")
+
+        errors: List[Exception] = []
+        with ThreadPoolExecutor(max_workers=self.workers, thread_name_prefix="model-download") as pool:
+            futures = [pool.submit(self._download_file, repo_id, sha, f, target) for f in files]
+            for future in futures:
+                try:
+                    future.result()
+                except Exception as e:
+                    errors.append(e)
+
+        with self._lock:
+            entry = self.progress[repo_id]
+            entry["finished_at"] = datetime.now().isoformat()
+            entry["state"] = "failed" if errors else "complete"
+            entry["error"] = str(errors[0]) if errors else None
+        if errors:
+            # A checksum failure is the one to report; transient errors resume next time
+            raise next((e for e in errors if isinstance(e, ChecksumError)), errors[0])
+
+        with open(target / MANIFEST, "w") as f:
+            json.dump({
+                "repo_id": repo_id,
+                "revision": revision,
+                "sha": sha,
+                "files": files,
+                "downloaded_at": datetime.now().isoformat(),
+            }, f, indent=2)
+        logger.info(f"Downloaded {repo_id}@{sha[:8]} to {target}")
+        return str(target)
+
+    def status(self) -> List[Dict[str, Any]]:
+        with self._lock:
+            return [dict(p) for p in self.progress.values()]
+
+
+# Singleton instance
+_model_downloader: Optional[ModelDownloader] = None
+
+
+def get_model_downloader() -> ModelDownloader:
+    """Get or create the model downloader."""
+    global _model_downloader
+    if _model_downloader is None:
+        _model_downloader = ModelDownloader()
+    return _model_downloader
@@ -28,6 +28,7 @@ class OllamaClient:
 
     Endpoints:
     - POST /api/embeddings - Text embeddings
+    - POST /api/embed - Batched text embeddings (with token counts)
     - POST /api/generate - Text generation (for reranking)
     - POST /api/chat - LLM chat
     - GET /api/tags - List models
@@ -78,6 +79,45 @@ class OllamaClient:
                 logger.error(f"Embedding failed: {e}")
                 raise
 
+    async def embed_with_usage(self, texts: List[str], model: str = None) -> Dict[str, Any]:
+        """
+        Embed a batch of texts in one request and report token usage.
+
+        Uses the batched /api/embed endpoint, which returns the prompt token
+        count. Falls back to per-text /api/embeddings on older Ollama servers
+        (token count is then None).
+
+        Args:
+            texts: List of texts to embed
+            model: Optional model name
+
+        Returns:
+            Dict with "embeddings" and "prompt_tokens"
+        """
+        model = model or settings.EMBEDDING_MODEL_NAME
+        embedding_timeout = settings.EMBEDDING_TIMEOUT
+
+        async with httpx.AsyncClient(timeout=embedding_timeout) as client:
+            response = await client.post(
+                f"{self.base_url}/api/embed",
+                json={
+                    "model": model,
+                    "input": texts
+                },
+            )
+            if response.status_code == 404:
+                # /api/embed not available (or model missing) - use legacy path
+                return {
+                    "embeddings": await self.embed(texts, model),
+                    "prompt_tokens": None
+                }
+            response.raise_for_status()
+            data = response.json()
+            return {
+                "embeddings": data["embeddings"],
+                "prompt_tokens": data.get("prompt_eval_count")
+            }
+
     async def rerank(
         self,
         query: str,
@@ -201,10 +241,14 @@ _ollama_client: Optional[OllamaClient] = None
 
 
 def get_inference_client() -> OllamaClient:
-    """Get singleton Ollama client."""
+    """Get singleton Ollama client (bus-backed when remote inference is on)."""
     global _ollama_client
     if _ollama_client is None:
-        _ollama_client = OllamaClient()
+        if settings.get("inference.remote.embed", False) or settings.get("inference.remote.rerank", False):
+            from .remote_client import RemoteInferenceClient
+            _ollama_client = RemoteInferenceClient()
+        else:
+            _ollama_client = OllamaClient()
     return _ollama_client
 
 
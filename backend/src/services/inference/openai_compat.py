@@ -0,0 +1,123 @@
+"""
+OpenAI-compatible embeddings helpers.
+
+Input normalization, batching, token accounting and response shaping for
+POST /api/v1/embeddings, so existing OpenAI SDK clients can point their
+base_url at Rice Search and reuse the configured embedding model.
+"""
+
+import re
+import math
+import base64
+import struct
+import asyncio
+import logging
+from typing import List, Union, Optional, Dict, Any
+
+from src.core.config import settings
+
+logger = logging.getLogger(__name__)
+
+# Rough sub-word split used when the backend does not report token counts
+_TOKEN_PATTERN = re.compile(r"\w{1,4}|[^\w\s]")
+
+
+def normalize_input(value: Union[str, List[Any]]) -> List[str]:
+    """
+    Normalize the OpenAI `input` field to a list of strings.
+
+    Raises:
+        ValueError: For empty input or pre-tokenized (integer) input
+    """
+    if isinstance(value, str):
+        texts = [value]
+    elif isinstance(value, list):
+        if any(not isinstance(v, str) for v in value):
+            raise ValueError("Token array input is not supported; send strings")
+        texts = value
+    else:
+        raise ValueError("input must be a string or a list of strings")
+
+    if not texts:
+        raise ValueError("input must not be empty")
+    if any(not t for t in texts):
+        raise ValueError("input must not contain empty strings")
+    return texts
+
+
+def estimate_tokens(text: str) -> int:
+    """Approximate token count (roughly matches BPE tokenizers on code and prose)."""
+    return len(_TOKEN_PATTERN.findall(text))
+
+
+def make_batches(texts: List[str], batch_size: int) -> List[List[str]]:
+    """Split texts into batches, preserving order."""
+    batch_size = max(1, batch_size)
+    return [texts[i:i + batch_size] for i in range(0, len(texts), batch_size)]
+
+
+def truncate_dimensions(vector: List[float], dimensions: Optional[int]) -> List[float]:
+    """
+    Shorten an embedding (Matryoshka-style) and re-normalize to unit length.
+    """
+    if not dimensions or dimensions >= len(vector):
+        return vector
+    head = vector[:dimensions]
+    norm = math.sqrt(sum(v * v for v in head))
+    return [v / norm for v in head] if norm else head
+
+
+def encode_base64(vector: List[float]) -> str:
+    """Encode as little-endian float32 base64 (OpenAI `encoding_format=base64`)."""
+    return base64.b64encode(struct.pack(f"<{len(vector)}f", *vector)).decode("ascii")
+
+
+async def create_embeddings(
+    texts: List[str],
+    model: Optional[str] = None,
+    dimensions: Optional[int] = None,
+    encoding_format: str = "float",
+) -> Dict[str, Any]:
+    """
+    Embed texts in batches and build an OpenAI-style response.
+
+    Batches run concurrently up to ``embeddings_api.max_concurrent_batches``.
+    """
+    from src.services.inference import get_inference_client
+
+    client = get_inference_client()
+    model = model or settings.EMBEDDING_MODEL_NAME
+    batch_size = int(settings.get("embeddings_api.batch_size", settings.get("models.embedding.batch_size", 32)))
+    semaphore = asyncio.Semaphore(int(settings.get("embeddings_api.max_concurrent_batches", 4)))
+
+    async def _run(batch: List[str]) -> Dict[str, Any]:
+        async with semaphore:
+            result = await client.embed_with_usage(batch, model)
+        tokens = result.get("prompt_tokens")
+        if tokens is None:
+            tokens = sum(estimate_tokens(t) for t in batch)
+        return {"embeddings": result["embeddings"], "tokens": tokens}
+
+    results = await asyncio.gather(*[_run(b) for b in make_batches(texts, batch_size)])
+
+    data = []
+    prompt_tokens = 0
+    for result in results:
+        prompt_tokens += result["tokens"]
+        for vector in result["embeddings"]:
+            vector = truncate_dimensions(vector, dimensions)
+            data.append({
+                "object": "embedding",
+                "index": len(data),
+                "embedding": encode_base64(vector) if encoding_format == "base64" else vector,
+            })
+
+    return {
+        "object": "list",
+        "data": data,
+        "model": model,
+        "usage": {
+            "prompt_tokens": prompt_tokens,
+            "total_tokens": prompt_tokens,
+        },
+    }
@@ -0,0 +1,216 @@
+"""
+Model Precision.
+
+Per-model numeric precision for the local PyTorch models (SPLADE sparse
+encoder and cross-encoder reranker):
+
+- ``fp32``: reference quality, slowest.
+- ``fp16``: half precision on CUDA (falls back to fp32 on CPU, where fp16
+  kernels are slower than fp32).
+- ``int8``: dynamic int8 quantization of the Linear layers on CPU (falls
+  back to fp16 on CUDA). Roughly 2-3x faster on CPU-only servers.
+
+The precision comes from the model registry entry (``precision``, set by an
+admin per model) and otherwise from ``models.<kind>.precision``. The
+benchmark measures latency and how far each precision's output drifts from
+fp32 on the same inputs, so the tradeoff can be checked before switching.
+
+Embeddings served by Ollama are not covered: pick a quantized Ollama tag
+(e.g. ``:q8_0``) instead.
+"""
+import logging
+import math
+import time
+from typing import Any, Callable, Dict, List, Optional
+
+from src.core.config import settings
+
+logger = logging.getLogger(__name__)
+
+PRECISIONS = ("fp32", "fp16", "int8")
+
+# Registry model type -> settings section
+MODEL_KINDS = {
+    "sparse_embedding": "sparse",
+    "reranker": "reranker",
+}
+
+BENCHMARK_TEXTS = [
+    "def parse_config(path): load the YAML settings file and merge environment overrides",
+    "Retry failed HTTP requests with exponential backoff and jitter",
+    "class LRUCache evicts the least recently used entry when capacity is exceeded",
+    "SELECT user_id, COUNT(*) FROM events GROUP BY user_id ORDER BY 2 DESC",
+    "How do I rotate the API signing keys without downtime?",
+    "async fn handle_request(req: Request) -> Result<Response, Error>",
+    "The indexer skips binary files and anything matched by .gitignore",
+    "Configure CORS origins for the admin dashboard",
+]
+
+
+def registry_precision(model_type: str) -> Optional[str]:
+    """Precision set on the active registry model of this type, if any."""
+    try:
+        from src.services.admin.admin_store import get_admin_store
+        for model in get_admin_store().get_models().values():
+            if model.get("type") == model_type and model.get("active", True) and model.get("precision"):
+                return model["precision"]
+    except Exception as e:
+        logger.debug(f"Model registry unavailable for precision lookup: {e}")
+    return None
+
+
+def configured_precision(model_type: str) -> str:
+    """Precision requested for a model type (registry, then settings)."""
+    precision = registry_precision(model_type)
+    if precision is None:
+        kind = MODEL_KINDS.get(model_type, model_type)
+        precision = settings.get(f"models.{kind}.precision", "fp32")
+    if precision not in PRECISIONS:
+        logger.warning(f"Unknown precision {precision!r} for {model_type}, using fp32")
+        return "fp32"
+    return precision
+
+
+def effective_precision(precision: str, device: str) -> str:
+    """Precision that actually runs on ``device``."""
+    if precision == "fp16" and device == "cpu":
+        return "fp32"
+    if precision == "int8" and device != "cpu":
+        # Dynamic quantization only has CPU kernels
+        return "fp16"
+    return precision
+
+
+def apply_precision(model: Any, precision: str, device: str) -> Any:
+    """
+    Convert a loaded torch module (already on ``device``) to a precision.
+
+    Returns:
+        The converted module (int8 returns a new quantized copy)
+    """
+    import torch
+
+    precision = effective_precision(precision, device)
+    if precision == "fp16":
+        return model.half()
+    if precision == "int8":
+        model = model.float()
+        return torch.quantization.quantize_dynamic(model, {torch.nn.Linear}, dtype=torch.qint8)
+    return model.float()
+
+
+def _cosine(a: Dict[int, float], b: Dict[int, float]) -> float:
+    dot = sum(v * b.get(k, 0.0) for k, v in a.items())
+    norm = math.sqrt(sum(v * v for v in a.values())) * math.sqrt(sum(v * v for v in b.values()))
+    return dot / norm if norm else 1.0
+
+
+def sparse_similarity(reference: List[Any], candidate: List[Any]) -> float:
+    """Mean cosine similarity between two lists of sparse vectors."""
+    if not reference:
+        return 1.0
+    total = 0.0
+    for ref, cand in zip(reference, candidate):
+        total += _cosine(dict(zip(ref.indices, ref.values)), dict(zip(cand.indices, cand.values)))
+    return total / len(reference)
+
+
+def ranking_agreement(reference: List[float], candidate: List[float]) -> float:
+    """Fraction of document pairs ordered the same way by both score lists."""
+    pairs = agree = 0
+    for i in range(len(reference)):
+        for j in range(i + 1, len(reference)):
+            pairs += 1
+            if (reference[i] - reference[j]) * (candidate[i] - candidate[j]) >= 0:
+                agree += 1
+    return agree / pairs if pairs else 1.0
+
+
+def _timed(fn: Callable[[], Any], runs: int) -> tuple:
+    fn()  # warm-up (first call pays kernel selection/allocation)
+    started = time.perf_counter()
+    for _ in range(runs):
+        output = fn()
+    return output, (time.perf_counter() - started) / runs * 1000
+
+
+def benchmark(
+    model_type: str,
+    precisions: Optional[List[str]] = None,
+    texts: Optional[List[str]] = None,
+    runs: int = 3,
+) -> Dict[str, Any]:
+    """
+    Compare precisions of a local model on the same inputs.
+
+    Loads a separate copy of the model per precision (the serving instance
+    is untouched), so expect a few seconds and extra memory while it runs.
+
+    Returns:
+        Dict with the device and one row per precision: ``latency_ms`` per
+        batch, ``speedup`` over fp32 and ``quality`` (1.0 = same output as
+        fp32; cosine similarity for sparse vectors, pairwise ranking
+        agreement for the reranker)
+    """
+    from src.core.device import get_device
+
+    if model_type not in MODEL_KINDS:
+        raise ValueError(f"Precision benchmark supports {sorted(MODEL_KINDS)}, not {model_type}")
+    texts = texts or BENCHMARK_TEXTS
+    precisions = [p for p in (precisions or PRECISIONS) if p in PRECISIONS]
+    device = get_device()
+
+    if model_type == "sparse_embedding":
+        from src.services.retrieval.splade_encoder import SpladeEncoder
+
+        def run_with(precision: str):
+            encoder = SpladeEncoder(device=device, precision=precision)
+            try:
+                return _timed(lambda: encoder.encode(texts), runs)
+            finally:
+                encoder._free_model()
+        compare = sparse_similarity
+    else:
+        from src.services.inference.local_reranker import LocalReranker
+
+        query = "how are failed requests retried"
+
+        def run_with(precision: str):
+            reranker = LocalReranker(precision=precision)
+            reranker._load_model()
+            pairs = [[query, text] for text in texts]
+            return _timed(lambda: [float(s) for s in reranker.model.predict(pairs)], runs)
+        compare = ranking_agreement
+
+    reference, reference_ms = run_with("fp32")
+    rows = []
+    for precision in precisions:
+        if precision == "fp32":
+            output, latency = reference, reference_ms
+        else:
+            output, latency = run_with(precision)
+        rows.append({
+            "precision": precision,
+            "effective_precision": effective_precision(precision, device),
+            "latency_ms": round(latency, 1),
+            "speedup": round(reference_ms / latency, 2) if latency else None,
+            "quality": round(compare(reference, output), 4),
+        })
+    return {
+        "model_type": model_type,
+        "device": device,
+        "texts": len(texts),
+        "configured": configured_precision(model_type),
+        "results": rows,
+    }
+
+
+def reload_model(model_type: str):
+    """Reload this process's copy of a model so a new precision takes effect."""
+    if model_type == "sparse_embedding":
+        from src.services.retrieval import splade_encoder
+        if splade_encoder._splade_encoder is not None:
+            splade_encoder.reload_splade_encoder(model_id=splade_encoder._splade_encoder.model_id)
+    elif model_type == "reranker":
+        from src.services.inference.local_reranker import reload_local_reranker
+        reload_local_reranker()
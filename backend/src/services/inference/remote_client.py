@@ -0,0 +1,99 @@
+"""
+Remote Inference Client.
+
+Sends embedding, sparse encoding and/or rerank requests over the event bus
+to ML workers (``src.worker.ml_worker``) instead of running them from this
+process, so the API server stays lightweight while models run on GPU
+machines. Enabled per task with ``inference.remote.embed``,
+``inference.remote.sparse`` and ``inference.remote.rerank``; everything else
+(chat, model management) still goes to Ollama directly.
+"""
+import asyncio
+import logging
+from typing import List, Dict, Any, Optional
+
+from src.core.config import settings
+from src.services.events.bus import get_event_bus
+from .ollama_client import OllamaClient
+
+logger = logging.getLogger(__name__)
+
+EMBED_TOPIC = "inference.embed"
+SPARSE_TOPIC = "inference.sparse"
+RERANK_TOPIC = "inference.rerank"
+
+
+def remote_enabled(task: str) -> bool:
+    return bool(settings.get(f"inference.remote.{task}", False))
+
+
+def remote_timeout() -> float:
+    return float(settings.get("inference.remote.timeout_seconds", 60))
+
+
+class RemoteInferenceClient(OllamaClient):
+    """Ollama client that delegates embed/rerank to bus workers when configured."""
+
+    async def _request(self, topic: str, payload: Dict[str, Any]) -> Dict[str, Any]:
+        return await asyncio.to_thread(
+            get_event_bus().request, topic, payload, remote_timeout()
+        )
+
+    async def embed(self, texts: List[str], model: str = None) -> List[List[float]]:
+        if not remote_enabled("embed"):
+            return await super().embed(texts, model)
+        result = await self._request(EMBED_TOPIC, {"texts": texts, "model": model})
+        return result["embeddings"]
+
+    async def embed_with_usage(self, texts: List[str], model: str = None) -> Dict[str, Any]:
+        if not remote_enabled("embed"):
+            return await super().embed_with_usage(texts, model)
+        return await self._request(EMBED_TOPIC, {"texts": texts, "model": model, "usage": True})
+
+    async def rerank(
+        self,
+        query: str,
+        documents: List[str],
+        top_n: int = None,
+        model: str = None,
+    ) -> List[Dict[str, Any]]:
+        if not remote_enabled("rerank"):
+            return await super().rerank(query, documents, top_n, model)
+        result = await self._request(
+            RERANK_TOPIC, {"query": query, "documents": documents, "top_n": top_n}
+        )
+        return result["results"]
+
+
+class RemoteSparseEncoder:
+    """SPLADE encoder proxy: same encode API, models run on ML workers."""
+
+    model_id = "remote"
+    device = "remote"
+
+    def encode(self, texts: List[str]) -> List[Any]:
+        from src.services.retrieval.splade_encoder import SparseVector
+
+        if not texts:
+            return []
+        result = get_event_bus().request(SPARSE_TOPIC, {"texts": texts}, remote_timeout())
+        return [SparseVector(indices=v["indices"], values=v["values"]) for v in result["vectors"]]
+
+    def encode_single(self, text: str) -> Any:
+        return self.encode([text])[0]
+
+    @property
+    def is_gpu(self) -> bool:
+        return False
+
+
+# Singleton instance
+_remote_sparse_encoder: Optional[RemoteSparseEncoder] = None
+
+
+def get_remote_sparse_encoder() -> RemoteSparseEncoder:
+    """Get singleton remote sparse encoder."""
+    global _remote_sparse_encoder
+    if _remote_sparse_encoder is None:
+        _remote_sparse_encoder = RemoteSparseEncoder()
+    return _remote_sparse_encoder
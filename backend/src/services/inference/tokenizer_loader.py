@@ -0,0 +1,134 @@
+"""
+Tokenizer Loading.
+
+Not every model repository ships a ``tokenizer.json``. Several useful
+rerankers and multilingual encoders only have the original tokenizer files,
+so the format is detected from the downloaded model assets:
+
+- ``tokenizer.json``: HuggingFace fast tokenizer, loaded as-is.
+- ``sentencepiece``: ``*.model`` files (``sentencepiece.bpe.model``,
+  ``spiece.model``, ``tokenizer.model``). Needs the ``sentencepiece``
+  package.
+- ``bpe``: ``vocab.json`` + ``merges.txt`` (GPT-2/RoBERTa byte-level BPE).
+- ``wordpiece``: ``vocab.txt`` (BERT).
+
+``AutoTokenizer`` is tried first, since ``tokenizer_config.json`` usually
+names the right class. When it can't build a tokenizer (no config, a custom
+class, or a failed sentencepiece → fast conversion), the slow sentencepiece
+tokenizer or the family tokenizer for the detected files is used instead,
+with the special tokens from ``special_tokens_map.json``.
+
+Detection needs a local directory (a ``models.download`` snapshot or a
+configured path); hub ids that weren't downloaded go straight to
+``AutoTokenizer``.
+"""
+import json
+import logging
+import os
+from typing import Any, Dict, Optional
+
+logger = logging.getLogger(__name__)
+
+FORMATS = ("tokenizer.json", "sentencepiece", "bpe", "wordpiece")
+
+# Sentencepiece model file names, most specific first
+SENTENCEPIECE_FILES = ("sentencepiece.bpe.model", "spiece.model", "tokenizer.model", "sentencepiece.model")
+
+SPECIAL_TOKENS = ("bos_token", "eos_token", "unk_token", "sep_token", "pad_token", "cls_token", "mask_token")
+
+
+def _sentencepiece_file(path: str) -> Optional[str]:
+    for name in SENTENCEPIECE_FILES:
+        if os.path.isfile(os.path.join(path, name)):
+            return os.path.join(path, name)
+    others = sorted(n for n in os.listdir(path) if n.endswith(".model"))
+    return os.path.join(path, others[0]) if others else None
+
+
+def detect_format(source: str) -> Optional[str]:
+    """
+    Tokenizer format of a model directory.
+
+    Returns:
+        One of ``FORMATS``, or None for hub ids and directories without
+        tokenizer files
+    """
+    if not os.path.isdir(source):
+        return None
+
+    def has(name: str) -> bool:
+        return os.path.isfile(os.path.join(source, name))
+
+    if has("tokenizer.json"):
+        return "tokenizer.json"
+    if _sentencepiece_file(source):
+        return "sentencepiece"
+    if has("vocab.json") and has("merges.txt"):
+        return "bpe"
+    if has("vocab.txt"):
+        return "wordpiece"
+    return None
+
+
+def _special_tokens(path: str) -> Dict[str, str]:
+    """Special tokens from ``special_tokens_map.json`` (strings or AddedToken dicts)."""
+    try:
+        with open(os.path.join(path, "special_tokens_map.json")) as f:
+            data = json.load(f)
+    except (OSError, ValueError):
+        return {}
+    tokens = {}
+    for key in SPECIAL_TOKENS:
+        value = data.get(key)
+        if isinstance(value, dict):
+            value = value.get("content")
+        if isinstance(value, str):
+            tokens[key] = value
+    return tokens
+
+
+def tokenizer_args(source: str) -> Dict[str, Any]:
+    """
+    ``from_pretrained`` arguments for loaders that build the tokenizer
+    themselves (sentence-transformers): sentencepiece-only models use the
+    slow tokenizer instead of a conversion that may fail.
+    """
+    return {"use_fast": False} if detect_format(source) == "sentencepiece" else {}
+
+
+def _from_files(source: str, fmt: str):
+    """Family tokenizer built straight from the detected files."""
+    special = _special_tokens(source)
+    if fmt == "sentencepiece":
+        from transformers import XLMRobertaTokenizer
+        # <s> A </s></s> B </s>: the layout of most sentencepiece cross-encoders
+        return XLMRobertaTokenizer(vocab_file=_sentencepiece_file(source), **special)
+    if fmt == "bpe":
+        from transformers import RobertaTokenizerFast
+        return RobertaTokenizerFast(
+            vocab_file=os.path.join(source, "vocab.json"),
+            merges_file=os.path.join(source, "merges.txt"),
+            **special,
+        )
+    from transformers import BertTokenizerFast
+    return BertTokenizerFast(vocab_file=os.path.join(source, "vocab.txt"), **special)
+
+
+def load_tokenizer(source: str, trust_remote_code: bool = False):
+    """
+    Tokenizer for a model directory or hub id, whatever files it ships.
+
+    Raises:
+        The ``AutoTokenizer`` error when there is no fallback for the
+        directory's files
+    """
+    from transformers import AutoTokenizer
+
+    fmt = detect_format(source)
+    try:
+        return AutoTokenizer.from_pretrained(source, trust_remote_code=trust_remote_code, **tokenizer_args(source))
+    except Exception as e:
+        if fmt in (None, "tokenizer.json"):
+            raise
+        logger.info(f"AutoTokenizer could not load {source} ({e}); building a {fmt} tokenizer from its files")
+        return _from_files(source, fmt)
@@ -0,0 +1,181 @@
+"""
+Inference Watchdog.
+
+Applies per-call timeouts to ML inference (dense embedding, sparse encoding,
+reranking), counts timeouts, and resets the underlying model handler after
+repeated consecutive timeouts so a wedged model or client is rebuilt instead
+of timing out every request until restart. Each kind also has a circuit
+breaker (``src.core.circuit_breaker``) that fails calls fast while the
+model keeps failing.
+"""
+
+import asyncio
+import logging
+from typing import Any, Awaitable, Callable, Dict, Optional
+
+from src.core.circuit_breaker import get_breakers
+from src.core.config import settings
+
+logger = logging.getLogger(__name__)
+
+EMBED = "embed"
+SPARSE = "sparse"
+RERANK = "rerank"
+KINDS = (EMBED, SPARSE, RERANK)
+
+
+class InferenceTimeoutError(Exception):
+    """Raised when an inference call exceeds its timeout."""
+
+    def __init__(self, kind: str, timeout: float):
+        super().__init__(f"{kind} inference timed out after {timeout:.1f}s")
+        self.kind = kind
+        self.timeout = timeout
+
+
+def _reset_embed():
+    from src.services.inference import ollama_client
+    ollama_client._ollama_client = None
+
+
+def _reset_sparse():
+    from src.services.retrieval import splade_encoder, bm42_encoder
+    from src.services.search.retriever import Retriever
+    splade_encoder.reload_splade_encoder()
+    bm42_encoder._bm42_encoder = None
+    # Drop encoders cached on the shared retriever
+    retriever = Retriever._multi_retriever
+    if retriever is not None:
+        retriever._splade_encoder = None
+        retriever._bm42_encoder = None
+
+
+def _reset_rerank():
+    from src.services.inference import local_reranker
+    local_reranker._local_reranker = None
+
+
+class InferenceWatchdog:
+    """Timeout enforcement and handler reset for inference calls."""
+
+    def __init__(self):
+        self._consecutive: Dict[str, int] = {k: 0 for k in KINDS}
+        self._handlers: Dict[str, Callable[[], None]] = {
+            EMBED: _reset_embed,
+            SPARSE: _reset_sparse,
+            RERANK: _reset_rerank,
+        }
+
+    def register_reset(self, kind: str, handler: Callable[[], None]):
+        """Register the function that rebuilds the handler for a kind."""
+        self._handlers[kind] = handler
+        self._consecutive.setdefault(kind, 0)
+
+    def timeout_for(self, kind: str) -> float:
+        """Configured timeout in seconds for an inference kind."""
+        return float(settings.get(f"inference.timeouts.{kind}_seconds", 30.0))
+
+    @property
+    def max_consecutive_timeouts(self) -> int:
+        return int(settings.get("inference.watchdog.max_consecutive_timeouts", 3))
+
+    async def run(
+        self,
+        kind: str,
+        call: Callable[[], Awaitable[Any]],
+        timeout: Optional[float] = None,
+    ) -> Any:
+        """
+        Run an inference call under a timeout.
+
+        Args:
+            kind: "embed", "sparse" or "rerank"
+            call: Zero-arg factory returning the awaitable to run
+            timeout: Per-request override (default from settings)
+
+        Raises:
+            InferenceTimeoutError: If the call does not finish in time
+            CircuitOpenError: The kind's circuit breaker is open (call not made)
+        """
+        timeout = timeout if timeout is not None else self.timeout_for(kind)
+        breaker = get_breakers().get(kind)
+        breaker.before_call()
+        try:
+            result = await asyncio.wait_for(call(), timeout=timeout)
+        except asyncio.TimeoutError:
+            self._on_timeout(kind, timeout)
+            error = InferenceTimeoutError(kind, timeout)
+            breaker.record_failure(error)
+            raise error
+        except Exception as e:
+            breaker.record_failure(e)
+            raise
+
+        self._consecutive[kind] = 0
+        breaker.record_success()
+        return result
+
+    def _on_timeout(self, kind: str, timeout: float):
+        self._consecutive[kind] = self._consecutive.get(kind, 0) + 1
+        count = self._consecutive[kind]
+        logger.warning(f"{kind} inference timed out after {timeout:.1f}s ({count} consecutive)")
+
+        store = self._store()
+        if store:
+            store.increment_counter(f"inference_timeouts_{kind}")
+
+        if count >= self.max_consecutive_timeouts:
+            self.reset(kind, reason=f"{count} consecutive timeouts")
+
+    def reset(self, kind: str, reason: str = "manual"):
+        """Rebuild the handler for a kind and raise an alert."""
+        self._consecutive[kind] = 0
+        handler = self._handlers.get(kind)
+        try:
+            if handler:
+                handler()
+            logger.error(f"Watchdog reset {kind} handler: {reason}")
+        except Exception as e:
+            logger.error(f"Watchdog failed to reset {kind} handler: {e}")
+
+        store = self._store()
+        if store:
+            store.increment_counter(f"inference_resets_{kind}")
+            store.log_audit("inference_watchdog_reset", f"{kind} handler reset: {reason}", "watchdog")
+            store.raise_alert(
+                severity="critical",
+                source=f"inference.{kind}",
+                message=f"{kind} inference handler reset after {reason}",
+            )
+
+    def get_stats(self) -> Dict[str, Dict[str, int]]:
+        """Timeout/reset counters and current consecutive timeouts per kind."""
+        store = self._store()
+        return {
+            kind: {
+                "timeouts": store.get_counter(f"inference_timeouts_{kind}") if store else 0,
+                "resets": store.get_counter(f"inference_resets_{kind}") if store else 0,
+                "consecutive_timeouts": self._consecutive.get(kind, 0),
+                "timeout_seconds": self.timeout_for(kind),
+            }
+            for kind in self._consecutive
+        }
+
+    @staticmethod
+    def _store():
+        try:
+            from src.services.admin.admin_store import get_admin_store
+            return get_admin_store()
+        except Exception:
+            return None
+
+
+# Singleton instance
+_watchdog: Optional[InferenceWatchdog] = None
+
+def get_inference_watchdog() -> InferenceWatchdog:
+    """Get global inference watchdog instance."""
+    global _watchdog
+    if _watchdog is None:
+        _watchdog = InferenceWatchdog()
+    return _watchdog
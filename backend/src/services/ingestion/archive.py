@@ -0,0 +1,187 @@
+"""
+Archive Ingestion.
+
+Indexes a zip, tar or tar.gz upload as one batch, for CI systems that
+would rather post a build artifact than install the CLI
+(``POST /api/v1/stores/{id}/ingest/archive``).
+
+Extraction is defensive: archives with absolute or ``..`` member paths are
+refused outright, links and special files are skipped, and the member
+count and extracted size are capped (``indexing.archive``), counting the
+bytes actually written so members that lie about their size can't get
+past the limit. Only files the indexer supports are extracted; they go
+through the normal indexer, so the store's exclusion policy applies.
+"""
+
+import logging
+import os
+import shutil
+import stat
+import tarfile
+import tempfile
+import zipfile
+from typing import IO, Any, Callable, Dict, List, Optional, Tuple
+
+from src.core.config import settings
+from src.services.webhooks.git import is_indexable
+
+logger = logging.getLogger(__name__)
+
+ZIP = "zip"
+TAR = "tar"
+
+MB = 1024 * 1024
+COPY_BUFFER = 64 * 1024
+
+
+class ArchiveError(ValueError):
+    """An archive that is unreadable, unsafe or over the limits."""
+
+
+def archive_format(head: bytes) -> Optional[str]:
+    """``zip`` or ``tar`` (plain or gzipped) from an upload's first 512 bytes."""
+    if head.startswith((b"PK\x03\x04", b"PK\x05\x06")):
+        return ZIP
+    if head.startswith(b"\x1f\x8b") or head[257:262] == b"ustar":
+        return TAR
+    return None
+
+
+def member_path(name: str) -> str:
+    """
+    Normalized relative path of an archive member.
+
+    Raises:
+        ArchiveError: absolute paths, drive letters or ``..`` components
+    """
+    path = name.replace("\\", "/")
+    parts = [p for p in path.split("/") if p not in ("", ".")]
+    if path.startswith("/") or (parts and len(parts[0]) == 2 and parts[0][1] == ":") or ".." in parts:
+        raise ArchiveError(f"Unsafe path in archive: '{name}'")
+    return "/".join(parts)
+
+
+def normalize_prefix(prefix: Optional[str]) -> str:
+    """Stored path prefix for the archive's files ('' for none)."""
+    return member_path(prefix or "").strip("/")
+
+
+class Extraction:
+    """Writes members under ``dest`` while enforcing the limits."""
+
+    def __init__(self, dest: str, max_files: int, max_bytes: int):
+        self.dest = os.path.realpath(dest)
+        self.max_files = max_files
+        self.max_bytes = max_bytes
+        self.files: List[str] = []
+        self.skipped = 0
+        self.written = 0
+
+    def add(self, name: str, declared_size: int, source: Callable[[], IO[bytes]]):
+        path = member_path(name)
+        if not path or not is_indexable(path):
+            self.skipped += 1
+            return
+        if self.max_files and len(self.files) >= self.max_files:
+            raise ArchiveError(f"Archive has more than {self.max_files} files (indexing.archive.max_files)")
+        if self.max_bytes and self.written + declared_size > self.max_bytes:
+            raise ArchiveError(self._too_large())
+
+        target = os.path.realpath(os.path.join(self.dest, path))
+        if not target.startswith(self.dest + os.sep):
+            raise ArchiveError(f"Unsafe path in archive: '{name}'")
+        os.makedirs(os.path.dirname(target), exist_ok=True)
+        with source() as src, open(target, "wb") as out:
+            while True:
+                block = src.read(COPY_BUFFER)
+                if not block:
+                    break
+                self.written += len(block)
+                if self.max_bytes and self.written > self.max_bytes:
+                    raise ArchiveError(self._too_large())
+                out.write(block)
+        if path not in self.files:
+            self.files.append(path)
+
+    def _too_large(self) -> str:
+        return f"Archive extracts to more than {self.max_bytes // MB} MB (indexing.archive.max_extracted_mb)"
+
+
+def extract(archive_path: str, dest: str) -> Tuple[List[str], int]:
+    """
+    Extract the indexable files of a zip or tar archive into ``dest``.
+
+    Returns:
+        (relative paths extracted, members skipped)
+
+    Raises:
+        ArchiveError: unknown format, unsafe paths or limits exceeded
+    """
+    extraction = Extraction(
+        dest,
+        max_files=int(settings.get("indexing.archive.max_files", 20000)),
+        max_bytes=int(settings.get("indexing.archive.max_extracted_mb", 2048)) * MB,
+    )
+    with open(archive_path, "rb") as f:
+        kind = archive_format(f.read(512))
+
+    try:
+        if kind == ZIP:
+            with zipfile.ZipFile(archive_path) as archive:
+                for info in archive.infolist():
+                    if info.is_dir():
+                        continue
+                    # Symlinks (unix mode in the high bits) are never extracted
+                    if stat.S_ISLNK(info.external_attr >> 16):
+                        extraction.skipped += 1
+                        continue
+                    extraction.add(info.filename, info.file_size, lambda info=info: archive.open(info))
+        elif kind == TAR:
+            with tarfile.open(archive_path, "r:*") as archive:
+                for member in archive:
+                    if member.isdir():
+                        continue
+                    # Symlinks, hard links and devices are never extracted
+                    if not member.isfile():
+                        extraction.skipped += 1
+                        continue
+                    extraction.add(member.name, member.size, lambda member=member: archive.extractfile(member))
+        else:
+            raise ArchiveError("Not a zip, tar or tar.gz archive")
+    except (zipfile.BadZipFile, tarfile.TarError, EOFError, OSError) as e:
+        raise ArchiveError(f"Unreadable archive: {e}")
+    return extraction.files, extraction.skipped
+
+
+def ingest_archive(
+    archive_path: str,
+    store_id: str,
+    prefix: str = "",
+    qdrant_client=None,
+    progress: Optional[Callable[[int, int], None]] = None,
+) -> Dict[str, Any]:
+    """Extract an uploaded archive, index its files and remove both."""
+    from src.db.qdrant import get_qdrant_client
+    from src.services.ingestion.indexer import Indexer, ingest_local_files
+
+    workdir = tempfile.mkdtemp(prefix="rice-archive-", dir=settings.get("indexing.archive.work_dir") or None)
+    try:
+        files, skipped = extract(archive_path, workdir)
+        indexer = Indexer(qdrant_client=qdrant_client or get_qdrant_client())
+        outcome = ingest_local_files(indexer, workdir, files, prefix, store_id, progress)
+    finally:
+        shutil.rmtree(workdir, ignore_errors=True)
+        if os.path.exists(archive_path):
+            os.remove(archive_path)
+
+    result = {
+        "store": store_id,
+        "prefix": prefix,
+        "files": len(files),
+        "indexed": len(outcome["indexed"]),
+        "skipped": len(outcome["skipped"]) + skipped,
+        "failed": len(outcome["failed"]),
+        "failures": outcome["failed"][:50],
+    }
+    logger.info(f"Archive ingest into {store_id}: {len(files)} files, {result['indexed']} indexed")
+    return result
@@ -0,0 +1,158 @@
+"""
+Chunk Redirects.
+
+Chunk IDs are content-addressed (``hashing.chunk_id``), so editing a file
+gives its changed chunks new IDs and links saved from the web UI or an
+editor would 404. When a file is re-indexed, every old chunk that did not
+survive is mapped to the nearest chunk of the new copy:
+
+1. A chunk with the same content (moved within the file)
+2. The chunk overlapping most of its old line range
+3. The chunk at the closest position
+
+    rice:chunk_redirects:<store>  <old chunk id> -> {"to": <chunk id>, "at": <epoch>}
+
+Chunk lookups that miss follow the map (through several edits if need
+be). Entries expire after ``indexing.chunk_redirects.retention_days``;
+deleting a file leaves nothing to redirect to.
+"""
+
+import json
+import logging
+import time
+from typing import Any, Dict, List, Optional
+
+import redis
+
+from src.core.config import settings
+
+logger = logging.getLogger(__name__)
+
+PAYLOAD_FIELDS = ["chunk_index", "content_hash", "start_line", "end_line"]
+
+MAX_HOPS = 10
+
+
+def _overlap(a: Dict[str, Any], b: Dict[str, Any]) -> int:
+    if None in (a.get("start_line"), a.get("end_line"), b.get("start_line"), b.get("end_line")):
+        return 0
+    return max(0, min(a["end_line"], b["end_line"]) - max(a["start_line"], b["start_line"]) + 1)
+
+
+def nearest(old: Dict[str, Any], new: List[Dict[str, Any]]) -> Optional[str]:
+    """ID of the new chunk an old chunk's links should go to (None: no chunks left)."""
+    if not new:
+        return None
+    if old.get("content_hash"):
+        for chunk in new:
+            if chunk.get("content_hash") == old["content_hash"]:
+                return chunk["chunk_id"]
+    index = old.get("chunk_index") or 0
+    best = max(new, key=lambda c: (_overlap(old, c), -abs((c.get("chunk_index") or 0) - index)))
+    return best["chunk_id"]
+
+
+def plan_redirects(old: List[Dict[str, Any]], new: List[Dict[str, Any]]) -> Dict[str, str]:
+    """old chunk id -> new chunk id for every old chunk that is gone."""
+    current = {c["chunk_id"] for c in new}
+    redirects = {}
+    for chunk in old:
+        if chunk["chunk_id"] in current:
+            continue
+        target = nearest(chunk, new)
+        if target:
+            redirects[chunk["chunk_id"]] = target
+    return redirects
+
+
+class ChunkRedirects:
+    """Per-store map from replaced chunk IDs to their successors."""
+
+    KEY_PREFIX = "rice:chunk_redirects"
+
+    def __init__(self, redis_client=None):
+        self._redis = redis_client
+
+    @property
+    def redis(self) -> redis.Redis:
+        """Lazy Redis connection."""
+        if self._redis is None:
+            self._redis = redis.from_url(settings.REDIS_URL, decode_responses=True)
+        return self._redis
+
+    @property
+    def enabled(self) -> bool:
+        return bool(settings.get("indexing.chunk_redirects.enabled", True))
+
+    @property
+    def retention_seconds(self) -> float:
+        return float(settings.get("indexing.chunk_redirects.retention_days", 90)) * 86400
+
+    def _key(self, org_id: str) -> str:
+        return f"{self.KEY_PREFIX}:{org_id}"
+
+    def record(self, org_id: str, old: List[Dict[str, Any]], new: List[Dict[str, Any]]) -> int:
+        """
+        Redirect a re-indexed file's replaced chunks (never fails indexing).
+
+        Returns:
+            Number of redirects recorded
+        """
+        if not self.enabled or not old:
+            return 0
+        redirects = plan_redirects(old, new)
+        try:
+            pipe = self.redis.pipeline()
+            # IDs that are live again (a revert) must not redirect
+            live = [c["chunk_id"] for c in new]
+            if live:
+                pipe.hdel(self._key(org_id), *live)
+            if redirects:
+                now = time.time()
+                pipe.hset(self._key(org_id), mapping={
+                    old_id: json.dumps({"to": new_id, "at": now}) for old_id, new_id in redirects.items()
+                })
+            pipe.execute()
+        except Exception as e:
+            logger.warning(f"Failed to record chunk redirects for {org_id}: {e}")
+            return 0
+        return len(redirects)
+
+    def resolve(self, org_id: str, chunk_id: str) -> Optional[str]:
+        """Current ID of a replaced chunk, None if it was never redirected."""
+        if not self.enabled:
+            return None
+        cutoff = time.time() - self.retention_seconds
+        seen = {chunk_id}
+        current = None
+        try:
+            for _ in range(MAX_HOPS):
+                data = self.redis.hget(self._key(org_id), current or chunk_id)
+                if not data:
+                    break
+                entry = json.loads(data)
+                if entry.get("at", 0) < cutoff:
+                    self.redis.hdel(self._key(org_id), current or chunk_id)
+                    break
+                if entry["to"] in seen:
+                    break
+                seen.add(entry["to"])
+                current = entry["to"]
+        except Exception as e:
+            logger.debug(f"Chunk redirect lookup for {chunk_id} failed: {e}")
+        return current
+
+    def drop(self, org_id: str):
+        """Forget a purged store's redirects."""
+        self.redis.delete(self._key(org_id))
+
+
+# Singleton instance
+_chunk_redirects: Optional[ChunkRedirects] = None
+
+def get_chunk_redirects() -> ChunkRedirects:
+    """Get global chunk redirects instance."""
+    global _chunk_redirects
+    if _chunk_redirects is None:
+        _chunk_redirects = ChunkRedirects()
+    return _chunk_redirects
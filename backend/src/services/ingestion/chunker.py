@@ -15,18 +15,26 @@ class DocumentChunker:
         self.splitter = RecursiveCharacterTextSplitter(
             chunk_size=chunk_size,
             chunk_overlap=chunk_overlap,
-            separators=["\n\n", "\n", " ", ""]
+            separators=["\n\n", "\n", " ", ""],
+            add_start_index=True
         )
 
     def chunk_text(self, text: str, metadata: Dict) -> List[Dict]:
         """
         Splits text into chunks and attaches metadata to each.
         Returns list of payload dicts for Qdrant.
+
+        Each chunk gets its 1-based ``start_line``/``end_line`` in the text
+        (privacy-mode clients read results from their local copy by line).
         """
         docs = self.splitter.create_documents([text], metadatas=[metadata])
         
         chunks = []
         for i, doc in enumerate(docs):
+            start = doc.metadata.pop("start_index", -1)
+            if start is not None and start >= 0:
+                doc.metadata["start_line"] = text.count("\n", 0, start) + 1
+                doc.metadata["end_line"] = doc.metadata["start_line"] + doc.page_content.count("\n")
             chunks.append({
                 "content": doc.page_content,
                 "metadata": doc.metadata,
@@ -0,0 +1,114 @@
+"""
+Per-Store Collection Tuning.
+
+Stores normally share one collection created with Qdrant's defaults. A store
+created with a ``collection_config`` gets its own collection built with the
+given shard count, replication factor, on-disk payload and HNSW parameters
+(``hnsw_m``, ``hnsw_ef_construct``), so large stores can be tuned without
+touching the others.
+
+Everything but the shard count can be changed later through Qdrant's
+collection update. Shard count is fixed once a collection exists, and the
+shared collection is never tuned per store; both take effect when the store
+moves to a new collection (``POST /api/v1/stores/{id}/migrate``), which is
+created with the store's config.
+"""
+
+import logging
+from typing import Any, Dict, List, Optional
+
+from qdrant_client.models import (
+    CollectionParamsDiff,
+    Distance,
+    HnswConfigDiff,
+    SparseIndexParams,
+    SparseVectorParams,
+    VectorParams,
+)
+
+from src.core.config import settings
+
+logger = logging.getLogger(__name__)
+
+FIELDS = ("shard_number", "replication_factor", "on_disk_payload", "hnsw_m", "hnsw_ef_construct")
+# Only settable when a collection is created
+CREATION_ONLY = ("shard_number",)
+
+
+def dedicated_collection(store_id: str) -> str:
+    """Name of the collection a tuned store is created with."""
+    return f"{settings.COLLECTION_PREFIX}_{store_id}"
+
+
+def _hnsw(config: Dict[str, Any]) -> Optional[HnswConfigDiff]:
+    hnsw = {k: config[f"hnsw_{k}"] for k in ("m", "ef_construct") if config.get(f"hnsw_{k}") is not None}
+    return HnswConfigDiff(**hnsw) if hnsw else None
+
+
+def create_collection(qdrant, name: str, dimension: int, config: Optional[Dict[str, Any]] = None):
+    """
+    Create a chunk collection (dense + doc + splade + bm42 vectors,
+    payload indexes) with a store's collection config.
+    """
+    from src.services.ingestion.doc_vectors import DOC_VECTOR
+    config = config or {}
+    params = {k: config[k] for k in ("shard_number", "replication_factor", "on_disk_payload") if config.get(k) is not None}
+    hnsw = _hnsw(config)
+    if hnsw is not None:
+        params["hnsw_config"] = hnsw
+
+    logger.info(f"Creating collection {name} ({dimension} dims, {params or 'defaults'})")
+    qdrant.create_collection(
+        collection_name=name,
+        vectors_config={
+            "dense": VectorParams(size=dimension, distance=Distance.COSINE),
+            DOC_VECTOR: VectorParams(size=dimension, distance=Distance.COSINE),
+        },
+        sparse_vectors_config={
+            "splade": SparseVectorParams(index=SparseIndexParams(on_disk=False)),
+            "bm42": SparseVectorParams(index=SparseIndexParams(on_disk=False)),
+        },
+        **params,
+    )
+    from src.services.ingestion.indexer import PAYLOAD_INDEXES
+    for field_name, schema in PAYLOAD_INDEXES.items():
+        try:
+            qdrant.create_payload_index(collection_name=name, field_name=field_name, field_schema=schema)
+        except Exception as e:
+            logger.warning(f"Failed to create payload index on {field_name}: {e}")
+
+
+def update_collection(qdrant, name: str, changes: Dict[str, Any]) -> List[str]:
+    """
+    Apply the updatable part of a config change to an existing collection.
+
+    Returns:
+        The fields applied (HNSW changes rebuild the index in the background)
+    """
+    applied = [k for k in FIELDS if k not in CREATION_ONLY and changes.get(k) is not None]
+    if not applied:
+        return []
+    params = {k: changes[k] for k in ("replication_factor", "on_disk_payload") if changes.get(k) is not None}
+    qdrant.update_collection(
+        collection_name=name,
+        collection_params=CollectionParamsDiff(**params) if params else None,
+        hnsw_config=_hnsw(changes),
+    )
+    logger.info(f"Updated collection {name}: {', '.join(applied)}")
+    return applied
+
+
+def live_config(qdrant, name: str) -> Optional[Dict[str, Any]]:
+    """A collection's current settings, None if it can't be read."""
+    try:
+        info = qdrant.get_collection(name).config
+    except Exception as e:
+        logger.debug(f"Could not read collection {name}: {e}")
+        return None
+    return {
+        "shard_number": getattr(info.params, "shard_number", None),
+        "replication_factor": getattr(info.params, "replication_factor", None),
+        "on_disk_payload": getattr(info.params, "on_disk_payload", None),
+        "hnsw_m": getattr(info.hnsw_config, "m", None),
+        "hnsw_ef_construct": getattr(info.hnsw_config, "ef_construct", None),
+    }
@@ -0,0 +1,195 @@
+"""
+Natural-Language Chunk Vectors.
+
+A chunk's ``dense`` vector embeds its code. Questions asked in prose ("where
+do failed uploads get retried") are closer to the prose around the code
+than to the code itself, so chunks also get a ``doc`` vector (same model)
+embedding their natural-language description:
+
+- code: docstrings and comments, after the chunk's symbol names split into
+  words (``retryUpload`` -> "retry upload")
+- docs (Markdown, reStructuredText, ...): the text is prose already, so the
+  ``dense`` vector is reused
+- code without enough prose (``indexing.doc_vectors.min_chars``): no
+  ``doc`` vector; the chunk is found through its code vector only
+- chunks with an LLM summary (see summaries): the summary, then the
+  above (docs then get their own ``doc`` vector)
+
+Searches run the query against both vectors and blend them in fusion with
+``search.doc_vectors.weights`` (``code``/``doc``; per store as the search
+config's ``vector_weights``, per request as ``vector_weights``).
+
+Collections get the ``doc`` vector when they are created. Qdrant can't add
+a named vector to an existing collection, so older collections keep
+working with code vectors only until the store moves to a new collection
+(``POST /api/v1/stores/{id}/migrate``); writes drop vectors a collection
+doesn't have.
+"""
+
+import logging
+import re
+import threading
+import time
+from typing import Any, Dict, Iterable, List, Optional, Set
+
+from src.core.config import settings
+from src.services.ingestion.natural_language import is_docs_chunk
+from src.services.retrieval.analyzer import extract_comments, split_identifier
+
+logger = logging.getLogger(__name__)
+
+DOC_VECTOR = "doc"
+
+# Triple-quoted strings: Python docstrings, Elixir @doc, Julia, Kotlin raw strings
+_TRIPLE_QUOTED = re.compile(r'("""|\'\'\')(.*?)\1', re.DOTALL)
+# Comment markers and decoration at the start/end of comment lines
+_MARKERS = re.compile(r"^\s*(?:/\*+|\*+/|\*|//+!?|#+!?|--+|\{-|-\}|=begin|=end)?\s*|\s*(?:\*+/|-\})\s*$")
+# Tool directives rather than prose
+_PRAGMA = re.compile(
+    r"^(?:noqa|type:|pylint:|eslint|tslint|nolint|prettier-ignore|istanbul|@ts-|clang-format|"
+    r"region\b|endregion\b|pragma\b|-\*-|copyright\b|spdx-license|!/)",
+    re.IGNORECASE,
+)
+_ALNUM = re.compile(r"[A-Za-z]")
+
+DEFAULT_WEIGHTS = {"code": 1.0, "doc": 1.0}
+
+
+def doc_vectors_enabled() -> bool:
+    return bool(settings.get("indexing.doc_vectors.enabled", True))
+
+
+def _prose_lines(block: str) -> List[str]:
+    lines = []
+    for line in block.splitlines():
+        line = _MARKERS.sub("", line).strip()
+        if line and _ALNUM.search(line) and not _PRAGMA.match(line):
+            lines.append(line)
+    return lines
+
+
+def doc_text(text: str, metadata: Dict[str, Any], summary: Optional[str] = None) -> Optional[str]:
+    """
+    Natural-language description of a code chunk (its LLM summary first),
+    None when it has no summary and too little prose to be worth a vector.
+    """
+    language = (metadata.get("language") or "").lower()
+    lines = []
+    for match in _TRIPLE_QUOTED.finditer(text):
+        lines += _prose_lines(match.group(2))
+    for comment in extract_comments(text, language):
+        lines += _prose_lines(comment)
+    prose = "\n".join(dict.fromkeys(lines))
+    if len(prose) < int(settings.get("indexing.doc_vectors.min_chars", 40)):
+        prose = ""
+    if summary:
+        prose = f"{summary}\n{prose}".strip()
+    if not prose:
+        return None
+
+    names = []
+    for symbol in metadata.get("symbols") or []:
+        words = " ".join(w for part in symbol.split(".") for w in split_identifier(part))
+        if words and words not in names:
+            names.append(words)
+    header = f"{', '.join(names)}\n" if names else ""
+    return (header + prose)[:int(settings.get("indexing.doc_vectors.max_chars", 2000))]
+
+
+def doc_texts(
+    chunks: Iterable[Dict[str, Any]],
+    summaries: Optional[List[Optional[str]]] = None,
+) -> List[Optional[str]]:
+    """
+    ``doc`` vector text per chunk ({"content", "metadata"}): a string to
+    embed, ``""`` to reuse the chunk's dense vector (docs) or None (no
+    vector). ``summaries`` are the chunks' LLM summaries, if any.
+    """
+    texts = []
+    for i, chunk in enumerate(chunks):
+        metadata = chunk.get("metadata") or {}
+        summary = summaries[i] if summaries else None
+        if is_docs_chunk(metadata.get("language")):
+            max_chars = int(settings.get("indexing.doc_vectors.max_chars", 2000))
+            texts.append(f"{summary}\n{chunk.get('content') or ''}"[:max_chars] if summary else "")
+        else:
+            texts.append(doc_text(chunk.get("content") or "", metadata, summary))
+    return texts
+
+
+# ============== Collection schema ==============
+
+_vector_names: Dict[str, tuple] = {}
+_lock = threading.Lock()
+
+
+def collection_vectors(qdrant, collection_name: str, max_age: float = 300.0) -> Set[str]:
+    """Named dense vectors of a collection (cached for ``max_age`` seconds)."""
+    with _lock:
+        cached = _vector_names.get(collection_name)
+        if cached and time.monotonic() - cached[0] < max_age:
+            return cached[1]
+    try:
+        vectors = qdrant.get_collection(collection_name).config.params.vectors
+        names = set(vectors) if isinstance(vectors, dict) else {"dense"}
+    except Exception as e:
+        logger.debug(f"Could not read vectors of {collection_name}: {e}")
+        return {"dense"}
+    with _lock:
+        _vector_names[collection_name] = (time.monotonic(), names)
+    return names
+
+
+def has_doc_vectors(qdrant, collection_name: str) -> bool:
+    return DOC_VECTOR in collection_vectors(qdrant, collection_name)
+
+
+def fit_points(qdrant, collection_name: str, points: List[Any]) -> List[Any]:
+    """Points without the ``doc`` vector if the collection predates it."""
+    if has_doc_vectors(qdrant, collection_name):
+        return points
+    from qdrant_client.models import PointStruct
+    fitted = []
+    for point in points:
+        vector = point.vector
+        if isinstance(vector, dict) and DOC_VECTOR in vector:
+            point = PointStruct(
+                id=point.id,
+                vector={k: v for k, v in vector.items() if k != DOC_VECTOR},
+                payload=point.payload,
+            )
+        fitted.append(point)
+    return fitted
+
+
+# ============== Search blending ==============
+
+def vector_weights(
+    store_config: Optional[Dict[str, Any]] = None,
+    override: Optional[Dict[str, float]] = None,
+) -> Dict[str, float]:
+    """
+    Code/doc vector weights: request override, then the store's search
+    config, then ``search.doc_vectors.weights``.
+    """
+    weights = {**DEFAULT_WEIGHTS, **(settings.get("search.doc_vectors.weights", None) or {})}
+    for source in ((store_config or {}).get("vector_weights"), override):
+        for key, value in (source or {}).items():
+            if key not in DEFAULT_WEIGHTS:
+                raise ValueError(f"Unknown vector weight '{key}'; expected code or doc")
+            if float(value) < 0:
+                raise ValueError(f"Vector weight '{key}' must not be negative")
+            weights[key] = float(value)
+    return weights
+
+
+def blend_weights(weights: Optional[Dict[str, float]], vectors: Dict[str, float]) -> Dict[str, float]:
+    """
+    Split the dense retriever's RRF weight between code (``bm42``) and doc
+    (``doc``) results.
+    """
+    weights = dict(weights or {})
+    dense = float(weights.get("bm42", 1.0))
+    weights["bm42"] = dense * vectors["code"]
+    weights[DOC_VECTOR] = dense * vectors["doc"]
+    return weights
@@ -0,0 +1,215 @@
+"""
+Heading-Aware Docs Chunking.
+
+Markdown, reStructuredText and YAML files are split on their structure
+instead of by character count, so a chunk is a section of a design doc or
+runbook rather than an arbitrary window across two of them:
+
+- Markdown: ATX (``## Title``) and setext (``Title`` over ``===``/``---``)
+  headings, ignoring fenced code blocks
+- reStructuredText: titles underlined (and optionally overlined) with
+  punctuation; levels follow the order adornment styles first appear in
+- YAML: top-level keys
+
+Every chunk carries its ``heading``, ``heading_level`` and a ``breadcrumb``
+of the titles above it, rooted at the file name
+(``README > Installation > Docker``). The breadcrumb is embedded with the
+chunk and search boosts chunks whose heading matches the query
+(``search.heading_boost``). Sections longer than
+``indexing.docs.max_section_chars`` are split on paragraph boundaries, each
+part keeping the section's heading.
+"""
+
+import os
+import re
+from typing import Any, Dict, List, Optional, Tuple
+
+from src.core.config import settings
+
+DEFAULT_LANGUAGES = ["markdown", "restructuredtext", "yaml"]
+BREADCRUMB_SEPARATOR = " > "
+
+_ATX = re.compile(r"^ {0,3}(#{1,6})[ \t]+(.+?)(?:[ \t]+#+)?[ \t]*$")
+_SETEXT = re.compile(r"^ {0,3}(=+|-+)[ \t]*$")
+_FENCE = re.compile(r"^ {0,3}(```|~~~)")
+_RST_ADORNMENT = re.compile(r"^([=\-~^\"#*+`:.'_])\1{2,}[ \t]*$")
+_YAML_KEY = re.compile(r"^(?![\s#-])(['\"]?)([^:#'\"]+?)\1[ \t]*:(?:[ \t]|$)")
+
+# (first line of the heading, level, title, lines the heading itself spans)
+Heading = Tuple[int, int, str, int]
+
+
+def docs_chunking_applies(language: Optional[str]) -> bool:
+    """Whether files of a language are chunked by heading."""
+    if not settings.get("indexing.docs.enabled", True):
+        return False
+    languages = settings.get("indexing.docs.languages") or DEFAULT_LANGUAGES
+    return (language or "").lower() in languages
+
+
+def markdown_headings(lines: List[str]) -> List[Heading]:
+    headings: List[Heading] = []
+    fence = None
+    # Front matter (--- ... --- at the top) is not a setext heading
+    skip = 0
+    if lines and lines[0].strip() == "---":
+        closing = [i for i, line in enumerate(lines[1:], 1) if line.strip() in ("---", "...")]
+        skip = closing[0] + 1 if closing else 0
+    for i, line in enumerate(lines):
+        if i < skip:
+            continue
+        match = _FENCE.match(line)
+        if match:
+            if fence is None:
+                fence = match.group(1)
+            elif match.group(1) == fence:
+                fence = None
+            continue
+        if fence is not None:
+            continue
+        atx = _ATX.match(line)
+        if atx:
+            headings.append((i, len(atx.group(1)), atx.group(2).strip(), 1))
+            continue
+        # Setext: a paragraph line underlined with === or ---
+        setext = _SETEXT.match(line)
+        if setext and i > skip and lines[i - 1].strip() and not _is_block_start(lines[i - 1]):
+            if headings and headings[-1][0] == i - 1:
+                continue
+            headings.append((i - 1, 1 if setext.group(1)[0] == "=" else 2, lines[i - 1].strip(), 2))
+    return headings
+
+
+def _is_block_start(line: str) -> bool:
+    stripped = line.lstrip()
+    return stripped.startswith(("#", ">", "- ", "* ", "+ ", "|")) or bool(_FENCE.match(line))
+
+
+def rst_headings(lines: List[str]) -> List[Heading]:
+    headings: List[Heading] = []
+    styles: List[Tuple[str, bool]] = []
+    i = 0
+    while i < len(lines) - 1:
+        title = lines[i].strip()
+        over = None
+        if _RST_ADORNMENT.match(lines[i]) and i + 2 < len(lines):
+            # Overlined title: adornment, title, matching adornment
+            over, title = lines[i].strip()[0], lines[i + 1].strip()
+            under = lines[i + 2]
+            span = 3
+        else:
+            under = lines[i + 1]
+            span = 2
+        match = _RST_ADORNMENT.match(under)
+        if (
+            title and not _RST_ADORNMENT.match(title)
+            and match and len(under.strip()) >= len(title)
+            and (over is None or over == match.group(1))
+        ):
+            style = (match.group(1), over is not None)
+            if style not in styles:
+                styles.append(style)
+            headings.append((i, styles.index(style) + 1, title, span))
+            i += span
+            continue
+        i += 1
+    return headings
+
+
+def yaml_headings(lines: List[str]) -> List[Heading]:
+    headings: List[Heading] = []
+    for i, line in enumerate(lines):
+        match = _YAML_KEY.match(line)
+        if match:
+            # The key line is content too ("retries: 3")
+            headings.append((i, 1, match.group(2).strip(), 0))
+    return headings
+
+
+HEADING_PARSERS = {
+    "markdown": markdown_headings,
+    "restructuredtext": rst_headings,
+    "yaml": yaml_headings,
+}
+
+
+def split_section(lines: List[str], start: int, max_chars: int) -> List[Tuple[int, int]]:
+    """
+    Line ranges (start, end exclusive) covering ``lines`` in parts of at most
+    ``max_chars``, cut at blank lines where possible.
+    """
+    parts: List[Tuple[int, int]] = []
+    part_start, size, last_blank = 0, 0, None
+    for i, line in enumerate(lines):
+        size += len(line) + 1
+        if not line.strip():
+            last_blank = i
+        if size > max_chars and i > part_start:
+            cut = last_blank + 1 if last_blank is not None and last_blank >= part_start else i
+            if cut <= part_start:
+                cut = i
+            parts.append((start + part_start, start + cut))
+            part_start = cut
+            size = sum(len(l) + 1 for l in lines[part_start:i + 1])
+            last_blank = None
+    parts.append((start + part_start, start + len(lines)))
+    return parts
+
+
+def chunk_document(text: str, metadata: Dict[str, Any], display_path: str, language: str) -> List[Dict]:
+    """
+    Heading-based chunks of a docs file, in the ``DocumentChunker`` format.
+
+    Returns an empty list when the file has no headings, so the caller can
+    fall back to size-based chunking.
+    """
+    parser = HEADING_PARSERS.get((language or "").lower())
+    lines = text.split("\n")
+    headings = parser(lines) if parser else []
+    if not headings:
+        return []
+
+    max_chars = int(settings.get("indexing.docs.max_section_chars", 2000))
+    root = os.path.splitext(os.path.basename(display_path))[0] or display_path
+
+    # Sections: the preamble before the first heading, then one per heading
+    sections = [(0, headings[0][0], 0, None, 0)] if headings[0][0] > 0 else []
+    for n, (line, level, title, span) in enumerate(headings):
+        end = headings[n + 1][0] if n + 1 < len(headings) else len(lines)
+        sections.append((line, end, level, title, span))
+
+    chunks: List[Dict] = []
+    stack: List[Tuple[int, str]] = []
+    for start, end, level, title, span in sections:
+        if title is not None:
+            while stack and stack[-1][0] >= level:
+                stack.pop()
+            stack.append((level, title))
+        crumbs = [root] + [t for _, t in stack]
+
+        body = lines[start:end]
+        # Trailing blank lines belong to no section
+        while body and not body[-1].strip():
+            body.pop()
+        # Headings with nothing under them live on in their children's breadcrumbs
+        if not any(l.strip() for l in body[span:]):
+            continue
+
+        for part_start, part_end in split_section(body, start, max_chars):
+            content = "\n".join(lines[part_start:part_end]).strip("\n")
+            if not content.strip():
+                continue
+            chunks.append({
+                "content": content,
+                "metadata": {
+                    **metadata,
+                    "chunk_type": "section",
+                    "heading": title,
+                    "heading_level": level,
+                    "breadcrumb": BREADCRUMB_SEPARATOR.join(crumbs),
+                    "start_line": part_start + 1,
+                    "end_line": part_end,
+                },
+                "chunk_index": len(chunks),
+            })
+    return chunks
@@ -0,0 +1,164 @@
+"""
+Index Exclusion Policies.
+
+The CLI honors .gitignore and .riceignore, but the server indexes whatever
+a client pushes, so vendored trees, huge artifacts and files that must not
+be searchable (private keys, proprietary headers) end up in results. The
+indexer enforces a policy per store before a file is chunked:
+
+1. Path globs (``**/vendor/**``, ``**/node_modules/**``)
+2. Size: files over ``max_file_bytes``
+3. Binary content: NUL bytes or mostly non-text bytes in the first 8 KB
+   (PDF and Office documents are parsed, not excluded)
+4. Content markers: private key blocks, license or confidentiality
+   headers found in the first ``marker_bytes``
+5. Generated files: ``generated`` overrides ``indexing.generated.action``
+   (``skip``, ``mark`` or ``off``)
+
+The policy is ``indexing.exclusion`` with the store's ``exclusion`` keys
+laid over it. Excluded files are removed from the index on their next
+upload, like generated files.
+"""
+
+import logging
+import os
+from dataclasses import asdict, dataclass, field, fields
+from typing import Any, Dict, List, Optional
+
+from src.core.config import settings
+from src.services.ingestion.generated import BINARY_DOCUMENT_EXTENSIONS, MARK, SKIP
+from src.services.search.filters import glob_match
+
+logger = logging.getLogger(__name__)
+
+EXCLUDED_PATH = "excluded_path"
+TOO_LARGE = "too_large"
+BINARY = "binary"
+CONTENT_MARKER = "content_marker"
+EXCLUSION_REASONS = (EXCLUDED_PATH, TOO_LARGE, BINARY, CONTENT_MARKER)
+
+GENERATED_OFF = "off"
+
+# Binary checks only look at the start of a file
+BINARY_SAMPLE_BYTES = 8192
+# Bytes that occur in text: printable, whitespace, backspace, form feed, escape
+_TEXT_BYTES = bytes({7, 8, 9, 10, 12, 13, 27} | set(range(0x20, 0x100)) - {0x7F})
+
+
+def _as_list(value) -> List[str]:
+    if isinstance(value, str):
+        return [v.strip() for v in value.split(",") if v.strip()]
+    return list(value or [])
+
+
+def looks_binary(sample: bytes) -> bool:
+    """NUL bytes, or more than 30% non-text bytes, in a file's first bytes."""
+    sample = sample[:BINARY_SAMPLE_BYTES]
+    if not sample:
+        return False
+    if b"\x00" in sample:
+        return True
+    non_text = sample.translate(None, _TEXT_BYTES)
+    return len(non_text) / len(sample) > 0.3
+
+
+@dataclass
+class ExclusionPolicy:
+    """What the indexer refuses for a store."""
+
+    exclude_paths: List[str] = field(default_factory=list)
+    # 0 disables the size limit
+    max_file_bytes: int = 0
+    binary: bool = True
+    content_markers: List[str] = field(default_factory=list)
+    marker_bytes: int = 65536
+    # skip | mark | off; None follows indexing.generated.action
+    generated: Optional[str] = None
+
+    @classmethod
+    def from_dict(cls, config: Dict[str, Any]) -> "ExclusionPolicy":
+        known = {f.name for f in fields(cls)}
+        policy = cls(**{k: v for k, v in (config or {}).items() if k in known and v is not None})
+        policy.exclude_paths = _as_list(policy.exclude_paths)
+        policy.content_markers = _as_list(policy.content_markers)
+        policy.max_file_bytes = int(policy.max_file_bytes or 0)
+        policy.marker_bytes = int(policy.marker_bytes)
+        if policy.generated not in (None, SKIP, MARK, GENERATED_OFF):
+            raise ValueError(f"generated must be {SKIP}, {MARK} or {GENERATED_OFF}")
+        return policy
+
+    def to_dict(self) -> Dict[str, Any]:
+        return asdict(self)
+
+    def check_path(self, display_path: str) -> Optional[str]:
+        """EXCLUDED_PATH if the path matches an exclude glob, else None."""
+        if any(glob_match(display_path, pattern) for pattern in self.exclude_paths):
+            return EXCLUDED_PATH
+        return None
+
+    def check(self, display_path: str, size: Optional[int] = None, sample: Optional[bytes] = None) -> Optional[str]:
+        """
+        Return why a file is excluded, or None.
+
+        Args:
+            display_path: Path the file is indexed under
+            size: File size in bytes (size check skipped if None)
+            sample: Start of the file (content checks skipped if None)
+        """
+        reason = self.check_path(display_path)
+        if reason:
+            return reason
+        if size is not None and self.max_file_bytes and size > self.max_file_bytes:
+            return TOO_LARGE
+        if sample is None:
+            return None
+
+        is_document = os.path.splitext(display_path.lower())[1] in BINARY_DOCUMENT_EXTENSIONS
+        if self.binary and not is_document and looks_binary(sample):
+            return BINARY
+        if self.content_markers and not is_document:
+            text = sample[:self.marker_bytes].decode("utf-8", errors="ignore").lower()
+            if any(marker.lower() in text for marker in self.content_markers):
+                return CONTENT_MARKER
+        return None
+
+    def check_file(self, file_path: str, display_path: str) -> Optional[str]:
+        """Check a file on disk (reads at most ``marker_bytes``)."""
+        reason = self.check_path(display_path)
+        if reason:
+            return reason
+        try:
+            size = os.path.getsize(file_path)
+            with open(file_path, "rb") as f:
+                sample = f.read(max(self.marker_bytes, BINARY_SAMPLE_BYTES))
+        except OSError as e:
+            logger.debug(f"Could not read {file_path} for exclusion check: {e}")
+            return None
+        return self.check(display_path, size, sample)
+
+
+def default_policy() -> Dict[str, Any]:
+    """The ``indexing.exclusion`` settings."""
+    config = {}
+    for f in fields(ExclusionPolicy):
+        value = settings.get(f"indexing.exclusion.{f.name}")
+        if value is not None:
+            config[f.name] = value
+    return config
+
+
+def get_exclusion_policy(org_id: Optional[str]) -> ExclusionPolicy:
+    """A store's effective policy: its ``exclusion`` keys over the defaults."""
+    config = default_policy()
+    if org_id:
+        try:
+            from src.services.admin.admin_store import get_admin_store
+            overrides = get_admin_store().get_stores().get(org_id, {}).get("exclusion") or {}
+            config.update({k: v for k, v in overrides.items() if v is not None})
+        except Exception as e:
+            logger.debug(f"Could not read exclusion policy for {org_id}: {e}")
+    try:
+        return ExclusionPolicy.from_dict(config)
+    except (TypeError, ValueError) as e:
+        logger.warning(f"Invalid exclusion policy for {org_id}, using defaults: {e}")
+        return ExclusionPolicy.from_dict(default_policy())
@@ -0,0 +1,133 @@
+"""
+Generated File Detection.
+
+Lockfiles, minified bundles and code generated by protobuf and similar tools
+are large, repetitive and rarely what a search is looking for, yet they can
+dominate results in some stores. The indexer checks each file against:
+
+1. Lockfile names (package-lock.json, Cargo.lock, go.sum, ...)
+2. Generated path patterns (*.min.js, *.pb.go, *_pb2.py, ...)
+3. Generator markers in the first lines ("Code generated ... DO NOT EDIT",
+   "@generated", ...)
+4. Minification: very long average line length
+
+Matches are skipped or indexed with a ``generated`` payload field depending
+on ``indexing.generated.action``.
+"""
+
+import fnmatch
+import logging
+import os
+from typing import List, Optional
+
+from src.core.config import settings
+
+logger = logging.getLogger(__name__)
+
+SKIP = "skip"
+MARK = "mark"
+
+LOCKFILE = "lockfile"
+GENERATED_PATH = "generated_path"
+GENERATED_HEADER = "generated_header"
+MINIFIED = "minified"
+GENERATED_KINDS = (LOCKFILE, GENERATED_PATH, GENERATED_HEADER, MINIFIED)
+
+# Content checks only look at this much of a file
+MAX_SAMPLE_BYTES = 1024 * 1024
+
+# Binary documents go through the document parser, not line heuristics
+BINARY_DOCUMENT_EXTENSIONS = {".pdf", ".docx", ".doc", ".pptx", ".xlsx", ".odt"}
+
+
+def _as_list(value) -> List[str]:
+    if isinstance(value, str):
+        return [v.strip() for v in value.split(",") if v.strip()]
+    return list(value or [])
+
+
+class GeneratedFileDetector:
+    """Classifies files as generated content by name and content heuristics."""
+
+    PREFIX = "indexing.generated"
+
+    @property
+    def enabled(self) -> bool:
+        return bool(settings.get(f"{self.PREFIX}.enabled", True))
+
+    @property
+    def action(self) -> str:
+        action = settings.get(f"{self.PREFIX}.action", SKIP)
+        return action if action in (SKIP, MARK) else SKIP
+
+    def detect(self, display_path: str, data: Optional[bytes] = None) -> Optional[str]:
+        """
+        Return why a file looks generated, or None.
+
+        Args:
+            display_path: Path the file is indexed under
+            data: File contents (content checks are skipped if None)
+
+        Returns:
+            One of lockfile, generated_path, generated_header, minified
+        """
+        name = os.path.basename(display_path.replace("\\", "/"))
+
+        if name in _as_list(settings.get(f"{self.PREFIX}.lockfiles", [])):
+            return LOCKFILE
+
+        lowered = name.lower()
+        for pattern in _as_list(settings.get(f"{self.PREFIX}.path_patterns", [])):
+            if fnmatch.fnmatch(lowered, pattern.lower()):
+                return GENERATED_PATH
+
+        if data is None:
+            return None
+        if os.path.splitext(lowered)[1] in BINARY_DOCUMENT_EXTENSIONS or b"\x00" in data[:8192]:
+            return None
+
+        text = data[:MAX_SAMPLE_BYTES].decode("utf-8", errors="ignore")
+        if self._has_generator_marker(text):
+            return GENERATED_HEADER
+        if self._looks_minified(text):
+            return MINIFIED
+        return None
+
+    def _has_generator_marker(self, text: str) -> bool:
+        header_lines = int(settings.get(f"{self.PREFIX}.header_lines", 10))
+        header = "\n".join(text.splitlines()[:header_lines]).lower()
+        markers = _as_list(settings.get(f"{self.PREFIX}.header_markers", []))
+        return any(marker.lower() in header for marker in markers)
+
+    def _looks_minified(self, text: str) -> bool:
+        min_bytes = int(settings.get(f"{self.PREFIX}.minified_min_bytes", 2048))
+        if len(text) < min_bytes:
+            return False
+        lines = [line for line in text.splitlines() if line.strip()]
+        if not lines:
+            return False
+        avg_length = sum(len(line) for line in lines) / len(lines)
+        return avg_length > float(settings.get(f"{self.PREFIX}.minified_line_length", 300))
+
+    def detect_file(self, file_path: str, display_path: str) -> Optional[str]:
+        """Detect from a file on disk (reads at most MAX_SAMPLE_BYTES)."""
+        if not self.enabled:
+            return None
+        try:
+            with open(file_path, "rb") as f:
+                data = f.read(MAX_SAMPLE_BYTES)
+        except OSError as e:
+            logger.debug(f"Could not read {file_path} for generated check: {e}")
+            data = None
+        return self.detect(display_path, data)
+
+
+# Singleton instance
+_detector: Optional[GeneratedFileDetector] = None
+
+def get_generated_detector() -> GeneratedFileDetector:
+    """Get global generated file detector instance."""
+    global _detector
+    if _detector is None:
+        _detector = GeneratedFileDetector()
+    return _detector
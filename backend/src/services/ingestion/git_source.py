@@ -0,0 +1,376 @@
+"""
+Git Repository Ingestion.
+
+Indexes a git repository the server clones itself, for repos the CLI
+machine doesn't have checked out (``POST /api/v1/stores/{id}/ingest/git``).
+
+The worker shallow-clones the branch into a throwaway directory with
+hooks, symlinks, local transports and user/system git config disabled,
+indexes every supported file through the normal indexer (so the store's
+exclusion policy applies) and records the commit in the store's ``git``
+metadata. Later runs against the same URL and branch fetch the recorded
+commit as well and only index what changed since, dropping deleted files.
+When that commit can't be fetched (force-push, expired history) the run
+falls back to a full index.
+
+Files are stored as ``<owner>/<name>/<path>``, the same paths git push
+webhooks use, so both keep the same files up to date.
+
+Tokens are never written to store metadata or task arguments: the API
+keeps them in Redis for ``indexing.git.token_ttl_seconds`` under the
+task id and the worker takes them once.
+"""
+
+import base64
+import ipaddress
+import logging
+import os
+import shutil
+import socket
+import subprocess
+import tempfile
+from datetime import datetime
+from typing import Any, Callable, Dict, List, Optional, Tuple
+from urllib.parse import urlparse
+
+import redis
+
+from src.core.config import settings
+from src.services.webhooks.git import display_path, is_indexable
+
+logger = logging.getLogger(__name__)
+
+QUEUED = "queued"
+RUNNING = "running"
+COMPLETE = "complete"
+FAILED = "failed"
+
+FULL = "full"
+INCREMENTAL = "incremental"
+
+# Username sent with a token over HTTP basic auth (GitHub and GitLab accept it)
+TOKEN_USERNAME = "x-access-token"
+
+# Config applied to every git call, over an empty global and system config
+SANDBOX_CONFIG = {
+    "core.hooksPath": "/dev/null",
+    "core.symlinks": "false",
+    "protocol.file.allow": "never",
+    "protocol.ext.allow": "never",
+    "submodule.recurse": "false",
+}
+
+
+class GitIngestError(ValueError):
+    """A repository that can't be accepted, cloned or fetched."""
+
+
+class GitIngestBusy(GitIngestError):
+    """A run for the store is still queued or running."""
+
+
+def resolve_host(host: str) -> List[str]:
+    """Addresses a host name resolves to (an IP literal resolves to itself)."""
+    return sorted({info[4][0] for info in socket.getaddrinfo(host, 443, proto=socket.IPPROTO_TCP)})
+
+
+def is_public_address(address: str) -> bool:
+    """False for loopback, private, link-local and other non-routable addresses."""
+    ip = ipaddress.ip_address(address.split("%")[0])
+    mapped = getattr(ip, "ipv4_mapped", None)
+    return (mapped or ip).is_global and not ip.is_multicast
+
+
+def validate_url(url: str) -> str:
+    """
+    Check a repository URL is https, carries no credentials and is on an
+    allowed host: one of ``indexing.git.allowed_hosts`` when set, else any
+    host resolving only to public addresses (internal servers must be
+    listed).
+    """
+    url = (url or "").strip()
+    parsed = urlparse(url)
+    if parsed.scheme != "https" or not parsed.hostname:
+        raise GitIngestError("Repository URL must be an https:// URL")
+    if parsed.username or parsed.password:
+        raise GitIngestError("Pass credentials as token, not in the repository URL")
+    if not parsed.path.strip("/"):
+        raise GitIngestError("Repository URL has no repository path")
+    allowed = [h.lower() for h in settings.get("indexing.git.allowed_hosts", []) or []]
+    if allowed:
+        if parsed.hostname.lower() not in allowed:
+            raise GitIngestError(f"Host '{parsed.hostname}' is not in indexing.git.allowed_hosts")
+        return url
+    try:
+        addresses = resolve_host(parsed.hostname)
+    except OSError:
+        raise GitIngestError(f"Can't resolve host '{parsed.hostname}'")
+    if not addresses or not all(is_public_address(a) for a in addresses):
+        raise GitIngestError(
+            f"Host '{parsed.hostname}' is not a public address; list internal hosts in indexing.git.allowed_hosts"
+        )
+    return url
+
+
+def repository_name(url: str) -> str:
+    """``owner/name`` (or ``group/sub/name``) from a repository URL."""
+    path = urlparse(url).path.strip("/")
+    return path[:-4] if path.endswith(".git") else path
+
+
+def git_env(home: str, token: Optional[str] = None) -> Dict[str, str]:
+    """
+    Environment for sandboxed git calls: no prompts, https only, no user or
+    system config, and the token (if any) as an auth header.
+
+    The header goes through GIT_CONFIG_* variables so it never shows up in
+    the process list or the clone's .git/config.
+    """
+    config = dict(SANDBOX_CONFIG)
+    if token:
+        credentials = base64.b64encode(f"{TOKEN_USERNAME}:{token}".encode()).decode()
+        config["http.extraHeader"] = f"Authorization: Basic {credentials}"
+    env = {
+        "PATH": os.environ.get("PATH", "/usr/bin:/bin"),
+        "HOME": home,
+        "GIT_TERMINAL_PROMPT": "0",
+        "GIT_ALLOW_PROTOCOL": "https",
+        "GIT_CONFIG_NOSYSTEM": "1",
+        "GIT_CONFIG_GLOBAL": os.devnull,
+        "GIT_CONFIG_COUNT": str(len(config)),
+    }
+    for i, (key, value) in enumerate(config.items()):
+        env[f"GIT_CONFIG_KEY_{i}"] = key
+        env[f"GIT_CONFIG_VALUE_{i}"] = value
+    return env
+
+
+def parse_name_status(output: str) -> Tuple[List[str], List[str]]:
+    """Changed and removed paths from ``git diff --name-status --no-renames``."""
+    changed, removed = [], []
+    for line in output.splitlines():
+        status, _, path = line.partition("\t")
+        if not path:
+            continue
+        (removed if status.startswith("D") else changed).append(path)
+    return sorted(changed), sorted(removed)
+
+
+def list_files(root: str) -> List[str]:
+    """Regular, indexable files under a checkout (no .git, no symlinks), relative to it."""
+    files = []
+    for directory, dirnames, filenames in os.walk(root):
+        dirnames[:] = [d for d in dirnames if d != ".git" and not os.path.islink(os.path.join(directory, d))]
+        for name in filenames:
+            full = os.path.join(directory, name)
+            if os.path.islink(full) or not os.path.isfile(full):
+                continue
+            path = os.path.relpath(full, root).replace(os.sep, "/")
+            if is_indexable(path):
+                files.append(path)
+    return sorted(files)
+
+
+class GitIngestion:
+    """Clone-and-index runs for stores, with state in the store's ``git`` metadata."""
+
+    TOKEN_KEY = "rice:git_ingest:token"
+
+    def __init__(self, redis_client=None, admin_store=None, qdrant_client=None):
+        self._redis = redis_client
+        self._admin_store = admin_store
+        self._qdrant = qdrant_client
+
+    @property
+    def redis(self) -> redis.Redis:
+        """Lazy Redis connection."""
+        if self._redis is None:
+            self._redis = redis.from_url(settings.REDIS_URL, decode_responses=True)
+        return self._redis
+
+    @property
+    def admin_store(self):
+        if self._admin_store is None:
+            from src.services.admin.admin_store import get_admin_store
+            self._admin_store = get_admin_store()
+        return self._admin_store
+
+    @property
+    def timeout(self) -> float:
+        return float(settings.get("indexing.git.timeout_seconds", 1800))
+
+    def _state(self, store_id: str) -> Dict[str, Any]:
+        return dict(self.admin_store.get_stores().get(store_id, {}).get("git") or {})
+
+    def _save(self, store_id: str, state: Dict[str, Any]):
+        stores = self.admin_store.get_stores()
+        if store_id in stores:
+            self.admin_store.set_store(store_id, {**stores[store_id], "git": state})
+
+    # ============== API side ==============
+
+    def start(
+        self,
+        store_id: str,
+        url: str,
+        task_id: str,
+        branch: Optional[str] = None,
+        token: Optional[str] = None,
+        full: bool = False,
+    ) -> Dict[str, Any]:
+        """
+        Record a queued run for the worker.
+
+        Raises:
+            GitIngestError: bad URL
+            GitIngestBusy: a run for the store is still in progress
+        """
+        url = validate_url(url)
+        previous = self._state(store_id)
+        if previous.get("state") in (QUEUED, RUNNING):
+            started = datetime.fromisoformat(previous.get("started_at") or datetime.now().isoformat())
+            if (datetime.now() - started).total_seconds() < self.timeout:
+                raise GitIngestBusy(f"A git ingest for '{store_id}' is already {previous['state']}")
+
+        same_source = previous.get("url") == url and (branch is None or previous.get("branch") == branch)
+        state = {
+            **(previous if same_source else {}),
+            "url": url,
+            "repository": repository_name(url),
+            "branch": branch or (previous.get("branch") if same_source else None),
+            "state": QUEUED,
+            "task_id": task_id,
+            "full": bool(full),
+            "started_at": datetime.now().isoformat(),
+            "error": None,
+        }
+        if token:
+            self.redis.set(f"{self.TOKEN_KEY}:{task_id}", token, ex=int(settings.get("indexing.git.token_ttl_seconds", 3600)))
+        self._save(store_id, state)
+        mode = INCREMENTAL if same_source and previous.get("sha") and not full else FULL
+        return {"repository": state["repository"], "branch": state["branch"], "mode": mode}
+
+    def fail(self, store_id: str, error: str):
+        """Mark the store's run failed (e.g. it couldn't be queued)."""
+        self._save(store_id, {**self._state(store_id), "state": FAILED, "error": error})
+
+    # ============== Worker side ==============
+
+    def _take_token(self, task_id: str) -> Optional[str]:
+        key = f"{self.TOKEN_KEY}:{task_id}"
+        pipe = self.redis.pipeline()
+        pipe.get(key)
+        pipe.delete(key)
+        token, _ = pipe.execute()
+        return token
+
+    def _git(self, args: List[str], cwd: str, env: Dict[str, str]) -> str:
+        try:
+            result = subprocess.run(
+                ["git", *args], cwd=cwd, env=env, capture_output=True, text=True, timeout=self.timeout
+            )
+        except subprocess.TimeoutExpired:
+            raise GitIngestError(f"git {args[0]} timed out after {self.timeout:.0f}s")
+        if result.returncode != 0:
+            raise GitIngestError(f"git {args[0]} failed: {result.stderr.strip()[-500:]}")
+        return result.stdout
+
+    def _clone(self, url: str, branch: Optional[str], checkout: str, env: Dict[str, str]) -> Tuple[str, str]:
+        """Shallow clone; returns (branch, sha)."""
+        args = ["clone", "--depth", "1", "--single-branch", "--no-tags"]
+        if branch:
+            args += ["--branch", branch]
+        self._git(args + ["--", url, checkout], cwd=os.path.dirname(checkout), env=env)
+        head = self._git(["rev-parse", "--abbrev-ref", "HEAD"], cwd=checkout, env=env).strip()
+        sha = self._git(["rev-parse", "HEAD"], cwd=checkout, env=env).strip()
+        return branch or head, sha
+
+    def _changes(self, checkout: str, since: str, env: Dict[str, str]) -> Optional[Tuple[List[str], List[str]]]:
+        """Paths changed and removed since ``since``, or None when it can't be fetched."""
+        try:
+            self._git(["fetch", "--depth", "1", "--no-tags", "origin", since], cwd=checkout, env=env)
+            output = self._git(["diff", "--name-status", "--no-renames", since, "HEAD"], cwd=checkout, env=env)
+        except GitIngestError as e:
+            logger.info(f"Falling back to a full index: {e}")
+            return None
+        return parse_name_status(output)
+
+    def run(self, store_id: str, task_id: str, progress: Optional[Callable[[int, int], None]] = None) -> Dict[str, Any]:
+        """Clone the store's queued repository and index it."""
+        from src.db.qdrant import get_qdrant_client
+        from src.services.ingestion.indexer import Indexer, ingest_local_files
+
+        state = self._state(store_id)
+        if not state.get("url"):
+            raise GitIngestError(f"No git ingest queued for '{store_id}'")
+        state.update(state=RUNNING, task_id=task_id)
+        self._save(store_id, state)
+
+        token = self._take_token(task_id)
+        workdir = tempfile.mkdtemp(prefix="rice-git-", dir=settings.get("indexing.git.work_dir") or None)
+        try:
+            # Checked again at clone time: the host may resolve elsewhere by now
+            validate_url(state["url"])
+            env = git_env(workdir, token)
+            checkout = os.path.join(workdir, "repo")
+            branch, sha = self._clone(state["url"], state.get("branch"), checkout, env)
+
+            previous_sha = state.get("sha")
+            changes = None
+            if previous_sha and not state.get("full"):
+                changes = ([], []) if previous_sha == sha else self._changes(checkout, previous_sha, env)
+            if changes is None:
+                mode, changed, removed = FULL, list_files(checkout), []
+            else:
+                mode, (changed, removed) = INCREMENTAL, changes
+                changed = [p for p in changed if is_indexable(p)]
+
+            max_files = int(settings.get("indexing.git.max_files", 20000))
+            if max_files and len(changed) > max_files:
+                raise GitIngestError(f"{len(changed)} files exceed indexing.git.max_files ({max_files})")
+
+            indexer = Indexer(qdrant_client=self._qdrant or get_qdrant_client())
+            repository = state["repository"]
+            for path in removed:
+                indexer.delete_file(display_path(repository, path), store_id)
+            outcome = ingest_local_files(indexer, checkout, changed, repository, store_id, progress)
+            # Deleted or turned into a symlink/directory since the diff base
+            for path in outcome["missing"]:
+                indexer.delete_file(display_path(repository, path), store_id)
+                removed.append(path)
+        except Exception as e:
+            self._save(store_id, {**state, "state": FAILED, "error": str(e), "finished_at": datetime.now().isoformat()})
+            raise
+        finally:
+            shutil.rmtree(workdir, ignore_errors=True)
+
+        summary = {
+            "mode": mode,
+            "indexed": len(outcome["indexed"]),
+            "removed": len(removed),
+            "skipped": len(outcome["skipped"]),
+            "failed": len(outcome["failed"]),
+        }
+        self._save(store_id, {
+            **state,
+            "branch": branch,
+            "sha": sha,
+            "previous_sha": previous_sha,
+            "state": COMPLETE,
+            "full": False,
+            "finished_at": datetime.now().isoformat(),
+            "last_run": summary,
+        })
+        logger.info(f"Git ingest of {repository}@{sha[:12]} into {store_id}: {summary}")
+        return {"store": store_id, "repository": repository, "branch": branch, "sha": sha, **summary, "failures": outcome["failed"][:50]}
+
+
+# Singleton instance
+_git_ingestion: Optional[GitIngestion] = None
+
+def get_git_ingestion() -> GitIngestion:
+    """Get global git ingestion instance."""
+    global _git_ingestion
+    if _git_ingestion is None:
+        _git_ingestion = GitIngestion()
+    return _git_ingestion
@@ -0,0 +1,62 @@
+"""
+Content Hashing.
+
+File hashes drive skip-unchanged logic in the indexer and the watch CLI.
+Hashing raw bytes made a Windows checkout (CRLF) and a Unix checkout (LF)
+of the same repository look entirely different, forcing full re-embeds, so
+text is normalized first:
+
+1. CRLF and lone CR line endings become LF
+2. Trailing whitespace is stripped from every line
+3. Trailing blank lines are dropped
+
+Binary content (anything with a NUL byte) is hashed as-is. The algorithm
+version is recorded next to stored hashes; hashes from another version
+never count as unchanged.
+
+Chunk IDs are content-addressed too: derived from the store, the file's
+display path, the chunk's content hash and its index, so re-indexing
+unchanged content (even from another temp upload path) keeps its IDs,
+and the same file in two stores never shares a point.
+"""
+
+import hashlib
+import re
+import uuid
+
+# Bump when normalization changes so stored hashes are recomputed
+HASH_VERSION = 2
+
+_LINE_END = re.compile(rb"\r\n?")
+_TRAILING_WS = re.compile(rb"[ \t\f\v]+$", re.MULTILINE)
+
+
+def normalize_content(data: bytes) -> bytes:
+    """Normalize line endings and trailing whitespace (binary data unchanged)."""
+    if b"\x00" in data:
+        return data
+    data = _LINE_END.sub(b"\n", data)
+    data = _TRAILING_WS.sub(b"", data)
+    return data.rstrip(b"\n")
+
+
+def content_hash(data: bytes) -> str:
+    """SHA256 of normalized content."""
+    return hashlib.sha256(normalize_content(data)).hexdigest()
+
+
+def file_hash(file_path: str) -> str:
+    """Normalized content hash of a file on disk."""
+    with open(file_path, "rb") as f:
+        return content_hash(f.read())
+
+
+def chunk_content_hash(content: str) -> str:
+    """SHA256 of a chunk's text, as stored in the ``content_hash`` payload field."""
+    return hashlib.sha256(content.encode("utf-8")).hexdigest()
+
+
+def chunk_id(org_id: str, path: str, chunk_hash: str, chunk_index: int) -> str:
+    """Deterministic point ID of a chunk (a UUID, as Qdrant requires)."""
+    key = "\x00".join((org_id or "", path, chunk_hash, str(chunk_index)))
+    return str(uuid.UUID(hashlib.sha256(key.encode("utf-8")).hexdigest()[:32]))
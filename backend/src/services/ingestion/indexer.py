@@ -2,7 +2,7 @@
 Indexing Service.
 
 Handles document processing and multi-representation storage:
-1. Dense embeddings (BentoML)
+1. Dense embeddings (BentoML): code, plus docstrings/comments (doc_vectors)
 2. SPLADE sparse vectors
 3. BM42 sparse vectors
 4. BM25 index (Tantivy)
@@ -10,10 +10,12 @@ Handles document processing and multi-representation storage:
 All representations computed at INDEX TIME and persisted.
 """
 
+import json
+import os
+import time
 import uuid
-import hashlib
 import logging
-from typing import Dict, List, Optional
+from typing import Any, Callable, Dict, List, Optional
 
 from qdrant_client.models import (
     PointStruct,
@@ -22,16 +24,91 @@ from qdrant_client.models import (
     SparseIndexParams,
     Distance,
     SparseVector,
+    PayloadSchemaType,
 )
 
 from src.core.config import settings
+from src.db.content_store import content_store_enabled, get_content_store
+from src.services.admin.store_stats import get_store_stats
 from src.services.ingestion.parser import DocumentParser
 from src.services.ingestion.chunker import DocumentChunker
+from src.services.ingestion.docs_chunker import chunk_document, docs_chunking_applies
+from src.services.ingestion.doc_vectors import DOC_VECTOR, doc_texts, doc_vectors_enabled, fit_points, has_doc_vectors
 from src.services.ingestion.ast_parser import get_ast_parser
+from src.services.ingestion.chunk_redirects import PAYLOAD_FIELDS as CHUNK_POSITION_FIELDS, get_chunk_redirects
+from src.services.ingestion.natural_language import detect_natural_language, is_docs_chunk
+from src.services.ingestion.notebook import NotebookError, chunk_notebook, is_notebook
+from src.services.ingestion.privacy import is_private_store, strip_content
+from src.services.ingestion.summaries import get_chunk_summarizer, store_summaries
+from src.services.ingestion.migration import store_collection, store_collections, write_collections
+from src.services.ingestion.pipeline import get_stage_limiter
 from src.services.search.retriever import embed_texts
+from src.services.retrieval.analyzer import analyze_all
+from src.services.search.filters import path_fields
+from src.services.search.query_cache import invalidate_store
+from src.services.events import emit
 
 logger = logging.getLogger(__name__)
 
+# Payload fields with a Qdrant index (filtering, symbol autocomplete facets)
+PAYLOAD_INDEXES = {
+    "symbols": PayloadSchemaType.KEYWORD,
+    "extension": PayloadSchemaType.KEYWORD,
+    "path_dirs": PayloadSchemaType.KEYWORD,
+    "indexed_at": PayloadSchemaType.FLOAT,
+    "content_language": PayloadSchemaType.KEYWORD,
+    "language": PayloadSchemaType.KEYWORD,
+    "connection_id": PayloadSchemaType.KEYWORD,
+}
+
+MB = 1024 * 1024
+
+# Rough wire size of one vector component (JSON over REST is the larger encoding)
+BYTES_PER_VALUE = 12
+
+
+def estimate_point_bytes(point: PointStruct) -> int:
+    """Approximate request size of a point (payload plus vectors)."""
+    size = len(json.dumps(point.payload or {}, default=str))
+    vectors = point.vector if isinstance(point.vector, dict) else {"": point.vector or []}
+    for vector in vectors.values():
+        if isinstance(vector, SparseVector):
+            size += 2 * len(vector.indices) * BYTES_PER_VALUE
+        else:
+            size += len(vector) * BYTES_PER_VALUE
+    return size
+
+
+def split_by_size(points: List[PointStruct], max_bytes: int) -> List[List[PointStruct]]:
+    """Group points into batches of at most max_bytes (a larger point gets its own batch)."""
+    batches: List[List[PointStruct]] = []
+    current: List[PointStruct] = []
+    current_bytes = 0
+    for point in points:
+        point_bytes = estimate_point_bytes(point)
+        if current and current_bytes + point_bytes > max_bytes:
+            batches.append(current)
+            current, current_bytes = [], 0
+        current.append(point)
+        current_bytes += point_bytes
+    if current:
+        batches.append(current)
+    return batches
+
+
+def upsert_limit_bytes() -> int:
+    """
+    Largest upsert request to send in one go.
+
+    ``indexing.upsert_max_mb``, kept under the gRPC send limit when Qdrant
+    is reached over gRPC.
+    """
+    limit_mb = float(settings.get("indexing.upsert_max_mb", 8))
+    if settings.get("infrastructure.qdrant.prefer_grpc", False):
+        grpc_mb = float(settings.get("infrastructure.qdrant.grpc_max_send_mb", 16))
+        limit_mb = min(limit_mb, grpc_mb * 0.9)
+    return max(1, int(limit_mb * MB))
+
 
 class Indexer:
     """
@@ -42,6 +119,9 @@ class Indexer:
     2. SPLADE sparse vectors (neural sparse)
     3. BM42 sparse vectors (Qdrant hybrid)
     4. BM25 index (Tantivy lexical)
+
+    Stores on the ``bm25`` sparse backend get a Redis BM25 inverted index
+    instead of SPLADE vectors.
     """
     
     def __init__(self, qdrant_client):
@@ -93,13 +173,18 @@ class Indexer:
         
         Schema:
         - dense: Dense vector (768 dims for bge-base, cosine)
+        - doc: Dense vector of the chunk's docstrings/comments (same model)
         - splade: Sparse vector
         - bm42: Sparse vector
         """
         try:
-            self.qdrant.get_collection(self.collection_name)
+            collection = self.qdrant.get_collection(self.collection_name)
             logger.info(f"Collection {self.collection_name} exists")
         except Exception:
+            collection = None
+        if collection is not None:
+            self.ensure_payload_indexes(collection.payload_schema or {})
+        else:
             logger.info(f"Creating collection {self.collection_name}")
             embedding_dim = settings.get("models.embedding.fallback_dimension", settings.EMBEDDING_DIM)
             self.qdrant.create_collection(
@@ -108,6 +193,10 @@ class Indexer:
                     "dense": VectorParams(
                         size=embedding_dim,
                         distance=Distance.COSINE
+                    ),
+                    DOC_VECTOR: VectorParams(
+                        size=embedding_dim,
+                        distance=Distance.COSINE
                     )
                 },
                 sparse_vectors_config={
@@ -120,6 +209,27 @@ class Indexer:
                 }
             )
             logger.info(f"Collection {self.collection_name} created with triple vector schema")
+            self.ensure_payload_indexes({})
+
+    def ensure_payload_indexes(self, existing: Dict):
+        """
+        Create payload indexes missing from the collection.
+
+        Args:
+            existing: The collection's current payload schema (field -> index)
+        """
+        for field_name, schema in PAYLOAD_INDEXES.items():
+            if field_name in existing:
+                continue
+            try:
+                self.qdrant.create_payload_index(
+                    collection_name=self.collection_name,
+                    field_name=field_name,
+                    field_schema=schema
+                )
+                logger.info(f"Created {schema} payload index on {field_name}")
+            except Exception as e:
+                logger.warning(f"Failed to create payload index on {field_name}: {e}")
     
     def ingest_file(
         self,
@@ -129,12 +239,17 @@ class Indexer:
         org_id: str,
         minio_bucket: str = None,
         minio_object_name: str = None,
+        connection_id: str = None,
+        language: str = None,
+        force: bool = False,
+        enforce_owner: bool = False,
     ) -> Dict:
         """
         Ingest a single file with all representations.
 
         Automatically deletes old chunks for the same file path before indexing.
-        This ensures files are replaced, not duplicated.
+        This ensures files are replaced, not duplicated. Files whose normalized
+        content hash matches the indexed copy are left alone unless forced.
 
         Args:
             file_path: Actual path to read file from
@@ -143,119 +258,175 @@ class Indexer:
             org_id: Organization ID
             minio_bucket: MinIO bucket (if stored)
             minio_object_name: MinIO object key (if stored)
+            connection_id: CLI connection that uploaded the file
+            language: Language reported by the client (detected if omitted)
+            force: Re-index even if the content is unchanged
+            enforce_owner: Refuse to replace a file another connection indexed
 
         Returns:
             Dict with status and statistics
         """
         import pathlib
-        from qdrant_client.models import Filter, FieldCondition, MatchValue
+        from src.services.ingestion.hashing import HASH_VERSION, chunk_content_hash, chunk_id as make_chunk_id, file_hash as compute_file_hash
 
-        # 0. Delete existing chunks for this file path (ensures replacement, not duplication)
+        # 0. Skip files whose content only differs by line endings/whitespace
         try:
-            logger.info(f"Checking for existing chunks for file: {display_path}")
-            existing_points = self.qdrant.scroll(
-                collection_name=self.collection_name,
-                scroll_filter=Filter(
-                    must=[
-                        FieldCondition(key="full_path", match=MatchValue(value=display_path)),
-                        FieldCondition(key="org_id", match=MatchValue(value=org_id))
-                    ]
-                ),
-                limit=10000,
-                with_payload=False
-            )[0]
+            file_hash = compute_file_hash(file_path)
+        except OSError as e:
+            logger.warning(f"Could not hash {file_path}: {e}")
+            file_hash = None
+        if file_hash and not force and settings.get("indexing.skip_unchanged", True):
+            if self._stored_file_hash(display_path, org_id) == (file_hash, HASH_VERSION):
+                logger.info(f"Skipping unchanged file {display_path}")
+                return {
+                    "status": "unchanged",
+                    "message": "Content unchanged",
+                    "chunks_indexed": 0,
+                    "file_hash": file_hash,
+                }
 
-            if existing_points:
-                chunk_ids = [str(p.id) for p in existing_points]
-                logger.info(f"Deleting {len(chunk_ids)} existing chunks for {display_path}")
+        # 0a. Delete existing chunks for this file path (ensures replacement, not duplication)
+        if enforce_owner and connection_id:
+            foreign = self.foreign_owners(display_path, org_id, connection_id)
+            if foreign:
+                logger.warning(f"Connection {connection_id} may not replace {display_path} (owned by {foreign})")
+                return {
+                    "status": "forbidden",
+                    "message": "File is owned by another connection",
+                    "chunks_indexed": 0,
+                    "owners": foreign,
+                }
+        # Replaced chunks are redirected to the new copy's once it is indexed
+        previous_chunks = self._indexed_chunks(display_path, org_id)
+        self.delete_file(display_path, org_id)
 
-                # Delete from Qdrant
-                self.qdrant.delete(
-                    collection_name=self.collection_name,
-                    points_selector=Filter(
-                        must=[
-                            FieldCondition(key="full_path", match=MatchValue(value=display_path)),
-                            FieldCondition(key="org_id", match=MatchValue(value=org_id))
-                        ]
-                    )
-                )
+        # 0b. Store exclusion policy: vendored paths, size, binary, content markers
+        from src.services.ingestion.exclusion import GENERATED_OFF, get_exclusion_policy
+        policy = get_exclusion_policy(org_id)
+        excluded = policy.check_file(file_path, display_path)
+        if excluded:
+            self._count_excluded()
+            logger.info(f"Excluding {display_path} from {org_id} ({excluded})")
+            return {
+                "status": "skipped",
+                "message": f"Excluded by policy ({excluded})",
+                "excluded": excluded,
+                "language": language,
+            }
 
-                # Delete from Tantivy
-                if self.tantivy_client:
-                    for cid in chunk_ids:
-                        try:
-                            self.tantivy_client.delete(cid)
-                        except Exception as e:
-                            logger.warning(f"Failed to delete chunk {cid} from Tantivy: {e}")
-        except Exception as e:
-            logger.warning(f"Error checking/deleting existing chunks: {e}")
+        # 0c. Lockfiles, minified bundles, generated code: skip or mark
+        from src.services.ingestion.generated import SKIP, get_generated_detector
+        detector = get_generated_detector()
+        action = policy.generated or detector.action
+        generated = None if action == GENERATED_OFF else detector.detect_file(file_path, display_path)
+        if generated:
+            self._count_generated(action)
+            if action == SKIP:
+                logger.info(f"Skipping generated file {display_path} ({generated})")
+                return {
+                    "status": "skipped",
+                    "message": f"Generated file ({generated})",
+                    "generated": generated,
+                    "language": language,
+                }
 
         path_obj = pathlib.Path(file_path)
+        try:
+            file_bytes = path_obj.stat().st_size
+        except OSError:
+            file_bytes = None
+
+        if not language:
+            language = self._detect_language(file_path, display_path)
         ast_parser = get_ast_parser()
         doc_id = str(uuid.uuid4())
         
         chunks = []
         is_ast = False
         
-        # 1. Try AST Parsing
-        if ast_parser.can_parse(path_obj):
-            try:
-                ast_chunks = ast_parser.parse_file(path_obj)
-                for i, c in enumerate(ast_chunks):
-                    chunks.append({
-                        "content": c.content,
-                        "metadata": {
-                            "client_system_path": display_path,
-                            "file_path": display_path,  # Legacy compatibility
-                            "repo_name": repo_name,
-                            "org_id": org_id,
-                            "doc_id": doc_id,
-                            "language": c.language,
-                            "chunk_type": c.chunk_type,
-                            "symbols": c.symbols,
-                            "start_line": c.start_line,
-                            "end_line": c.end_line,
-                            "minio_bucket": minio_bucket,
-                            "minio_object_name": minio_object_name,
-                        },
-                        "chunk_index": i
-                    })
-                is_ast = True
-            except Exception as e:
-                logger.error(f"AST Parsing failed: {e}")
-                return {"status": "error", "message": f"AST Parsing failed: {str(e)}"}
+        base_metadata = {
+            "client_system_path": display_path,
+            "file_path": display_path,
+            "repo_name": repo_name,
+            "org_id": org_id,
+            "doc_id": doc_id,
+            "language": language,
+            "chunk_type": "text",
+            "symbols": [],
+            "start_line": 0,
+            "end_line": 0,
+            "minio_bucket": minio_bucket,
+            "minio_object_name": minio_object_name,
+        }
+
+        limiter = get_stage_limiter()
+        with limiter.stage("chunking"):
+            # 0. Notebooks: one chunk per code/markdown cell, never the raw JSON
+            if is_notebook(display_path, language):
+                try:
+                    with open(file_path, "r", encoding="utf-8") as f:
+                        chunks = chunk_notebook(f.read(), base_metadata)
+                except (OSError, UnicodeDecodeError, NotebookError) as e:
+                    return {"status": "error", "message": f"Parsing failed: {str(e)}"}
+                if not chunks:
+                    return {"status": "skipped", "message": "Empty notebook"}
+
+            # 1. Try AST Parsing
+            elif ast_parser.can_parse(path_obj):
+                try:
+                    ast_chunks = ast_parser.parse_file(path_obj)
+                    for i, c in enumerate(ast_chunks):
+                        chunks.append({
+                            "content": c.content,
+                            "metadata": {
+                                "client_system_path": display_path,
+                                "file_path": display_path,  # Legacy compatibility
+                                "repo_name": repo_name,
+                                "org_id": org_id,
+                                "doc_id": doc_id,
+                                "language": language or c.language,
+                                "chunk_type": c.chunk_type,
+                                "symbols": c.symbols,
+                                "start_line": c.start_line,
+                                "end_line": c.end_line,
+                                "minio_bucket": minio_bucket,
+                                "minio_object_name": minio_object_name,
+                            },
+                            "chunk_index": i
+                        })
+                    is_ast = True
+                except Exception as e:
+                    logger.error(f"AST Parsing failed: {e}")
+                    return {"status": "error", "message": f"AST Parsing failed: {str(e)}"}
         
-        # 2. Fallback to Standard Parsing
-        if not chunks:
-            try:
-                text = DocumentParser.parse_file(file_path)
-            except Exception as e:
-                return {"status": "error", "message": f"Parsing failed: {str(e)}"}
-
-            if not text.strip():
-                return {"status": "skipped", "message": "Empty file"}
-
-            base_metadata = {
-                "client_system_path": display_path,
-                "file_path": display_path,
-                "repo_name": repo_name,
-                "org_id": org_id,
-                "doc_id": doc_id,
-                "chunk_type": "text",
-                "symbols": [],
-                "start_line": 0,
-                "end_line": 0,
-                "minio_bucket": minio_bucket,
-                "minio_object_name": minio_object_name,
-            }
-            
-            chunks = self.chunker.chunk_text(text, base_metadata)
+            # 2. Fallback to Standard Parsing
+            if not chunks:
+                try:
+                    text = DocumentParser.parse_file(file_path)
+                except Exception as e:
+                    return {"status": "error", "message": f"Parsing failed: {str(e)}"}
+
+                if not text.strip():
+                    return {"status": "skipped", "message": "Empty file"}
+
+                # Docs split on headings; files without any fall back to size-based chunks
+                if docs_chunking_applies(language):
+                    chunks = chunk_document(text, base_metadata, display_path, language)
+                if not chunks:
+                    chunks = self.chunker.chunk_text(text, base_metadata)
         
         if not chunks:
             logger.info("No chunks generated")
             return {"status": "skipped", "message": "No chunks generated"}
 
         logger.info(f"Generated {len(chunks)} chunks (AST={is_ast})")
+
+        # 2a. One-sentence LLM summaries (stores that turn them on)
+        summaries = []
+        summary_config = store_summaries(org_id)
+        if summary_config["enabled"]:
+            with limiter.stage("summaries"):
+                summaries = get_chunk_summarizer().summarize(chunks, summary_config["max_concurrency"])
         
         # 3. Generate all representations
         # Extract file name for enhanced indexing
@@ -267,53 +438,81 @@ class Indexer:
         enhanced_contents = []
         for c in chunks:
             # Prepend file metadata to make file names searchable
-            enhanced = f"File: {file_name}\nPath: {display_path}\n\n{c['content']}"
+            header = f"File: {file_name}\nPath: {display_path}"
+            if c["metadata"].get("breadcrumb"):
+                # Section context for docs chunks ("README > Installation > Docker")
+                header += f"\nSection: {c['metadata']['breadcrumb']}"
+            enhanced = f"{header}\n\n{c['content']}"
             enhanced_contents.append(enhanced)
 
         # Use enhanced contents for embedding (file path is now searchable)
         contents = enhanced_contents
 
-        # 3a. Dense embeddings (BentoML)
-        logger.info("Generating dense embeddings...")
-        try:
-            dense_embeddings = embed_texts(contents)
-        except Exception as e:
-            logger.error(f"Dense embedding failed: {e}")
-            return {"status": "error", "message": f"Dense embedding failed: {e}"}
-        
-        # 3b. SPLADE sparse vectors
-        splade_vectors = []
-        if self.splade_encoder:
-            logger.info("Generating SPLADE vectors...")
+        with limiter.stage("embedding"):
+            # 3a. Dense embeddings (BentoML)
+            logger.info("Generating dense embeddings...")
             try:
-                splade_vectors = self.splade_encoder.encode(contents)
+                dense_embeddings = embed_texts(contents)
             except Exception as e:
-                logger.warning(f"SPLADE encoding failed: {e}")
+                logger.error(f"Dense embedding failed: {e}")
+                return {"status": "error", "message": f"Dense embedding failed: {e}"}
+
+            # 3a'. Summary/docstring/comment embeddings ("" reuses the dense vector)
+            doc_embeddings = self._embed_doc_texts(org_id, chunks, summaries)
         
-        # 3c. BM42 sparse vectors
-        bm42_vectors = []
-        if self.bm42_encoder:
-            logger.info("Generating BM42 vectors...")
-            try:
-                bm42_vectors = self.bm42_encoder.encode(contents)
-            except Exception as e:
-                logger.warning(f"BM42 encoding failed: {e}")
+            # Sparse encoders get identifier-split, abbreviation-expanded text
+            sparse_contents = analyze_all(contents, language)
+
+            # 3b. SPLADE sparse vectors (stores on the BM25 backend skip the model)
+            from src.services.retrieval.bm25_index import BM25, get_store_sparse_backend
+            sparse_backend = get_store_sparse_backend(org_id)
+            splade_vectors = []
+            if sparse_backend != BM25 and self.splade_encoder:
+                logger.info("Generating SPLADE vectors...")
+                try:
+                    splade_vectors = self.splade_encoder.encode(sparse_contents)
+                except Exception as e:
+                    logger.warning(f"SPLADE encoding failed: {e}")
+        
+            # 3c. BM42 sparse vectors
+            bm42_vectors = []
+            if self.bm42_encoder:
+                logger.info("Generating BM42 vectors...")
+                try:
+                    bm42_vectors = self.bm42_encoder.encode(sparse_contents)
+                except Exception as e:
+                    logger.warning(f"BM42 encoding failed: {e}")
         
         # 4. Prepare Qdrant points
         self.ensure_collection()
         points = []
         chunk_ids = []
+        indexed_at = time.time()
+        path_payload = path_fields(display_path)
+        detect_content_language = settings.get("indexing.content_language.enabled", True)
+        # Privacy mode: vectors and line ranges only, never the chunk text
+        private = is_private_store(org_id)
+        # Content store: the payload references the text instead of carrying it
+        content_store = get_content_store() if content_store_enabled() and not private else None
+        # Whole-file text for result context (surrounding lines, imports)
+        file_ref = None
+        if content_store and settings.get("infrastructure.content_store.files", True) and chunks[0]["metadata"].get("start_line"):
+            try:
+                with open(file_path, "r", encoding="utf-8") as f:
+                    file_ref = content_store.put(f.read())
+            except (OSError, UnicodeDecodeError) as e:
+                logger.debug(f"File text of {display_path} not stored: {e}")
         
         for i, chunk in enumerate(chunks):
-            # Deterministic chunk ID
-            content_hash = hashlib.sha256(
-                f"{file_path}:{chunk['chunk_index']}:{chunk['content']}".encode()
-            ).hexdigest()
-            chunk_id = str(uuid.UUID(content_hash[:32]))
+            # Content-addressed chunk ID: stable across re-indexes of unchanged content
+            content_hash = chunk_content_hash(chunk["content"])
+            chunk_id = make_chunk_id(org_id, display_path, content_hash, chunk["chunk_index"])
             chunk_ids.append(chunk_id)
             
             # Build vector dict
             vectors = {"dense": dense_embeddings[i]}
+            if doc_embeddings and doc_embeddings[i] is not None:
+                vectors[DOC_VECTOR] = doc_embeddings[i] or dense_embeddings[i]
             
             # Add SPLADE if available
             if splade_vectors and i < len(splade_vectors):
@@ -331,27 +530,62 @@ class Indexer:
                     values=bv.values
                 )
             
+            payload = {
+                "text": chunk["content"],  # Original chunk content (not enhanced)
+                **chunk["metadata"],
+                "chunk_id": chunk_id,
+                "chunk_index": chunk["chunk_index"],
+                "content_hash": content_hash,
+                "file_hash": file_hash,  # Normalized whole-file hash (skip-unchanged)
+                "file_bytes": file_bytes,  # Whole-file size (store stats)
+                "hash_version": HASH_VERSION,
+                # Add separate fields for filtering and display
+                "full_path": display_path,  # Full path for filtering
+                "filename": file_name,  # Just filename for quick access
+                **path_payload,  # extension, path_dirs (path filters)
+                "indexed_at": indexed_at,  # Used by hot/cold tiering
+                "connection_id": connection_id,  # Uploading CLI connection
+                "generated": generated,  # Set when indexed in "mark" mode
+                # Human language of docs chunks (en, de, ja, ...); per chunk for notebook markdown cells
+                "content_language": (
+                    detect_natural_language(chunk["content"])
+                    if detect_content_language and is_docs_chunk(chunk["metadata"].get("language"))
+                    else None
+                ),
+                "content_stored": not private,
+                "summary": summaries[i] if summaries else None,
+            }
+            if content_store:
+                payload["content_ref"] = content_store.put(payload.pop("text"))
+                payload["content_length"] = len(chunk["content"])
+                if file_ref:
+                    payload["file_ref"] = file_ref
             points.append(PointStruct(
                 id=chunk_id,
                 vector=vectors,
-                payload={
-                    "text": chunk["content"],  # Original chunk content (not enhanced)
-                    **chunk["metadata"],
-                    "chunk_id": chunk_id,
-                    "chunk_index": chunk["chunk_index"],
-                    "content_hash": content_hash,
-                    # Add separate fields for filtering and display
-                    "full_path": display_path,  # Full path for filtering
-                    "filename": file_name,  # Just filename for quick access
-                }
+                payload=strip_content(payload) if private else payload
             ))
         
-        # 5. Upsert to Qdrant
-        logger.info(f"Upserting {len(points)} points to Qdrant...")
-        self.qdrant.upsert(
-            collection_name=self.collection_name,
-            points=points
-        )
+        with limiter.stage("upsert"):
+            # 5. Upsert to Qdrant (split when the request would be too large)
+            self._upsert_points(points, org_id)
+            # Counted before the index version moves, so stats ETags follow
+            get_store_stats().record_file(
+                org_id, display_path, len(points), file_bytes or 0, language or chunks[0]["metadata"].get("language")
+            )
+            invalidate_store(org_id)
+            get_chunk_redirects().record(
+                org_id, previous_chunks, [{"chunk_id": str(p.id), **p.payload} for p in points]
+            )
+
+            # 5a. Variant B vectors when the store runs an A/B embedding experiment
+            self._index_experiment(org_id, points, contents)
+
+            # 5b. Query suggestion vocabulary (privacy-mode stores: names only)
+            self._add_suggestion_terms(org_id, [
+                p.payload if private else {**p.payload, "text": chunk["content"]}
+                for p, chunk in zip(points, chunks)
+            ])
         
         # 6. Index in Tantivy (BM25)
         tantivy_indexed = 0
@@ -367,47 +601,442 @@ class Indexer:
             except Exception as e:
                 logger.warning(f"Tantivy indexing failed: {e}")
         
+        # 7. BM25 sparse inverted index
+        bm25_sparse_indexed = 0
+        if sparse_backend == BM25:
+            from src.services.retrieval.bm25_index import get_bm25_index
+            try:
+                # The BM25 tokenizer splits identifiers itself
+                bm25_contents = analyze_all(contents, language, split_identifiers=False)
+                bm25_sparse_indexed = get_bm25_index().add(org_id, zip(chunk_ids, bm25_contents))
+            except Exception as e:
+                logger.warning(f"BM25 sparse indexing failed: {e}")
+
         logger.info("Indexing complete")
         
         return {
             "status": "success",
             "chunks_indexed": len(points),
             "mode": "ast" if is_ast else "fallback",
+            "generated": generated,
+            "file_hash": file_hash,
             "representations": {
                 "dense": len(points),
+                "doc": sum(1 for v in doc_embeddings if v is not None),
+                "summaries": sum(1 for s in summaries if s),
                 "splade": len(splade_vectors) if splade_vectors else 0,
                 "bm42": len(bm42_vectors) if bm42_vectors else 0,
-                "bm25": tantivy_indexed
+                "bm25": tantivy_indexed,
+                "bm25_sparse": bm25_sparse_indexed
             }
         }
     
-    def delete_document(self, doc_id: str) -> Dict:
-        """Delete all chunks for a document."""
+    def _embed_doc_texts(
+        self,
+        org_id: str,
+        chunks: List[Dict],
+        summaries: List[Optional[str]] = None,
+    ) -> List[Optional[List[float]]]:
+        """
+        ``doc`` vectors per chunk: a vector, ``[]`` (reuse the dense vector)
+        or None. Empty when no collection the store writes to has ``doc``
+        vectors; never fails indexing.
+        """
+        if not doc_vectors_enabled():
+            return []
+        if not any(has_doc_vectors(self.qdrant, c) for c in write_collections(org_id)):
+            return []
+        texts = doc_texts(chunks, summaries)
+        wanted = [t for t in texts if t]
+        try:
+            embedded = iter(embed_texts(wanted) if wanted else [])
+        except Exception as e:
+            logger.warning(f"Doc vector embedding failed: {e}")
+            return []
+        return [next(embedded) if t else ([] if t == "" else None) for t in texts]
+
+    def _index_experiment(self, org_id: str, points: List[PointStruct], contents: List[str]):
+        """Embed the points with a store's experiment model (never fails indexing)."""
+        from src.services.search.experiments import get_experiment_runner
+        try:
+            get_experiment_runner().index_points(org_id, points, contents)
+        except Exception as e:
+            logger.warning(f"Experiment indexing failed for {org_id}: {e}")
+
+    def _add_suggestion_terms(self, org_id: str, payloads: List[Dict[str, Any]]):
+        """Count new chunks' terms for spelling suggestions (never fails indexing)."""
+        from src.services.search.suggestions import get_query_suggester
+        try:
+            get_query_suggester().add(org_id, payloads)
+        except Exception as e:
+            logger.warning(f"Suggestion vocabulary update failed for {org_id}: {e}")
+
+    def _upsert_points(self, points: List[PointStruct], org_id: str = None):
+        """
+        Upsert points, in size-bounded batches above ``upsert_limit_bytes``.
+
+        Points go to the store's collection, and also to the target of a
+        running embedding migration.
+        """
+        batches = split_by_size(points, upsert_limit_bytes())
+        if len(batches) > 1:
+            logger.info(f"Upserting {len(points)} points to Qdrant in {len(batches)} batches...")
+        else:
+            logger.info(f"Upserting {len(points)} points to Qdrant...")
+        collections = write_collections(org_id)
+        errors = []
+        for collection_name in collections:
+            try:
+                for batch in batches:
+                    self.qdrant.upsert(
+                        collection_name=collection_name,
+                        points=fit_points(self.qdrant, collection_name, batch)
+                    )
+            except Exception as e:
+                # During a migration the old collection rejects new-model
+                # vectors of another size; one successful write is enough
+                logger.warning(f"Upsert to {collection_name} failed: {e}")
+                errors.append(e)
+        if len(errors) == len(collections):
+            raise errors[-1]
+
+    def _stored_file_hash(self, display_path: str, org_id: str) -> Optional[tuple]:
+        """(file_hash, hash_version) of the indexed copy of a file, if any."""
         from qdrant_client.models import Filter, FieldCondition, MatchValue
-        
-        # Get chunk IDs for Tantivy deletion
+
+        try:
+            points = self.qdrant.scroll(
+                collection_name=store_collection(org_id),
+                scroll_filter=Filter(
+                    must=[
+                        FieldCondition(key="full_path", match=MatchValue(value=display_path)),
+                        FieldCondition(key="org_id", match=MatchValue(value=org_id))
+                    ]
+                ),
+                limit=1,
+                with_payload=["file_hash", "hash_version"],
+                with_vectors=False
+            )[0]
+        except Exception as e:
+            logger.debug(f"Could not read stored hash for {display_path}: {e}")
+            return None
+        if not points:
+            return None
+        payload = points[0].payload or {}
+        return payload.get("file_hash"), payload.get("hash_version")
+
+    def _indexed_chunks(self, display_path: str, org_id: str) -> List[Dict]:
+        """Position and content hash of a file's indexed chunks (for redirects)."""
+        from qdrant_client.models import Filter, FieldCondition, MatchValue
+
+        if not get_chunk_redirects().enabled:
+            return []
+        try:
+            points = self.qdrant.scroll(
+                collection_name=store_collection(org_id),
+                scroll_filter=Filter(
+                    must=[
+                        FieldCondition(key="full_path", match=MatchValue(value=display_path)),
+                        FieldCondition(key="org_id", match=MatchValue(value=org_id))
+                    ]
+                ),
+                limit=10000,
+                with_payload=CHUNK_POSITION_FIELDS,
+                with_vectors=False
+            )[0]
+        except Exception as e:
+            logger.debug(f"Could not read indexed chunks of {display_path}: {e}")
+            return []
+        return [{"chunk_id": str(p.id), **(p.payload or {})} for p in points]
+
+    def _detect_language(self, file_path: str, display_path: str) -> Optional[str]:
+        """Language from the display path, falling back to the file's content."""
+        from src.services.ingestion.language import detect_language
+        try:
+            with open(file_path, "rb") as f:
+                sample = f.read(64 * 1024).decode("utf-8", errors="ignore")
+        except OSError:
+            sample = None
+        return detect_language(display_path, sample)
+
+    def _count_generated(self, action: str):
+        """Track skipped/marked generated files for the admin metrics."""
+        try:
+            from src.services.admin.admin_store import get_admin_store
+            counter = "generated_files_skipped" if action == "skip" else "generated_files_marked"
+            get_admin_store().increment_counter(counter)
+        except Exception as e:
+            logger.debug(f"Failed to count generated file: {e}")
+
+    def _count_excluded(self):
+        """Track files refused by exclusion policies for the admin metrics."""
+        try:
+            from src.services.admin.admin_store import get_admin_store
+            get_admin_store().increment_counter("excluded_files")
+        except Exception as e:
+            logger.debug(f"Failed to count excluded file: {e}")
+
+    def _remove_from_bm25_index(self, chunk_ids: List[str]):
+        """Drop chunks from the BM25 sparse index (no-op for SPLADE stores)."""
+        if not chunk_ids:
+            return
+        try:
+            from src.services.retrieval.bm25_index import get_bm25_index
+            get_bm25_index().remove(chunk_ids)
+        except Exception as e:
+            logger.warning(f"Failed to remove chunks from BM25 sparse index: {e}")
+
+    def file_owners(self, display_path: str, org_id: str) -> List[Optional[str]]:
+        """Connections that indexed chunks of a file (None for chunks without one)."""
+        from qdrant_client.models import Filter, FieldCondition, MatchValue
+
         points = self.qdrant.scroll(
-            collection_name=self.collection_name,
+            collection_name=store_collection(org_id),
             scroll_filter=Filter(
-                must=[FieldCondition(key="doc_id", match=MatchValue(value=doc_id))]
+                must=[
+                    FieldCondition(key="full_path", match=MatchValue(value=display_path)),
+                    FieldCondition(key="org_id", match=MatchValue(value=org_id))
+                ]
             ),
             limit=10000,
-            with_payload=False
+            with_payload=["connection_id"],
+            with_vectors=False
         )[0]
+        return sorted({(p.payload or {}).get("connection_id") for p in points}, key=lambda c: c or "")
+
+    def foreign_owners(self, display_path: str, org_id: str, connection_id: str) -> List[str]:
+        """
+        Other connections owning chunks of a file.
+
+        Chunks indexed without a connection (webhooks, admin uploads, older
+        data) belong to nobody and may be replaced by any connection.
+        """
+        return [c for c in self.file_owners(display_path, org_id) if c and c != connection_id]
+
+    def delete_file(self, display_path: str, org_id: str, connection_id: str = None) -> int:
+        """
+        Delete all chunks for a file path within a store.
+
+        Args:
+            display_path: Client-side path the file was indexed under (full_path)
+            org_id: Organization ID
+            connection_id: Only delete chunks this connection indexed
+
+        Returns:
+            Number of chunks removed
+        """
+        from qdrant_client.models import Filter, FieldCondition, MatchValue
+
+        conditions = [
+            FieldCondition(key="full_path", match=MatchValue(value=display_path)),
+            FieldCondition(key="org_id", match=MatchValue(value=org_id))
+        ]
+        if connection_id:
+            conditions.append(FieldCondition(key="connection_id", match=MatchValue(value=connection_id)))
+        file_filter = Filter(must=conditions)
+
+        removed = 0
+        try:
+            logger.info(f"Checking for existing chunks for file: {display_path}")
+            existing_points = self.qdrant.scroll(
+                collection_name=store_collection(org_id),
+                scroll_filter=file_filter,
+                limit=10000,
+                with_payload=False
+            )[0]
+
+            if existing_points:
+                chunk_ids = [str(p.id) for p in existing_points]
+                logger.info(f"Deleting {len(chunk_ids)} existing chunks for {display_path}")
+
+                # Delete from Qdrant (and a running migration's target)
+                for collection_name in write_collections(org_id):
+                    self.qdrant.delete(
+                        collection_name=collection_name,
+                        points_selector=file_filter
+                    )
+                removed = len(chunk_ids)
+
+                # Delete from Tantivy
+                if self.tantivy_client:
+                    for cid in chunk_ids:
+                        try:
+                            self.tantivy_client.delete(cid)
+                        except Exception as e:
+                            logger.warning(f"Failed to delete chunk {cid} from Tantivy: {e}")
+
+                self._remove_from_bm25_index(chunk_ids)
+                get_store_stats().remove_file(org_id, display_path)
+                invalidate_store(org_id)
+                emit("index.file.deleted", path=display_path, org_id=org_id, connection_id=connection_id, chunks_removed=removed)
+        except Exception as e:
+            logger.warning(f"Error checking/deleting existing chunks: {e}")
+
+        # Drop cold tier copies so stale chunks cannot resurface
+        if settings.get("search.tiering.enabled", False):
+            from src.services.search.tiering import get_tier_manager
+            get_tier_manager().delete_matching(file_filter, org_id)
+
+        return removed
+
+    def delete_by_connection(self, connection_id: str, org_id: str = None) -> Dict:
+        """
+        Delete all chunks uploaded by a CLI connection.
+
+        Args:
+            connection_id: Connection that indexed the chunks
+            org_id: Restrict to a single store (default: every store)
+
+        Returns:
+            Dict with chunks_removed and the set of affected files per store
+        """
+        from qdrant_client.models import Filter, FieldCondition, MatchValue
+
+        conditions = [FieldCondition(key="connection_id", match=MatchValue(value=connection_id))]
+        if org_id:
+            conditions.append(FieldCondition(key="org_id", match=MatchValue(value=org_id)))
+        connection_filter = Filter(must=conditions)
+
+        # Collect chunk ids (for Tantivy) and affected files before deleting
+        chunk_ids = []
+        files: Dict[str, set] = {}
+        for collection_name in write_collections(org_id) if org_id else store_collections():
+            found = 0
+            offset = None
+            while True:
+                points, offset = self.qdrant.scroll(
+                    collection_name=collection_name,
+                    scroll_filter=connection_filter,
+                    limit=1000,
+                    offset=offset,
+                    with_payload=["org_id", "full_path"],
+                    with_vectors=False
+                )
+                found += len(points)
+                for p in points:
+                    chunk_ids.append(str(p.id))
+                    payload = p.payload or {}
+                    files.setdefault(payload.get("org_id", "public"), set()).add(payload.get("full_path"))
+                if offset is None or not points:
+                    break
+            if found:
+                self.qdrant.delete(
+                    collection_name=collection_name,
+                    points_selector=connection_filter
+                )
+        # A running migration holds copies of the same chunks
+        chunk_ids = list(dict.fromkeys(chunk_ids))
+
+        if chunk_ids:
+            logger.info(f"Deleted {len(chunk_ids)} chunks from connection {connection_id}")
+
+            if self.tantivy_client:
+                for cid in chunk_ids:
+                    try:
+                        self.tantivy_client.delete(cid)
+                    except Exception as e:
+                        logger.warning(f"Failed to delete chunk {cid} from Tantivy: {e}")
+
+            self._remove_from_bm25_index(chunk_ids)
+            for store, paths in files.items():
+                for path in paths:
+                    if path:
+                        get_store_stats().remove_file(store, path)
+                invalidate_store(store)
+
+        # Cold tier copies carry the same payload
+        if settings.get("search.tiering.enabled", False):
+            from src.services.search.tiering import get_tier_manager
+            get_tier_manager().delete_matching(connection_filter, org_id)
+
+        return {
+            "status": "deleted",
+            "chunks_removed": len(chunk_ids),
+            "files": {store: sorted(f for f in paths if f) for store, paths in files.items()},
+        }
+
+    def delete_document(self, doc_id: str) -> Dict:
+        """Delete all chunks for a document."""
+        from qdrant_client.models import Filter, FieldCondition, MatchValue
+        
+        doc_filter = Filter(must=[FieldCondition(key="doc_id", match=MatchValue(value=doc_id))])
+        collections = store_collections()
+
+        # Get chunk IDs for Tantivy deletion (and files for store stats)
+        points = []
+        for collection_name in collections:
+            points += self.qdrant.scroll(
+                collection_name=collection_name,
+                scroll_filter=doc_filter,
+                limit=10000,
+                with_payload=["org_id", "full_path"]
+            )[0]
         
         chunk_ids = [str(p.id) for p in points]
+        files = {
+            ((p.payload or {}).get("org_id", "public"), (p.payload or {}).get("full_path"))
+            for p in points
+        }
         
         # Delete from Tantivy
         if self.tantivy_client and chunk_ids:
             for cid in chunk_ids:
                 self.tantivy_client.delete(cid)
+
+        self._remove_from_bm25_index(chunk_ids)
         
         # Delete from Qdrant
-        self.qdrant.delete(
-            collection_name=self.collection_name,
-            points_selector=Filter(
-                must=[FieldCondition(key="doc_id", match=MatchValue(value=doc_id))]
+        for collection_name in collections:
+            self.qdrant.delete(
+                collection_name=collection_name,
+                points_selector=doc_filter
             )
-        )
+        for store, path in files:
+            if path:
+                get_store_stats().remove_file(store, path)
+        # Not scoped to a store
+        invalidate_store()
         
         return {"status": "deleted", "chunks_removed": len(chunk_ids)}
+
+
+def ingest_local_files(
+    indexer: "Indexer",
+    root: str,
+    paths: List[str],
+    prefix: str,
+    org_id: str,
+    progress: Optional[Callable[[int, int], None]] = None,
+) -> Dict[str, list]:
+    """
+    Index files under a local directory (a git clone or an extracted
+    archive), stored as ``<prefix>/<path>``.
+
+    Paths that aren't regular files (gone, symlinks, directories) are
+    reported as missing rather than read.
+
+    Returns:
+        Dict of ``indexed``, ``skipped`` and ``missing`` paths and
+        ``failed`` {"path", "error"} entries
+    """
+    outcome = {"indexed": [], "skipped": [], "missing": [], "failed": []}
+    for i, path in enumerate(paths):
+        if progress:
+            progress(i, len(paths))
+        full_path = os.path.join(root, path)
+        if os.path.islink(full_path) or not os.path.isfile(full_path):
+            outcome["missing"].append(path)
+            continue
+        target = f"{prefix}/{path}" if prefix else path
+        try:
+            result = indexer.ingest_file(full_path, target, prefix or "default", org_id)
+        except Exception as e:
+            logger.warning(f"Indexing failed for {target}: {e}")
+            result = {"status": "error", "message": str(e)}
+        if result.get("status") == "error":
+            outcome["failed"].append({"path": path, "error": result.get("message")})
+        elif result.get("status") in ("skipped", "unchanged"):
+            outcome["skipped"].append(path)
+        else:
+            outcome["indexed"].append(path)
+    return outcome
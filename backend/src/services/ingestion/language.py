@@ -0,0 +1,223 @@
+"""
+Language Detection.
+
+Most clients upload files without a language, which leaves ``language``
+empty in chunk payloads and makes language filters match nothing. The index
+pipeline fills it in from, in order:
+
+1. Well-known file names (Dockerfile, Makefile, Gemfile, ...)
+2. File extension
+3. Shebang line (``#!/usr/bin/env python3`` -> python)
+4. Content heuristics, for extension-less files and ambiguous extensions
+   such as ``.h`` (C or C++)
+"""
+
+import os
+import re
+from typing import Dict, List, Optional, Tuple
+
+from src.services.ingestion.ast_parser import EXTENSION_TO_LANGUAGE
+
+FILENAME_TO_LANGUAGE = {
+    "dockerfile": "dockerfile",
+    "containerfile": "dockerfile",
+    "makefile": "makefile",
+    "gnumakefile": "makefile",
+    "cmakelists.txt": "cmake",
+    "gemfile": "ruby",
+    "rakefile": "ruby",
+    "vagrantfile": "ruby",
+    "jenkinsfile": "groovy",
+    "build": "starlark",
+    "build.bazel": "starlark",
+    "workspace": "starlark",
+    "go.mod": "go-mod",
+    ".bashrc": "shell",
+    ".zshrc": "shell",
+    ".profile": "shell",
+}
+
+EXTENSION_LANGUAGES = {
+    **EXTENSION_TO_LANGUAGE,
+    ".pyi": "python",
+    ".mjs": "javascript",
+    ".cjs": "javascript",
+    ".mts": "typescript",
+    ".cxx": "cpp",
+    ".hh": "cpp",
+    ".cs": "csharp",
+    ".kt": "kotlin",
+    ".kts": "kotlin",
+    ".scala": "scala",
+    ".swift": "swift",
+    ".m": "objective-c",
+    ".lua": "lua",
+    ".pl": "perl",
+    ".r": "r",
+    ".dart": "dart",
+    ".ex": "elixir",
+    ".exs": "elixir",
+    ".erl": "erlang",
+    ".hs": "haskell",
+    ".clj": "clojure",
+    ".sh": "shell",
+    ".bash": "shell",
+    ".zsh": "shell",
+    ".ps1": "powershell",
+    ".sql": "sql",
+    ".proto": "protobuf",
+    ".graphql": "graphql",
+    ".tf": "hcl",
+    ".hcl": "hcl",
+    ".vue": "vue",
+    ".svelte": "svelte",
+    ".html": "html",
+    ".htm": "html",
+    ".css": "css",
+    ".scss": "scss",
+    ".md": "markdown",
+    ".ipynb": "jupyter",
+    ".rst": "restructuredtext",
+    ".adoc": "asciidoc",
+    ".txt": "text",
+    ".json": "json",
+    ".yaml": "yaml",
+    ".yml": "yaml",
+    ".toml": "toml",
+    ".ini": "ini",
+    ".xml": "xml",
+    ".pdf": "pdf",
+}
+
+# Extensions shared by several languages; content decides
+AMBIGUOUS_EXTENSIONS = {".h": ("c", "cpp")}
+
+SHEBANG_INTERPRETERS = {
+    "python": "python",
+    "node": "javascript",
+    "nodejs": "javascript",
+    "deno": "typescript",
+    "ts-node": "typescript",
+    "bash": "shell",
+    "sh": "shell",
+    "zsh": "shell",
+    "dash": "shell",
+    "ksh": "shell",
+    "ruby": "ruby",
+    "perl": "perl",
+    "php": "php",
+    "lua": "lua",
+    "rscript": "r",
+    "pwsh": "powershell",
+    "elixir": "elixir",
+}
+
+# (language, pattern) pairs; each match is one vote
+CONTENT_RULES: List[Tuple[str, re.Pattern]] = [
+    (lang, re.compile(pattern, re.MULTILINE))
+    for lang, pattern in [
+        ("go", r"^package \w+\s*$"),
+        ("go", r"^func (\(\w+ \*?\w+\) )?\w+\("),
+        ("go", r":= "),
+        ("python", r"^(from [\w.]+ )?import \w+"),
+        ("python", r"^\s*def \w+\(.*\):\s*$"),
+        ("python", r"^\s*class \w+(\(.*\))?:\s*$"),
+        ("rust", r"^\s*(pub )?fn \w+"),
+        ("rust", r"^use \w+(::\w+)+;"),
+        ("rust", r"\blet mut\b"),
+        ("javascript", r"\b(const|let) \w+ = require\("),
+        ("javascript", r"^\s*module\.exports\b"),
+        ("typescript", r"^\s*(export )?interface \w+ \{"),
+        ("typescript", r":\s*(string|number|boolean)\b[;,)=]"),
+        ("java", r"^\s*public (final )?class \w+"),
+        ("java", r"^import java\."),
+        ("c", r"^#include <\w+\.h>"),
+        ("c", r"\b(malloc|printf|struct \w+ \*)"),
+        ("cpp", r"^#include <\w+>\s*$"),
+        ("cpp", r"\b(std::|namespace \w+|template\s*<)"),
+        ("cpp", r"^\s*class \w+(\s*:\s*public \w+)?\s*\{"),
+        ("ruby", r"^\s*require ['\"]"),
+        ("ruby", r"^\s*def \w+[^:]*$"),
+        ("ruby", r"^\s*end\s*$"),
+        ("php", r"<\?php"),
+        ("shell", r"^\s*(if \[|fi$|esac$|export \w+=)"),
+        ("sql", r"(?i)^\s*(select .+ from|create table|insert into)\b"),
+        ("markdown", r"^#{1,6} \S"),
+        ("markdown", r"^```"),
+    ]
+]
+
+MIN_CONTENT_VOTES = 2
+
+
+def _extension(name: str) -> str:
+    return os.path.splitext(name)[1].lower()
+
+
+def from_shebang(content: str) -> Optional[str]:
+    """Language named by a ``#!`` line, if any."""
+    if not content.startswith("#!"):
+        return None
+    parts = content.splitlines()[0][2:].strip().split()
+    if not parts:
+        return None
+    interpreter = os.path.basename(parts[0])
+    if interpreter == "env":
+        args = [p for p in parts[1:] if not p.startswith("-")]
+        if not args:
+            return None
+        interpreter = os.path.basename(args[0])
+    # python3.11 -> python
+    interpreter = re.sub(r"[\d.]+$", "", interpreter).lower()
+    return SHEBANG_INTERPRETERS.get(interpreter)
+
+
+def from_content(content: str, candidates: Optional[Tuple[str, ...]] = None) -> Optional[str]:
+    """
+    Best language by heuristic votes, or None if nothing is convincing.
+
+    Args:
+        content: File text (a prefix is enough)
+        candidates: Restrict the choice (e.g. c/cpp for ``.h``)
+    """
+    votes: Dict[str, int] = {}
+    for lang, pattern in CONTENT_RULES:
+        if candidates and lang not in candidates:
+            continue
+        if pattern.search(content):
+            votes[lang] = votes.get(lang, 0) + 1
+    if not votes:
+        return candidates[0] if candidates else None
+    lang, count = max(votes.items(), key=lambda x: x[1])
+    if candidates:
+        return lang
+    return lang if count >= MIN_CONTENT_VOTES else None
+
+
+def detect_language(path: str, content: Optional[str] = None) -> Optional[str]:
+    """
+    Detect a file's language.
+
+    Args:
+        path: File path (only the name is used)
+        content: File text, used for shebangs and heuristics
+
+    Returns:
+        Language name (matching the AST parser's names where they overlap),
+        or None if unknown
+    """
+    name = os.path.basename(path.replace("\\", "/"))
+    lowered = name.lower()
+
+    if lowered in FILENAME_TO_LANGUAGE:
+        return FILENAME_TO_LANGUAGE[lowered]
+
+    ext = _extension(lowered)
+    if ext in AMBIGUOUS_EXTENSIONS:
+        return from_content(content or "", AMBIGUOUS_EXTENSIONS[ext])
+    if ext in EXTENSION_LANGUAGES:
+        return EXTENSION_LANGUAGES[ext]
+
+    if not content:
+        return None
+    return from_shebang(content) or from_content(content[:20000])
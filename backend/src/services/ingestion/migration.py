@@ -0,0 +1,338 @@
+"""
+Embedding Model Migration.
+
+Changing the default embedding model leaves a store's vectors from the old
+model in place: similarity against new query vectors is meaningless, and a
+different dimension breaks dense search and indexing outright. Stores record
+the model and dimension their vectors were built with; ``embedding_status``
+compares that (or, for stores that predate the record, the collection's
+dimension) against the configured model.
+
+A migration re-embeds every chunk of a store from its stored text into a
+new per-store collection (sparse vectors and payloads are copied as-is,
+the collection is created with the store's ``collection_config``), then
+switches the store over to it. Files indexed while it runs are written
+to both collections. Progress is reported through the Celery task state
+(``GET /api/v1/admin/public/jobs/{task_id}``).
+"""
+
+import logging
+import re
+from datetime import datetime
+from typing import Any, Callable, Dict, List, Optional
+
+from qdrant_client.models import FieldCondition, Filter, MatchValue, PointStruct
+
+from src.core.config import settings
+
+logger = logging.getLogger(__name__)
+
+RUNNING = "running"
+COMPLETE = "complete"
+FAILED = "failed"
+
+
+def configured_model() -> str:
+    """The default embedding model new vectors are built with."""
+    return settings.EMBEDDING_MODEL
+
+
+def configured_dimension() -> int:
+    """Dense dimension new collections are created with."""
+    return int(settings.get("models.embedding.fallback_dimension", settings.EMBEDDING_DIM))
+
+
+def _store(store_id: Optional[str]) -> Dict[str, Any]:
+    if not store_id:
+        return {}
+    try:
+        from src.services.admin.admin_store import get_admin_store
+        return get_admin_store().get_stores().get(store_id) or {}
+    except Exception as e:
+        logger.debug(f"Could not read store {store_id}: {e}")
+        return {}
+
+
+def store_collection(store_id: Optional[str]) -> str:
+    """Collection holding a store's chunks (its migrated collection, else the shared one)."""
+    return _store(store_id).get("collection") or settings.COLLECTION_PREFIX
+
+
+def write_collections(store_id: Optional[str]) -> List[str]:
+    """Collections new chunks go to: the store's, plus a running migration's target."""
+    store = _store(store_id)
+    collections = [store.get("collection") or settings.COLLECTION_PREFIX]
+    migration = store.get("migration") or {}
+    if migration.get("state") == RUNNING and migration.get("collection") not in collections:
+        collections.append(migration["collection"])
+    return collections
+
+
+def store_collections(store_id: Optional[str] = None) -> List[str]:
+    """
+    Collections holding a store's chunks (``store_collection``); without a
+    store, the shared collection and every migrated store's own.
+    """
+    if store_id:
+        return [store_collection(store_id)]
+    names = [settings.COLLECTION_PREFIX]
+    try:
+        from src.services.admin.admin_store import get_admin_store
+        names += [store.get("collection") for store in get_admin_store().get_stores().values()]
+    except Exception as e:
+        logger.warning(f"Could not list store collections: {e}")
+    return [n for n in dict.fromkeys(names) if n]
+
+
+def migration_collection(store_id: str, model: str) -> str:
+    """Per-store collection for vectors built with ``model``."""
+    slug = re.sub(r"[^a-z0-9]+", "_", model.lower()).strip("_")
+    return f"{settings.COLLECTION_PREFIX}_{store_id}_{slug}"
+
+
+def collection_dimension(qdrant, collection_name: str) -> Optional[int]:
+    """Size of a collection's ``dense`` vectors, None if it doesn't exist."""
+    try:
+        vectors = qdrant.get_collection(collection_name).config.params.vectors
+    except Exception:
+        return None
+    dense = vectors.get("dense") if isinstance(vectors, dict) else vectors
+    return getattr(dense, "size", None)
+
+
+def embedding_status(store_id: str, qdrant) -> Dict[str, Any]:
+    """
+    Whether a store's vectors match the configured embedding model.
+
+    Returns:
+        Dict with the store's model/dimension/collection, the configured
+        model/dimension, ``mismatch`` and a human-readable ``reason``
+    """
+    store = _store(store_id)
+    collection = store.get("collection") or settings.COLLECTION_PREFIX
+    model = store.get("embedding_model")
+    # The collection's actual dimension wins over what the store recorded
+    dimension = collection_dimension(qdrant, collection) or store.get("embedding_dim")
+    current_model, current_dimension = configured_model(), configured_dimension()
+
+    reason = None
+    if dimension and dimension != current_dimension:
+        reason = f"Vectors have {dimension} dimensions; {current_model} produces {current_dimension}"
+    elif model and model != current_model:
+        reason = f"Vectors were built with {model}; the default model is now {current_model}"
+
+    return {
+        "store": store_id,
+        "collection": collection,
+        "model": model,
+        "dimension": dimension,
+        "current_model": current_model,
+        "current_dimension": current_dimension,
+        "mismatch": reason is not None,
+        "reason": reason,
+        "migration": store.get("migration"),
+    }
+
+
+def _update_store(store_id: str, **fields):
+    from src.services.admin.admin_store import get_admin_store
+    admin_store = get_admin_store()
+    store = admin_store.get_stores().get(store_id) or {}
+    admin_store.set_store(store_id, {**store, **fields})
+
+
+def _embedding_text(payload: Dict[str, Any]) -> str:
+    from src.services.search.experiments import embedding_text
+    return embedding_text(payload)
+
+
+class StoreMigration:
+    """Re-embeds a store's chunks into a new collection and switches it over."""
+
+    def __init__(self, qdrant_client=None, embed: Callable[[List[str]], List[List[float]]] = None):
+        self._qdrant = qdrant_client
+        self._embed = embed
+
+    @property
+    def qdrant(self):
+        """Lazy Qdrant client."""
+        if self._qdrant is None:
+            from src.db.qdrant import get_qdrant_client
+            self._qdrant = get_qdrant_client()
+        return self._qdrant
+
+    @property
+    def batch_size(self) -> int:
+        return int(settings.get("migration.batch_size", 64))
+
+    def embed(self, texts: List[str]) -> List[List[float]]:
+        if self._embed is not None:
+            return self._embed(texts)
+        from src.services.search.retriever import embed_texts
+        return embed_texts(texts)
+
+    def _store_filter(self, store_id: str) -> Filter:
+        return Filter(must=[FieldCondition(key="org_id", match=MatchValue(value=store_id))])
+
+    def plan(self, store_id: str) -> Dict[str, Any]:
+        """Source and target collection and chunk count (raises ValueError if not possible)."""
+        from src.services.admin.admin_store import get_admin_store
+        store = get_admin_store().get_stores().get(store_id)
+        if store is None:
+            raise ValueError(f"Store {store_id} not found")
+        if store.get("privacy_mode"):
+            raise ValueError(f"Store {store_id} is in privacy mode; there is no stored content to re-embed")
+        if (store.get("migration") or {}).get("state") == RUNNING:
+            raise ValueError(f"Store {store_id} already has a migration running")
+
+        model = configured_model()
+        source = store.get("collection") or settings.COLLECTION_PREFIX
+        target = migration_collection(store_id, model)
+        if target == source:
+            raise ValueError(f"Store {store_id} is already on {model}")
+        total = self.qdrant.count(
+            collection_name=source, count_filter=self._store_filter(store_id), exact=True
+        ).count
+        return {"store": store_id, "model": model, "source": source, "target": target, "total": total}
+
+    def start(self, store_id: str, task_id: Optional[str] = None) -> Dict[str, Any]:
+        """
+        Create the target collection and mark the migration as running, so
+        files indexed from now on also go to the target.
+        """
+        plan = self.plan(store_id)
+        plan["dimension"] = len(self.embed(["dimension probe"])[0])
+        self._create_collection(store_id, plan["target"], plan["dimension"])
+        _update_store(store_id, migration={
+            "state": RUNNING,
+            "task_id": task_id,
+            "model": plan["model"],
+            "collection": plan["target"],
+            "dimension": plan["dimension"],
+            "started_at": datetime.now().isoformat(),
+        })
+        return plan
+
+    def _create_collection(self, store_id: str, name: str, dimension: int):
+        from src.services.ingestion.collection_config import create_collection
+        # Recreated so a failed earlier attempt can't leave stale points
+        try:
+            self.qdrant.delete_collection(name)
+        except Exception:
+            pass
+        create_collection(self.qdrant, name, dimension, _store(store_id).get("collection_config"))
+
+    def _embed_docs(self, points: List[Any], vectors: List[List[float]]) -> List[Dict[str, Any]]:
+        """``doc`` vectors for migrated points ({} where a chunk has none)."""
+        from src.services.ingestion.doc_vectors import DOC_VECTOR, doc_texts, doc_vectors_enabled
+        if not doc_vectors_enabled():
+            return [{} for _ in points]
+        from src.db.content_store import payload_text
+        payloads = [p.payload or {} for p in points]
+        texts = doc_texts(
+            ({"content": payload_text(payload), "metadata": payload} for payload in payloads),
+            [payload.get("summary") for payload in payloads],
+        )
+        wanted = [t for t in texts if t]
+        embedded = iter(self.embed(wanted) if wanted else [])
+        return [
+            {DOC_VECTOR: next(embedded)} if t else ({DOC_VECTOR: vector} if t == "" else {})
+            for t, vector in zip(texts, vectors)
+        ]
+
+    def run(
+        self,
+        store_id: str,
+        progress: Optional[Callable[[int, int], None]] = None,
+    ) -> Dict[str, Any]:
+        """
+        Re-embed every chunk of a store and switch the store to the new collection.
+
+        Args:
+            store_id: Store to migrate (``start`` must have been called)
+            progress: Called with (migrated, total) after each batch
+
+        Returns:
+            Dict with source/target collections, model and chunk counts
+        """
+        store = _store(store_id)
+        migration = store.get("migration") or {}
+        if migration.get("state") != RUNNING:
+            raise ValueError(f"Store {store_id} has no migration running")
+        source = store.get("collection") or settings.COLLECTION_PREFIX
+        target, model, dimension = migration["collection"], migration["model"], migration["dimension"]
+        total = self.qdrant.count(
+            collection_name=source, count_filter=self._store_filter(store_id), exact=True
+        ).count
+
+        try:
+            migrated = 0
+            offset = None
+            while True:
+                points, offset = self.qdrant.scroll(
+                    collection_name=source,
+                    scroll_filter=self._store_filter(store_id),
+                    limit=self.batch_size,
+                    offset=offset,
+                    with_payload=True,
+                    with_vectors=["splade", "bm42"],
+                )
+                if points:
+                    vectors = self.embed([_embedding_text(p.payload or {}) for p in points])
+                    doc_vectors = self._embed_docs(points, vectors)
+                    self.qdrant.upsert(
+                        collection_name=target,
+                        points=[
+                            PointStruct(
+                                id=point.id,
+                                vector={**(point.vector or {}), "dense": vector, **doc},
+                                payload=point.payload,
+                            )
+                            for point, vector, doc in zip(points, vectors, doc_vectors)
+                        ],
+                    )
+                    migrated += len(points)
+                    if progress:
+                        progress(migrated, total)
+                if offset is None:
+                    break
+        except Exception as e:
+            logger.error(f"Migration of {store_id} to {target} failed: {e}")
+            _update_store(store_id, migration={**migration, "state": FAILED, "error": str(e)})
+            raise
+
+        # Switch over, then drop the store's old vectors
+        _update_store(
+            store_id,
+            collection=target,
+            embedding_model=model,
+            embedding_dim=dimension,
+            migration={**migration, "state": COMPLETE, "migrated": migrated,
+                       "finished_at": datetime.now().isoformat()},
+        )
+        self._drop_source(store_id, source)
+        from src.services.search.query_cache import invalidate_store
+        invalidate_store(store_id)
+        logger.info(f"Migrated {migrated} chunks of {store_id} from {source} to {target}")
+        return {"store": store_id, "model": model, "source": source, "target": target,
+                "dimension": dimension, "migrated": migrated, "total": total}
+
+    def _drop_source(self, store_id: str, source: str):
+        try:
+            if source == settings.COLLECTION_PREFIX:
+                self.qdrant.delete(collection_name=source, points_selector=self._store_filter(store_id))
+            else:
+                self.qdrant.delete_collection(source)
+        except Exception as e:
+            logger.warning(f"Failed to remove old vectors of {store_id} from {source}: {e}")
+
+
+# Singleton instance
+_store_migration: Optional[StoreMigration] = None
+
+def get_store_migration() -> StoreMigration:
+    """Get global store migration instance."""
+    global _store_migration
+    if _store_migration is None:
+        _store_migration = StoreMigration()
+    return _store_migration
@@ -0,0 +1,102 @@
+"""
+Natural Language Detection.
+
+Documentation chunks (Markdown, reStructuredText, plain text, ...) get a
+``content_language`` payload field with the ISO 639-1 code of the human
+language they are written in, so searches can be limited to, say, German
+docs. This is separate from ``language``, the file's programming or markup
+language.
+
+Detection is dictionary-free and cheap enough to run on every chunk:
+
+1. Scripts: kana -> ja, Hangul -> ko, Han only -> zh, Cyrillic -> ru,
+   Greek -> el, Arabic -> ar, Hebrew -> he
+2. Latin text: stopword profiles for en, de, fr, es, it, pt, nl
+
+Code blocks, inline code and URLs are ignored. Chunks that are too short
+or match no profile confidently are left without a language.
+"""
+
+import re
+from collections import Counter
+from typing import Optional
+
+from src.core.config import settings
+
+DOC_LANGUAGES = {"markdown", "restructuredtext", "text", "asciidoc", "org", "html", "latex"}
+
+STOPWORDS = {
+    "en": {"the", "and", "of", "to", "is", "in", "that", "it", "for", "with", "as", "on", "this",
+           "are", "be", "you", "can", "by", "or", "from", "an", "not", "when", "which", "will"},
+    "de": {"der", "die", "das", "und", "ist", "nicht", "ein", "eine", "zu", "den", "mit", "von",
+           "sie", "auf", "für", "dem", "des", "sich", "auch", "wird", "werden", "oder", "wenn", "kann"},
+    "fr": {"le", "la", "les", "et", "est", "des", "une", "un", "du", "que", "pour", "dans", "pas",
+           "sur", "avec", "qui", "ce", "sont", "vous", "peut", "être", "par", "au", "aux"},
+    "es": {"el", "la", "los", "las", "y", "es", "que", "de", "en", "un", "una", "por", "para",
+           "con", "del", "se", "no", "lo", "como", "más", "puede", "está", "al", "sus"},
+    "it": {"il", "la", "gli", "le", "e", "è", "che", "di", "un", "una", "per", "con", "non",
+           "del", "della", "sono", "questo", "come", "anche", "può", "nel", "alla", "dei", "si"},
+    "pt": {"o", "a", "os", "as", "e", "é", "que", "de", "um", "uma", "para", "com", "não", "do",
+           "da", "em", "no", "na", "se", "por", "são", "pode", "mais", "dos"},
+    "nl": {"de", "het", "een", "en", "is", "van", "dat", "niet", "te", "op", "met", "voor",
+           "zijn", "wordt", "ook", "aan", "als", "bij", "kan", "worden", "deze", "naar", "er", "om"},
+}
+
+_SCRIPTS = [
+    ("ja", re.compile(r"[぀-ヿ]")),
+    ("ko", re.compile(r"[가-힯]")),
+    ("zh", re.compile(r"[一-鿿]")),
+    ("ru", re.compile(r"[Ѐ-ӿ]")),
+    ("el", re.compile(r"[Ͱ-Ͽ]")),
+    ("ar", re.compile(r"[؀-ۿ]")),
+    ("he", re.compile(r"[֐-׿]")),
+]
+_LETTER = re.compile(r"[^\W\d_]", re.UNICODE)
+_WORD = re.compile(r"[^\W\d_]+", re.UNICODE)
+_NOISE = re.compile(r"```.*?```|~~~.*?~~~|`[^`\n]*`|https?://\S+|<[^>]+>", re.DOTALL)
+
+
+def is_docs_chunk(language: Optional[str]) -> bool:
+    """Whether chunks of a file language are prose worth detecting."""
+    return (language or "").lower() in DOC_LANGUAGES
+
+
+def _strip_noise(text: str) -> str:
+    """Drop code blocks, inline code, URLs and tags."""
+    return _NOISE.sub(" ", text)
+
+
+def detect_natural_language(text: str, min_chars: Optional[int] = None) -> Optional[str]:
+    """
+    ISO 639-1 code of the text's language, or None if unsure.
+
+    Args:
+        text: Chunk content
+        min_chars: Minimum letters needed (default ``indexing.content_language.min_chars``)
+    """
+    if min_chars is None:
+        min_chars = int(settings.get("indexing.content_language.min_chars", 40))
+
+    prose = _strip_noise(text)
+    letters = _LETTER.findall(prose)
+    if len(letters) < min_chars:
+        return None
+
+    # Non-Latin scripts: a script covering a fair share of letters decides
+    for code, pattern in _SCRIPTS:
+        share = len(pattern.findall(prose)) / len(letters)
+        # Japanese mixes kana with Han; a little kana is enough
+        if share >= (0.05 if code == "ja" else 0.3):
+            return code
+
+    words = [w.lower() for w in _WORD.findall(prose)]
+    if len(words) < 5:
+        return None
+    counts = Counter(words)
+    scores = {code: sum(counts[w] for w in stopwords) for code, stopwords in STOPWORDS.items()}
+    best = max(scores, key=scores.get)
+    ranked = sorted(scores.values(), reverse=True)
+    # Needs enough stopwords overall and a clear lead over the runner-up
+    if ranked[0] < max(2, len(words) * 0.08) or ranked[0] < ranked[1] * 1.3:
+        return None
+    return best
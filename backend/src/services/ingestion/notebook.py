@@ -0,0 +1,94 @@
+"""
+Jupyter Notebook Chunking.
+
+A notebook is a JSON document; embedding it as text mixes cell sources with
+escaped quotes, execution counts and base64 outputs. Instead each code and
+markdown cell becomes its own chunk (long cells are split on paragraph
+boundaries), carrying:
+
+- ``cell_index``: position of the cell in the notebook (0-based)
+- ``cell_type``: ``code`` or ``markdown``
+- ``language``: the kernel's language for code cells (``python``, ``r``,
+  ``julia``, ...) and ``markdown`` for markdown cells
+
+Outputs and raw cells are not indexed. Line numbers refer to the JSON file,
+so ``start_line``/``end_line`` stay 0 for notebook chunks.
+"""
+
+import json
+from typing import Any, Dict, List, Optional
+
+from src.core.config import settings
+from src.services.ingestion.docs_chunker import split_section
+
+NOTEBOOK_LANGUAGE = "jupyter"
+INDEXED_CELL_TYPES = ("code", "markdown")
+
+
+class NotebookError(ValueError):
+    """A file that is not a readable notebook."""
+
+
+def is_notebook(path: str, language: Optional[str] = None) -> bool:
+    """Whether a file is a notebook (by detected language or extension)."""
+    return language == NOTEBOOK_LANGUAGE or path.lower().endswith(".ipynb")
+
+
+def kernel_language(notebook: Dict[str, Any]) -> Optional[str]:
+    """Language of the notebook's kernel, from ``language_info`` or ``kernelspec``."""
+    metadata = notebook.get("metadata") or {}
+    language = (
+        (metadata.get("language_info") or {}).get("name")
+        or (metadata.get("kernelspec") or {}).get("language")
+    )
+    return str(language).lower() if language else None
+
+
+def cell_source(cell: Dict[str, Any]) -> str:
+    source = cell.get("source", "")
+    return "".join(source) if isinstance(source, list) else str(source or "")
+
+
+def chunk_notebook(text: str, metadata: Dict[str, Any]) -> List[Dict]:
+    """
+    One chunk per code/markdown cell, in the ``DocumentChunker`` format.
+
+    Raises:
+        NotebookError: invalid JSON or no ``cells`` list
+    """
+    try:
+        notebook = json.loads(text)
+    except ValueError as e:
+        raise NotebookError(f"Invalid notebook JSON: {e}")
+    cells = notebook.get("cells") if isinstance(notebook, dict) else None
+    if not isinstance(cells, list):
+        raise NotebookError("Notebook has no cells (nbformat 4 required)")
+
+    code_language = kernel_language(notebook) or "python"
+    max_chars = int(settings.get("indexing.notebook.max_cell_chars", 2000))
+
+    chunks: List[Dict] = []
+    for index, cell in enumerate(cells):
+        cell_type = cell.get("cell_type") if isinstance(cell, dict) else None
+        if cell_type not in INDEXED_CELL_TYPES:
+            continue
+        lines = cell_source(cell).split("\n")
+        for start, end in split_section(lines, 0, max_chars):
+            content = "\n".join(lines[start:end]).strip("\n")
+            if not content.strip():
+                continue
+            chunks.append({
+                "content": content,
+                "metadata": {
+                    **metadata,
+                    "chunk_type": f"{cell_type}_cell",
+                    "language": code_language if cell_type == "code" else "markdown",
+                    "notebook_language": code_language,
+                    "cell_index": index,
+                    "cell_type": cell_type,
+                    "start_line": 0,
+                    "end_line": 0,
+                },
+                "chunk_index": len(chunks),
+            })
+    return chunks
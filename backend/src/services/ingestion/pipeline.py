@@ -0,0 +1,211 @@
+"""
+Index Pipeline Limits.
+
+Bounded concurrency and backpressure for indexing, so a large scan cannot
+saturate the ML service and Qdrant and starve searches:
+
+- each stage of ``Indexer.ingest_file`` (chunking, summaries, embedding,
+  upsert) runs at most ``indexing.pipeline.<stage>.workers`` files at a
+  time per worker process; at most ``queue`` more wait for a slot, beyond that the file is
+  refused with ``PipelineBusy`` and the task retried later
+- uploads are refused with 429 and Retry-After while more than
+  ``indexing.pipeline.max_queued_files`` files wait for a worker; clients
+  back off and resend
+
+Stage depths are published to Redis per worker process (expiring with the
+process) and exported with the queue depth on ``/metrics``.
+"""
+
+import logging
+import os
+import socket
+import threading
+from contextlib import contextmanager
+from typing import Dict, Optional
+
+import redis
+
+from src.core.config import settings
+
+logger = logging.getLogger(__name__)
+
+STAGES = ("chunking", "summaries", "embedding", "upsert")
+
+# Celery's default queue (a Redis list on the broker)
+CELERY_QUEUE = "celery"
+
+# Per-process stage snapshots, kept alive by activity
+SNAPSHOT_PREFIX = "rice:pipeline:stages"
+SNAPSHOT_TTL_SECONDS = 120
+
+DEFAULT_WORKERS = {"chunking": 4, "summaries": 1, "embedding": 2, "upsert": 2}
+
+
+class PipelineBusy(Exception):
+    """A stage's wait queue is full; try again after ``retry_after`` seconds."""
+
+    def __init__(self, stage: str, retry_after: int):
+        super().__init__(f"Index pipeline busy ({stage} queue full)")
+        self.stage = stage
+        self.retry_after = retry_after
+
+
+def retry_after_seconds() -> int:
+    return int(settings.get("indexing.pipeline.retry_after_seconds", 5))
+
+
+class StageLimiter:
+    """Worker slots and wait queues for the index pipeline stages."""
+
+    def __init__(self, redis_client=None):
+        self._redis = redis_client
+        self._lock = threading.Lock()
+        self._slots: Dict[str, threading.BoundedSemaphore] = {}
+        self._active = {stage: 0 for stage in STAGES}
+        self._waiting = {stage: 0 for stage in STAGES}
+        self._snapshot_key = f"{SNAPSHOT_PREFIX}:{socket.gethostname()}:{os.getpid()}"
+
+    @property
+    def redis(self) -> redis.Redis:
+        """Lazy Redis connection."""
+        if self._redis is None:
+            self._redis = redis.from_url(settings.REDIS_URL, decode_responses=True)
+        return self._redis
+
+    def workers(self, stage: str) -> int:
+        return max(1, int(settings.get(f"indexing.pipeline.{stage}.workers", DEFAULT_WORKERS[stage])))
+
+    def queue_limit(self, stage: str) -> int:
+        return max(0, int(settings.get(f"indexing.pipeline.{stage}.queue", 32)))
+
+    def _slot(self, stage: str) -> threading.BoundedSemaphore:
+        # Sized on first use; worker counts apply on restart
+        if stage not in self._slots:
+            self._slots[stage] = threading.BoundedSemaphore(self.workers(stage))
+        return self._slots[stage]
+
+    @contextmanager
+    def stage(self, stage: str):
+        """
+        Run a block in one of the stage's worker slots.
+
+        Raises:
+            PipelineBusy: the stage's wait queue is full
+        """
+        with self._lock:
+            slot = self._slot(stage)
+            waits = not slot.acquire(blocking=False)
+            if waits:
+                if self._waiting[stage] >= self.queue_limit(stage):
+                    raise PipelineBusy(stage, retry_after_seconds())
+                self._waiting[stage] += 1
+            else:
+                self._active[stage] += 1
+        self._publish()
+
+        if waits:
+            slot.acquire()
+            with self._lock:
+                self._waiting[stage] -= 1
+                self._active[stage] += 1
+            self._publish()
+        try:
+            yield
+        finally:
+            with self._lock:
+                self._active[stage] -= 1
+            slot.release()
+            self._publish()
+
+    def depths(self) -> Dict[str, Dict[str, int]]:
+        """Active and waiting files per stage in this process."""
+        with self._lock:
+            return {
+                stage: {"active": self._active[stage], "waiting": self._waiting[stage]}
+                for stage in STAGES
+            }
+
+    def _publish(self):
+        """Store this process's depths for /metrics (never fails indexing)."""
+        mapping = {
+            f"{stage}:{kind}": count
+            for stage, counts in self.depths().items()
+            for kind, count in counts.items()
+        }
+        try:
+            pipe = self.redis.pipeline()
+            pipe.hset(self._snapshot_key, mapping=mapping)
+            pipe.expire(self._snapshot_key, SNAPSHOT_TTL_SECONDS)
+            pipe.execute()
+        except Exception as e:
+            logger.debug(f"Failed to publish pipeline depths: {e}")
+
+
+def queued_files(redis_client=None) -> Optional[int]:
+    """Index tasks waiting for a worker, or None if the broker is unreachable."""
+    try:
+        client = redis_client or get_stage_limiter().redis
+        return int(client.llen(CELERY_QUEUE))
+    except Exception as e:
+        logger.warning(f"Could not read index queue depth: {e}")
+        return None
+
+
+def check_admission(redis_client=None) -> Optional[int]:
+    """
+    Seconds an uploader should wait before retrying, or None to accept.
+
+    Uploads are accepted when the broker cannot be read; the worker stages
+    still bound the load.
+    """
+    limit = int(settings.get("indexing.pipeline.max_queued_files", 1000))
+    if limit <= 0:
+        return None
+    queued = queued_files(redis_client)
+    if queued is None or queued < limit:
+        return None
+    return retry_after_seconds()
+
+
+def stage_depths(redis_client=None) -> Dict[str, Dict[str, int]]:
+    """Active and waiting files per stage, summed over worker processes."""
+    totals = {stage: {"active": 0, "waiting": 0} for stage in STAGES}
+    client = redis_client or get_stage_limiter().redis
+    for key in client.scan_iter(f"{SNAPSHOT_PREFIX}:*"):
+        for field, count in (client.hgetall(key) or {}).items():
+            stage, _, kind = field.partition(":")
+            if stage in totals and kind in totals[stage]:
+                totals[stage][kind] += int(count)
+    return totals
+
+
+def prometheus_lines(redis_client=None):
+    """Queue and stage depth gauges for ``/metrics``."""
+    lines = []
+    queued = queued_files(redis_client)
+    if queued is not None:
+        lines.append("# HELP rice_search_index_queue_depth Files waiting for an index worker")
+        lines.append("# TYPE rice_search_index_queue_depth gauge")
+        lines.append(f"rice_search_index_queue_depth {queued}")
+    try:
+        depths = stage_depths(redis_client)
+    except Exception as e:
+        logger.warning(f"Could not read pipeline stage depths: {e}")
+        return lines
+    lines.append("# HELP rice_search_index_stage_files Files in an index pipeline stage, by state")
+    lines.append("# TYPE rice_search_index_stage_files gauge")
+    for stage, counts in depths.items():
+        for kind, count in counts.items():
+            lines.append(f'rice_search_index_stage_files{{stage="{stage}",state="{kind}"}} {count}')
+    return lines
+
+
+# Singleton instance
+_stage_limiter: Optional[StageLimiter] = None
+
+def get_stage_limiter() -> StageLimiter:
+    """Get global stage limiter instance."""
+    global _stage_limiter
+    if _stage_limiter is None:
+        _stage_limiter = StageLimiter()
+    return _stage_limiter
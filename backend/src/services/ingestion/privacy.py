@@ -0,0 +1,81 @@
+"""
+Store Privacy Mode.
+
+Stores with ``privacy_mode`` keep vectors and location metadata (path, line
+range, language, symbols) but never the chunk text: the ``text`` payload is
+not written (nor LLM summaries of it), search results carry
+``content_stored: false`` and clients read the lines from their local
+checkout. Lexical indexes keep terms, not text
+(Tantivy indexes without storing; the BM25 backend stores term counts).
+
+Reranking and RAG need the text, so they are skipped or refused for these
+stores.
+"""
+
+import logging
+from typing import Any, Dict, Optional
+
+from qdrant_client.models import FieldCondition, Filter, MatchValue
+
+from src.core.config import settings
+
+logger = logging.getLogger(__name__)
+
+# Payload fields holding raw content
+CONTENT_FIELDS = ["text"]
+# Payload fields pointing at content kept in the content store
+CONTENT_REF_FIELDS = ["content_ref", "file_ref"]
+# Payload fields derived from content (LLM summaries)
+DERIVED_FIELDS = ["summary"]
+
+
+def is_private_store(org_id: Optional[str]) -> bool:
+    """Whether a store is in privacy mode (no content stored)."""
+    if not org_id:
+        return False
+    try:
+        from src.services.admin.admin_store import get_admin_store
+        return bool(get_admin_store().get_stores().get(org_id, {}).get("privacy_mode"))
+    except Exception as e:
+        logger.debug(f"Could not read privacy mode for {org_id}: {e}")
+        return False
+
+
+def strip_content(payload: Dict[str, Any]) -> Dict[str, Any]:
+    """Copy of a chunk payload without its content fields."""
+    return {k: v for k, v in payload.items() if k not in CONTENT_FIELDS + CONTENT_REF_FIELDS + DERIVED_FIELDS}
+
+
+def purge_store_content(qdrant, org_id: str):
+    """
+    Remove stored content from a store's chunks (hot and cold tier) when
+    privacy mode is turned on.
+    """
+    from src.services.ingestion.migration import store_collection
+    from src.services.search.tiering import store_cold_collection
+
+    store_filter = Filter(must=[FieldCondition(key="org_id", match=MatchValue(value=org_id))])
+    for collection_name in [store_collection(org_id), store_cold_collection(org_id)]:
+        try:
+            qdrant.delete_payload(
+                collection_name=collection_name,
+                keys=CONTENT_FIELDS,
+                points=store_filter,
+            )
+            qdrant.delete_payload(
+                collection_name=collection_name,
+                keys=CONTENT_REF_FIELDS,
+                points=store_filter,
+            )
+            qdrant.delete_payload(
+                collection_name=collection_name,
+                keys=DERIVED_FIELDS,
+                points=store_filter,
+            )
+            qdrant.set_payload(
+                collection_name=collection_name,
+                payload={"content_stored": False},
+                points=store_filter,
+            )
+        except Exception as e:
+            logger.debug(f"Content purge in {collection_name} skipped: {e}")
@@ -0,0 +1,275 @@
+"""
+Reindex Planning.
+
+Compares a client's file list (paths plus normalized content hashes, see
+``hashing.py``) with what a store has indexed, so operators can see whether
+a reindex would change anything before uploading:
+
+- added: paths not indexed yet
+- removed: indexed paths missing from the list
+- changed: hash differs, or the indexed copy predates the current
+  ``hash_version`` (it would be re-embedded)
+- unchanged: hash matches; the indexer would skip the upload
+
+Previews only read. Applying a plan returns the paths that still need
+uploading and, when asked to, drops removed files; a plan built from an
+empty file list is never applied, since it would remove every file.
+
+``check_hashes`` is the lighter pre-flight the CLI runs before every scan:
+it only looks up the listed paths and answers which need uploading, so a
+run over an unchanged repository transfers hashes instead of the files.
+"""
+
+import logging
+from typing import Any, Dict, List, Optional, Tuple
+
+from qdrant_client.models import Filter, FieldCondition, MatchAny, MatchValue
+
+from src.core.config import settings
+from src.services.ingestion.hashing import HASH_VERSION
+
+logger = logging.getLogger(__name__)
+
+PAYLOAD_FIELDS = ["full_path", "file_hash", "hash_version"]
+
+# Cap on paths listed per category in a plan
+MAX_LISTED_PATHS = 1000
+
+
+class ReindexPlanner:
+    """Diffs incoming file hashes against a store's indexed files."""
+
+    def __init__(self, qdrant_client=None):
+        self._qdrant = qdrant_client
+
+    @property
+    def qdrant(self):
+        """Lazy Qdrant client."""
+        if self._qdrant is None:
+            from src.db.qdrant import get_qdrant_client
+            self._qdrant = get_qdrant_client()
+        return self._qdrant
+
+    def collections(self, org_id: Optional[str] = None) -> List[str]:
+        """Chunk collections to scan (hot tiers plus cold tiers if present)."""
+        from src.services.search.tiering import tier_collections
+        return tier_collections(self.qdrant, org_id)
+
+    def indexed_files(self, org_id: str, batch_size: int = 1024) -> Dict[str, Dict[str, Any]]:
+        """
+        Indexed files in a store.
+
+        Returns:
+            Dict of path -> {"hash", "hash_version", "chunks"}
+        """
+        files: Dict[str, Dict[str, Any]] = {}
+        store_filter = Filter(must=[FieldCondition(key="org_id", match=MatchValue(value=org_id))])
+        for collection_name in self.collections(org_id):
+            offset = None
+            while True:
+                points, offset = self.qdrant.scroll(
+                    collection_name=collection_name,
+                    scroll_filter=store_filter,
+                    limit=batch_size,
+                    offset=offset,
+                    with_payload=PAYLOAD_FIELDS,
+                    with_vectors=False
+                )
+                for point in points:
+                    payload = point.payload or {}
+                    path = payload.get("full_path")
+                    if not path:
+                        continue
+                    entry = files.setdefault(path, {"hash": None, "hash_version": None, "chunks": 0})
+                    entry["chunks"] += 1
+                    if payload.get("file_hash"):
+                        entry["hash"] = payload["file_hash"]
+                        entry["hash_version"] = payload.get("hash_version")
+                if offset is None or not points:
+                    break
+        return files
+
+    def plan(
+        self,
+        org_id: str,
+        incoming: List[Dict[str, Any]],
+        max_paths: Optional[int] = MAX_LISTED_PATHS
+    ) -> Dict[str, Any]:
+        """
+        Diff a file list against the store.
+
+        Args:
+            org_id: Store
+            incoming: [{"path", "hash", "size" (optional, bytes)}]
+            max_paths: Cap on paths listed per category (None: all)
+
+        Returns:
+            File and chunk counts per category, ``noop`` when nothing would
+            change, and the affected paths
+        """
+        indexed = self.indexed_files(org_id)
+        chunk_size = max(1, int(settings.get("indexing.chunk_size", 1000)))
+
+        added, changed, unchanged = [], [], []
+        chunks_replaced = chunks_unchanged = 0
+        added_estimate = 0
+        incoming_paths = set()
+
+        for doc in incoming:
+            path = doc["path"]
+            incoming_paths.add(path)
+            current = indexed.get(path)
+            if current is None:
+                added.append(path)
+                # Rough: one chunk per chunk_size bytes
+                if doc.get("size"):
+                    added_estimate += max(1, -(-int(doc["size"]) // chunk_size))
+            elif current["hash"] == doc["hash"] and current["hash_version"] == HASH_VERSION:
+                unchanged.append(path)
+                chunks_unchanged += current["chunks"]
+            else:
+                changed.append(path)
+                chunks_replaced += current["chunks"]
+
+        removed = sorted(p for p in indexed if p not in incoming_paths)
+        chunks_removed = sum(indexed[p]["chunks"] for p in removed)
+
+        return {
+            "store": org_id,
+            "hash_version": HASH_VERSION,
+            "noop": not (added or changed or removed),
+            "files": {
+                "added": len(added),
+                "removed": len(removed),
+                "changed": len(changed),
+                "unchanged": len(unchanged),
+            },
+            "chunks": {
+                "removed": chunks_removed,
+                "replaced": chunks_replaced,
+                "unchanged": chunks_unchanged,
+                # Known only from sizes sent with new files
+                "added_estimate": added_estimate,
+            },
+            "paths": {
+                "added": sorted(added)[:max_paths],
+                "removed": removed[:max_paths],
+                "changed": sorted(changed)[:max_paths],
+            },
+        }
+
+    def stored_hashes(
+        self,
+        org_id: str,
+        paths: List[str],
+        batch_size: int = 256
+    ) -> Dict[str, Tuple[Optional[str], Optional[int]]]:
+        """
+        (file_hash, hash_version) of the listed paths that are indexed.
+
+        Reads the store's collection, like the indexer's skip-unchanged
+        check, so a path reported unchanged is one an upload would skip.
+        """
+        from src.services.ingestion.migration import store_collection
+
+        stored: Dict[str, Tuple[Optional[str], Optional[int]]] = {}
+        collection_name = store_collection(org_id)
+        for start in range(0, len(paths), batch_size):
+            batch_filter = Filter(must=[
+                FieldCondition(key="org_id", match=MatchValue(value=org_id)),
+                FieldCondition(key="full_path", match=MatchAny(any=paths[start:start + batch_size])),
+            ])
+            offset = None
+            while True:
+                points, offset = self.qdrant.scroll(
+                    collection_name=collection_name,
+                    scroll_filter=batch_filter,
+                    limit=1024,
+                    offset=offset,
+                    with_payload=PAYLOAD_FIELDS,
+                    with_vectors=False
+                )
+                for point in points:
+                    payload = point.payload or {}
+                    if payload.get("full_path") and payload.get("file_hash"):
+                        stored[payload["full_path"]] = (payload["file_hash"], payload.get("hash_version"))
+                if offset is None or not points:
+                    break
+        return stored
+
+    def check_hashes(self, org_id: str, incoming: List[Dict[str, str]]) -> Dict[str, Any]:
+        """
+        Which of the listed files need uploading.
+
+        Args:
+            org_id: Store
+            incoming: [{"path", "hash"}] as the client would upload them
+
+        Returns:
+            ``upload`` (paths that are new, changed or indexed under an older
+            ``hash_version``), the count of ``unchanged`` files and the paths
+            the store's exclusion policy ``excluded``. Excluded files that
+            are still indexed stay in ``upload`` so the indexer removes them.
+        """
+        from src.services.ingestion.exclusion import get_exclusion_policy
+
+        policy = get_exclusion_policy(org_id)
+        paths = [doc["path"] for doc in incoming]
+        excluded = {path for path in paths if policy.check_path(path)}
+        skip_unchanged = settings.get("indexing.skip_unchanged", True)
+        lookup = paths if skip_unchanged else sorted(excluded)
+        stored = self.stored_hashes(org_id, lookup) if lookup else {}
+
+        upload, unchanged = [], 0
+        for doc in incoming:
+            path = doc["path"]
+            if path in excluded:
+                if path in stored:
+                    upload.append(path)
+            elif skip_unchanged and stored.get(path) == (doc["hash"], HASH_VERSION):
+                unchanged += 1
+            else:
+                upload.append(path)
+        return {
+            "store": org_id,
+            "hash_version": HASH_VERSION,
+            "upload": upload,
+            "unchanged": unchanged,
+            "excluded": sorted(excluded),
+        }
+
+    def apply(self, org_id: str, plan: Dict[str, Any], indexer=None) -> Dict[str, Any]:
+        """
+        Drop a plan's removed files from the store.
+
+        Build the plan with ``max_paths=None`` so no removed path is cut off.
+
+        Returns:
+            Number of files and chunks removed
+
+        Raises:
+            ValueError: The plan came from an empty file list
+        """
+        if not any(plan["files"][k] for k in ("added", "changed", "unchanged")) and plan["files"]["removed"]:
+            raise ValueError(f"Refusing to remove all {plan['files']['removed']} files of {org_id}: the file list is empty")
+        if indexer is None:
+            from src.services.ingestion.indexer import Indexer
+            indexer = Indexer(self.qdrant)
+
+        files = chunks = 0
+        for path in plan["paths"]["removed"]:
+            chunks += indexer.delete_file(path, org_id)
+            files += 1
+        logger.info(f"Reindex of {org_id} removed {files} files ({chunks} chunks)")
+        return {"files_removed": files, "chunks_removed": chunks}
+
+
+# Singleton instance
+_planner: Optional[ReindexPlanner] = None
+
+def get_reindex_planner() -> ReindexPlanner:
+    """Get global reindex planner instance."""
+    global _planner
+    if _planner is None:
+        _planner = ReindexPlanner()
+    return _planner
@@ -0,0 +1,89 @@
+"""
+Demo Store Seeding.
+
+Loads a bundled dataset (see ``src.datasets``) into a store through the
+normal indexing pipeline, so search, the Web UI and ``ricesearch eval`` can
+be tried right after install.
+"""
+
+import logging
+from datetime import datetime
+from typing import Dict, Optional
+
+from src.datasets import DATASETS, dataset_path, dataset_files
+
+logger = logging.getLogger(__name__)
+
+DEFAULT_STORE = "demo"
+
+
+def seed_dataset(
+    dataset: str,
+    store_id: str = DEFAULT_STORE,
+    indexer=None,
+    admin_store=None,
+) -> Dict:
+    """
+    Index a bundled dataset into a store, creating the store if needed.
+
+    Files are indexed under ``<dataset>/<relative path>`` so the dataset's
+    ``queries.yaml`` labels match. Re-seeding replaces existing chunks.
+
+    Args:
+        dataset: Dataset name, e.g. "go-stdlib-sample"
+        store_id: Store (org_id) to index into
+        indexer: Indexer to use (default: one on the shared Qdrant client)
+        admin_store: AdminStore to register the store in
+
+    Returns:
+        Dict with file/chunk counts and any failures
+    """
+    root = dataset_path(dataset)
+
+    if admin_store is None:
+        from src.services.admin.admin_store import get_admin_store
+        admin_store = get_admin_store()
+    if indexer is None:
+        from src.db.qdrant import get_qdrant_client
+        from src.services.ingestion.indexer import Indexer
+        indexer = Indexer(qdrant_client=get_qdrant_client())
+
+    created = False
+    if store_id not in admin_store.get_stores():
+        admin_store.set_store(store_id, {
+            "id": store_id,
+            "name": f"Demo ({dataset})",
+            "type": "demo",
+            "description": DATASETS[dataset]["description"],
+            "created_at": datetime.now().isoformat(),
+        })
+        created = True
+
+    indexed, chunks, skipped, failed = 0, 0, 0, []
+    for path in dataset_files(dataset):
+        display_path = f"{dataset}/{path.relative_to(root).as_posix()}"
+        try:
+            result = indexer.ingest_file(str(path), display_path, dataset, store_id)
+        except Exception as e:
+            result = {"status": "error", "message": str(e)}
+        if result.get("status") == "error":
+            logger.warning(f"Failed to seed {display_path}: {result.get('message')}")
+            failed.append({"path": display_path, "error": result.get("message")})
+        elif result.get("status") in ("skipped", "unchanged"):
+            skipped += 1
+        else:
+            indexed += 1
+            chunks += result.get("chunks_indexed", 0)
+
+    admin_store.log_audit("store_seeded", f"Seeded {store_id} with {dataset} ({indexed} files)")
+    queries = root / "queries.yaml"
+    return {
+        "dataset": dataset,
+        "store": store_id,
+        "store_created": created,
+        "files_indexed": indexed,
+        "chunks_indexed": chunks,
+        "files_skipped": skipped,
+        "failed": failed,
+        "queries": str(queries) if queries.exists() else None,
+    }
@@ -0,0 +1,156 @@
+"""
+LLM Chunk Summaries.
+
+An optional enrichment stage between chunking and embedding: an LLM writes
+a one-sentence description of each chunk ("Retries failed uploads with
+exponential backoff"). The summary is
+
+- stored in the chunk payload (``summary``) and shown under search results
+- prepended to the chunk's ``doc`` vector text, so prose questions match
+  code that has no docstrings or comments
+
+It costs one LLM call per chunk, so it is off unless a store turns it on
+(``PUT /api/v1/stores/{id}/summaries``) or ``indexing.summaries.enabled``
+makes it the default. Files summarize in the pipeline's ``summaries`` stage
+(``indexing.pipeline.summaries.workers`` files at a time per worker
+process), each with at most ``max_concurrency`` calls in flight.
+
+Backends are the query understanding ones (``ollama``, ``openai``) tried in
+the order of ``indexing.summaries.backends``. A chunk whose call fails gets
+no summary, and a file stops asking once a call has failed, so an
+unreachable LLM costs one timeout per file; summaries never fail indexing.
+Privacy-mode stores are never summarized: a summary is derived content.
+"""
+
+import asyncio
+import logging
+import re
+from concurrent.futures import ThreadPoolExecutor
+from typing import Any, Dict, List, Optional
+
+from src.core.config import settings
+from src.services.query.llm_backends import QueryLLM, build_backend
+
+logger = logging.getLogger(__name__)
+
+SECTION = "indexing.summaries"
+
+PROMPT = """Summarize what this {kind} from {path} does in ONE plain-English sentence.
+Describe its purpose, not its syntax. Do not start with "This code".
+
+{content}
+
+Summary:"""
+
+# Preambles small models put before the sentence
+_PREAMBLE = re.compile(r"^(?:summary|here is (?:a|the) (?:one-sentence )?summary)\s*[:\-]\s*", re.IGNORECASE)
+_SENTENCE_END = re.compile(r"(?<=[.!?])\s")
+
+
+def store_summaries(store_id: Optional[str]) -> Dict[str, Any]:
+    """A store's summary settings (``enabled``, ``max_concurrency``) over the defaults."""
+    config = {
+        "enabled": bool(settings.get(f"{SECTION}.enabled", False)),
+        "max_concurrency": int(settings.get(f"{SECTION}.max_concurrency", 2)),
+    }
+    if not store_id:
+        return config
+    try:
+        from src.services.admin.admin_store import get_admin_store
+        store = get_admin_store().get_stores().get(store_id) or {}
+    except Exception as e:
+        logger.debug(f"Could not read summary settings for {store_id}: {e}")
+        return config
+    config.update({k: v for k, v in (store.get("summaries") or {}).items() if v is not None})
+    if store.get("privacy_mode"):
+        config["enabled"] = False
+    return config
+
+
+def clean_summary(text: Optional[str]) -> Optional[str]:
+    """First sentence of a completion, without quotes or preamble."""
+    if not text:
+        return None
+    text = " ".join(text.split())
+    text = _PREAMBLE.sub("", text).strip("\"'` ")
+    text = _SENTENCE_END.split(text, maxsplit=1)[0]
+    text = text[:int(settings.get(f"{SECTION}.max_chars", 300))].strip()
+    return text or None
+
+
+class ChunkSummarizer:
+    """Summarizes chunks through the configured LLM backend chain."""
+
+    def __init__(self, llm: QueryLLM = None):
+        self._llm = llm
+
+    @property
+    def llm(self) -> QueryLLM:
+        if self._llm is not None:
+            return self._llm
+        names = settings.get(f"{SECTION}.backends", ["ollama"]) or []
+        if isinstance(names, str):
+            names = [n.strip() for n in names.split(",") if n.strip()]
+        return QueryLLM([b for b in (build_backend(n, SECTION) for n in names) if b])
+
+    def prompt(self, chunk: Dict[str, Any]) -> str:
+        metadata = chunk.get("metadata") or {}
+        language = metadata.get("language")
+        symbols = ", ".join(metadata.get("symbols") or [])
+        kind = f"{language} code" if language else "text"
+        if symbols:
+            kind += f" ({symbols})"
+        content = (chunk.get("content") or "")[:int(settings.get(f"{SECTION}.max_chunk_chars", 4000))]
+        return PROMPT.format(kind=kind, path=metadata.get("file_path") or "a file", content=content)
+
+    async def summarize_async(self, chunks: List[Dict[str, Any]], max_concurrency: int = 2) -> List[Optional[str]]:
+        """
+        One summary per chunk ({"content", "metadata"}), None where the
+        chunk is too short or the call failed.
+        """
+        llm = self.llm
+        min_chars = int(settings.get(f"{SECTION}.min_chars", 80))
+        max_tokens = int(settings.get(f"{SECTION}.max_tokens", 60))
+        temperature = float(settings.get(f"{SECTION}.temperature", 0.0))
+        timeout = float(settings.get(f"{SECTION}.timeout_seconds", 30.0))
+        limit = asyncio.Semaphore(max(1, int(max_concurrency)))
+        failed = asyncio.Event()
+
+        async def summarize(chunk: Dict[str, Any]) -> Optional[str]:
+            if len((chunk.get("content") or "").strip()) < min_chars:
+                return None
+            async with limit:
+                if failed.is_set():
+                    return None
+                text = await llm.complete(self.prompt(chunk), max_tokens, temperature, timeout)
+            if text is None:
+                failed.set()
+            return clean_summary(text)
+
+        return list(await asyncio.gather(*(summarize(c) for c in chunks)))
+
+    def summarize(self, chunks: List[Dict[str, Any]], max_concurrency: int = 2) -> List[Optional[str]]:
+        """Synchronous ``summarize_async`` for the indexer (never raises)."""
+        try:
+            with ThreadPoolExecutor(max_workers=1) as pool:
+                return pool.submit(asyncio.run, self.summarize_async(chunks, max_concurrency)).result()
+        except Exception as e:
+            logger.warning(f"Chunk summaries failed: {e}")
+            return [None] * len(chunks)
+
+    def status(self) -> Dict[str, Any]:
+        return {
+            "enabled_by_default": bool(settings.get(f"{SECTION}.enabled", False)),
+            "backends": self.llm.status(),
+        }
+
+
+# Singleton instance
+_summarizer: Optional[ChunkSummarizer] = None
+
+def get_chunk_summarizer() -> ChunkSummarizer:
+    """Get global chunk summarizer instance."""
+    global _summarizer
+    if _summarizer is None:
+        _summarizer = ChunkSummarizer()
+    return _summarizer
@@ -12,6 +12,7 @@ from typing import List, Dict, Any, Optional
 from qdrant_client.models import Filter, FieldCondition, MatchValue
 
 from src.services.search.retriever import Retriever
+from src.db.content_store import payload_text
 from src.db.qdrant import get_qdrant_client
 from src.core.config import settings
 
@@ -103,7 +104,7 @@ async def handle_read_file(
         )
         
         content = "\n".join([
-            chunk.payload.get("text", "")
+            payload_text(chunk.payload)
             for chunk in chunks
         ])
         
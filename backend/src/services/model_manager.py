@@ -7,6 +7,7 @@ Enables dynamic GPU resource management (pause/resume).
 
 import logging
 import gc
+import os
 import time
 # import torch  # Lazy loaded
 from typing import Dict, Any, Optional, Callable, List
@@ -17,8 +18,8 @@ from src.services.admin.admin_store import get_admin_store
 
 logger = logging.getLogger(__name__)
 
-import threading
 from src.core.config import settings
+from src.services.events.bus import emit
 
 class ModelManager:
     """
@@ -36,9 +37,10 @@ class ModelManager:
         
         # TTL Monitor
         if settings.MODEL_AUTO_UNLOAD:
-            self._running = True
-            self._monitor_thread = threading.Thread(target=self._ttl_monitor_loop, daemon=True)
-            self._monitor_thread.start()
+            from src.core.supervisor import ORDER_MODELS, get_supervisor
+            self._monitor_task = get_supervisor().spawn_loop(
+                "model-ttl-monitor", self.check_ttl, interval=60, group="models", order=ORDER_MODELS
+            )
             logger.info(f"TTL Monitor started (TTL={settings.MODEL_TTL_SECONDS}s) [{self.manager_id}]")
     
     @classmethod
@@ -47,26 +49,44 @@ class ModelManager:
             cls._instance = cls()
         return cls._instance
 
-    def register_model(self, model_id: str, model_type: str, instance: Any = None):
+    def register_model(
+        self,
+        model_id: str,
+        model_type: str,
+        instance: Any = None,
+        unloader: Optional[Callable[[], None]] = None
+    ):
         """
         Register a model to be managed. 
         If instance is provided, it's considered loaded.
+        unloader is called after an unload so the owner drops its references
+        (e.g. a module-level singleton) and reloads lazily on next use.
         """
         if model_id not in self._models:
             self._models[model_id] = {
                 "type": model_type,
                 "instance": instance,
                 "loaded": instance is not None,
-                "last_accessed": time.time() if instance is not None else 0
+                "last_accessed": time.time() if instance is not None else 0,
+                "unloader": unloader
             }
             logger.info(f"Registered model: {model_id} ({model_type})")
         else:
             # Update instance if re-registering
+            if unloader is not None:
+                self._models[model_id]["unloader"] = unloader
             if instance is not None:
                 self._models[model_id]["instance"] = instance
                 self._models[model_id]["loaded"] = True
+                self._models[model_id]["last_accessed"] = time.time()
+
+    def touch(self, model_id: str):
+        """Mark a model as used now (resets its idle timer)."""
+        model = self._models.get(model_id)
+        if model is not None:
+            model["last_accessed"] = time.time()
 
-    def unload_model(self, model_id: str) -> bool:
+    def unload_model(self, model_id: str, reason: str = "manual") -> bool:
         """
         Unload a model from memory (move to CPU and delete).
         Returns True if unloaded, False if not found or already unloaded.
@@ -94,9 +114,12 @@ class ModelManager:
                 # SentenceTransformer / CrossEncoder wrapper
                 instance.model.cpu()
                 
-            # 3. Release reference
+            # 3. Release reference (ours and the owner's)
             self._models[model_id]["instance"] = None
             self._models[model_id]["loaded"] = False
+            unloader = model_entry.get("unloader")
+            if unloader is not None:
+                unloader()
             
             # 4. Force GC
             del instance
@@ -118,6 +141,8 @@ class ModelManager:
                 logger.warning(f"Failed to sync model status to Redis: {e}")
                 
             logger.info(f"Model {model_id} unloaded successfully")
+            idle = time.time() - model_entry.get("last_accessed", 0)
+            emit("model.unloaded", model_id=model_id, type=model_entry["type"], reason=reason, idle_seconds=round(idle))
             return True
         except Exception as e:
             logger.error(f"Failed to unload model {model_id}: {e}")
@@ -251,12 +276,15 @@ class ModelManager:
         return statuses
 
     
-    def check_ttl(self) -> int:
+    def check_ttl(self, ttl_seconds: Optional[int] = None) -> int:
         """
         Unload models that haven't been accessed in 'ttl_seconds'.
-        Reads dynamic config from AdminStore.
+        Reads dynamic config from AdminStore unless ttl_seconds is given.
         Returns number of unloaded models.
         """
+        if ttl_seconds is not None:
+            return self._unload_idle(ttl_seconds)
+
         # Read dynamic config
         try:
             from src.services.admin.admin_store import get_admin_store
@@ -271,7 +299,9 @@ class ModelManager:
 
         if not auto_unload or ttl_seconds <= 0:
             return 0
-            
+        return self._unload_idle(ttl_seconds)
+
+    def _unload_idle(self, ttl_seconds: int) -> int:
         now = time.time()
         unloaded_count = 0
         
@@ -283,20 +313,11 @@ class ModelManager:
                 last = model.get("last_accessed", 0)
                 if now - last > ttl_seconds:
                     logger.info(f"TTL Expired for {mid} (Idle {int(now-last)}s > {ttl_seconds}s). Unloading.")
-                    if self.unload_model(mid):
+                    if self.unload_model(mid, reason="idle"):
                         unloaded_count += 1
                         
         return unloaded_count
 
-    def _ttl_monitor_loop(self):
-        """Background loop to check for expired models."""
-        while getattr(self, "_running", True):
-            try:
-                time.sleep(60) # Check every 60 seconds
-                self.check_ttl() # Config is read inside
-            except Exception as e:
-                logger.error(f"Error in TTL monitor: {e}")
-
     
     def resolve_model_name(self, model_key: str) -> str:
         """
@@ -323,28 +344,30 @@ class ModelManager:
         
         def loader():
             logger.info(f"Downloading/Loading model: {model_id} (trust_remote_code={trust_remote_code})")
+            source = self._model_source(model_id)
             
             import torch
             device = "cuda" if torch.cuda.is_available() else "cpu"
             
             if model_type == "embedding":
                  from sentence_transformers import SentenceTransformer
-                 model = SentenceTransformer(model_id, trust_remote_code=trust_remote_code, device=device)
+                 model = SentenceTransformer(source, trust_remote_code=trust_remote_code, device=device)
                  return model # ST is self-contained
                  
-            from transformers import AutoTokenizer, AutoModel, AutoModelForMaskedLM, AutoModelForSequenceClassification
+            from transformers import AutoModel, AutoModelForMaskedLM, AutoModelForSequenceClassification
+            from src.services.inference.tokenizer_loader import load_tokenizer
             
-            tokenizer = AutoTokenizer.from_pretrained(model_id, trust_remote_code=trust_remote_code)
+            tokenizer = load_tokenizer(source, trust_remote_code=trust_remote_code)
             
             # Load on CPU first to avoid "meta tensor" errors
             # Explicitly avoiding device="cuda" in from_pretrained
             
             if model_type == "sparse_embedding":
-                model = AutoModelForMaskedLM.from_pretrained(model_id, trust_remote_code=trust_remote_code)
+                model = AutoModelForMaskedLM.from_pretrained(source, trust_remote_code=trust_remote_code)
             elif model_type == "classification":
-                model = AutoModelForSequenceClassification.from_pretrained(model_id, trust_remote_code=trust_remote_code)
+                model = AutoModelForSequenceClassification.from_pretrained(source, trust_remote_code=trust_remote_code)
             else:
-                model = AutoModel.from_pretrained(model_id, trust_remote_code=trust_remote_code)
+                model = AutoModel.from_pretrained(source, trust_remote_code=trust_remote_code)
                 
             model.to(device)
             model.eval()
@@ -353,6 +376,23 @@ class ModelManager:
 
         return self.load_model(model_id, loader)
 
+    def _model_source(self, model_id: str) -> str:
+        """
+        Where to load a hub model from: a verified local download
+        (models.download), or the hub id itself when downloads are off or
+        the hub can't be reached. A checksum failure is not papered over.
+        """
+        if not settings.get("models.download.enabled", True) or os.path.isdir(model_id):
+            return model_id
+        from src.services.inference.model_downloader import ChecksumError, get_model_downloader
+        try:
+            return get_model_downloader().download(model_id)
+        except ChecksumError:
+            raise
+        except Exception as e:
+            logger.warning(f"Verified download of {model_id} failed ({e}); loading through the HuggingFace cache")
+            return model_id
+
     def swap_model(self, model_key: str, new_model_id: str):
         """
         Swap the runtime model for a given key (e.g. swap the 'dense' model).
@@ -427,6 +467,75 @@ class ModelManager:
             
         return usage
 
+    def get_health(self) -> Dict[str, Any]:
+        """Loaded/idle state of this process's models for /health."""
+        now = time.time()
+        models = {}
+        for mid, data in self._models.items():
+            entry = {"type": data["type"], "loaded": data["loaded"]}
+            if data["loaded"]:
+                entry["idle_seconds"] = round(now - data.get("last_accessed", now))
+            if data.get("load_seconds") is not None:
+                entry["load_seconds"] = data["load_seconds"]
+            if data.get("warmup_ms") is not None:
+                entry["warmup_ms"] = data["warmup_ms"]
+            models[mid] = entry
+        return {
+            "status": "up",
+            "loaded": sorted(mid for mid, m in self._models.items() if m["loaded"]),
+            "auto_unload": bool(settings.MODEL_AUTO_UNLOAD),
+            "idle_unload_seconds": settings.MODEL_TTL_SECONDS,
+            "models": models,
+        }
+
 # Module level helper
 def get_model_manager() -> ModelManager:
     return ModelManager.get_instance()
+
+
+def touch_model(model_id: str):
+    """Reset a model's idle timer (no-op before any model is tracked)."""
+    manager = ModelManager._instance
+    if manager is not None:
+        manager.touch(model_id)
+
+
+def warm_up_model(model_id: str, run: Callable[[str], Any]) -> Optional[float]:
+    """
+    Send a warm-up request through a freshly loaded model so the first real
+    request doesn't pay for kernel selection and allocation.
+
+    Returns:
+        Warm-up time in ms (None when disabled or it failed)
+    """
+    if not settings.get("model_management.warmup.enabled", True):
+        return None
+    text = settings.get("model_management.warmup.text", "def hello_world(): return 'warm up'")
+    started = time.perf_counter()
+    try:
+        run(text)
+    except Exception as e:
+        logger.warning(f"Warm-up of {model_id} failed: {e}")
+        return None
+    return round((time.perf_counter() - started) * 1000, 1)
+
+
+def track_loaded_model(
+    model_id: str,
+    model_type: str,
+    instance: Any,
+    unloader: Callable[[], None],
+    load_seconds: float,
+    warmup_ms: Optional[float] = None
+):
+    """
+    Hand a lazily loaded model to the manager: it is unloaded after
+    model_management.ttl_seconds idle (if auto_unload), shows up in /health,
+    and a model.loaded event is published.
+    """
+    manager = get_model_manager()
+    manager.register_model(model_id, model_type, instance=instance, unloader=unloader)
+    manager._models[model_id]["load_seconds"] = round(load_seconds, 2)
+    manager._models[model_id]["warmup_ms"] = warmup_ms
+    manager._sync_model_status_to_redis(model_id)
+    emit("model.loaded", model_id=model_id, type=model_type, load_seconds=round(load_seconds, 2), warmup_ms=warmup_ms)
@@ -0,0 +1,179 @@
+"""
+Query Understanding LLM Backends.
+
+Intent classification and query expansion can run against any of:
+
+- ``ollama``: Ollama chat API (the default inference service)
+- ``openai``: Any OpenAI-compatible ``/chat/completions`` server, e.g. a local
+  llama.cpp server or a hosted API
+
+Backends are tried in the order given by ``search.query_analysis.backends``.
+Each call has its own timeout; if every backend fails or times out the caller
+falls back to the pattern-based heuristics.
+"""
+
+import asyncio
+import logging
+from typing import Dict, List, Optional
+
+import httpx
+
+from src.core.config import settings
+
+logger = logging.getLogger(__name__)
+
+
+class QueryLLMBackend:
+    """Base class for a chat completion backend."""
+
+    name = "base"
+
+    def __init__(self, url: str, model: str):
+        self.url = url.rstrip("/")
+        self.model = model
+
+    async def complete(self, prompt: str, max_tokens: int, temperature: float, timeout: float) -> str:
+        """Return the completion text for a single user prompt."""
+        raise NotImplementedError
+
+
+class OllamaBackend(QueryLLMBackend):
+    """Ollama ``/api/chat`` backend."""
+
+    name = "ollama"
+
+    async def complete(self, prompt: str, max_tokens: int, temperature: float, timeout: float) -> str:
+        async with httpx.AsyncClient(timeout=timeout) as client:
+            response = await client.post(
+                f"{self.url}/api/chat",
+                json={
+                    "model": self.model,
+                    "messages": [{"role": "user", "content": prompt}],
+                    "stream": False,
+                    "options": {
+                        "num_predict": max_tokens,
+                        "temperature": temperature,
+                    }
+                },
+            )
+            response.raise_for_status()
+            return response.json().get("message", {}).get("content", "")
+
+
+class OpenAICompatBackend(QueryLLMBackend):
+    """OpenAI-compatible ``/chat/completions`` backend (llama.cpp, vLLM, hosted APIs)."""
+
+    name = "openai"
+
+    def __init__(self, url: str, model: str, api_key: str = ""):
+        super().__init__(url, model)
+        self.api_key = api_key
+
+    async def complete(self, prompt: str, max_tokens: int, temperature: float, timeout: float) -> str:
+        headers = {"Authorization": f"Bearer {self.api_key}"} if self.api_key else {}
+        async with httpx.AsyncClient(timeout=timeout) as client:
+            response = await client.post(
+                f"{self.url}/chat/completions",
+                headers=headers,
+                json={
+                    "model": self.model,
+                    "messages": [{"role": "user", "content": prompt}],
+                    "max_tokens": max_tokens,
+                    "temperature": temperature,
+                },
+            )
+            response.raise_for_status()
+            choices = response.json().get("choices") or [{}]
+            return choices[0].get("message", {}).get("content", "")
+
+
+def build_backend(name: str, section: str = "search.query_analysis") -> Optional[QueryLLMBackend]:
+    """Create a backend from its ``<section>.<name>`` settings."""
+    prefix = f"{section}.{name}"
+    if name == "ollama":
+        return OllamaBackend(
+            url=settings.get(f"{prefix}.url") or settings.OLLAMA_BASE_URL,
+            model=settings.get(f"{prefix}.model") or settings.LLM_MODEL,
+        )
+    if name == "openai":
+        url = settings.get(f"{prefix}.url")
+        if not url:
+            logger.warning(f"openai backend in {section} configured without a url, skipping")
+            return None
+        return OpenAICompatBackend(
+            url=url,
+            model=settings.get(f"{prefix}.model") or settings.LLM_MODEL,
+            api_key=settings.get(f"{prefix}.api_key", ""),
+        )
+    logger.warning(f"Unknown query analysis backend: {name}")
+    return None
+
+
+class QueryLLM:
+    """Runs prompts through the configured backend fallback chain."""
+
+    def __init__(self, backends: List[QueryLLMBackend] = None):
+        self._backends = backends
+
+    @property
+    def backends(self) -> List[QueryLLMBackend]:
+        if self._backends is not None:
+            return self._backends
+        names = settings.get("search.query_analysis.backends", ["ollama"]) or []
+        if isinstance(names, str):
+            names = [n.strip() for n in names.split(",") if n.strip()]
+        return [b for b in (build_backend(n) for n in names) if b]
+
+    @property
+    def timeout(self) -> float:
+        return float(settings.get("search.query_analysis.timeout_seconds", 5.0))
+
+    async def complete(
+        self,
+        prompt: str,
+        max_tokens: int = None,
+        temperature: float = None,
+        timeout: float = None,
+    ) -> Optional[str]:
+        """
+        Try each backend in order until one answers.
+
+        Args:
+            prompt: User prompt
+            max_tokens: Max completion tokens
+            temperature: Sampling temperature
+            timeout: Per-backend timeout in seconds (default from settings)
+
+        Returns:
+            Completion text, or None if every backend failed
+        """
+        max_tokens = max_tokens if max_tokens is not None else settings.QUERY_ANALYSIS_LLM_MAX_TOKENS
+        temperature = temperature if temperature is not None else settings.QUERY_ANALYSIS_LLM_TEMPERATURE
+        timeout = timeout if timeout is not None else self.timeout
+
+        for backend in self.backends:
+            try:
+                return await asyncio.wait_for(
+                    backend.complete(prompt, max_tokens, temperature, timeout),
+                    timeout=timeout
+                )
+            except asyncio.TimeoutError:
+                logger.warning(f"Query backend {backend.name} timed out after {timeout:.1f}s")
+            except Exception as e:
+                logger.warning(f"Query backend {backend.name} failed: {e}")
+        return None
+
+    def status(self) -> List[Dict[str, str]]:
+        """Configured backends in fallback order."""
+        return [{"name": b.name, "url": b.url, "model": b.model} for b in self.backends]
+
+
+# Singleton instance
+_query_llm: Optional[QueryLLM] = None
+
+def get_query_llm() -> QueryLLM:
+    """Get global query LLM instance."""
+    global _query_llm
+    if _query_llm is None:
+        _query_llm = QueryLLM()
+    return _query_llm
@@ -0,0 +1,215 @@
+"""
+Code Analyzer for Sparse Indexing.
+
+Sparse encoders see ``getUserById`` or ``max_conn_retries`` as opaque
+tokens, so a query for "user by id" or "connection retries" misses them.
+When ``indexing.analyzer.enabled`` is on, text passes through this stage
+before sparse encoding (SPLADE, BM42, BM25 sparse), at index and query time:
+
+- identifiers are followed by their camelCase/snake_case parts
+  (``getUserById`` -> ``getUserById get user by id``)
+- common abbreviations are followed by their expansion (``cfg`` -> ``config``)
+- comments are optionally stripped (index time only, off by default)
+
+Every option can be overridden per language under
+``indexing.analyzer.languages.<language>``. Dense embeddings and stored
+chunk text are unaffected. Reindex stores after changing these settings.
+"""
+
+import logging
+import re
+from typing import Any, Dict, List, Optional, Tuple
+
+from src.core.config import settings
+
+logger = logging.getLogger(__name__)
+
+DEFAULT_ABBREVIATIONS = {
+    "addr": "address",
+    "arg": "argument",
+    "args": "arguments",
+    "auth": "authentication",
+    "btn": "button",
+    "buf": "buffer",
+    "cfg": "config",
+    "cmd": "command",
+    "conf": "config",
+    "conn": "connection",
+    "ctx": "context",
+    "db": "database",
+    "del": "delete",
+    "dir": "directory",
+    "doc": "document",
+    "dst": "destination",
+    "env": "environment",
+    "err": "error",
+    "fn": "function",
+    "func": "function",
+    "idx": "index",
+    "impl": "implementation",
+    "init": "initialize",
+    "len": "length",
+    "msg": "message",
+    "num": "number",
+    "obj": "object",
+    "param": "parameter",
+    "params": "parameters",
+    "pkg": "package",
+    "ptr": "pointer",
+    "repo": "repository",
+    "req": "request",
+    "res": "response",
+    "resp": "response",
+    "src": "source",
+    "str": "string",
+    "tmp": "temporary",
+    "util": "utility",
+    "val": "value",
+    "var": "variable",
+}
+
+# (line comment markers, block comment delimiters)
+_C_STYLE = (["//"], [("/*", "*/")])
+_HASH = (["#"], [])
+COMMENT_SYNTAX: Dict[str, Tuple[List[str], List[Tuple[str, str]]]] = {
+    "python": (["#"], []),
+    "ruby": (["#"], [("=begin", "=end")]),
+    "shell": _HASH,
+    "bash": _HASH,
+    "perl": _HASH,
+    "r": _HASH,
+    "yaml": _HASH,
+    "toml": _HASH,
+    "dockerfile": _HASH,
+    "makefile": _HASH,
+    "elixir": _HASH,
+    "javascript": _C_STYLE,
+    "typescript": _C_STYLE,
+    "tsx": _C_STYLE,
+    "go": _C_STYLE,
+    "rust": _C_STYLE,
+    "java": _C_STYLE,
+    "c": _C_STYLE,
+    "cpp": _C_STYLE,
+    "csharp": _C_STYLE,
+    "kotlin": _C_STYLE,
+    "scala": _C_STYLE,
+    "swift": _C_STYLE,
+    "dart": _C_STYLE,
+    "php": (["//", "#"], [("/*", "*/")]),
+    "sql": (["--"], [("/*", "*/")]),
+    "lua": (["--"], [("--[[", "]]")]),
+    "haskell": (["--"], [("{-", "-}")]),
+}
+
+_STRING = r'"(?:\\.|[^"\\\n])*"|\'(?:\\.|[^\'\\\n])*\''
+_IDENTIFIER = re.compile(r"[A-Za-z_][A-Za-z0-9_]*")
+_CAMEL = re.compile(r"[A-Z]+(?=[A-Z][a-z])|[A-Z]?[a-z]+|[A-Z]+|[0-9]+")
+_comment_patterns: Dict[str, Optional[re.Pattern]] = {}
+
+
+def _comment_pattern(language: str) -> Optional[re.Pattern]:
+    """Regex matching string literals (kept) or comments (dropped)."""
+    if language not in _comment_patterns:
+        syntax = COMMENT_SYNTAX.get(language)
+        if not syntax:
+            _comment_patterns[language] = None
+        else:
+            line_markers, blocks = syntax
+            comments = [f"{re.escape(start)}.*?{re.escape(end)}" for start, end in blocks]
+            comments += [f"{re.escape(marker)}[^\\n]*" for marker in line_markers]
+            _comment_patterns[language] = re.compile(
+                f"({_STRING})|(?:{'|'.join(comments)})", re.DOTALL
+            )
+    return _comment_patterns[language]
+
+
+def strip_comments(text: str, language: str) -> str:
+    """Remove comments for a language (text unchanged if unknown)."""
+    pattern = _comment_pattern((language or "").lower())
+    if pattern is None:
+        return text
+    return pattern.sub(lambda m: m.group(1) or "", text)
+
+
+def extract_comments(text: str, language: str) -> List[str]:
+    """Comments of a language's code, markers included (empty if unknown)."""
+    pattern = _comment_pattern((language or "").lower())
+    if pattern is None:
+        return []
+    return [m.group(0) for m in pattern.finditer(text) if m.group(1) is None]
+
+
+def split_identifier(identifier: str) -> List[str]:
+    """``getHTTPResponse_code`` -> get, http, response, code."""
+    return [p.lower() for part in identifier.split("_") for p in _CAMEL.findall(part)]
+
+
+def analyzer_config(language: Optional[str] = None) -> Dict[str, Any]:
+    """Analyzer options for a language (global settings + overrides)."""
+    config = {
+        "enabled": bool(settings.get("indexing.analyzer.enabled", False)),
+        "split_identifiers": bool(settings.get("indexing.analyzer.split_identifiers", True)),
+        "strip_comments": bool(settings.get("indexing.analyzer.strip_comments", False)),
+        "expand_abbreviations": bool(settings.get("indexing.analyzer.expand_abbreviations", True)),
+        "abbreviations": {
+            **DEFAULT_ABBREVIATIONS,
+            **(settings.get("indexing.analyzer.abbreviations", {}) or {}),
+        },
+    }
+    overrides = (settings.get("indexing.analyzer.languages", {}) or {}).get((language or "").lower()) or {}
+    for key, value in overrides.items():
+        if key == "abbreviations":
+            config["abbreviations"] = {**config["abbreviations"], **(value or {})}
+        else:
+            config[key] = value
+    return config
+
+
+def analyze(
+    text: str,
+    language: Optional[str] = None,
+    query: bool = False,
+    split_identifiers: Optional[bool] = None
+) -> str:
+    """
+    Text for sparse encoding.
+
+    Args:
+        text: Chunk content or query
+        language: Chunk language (per-language options)
+        query: Query-time analysis (never strips comments)
+        split_identifiers: Override the configured option (the BM25 sparse
+            tokenizer already splits identifiers)
+
+    Returns:
+        Analyzed text, or ``text`` unchanged when the analyzer is disabled
+    """
+    config = analyzer_config(language)
+    if not config["enabled"]:
+        return text
+
+    if config["strip_comments"] and not query:
+        text = strip_comments(text, language)
+
+    split = config["split_identifiers"] if split_identifiers is None else split_identifiers
+    expand = config["expand_abbreviations"]
+    if not split and not expand:
+        return text
+    abbreviations = config["abbreviations"]
+
+    def annotate(match: re.Match) -> str:
+        word = match.group(0)
+        parts = split_identifier(word)
+        extra = []
+        if split and len(parts) > 1:
+            extra.extend(parts)
+        if expand:
+            extra.extend(abbreviations[p] for p in (parts or [word.lower()]) if p in abbreviations)
+        return f"{word} {' '.join(extra)}" if extra else word
+
+    return _IDENTIFIER.sub(annotate, text)
+
+
+def analyze_all(texts: List[str], language: Optional[str] = None, **kwargs: Any) -> List[str]:
+    return [analyze(t, language, **kwargs) for t in texts]
@@ -0,0 +1,230 @@
+"""
+BM25 Sparse Backend.
+
+A model-free alternative to the SPLADE encoder for small or CPU-only
+deployments. Stores select it with ``sparse_backend: bm25``; the index
+pipeline then skips SPLADE and maintains a per-store inverted index in Redis
+instead, and searches read it in place of the SPLADE retriever. Results
+enter fusion as the ``bm25_sparse`` retriever (sparse group).
+
+Redis layout (prefix ``rice:bm25``):
+- ``{org}:postings:{term}``: hash chunk_id -> term frequency
+- ``{org}:doclen``: hash chunk_id -> chunk length in tokens
+- ``{org}:stats``: hash with total_len (for average chunk length)
+- ``doc:{chunk_id}``: hash with org and the chunk's term frequencies, used
+  to remove the chunk's postings on delete
+"""
+
+import json
+import math
+import re
+import logging
+from collections import Counter
+from typing import Dict, Iterable, List, Optional, Tuple
+
+import redis
+
+from src.core.config import settings
+
+logger = logging.getLogger(__name__)
+
+SPLADE = "splade"
+BM25 = "bm25"
+BACKENDS = (SPLADE, BM25)
+
+_WORD = re.compile(r"[A-Za-z0-9_]+")
+_CAMEL = re.compile(r"[A-Z]+(?=[A-Z][a-z])|[A-Z]?[a-z]+|[A-Z]+|[0-9]+")
+
+
+def tokenize(text: str, min_length: int = 2) -> List[str]:
+    """
+    Code-aware tokens: identifiers plus their camelCase/snake_case parts.
+
+    ``HandleFunc`` -> handlefunc, handle, func
+    """
+    tokens = []
+    for word in _WORD.findall(text):
+        lower = word.lower()
+        if len(lower) >= min_length:
+            tokens.append(lower)
+        parts = [p.lower() for chunk in word.split("_") for p in _CAMEL.findall(chunk)]
+        if len(parts) > 1:
+            tokens.extend(p for p in parts if len(p) >= min_length and p != lower)
+    return tokens
+
+
+def bm25_idf(n_docs: int, df: int) -> float:
+    return math.log(1.0 + (n_docs - df + 0.5) / (df + 0.5))
+
+
+class BM25Index:
+    """Per-store BM25 inverted index in Redis."""
+
+    PREFIX = "rice:bm25"
+
+    def __init__(self, redis_client=None):
+        self._redis = redis_client
+
+    @property
+    def redis(self) -> redis.Redis:
+        """Lazy Redis connection."""
+        if self._redis is None:
+            self._redis = redis.from_url(settings.REDIS_URL, decode_responses=True)
+        return self._redis
+
+    @property
+    def k1(self) -> float:
+        return float(settings.get("search.bm25_sparse.k1", 1.2))
+
+    @property
+    def b(self) -> float:
+        return float(settings.get("search.bm25_sparse.b", 0.75))
+
+    @property
+    def min_token_length(self) -> int:
+        return int(settings.get("search.bm25_sparse.min_token_length", 2))
+
+    def _key(self, org_id: str, *parts: str) -> str:
+        return ":".join([self.PREFIX, org_id, *parts])
+
+    def add(self, org_id: str, chunks: Iterable[Tuple[str, str]]) -> int:
+        """
+        Index chunks for a store.
+
+        Args:
+            org_id: Store
+            chunks: (chunk_id, text) pairs
+
+        Returns:
+            Number of chunks indexed
+        """
+        pipe = self.redis.pipeline()
+        count = 0
+        added_len = 0
+        for chunk_id, text in chunks:
+            tokens = tokenize(text, self.min_token_length)
+            if not tokens:
+                continue
+            freqs = Counter(tokens)
+            for term, tf in freqs.items():
+                pipe.hset(self._key(org_id, "postings", term), chunk_id, tf)
+            pipe.hset(self._key(org_id, "doclen"), chunk_id, len(tokens))
+            pipe.hset(f"{self.PREFIX}:doc:{chunk_id}", mapping={"org": org_id, "terms": json.dumps(freqs)})
+            added_len += len(tokens)
+            count += 1
+        pipe.hincrby(self._key(org_id, "stats"), "total_len", added_len)
+        pipe.execute()
+        return count
+
+    def remove(self, chunk_ids: List[str]) -> int:
+        """
+        Drop chunks from whichever store indexed them.
+
+        Chunks that were never BM25-indexed are ignored.
+
+        Returns:
+            Number of chunks removed
+        """
+        if not chunk_ids:
+            return 0
+        pipe = self.redis.pipeline()
+        for chunk_id in chunk_ids:
+            pipe.hgetall(f"{self.PREFIX}:doc:{chunk_id}")
+        docs = pipe.execute()
+
+        pipe = self.redis.pipeline()
+        removed = 0
+        for chunk_id, doc in zip(chunk_ids, docs):
+            if not doc:
+                continue
+            org_id = doc["org"]
+            freqs = json.loads(doc["terms"])
+            for term in freqs:
+                pipe.hdel(self._key(org_id, "postings", term), chunk_id)
+            pipe.hdel(self._key(org_id, "doclen"), chunk_id)
+            pipe.hincrby(self._key(org_id, "stats"), "total_len", -sum(freqs.values()))
+            pipe.delete(f"{self.PREFIX}:doc:{chunk_id}")
+            removed += 1
+        pipe.execute()
+        return removed
+
+    def search(self, org_id: str, query: str, limit: int = 10) -> List[Tuple[str, float]]:
+        """
+        Score a store's chunks against a query.
+
+        Returns:
+            (chunk_id, score) pairs, best first
+        """
+        terms = sorted(set(tokenize(query, self.min_token_length)))
+        if not terms:
+            return []
+
+        n_docs = self.redis.hlen(self._key(org_id, "doclen"))
+        if not n_docs:
+            return []
+        total_len = float(self.redis.hget(self._key(org_id, "stats"), "total_len") or 0)
+        avgdl = total_len / n_docs if total_len > 0 else 1.0
+
+        pipe = self.redis.pipeline()
+        for term in terms:
+            pipe.hgetall(self._key(org_id, "postings", term))
+        postings = [p for p in pipe.execute() if p]
+        if not postings:
+            return []
+
+        candidates = sorted({chunk_id for p in postings for chunk_id in p})
+        lengths = dict(zip(candidates, self.redis.hmget(self._key(org_id, "doclen"), candidates)))
+
+        k1, b = self.k1, self.b
+        scores: Dict[str, float] = {}
+        for posting in postings:
+            idf = bm25_idf(n_docs, len(posting))
+            for chunk_id, tf in posting.items():
+                tf = float(tf)
+                dl = float(lengths.get(chunk_id) or avgdl)
+                norm = tf + k1 * (1.0 - b + b * dl / avgdl)
+                scores[chunk_id] = scores.get(chunk_id, 0.0) + idf * tf * (k1 + 1.0) / norm
+
+        return sorted(scores.items(), key=lambda x: x[1], reverse=True)[:limit]
+
+    def stats(self, org_id: str) -> Dict[str, float]:
+        """Chunk count and average chunk length for a store."""
+        n_docs = self.redis.hlen(self._key(org_id, "doclen"))
+        total_len = float(self.redis.hget(self._key(org_id, "stats"), "total_len") or 0)
+        return {
+            "chunks": n_docs,
+            "avg_chunk_tokens": round(total_len / n_docs, 2) if n_docs else 0.0,
+        }
+
+    def clear(self, org_id: str):
+        """Drop a store's whole index (e.g. after switching back to SPLADE)."""
+        doc_ids = self.redis.hkeys(self._key(org_id, "doclen"))
+        keys = list(self.redis.scan_iter(match=self._key(org_id, "*")))
+        keys.extend(f"{self.PREFIX}:doc:{chunk_id}" for chunk_id in doc_ids)
+        if keys:
+            self.redis.delete(*keys)
+
+
+def get_store_sparse_backend(org_id: Optional[str]) -> str:
+    """Sparse backend for a store (store setting, then ``search.sparse_backend``)."""
+    default = settings.get("search.sparse_backend", SPLADE)
+    if not org_id:
+        return default
+    try:
+        from src.services.admin.admin_store import get_admin_store
+        backend = get_admin_store().get_stores().get(org_id, {}).get("sparse_backend")
+    except Exception as e:
+        logger.debug(f"Could not read sparse backend for {org_id}: {e}")
+        backend = None
+    return backend if backend in BACKENDS else default
+
+
+# Singleton instance
+_bm25_index: Optional[BM25Index] = None
+
+def get_bm25_index() -> BM25Index:
+    """Get global BM25 index instance."""
+    global _bm25_index
+    if _bm25_index is None:
+        _bm25_index = BM25Index()
+    return _bm25_index
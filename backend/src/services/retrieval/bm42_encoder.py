@@ -11,8 +11,10 @@ This encoder ONLY generates the sparse vectors. BM42 scoring is done internally
 """
 
 import logging
+import time
 from typing import List, NamedTuple, Optional
 from src.core.config import settings
+from src.services.model_manager import touch_model, track_loaded_model, warm_up_model
 
 logger = logging.getLogger(__name__)
 
@@ -66,6 +68,7 @@ class BM42Encoder:
         
         if self.model is None:
             raise RuntimeError("BM42 model not loaded")
+        touch_model(self.model_name)
         
         results = []
         
@@ -95,6 +98,20 @@ def get_bm42_encoder() -> BM42Encoder:
     global _bm42_encoder
     
     if _bm42_encoder is None:
-        _bm42_encoder = BM42Encoder()
+        started = time.perf_counter()
+        encoder = BM42Encoder()
+        load_seconds = time.perf_counter() - started
+        warmup_ms = warm_up_model(encoder.model_name, lambda text: encoder.encode([text]))
+        track_loaded_model(encoder.model_name, "bm42", encoder, _forget_encoder, load_seconds, warmup_ms)
+        _bm42_encoder = encoder
     
     return _bm42_encoder
+
+
+def _forget_encoder():
+    """Drop the unloaded encoder; the next get_bm42_encoder() reloads it."""
+    global _bm42_encoder
+    _bm42_encoder = None
+    from src.services.search.retriever import Retriever
+    if Retriever._multi_retriever is not None:
+        Retriever._multi_retriever._bm42_encoder = None
@@ -4,9 +4,14 @@ Result Fusion Algorithms.
 Combines results from multiple retrievers (BM25, SPLADE, BM42) using:
 - Reciprocal Rank Fusion (RRF)
 - Weighted score fusion
+
+Docs chunks whose section heading matches the query are boosted after
+fusion (``heading_boost``), and ``diversify`` trades relevance for results
+from more distinct files.
 """
 
 import logging
+import re
 from typing import List, Dict, Any, Optional
 from dataclasses import dataclass, field
 from collections import defaultdict
@@ -27,19 +32,21 @@ class FusedResult:
 def rrf_fusion(
     result_sets: Dict[str, List[Dict]],
     limit: int = 10,
-    k: int = 60
+    k: int = 60,
+    weights: Optional[Dict[str, float]] = None
 ) -> List[FusedResult]:
     """
     Reciprocal Rank Fusion (RRF).
     
     Combines ranked lists by summing reciprocal ranks:
-    RRF(d) = Σ 1/(k + rank(d))
+    RRF(d) = Σ w(r)/(k + rank(d))
     
     Args:
         result_sets: Dict mapping retriever name to list of results
                      Each result must have 'chunk_id' and 'score'
         limit: Maximum number of results to return
         k: RRF parameter (default 60)
+        weights: Optional per-retriever multipliers (default 1.0)
         
     Returns:
         List of FusedResult objects, sorted by fused score
@@ -53,13 +60,14 @@ def rrf_fusion(
     chunk_data: Dict[str, Dict] = {}
     
     for retriever_name, results in result_sets.items():
+        weight = weights.get(retriever_name, 1.0) if weights else 1.0
         for rank, result in enumerate(results):
             chunk_id = result.get("chunk_id") or result.get("id") or str(result.get("chunk_id", ""))
             if not chunk_id:
                 continue
             
             # RRF score contribution
-            rrf_score = 1.0 / (k + rank + 1)  # +1 because rank is 0-indexed
+            rrf_score = weight / (k + rank + 1)  # +1 because rank is 0-indexed
             chunk_scores[chunk_id] += rrf_score
             
             # Track source scores
@@ -193,6 +201,61 @@ def weighted_fusion(
     return results
 
 
+_TERM = re.compile(r"[^\W_]{2,}", re.UNICODE)
+
+
+def heading_boost(results: List[FusedResult], query: str, boost: float) -> List[FusedResult]:
+    """
+    Boost docs chunks whose ``heading`` shares terms with the query.
+
+    A chunk's fused score is multiplied by ``1 + boost * overlap``, where
+    overlap is the share of query terms found in the heading, then results
+    are re-sorted.
+    """
+    terms = {t.lower() for t in _TERM.findall(query or "")}
+    if not boost or not terms:
+        return results
+    boosted = False
+    for result in results:
+        heading = result.payload.get("heading")
+        if not heading:
+            continue
+        overlap = len(terms & {t.lower() for t in _TERM.findall(heading)}) / len(terms)
+        if overlap:
+            result.fused_score *= 1 + boost * overlap
+            boosted = True
+    if boosted:
+        results.sort(key=lambda r: r.fused_score, reverse=True)
+    return results
+
+
+def diversify(results: List[FusedResult], diversity_lambda: float) -> List[FusedResult]:
+    """
+    Reorder results by maximal marginal relevance over files.
+
+    Each pick maximizes ``lambda * relevance - (1 - lambda) * redundancy``,
+    where relevance is the fused score relative to the top one and
+    redundancy is 1 for a file already picked. ``diversity_lambda`` 1.0
+    keeps the fused order.
+    """
+    if diversity_lambda >= 1.0 or len(results) < 2:
+        return results
+    top = max(r.fused_score for r in results) or 1.0
+    remaining = list(results)
+    picked: List[FusedResult] = []
+    files = set()
+    while remaining:
+        best = max(
+            remaining,
+            key=lambda r: diversity_lambda * r.fused_score / top
+            - (1.0 - diversity_lambda) * (r.payload.get("full_path") in files),
+        )
+        remaining.remove(best)
+        picked.append(best)
+        files.add(best.payload.get("full_path"))
+    return picked
+
+
 def deduplicate_results(results: List[Dict], key: str = "chunk_id") -> List[Dict]:
     """Remove duplicate results by key."""
     seen = set()
@@ -10,13 +10,18 @@ Models:
 """
 
 import logging
+import threading
+import time
 from typing import List, Dict, Optional, NamedTuple
 from functools import lru_cache
 
 import torch
-from transformers import AutoTokenizer, AutoModelForMaskedLM
+from transformers import AutoModelForMaskedLM
 
 from src.core.config import settings
+from src.services.model_manager import get_model_manager, touch_model, track_loaded_model, warm_up_model
+from src.services.inference.quantization import apply_precision, configured_precision, effective_precision
+from src.services.inference.tokenizer_loader import load_tokenizer
 
 logger = logging.getLogger(__name__)
 
@@ -36,7 +41,7 @@ class SpladeEncoder:
     - Runtime model switching
     - Runtime device switching
     - Batched inference
-    - fp16 support on GPU
+    - fp16 on GPU, dynamic int8 on CPU (see quantization.py)
     """
 
     def __init__(
@@ -45,7 +50,8 @@ class SpladeEncoder:
         device: str = None,
         use_fp16: bool = True,
         max_length: int = None,
-        batch_size: int = None
+        batch_size: int = None,
+        precision: str = None
     ):
         # Get defaults from settings
         default_model = settings.SPARSE_MODEL
@@ -55,15 +61,24 @@ class SpladeEncoder:
         self.max_length = max_length if max_length is not None else settings.SPLADE_MAX_TOKENS
         self.batch_size = batch_size if batch_size is not None else settings.SPLADE_BATCH_SIZE
         self.use_fp16 = use_fp16
+        if precision is None:
+            precision = configured_precision("sparse_embedding") if use_fp16 else "fp32"
+        self.precision = precision
         
         # Determine device
         if device is None:
             self.device = "cuda" if torch.cuda.is_available() and settings.FORCE_GPU else "cpu"
+            if settings.FORCE_GPU and self.device == "cpu":
+                from src.core.device import cuda_unavailable_reason, record_fallback
+                record_fallback("splade", cuda_unavailable_reason() or "CUDA not available")
         else:
             self.device = device
         
         self.model = None
         self.tokenizer = None
+        # Encodes in flight; freeing the model waits for them to finish
+        self._in_use = 0
+        self._idle = threading.Condition()
         self._load_model()
     
     def _load_model(self):
@@ -71,19 +86,25 @@ class SpladeEncoder:
         logger.info(f"Loading SPLADE model: {self.model_id} on {self.device}")
         
         try:
-            self.tokenizer = AutoTokenizer.from_pretrained(self.model_id)
+            self.tokenizer = load_tokenizer(self.model_id)
             self.model = AutoModelForMaskedLM.from_pretrained(self.model_id)
             
-            # Move to device
-            self.model = self.model.to(self.device)
-            
-            # Enable fp16 on CUDA
-            if self.device == "cuda" and self.use_fp16:
-                self.model = self.model.half()
-                logger.info("SPLADE using fp16 precision")
+            # Move to device (a GPU out of memory leaves us on CPU rather than down)
+            try:
+                self.model = self.model.to(self.device)
+            except torch.cuda.OutOfMemoryError as e:
+                from src.core.device import record_fallback
+                record_fallback("splade", f"CUDA out of memory while loading {self.model_id}: {e}")
+                torch.cuda.empty_cache()
+                self.device = "cpu"
+                self.model = self.model.to(self.device)
             
+            self.model = apply_precision(self.model, self.precision, self.device)
             self.model.eval()
-            logger.info(f"SPLADE model loaded successfully on {self.device}")
+            logger.info(
+                f"SPLADE model loaded successfully on {self.device} "
+                f"({effective_precision(self.precision, self.device)})"
+            )
             
         except Exception as e:
             logger.error(f"Failed to load SPLADE model: {e}")
@@ -111,28 +132,32 @@ class SpladeEncoder:
             return
         
         if device == "cuda" and not torch.cuda.is_available():
-            logger.warning("CUDA not available, staying on CPU")
+            from src.core.device import cuda_unavailable_reason, record_fallback
+            record_fallback("splade", cuda_unavailable_reason() or "CUDA not available")
             return
         
         logger.info(f"Switching SPLADE device: {self.device} -> {device}")
         
-        self.device = device
-        self.model = self.model.to(device)
+        if effective_precision(self.precision, self.device) == "int8":
+            # Quantized modules can't move devices; load a fresh copy
+            self._free_model()
+            self.device = device
+            self._load_model()
+            return
         
-        # Handle precision
-        if device == "cuda" and self.use_fp16:
-            self.model = self.model.half()
-        elif device == "cpu":
-            self.model = self.model.float()
+        self.device = device
+        self.model = apply_precision(self.model.to(device), self.precision, device)
     
     def _free_model(self):
-        """Free GPU memory from current model."""
-        if self.model is not None:
-            del self.model
-            self.model = None
-        if self.tokenizer is not None:
-            del self.tokenizer
-            self.tokenizer = None
+        """Free GPU memory from current model (once in-flight encodes finish)."""
+        with self._idle:
+            self._idle.wait_for(lambda: self._in_use == 0)
+            if self.model is not None:
+                del self.model
+                self.model = None
+            if self.tokenizer is not None:
+                del self.tokenizer
+                self.tokenizer = None
         
         if torch.cuda.is_available():
             torch.cuda.empty_cache()
@@ -150,16 +175,24 @@ class SpladeEncoder:
         if not texts:
             return []
         
-        if self.model is None:
-            raise RuntimeError("SPLADE model not loaded")
+        with self._idle:
+            if self.model is None:
+                raise RuntimeError("SPLADE model not loaded")
+            self._in_use += 1
+        touch_model(self.model_id)
         
         results = []
         
-        # Process in batches
-        for i in range(0, len(texts), self.batch_size):
-            batch = texts[i:i + self.batch_size]
-            batch_results = self._encode_batch(batch)
-            results.extend(batch_results)
+        try:
+            # Process in batches
+            for i in range(0, len(texts), self.batch_size):
+                batch = texts[i:i + self.batch_size]
+                batch_results = self._encode_batch(batch)
+                results.extend(batch_results)
+        finally:
+            with self._idle:
+                self._in_use -= 1
+                self._idle.notify_all()
         
         return results
     
@@ -226,25 +259,60 @@ class SpladeEncoder:
 _splade_encoder: Optional[SpladeEncoder] = None
 
 
-def get_splade_encoder() -> SpladeEncoder:
-    """Get or create the singleton SPLADE encoder."""
+def get_splade_encoder(local: bool = False) -> SpladeEncoder:
+    """
+    Get or create the singleton SPLADE encoder.
+
+    With ``inference.remote.sparse`` on, returns a proxy that encodes on ML
+    workers over the event bus; ``local=True`` always uses this process.
+    """
     global _splade_encoder
+
+    if not local and settings.get("inference.remote.sparse", False):
+        from src.services.inference.remote_client import get_remote_sparse_encoder
+        return get_remote_sparse_encoder()
     
     if _splade_encoder is None:
-        _splade_encoder = SpladeEncoder()
+        _splade_encoder = _load_managed()
     
     return _splade_encoder
 
 
+def _load_managed(**kwargs) -> SpladeEncoder:
+    """Load, warm up and hand the encoder to the model manager (idle unload)."""
+    started = time.perf_counter()
+    encoder = SpladeEncoder(**kwargs)
+    load_seconds = time.perf_counter() - started
+    warmup_ms = warm_up_model(encoder.model_id, lambda text: encoder.encode([text]))
+    track_loaded_model(encoder.model_id, "sparse_embedding", encoder, _forget_encoder, load_seconds, warmup_ms)
+    return encoder
+
+
+def _forget_encoder():
+    """Drop the unloaded encoder; the next get_splade_encoder() reloads it."""
+    global _splade_encoder
+    encoder, _splade_encoder = _splade_encoder, None
+    if encoder is not None:
+        encoder._free_model()
+    from src.services.search.retriever import Retriever
+    if Retriever._multi_retriever is not None:
+        Retriever._multi_retriever._splade_encoder = None
+
+
 def reload_splade_encoder(
     model_id: str = None,
     device: str = None
 ) -> SpladeEncoder:
     """Reload SPLADE encoder with new settings."""
     global _splade_encoder
+
+    if settings.get("inference.remote.sparse", False):
+        # Nothing loaded here; the workers own the model
+        return get_splade_encoder()
     
     if _splade_encoder is not None:
-        _splade_encoder._free_model()
+        if not get_model_manager().unload_model(_splade_encoder.model_id, reason="reload"):
+            _forget_encoder()
     
-    _splade_encoder = SpladeEncoder(model_id=model_id, device=device)
+    _splade_encoder = _load_managed(model_id=model_id, device=device)
     return _splade_encoder
@@ -0,0 +1,222 @@
+"""
+Query-Time Boosting Rules.
+
+Per-store rules that scale fused scores after RRF (and the heading boost),
+before results are cut to the page, so a store can prefer some parts of
+its index without reindexing:
+
+- ``path_prefix``: file path starts with ``value`` (``src/``)
+- ``path_glob``: file path or name matches the glob ``value`` (``*_test.go``)
+- ``language``: chunk language is ``value`` (``go``)
+- ``query_language``: chunk language is the one the query asks for
+  ("retry helper in python"); no ``value``
+
+A matching rule multiplies the score by its ``factor`` (``1.2`` boosts,
+``0.5`` demotes); factors of several matching rules multiply. Rules live on
+the store (``boosts``) and are edited with ``PUT /stores/{id}/boosts``.
+
+Stores can also favour recently indexed chunks (``recency``, edited with
+``PUT /stores/{id}/recency``): a chunk's score is multiplied by
+``1 + weight * 0.5 ** (age_days / half_life_days)``, where the age comes
+from ``indexed_at``. Unchanged files are not re-indexed, so that is when
+the file last changed.
+
+Explain output lists the rules (and recency factor) applied to each result.
+"""
+
+import fnmatch
+import logging
+import time
+from typing import Any, Dict, List, Optional
+
+from src.core.config import settings
+from src.services.retrieval.fusion import FusedResult
+
+logger = logging.getLogger(__name__)
+
+PATH_PREFIX = "path_prefix"
+PATH_GLOB = "path_glob"
+LANGUAGE = "language"
+QUERY_LANGUAGE = "query_language"
+
+RULE_TYPES = (PATH_PREFIX, PATH_GLOB, LANGUAGE, QUERY_LANGUAGE)
+
+RECENCY = "recency"
+
+MIN_FACTOR = 0.01
+MAX_FACTOR = 100.0
+
+DAY_SECONDS = 86400
+
+
+class InvalidRule(ValueError):
+    """A boosting rule that can't be applied."""
+
+
+def _path(payload: Dict[str, Any]) -> str:
+    return (payload.get("full_path") or payload.get("file_path") or "").replace("\\", "/")
+
+
+def normalize_rules(rules: List[Dict[str, Any]]) -> List[Dict[str, Any]]:
+    """
+    Validated rules in the stored form (``type``, ``value``, ``factor``).
+
+    Raises:
+        InvalidRule: unknown type, missing value, factor out of range, or
+            more than ``search.boosting.max_rules`` rules
+    """
+    max_rules = int(settings.get("search.boosting.max_rules", 50))
+    if len(rules) > max_rules:
+        raise InvalidRule(f"At most {max_rules} boosting rules per store")
+    normalized = []
+    for i, rule in enumerate(rules):
+        kind = rule.get("type")
+        if kind not in RULE_TYPES:
+            raise InvalidRule(f"Rule {i}: type must be one of {', '.join(RULE_TYPES)}")
+        value = (rule.get("value") or "").strip()
+        if kind == QUERY_LANGUAGE:
+            value = None
+        elif not value:
+            raise InvalidRule(f"Rule {i}: {kind} needs a value")
+        elif kind == LANGUAGE:
+            value = value.lower()
+        try:
+            factor = float(rule.get("factor"))
+        except (TypeError, ValueError):
+            raise InvalidRule(f"Rule {i}: factor must be a number")
+        if not MIN_FACTOR <= factor <= MAX_FACTOR:
+            raise InvalidRule(f"Rule {i}: factor must be between {MIN_FACTOR} and {MAX_FACTOR}")
+        normalized.append({"type": kind, "value": value, "factor": factor})
+    return normalized
+
+
+def query_language(query: str) -> Optional[str]:
+    """Programming language the query asks for, if it names one."""
+    from src.services.query.analyzer import QueryAnalyzer
+    return QueryAnalyzer().analyze(query or "")["scope"].get("language")
+
+
+def matches(rule: Dict[str, Any], payload: Dict[str, Any], target_language: Optional[str] = None) -> bool:
+    """Whether a rule applies to a chunk."""
+    kind = rule["type"]
+    if kind == PATH_PREFIX:
+        return _path(payload).lstrip("/").startswith(rule["value"].lstrip("/"))
+    if kind == PATH_GLOB:
+        path = _path(payload)
+        return bool(path) and (
+            fnmatch.fnmatch(path, rule["value"]) or fnmatch.fnmatch(path.rsplit("/", 1)[-1], rule["value"])
+        )
+    language = (payload.get("language") or "").lower()
+    if kind == LANGUAGE:
+        return language == rule["value"]
+    if kind == QUERY_LANGUAGE:
+        return bool(target_language) and language == target_language
+    return False
+
+
+def apply_boosts(
+    results: List[FusedResult], rules: List[Dict[str, Any]], query: str
+) -> Dict[str, List[Dict[str, Any]]]:
+    """
+    Scale fused scores by the matching rules and re-sort ``results`` in place.
+
+    Returns:
+        chunk_id -> rules applied to it (with the score before boosting),
+        for explain output
+    """
+    if not rules or not results:
+        return {}
+    target = query_language(query) if any(r["type"] == QUERY_LANGUAGE for r in rules) else None
+    applied: Dict[str, List[Dict[str, Any]]] = {}
+    for result in results:
+        hits = [r for r in rules if matches(r, result.payload, target)]
+        if not hits:
+            continue
+        before = result.fused_score
+        for rule in hits:
+            result.fused_score *= rule["factor"]
+        applied[result.chunk_id] = [
+            {**rule, "value": target if rule["type"] == QUERY_LANGUAGE else rule["value"], "score_before": before}
+            for rule in hits
+        ]
+    if applied:
+        results.sort(key=lambda r: r.fused_score, reverse=True)
+    return applied
+
+
+def normalize_recency(half_life_days: Optional[float], weight: Optional[float] = None) -> Optional[Dict[str, float]]:
+    """
+    Validated recency config, or None to turn it off (no half-life).
+
+    Raises:
+        InvalidRule: half-life not positive, or weight outside 0-10
+    """
+    if half_life_days is None:
+        return None
+    if half_life_days <= 0:
+        raise InvalidRule("half_life_days must be positive")
+    if weight is None:
+        weight = float(settings.get("search.recency.weight", 0.5))
+    if not 0 <= weight <= 10:
+        raise InvalidRule("weight must be between 0 and 10")
+    return {"half_life_days": float(half_life_days), "weight": float(weight)}
+
+
+def recency_decay(
+    results: List[FusedResult], config: Optional[Dict[str, float]], now: Optional[float] = None
+) -> Dict[str, List[Dict[str, Any]]]:
+    """
+    Favour recently indexed chunks and re-sort ``results`` in place.
+
+    Chunks without ``indexed_at`` (indexed before it was recorded) are
+    left as they are.
+
+    Returns:
+        chunk_id -> the recency factor applied, in ``apply_boosts`` form
+    """
+    if not config or not results or not config.get("weight"):
+        return {}
+    now = now or time.time()
+    half_life = config["half_life_days"]
+    applied: Dict[str, List[Dict[str, Any]]] = {}
+    for result in results:
+        indexed_at = result.payload.get("indexed_at")
+        if indexed_at is None:
+            continue
+        age_days = max(0.0, (now - float(indexed_at)) / DAY_SECONDS)
+        factor = 1 + config["weight"] * 0.5 ** (age_days / half_life)
+        before = result.fused_score
+        result.fused_score *= factor
+        applied[result.chunk_id] = [{
+            "type": RECENCY,
+            "value": f"{age_days:.1f} days old",
+            "factor": round(factor, 4),
+            "score_before": before,
+        }]
+    if applied:
+        results.sort(key=lambda r: r.fused_score, reverse=True)
+    return applied
+
+
+def get_store_recency(org_id: Optional[str]) -> Optional[Dict[str, float]]:
+    """A store's recency config, None when unset or recency is off."""
+    if not org_id or not settings.get("search.recency.enabled", True):
+        return None
+    try:
+        from src.services.admin.admin_store import get_admin_store
+        return get_admin_store().get_stores().get(org_id, {}).get("recency")
+    except Exception as e:
+        logger.debug(f"No recency config for {org_id}: {e}")
+        return None
+
+
+def get_store_boosts(org_id: Optional[str]) -> List[Dict[str, Any]]:
+    """A store's boosting rules (empty when unset or boosting is off)."""
+    if not org_id or not settings.get("search.boosting.enabled", True):
+        return []
+    try:
+        from src.services.admin.admin_store import get_admin_store
+        return get_admin_store().get_stores().get(org_id, {}).get("boosts") or []
+    except Exception as e:
+        logger.debug(f"No boosting rules for {org_id}: {e}")
+        return []
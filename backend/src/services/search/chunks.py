@@ -0,0 +1,83 @@
+"""
+Chunk Lookup.
+
+Wide searches are heavy when every result carries its full chunk text.
+Search can return short previews instead (``include_content=false``);
+clients fetch a chunk's content when the user opens it.
+"""
+
+import logging
+from typing import Any, Dict, List, Optional
+
+from src.core.config import settings
+from src.db.content_store import payload_text
+
+logger = logging.getLogger(__name__)
+
+
+def preview_result(result: Dict[str, Any], chars: Optional[int] = None) -> Dict[str, Any]:
+    """
+    Copy of a result with ``text`` replaced by a short ``preview`` (read
+    from the content store when the text lives there).
+    """
+    if chars is None:
+        chars = int(settings.get("search.preview_chars", 300))
+    preview = {k: v for k, v in result.items() if k != "text"}
+    preview["preview"] = payload_text(result, chars)
+    preview["content_length"] = result.get("content_length") or len(result.get("text") or "")
+    return preview
+
+
+def get_chunk(chunk_id: str, org_id: str, qdrant_client=None) -> Optional[Dict[str, Any]]:
+    """
+    A chunk's payload (text included) from the hot or cold tier.
+
+    A chunk replaced by re-indexing its file resolves to its successor
+    (``chunk_redirects``), with ``redirected_from`` set to the ID asked for.
+
+    Returns:
+        Payload dict, or None if the chunk does not exist or belongs to
+        another store (``public`` sees every store, as in search)
+    """
+    from src.services.ingestion.chunk_redirects import get_chunk_redirects
+
+    if qdrant_client is None:
+        from src.db.qdrant import get_qdrant_client
+        qdrant_client = get_qdrant_client()
+
+    chunk = _lookup(chunk_id, org_id, qdrant_client)
+    if chunk is not None:
+        return chunk
+    current = get_chunk_redirects().resolve(org_id, chunk_id)
+    if not current:
+        return None
+    chunk = _lookup(current, org_id, qdrant_client)
+    return {**chunk, "redirected_from": chunk_id} if chunk else None
+
+
+def _lookup(chunk_id: str, org_id: str, qdrant_client) -> Optional[Dict[str, Any]]:
+    from src.services.ingestion.migration import store_collection
+    from src.services.search.tiering import store_cold_collection
+
+    collections: List[str] = [store_collection(org_id), store_cold_collection(org_id)]
+    for collection_name in collections:
+        try:
+            points = qdrant_client.retrieve(
+                collection_name=collection_name,
+                ids=[chunk_id],
+                with_payload=True,
+                with_vectors=False
+            )
+        except Exception as e:
+            logger.debug(f"Chunk lookup in {collection_name} failed: {e}")
+            continue
+        if not points:
+            continue
+
+        payload = dict(points[0].payload or {})
+        if org_id and org_id != "public" and payload.get("org_id") != org_id:
+            return None
+        if payload.get("content_ref") and not payload.get("text"):
+            payload["text"] = payload_text(payload)
+        return {"chunk_id": str(points[0].id), **payload}
+    return None
@@ -0,0 +1,209 @@
+"""
+Result Context Expansion.
+
+Agents that consume search results often can't use a snippet without the
+code around it and the file's imports. With ``context_lines`` on a search
+request each result gets a ``context`` object:
+
+    before / after       up to N lines above and below the chunk
+    before_start_line    first line of ``before``
+    after_end_line       last line of ``after``
+    header               the file's package/import block (text and line range)
+    source               ``file`` or ``chunks``
+
+Files are read from the content store: with
+``infrastructure.content_store.files`` the indexer keeps each file's text
+next to its chunks (``file_ref``). Files indexed without it are pieced
+together from their stored chunks, so lines no chunk covers (say, the
+imports of a file chunked by function) can be missing. Privacy-mode results
+never get context.
+"""
+
+import logging
+import re
+from typing import Any, Dict, List, Optional
+
+from qdrant_client.models import FieldCondition, Filter, MatchValue
+
+from src.core.config import settings
+from src.db.content_store import get_content_store, payload_text
+
+logger = logging.getLogger(__name__)
+
+FILE = "file"
+CHUNKS = "chunks"
+
+# Lines that belong to a file's header, per language
+_HEADER_PATTERNS = {
+    "python": r"^(import \w|from [\w.]+ import\b|__all__\b)",
+    "go": r"^(package \w+|import\b)",
+    "rust": r"^(pub )?(use |mod \w+;|extern crate\b)",
+    "java": r"^(package |import )",
+    "kotlin": r"^(package |import )",
+    "scala": r"^(package |import )",
+    "javascript": r"^(import\b|export \* from\b|(const|let|var) .+= require\()",
+    "typescript": r"^(import\b|export \* from\b|(const|let|var) .+= require\()",
+    "tsx": r"^(import\b|export \* from\b)",
+    "c": r"^#\s*(include|import)\b",
+    "cpp": r"^(#\s*(include|import)\b|using namespace\b)",
+    "csharp": r"^(using |namespace )",
+    "ruby": r"^(require|require_relative|load)\b",
+    "php": r"^(<\?php|namespace |use |require|include)",
+    "swift": r"^import ",
+    "dart": r"^(import|export|part|library) ",
+    "elixir": r"^\s*(import|alias|use|require) ",
+}
+HEADER_PATTERNS = {lang: re.compile(pattern) for lang, pattern in _HEADER_PATTERNS.items()}
+# Multi-line import blocks: Go's import ( ... ), Python's from x import ( ... ),
+# JavaScript's import { ... } from
+_BLOCK_OPEN = re.compile(r"[({]$")
+
+
+def file_header(lines: List[Optional[str]], language: Optional[str], scan_lines: int) -> Optional[Dict[str, Any]]:
+    """
+    The package/import block near the top of a file: from its first to its
+    last header line within the first ``scan_lines`` lines.
+
+    ``lines`` may hold None for unknown lines; the block stops at them.
+    """
+    pattern = HEADER_PATTERNS.get((language or "").lower())
+    if not pattern:
+        return None
+    first = last = None
+    in_block = False
+    for i, line in enumerate(lines[:scan_lines]):
+        if line is None:
+            if first is not None:
+                break
+            continue
+        stripped = line.rstrip()
+        if in_block:
+            last = i
+            in_block = not stripped.startswith((")", "}"))
+        elif pattern.match(stripped):
+            first = i if first is None else first
+            last = i
+            in_block = bool(_BLOCK_OPEN.search(stripped))
+    if first is None:
+        return None
+    return {
+        "start_line": first + 1,
+        "end_line": last + 1,
+        "text": "\n".join(line or "" for line in lines[first:last + 1]),
+    }
+
+
+def surrounding_lines(lines: List[Optional[str]], start_line: int, end_line: int, count: int) -> Dict[str, Any]:
+    """Up to ``count`` known lines above ``start_line`` and below ``end_line`` (1-based)."""
+    before: List[str] = []
+    i = start_line - 2
+    while 0 <= i < len(lines) and len(before) < count and lines[i] is not None:
+        before.insert(0, lines[i])
+        i -= 1
+    after: List[str] = []
+    i = end_line
+    while i < len(lines) and len(after) < count and lines[i] is not None:
+        after.append(lines[i])
+        i += 1
+    return {
+        "before": "\n".join(before),
+        "after": "\n".join(after),
+        "before_start_line": start_line - len(before),
+        "after_end_line": end_line + len(after),
+    }
+
+
+def lines_from_chunks(payloads: List[Dict[str, Any]]) -> List[Optional[str]]:
+    """A file's lines pieced together from its chunks (None where no chunk covers a line)."""
+    known: Dict[int, str] = {}
+    for payload in payloads:
+        start = int(payload.get("start_line") or 0)
+        if start <= 0:
+            continue
+        for offset, line in enumerate(payload_text(payload).split("\n")):
+            known.setdefault(start + offset, line)
+    if not known:
+        return []
+    return [known.get(n) for n in range(1, max(known) + 1)]
+
+
+class ContextExpander:
+    """Attaches surrounding lines and file headers to search results."""
+
+    def __init__(self, qdrant_client=None):
+        self._qdrant = qdrant_client
+        self._files: Dict[tuple, tuple] = {}
+
+    @property
+    def qdrant(self):
+        if self._qdrant is None:
+            from src.db.qdrant import get_qdrant_client
+            self._qdrant = get_qdrant_client()
+        return self._qdrant
+
+    def _file_lines(self, result: Dict[str, Any], org_id: str) -> tuple:
+        """(lines, source) for a result's file, cached per expansion."""
+        path = result.get("full_path") or result.get("file_path")
+        key = (result.get("org_id") or org_id, path)
+        if key in self._files:
+            return self._files[key]
+
+        lines, source = [], None
+        ref = result.get("file_ref")
+        text = get_content_store().get(ref) if ref else None
+        if text is not None:
+            lines, source = text.split("\n"), FILE
+        elif path:
+            lines, source = lines_from_chunks(self._chunk_payloads(key[0], path)), CHUNKS
+        self._files[key] = (lines, source)
+        return lines, source
+
+    def _chunk_payloads(self, org_id: str, path: str) -> List[Dict[str, Any]]:
+        from src.services.ingestion.migration import store_collection
+
+        max_chunks = int(settings.get("search.context.max_chunks", 500))
+        try:
+            points, _ = self.qdrant.scroll(
+                collection_name=store_collection(org_id),
+                scroll_filter=Filter(must=[
+                    FieldCondition(key="org_id", match=MatchValue(value=org_id)),
+                    FieldCondition(key="full_path", match=MatchValue(value=path)),
+                ]),
+                limit=max_chunks,
+                with_payload=True,
+                with_vectors=False,
+            )
+        except Exception as e:
+            logger.warning(f"Failed to load chunks of {path} for context: {e}")
+            return []
+        return [p.payload for p in points]
+
+    def expand(self, results: List[Dict[str, Any]], org_id: str, context_lines: int) -> List[Dict[str, Any]]:
+        """Copies of ``results`` with a ``context`` object (None when unavailable)."""
+        scan_lines = int(settings.get("search.context.header_scan_lines", 200))
+        expanded = []
+        for result in results:
+            context = None
+            start, end = int(result.get("start_line") or 0), int(result.get("end_line") or 0)
+            if result.get("content_stored", True) and start > 0:
+                lines, source = self._file_lines(result, org_id)
+                if lines:
+                    context = {
+                        **surrounding_lines(lines, start, max(end, start), context_lines),
+                        "header": file_header(lines, result.get("language"), scan_lines),
+                        "source": source,
+                    }
+                    # The chunk itself may be the header
+                    header = context["header"]
+                    if header and header["start_line"] >= start and header["end_line"] <= end:
+                        context["header"] = None
+            expanded.append({**result, "context": context})
+        return expanded
+
+
+def expand_context(results: List[Dict[str, Any]], org_id: str, context_lines: int) -> List[Dict[str, Any]]:
+    """Add ``context`` to search results (``context_lines`` capped by ``search.context.max_lines``)."""
+    context_lines = min(int(context_lines), int(settings.get("search.context.max_lines", 50)))
+    if context_lines <= 0 or not results:
+        return results
+    return ContextExpander().expand(results, org_id, context_lines)
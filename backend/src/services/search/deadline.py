@@ -0,0 +1,74 @@
+"""
+Search Deadlines.
+
+Bounds how long a search may run, independent of HTTP server/client
+timeouts. A search that overruns (usually a slow rerank or a wedged
+retriever) is cancelled and raises ``SearchTimeoutError`` so callers get a
+fast, typed failure instead of waiting for the connection to drop.
+
+The deadline is ``search.timeout.default_seconds`` unless a request asks
+for its own, capped at ``search.timeout.max_seconds``. A timeout of 0
+means "as long as allowed"; with ``max_seconds`` also 0 there is no
+deadline.
+"""
+
+import asyncio
+import logging
+from typing import Any, Awaitable, Dict, Optional
+
+from src.core.config import settings
+
+logger = logging.getLogger(__name__)
+
+
+class SearchTimeoutError(Exception):
+    """Raised when a search exceeds its deadline."""
+
+    def __init__(self, timeout: float):
+        super().__init__(f"Search timed out after {timeout:.1f}s")
+        self.timeout = timeout
+
+    def to_dict(self) -> Dict[str, Any]:
+        return {"error": "search_timeout", "timeout_seconds": self.timeout, "message": str(self)}
+
+
+def resolve_timeout(requested: Optional[float] = None) -> Optional[float]:
+    """
+    Deadline in seconds for a search.
+
+    Args:
+        requested: Per-request override (None: server default)
+
+    Returns:
+        Seconds, or None for no deadline
+    """
+    timeout = requested if requested is not None else float(settings.get("search.timeout.default_seconds", 10.0))
+    max_timeout = float(settings.get("search.timeout.max_seconds", 60.0))
+    if max_timeout > 0:
+        timeout = min(timeout, max_timeout) if timeout > 0 else max_timeout
+    return timeout if timeout > 0 else None
+
+
+async def with_deadline(call: Awaitable[Any], timeout: Optional[float]) -> Any:
+    """
+    Await a search under a deadline.
+
+    Raises:
+        SearchTimeoutError: If the call does not finish in time
+    """
+    if timeout is None:
+        return await call
+    try:
+        return await asyncio.wait_for(call, timeout=timeout)
+    except asyncio.TimeoutError:
+        logger.warning(f"Search timed out after {timeout:.1f}s")
+        _count_timeout()
+        raise SearchTimeoutError(timeout)
+
+
+def _count_timeout():
+    try:
+        from src.services.admin.admin_store import get_admin_store
+        get_admin_store().increment_counter("search_timeouts")
+    except Exception:
+        pass
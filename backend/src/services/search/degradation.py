@@ -0,0 +1,49 @@
+"""
+Search Degradation Notes.
+
+When a search drops part of its work because a dependency is failing
+(reranking skipped, dense retrieval skipped, Qdrant unreachable), the step
+that gave up records why. The search endpoint collects the notes and
+answers with ``degraded: true`` and the reasons instead of an error, and
+degraded results are not cached.
+
+Notes travel in a context variable, so retrievers running concurrently in
+one search (``asyncio.gather``) add to the same list.
+"""
+
+import logging
+from contextlib import contextmanager
+from contextvars import ContextVar
+from typing import List, Optional
+
+logger = logging.getLogger(__name__)
+
+_notes: ContextVar[Optional[List[str]]] = ContextVar("search_degradation", default=None)
+
+
+@contextmanager
+def track():
+    """
+    Collect degradation notes for the enclosed search.
+
+    Nested ``track`` blocks share the outer list, so the endpoint sees what
+    any layer below it noted.
+    """
+    notes = _notes.get()
+    if notes is not None:
+        yield notes
+        return
+    notes = []
+    token = _notes.set(notes)
+    try:
+        yield notes
+    finally:
+        _notes.reset(token)
+
+
+def note(reason: str):
+    """Record that the current search is degraded (no-op outside ``track``)."""
+    logger.warning(f"Search degraded: {reason}")
+    notes = _notes.get()
+    if notes is not None and reason not in notes:
+        notes.append(reason)
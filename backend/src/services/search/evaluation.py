@@ -0,0 +1,135 @@
+"""
+Relevance Evaluation Harness.
+
+Runs a labeled query set against a store and computes NDCG@k, MRR and
+recall@k. Used by ``ricesearch eval`` (via ``POST /api/v1/search/eval``) to
+gate model and chunker changes against a golden dataset.
+
+Labels are file paths, optionally with graded relevance::
+
+    {"query": "where is the jwt validated", "relevant": ["src/auth/jwt.py"]}
+    {"query": "retry policy", "relevant": {"src/http/retry.py": 3, "docs/retry.md": 1}}
+
+A result matches a label when its path ends with the labeled path, so
+labels can be written relative to the repository root.
+"""
+
+import math
+import logging
+from typing import Any, Awaitable, Callable, Dict, List, Optional, Union
+
+logger = logging.getLogger(__name__)
+
+Labels = Union[List[str], Dict[str, float]]
+
+
+def normalize_labels(relevant: Labels) -> Dict[str, float]:
+    """Labels as path -> grade (plain lists are grade 1)."""
+    if isinstance(relevant, dict):
+        return {p.replace("\\", "/"): float(g) for p, g in relevant.items()}
+    return {p.replace("\\", "/"): 1.0 for p in relevant}
+
+
+def match_label(path: Optional[str], labels: Dict[str, float]) -> Optional[str]:
+    """Label a result path matches (suffix match on path segments)."""
+    if not path:
+        return None
+    path = path.replace("\\", "/")
+    for label in labels:
+        if path == label or path.endswith("/" + label.lstrip("/")):
+            return label
+    return None
+
+
+def result_path(result: Dict[str, Any]) -> Optional[str]:
+    return result.get("full_path") or result.get("file_path") or result.get("client_system_path")
+
+
+def score_query(ranked_paths: List[Optional[str]], relevant: Labels, k: int) -> Dict[str, Any]:
+    """
+    Metrics for one query.
+
+    Each label is credited once, at its best rank.
+
+    Returns:
+        Dict with ndcg, mrr, recall, first_relevant_rank and matched labels
+    """
+    labels = normalize_labels(relevant)
+    gains = []
+    found = []
+    for path in ranked_paths[:k]:
+        label = match_label(path, labels)
+        if label and label not in found:
+            found.append(label)
+            gains.append(labels[label])
+        else:
+            gains.append(0.0)
+
+    dcg = sum((2 ** g - 1) / math.log2(i + 2) for i, g in enumerate(gains))
+    ideal = sorted(labels.values(), reverse=True)[:k]
+    idcg = sum((2 ** g - 1) / math.log2(i + 2) for i, g in enumerate(ideal))
+
+    first = next((i + 1 for i, g in enumerate(gains) if g > 0), None)
+    return {
+        "ndcg": dcg / idcg if idcg else 0.0,
+        "mrr": 1.0 / first if first else 0.0,
+        "recall": len(found) / len(labels) if labels else 0.0,
+        "first_relevant_rank": first,
+        "matched": found,
+        "missing": [label for label in labels if label not in found],
+    }
+
+
+def aggregate(per_query: List[Dict[str, Any]], k: int) -> Dict[str, float]:
+    """Mean metrics across queries, keyed as ndcg@k, mrr, recall@k."""
+    n = len(per_query)
+    if not n:
+        return {f"ndcg@{k}": 0.0, "mrr": 0.0, f"recall@{k}": 0.0}
+    return {
+        f"ndcg@{k}": round(sum(q["ndcg"] for q in per_query) / n, 4),
+        "mrr": round(sum(q["mrr"] for q in per_query) / n, 4),
+        f"recall@{k}": round(sum(q["recall"] for q in per_query) / n, 4),
+    }
+
+
+async def run_eval(
+    queries: List[Dict[str, Any]],
+    org_id: str,
+    k: int = 10,
+    search: Optional[Callable[..., Awaitable[List[Dict]]]] = None,
+    **search_kwargs,
+) -> Dict[str, Any]:
+    """
+    Run every labeled query against a store and score the results.
+
+    Args:
+        queries: Dicts with "query" and "relevant" labels
+        org_id: Store to search
+        k: Cutoff for NDCG and recall
+        search: Async search function (default: Retriever.search)
+        search_kwargs: Extra retriever flags (use_bm25, rerank, ...)
+
+    Returns:
+        Dict with aggregate metrics and per-query detail
+    """
+    if search is None:
+        from src.services.search.retriever import Retriever
+        search = Retriever.search
+
+    per_query = []
+    for item in queries:
+        try:
+            results = await search(query=item["query"], limit=k, org_id=org_id, **search_kwargs)
+        except Exception as e:
+            logger.warning(f"Eval query failed '{item['query']}': {e}")
+            results = []
+        scored = score_query([result_path(r) for r in results], item.get("relevant", []), k)
+        per_query.append({"query": item["query"], **scored})
+
+    return {
+        "store": org_id,
+        "k": k,
+        "query_count": len(per_query),
+        "metrics": aggregate(per_query, k),
+        "queries": per_query,
+    }
@@ -0,0 +1,359 @@
+"""
+A/B Model Experiments.
+
+Compares a candidate embedding or rerank model (variant B) against the
+store's current model (variant A) on live queries.
+
+- embedding: chunks are additionally embedded with model B into the named
+  vector ``dense_b`` of a per-store experiment collection (same point ids as
+  the main collection; Qdrant can't add named vectors to an existing
+  collection). New files get B vectors at index time; existing files via
+  ``backfill``. Each variant is a dense search with its own model.
+- rerank: one candidate set from the normal search pipeline is reranked by
+  both models.
+
+Searches with ``experiment: true`` return both variants side by side with
+overlap metrics (``overlap_metrics``).
+"""
+
+import asyncio
+import logging
+import time
+from concurrent.futures import ThreadPoolExecutor
+from datetime import datetime
+from typing import Any, Dict, List, Optional
+
+from qdrant_client.models import (
+    Distance,
+    FieldCondition,
+    Filter,
+    MatchValue,
+    PointStruct,
+    VectorParams,
+)
+
+from src.core.config import settings
+from src.db.content_store import payload_text
+from src.services.ingestion.migration import store_collection
+from src.services.search.filters import SearchFilters, build_filter
+
+logger = logging.getLogger(__name__)
+
+EMBEDDING = "embedding"
+RERANK = "rerank"
+KINDS = (EMBEDDING, RERANK)
+
+VECTOR_B = "dense_b"
+
+
+def experiment_collection(store_id: str) -> str:
+    """Collection holding a store's variant B vectors."""
+    return f"{settings.COLLECTION_PREFIX}_exp_{store_id}"
+
+
+def get_store_experiment(store_id: Optional[str]) -> Optional[Dict[str, Any]]:
+    """A store's active experiment ({"kind", "model_a", "model_b", ...}) or None."""
+    if not store_id:
+        return None
+    try:
+        from src.services.admin.admin_store import get_admin_store
+        return get_admin_store().get_stores().get(store_id, {}).get("experiment")
+    except Exception as e:
+        logger.debug(f"Could not read experiment for {store_id}: {e}")
+        return None
+
+
+def current_model(kind: str) -> str:
+    """The production model an experiment's variant A runs with."""
+    if kind == EMBEDDING:
+        return settings.EMBEDDING_MODEL_NAME
+    return settings.RERANK_MODEL
+
+
+def overlap_metrics(a: List[str], b: List[str], k: int) -> Dict[str, Any]:
+    """
+    How similar two rankings are in their top ``k``.
+
+    - overlap: shared results / results returned (1.0 = same set)
+    - jaccard: shared / union
+    - rbo: rank-biased overlap (p=0.9), weights agreement at the top
+    - top1_agree: both variants rank the same result first
+    - mean_rank_shift: average position change of shared results
+    """
+    top_a, top_b = a[:k], b[:k]
+    shared = set(top_a) & set(top_b)
+    union = set(top_a) | set(top_b)
+    depth = max(len(top_a), len(top_b))
+
+    p = 0.9
+    rbo = 0.0
+    for d in range(1, depth + 1):
+        rbo += p ** (d - 1) * len(set(top_a[:d]) & set(top_b[:d])) / d
+    rbo = rbo * (1 - p) / (1 - p ** depth) if depth else 1.0
+
+    shifts = [abs(top_a.index(r) - top_b.index(r)) for r in shared]
+    return {
+        "k": k,
+        "shared": len(shared),
+        "only_a": len(set(top_a) - shared),
+        "only_b": len(set(top_b) - shared),
+        "overlap": round(len(shared) / depth, 4) if depth else 1.0,
+        "jaccard": round(len(shared) / len(union), 4) if union else 1.0,
+        "rbo": round(rbo, 4),
+        "top1_agree": bool(top_a and top_b and top_a[0] == top_b[0]),
+        "mean_rank_shift": round(sum(shifts) / len(shifts), 2) if shifts else None,
+    }
+
+
+def embedding_text(payload: Dict[str, Any]) -> str:
+    """The text the indexer embeds for a chunk (file name and path prepended)."""
+    return f"File: {payload.get('filename', '')}\nPath: {payload.get('full_path', '')}\n\n{payload_text(payload)}"
+
+
+def _run(coro):
+    """Run a coroutine from sync code without touching the caller's event loop."""
+    with ThreadPoolExecutor(max_workers=1) as pool:
+        return pool.submit(asyncio.run, coro).result()
+
+
+class ExperimentRunner:
+    """Indexes variant B vectors and runs side-by-side searches."""
+
+    def __init__(self, qdrant_client=None):
+        self._qdrant = qdrant_client
+        self.backfills: Dict[str, Dict[str, Any]] = {}
+
+    @property
+    def qdrant(self):
+        """Lazy Qdrant client."""
+        if self._qdrant is None:
+            from src.db.qdrant import get_qdrant_client
+            self._qdrant = get_qdrant_client()
+        return self._qdrant
+
+    @property
+    def batch_size(self) -> int:
+        return int(settings.get("experiments.backfill_batch_size", 64))
+
+    # ============== Lifecycle ==============
+
+    def start(self, store_id: str, kind: str, model_b: str) -> Dict[str, Any]:
+        """Experiment record for a store (the caller saves it on the store)."""
+        if kind not in KINDS:
+            raise ValueError(f"Unknown experiment kind '{kind}'; expected one of {list(KINDS)}")
+        model_a = current_model(kind)
+        if model_b == model_a:
+            raise ValueError(f"Variant B must differ from the current model ({model_a})")
+        if kind == EMBEDDING:
+            # Recreated so vectors from an earlier model B can't leak in
+            self.drop(store_id)
+            self._ensure_collection(store_id, len(self._embed_b([model_b], model_b)[0]))
+        return {
+            "kind": kind,
+            "model_a": model_a,
+            "model_b": model_b,
+            "started_at": datetime.now().isoformat(),
+        }
+
+    def drop(self, store_id: str):
+        """Remove a store's experiment vectors."""
+        self.backfills.pop(store_id, None)
+        try:
+            self.qdrant.delete_collection(experiment_collection(store_id))
+        except Exception as e:
+            logger.debug(f"No experiment collection to drop for {store_id}: {e}")
+
+    def _ensure_collection(self, store_id: str, dimension: int):
+        name = experiment_collection(store_id)
+        try:
+            self.qdrant.get_collection(name)
+        except Exception:
+            logger.info(f"Creating experiment collection {name} ({VECTOR_B}, {dimension} dims)")
+            self.qdrant.create_collection(
+                collection_name=name,
+                vectors_config={VECTOR_B: VectorParams(size=dimension, distance=Distance.COSINE)},
+            )
+
+    # ============== Indexing ==============
+
+    def _embed_b(self, texts: List[str], model: str) -> List[List[float]]:
+        from src.services.inference import get_inference_client
+        return _run(get_inference_client().embed(texts, model))
+
+    def index_points(self, store_id: str, points: List[Any], texts: List[str]) -> int:
+        """
+        Add variant B vectors for freshly indexed points (embedding experiments).
+
+        The payload is copied without the chunk text so filters work on the
+        experiment collection; results are hydrated from the main collection.
+        """
+        experiment = get_store_experiment(store_id)
+        if not points or not experiment or experiment.get("kind") != EMBEDDING:
+            return 0
+        vectors = self._embed_b(texts, experiment["model_b"])
+        self.qdrant.upsert(
+            collection_name=experiment_collection(store_id),
+            points=[
+                PointStruct(
+                    id=point.id,
+                    vector={VECTOR_B: vector},
+                    payload={k: v for k, v in (point.payload or {}).items() if k != "text"},
+                )
+                for point, vector in zip(points, vectors)
+            ],
+        )
+        return len(points)
+
+    def backfill(self, store_id: str) -> Dict[str, Any]:
+        """Embed a store's already indexed chunks with model B."""
+        experiment = get_store_experiment(store_id)
+        if not experiment or experiment.get("kind") != EMBEDDING:
+            raise ValueError(f"Store {store_id} has no embedding experiment")
+
+        status = {"state": "running", "indexed": 0, "started_at": datetime.now().isoformat(), "error": None}
+        self.backfills[store_id] = status
+        offset = None
+        try:
+            while True:
+                points, offset = self.qdrant.scroll(
+                    collection_name=store_collection(store_id),
+                    scroll_filter=Filter(must=[FieldCondition(key="org_id", match=MatchValue(value=store_id))]),
+                    limit=self.batch_size,
+                    offset=offset,
+                    with_payload=True,
+                )
+                if points:
+                    # Scrolled records carry id and payload like the indexer's points
+                    status["indexed"] += self.index_points(
+                        store_id, points, [embedding_text(p.payload) for p in points]
+                    )
+                if offset is None:
+                    break
+            status["state"] = "complete"
+        except Exception as e:
+            logger.error(f"Experiment backfill of {store_id} failed: {e}")
+            status.update(state="failed", error=str(e))
+        status["finished_at"] = datetime.now().isoformat()
+        return status
+
+    # ============== Search ==============
+
+    async def compare(
+        self,
+        query: str,
+        store_id: str,
+        limit: int = 10,
+        filters: Optional[SearchFilters] = None,
+        **search_options: Any,
+    ) -> Dict[str, Any]:
+        """
+        Run the query with both variants.
+
+        Returns:
+            {"kind", "variants": {"a": {...}, "b": {...}}, "metrics": {...}}
+            where each variant has its ``model``, ``results`` and ``latency_ms``.
+        """
+        experiment = get_store_experiment(store_id)
+        if not experiment:
+            raise ValueError(f"Store {store_id} has no active experiment")
+
+        if experiment["kind"] == EMBEDDING:
+            search_filter = build_filter(store_id, filters)
+            a, b = await asyncio.gather(
+                self._timed(self._dense_a(query, limit, search_filter, store_id)),
+                self._timed(self._dense_b(query, limit, search_filter, store_id, experiment["model_b"])),
+            )
+        else:
+            a, b = await self._compare_rerank(query, store_id, limit, filters, experiment["model_b"], search_options)
+
+        ids_a = [r["chunk_id"] for r in a["results"]]
+        ids_b = [r["chunk_id"] for r in b["results"]]
+        return {
+            "kind": experiment["kind"],
+            "variants": {
+                "a": {"model": experiment["model_a"], **a},
+                "b": {"model": experiment["model_b"], **b},
+            },
+            "metrics": overlap_metrics(ids_a, ids_b, limit),
+        }
+
+    @staticmethod
+    async def _timed(coro) -> Dict[str, Any]:
+        started = time.perf_counter()
+        results = await coro
+        return {"results": results, "latency_ms": round((time.perf_counter() - started) * 1000, 1)}
+
+    @staticmethod
+    def _format(point, payload: Dict[str, Any]) -> Dict[str, Any]:
+        return {"chunk_id": str(point.id), "score": point.score, **payload, "text": payload_text(payload)}
+
+    async def _dense_a(self, query: str, limit: int, search_filter, store_id: str) -> List[Dict[str, Any]]:
+        from src.services.search.retriever import embed_texts_async
+        vector = (await embed_texts_async([query]))[0]
+        response = await asyncio.to_thread(
+            self.qdrant.query_points,
+            collection_name=store_collection(store_id),
+            query=vector,
+            using="dense",
+            limit=limit,
+            query_filter=search_filter,
+            with_payload=True,
+        )
+        return [self._format(p, p.payload) for p in response.points]
+
+    async def _dense_b(self, query: str, limit: int, search_filter, store_id: str, model: str) -> List[Dict[str, Any]]:
+        from src.services.inference import get_inference_client
+        vector = (await get_inference_client().embed([query], model))[0]
+        response = await asyncio.to_thread(
+            self.qdrant.query_points,
+            collection_name=experiment_collection(store_id),
+            query=vector,
+            using=VECTOR_B,
+            limit=limit,
+            query_filter=search_filter,
+        )
+        if not response.points:
+            return []
+        # Chunk text lives in the store's collection (chunks deleted since are dropped)
+        stored = await asyncio.to_thread(
+            self.qdrant.retrieve,
+            collection_name=store_collection(store_id),
+            ids=[p.id for p in response.points],
+            with_payload=True,
+        )
+        payloads = {str(p.id): p.payload for p in stored}
+        return [self._format(p, payloads[str(p.id)]) for p in response.points if str(p.id) in payloads]
+
+    async def _compare_rerank(self, query, store_id, limit, filters, model_b, search_options):
+        from src.services.search.reranker import rerank_search_results
+        from src.services.search.retriever import Retriever
+
+        depth = int(settings.get("experiments.rerank_candidates", 30))
+        candidates = await Retriever.search(
+            query, limit=max(limit, depth), org_id=store_id, rerank=False,
+            filters=filters, use_cache=False, **search_options
+        )
+
+        async def variant_a():
+            return (await rerank_search_results(query, [dict(r) for r in candidates]))[:limit]
+
+        async def variant_b():
+            ranked = await self._reranker(model_b).rerank(query, [r.get("text", "") for r in candidates])
+            return [{**candidates[r["index"]], "rerank_score": r["relevance_score"]} for r in ranked[:limit]]
+
+        return await asyncio.gather(self._timed(variant_a()), self._timed(variant_b()))
+
+    def _reranker(self, model: str):
+        from src.services.inference.local_reranker import get_named_reranker
+        return get_named_reranker(model)
+
+
+# Singleton instance
+_experiment_runner: Optional[ExperimentRunner] = None
+
+def get_experiment_runner() -> ExperimentRunner:
+    """Get global experiment runner instance."""
+    global _experiment_runner
+    if _experiment_runner is None:
+        _experiment_runner = ExperimentRunner()
+    return _experiment_runner
@@ -0,0 +1,136 @@
+"""
+Search Result Explanation.
+
+Records how each result moved through the search pipeline so relevance
+regressions can be debugged:
+
+1. Retrievers: rank and raw score in each retriever's list
+2. Fusion: weighted RRF contribution per retriever and the fused rank
+3. Boosts: the store's boosting rules that scaled the fused score
+4. Dedup: lower-scoring chunks from the same file that were dropped
+5. Cold tier: results added from the cold collection
+6. Rerank: rank before/after and the rerank score
+
+Only used when a search is made with ``explain=true``.
+"""
+
+from typing import Dict, List, Optional, Any
+
+from src.services.retrieval.fusion import FusedResult
+
+
+def _path(result: Dict) -> Optional[str]:
+    return result.get("full_path") or result.get("file_path") or result.get("client_system_path")
+
+
+class SearchExplainer:
+    """Collects per-stage ranks during one search and annotates the results."""
+
+    def __init__(self, rrf_k: int, weights: Optional[Dict[str, float]] = None):
+        self.rrf_k = rrf_k
+        self.weights = weights or {}
+        self.retrievers: List[str] = []
+        self._ranks: Dict[str, Dict[str, Dict[str, float]]] = {}
+        self._fused: Dict[str, Dict[str, float]] = {}
+        self._dedup_removed: Dict[str, List[Dict[str, Any]]] = {}
+        self._boosts: Dict[str, List[Dict[str, Any]]] = {}
+        self._pre_rerank: Dict[str, int] = {}
+
+    def record_retrievers(self, result_sets: Dict[str, List[Dict]]):
+        """Rank and score of each chunk in each retriever's list."""
+        self.retrievers = list(result_sets.keys())
+        for name, results in result_sets.items():
+            for rank, result in enumerate(results):
+                chunk_id = result.get("chunk_id") or result.get("id")
+                if chunk_id:
+                    self._ranks.setdefault(chunk_id, {})[name] = {
+                        "rank": rank + 1,
+                        "score": result.get("score", 0.0),
+                    }
+
+    def record_fusion(self, fused_results: List[FusedResult], output: List[Dict]):
+        """Fused rank/score, and which chunks file dedup dropped in favour of which."""
+        for rank, fused in enumerate(fused_results):
+            self._fused[fused.chunk_id] = {"rank": rank + 1, "score": fused.fused_score}
+
+        kept_by_path = {_path(r): r["chunk_id"] for r in output if _path(r)}
+        kept = {r["chunk_id"] for r in output}
+        for fused in fused_results:
+            if fused.chunk_id in kept:
+                continue
+            winner = kept_by_path.get(_path(fused.payload))
+            if winner:
+                self._dedup_removed.setdefault(winner, []).append({
+                    "chunk_id": fused.chunk_id,
+                    "fused_rank": self._fused[fused.chunk_id]["rank"],
+                    "fused_score": fused.fused_score,
+                })
+
+    def record_boosts(self, applied: Dict[str, List[Dict[str, Any]]]):
+        """Boosting rules applied to each chunk (``boosting.apply_boosts``)."""
+        self._boosts = applied
+
+    def record_pre_rerank(self, output: List[Dict]):
+        """Positions right before reranking."""
+        self._pre_rerank = {r["chunk_id"]: i + 1 for i, r in enumerate(output)}
+
+    def _retriever_detail(self, chunk_id: str) -> Dict[str, Dict[str, float]]:
+        detail = {}
+        for name, entry in self._ranks.get(chunk_id, {}).items():
+            weight = self.weights.get(name, 1.0)
+            detail[name] = {
+                **entry,
+                "weight": weight,
+                "rrf_contribution": weight / (self.rrf_k + entry["rank"]),
+            }
+        return detail
+
+    def explain(self, result: Dict, final_rank: int, reranked: bool) -> Dict[str, Any]:
+        """Build the explanation for one final result."""
+        chunk_id = result["chunk_id"]
+        stages = []
+
+        fused = self._fused.get(chunk_id)
+        if result.get("tier") == "cold":
+            stages.append("cold_tier: added from the cold collection because hot results were thin")
+
+        boosts = self._boosts.get(chunk_id, [])
+        for rule in boosts:
+            target = f" {rule['value']}" if rule.get("value") else ""
+            stages.append(f"boost: x{rule['factor']:g} ({rule['type']}{target})")
+
+        removed = self._dedup_removed.get(chunk_id, [])
+        if removed:
+            stages.append(f"dedup: kept over {len(removed)} lower-scoring chunk(s) from the same file")
+
+        rerank = None
+        before = self._pre_rerank.get(chunk_id)
+        if reranked and before is not None:
+            delta = before - final_rank
+            rerank = {
+                "score": result.get("rerank_score"),
+                "rank_before": before,
+                "rank_after": final_rank,
+                "delta": delta,
+            }
+            if delta:
+                stages.append(f"rerank: moved {'up' if delta > 0 else 'down'} {abs(delta)}")
+
+        retrievers = self._retriever_detail(chunk_id)
+        return {
+            "final_rank": final_rank,
+            "fused_rank": fused["rank"] if fused else None,
+            "fused_score": fused["score"] if fused else result.get("score"),
+            "retrievers": retrievers,
+            "missing_from": [name for name in self.retrievers if name not in retrievers],
+            "rerank": rerank,
+            "boosts": boosts,
+            "dedup_removed": removed,
+            "stages": stages,
+        }
+
+    def annotate(self, output: List[Dict], reranked: bool) -> List[Dict]:
+        """Attach an ``explanation`` to every result."""
+        for i, result in enumerate(output):
+            result["explanation"] = self.explain(result, i + 1, reranked)
+        return output
@@ -0,0 +1,91 @@
+"""
+Search Facets.
+
+Counts over a search's candidate set (before it is cut to ``limit``), so a
+UI can show what the matches are made of and narrow with one click:
+
+- ``language``: programming language of the file (``language`` payload)
+- ``directory``: top-level directory below the directory all candidates
+  share (``/work/api`` for ``/work/api/src/...`` and ``/work/api/docs/...``)
+- ``connection``: CLI connection that uploaded the file
+
+Each value carries the filter that selects it (``languages``, ``paths``
+or ``connections`` in the search request). Candidates are counted per file
+after deduplication, so counts are files, not chunks.
+"""
+
+import posixpath
+from collections import Counter
+from typing import Any, Dict, List, Optional
+
+from src.core.config import settings
+from src.services.search.filters import normalize_path
+
+
+def _path(result: Dict[str, Any]) -> str:
+    return normalize_path(result.get("full_path") or result.get("file_path") or "")
+
+
+def common_directory(paths: List[str]) -> str:
+    """Deepest directory containing every path ("" if none is shared)."""
+    dirs = [posixpath.dirname(p) for p in paths if p]
+    if not dirs:
+        return ""
+    split = [d.split("/") for d in dirs]
+    common = []
+    for parts in zip(*split):
+        if len(set(parts)) != 1:
+            break
+        common.append(parts[0])
+    return "/".join(common)
+
+
+def _top_directory(path: str, root: str) -> Optional[str]:
+    """First directory of ``path`` below ``root`` (None for files directly in it)."""
+    rest = path[len(root):].lstrip("/") if root else path.lstrip("/")
+    if "/" not in rest:
+        return None
+    return rest.split("/", 1)[0]
+
+
+def _values(counter: Counter, max_values: int, filter_key: str, filter_value) -> List[Dict[str, Any]]:
+    ranked = sorted(counter.items(), key=lambda item: (-item[1], item[0]))[:max_values]
+    return [
+        {"value": value, "count": count, "filter": {filter_key: [filter_value(value)]}}
+        for value, count in ranked
+    ]
+
+
+def compute_facets(results: List[Dict[str, Any]], max_values: Optional[int] = None) -> Dict[str, List[Dict[str, Any]]]:
+    """
+    Facet counts over search candidates.
+
+    Returns:
+        Dict of facet -> [{value, count, filter}], most common first; facets
+        with no values are omitted
+    """
+    if max_values is None:
+        max_values = int(settings.get("search.facets.max_values", 10))
+
+    paths = [_path(r) for r in results]
+    root = common_directory(paths)
+
+    languages: Counter = Counter()
+    directories: Counter = Counter()
+    connections: Counter = Counter()
+    for result, path in zip(results, paths):
+        if result.get("language"):
+            languages[str(result["language"]).lower()] += 1
+        directory = _top_directory(path, root) if path else None
+        if directory:
+            directories[directory] += 1
+        if result.get("connection_id"):
+            connections[result["connection_id"]] += 1
+
+    prefix = f"{root.strip('/')}/" if root.strip("/") else ""
+    facets = {
+        "language": _values(languages, max_values, "languages", lambda v: v),
+        "directory": _values(directories, max_values, "paths", lambda v: f"{prefix}{v}/**"),
+        "connection": _values(connections, max_values, "connections", lambda v: v),
+    }
+    return {name: values for name, values in facets.items() if values}
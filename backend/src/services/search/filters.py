@@ -0,0 +1,306 @@
+"""
+Search Filters.
+
+Restricts results by chunk payload on top of the store (org) filter.
+Filters come from request fields or the inline query syntax
+(``lang:go path:cmd/ "exact phrase" -vendor``, see ``query_syntax``):
+
+- ``symbol:ParseConfig``: chunks defining the symbol (exact match against
+  the AST ``symbols`` payload; methods are ``Class.method``)
+- Path globs to include/exclude (``src/**``, ``**/test/**``, ``*.go``)
+- File extensions (``go``, ``.py``)
+- Modified since: chunks indexed at or after a time
+- Content languages: docs chunks written in one of these human languages
+  (``content_language`` payload, ISO 639-1 such as ``en``, ``de``, ``ja``)
+- Languages: chunks of files in one of these programming languages
+  (``language`` payload, e.g. ``python``, ``go``)
+- Connections: chunks uploaded by one of these CLI connections
+  (``connection_id`` payload)
+- Phrases: chunks whose text contains every phrase (case-insensitive)
+- Excluded terms: chunks whose path or text contains one of these words
+
+Globs are matched against the end of ``full_path`` at a directory boundary,
+so ``src/**`` matches ``/home/me/repo/src/main.go``. ``**`` crosses
+directories, ``*`` and ``?`` do not.
+
+Qdrant retrievers apply the filter natively. Not every glob has a Qdrant
+equivalent: directory globs (``**/test/**``) and extension globs
+(``**/*.go``) become conditions on the keyword-indexed ``path_dirs`` and
+``extension`` fields, and chunks indexed before those fields existed pass
+the Qdrant stage. Every result is then checked with ``SearchFilters.matches``,
+which also covers retrievers that look chunks up outside Qdrant (Tantivy
+BM25, the BM25 sparse index). Phrases and excluded terms are only checked
+there; chunks whose text isn't in the index (content store, privacy mode)
+are matched on their path alone.
+"""
+
+import re
+from dataclasses import dataclass, field, replace
+from datetime import datetime
+from functools import lru_cache
+from typing import Any, Dict, List, Optional, Tuple, Union
+
+from qdrant_client.models import (
+    Filter,
+    FieldCondition,
+    IsEmptyCondition,
+    MatchAny,
+    MatchValue,
+    PayloadField,
+    Range,
+)
+
+from src.db.content_store import payload_text
+
+_EXTENSION_GLOB = re.compile(r"^(?:\*\*/)?\*(\.[\w.+-]+)$")
+_DIRECTORY_GLOB = re.compile(r"^\*\*/([^*?\[\]/]+)/\*\*$")
+
+
+def normalize_path(path: str) -> str:
+    return path.replace("\\", "/")
+
+
+def normalize_extension(ext: str) -> str:
+    ext = ext.strip().lower()
+    return ext if ext.startswith(".") else f".{ext}"
+
+
+def path_fields(display_path: str) -> Dict[str, Any]:
+    """Payload fields the index pipeline stores for path filtering."""
+    parts = [p for p in normalize_path(display_path).split("/") if p]
+    name = parts[-1] if parts else ""
+    ext = name.rsplit(".", 1)[1].lower() if "." in name.lstrip(".") else ""
+    return {
+        "extension": f".{ext}" if ext else "",
+        "path_dirs": parts[:-1],
+    }
+
+
+@lru_cache(maxsize=256)
+def glob_regex(pattern: str) -> re.Pattern:
+    """Compile a path glob (``**`` crosses directories) anchored at a directory boundary."""
+    pattern = normalize_path(pattern).strip("/")
+    out = []
+    i = 0
+    while i < len(pattern):
+        if pattern.startswith("**/", i):
+            out.append("(?:.*/)?")
+            i += 3
+        elif pattern.startswith("**", i):
+            out.append(".*")
+            i += 2
+        elif pattern[i] == "*":
+            out.append("[^/]*")
+            i += 1
+        elif pattern[i] == "?":
+            out.append("[^/]")
+            i += 1
+        else:
+            out.append(re.escape(pattern[i]))
+            i += 1
+    return re.compile(r"(?:^|/)" + "".join(out) + r"$")
+
+
+def glob_match(path: str, pattern: str) -> bool:
+    return bool(glob_regex(pattern).search(normalize_path(path)))
+
+
+def parse_time(value: Union[str, float, int, None]) -> Optional[float]:
+    """Epoch seconds from a number or ISO 8601 string."""
+    if value is None or value == "":
+        return None
+    if isinstance(value, (int, float)):
+        return float(value)
+    try:
+        return float(value)
+    except ValueError:
+        return datetime.fromisoformat(value.replace("Z", "+00:00")).timestamp()
+
+
+@lru_cache(maxsize=256)
+def _term_regex(term: str) -> re.Pattern:
+    """Whole-word, case-insensitive match (``-vendor`` skips ``vendorID``)."""
+    return re.compile(r"(?<![\w])" + re.escape(term) + r"(?![\w])", re.IGNORECASE)
+
+
+def _field_or_missing(key: str, values: List[str]) -> Filter:
+    """Match any value, or let through chunks indexed before ``key`` existed."""
+    return Filter(should=[
+        FieldCondition(key=key, match=MatchAny(any=values)),
+        IsEmptyCondition(is_empty=PayloadField(key=key)),
+    ])
+
+
+@dataclass
+class SearchFilters:
+    """Payload filters for a search."""
+
+    symbols: List[str] = field(default_factory=list)
+    # Path globs; a chunk must match one include (if any) and no exclude
+    include_paths: List[str] = field(default_factory=list)
+    exclude_paths: List[str] = field(default_factory=list)
+    extensions: List[str] = field(default_factory=list)
+    # Epoch seconds
+    modified_since: Optional[float] = None
+    # ISO 639-1 codes; only docs chunks carry a content language
+    content_languages: List[str] = field(default_factory=list)
+    # Programming languages as detected at index time ("python", "go")
+    languages: List[str] = field(default_factory=list)
+    # Uploading CLI connections
+    connections: List[str] = field(default_factory=list)
+    # Text the chunk must contain / words that drop it (path or text)
+    phrases: List[str] = field(default_factory=list)
+    exclude_terms: List[str] = field(default_factory=list)
+
+    def __post_init__(self):
+        self.extensions = [normalize_extension(e) for e in self.extensions if e.strip()]
+        self.content_languages = [c.strip().lower() for c in self.content_languages if c.strip()]
+        self.languages = [l.strip().lower() for l in self.languages if l.strip()]
+        self.connections = [c.strip() for c in self.connections if c.strip()]
+        self.phrases = [p for p in self.phrases if p.strip()]
+        self.exclude_terms = [t.strip() for t in self.exclude_terms if t.strip()]
+
+    def is_empty(self) -> bool:
+        return not (
+            self.symbols or self.include_paths or self.exclude_paths
+            or self.extensions or self.modified_since is not None
+            or self.content_languages or self.languages or self.connections
+            or self.phrases or self.exclude_terms
+        )
+
+    def conditions(self) -> List[Any]:
+        """Qdrant conditions (all must hold)."""
+        conditions: List[Any] = []
+        if self.symbols:
+            conditions.append(FieldCondition(key="symbols", match=MatchAny(any=self.symbols)))
+        if self.extensions:
+            conditions.append(_field_or_missing("extension", self.extensions))
+        if self.modified_since is not None:
+            conditions.append(FieldCondition(key="indexed_at", range=Range(gte=self.modified_since)))
+        if self.content_languages:
+            conditions.append(FieldCondition(key="content_language", match=MatchAny(any=self.content_languages)))
+        if self.languages:
+            conditions.append(FieldCondition(key="language", match=MatchAny(any=self.languages)))
+        if self.connections:
+            conditions.append(FieldCondition(key="connection_id", match=MatchAny(any=self.connections)))
+
+        # Includes only narrow the Qdrant stage if every glob translates
+        include = [self._translate(p) for p in self.include_paths]
+        if include and all(include):
+            extensions = [v for k, v in include if k == "extension"]
+            dirs = [v for k, v in include if k == "path_dirs"]
+            should = []
+            if extensions:
+                should.append(_field_or_missing("extension", extensions))
+            if dirs:
+                should.append(_field_or_missing("path_dirs", dirs))
+            conditions.append(Filter(should=should))
+        return conditions
+
+    def must_not(self) -> List[FieldCondition]:
+        """Qdrant conditions that exclude chunks."""
+        must_not = []
+        for pattern in self.exclude_paths:
+            translated = self._translate(pattern)
+            if translated:
+                key, value = translated
+                must_not.append(FieldCondition(key=key, match=MatchValue(value=value)))
+        for term in self.exclude_terms:
+            # A directory with that name always contains the term
+            if "/" not in term and " " not in term:
+                must_not.append(FieldCondition(key="path_dirs", match=MatchValue(value=term)))
+        return must_not
+
+    @staticmethod
+    def _translate(pattern: str) -> Optional[Tuple[str, str]]:
+        """Payload equivalent of an extension or directory glob."""
+        pattern = normalize_path(pattern).strip("/")
+        ext = _EXTENSION_GLOB.match(pattern)
+        if ext:
+            return "extension", ext.group(1).lower()
+        directory = _DIRECTORY_GLOB.match(pattern)
+        if directory:
+            return "path_dirs", directory.group(1)
+        return None
+
+    def matches(self, payload: Dict[str, Any]) -> bool:
+        """Whether a chunk payload passes the filters."""
+        if self.symbols and not set(self.symbols) & set(payload.get("symbols") or []):
+            return False
+
+        path = payload.get("full_path") or payload.get("file_path") or ""
+        if self.include_paths and not any(glob_match(path, p) for p in self.include_paths):
+            return False
+        if any(glob_match(path, p) for p in self.exclude_paths):
+            return False
+        if self.extensions and path_fields(path)["extension"] not in self.extensions:
+            return False
+
+        if self.modified_since is not None:
+            indexed_at = payload.get("indexed_at")
+            if indexed_at is None or float(indexed_at) < self.modified_since:
+                return False
+
+        if self.content_languages and payload.get("content_language") not in self.content_languages:
+            return False
+        if self.languages and (payload.get("language") or "").lower() not in self.languages:
+            return False
+        if self.connections and payload.get("connection_id") not in self.connections:
+            return False
+
+        if not self.phrases and not self.exclude_terms:
+            return True
+        # Text may live in the content store; without any (privacy mode) a phrase can't match
+        text = payload_text(payload).lower()
+        if self.phrases and not (text and all(p.lower() in text for p in self.phrases)):
+            return False
+        if any(_term_regex(t).search(path) or _term_regex(t).search(text) for t in self.exclude_terms):
+            return False
+        return True
+
+    def to_dict(self) -> Dict[str, Any]:
+        result = {
+            "symbols": self.symbols,
+            "include_paths": self.include_paths,
+            "exclude_paths": self.exclude_paths,
+            "extensions": self.extensions,
+            "modified_since": self.modified_since,
+            "content_languages": self.content_languages,
+            "languages": self.languages,
+            "connections": self.connections,
+            "phrases": self.phrases,
+            "exclude_terms": self.exclude_terms,
+        }
+        return {k: v for k, v in result.items() if v}
+
+
+def parse_query(query: str, filters: Optional[SearchFilters] = None) -> Tuple[str, SearchFilters]:
+    """
+    Pull inline filter tokens out of a query.
+
+    Args:
+        query: Raw query text, e.g. ``"config loading lang:go symbol:ParseConfig"``
+        filters: Filters from request fields (not modified)
+
+    Returns:
+        (semantic query text, merged filters); see ``query_syntax.parse``
+        for how each token was read.
+    """
+    from src.services.search.query_syntax import parse
+
+    parsed = parse(query, filters)
+    return parsed.text, parsed.filters
+
+
+def build_filter(org_id: Optional[str], filters: Optional[SearchFilters] = None) -> Optional[Filter]:
+    """Qdrant filter for a store plus payload filters (None if unrestricted)."""
+    must = []
+    must_not = []
+    if org_id and org_id != "public":
+        must.append(FieldCondition(key="org_id", match=MatchValue(value=org_id)))
+    if filters:
+        must.extend(filters.conditions())
+        must_not.extend(filters.must_not())
+    if not must and not must_not:
+        return None
+    return Filter(must=must, must_not=must_not or None)
@@ -0,0 +1,305 @@
+"""
+Fusion Weight Tuning.
+
+Search responses carry a ``query_id``. Clients report which results were
+clicked or ignored via ``POST /api/v1/search/feedback``; each event credits
+the retriever groups that surfaced the result:
+
+- ``sparse``: BM25, SPLADE and the BM25 sparse backend
+- ``dense``: BM42 (dense + sparse hybrid) and docstring vectors
+
+Clicks further down the list count more, since they mean the fused ranking
+put a wanted result too low. A periodic tuner moves each store's
+sparse/dense weights toward the groups' click-through ratio. The weights
+scale each retriever's RRF contribution at search time.
+
+The same pass tunes the store's diversity lambda (see ``diversify``) from
+the mean click rank: users clicking deep into the list are skipping near
+duplicates at the top, so the lambda moves toward ``min_lambda`` and
+results spread over more files.
+"""
+
+import json
+import math
+import time
+import uuid
+import logging
+from typing import Dict, List, Optional, Any
+
+import redis
+
+from src.core.config import settings
+
+logger = logging.getLogger(__name__)
+
+SPARSE = "sparse"
+DENSE = "dense"
+GROUPS = {"bm25": SPARSE, "splade": SPARSE, "bm25_sparse": SPARSE, "bm42": DENSE}
+# Docstring vectors are weighted through bm42 (see doc_vectors) but earn dense credit
+CREDITED = {**GROUPS, "doc": DENSE}
+
+CLICK = "click"
+IGNORE = "ignore"
+
+DIVERSITY = "diversity_lambda"
+
+
+def default_diversity_lambda() -> float:
+    """Diversity lambda of untuned stores (1.0: fused order)."""
+    return float(settings.get("search.diversity.lambda", 1.0))
+
+
+def click_credit(rank: int) -> float:
+    """Weight of a click at a 0-based rank (deeper clicks count more)."""
+    return math.log2(2 + rank)
+
+
+def target_sparse_share(stats: Dict[str, float]) -> Optional[float]:
+    """
+    Sparse share of the total weight implied by feedback stats.
+
+    Returns:
+        Share in [0, 1], or None if either group has no feedback
+    """
+    rates = {}
+    for group in (SPARSE, DENSE):
+        clicks = float(stats.get(f"{group}_clicks", 0))
+        ignores = float(stats.get(f"{group}_ignores", 0))
+        if clicks + ignores <= 0:
+            return None
+        rates[group] = clicks / (clicks + ignores)
+    total = rates[SPARSE] + rates[DENSE]
+    return rates[SPARSE] / total if total else 0.5
+
+
+def target_diversity_lambda(stats: Dict[str, float], bounds: tuple, deep_rank: float) -> Optional[float]:
+    """
+    Diversity lambda implied by the mean click rank.
+
+    Top clicks keep the upper bound; a mean rank of ``deep_rank`` or more
+    reaches the lower one.
+
+    Returns:
+        Lambda within bounds, or None without clicks
+    """
+    clicks = float(stats.get("click_count", 0))
+    if clicks <= 0:
+        return None
+    low, high = bounds
+    depth = min(1.0, float(stats.get("click_rank_sum", 0)) / clicks / max(deep_rank, 1.0))
+    return high - (high - low) * depth
+
+
+class FusionTuner:
+    """Records search impressions/feedback and tunes per-store fusion weights."""
+
+    QUERY_KEY = "rice:feedback:query"
+    STATS_KEY = "rice:feedback:stats"
+    WEIGHTS_KEY = "rice:fusion:weights"
+
+    def __init__(self, redis_client=None):
+        self._redis = redis_client
+
+    @property
+    def redis(self) -> redis.Redis:
+        """Lazy Redis connection."""
+        if self._redis is None:
+            self._redis = redis.from_url(settings.REDIS_URL, decode_responses=True)
+        return self._redis
+
+    @property
+    def enabled(self) -> bool:
+        return bool(settings.get("search.fusion_tuning.enabled", False))
+
+    @property
+    def min_feedback(self) -> int:
+        return int(settings.get("search.fusion_tuning.min_feedback", 50))
+
+    @property
+    def learning_rate(self) -> float:
+        return float(settings.get("search.fusion_tuning.learning_rate", 0.2))
+
+    @property
+    def share_bounds(self) -> tuple:
+        return (
+            float(settings.get("search.fusion_tuning.min_share", 0.2)),
+            float(settings.get("search.fusion_tuning.max_share", 0.8)),
+        )
+
+    @property
+    def lambda_bounds(self) -> tuple:
+        return (
+            float(settings.get("search.fusion_tuning.min_lambda", 0.5)),
+            float(settings.get("search.fusion_tuning.max_lambda", 1.0)),
+        )
+
+    @property
+    def deep_click_rank(self) -> float:
+        return float(settings.get("search.fusion_tuning.deep_click_rank", 10))
+
+    # ============== Feedback ==============
+
+    def record_impression(self, org_id: str, results: List[Dict]) -> str:
+        """
+        Remember what a search returned so later feedback can be attributed.
+
+        Returns:
+            query_id to hand back to the client
+        """
+        query_id = uuid.uuid4().hex
+        impression = {
+            "org_id": org_id,
+            "results": {
+                r["chunk_id"]: {"rank": rank, "retrievers": sorted((r.get("retriever_scores") or {}).keys())}
+                for rank, r in enumerate(results)
+                if r.get("chunk_id")
+            },
+        }
+        ttl = int(settings.get("search.fusion_tuning.impression_ttl_seconds", 86400))
+        try:
+            self.redis.setex(f"{self.QUERY_KEY}:{query_id}", ttl, json.dumps(impression))
+        except Exception as e:
+            logger.warning(f"Failed to record search impression: {e}")
+        return query_id
+
+    def record_feedback(self, query_id: str, result_id: str, action: str) -> Optional[Dict[str, Any]]:
+        """
+        Apply a click/ignore event to the store's feedback stats.
+
+        Returns:
+            Dict with org_id, rank and credited groups, or None if the
+            query or result is unknown (expired or never returned)
+        """
+        data = self.redis.get(f"{self.QUERY_KEY}:{query_id}")
+        if not data:
+            return None
+        impression = json.loads(data)
+        result = impression["results"].get(result_id)
+        if result is None:
+            return None
+
+        org_id = impression["org_id"]
+        groups = sorted({CREDITED[r] for r in result["retrievers"] if r in CREDITED})
+        amount = click_credit(result["rank"]) if action == CLICK else 1.0
+        field = "clicks" if action == CLICK else "ignores"
+
+        # Float increments throughout: tuning halves the counters
+        key = f"{self.STATS_KEY}:{org_id}"
+        for group in groups:
+            # Split credit when both groups found the result
+            self.redis.hincrbyfloat(key, f"{group}_{field}", amount / len(groups))
+        self.redis.hincrbyfloat(key, "events", 1)
+        if action == CLICK:
+            self.redis.hincrbyfloat(key, "click_rank_sum", result["rank"])
+            self.redis.hincrbyfloat(key, "click_count", 1)
+
+        return {"org_id": org_id, "rank": result["rank"], "groups": groups}
+
+    def get_stats(self, org_id: str) -> Dict[str, float]:
+        try:
+            return {k: float(v) for k, v in (self.redis.hgetall(f"{self.STATS_KEY}:{org_id}") or {}).items()}
+        except Exception as e:
+            logger.warning(f"Failed to read feedback stats: {e}")
+            return {}
+
+    # ============== Weights ==============
+
+    def get_weights(self, org_id: str, default: Optional[Dict[str, float]] = None) -> Dict[str, float]:
+        """
+        Per-retriever RRF weights for a store.
+
+        Until tuned, falls back to ``default`` (per-retriever weights, e.g. the
+        store's applied search config) or 1.0 each.
+        """
+        default = default or {}
+        defaults = {
+            SPARSE: float(default.get("bm25", 1.0)),
+            DENSE: float(default.get("bm42", 1.0)),
+        }
+        try:
+            data = self.redis.hgetall(f"{self.WEIGHTS_KEY}:{org_id}") or {}
+            groups = {g: float(data.get(g, defaults[g])) for g in defaults}
+        except Exception:
+            groups = defaults
+        return {retriever: groups[group] for retriever, group in GROUPS.items()}
+
+    def get_diversity_lambda(self, org_id: str) -> float:
+        """A store's tuned diversity lambda, else the configured default."""
+        try:
+            value = self.redis.hget(f"{self.WEIGHTS_KEY}:{org_id}", DIVERSITY)
+        except Exception:
+            value = None
+        return float(value) if value is not None else default_diversity_lambda()
+
+    def set_weights(self, org_id: str, sparse: float, dense: float):
+        """Set a store's group weights directly (e.g. from the tuning assistant)."""
+        self.redis.hset(
+            f"{self.WEIGHTS_KEY}:{org_id}",
+            mapping={SPARSE: sparse, DENSE: dense, "tuned_at": time.time()}
+        )
+
+    def tune(self, org_id: str) -> Optional[Dict[str, Any]]:
+        """
+        Move a store's sparse/dense weights and diversity lambda toward the
+        feedback targets.
+
+        Weights always sum to 2.0 so an untuned store (1.0/1.0) is unchanged.
+        Stats are halved afterwards so recent feedback dominates.
+
+        Returns:
+            New weights, or None if there is not enough feedback yet
+        """
+        stats = self.get_stats(org_id)
+        if stats.get("events", 0) < self.min_feedback:
+            return None
+        target = target_sparse_share(stats)
+        if target is None:
+            return None
+
+        current = self.get_weights(org_id)["bm25"] / 2.0
+        low, high = self.share_bounds
+        share = current + self.learning_rate * (target - current)
+        share = min(high, max(low, share))
+
+        weights = {
+            SPARSE: round(2.0 * share, 4),
+            DENSE: round(2.0 * (1.0 - share), 4),
+            "tuned_at": time.time(),
+        }
+        target_lambda = target_diversity_lambda(stats, self.lambda_bounds, self.deep_click_rank)
+        if target_lambda is not None:
+            current_lambda = self.get_diversity_lambda(org_id)
+            low, high = self.lambda_bounds
+            diversity = current_lambda + self.learning_rate * (target_lambda - current_lambda)
+            weights[DIVERSITY] = round(min(high, max(low, diversity)), 4)
+        key = f"{self.STATS_KEY}:{org_id}"
+        self.redis.hset(f"{self.WEIGHTS_KEY}:{org_id}", mapping=weights)
+        self.redis.hset(key, mapping={k: v / 2.0 for k, v in stats.items()})
+
+        clicks = stats.get("click_count", 0)
+        weights["mean_click_rank"] = round(stats.get("click_rank_sum", 0) / clicks, 2) if clicks else None
+        logger.info(f"Tuned fusion weights for {org_id}: {weights}")
+        return weights
+
+    def tune_all(self) -> Dict[str, Any]:
+        """Tune every store that has feedback."""
+        results = {}
+        for key in self.redis.scan_iter(match=f"{self.STATS_KEY}:*"):
+            org_id = key[len(self.STATS_KEY) + 1:]
+            results[org_id] = self.tune(org_id)
+        return results
+
+    def reset(self, org_id: str):
+        """Drop tuned weights and feedback for a store."""
+        self.redis.delete(f"{self.WEIGHTS_KEY}:{org_id}", f"{self.STATS_KEY}:{org_id}")
+
+
+# Singleton instance
+_fusion_tuner: Optional[FusionTuner] = None
+
+def get_fusion_tuner() -> FusionTuner:
+    """Get global fusion tuner instance."""
+    global _fusion_tuner
+    if _fusion_tuner is None:
+        _fusion_tuner = FusionTuner()
+    return _fusion_tuner
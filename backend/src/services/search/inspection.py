@@ -0,0 +1,255 @@
+"""
+Index and Query Inspection.
+
+Debugging views behind ``rice-search inspect``:
+
+- File: a file's chunks as indexed (boundaries, symbols, token estimates,
+  vector sizes), to spot bad chunking or empty vectors
+- Query: how a query was parsed and analyzed, how long each encoder took,
+  and the raw candidates each retriever returned before fusion and
+  reranking
+
+Both read the index directly and bypass the query cache.
+"""
+
+import asyncio
+import logging
+import math
+import time
+from typing import Any, Dict, List, Optional
+
+from qdrant_client.models import FieldCondition, Filter, MatchValue
+
+from src.db.content_store import hydrate
+from src.db.qdrant import get_qdrant_client
+from src.services.inference.openai_compat import estimate_tokens
+from src.services.ingestion.migration import store_collection
+from src.services.search.filters import SearchFilters, build_filter
+from src.services.search.query_syntax import parse as parse_query_syntax
+
+logger = logging.getLogger(__name__)
+
+# Chunks listed per file before truncating
+MAX_FILE_CHUNKS = 1000
+
+
+def vector_stats(vector: Any) -> Optional[Dict[str, Any]]:
+    """Size and L2 norm of a dense (list) or sparse (indices/values) vector."""
+    if vector is None:
+        return None
+    if isinstance(vector, dict):
+        values = vector.get("values") or []
+        return {"nnz": len(values), "norm": round(math.sqrt(sum(v * v for v in values)), 4)}
+    if hasattr(vector, "values") and hasattr(vector, "indices"):
+        values = list(vector.values)
+        return {"nnz": len(values), "norm": round(math.sqrt(sum(v * v for v in values)), 4)}
+    values = list(vector)
+    return {"dim": len(values), "norm": round(math.sqrt(sum(v * v for v in values)), 4)}
+
+
+def _candidate(rank: int, result: Dict[str, Any]) -> Dict[str, Any]:
+    return {
+        "rank": rank,
+        "chunk_id": result.get("chunk_id"),
+        "score": result.get("score"),
+        "path": result.get("full_path") or result.get("file_path"),
+        "start_line": result.get("start_line"),
+        "end_line": result.get("end_line"),
+    }
+
+
+class SearchInspector:
+    """Builds the file and query debugging views."""
+
+    def __init__(self, qdrant=None, retriever=None):
+        self._qdrant = qdrant
+        self._retriever = retriever
+
+    @property
+    def qdrant(self):
+        if self._qdrant is None:
+            self._qdrant = get_qdrant_client()
+        return self._qdrant
+
+    @property
+    def retriever(self):
+        if self._retriever is None:
+            from src.services.search.retriever import Retriever
+            self._retriever = Retriever.get_multi_retriever()
+        return self._retriever
+
+    def inspect_file(self, org_id: str, path: str) -> Dict[str, Any]:
+        """
+        A file's chunks in order.
+
+        Returns:
+            Dict with the file's ``chunks`` (lines, type, symbols, chars,
+            token estimate, per-vector size and norm) and totals
+        """
+        scroll_filter = Filter(must=[
+            FieldCondition(key="org_id", match=MatchValue(value=org_id)),
+            FieldCondition(key="full_path", match=MatchValue(value=path)),
+        ])
+        points, offset = [], None
+        while len(points) < MAX_FILE_CHUNKS:
+            batch, offset = self.qdrant.scroll(
+                collection_name=store_collection(org_id),
+                scroll_filter=scroll_filter,
+                limit=256,
+                offset=offset,
+                with_payload=True,
+                with_vectors=True,
+            )
+            points.extend(batch)
+            if offset is None:
+                break
+
+        payloads = hydrate([{**(p.payload or {}), "chunk_id": str(p.id)} for p in points])
+        chunks = []
+        for point, payload in zip(points, payloads):
+            text = payload.get("text") or ""
+            vectors = point.vector if isinstance(point.vector, dict) else {"dense": point.vector}
+            chunks.append({
+                "chunk_id": payload["chunk_id"],
+                "chunk_index": payload.get("chunk_index"),
+                "start_line": payload.get("start_line"),
+                "end_line": payload.get("end_line"),
+                "chunk_type": payload.get("chunk_type"),
+                "symbols": payload.get("symbols") or [],
+                "chars": len(text) or payload.get("content_length") or 0,
+                "tokens": estimate_tokens(text) if text else None,
+                "vectors": {name: vector_stats(v) for name, v in (vectors or {}).items()},
+            })
+        chunks.sort(key=lambda c: (c["start_line"] or 0, c["chunk_index"] or 0))
+
+        first = payloads[0] if payloads else {}
+        return {
+            "store": org_id,
+            "path": path,
+            "language": first.get("language"),
+            "file_hash": first.get("file_hash"),
+            "connection_id": first.get("connection_id"),
+            "indexed_at": first.get("indexed_at"),
+            "chunk_count": len(chunks),
+            "truncated": len(points) >= MAX_FILE_CHUNKS and offset is not None,
+            "tokens": sum(c["tokens"] or 0 for c in chunks),
+            "chunks": chunks,
+        }
+
+    async def inspect_query(
+        self,
+        query: str,
+        org_id: str,
+        limit: int = 10,
+        filters: Optional[SearchFilters] = None,
+        force_heuristic: bool = False,
+    ) -> Dict[str, Any]:
+        """
+        Parsed query, analysis, encoder timings and raw per-retriever candidates.
+
+        Every retriever runs (failures are reported per retriever), and
+        ``dense`` is queried alone as well as inside BM42's hybrid query.
+        """
+        from src.services.retrieval.analyzer import analyze
+        from src.services.retrieval.bm25_index import BM25, get_store_sparse_backend
+        from src.services.search.query_analyzer import analyze_for_search
+        from src.services.search.retriever import embed_texts_async
+
+        parsed = parse_query_syntax(query, filters)
+        text, filters = parsed.text, parsed.filters
+        report: Dict[str, Any] = {
+            "store": org_id,
+            "query": query,
+            "parsed": parsed.to_dict(),
+            "analysis": None,
+            "timings_ms": {},
+            "candidates": {},
+            "errors": {},
+        }
+
+        processed = text
+        started = time.perf_counter()
+        try:
+            analysis = await analyze_for_search(text, force_heuristic)
+            processed = analysis.processed_query
+            report["analysis"] = analysis.to_metadata()
+        except Exception as e:
+            report["errors"]["analysis"] = str(e)
+        report["timings_ms"]["analysis"] = self._elapsed(started)
+        report["processed_query"] = processed
+        report["sparse_text"] = analyze(processed, query=True)
+
+        retriever = self.retriever
+        encoded: Dict[str, Any] = {}
+        encoders = {
+            "dense": lambda: embed_texts_async([processed]),
+            "bm42": lambda: asyncio.to_thread(retriever.bm42_encoder.encode_single, report["sparse_text"]),
+        }
+        sparse_backend = get_store_sparse_backend(org_id)
+        if sparse_backend != BM25:
+            encoders["splade"] = lambda: asyncio.to_thread(retriever.splade_encoder.encode_single, report["sparse_text"])
+        for name, encode in encoders.items():
+            started = time.perf_counter()
+            try:
+                vector = await encode()
+                encoded[name] = vector[0] if name == "dense" else vector
+                stats = vector_stats(encoded[name])
+                report.setdefault("query_vectors", {})[name] = stats
+            except Exception as e:
+                report["errors"][f"encode_{name}"] = str(e)
+            report["timings_ms"][f"encode_{name}"] = self._elapsed(started)
+
+        qdrant = self.qdrant
+        collection = store_collection(org_id)
+        search_filter = build_filter(org_id, filters)
+        searches = {"bm25": lambda: retriever._search_bm25(processed, limit, collection)}
+        if sparse_backend == BM25:
+            searches["bm25_sparse"] = lambda: retriever._search_bm25_sparse(processed, qdrant, limit, org_id)
+        elif "splade" in encoded:
+            searches["splade"] = lambda: retriever._search_splade(
+                processed, qdrant, limit, search_filter, collection, encoded
+            )
+        if "dense" in encoded:
+            searches["dense"] = lambda: self._search_dense(encoded["dense"], limit, search_filter, collection)
+            if "bm42" in encoded:
+                searches["bm42"] = lambda: retriever._search_bm42(
+                    processed, qdrant, limit, search_filter, collection, encoded
+                )
+        for name, search in searches.items():
+            started = time.perf_counter()
+            try:
+                results = await search()
+                if filters and not filters.is_empty():
+                    results = [r for r in results if filters.matches(r)]
+                report["candidates"][name] = [_candidate(i, r) for i, r in enumerate(results[:limit], start=1)]
+            except Exception as e:
+                report["errors"][name] = str(e)
+            report["timings_ms"][name] = self._elapsed(started)
+        return report
+
+    async def _search_dense(self, vector: List[float], limit: int, search_filter, collection: str) -> List[Dict]:
+        response = await asyncio.to_thread(
+            self.qdrant.query_points,
+            collection_name=collection,
+            query=vector,
+            using="dense",
+            limit=limit,
+            query_filter=search_filter,
+            with_payload=True,
+        )
+        return [{"chunk_id": str(p.id), "score": p.score, **(p.payload or {})} for p in response.points]
+
+    @staticmethod
+    def _elapsed(started: float) -> float:
+        return round((time.perf_counter() - started) * 1000, 1)
+
+
+# Singleton instance
+_inspector: Optional[SearchInspector] = None
+
+def get_search_inspector() -> SearchInspector:
+    """Get global search inspector instance."""
+    global _inspector
+    if _inspector is None:
+        _inspector = SearchInspector()
+    return _inspector
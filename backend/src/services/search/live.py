@@ -0,0 +1,105 @@
+"""
+Live search (search as you type).
+
+A ``LiveSearchSession`` serves one WebSocket connection. Every message
+carries the query as typed so far and a client sequence number (``seq``).
+The session waits ``search.live.debounce_ms`` for typing to pause, cancels
+the search still running for an older query, and sends each result tagged
+with the ``seq`` it answers so the client can drop anything stale. A
+cancelled search may still finish in its worker thread; its results are
+discarded.
+"""
+
+import asyncio
+import logging
+import time
+from typing import Any, Awaitable, Callable, Dict, Optional
+
+from fastapi import HTTPException
+
+from src.core.config import settings
+
+logger = logging.getLogger(__name__)
+
+SearchFn = Callable[[Dict[str, Any]], Awaitable[Dict[str, Any]]]
+SendFn = Callable[[Dict[str, Any]], Awaitable[None]]
+
+
+class LiveSearchSession:
+    """Debounced, cancel-on-supersede searches for one connection."""
+
+    def __init__(
+        self,
+        search: SearchFn,
+        send: SendFn,
+        debounce_ms: Optional[float] = None,
+        min_chars: Optional[int] = None,
+    ):
+        self.search = search
+        self.send = send
+        self.debounce = (
+            debounce_ms if debounce_ms is not None
+            else float(settings.get("search.live.debounce_ms", 150))
+        ) / 1000
+        self.min_chars = min_chars if min_chars is not None else int(settings.get("search.live.min_chars", 2))
+        self._task: Optional[asyncio.Task] = None
+        self.searches = 0
+        self.superseded = 0
+
+    async def submit(self, message: Dict[str, Any]):
+        """Start handling a query, superseding the previous one."""
+        await self._cancel()
+        self._task = asyncio.create_task(self._run(message))
+
+    async def close(self):
+        """Cancel pending work (connection closed)."""
+        await self._cancel()
+
+    async def _cancel(self):
+        task, self._task = self._task, None
+        if task is None or task.done():
+            return
+        task.cancel()
+        self.superseded += 1
+        try:
+            await task
+        except asyncio.CancelledError:
+            pass
+        except Exception as e:
+            logger.debug(f"Superseded live search failed: {e}")
+
+    async def _run(self, message: Dict[str, Any]):
+        seq = message.get("seq")
+        query = str(message.get("query") or "").strip()
+
+        await asyncio.sleep(self.debounce)
+        if len(query) < self.min_chars:
+            await self._reply({"type": "results", "seq": seq, "query": query, "results": []})
+            return
+
+        started = time.perf_counter()
+        self.searches += 1
+        try:
+            response = await self.search({**message, "query": query})
+        except asyncio.CancelledError:
+            raise
+        except HTTPException as e:
+            await self._reply({"type": "error", "seq": seq, "status": e.status_code, "detail": e.detail})
+            return
+        except Exception as e:
+            logger.warning(f"Live search failed: {e}")
+            await self._reply({"type": "error", "seq": seq, "status": 500, "detail": str(e)})
+            return
+
+        await self._reply({
+            "type": "results",
+            "seq": seq,
+            "query": query,
+            "took_ms": round((time.perf_counter() - started) * 1000, 1),
+            **response,
+        })
+
+    async def _reply(self, payload: Dict[str, Any]):
+        # A message half-written to the socket would break the connection,
+        # so a reply in progress finishes even if a newer query cancels us
+        await asyncio.shield(self.send(payload))
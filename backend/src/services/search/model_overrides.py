@@ -0,0 +1,139 @@
+"""
+Per-Request Model Overrides.
+
+Searches normally embed the query with the model the store's vectors were
+built with and rerank with the configured reranker. Requests can pick
+another model for one query (``model_id``, ``rerank_model_id``) to compare
+models on real queries without changing the store.
+
+An embedding model is only usable if the store has vectors built with it;
+query vectors from any other model are meaningless against the index:
+
+- the store's own model (``dense`` in the store's collection)
+- the variant B model of a running embedding experiment (``dense_b`` in
+  the experiment collection)
+
+Rerankers work on chunk text, so any reranker the server knows can be
+picked: the configured one, those in the model registry and a rerank
+experiment's variant B.
+
+Models are named by registry ID (``baai-bge-reranker-base``) or model
+name (``BAAI/bge-reranker-base``); unknown or unindexed models raise
+``ValueError`` listing the valid choices.
+"""
+
+import logging
+from typing import Any, Dict, List, Optional
+
+from src.core.config import settings
+
+logger = logging.getLogger(__name__)
+
+
+def model_slug(name: str) -> str:
+    """Registry ID of a model name (as the admin model registry derives it)."""
+    return name.replace("/", "-").lower()
+
+
+def _matches(model_id: str, name: str) -> bool:
+    return model_id.lower() in (name.lower(), model_slug(name))
+
+
+def _store(store_id: str) -> Dict[str, Any]:
+    from src.services.admin.admin_store import get_admin_store
+    return get_admin_store().get_stores().get(store_id) or {}
+
+
+def indexed_models(store_id: str) -> Dict[str, Dict[str, Any]]:
+    """Embedding models with vectors in a store -> {"collection", "vector", "source"}."""
+    from src.services.ingestion.migration import configured_model, store_collection
+    from src.services.search.experiments import EMBEDDING, VECTOR_B, experiment_collection
+
+    store = _store(store_id)
+    models = {
+        store.get("embedding_model") or configured_model(): {
+            "collection": store_collection(store_id),
+            "vector": "dense",
+            "source": "store",
+        }
+    }
+    experiment = store.get("experiment") or {}
+    if experiment.get("kind") == EMBEDDING and experiment.get("model_b"):
+        models.setdefault(experiment["model_b"], {
+            "collection": experiment_collection(store_id),
+            "vector": VECTOR_B,
+            "source": "experiment",
+        })
+    return models
+
+
+def rerank_models(store_id: str) -> List[str]:
+    """Rerankers a request can pick for a store, the configured one first."""
+    from src.services.search.experiments import RERANK
+
+    models = [settings.RERANK_MODEL]
+    try:
+        from src.services.admin.admin_store import get_admin_store
+        registry = get_admin_store().get_models()
+    except Exception as e:
+        logger.debug(f"Could not read the model registry: {e}")
+        registry = {}
+    models += [m["name"] for m in registry.values() if m.get("type") == "reranker" and m.get("name")]
+    experiment = _store(store_id).get("experiment") or {}
+    if experiment.get("kind") == RERANK and experiment.get("model_b"):
+        models.append(experiment["model_b"])
+    return list(dict.fromkeys(models))
+
+
+def resolve_embedding_model(store_id: str, model_id: Optional[str]) -> Optional[Dict[str, Any]]:
+    """
+    Where to search for a requested embedding model.
+
+    Returns:
+        None for the store's own model (no override), else
+        {"model", "collection", "vector", "source"}
+
+    Raises:
+        ValueError: the store has no vectors from that model
+    """
+    if not model_id:
+        return None
+    models = indexed_models(store_id)
+    for name, target in models.items():
+        if _matches(model_id, name):
+            return None if target["source"] == "store" else {"model": name, **target}
+    raise ValueError(
+        f"Model '{model_id}' is not indexed in store '{store_id}'; "
+        f"available: {', '.join(models)}"
+    )
+
+
+def resolve_rerank_model(store_id: str, model_id: Optional[str]) -> Optional[str]:
+    """
+    Model name of a requested reranker (None for the configured one).
+
+    Raises:
+        ValueError: unknown reranker
+    """
+    if not model_id:
+        return None
+    models = rerank_models(store_id)
+    for name in models:
+        if _matches(model_id, name):
+            return None if name == settings.RERANK_MODEL else name
+    raise ValueError(f"Unknown reranker '{model_id}'; available: {', '.join(models)}")
+
+
+def available_models(store_id: str) -> Dict[str, Any]:
+    """Choices for ``model_id`` and ``rerank_model_id`` on a store."""
+    return {
+        "store": store_id,
+        "embedding": [
+            {"model": name, "id": model_slug(name), "source": target["source"]}
+            for name, target in indexed_models(store_id).items()
+        ],
+        "rerank": [
+            {"model": name, "id": model_slug(name), "default": name == settings.RERANK_MODEL}
+            for name in rerank_models(store_id)
+        ],
+    }
@@ -1,19 +1,41 @@
 """
-Query Analyzer Service - Ollama-based.
+Query Analyzer Service - LLM-backed.
 
-ALL query classification is delegated to Ollama (qwen2.5-coder:1.5b).
-Uses pattern matching for fast analysis, with Ollama for complex queries.
+Uses pattern matching for fast analysis, with an LLM for complex queries.
+The LLM runs through the backend chain in src.services.query.llm_backends
+(Ollama by default, or any OpenAI-compatible server). When no backend
+answers in time, the pattern-based result is used as-is.
 No in-process model loading - backend only orchestrates service calls.
+
+Every analysis records which path served it (``model`` or ``heuristic``)
+and, for the heuristic path, why the model was not used. Requests can
+force the heuristic path, and admins can disable or warm up the query
+model at runtime.
 """
 
 import logging
 import re
+import time
 from typing import Dict, Any, Optional, List
-from dataclasses import dataclass
+from dataclasses import dataclass, field
 from enum import Enum
 
 logger = logging.getLogger(__name__)
 
+# Which path served an analysis
+MODEL = "model"
+HEURISTIC = "heuristic"
+
+# Why the heuristic path served a query
+FALLBACK_REASONS = [
+    "forced",          # Request asked for the heuristic path
+    "disabled",        # Query model turned off at runtime by an admin
+    "not_configured",  # search.query_analysis.use_llm is off
+    "confident",       # Patterns were confident and expansion is off
+    "unavailable",     # No backend answered in time
+    "unrecognized",    # Backend answered with no known intent
+]
+
 
 class QueryIntent(str, Enum):
     """Query intent types."""
@@ -35,6 +57,19 @@ class QueryAnalysis:
     path_hints: List[str]
     symbol_hints: List[str]
     filters: Dict[str, Any]
+    expansions: List[str] = field(default_factory=list)
+    path: str = HEURISTIC
+    fallback_reason: Optional[str] = None
+
+    def to_metadata(self) -> Dict[str, Any]:
+        """Summary for search response metadata."""
+        return {
+            "path": self.path,
+            "fallback_reason": self.fallback_reason,
+            "intent": self.intent.value,
+            "confidence": self.confidence,
+            "expansions": self.expansions,
+        }
 
 
 # Pattern-based hints (fast path)
@@ -70,16 +105,19 @@ LANGUAGE_KEYWORDS = {
 }
 
 
-def analyze_query(query: str, use_llm: bool = False) -> QueryAnalysis:
+def analyze_query(query: str, use_llm: bool = False, force_heuristic: bool = False) -> QueryAnalysis:
     """
     Analyze a search query to extract intent and hints.
     
     Args:
         query: The search query
-        use_llm: If True, use vLLM for complex classification
+        use_llm: If True, use the LLM backends for low-confidence
+            classification and (if enabled) query expansion
+        force_heuristic: Skip the LLM backends even if use_llm is set
         
     Returns:
-        QueryAnalysis with extracted information
+        QueryAnalysis with extracted information; ``path`` says whether
+        the model contributed and ``fallback_reason`` why it did not
     """
     query_lower = query.lower()
     
@@ -112,15 +150,39 @@ def analyze_query(query: str, use_llm: bool = False) -> QueryAnalysis:
     symbol_hints = re.findall(r'\b([a-z]+[A-Z][a-zA-Z]*|[a-z]+_[a-z_]+)\b', query)
     symbol_hints.extend(re.findall(r'\b([A-Z][a-zA-Z]+)\b', query))
     
-    # 5. Optional: Use vLLM for complex classification
-    if use_llm and confidence < 0.7:
-        try:
-            llm_intent = classify_with_llm(query)
-            if llm_intent:
-                intent = llm_intent
-                confidence = 0.9
-        except Exception as e:
-            logger.warning(f"vLLM classification failed: {e}")
+    # 5. Optional: Use LLM backends for complex classification and expansion
+    from src.core.config import settings
+
+    expansions = []
+    path, fallback_reason = HEURISTIC, None
+    if force_heuristic:
+        fallback_reason = "forced"
+    elif use_llm and not query_model_enabled():
+        fallback_reason = "disabled"
+    elif not use_llm:
+        fallback_reason = "not_configured"
+    else:
+        threshold = float(settings.get("search.query_analysis.confidence_threshold", 0.7))
+        expand = settings.get("search.query_analysis.expand", False)
+        fallback_reason = "confident"
+        if confidence < threshold:
+            try:
+                llm_intent = classify_with_llm(query)
+                if llm_intent:
+                    intent = llm_intent
+                    confidence = 0.9
+                    path, fallback_reason = MODEL, None
+                else:
+                    fallback_reason = "unrecognized"
+            except Exception as e:
+                logger.warning(f"LLM classification failed: {e}")
+                fallback_reason = "unavailable"
+        if expand:
+            expansions = expand_with_llm(query)
+            if expansions:
+                path, fallback_reason = MODEL, None
+            elif fallback_reason == "confident":
+                fallback_reason = "unavailable"
     
     # Build filters from hints
     filters = {}
@@ -131,57 +193,158 @@ def analyze_query(query: str, use_llm: bool = False) -> QueryAnalysis:
     
     return QueryAnalysis(
         original_query=query,
-        processed_query=query,  # Could be cleaned/expanded
+        processed_query=" ".join([query, *expansions]) if expansions else query,
         intent=intent,
         confidence=confidence,
         language_hints=language_hints,
         path_hints=path_hints,
         symbol_hints=symbol_hints,
-        filters=filters
+        filters=filters,
+        expansions=expansions,
+        path=path,
+        fallback_reason=fallback_reason
     )
 
 
-def classify_with_llm(query: str) -> Optional[QueryIntent]:
+async def analyze_for_search(query: str, force_heuristic: bool = False) -> QueryAnalysis:
     """
-    Classify query intent using Ollama LLM (qwen2.5-coder:1.5b).
+    Analyze a query on the search path and count which path served it.
+
+    The LLM backends are used when ``search.query_analysis.use_llm`` is on,
+    the query model is enabled and the request didn't force the heuristic
+    path; the analyzer drives its own event loop, so it runs in a thread.
+    """
+    import asyncio
+    from src.core.config import settings
+
+    use_llm = bool(settings.get("search.query_analysis.use_llm", False))
+    analysis = await asyncio.to_thread(analyze_query, query, use_llm, force_heuristic)
+    record_query_path(analysis)
+    return analysis
+
+
+def record_query_path(analysis: QueryAnalysis):
+    """Count the path (and fallback reason) for the metrics endpoint."""
+    try:
+        from src.services.admin.admin_store import get_admin_store
+        store = get_admin_store()
+        store.increment_counter(f"query_analysis_{analysis.path}")
+        if analysis.fallback_reason:
+            store.increment_counter(f"query_analysis_fallback_{analysis.fallback_reason}")
+    except Exception as e:
+        logger.debug(f"Failed to record query analysis path: {e}")
+
+
+def query_model_enabled() -> bool:
+    """Whether the query model may be used (runtime admin switch)."""
+    try:
+        from src.services.admin.admin_store import get_admin_store
+        return bool(get_admin_store().get_effective_config().get("query_model_enabled", True))
+    except Exception:
+        return True
+
+
+# Last warm-up result in this process
+_last_warmup: Optional[Dict[str, Any]] = None
+
+
+async def warm_up_query_model() -> Dict[str, Any]:
+    """
+    Send a one-token prompt through the backend chain so the query model is
+    loaded before real queries need it (Ollama loads models on first use).
+    """
+    global _last_warmup
+    from src.core.config import settings
+    from src.services.query.llm_backends import get_query_llm
+
+    timeout = float(settings.get("search.query_analysis.warmup_timeout_seconds", 60.0))
+    started = time.perf_counter()
+    result = await get_query_llm().complete("Reply with OK.", max_tokens=1, timeout=timeout)
+    _last_warmup = {
+        "ok": result is not None,
+        "latency_ms": round((time.perf_counter() - started) * 1000),
+        "at": time.time(),
+    }
+    if result is None:
+        logger.warning("Query model warm-up failed: no backend answered")
+    return _last_warmup
 
-    Uses Ollama's chat API with a classification prompt.
+
+def query_model_status() -> Dict[str, Any]:
+    """Query model state for the admin UI: switch, backends, path counters."""
+    from src.core.config import settings
+    from src.services.admin.admin_store import get_admin_store
+    from src.services.query.llm_backends import get_query_llm
+
+    store = get_admin_store()
+    return {
+        "enabled": query_model_enabled(),
+        "use_llm": bool(settings.get("search.query_analysis.use_llm", False)),
+        "backends": get_query_llm().status(),
+        "last_warmup": _last_warmup,
+        "paths": {p: store.get_counter(f"query_analysis_{p}") for p in (MODEL, HEURISTIC)},
+        "fallbacks": {r: store.get_counter(f"query_analysis_fallback_{r}") for r in FALLBACK_REASONS},
+    }
+
+
+def classify_with_llm(query: str) -> Optional[QueryIntent]:
+    """
+    Classify query intent using the configured LLM backend chain.
 
     Raises:
-        RuntimeError: If Ollama service is unavailable
+        RuntimeError: If no backend answered
     """
-    from src.services.inference import get_inference_client
+    import asyncio
+    from src.services.query.llm_backends import get_query_llm
 
     categories = [intent.value for intent in QueryIntent]
 
-    try:
-        client = get_inference_client()
-
-        # Build classification prompt
-        prompt = f"""Classify the intent of this search query: "{query}"
+    prompt = f"""Classify the intent of this search query: "{query}"
 
 Categories: {', '.join(categories)}
 
 Respond with ONLY the category name, nothing else."""
 
-        from src.core.config import settings
-        import asyncio
-        result = asyncio.run(client.chat(
-            messages=[{"role": "user", "content": prompt}],
-            max_tokens=settings.QUERY_ANALYSIS_LLM_MAX_TOKENS,
-            temperature=settings.QUERY_ANALYSIS_LLM_TEMPERATURE
-        ))
-
-        # Parse result
-        result_lower = result.lower().strip()
-        for intent in QueryIntent:
-            if intent.value in result_lower:
-                return intent
-
-        return None
-    except Exception as e:
-        logger.error(f"LLM classification failed: {e}")
-        raise RuntimeError(f"Query classification service unavailable: {e}")
+    result = asyncio.run(get_query_llm().complete(prompt))
+    if result is None:
+        raise RuntimeError("Query classification service unavailable")
+
+    # Parse result
+    result_lower = result.lower().strip()
+    for intent in QueryIntent:
+        if intent.value in result_lower:
+            return intent
+
+    return None
+
+
+def expand_with_llm(query: str) -> List[str]:
+    """
+    Suggest related search terms (synonyms, identifiers) for a query.
+
+    Returns:
+        Up to search.query_analysis.max_expansions terms, or [] if no backend answered
+    """
+    import asyncio
+    from src.core.config import settings
+    from src.services.query.llm_backends import get_query_llm
+
+    limit = int(settings.get("search.query_analysis.max_expansions", 3))
+    prompt = f"""Suggest up to {limit} alternative search terms for this code search query: "{query}"
+
+Include likely identifier names or synonyms. Respond with ONLY a comma-separated list."""
+
+    result = asyncio.run(get_query_llm().complete(prompt, max_tokens=64))
+    if not result:
+        return []
+
+    query_lower = query.lower()
+    terms = []
+    for term in re.split(r"[,\n]", result):
+        term = term.strip().strip("\"'`-*. ")
+        if term and term.lower() not in query_lower and term not in terms:
+            terms.append(term)
+    return terms[:limit]
 
 
 # For backwards compatibility
@@ -0,0 +1,177 @@
+"""
+Query Result Cache.
+
+Dashboards and agents re-issue identical searches constantly. Results are
+kept in an in-process LRU keyed by (store, normalized query, filters,
+options) and served for ``search.cache.ttl_seconds``.
+
+Index writes happen on the worker, so invalidation goes through Redis: every
+write or delete bumps a per-store generation counter (deletes that are not
+scoped to a store bump a global one). Entries remember the generations they
+were computed under and are dropped once either moves. When Redis is
+unreachable the cache is bypassed rather than risk serving stale results.
+"""
+
+import copy
+import json
+import logging
+import threading
+import time
+from collections import OrderedDict
+from typing import Any, Dict, List, Optional, Tuple
+
+import redis
+
+from src.core.config import settings
+
+logger = logging.getLogger(__name__)
+
+ALL_STORES = "*"
+
+
+def normalize_query(query: str) -> str:
+    """Collapse whitespace so trivially different spellings share an entry."""
+    return " ".join(query.split())
+
+
+def cache_key(org_id: str, query: str, filters: Any = None, **options: Any) -> str:
+    """Stable key for a search (filters may be ``SearchFilters`` or a dict)."""
+    if filters is not None and hasattr(filters, "to_dict"):
+        filters = filters.to_dict()
+    return json.dumps(
+        [org_id, normalize_query(query), filters or {}, options],
+        sort_keys=True,
+        default=str,
+    )
+
+
+class QueryCache:
+    """LRU of search results with TTL and Redis-backed invalidation."""
+
+    GENERATION_KEY = "rice:search_cache:generation"
+
+    def __init__(self, redis_client=None):
+        self._redis = redis_client
+        self._entries: "OrderedDict[str, Tuple[float, Tuple[int, int], List[Dict]]]" = OrderedDict()
+        self._lock = threading.Lock()
+        self.hits = 0
+        self.misses = 0
+
+    @property
+    def redis(self) -> redis.Redis:
+        """Lazy Redis connection."""
+        if self._redis is None:
+            self._redis = redis.from_url(settings.REDIS_URL, decode_responses=True)
+        return self._redis
+
+    @property
+    def enabled(self) -> bool:
+        return bool(settings.get("search.cache.enabled", True))
+
+    @property
+    def ttl_seconds(self) -> float:
+        return float(settings.get("search.cache.ttl_seconds", 60))
+
+    @property
+    def max_entries(self) -> int:
+        return int(settings.get("search.cache.max_entries", 1000))
+
+    def _generations(self, org_id: str) -> Optional[Tuple[int, int]]:
+        """(store, global) generation, or None if Redis is unavailable."""
+        try:
+            store_gen, all_gen = self.redis.hmget(self.GENERATION_KEY, org_id, ALL_STORES)
+            return int(store_gen or 0), int(all_gen or 0)
+        except Exception as e:
+            logger.debug(f"Query cache bypassed, generation lookup failed: {e}")
+            return None
+
+    def get(self, key: str, generations: Optional[Tuple[int, int]]) -> Optional[List[Dict]]:
+        """Cached results for a key, if still valid under ``generations``."""
+        if generations is None:
+            return None
+        with self._lock:
+            entry = self._entries.get(key)
+            if entry is not None:
+                expires_at, cached_generations, results = entry
+                if time.monotonic() < expires_at and cached_generations == generations:
+                    self._entries.move_to_end(key)
+                    self.hits += 1
+                    # Callers annotate results; never hand out the cached objects
+                    return copy.deepcopy(results)
+                del self._entries[key]
+            self.misses += 1
+        return None
+
+    def put(self, key: str, results: List[Dict], generations: Optional[Tuple[int, int]]):
+        """
+        Cache results computed under ``generations`` (read before searching,
+        so a write that lands mid-search invalidates the entry).
+        """
+        if generations is None or self.max_entries <= 0:
+            return
+        entry = (time.monotonic() + self.ttl_seconds, generations, copy.deepcopy(results))
+        with self._lock:
+            self._entries[key] = entry
+            self._entries.move_to_end(key)
+            while len(self._entries) > self.max_entries:
+                self._entries.popitem(last=False)
+
+    def generations(self, org_id: str) -> Optional[Tuple[int, int]]:
+        """Current generations for a store (None: don't cache)."""
+        if not self.enabled:
+            return None
+        return self._generations(org_id)
+
+    def invalidate(self, org_id: Optional[str] = None):
+        """
+        Invalidate cached searches for a store (None: every store).
+
+        Called from index writes and deletes, in whichever process runs them.
+        """
+        try:
+            self.redis.hincrby(self.GENERATION_KEY, org_id or ALL_STORES, 1)
+        except Exception as e:
+            logger.warning(f"Failed to invalidate query cache for {org_id or 'all stores'}: {e}")
+        if org_id is None:
+            with self._lock:
+                self._entries.clear()
+
+    def clear(self):
+        with self._lock:
+            self._entries.clear()
+
+    def get_stats(self) -> Dict[str, Any]:
+        total = self.hits + self.misses
+        return {
+            "enabled": self.enabled,
+            "entries": len(self._entries),
+            "max_entries": self.max_entries,
+            "ttl_seconds": self.ttl_seconds,
+            "hits": self.hits,
+            "misses": self.misses,
+            "hit_rate": round(self.hits / total, 3) if total else 0.0,
+        }
+
+
+# Singleton instance
+_query_cache: Optional[QueryCache] = None
+
+def get_query_cache() -> QueryCache:
+    """Get global query cache instance."""
+    global _query_cache
+    if _query_cache is None:
+        _query_cache = QueryCache()
+    return _query_cache
+
+
+def invalidate_store(org_id: Optional[str] = None):
+    """Invalidate cached searches after an index write (never raises)."""
+    try:
+        get_query_cache().invalidate(org_id)
+    except Exception as e:
+        logger.warning(f"Query cache invalidation failed: {e}")
+
+
+def index_version(org_id: str) -> Optional[Tuple[int, int]]:
+    """(store, global) index generation, for ETags (None if Redis is unavailable)."""
+    return get_query_cache()._generations(org_id)
@@ -0,0 +1,214 @@
+"""
+Inline Query Syntax.
+
+Search boxes and CLIs send the query as typed; filters written inline are
+compiled server-side into ``SearchFilters`` and the rest is the semantic
+query:
+
+    lang:go path:cmd/ symbol:NewServer "exact phrase" -vendor
+
+- ``lang:go`` / ``language:go``: programming language. Extensions and
+  common names work too (``lang:py``, ``lang:golang``, ``lang:c++``)
+- ``path:cmd/`` / ``file:cmd/``: path glob. A trailing ``/`` means the
+  directory (``cmd/**``); a plain path matches the file or the directory
+  with that name; values with ``*`` or ``?`` are used as globs
+- ``ext:go``: file extension
+- ``symbol:NewServer``: chunks defining the symbol
+- ``"exact phrase"``: chunks containing the phrase (case-insensitive).
+  The words stay in the semantic query
+- ``-path:vendor/``, ``-ext:pb.go``: exclude paths or extensions
+- ``-vendor``, ``-"legacy api"``: drop chunks whose path or text
+  contains the word or phrase
+
+Values can be quoted (``path:"my docs/"``). Unknown keys (``http://``,
+``std::vector``) and lone ``-`` or ``--flags`` are left in the query.
+``ParsedQuery`` records how each token was read so clients can show the
+interpretation next to the results.
+"""
+
+import re
+from dataclasses import dataclass, field, replace
+from typing import Any, Dict, List, Optional
+
+from src.services.search.filters import SearchFilters
+
+# Optional "-", optional "key:", then a quoted value; anything else is a plain word
+_TOKEN = re.compile(r'(?P<neg>-)?(?:(?P<key>[A-Za-z]+):)?"(?P<quoted>[^"]*)"|\S+')
+_KEYED = re.compile(r"^(?P<neg>-)?(?P<key>[A-Za-z]+):(?P<value>.+)$")
+# Words that can be negated; "--flag" and "-1" stay search text
+_NEGATABLE = re.compile(r"^-(?P<value>[A-Za-z_.][\w./-]*)$")
+_GLOB_CHARS = re.compile(r"[*?\[\]]")
+
+KEYS = {
+    "lang": "language",
+    "language": "language",
+    "path": "path",
+    "file": "path",
+    "ext": "extension",
+    "symbol": "symbol",
+}
+
+LANGUAGE_ALIASES = {
+    "golang": "go",
+    "js": "javascript",
+    "node": "javascript",
+    "ts": "typescript",
+    "py": "python",
+    "rb": "ruby",
+    "rs": "rust",
+    "c++": "cpp",
+    "cxx": "cpp",
+    "c#": "csharp",
+    "cs": "csharp",
+    "sh": "shell",
+    "bash": "shell",
+    "md": "markdown",
+    "yml": "yaml",
+}
+
+
+def normalize_language(value: str) -> str:
+    """Language payload value for ``lang:`` (``py`` -> ``python``)."""
+    from src.services.ingestion.language import EXTENSION_LANGUAGES
+
+    value = value.strip().lower()
+    if value in LANGUAGE_ALIASES:
+        return LANGUAGE_ALIASES[value]
+    if value in set(EXTENSION_LANGUAGES.values()):
+        return value
+    return EXTENSION_LANGUAGES.get(f".{value.lstrip('.')}", value)
+
+
+def path_globs(value: str) -> List[str]:
+    """Globs for a ``path:`` value: ``cmd/`` -> ``cmd/**``, ``cmd/api`` -> file or directory."""
+    value = value.replace("\\", "/").strip()
+    if _GLOB_CHARS.search(value):
+        return [value]
+    if value.endswith("/"):
+        return [value.rstrip("/") + "/**"]
+    return [value, value + "/**"]
+
+
+@dataclass
+class ParsedQuery:
+    """How a raw query was read: semantic text plus the compiled filters."""
+
+    raw: str
+    text: str
+    filters: SearchFilters
+    # One entry per recognised token: token, field, value, negated
+    tokens: List[Dict[str, Any]] = field(default_factory=list)
+    # Tokens that were understood but not applied
+    warnings: List[str] = field(default_factory=list)
+
+    def to_dict(self) -> Dict[str, Any]:
+        result = {
+            "raw": self.raw,
+            "text": self.text,
+            "filters": self.filters.to_dict(),
+            "tokens": self.tokens,
+        }
+        if self.warnings:
+            result["warnings"] = self.warnings
+        return result
+
+
+def _add(values: List[str], new: List[str]):
+    for value in new:
+        if value and value not in values:
+            values.append(value)
+
+
+def parse(query: str, filters: Optional[SearchFilters] = None) -> ParsedQuery:
+    """
+    Compile inline filter tokens.
+
+    Args:
+        query: Raw query text as typed
+        filters: Filters from request fields (not modified); inline tokens
+            are added to them
+
+    Returns:
+        ParsedQuery. A query made only of filter tokens falls back to the
+        symbol names, phrases or filter values so retrievers still have
+        text to score.
+    """
+    filters = filters or SearchFilters()
+    merged = {
+        "symbols": list(filters.symbols),
+        "include_paths": list(filters.include_paths),
+        "exclude_paths": list(filters.exclude_paths),
+        "extensions": list(filters.extensions),
+        "languages": list(filters.languages),
+        "phrases": list(filters.phrases),
+        "exclude_terms": list(filters.exclude_terms),
+    }
+    words: List[str] = []
+    fallback: List[str] = []
+    tokens: List[Dict[str, Any]] = []
+    warnings: List[str] = []
+
+    for match in _TOKEN.finditer(query):
+        token = match.group(0)
+        if match.group("quoted") is not None:
+            negated, key, value = bool(match.group("neg")), match.group("key"), match.group("quoted").strip()
+        else:
+            keyed = _KEYED.match(token)
+            negatable = _NEGATABLE.match(token)
+            if keyed:
+                negated, key, value = bool(keyed.group("neg")), keyed.group("key"), keyed.group("value")
+            elif negatable:
+                negated, key, value = True, None, negatable.group("value")
+            else:
+                words.append(token)
+                continue
+
+        name = KEYS.get(key.lower()) if key else None
+        if key and not name:
+            words.append(token)
+            continue
+        if not value:
+            warnings.append(f"Ignored empty {token}")
+            continue
+
+        if name is None:
+            # Quoted phrase or negated word
+            if negated:
+                _add(merged["exclude_terms"], [value])
+                name = "exclude"
+            else:
+                _add(merged["phrases"], [value])
+                words.append(value)
+                name = "phrase"
+        elif name == "path":
+            _add(merged["exclude_paths" if negated else "include_paths"], path_globs(value))
+            if not negated:
+                fallback.append(value.strip("/"))
+        elif name == "extension":
+            ext = value.lstrip(".").lower()
+            if negated:
+                _add(merged["exclude_paths"], [f"*.{ext}"])
+            else:
+                _add(merged["extensions"], [f".{ext}"])
+            value = f".{ext}"
+        elif negated:
+            warnings.append(f"{token}: excluding by {name} is not supported")
+            continue
+        elif name == "language":
+            value = normalize_language(value)
+            _add(merged["languages"], [value])
+            fallback.append(value)
+        elif name == "symbol":
+            _add(merged["symbols"], [value])
+        tokens.append({"token": token, "field": name, "value": value, "negated": negated})
+
+    text = " ".join(" ".join(words).split())
+    if not text:
+        text = " ".join(merged["symbols"] or merged["phrases"] or fallback)
+    return ParsedQuery(
+        raw=query,
+        text=text,
+        filters=replace(filters, **merged),
+        tokens=tokens,
+        warnings=warnings,
+    )
@@ -0,0 +1,102 @@
+"""
+Rerank Candidate Sampling.
+
+Reranking cost grows with the number of candidates, and wide searches
+(large limits, pagination windows) can send hundreds of chunks to the
+cross-encoder. Above ``models.reranker.max_candidates`` only a sample is
+reranked; the rest keep their fused order after the reranked results, so
+nothing is dropped.
+
+Strategies (``models.reranker.sampling.strategy``):
+- truncate: the top fused candidates
+- smart: every retriever's top candidates (dense and sparse each get their
+  best matches in, even when fusion ranked them low), then a diverse tail
+  that prefers files not yet sampled, in fused order
+"""
+
+import logging
+from typing import Any, Dict, List, Optional, Tuple
+
+from src.core.config import settings
+
+logger = logging.getLogger(__name__)
+
+TRUNCATE = "truncate"
+SMART = "smart"
+STRATEGIES = (TRUNCATE, SMART)
+
+
+def _path(result: Dict[str, Any]) -> Optional[str]:
+    return result.get("full_path") or result.get("file_path")
+
+
+def _retriever_rankings(results: List[Dict[str, Any]]) -> List[List[int]]:
+    """Candidate indexes per retriever, best first by that retriever's score."""
+    scores: Dict[str, List[Tuple[float, int]]] = {}
+    for i, result in enumerate(results):
+        for name, score in (result.get("retriever_scores") or {}).items():
+            scores.setdefault(name, []).append((score, i))
+    return [
+        [i for _, i in sorted(entries, key=lambda e: (-e[0], e[1]))]
+        for _, entries in sorted(scores.items())
+    ]
+
+
+def sample_candidates(
+    results: List[Dict[str, Any]],
+    cap: Optional[int] = None,
+    strategy: Optional[str] = None,
+    top_per_retriever: Optional[int] = None,
+) -> Tuple[List[Dict[str, Any]], List[Dict[str, Any]]]:
+    """
+    Split fused results into the candidates to rerank and the remainder.
+
+    Args:
+        results: Fused results, best first
+        cap: Maximum candidates to rerank (default: models.reranker.max_candidates; 0 = no cap)
+        strategy: ``smart`` or ``truncate`` (default: models.reranker.sampling.strategy)
+        top_per_retriever: Per-retriever head kept by ``smart``
+
+    Returns:
+        (candidates in fused order, remainder in fused order)
+    """
+    if cap is None:
+        cap = int(settings.get("models.reranker.max_candidates", 50))
+    if cap <= 0 or len(results) <= cap:
+        return list(results), []
+    strategy = strategy or settings.get("models.reranker.sampling.strategy", SMART)
+    if strategy not in STRATEGIES:
+        logger.warning(f"Unknown rerank sampling strategy '{strategy}', using {SMART}")
+        strategy = SMART
+
+    if strategy == TRUNCATE:
+        chosen = set(range(cap))
+    else:
+        if top_per_retriever is None:
+            top_per_retriever = int(settings.get("models.reranker.sampling.top_per_retriever", 10))
+        chosen = set()
+        # Round-robin over retrievers so each gets its best in before the cap
+        rankings = [ranking[:top_per_retriever] for ranking in _retriever_rankings(results)]
+        for depth in range(max((len(r) for r in rankings), default=0)):
+            for ranking in rankings:
+                if len(chosen) < cap and depth < len(ranking):
+                    chosen.add(ranking[depth])
+
+        # Diverse tail: unsampled files first, then whatever ranks next
+        sampled_paths = {_path(results[i]) for i in chosen}
+        for i, result in enumerate(results):
+            if len(chosen) >= cap:
+                break
+            path = _path(result)
+            if i not in chosen and (path is None or path not in sampled_paths):
+                chosen.add(i)
+                sampled_paths.add(path)
+        for i in range(len(results)):
+            if len(chosen) >= cap:
+                break
+            chosen.add(i)
+
+    logger.debug(f"Reranking {len(chosen)} of {len(results)} candidates ({strategy})")
+    candidates = [r for i, r in enumerate(results) if i in chosen]
+    remainder = [r for i, r in enumerate(results) if i not in chosen]
+    return candidates, remainder
@@ -5,9 +5,10 @@ Uses unified-inference's rerank endpoint for dedicated reranker models,
 or LLM-based reranking via chat as fallback.
 """
 import logging
-from typing import List, Dict, Any
+from typing import List, Dict, Any, Optional
 
 from src.core.config import settings
+from src.services.admin.usage import record_usage
 
 logger = logging.getLogger(__name__)
 
@@ -28,6 +29,7 @@ async def rerank_results(query: str, documents: List[str]) -> List[float]:
     """
     if not documents:
         return []
+    record_usage("rerank_pairs", len(documents))
 
     from src.services.inference import get_inference_client
     client = get_inference_client()
@@ -37,12 +39,19 @@ async def rerank_results(query: str, documents: List[str]) -> List[float]:
 
     # Use local cross-encoder reranker
     if mode == "local":
+        from src.core.circuit_breaker import CircuitOpenError
+        from src.services.inference.watchdog import get_inference_watchdog, RERANK, InferenceTimeoutError
         try:
-            results = await client.rerank(query, documents)
+            results = await get_inference_watchdog().run(
+                RERANK, lambda: client.rerank(query, documents)
+            )
             # local_reranker returns list of dicts with "relevance_score" key
             scores = [r["relevance_score"] for r in results]
             logger.info(f"Local reranker returned {len(scores)} scores. Range: {min(scores):.3f} - {max(scores):.3f}")
             return scores
+        except (InferenceTimeoutError, CircuitOpenError):
+            # Don't pile a slower LLM fallback on top of a timeout or an open circuit
+            raise
         except Exception as e:
             logger.warning(f"Local reranker failed, trying LLM fallback: {e}")
 
@@ -50,6 +59,28 @@ async def rerank_results(query: str, documents: List[str]) -> List[float]:
     return await _rerank_with_llm(client, query, documents)
 
 
+async def rerank_with_model(query: str, documents: List[str], model: str) -> List[float]:
+    """
+    Score documents with a cross-encoder other than the configured one.
+
+    No LLM fallback: a model picked for one request should fail loudly
+    rather than quietly score with something else.
+    """
+    if not documents:
+        return []
+    record_usage("rerank_pairs", len(documents))
+
+    from src.services.inference.local_reranker import get_named_reranker
+    from src.services.inference.watchdog import get_inference_watchdog, RERANK
+    results = await get_inference_watchdog().run(
+        RERANK, lambda: get_named_reranker(model).rerank(query, documents)
+    )
+    scores = [0.0] * len(documents)
+    for r in results:
+        scores[r["index"]] = r["relevance_score"]
+    return scores
+
+
 async def _rerank_with_llm(client, query: str, documents: List[str]) -> List[float]:
     """Rerank using LLM prompting (Async)."""
     from src.core.config import settings
@@ -133,14 +164,41 @@ Scores:"""
         return [1.0 - (i / n) for i in range(n)]
 
 
-async def rerank_search_results(query: str, results: List[Dict[str, Any]], content_key: str = "text") -> List[Dict[str, Any]]:
-    """Rerank search results and return sorted by relevance (Async)."""
+async def rerank_search_results(
+    query: str,
+    results: List[Dict[str, Any]],
+    content_key: str = "text",
+    model: Optional[str] = None,
+) -> List[Dict[str, Any]]:
+    """
+    Rerank search results and return sorted by relevance (Async).
+
+    ``model`` picks a cross-encoder other than the configured reranker.
+
+    Above ``models.reranker.max_candidates`` only a sample is reranked (see
+    rerank_sampling); the rest follow the reranked results in fused order.
+    """
     if not results:
         return results
 
+    from src.core.circuit_breaker import CircuitOpenError
+    from src.services.inference.watchdog import InferenceTimeoutError
+    from src.services.search import degradation
+    from src.services.search.rerank_sampling import sample_candidates
+
+    fused = results
+    results, remainder = sample_candidates(fused)
     texts = [r.get(content_key, "") for r in results]
     logger.debug(f"Reranking {len(texts)} documents. First text sample: {texts[0][:100] if texts else 'N/A'}...")
-    scores = await rerank_results(query, texts)
+    try:
+        if model:
+            scores = await rerank_with_model(query, texts, model)
+        else:
+            scores = await rerank_results(query, texts)
+    except (InferenceTimeoutError, CircuitOpenError) as e:
+        # Keep fused order rather than failing the search
+        degradation.note(f"reranking skipped ({e})")
+        return fused
     logger.info(f"Raw rerank scores range: {min(scores):.3f} - {max(scores):.3f}")
 
     # BGE reranker returns raw confidence scores, typically in range [-1, 1]
@@ -150,4 +208,4 @@ async def rerank_search_results(query: str, results: List[Dict[str, Any]], conte
     for i, result in enumerate(results):
         result["rerank_score"] = scores[i]
 
-    return sorted(results, key=lambda x: x.get("rerank_score", 0), reverse=True)
+    return sorted(results, key=lambda x: x.get("rerank_score", 0), reverse=True) + remainder
@@ -8,6 +8,14 @@ Supports THREE retrieval methods simultaneously:
 
 All methods can be enabled/disabled at runtime via flags.
 Results are fused using Reciprocal Rank Fusion (RRF).
+
+A request can pick another embedding model the store has vectors for
+(``model_id``); BM42 is then replaced by a dense search with that model.
+``rerank_model_id`` picks the cross-encoder (see model_overrides).
+
+Collections with ``doc`` vectors (docstrings and comments, see
+doc_vectors) are also searched by those; the code and doc results are
+blended in fusion with the store's code/doc vector weights.
 """
 
 import logging
@@ -16,17 +24,28 @@ from typing import List, Dict, Optional, Any
 
 from qdrant_client.models import (
     Filter,
-    FieldCondition,
-    MatchValue,
     Prefetch,
     FusionQuery,
     Fusion,
     SparseVector,
 )
 
+from src.core.circuit_breaker import CircuitOpenError, get_breakers
+from src.db.content_store import hydrate
 from src.db.qdrant import get_qdrant_client
 from src.core.config import settings
-from src.services.retrieval.fusion import rrf_fusion, FusedResult
+from src.services.admin.usage import record_usage
+from src.services.ingestion.doc_vectors import DOC_VECTOR, blend_weights, has_doc_vectors, vector_weights as resolve_vector_weights
+from src.services.ingestion.migration import store_collection
+from src.services.inference.openai_compat import estimate_tokens
+from src.services.retrieval.fusion import rrf_fusion, heading_boost, diversify, FusedResult
+from src.services.retrieval.analyzer import analyze, analyze_all
+from src.services.search.boosting import apply_boosts, get_store_boosts, get_store_recency, recency_decay
+from src.services.search.filters import SearchFilters, build_filter
+from src.services.search import degradation
+from src.services.search.deadline import resolve_timeout, with_deadline
+from src.services.search.query_cache import cache_key, get_query_cache
+from src.services.inference.watchdog import get_inference_watchdog, EMBED, SPARSE
 
 logger = logging.getLogger(__name__)
 
@@ -43,8 +62,28 @@ async def embed_texts_async(texts: List[str]) -> List[List[float]]:
     """
     from src.services.inference import get_inference_client
 
-    client = get_inference_client()
-    return await client.embed(texts)
+    record_usage("embed_tokens", sum(estimate_tokens(t) for t in texts))
+    return await get_inference_watchdog().run(
+        EMBED, lambda: get_inference_client().embed(texts)
+    )
+
+
+async def qdrant_call(fn, **kwargs):
+    """
+    Run a (sync) Qdrant client call in a thread behind the ``qdrant`` breaker.
+
+    Raises:
+        CircuitOpenError: Qdrant has been failing; the call was not made
+    """
+    breaker = get_breakers().get("qdrant")
+    breaker.before_call()
+    try:
+        result = await asyncio.to_thread(fn, **kwargs)
+    except Exception as e:
+        breaker.record_failure(e)
+        raise
+    breaker.record_success()
+    return result
 
 
 def embed_texts(texts: List[str]) -> List[List[float]]:
@@ -120,6 +159,13 @@ class MultiRetriever:
         use_bm42: bool = True,
         rerank: bool = None,
         rrf_k: int = None,
+        explain: bool = False,
+        weights: Optional[Dict[str, float]] = None,
+        filters: Optional[SearchFilters] = None,
+        encoded: Optional[Dict[str, Any]] = None,
+        model_id: Optional[str] = None,
+        rerank_model_id: Optional[str] = None,
+        vector_weights: Optional[Dict[str, float]] = None,
     ) -> List[Dict[str, Any]]:
         """
         Search using selected retrievers with RRF fusion.
@@ -131,43 +177,77 @@ class MultiRetriever:
             use_bm25: Enable BM25 (Tantivy)
             use_splade: Enable SPLADE
             use_bm42: Enable BM42 hybrid
-            rerank: Enable reranking (default from settings)
-            rrf_k: RRF parameter
+            rerank: Enable reranking (default: store config, then settings)
+            rrf_k: RRF parameter (default: store config, then settings)
+            explain: Attach a per-result ``explanation`` of score composition
+            weights: Per-retriever RRF weights (default: tuned/store weights)
+            filters: Payload filters (symbols, paths, ...) on top of the store filter
+            encoded: Query vectors computed ahead ("dense", "splade", "bm42"),
+                used by batch search to encode many queries at once
+            model_id: Embedding model to search with instead of the store's
+            rerank_model_id: Reranker to use instead of the configured one
+            vector_weights: Code/doc vector blend ({"code", "doc"}; default:
+                store config, then settings)
             
         Returns:
             List of search results with metadata
+
+        Raises:
+            ValueError: ``model_id`` isn't indexed in the store,
+                ``rerank_model_id`` is unknown or ``vector_weights`` invalid
         """
+        from src.services.search.model_overrides import resolve_embedding_model, resolve_rerank_model
+        dense_target = resolve_embedding_model(org_id, model_id)
+        rerank_model = resolve_rerank_model(org_id, rerank_model_id)
+        store_config = self._store_search_config(org_id)
+
         if rerank is None:
-            rerank = settings.RERANK_ENABLED
+            rerank = store_config.get("rerank", settings.RERANK_ENABLED)
 
         if rrf_k is None:
-            rrf_k = settings.RRF_K
+            rrf_k = store_config.get("rrf_k", settings.RRF_K)
 
         qdrant = get_qdrant_client()
+        collection = store_collection(org_id)
         result_sets: Dict[str, List[Dict]] = {}
         
-        # Build organization filter
-        search_filter = None
-        if org_id and org_id != "public":
-            search_filter = Filter(
-                must=[FieldCondition(key="org_id", match=MatchValue(value=org_id))]
-            )
+        # Build organization + payload filter
+        search_filter = build_filter(org_id, filters)
+
+        # Code and docstring vectors, blended in fusion
+        vectors = None
+        if use_bm42 and not dense_target and has_doc_vectors(qdrant, collection):
+            vectors = resolve_vector_weights(store_config, vector_weights)
+            if vectors["doc"] > 0 and (encoded or {}).get("dense") is None:
+                # Embed once for both dense searches
+                encoded = {**(encoded or {}), "dense": (await embed_texts_async([query]))[0]}
         
         # Execute retrievers in parallel using asyncio.gather
         tasks = []
         names = []
 
         if use_bm25:
-            tasks.append(self._search_bm25(query, limit * 2))
+            tasks.append(self._search_bm25(query, limit * 2, collection))
             names.append("bm25")
         
         if use_splade:
-            tasks.append(self._search_splade(query, qdrant, limit * 2, search_filter))
-            names.append("splade")
+            from src.services.retrieval.bm25_index import BM25, get_store_sparse_backend
+            if get_store_sparse_backend(org_id) == BM25:
+                tasks.append(self._search_bm25_sparse(query, qdrant, limit * 2, org_id))
+                names.append("bm25_sparse")
+            else:
+                tasks.append(self._search_splade(query, qdrant, limit * 2, search_filter, collection, encoded))
+                names.append("splade")
             
-        if use_bm42:
-            tasks.append(self._search_bm42(query, qdrant, limit * 2, search_filter))
+        if use_bm42 and dense_target:
+            tasks.append(self._search_dense_model(query, qdrant, limit * 2, search_filter, collection, dense_target))
+            names.append("dense")
+        elif use_bm42:
+            tasks.append(self._search_bm42(query, qdrant, limit * 2, search_filter, collection, encoded))
             names.append("bm42")
+            if vectors and vectors["doc"] > 0:
+                tasks.append(self._search_doc(query, qdrant, limit * 2, search_filter, collection, encoded))
+                names.append(DOC_VECTOR)
             
         if not tasks:
             logger.warning("No retrievers selected")
@@ -179,21 +259,73 @@ class MultiRetriever:
         for name, res in zip(names, results_list):
             if isinstance(res, Exception):
                 logger.warning(f"{name} search failed: {res}")
-            elif res:
+                if isinstance(res, CircuitOpenError):
+                    degradation.note(f"{name} retrieval skipped ({res})")
+                continue
+            if res and filters:
+                # BM25 lookups skip the Qdrant filter and some globs have no
+                # Qdrant equivalent, so check every result
+                res = [r for r in res if filters.matches(r)]
+            if res:
                 result_sets[name] = res
                 logger.debug(f"{name} returned {len(res)} results")
 
-        # 4. Fusion
-        if not result_sets:
+        # 4. Fusion (per-store weights: click-tuned, else applied store config)
+        output = []
+        explainer = None
+        if result_sets:
+            from src.services.search.fusion_tuning import default_diversity_lambda, get_fusion_tuner
+            tuner = get_fusion_tuner()
+            if weights is None:
+                weights = store_config.get("weights")
+                if tuner.enabled:
+                    weights = tuner.get_weights(org_id, default=weights)
+            diversity = tuner.get_diversity_lambda(org_id) if tuner.enabled else default_diversity_lambda()
+            if DOC_VECTOR in result_sets:
+                weights = blend_weights(weights, vectors)
+            # Fuse a wider pool so docs sections with matching headings (and
+            # chunks the store's boosting rules, recency or diversity favour) can move up into the page
+            boost = float(settings.get("search.heading_boost", 0.3))
+            boost_rules = get_store_boosts(org_id)
+            recency = get_store_recency(org_id)
+            pool = limit * 2 if boost or boost_rules or recency or diversity < 1.0 else limit
+            fused_results = rrf_fusion(result_sets, limit=pool, k=rrf_k, weights=weights)
+            fused_results = heading_boost(fused_results, query, boost)
+            boosted = apply_boosts(fused_results, boost_rules, query)
+            for chunk_id, factors in recency_decay(fused_results, recency).items():
+                boosted.setdefault(chunk_id, []).extend(factors)
+            fused_results = diversify(fused_results, diversity)[:limit]
+
+            # Convert to output format
+            output = self._format_results(fused_results)
+
+            if explain:
+                from src.services.search.explain import SearchExplainer
+                explainer = SearchExplainer(rrf_k, weights)
+                explainer.record_retrievers(result_sets)
+                explainer.record_fusion(fused_results, output)
+                explainer.record_boosts(boosted)
+        else:
             logger.warning("All retrievers failed or returned no results")
-            return []
-        
-        fused_results = rrf_fusion(result_sets, limit=limit, k=rrf_k)
-        
-        # Convert to output format
-        output = self._format_results(fused_results)
+
+        # Cold tier fallback when the hot tier comes back thin
+        from src.services.search.tiering import get_tier_manager
+        tier_manager = get_tier_manager()
+        if tier_manager.should_query_cold(len(output), limit):
+            output = await self._search_cold_tier(
+                query, qdrant, output, limit, search_filter, use_splade, use_bm42, rrf_k, filters, encoded,
+                collection
+            )
+        if tier_manager.enabled:
+            tier_manager.record_hits([r["chunk_id"] for r in output if r.get("tier") != "cold"])
         
-        # 5. Reranking (Async)
+        if explainer:
+            explainer.record_pre_rerank(output)
+
+        # 5. Reranking (Async); privacy-mode stores have no text to rerank
+        from src.services.ingestion.privacy import is_private_store
+        if rerank and output and is_private_store(org_id):
+            rerank = False
         if rerank and output:
             from src.services.search.reranker import rerank_search_results
             # We assume rerank_search_results handles async or we wrap it.
@@ -212,11 +344,183 @@ class MultiRetriever:
             # For this step, I'll temporarily disable reranking or wrap it?
             # Better: I will create a `rerank_search_results_async` inline or import it (assuming next step fixes it).
             # I will call `await self._rerank_async(query, output)`
-            output = await self._rerank_async(query, output)
+            output = await asyncio.to_thread(hydrate, output)  # Rerankers need the text
+            output = await self._rerank_async(query, output, rerank_model)
+
+        if explainer:
+            explainer.annotate(output, reranked=bool(rerank))
         
         return output
     
-    async def _rerank_async(self, query: str, results: List[Dict]) -> List[Dict]:
+    async def encode_queries(
+        self,
+        queries: List[str],
+        org_id: str,
+        use_splade: bool = True,
+        use_bm42: bool = True,
+    ) -> List[Dict[str, Any]]:
+        """
+        Encode many queries in one ML batch per representation.
+
+        Returns:
+            One dict per query with the vectors the enabled retrievers need
+            (missing keys fall back to per-query encoding)
+        """
+        encoded: List[Dict[str, Any]] = [{} for _ in queries]
+        if not queries:
+            return encoded
+
+        from src.services.retrieval.bm25_index import BM25, get_store_sparse_backend
+        sparse_queries = analyze_all(queries, query=True)
+        jobs = []
+        if use_bm42:
+            jobs.append(("dense", embed_texts_async(queries)))
+            jobs.append(("bm42", get_inference_watchdog().run(
+                SPARSE, lambda: asyncio.to_thread(self.bm42_encoder.encode, sparse_queries)
+            )))
+        if use_splade and get_store_sparse_backend(org_id) != BM25:
+            jobs.append(("splade", get_inference_watchdog().run(
+                SPARSE, lambda: asyncio.to_thread(self.splade_encoder.encode, sparse_queries)
+            )))
+
+        vectors = await asyncio.gather(*(job for _, job in jobs), return_exceptions=True)
+        for (name, _), result in zip(jobs, vectors):
+            if isinstance(result, Exception):
+                logger.warning(f"Batch {name} encoding failed, encoding per query: {result}")
+                continue
+            for entry, vector in zip(encoded, result):
+                entry[name] = vector
+        return encoded
+
+    async def search_batch(
+        self,
+        queries: List[str],
+        limit: int = 10,
+        org_id: str = "public",
+        filters: Optional[List[Optional[SearchFilters]]] = None,
+        timeout: Optional[float] = None,
+        **options: Any,
+    ) -> List[Any]:
+        """
+        Run many queries against one store.
+
+        Queries are encoded together, then searched concurrently (at most
+        ``search.batch.concurrency`` at a time).
+
+        Args:
+            queries: Query texts
+            limit: Maximum results per query
+            org_id: Store
+            filters: Per-query payload filters (same order as queries)
+            timeout: Per-query deadline in seconds (None: no deadline)
+            **options: Passed to ``search`` (use_bm25, rerank, explain, ...)
+
+        Returns:
+            Per-query result lists, or the exception a query failed with
+        """
+        encoded = await self.encode_queries(
+            queries,
+            org_id,
+            use_splade=options.get("use_splade", True),
+            use_bm42=options.get("use_bm42", True),
+        )
+        filters = filters or [None] * len(queries)
+        semaphore = asyncio.Semaphore(max(1, int(settings.get("search.batch.concurrency", 8))))
+
+        async def _one(query: str, query_filters: Optional[SearchFilters], vectors: Dict[str, Any]):
+            async with semaphore:
+                return await with_deadline(
+                    self.search(
+                        query, limit=limit, org_id=org_id, filters=query_filters, encoded=vectors, **options
+                    ),
+                    timeout
+                )
+
+        return await asyncio.gather(
+            *(_one(q, f, e) for q, f, e in zip(queries, filters, encoded)),
+            return_exceptions=True
+        )
+
+    def _store_search_config(self, org_id: str) -> Dict[str, Any]:
+        """Search defaults applied to a store by the tuning assistant."""
+        if not org_id:
+            return {}
+        try:
+            from src.services.admin.admin_store import get_admin_store
+            return get_admin_store().get_store_search_config(org_id)
+        except Exception as e:
+            logger.debug(f"No store search config for {org_id}: {e}")
+            return {}
+
+    async def _search_cold_tier(
+        self,
+        query: str,
+        qdrant,
+        hot_output: List[Dict],
+        limit: int,
+        search_filter: Optional[Filter],
+        use_splade: bool,
+        use_bm42: bool,
+        rrf_k: int,
+        filters: Optional[SearchFilters] = None,
+        encoded: Optional[Dict[str, Any]] = None,
+        collection_name: Optional[str] = None,
+    ) -> List[Dict]:
+        """
+        Search the cold collection of the store's hot collection and append
+        results not already found hot.
+
+        BM25 is skipped because cold chunks are removed from Tantivy.
+        """
+        from src.services.search.tiering import cold_collection_for, get_tier_manager
+        cold_collection = cold_collection_for(collection_name or settings.COLLECTION_PREFIX)
+
+        tasks = []
+        names = []
+        if use_splade:
+            tasks.append(self._search_splade(query, qdrant, limit * 2, search_filter, cold_collection, encoded))
+            names.append("splade")
+        if use_bm42:
+            tasks.append(self._search_bm42(query, qdrant, limit * 2, search_filter, cold_collection, encoded))
+            names.append("bm42")
+        if not tasks:
+            return hot_output
+
+        result_sets: Dict[str, List[Dict]] = {}
+        results_list = await asyncio.gather(*tasks, return_exceptions=True)
+        for name, res in zip(names, results_list):
+            if isinstance(res, Exception):
+                logger.debug(f"cold {name} search failed: {res}")
+                continue
+            if res and filters:
+                res = [r for r in res if filters.matches(r)]
+            if res:
+                result_sets[name] = res
+
+        if not result_sets:
+            return hot_output
+
+        cold_output = self._format_results(rrf_fusion(result_sets, limit=limit, k=rrf_k))
+
+        seen = {r.get("full_path") or r.get("chunk_id") for r in hot_output}
+        merged = list(hot_output)
+        promoted = []
+        for result in cold_output:
+            if len(merged) >= limit:
+                break
+            key = result.get("full_path") or result.get("chunk_id")
+            if key in seen:
+                continue
+            seen.add(key)
+            result["tier"] = "cold"
+            merged.append(result)
+            promoted.append(result["chunk_id"])
+
+        logger.debug(f"Cold tier added {len(promoted)} results")
+        get_tier_manager().queue_promotion(promoted)
+        return merged
+
+    async def _rerank_async(self, query: str, results: List[Dict], model: Optional[str] = None) -> List[Dict]:
         """Helper to call reranker async."""
         from src.services.inference import get_inference_client
         client = get_inference_client()
@@ -241,10 +545,10 @@ class MultiRetriever:
         # I will modify this file to assume `rerank_search_results` IS async.
         from src.services.search.reranker import rerank_search_results
         # await rerank_search_results(...)
-        return await rerank_search_results(query, results)
+        return await rerank_search_results(query, results, model=model)
 
-    async def _search_bm25(self, query: str, limit: int) -> List[Dict]:
-        """Search using BM25 via Tantivy (Async/Threaded)."""
+    async def _search_bm25(self, query: str, limit: int, collection_name: Optional[str] = None) -> List[Dict]:
+        """Search using BM25 via Tantivy, hydrating hits from the store's collection (Async/Threaded)."""
         def _blocking_bm25():
             try:
                  return self.tantivy_client.search(query, limit)
@@ -264,12 +568,13 @@ class MultiRetriever:
         # Fetch full data from Qdrant (Threaded)
         qdrant = get_qdrant_client()
         
-        points = await asyncio.to_thread(
+        points = await qdrant_call(
             qdrant.retrieve,
-            collection_name=settings.COLLECTION_PREFIX,
+            collection_name=collection_name or settings.COLLECTION_PREFIX,
             ids=chunk_ids,
             with_payload=True
         )
+        record_usage("qdrant_reads", len(points))
         
         results = []
         for point in points:
@@ -290,16 +595,22 @@ class MultiRetriever:
         query: str,
         qdrant,
         limit: int,
-        search_filter: Optional[Filter]
+        search_filter: Optional[Filter],
+        collection_name: Optional[str] = None,
+        encoded: Optional[Dict[str, Any]] = None
     ) -> List[Dict]:
         """Search using SPLADE sparse vectors (Async/Threaded)."""
-        # Encode query (CPU bound)
-        sparse_vec = await asyncio.to_thread(self.splade_encoder.encode_single, query)
+        sparse_vec = (encoded or {}).get("splade")
+        if sparse_vec is None:
+            # Encode query (CPU bound)
+            sparse_vec = await get_inference_watchdog().run(
+                SPARSE, lambda: asyncio.to_thread(self.splade_encoder.encode_single, analyze(query, query=True))
+            )
         
         # Search Qdrant (Network/IO bound but client is sync)
-        results = await asyncio.to_thread(
+        results = await qdrant_call(
              qdrant.query_points,
-             collection_name=settings.COLLECTION_PREFIX,
+             collection_name=collection_name or settings.COLLECTION_PREFIX,
              query=SparseVector(
                  indices=sparse_vec.indices,
                  values=sparse_vec.values
@@ -309,6 +620,7 @@ class MultiRetriever:
              query_filter=search_filter,
              with_payload=True
         )
+        record_usage("qdrant_reads", len(results.points))
         
         return [
             {
@@ -320,26 +632,70 @@ class MultiRetriever:
             for point in results.points
         ]
     
+    async def _search_bm25_sparse(
+        self,
+        query: str,
+        qdrant,
+        limit: int,
+        org_id: str
+    ) -> List[Dict]:
+        """Search the store's BM25 inverted index, then load payloads from Qdrant."""
+        from src.services.retrieval.bm25_index import get_bm25_index
+
+        analyzed = analyze(query, query=True, split_identifiers=False)
+        scored = await asyncio.to_thread(get_bm25_index().search, org_id, analyzed, limit)
+        if not scored:
+            return []
+
+        points = await qdrant_call(
+            qdrant.retrieve,
+            collection_name=store_collection(org_id),
+            ids=[chunk_id for chunk_id, _ in scored],
+            with_payload=True
+        )
+        record_usage("qdrant_reads", len(points))
+        payloads = {str(p.id): p.payload for p in points}
+
+        # Chunks demoted to the cold tier are no longer in the hot collection
+        return [
+            {
+                "chunk_id": chunk_id,
+                "score": score,
+                "text": payloads[chunk_id].get("text", ""),
+                **payloads[chunk_id]
+            }
+            for chunk_id, score in scored
+            if chunk_id in payloads
+        ]
+
     async def _search_bm42(
         self,
         query: str,
         qdrant,
         limit: int,
-        search_filter: Optional[Filter]
+        search_filter: Optional[Filter],
+        collection_name: Optional[str] = None,
+        encoded: Optional[Dict[str, Any]] = None
     ) -> List[Dict]:
         """Search using BM42 hybrid (Async)."""
         # Generate query representations
         # embed_texts_async is async
-        embeddings_list = await embed_texts_async([query])
-        dense_vec = embeddings_list[0]
+        dense_vec = (encoded or {}).get("dense")
+        if dense_vec is None:
+            embeddings_list = await embed_texts_async([query])
+            dense_vec = embeddings_list[0]
         
         # Encode sparse (CPU bound)
-        bm42_sparse = await asyncio.to_thread(self.bm42_encoder.encode_single, query)
+        bm42_sparse = (encoded or {}).get("bm42")
+        if bm42_sparse is None:
+            bm42_sparse = await get_inference_watchdog().run(
+                SPARSE, lambda: asyncio.to_thread(self.bm42_encoder.encode_single, analyze(query, query=True))
+            )
         
         # Hybrid search with RRF fusion
-        results = await asyncio.to_thread(
+        results = await qdrant_call(
             qdrant.query_points,
-            collection_name=settings.COLLECTION_PREFIX,
+            collection_name=collection_name or settings.COLLECTION_PREFIX,
             prefetch=[
                 Prefetch(
                     query=dense_vec,
@@ -361,6 +717,7 @@ class MultiRetriever:
             limit=limit,
             with_payload=True
         )
+        record_usage("qdrant_reads", len(results.points))
         
         return [
             {
@@ -372,6 +729,88 @@ class MultiRetriever:
             for point in results.points
         ]
     
+    async def _search_doc(
+        self,
+        query: str,
+        qdrant,
+        limit: int,
+        search_filter: Optional[Filter],
+        collection_name: str,
+        encoded: Optional[Dict[str, Any]] = None
+    ) -> List[Dict]:
+        """Search the chunks' docstring/comment vectors (Async)."""
+        dense_vec = (encoded or {}).get("dense")
+        if dense_vec is None:
+            dense_vec = (await embed_texts_async([query]))[0]
+        results = await qdrant_call(
+            qdrant.query_points,
+            collection_name=collection_name,
+            query=dense_vec,
+            using=DOC_VECTOR,
+            limit=limit,
+            query_filter=search_filter,
+            with_payload=True
+        )
+        record_usage("qdrant_reads", len(results.points))
+        return [
+            {
+                "chunk_id": str(point.id),
+                "score": point.score,
+                "text": point.payload.get("text", ""),
+                **point.payload
+            }
+            for point in results.points
+        ]
+
+    async def _search_dense_model(
+        self,
+        query: str,
+        qdrant,
+        limit: int,
+        search_filter: Optional[Filter],
+        collection_name: str,
+        target: Dict[str, Any],
+    ) -> List[Dict]:
+        """Dense search with a requested model's vectors (Async)."""
+        from src.services.inference import get_inference_client
+        vector = (await get_inference_watchdog().run(
+            EMBED, lambda: get_inference_client().embed([query], target["model"])
+        ))[0]
+        response = await qdrant_call(
+            qdrant.query_points,
+            collection_name=target["collection"],
+            query=vector,
+            using=target["vector"],
+            limit=limit,
+            query_filter=search_filter,
+            with_payload=target["collection"] == collection_name,
+        )
+        points = response.points
+        record_usage("qdrant_reads", len(points))
+        if not points:
+            return []
+
+        payloads = {str(p.id): p.payload for p in points if p.payload}
+        if target["collection"] != collection_name:
+            # Vector-only collection: payloads live with the store's chunks
+            stored = await qdrant_call(
+                qdrant.retrieve,
+                collection_name=collection_name,
+                ids=[p.id for p in points],
+                with_payload=True,
+            )
+            payloads = {str(p.id): p.payload for p in stored}
+        return [
+            {
+                "chunk_id": str(point.id),
+                "score": point.score,
+                "text": payloads[str(point.id)].get("text", ""),
+                **payloads[str(point.id)],
+            }
+            for point in points
+            if str(point.id) in payloads
+        ]
+
     def _format_results(self, fused_results: List[FusedResult]) -> List[Dict]:
         """
         Convert FusedResult objects to output dicts.
@@ -451,13 +890,90 @@ class Retriever:
         use_bm25: bool = True,
         use_splade: bool = True,
         use_bm42: bool = True,
+        explain: bool = False,
+        rrf_k: int = None,
+        weights: Optional[Dict[str, float]] = None,
+        filters: Optional[SearchFilters] = None,
+        timeout: Optional[float] = None,
+        use_cache: bool = True,
+        include_content: bool = True,
+        model_id: Optional[str] = None,
+        rerank_model_id: Optional[str] = None,
+        vector_weights: Optional[Dict[str, float]] = None,
     ) -> List[Dict]:
         """
         Search using dense or hybrid mode with optional reranking.
+
+        The search runs under a deadline (``timeout`` seconds, or the
+        ``search.timeout`` default) and raises ``SearchTimeoutError`` past it.
+        Repeated searches are served from the query cache until the store
+        is written to or the entry expires (``use_cache=False`` to bypass);
+        degraded results (a dependency's breaker was open) are not cached.
+        Chunk text kept in the content store is read for the returned
+        results unless ``include_content`` is False. ``model_id`` and
+        ``rerank_model_id`` pick other models for this query (ValueError
+        if the store can't use them); ``vector_weights`` blends code and
+        doc vectors.
         """
+        cache = get_query_cache()
+        generations = cache.generations(org_id) if use_cache else None
+        key = cache_key(
+            org_id, query, filters,
+            limit=limit, hybrid=hybrid, rerank=rerank, analyze_query=analyze_query,
+            use_bm25=use_bm25, use_splade=use_splade, use_bm42=use_bm42,
+            explain=explain, rrf_k=rrf_k, weights=weights,
+            model_id=model_id, rerank_model_id=rerank_model_id, vector_weights=vector_weights,
+        )
+        results = cache.get(key, generations)
+        if results is None:
+            with degradation.track() as notes:
+                noted = len(notes)
+                results = await with_deadline(
+                    Retriever._search(
+                        query, limit, org_id, hybrid, rerank, analyze_query,
+                        use_bm25, use_splade, use_bm42, explain, rrf_k, weights, filters,
+                        model_id, rerank_model_id, vector_weights,
+                    ),
+                    resolve_timeout(timeout)
+                )
+                # Degraded results would outlive the outage in the cache
+                if len(notes) == noted:
+                    cache.put(key, results, generations)
+        if include_content:
+            results = await asyncio.to_thread(hydrate, results)
+        return results
+
+    @staticmethod
+    async def _search(
+        query: str,
+        limit: int,
+        org_id: str,
+        hybrid: Optional[bool],
+        rerank: Optional[bool],
+        analyze_query: Optional[bool],
+        use_bm25: bool,
+        use_splade: bool,
+        use_bm42: bool,
+        explain: bool,
+        rrf_k: Optional[int],
+        weights: Optional[Dict[str, float]],
+        filters: Optional[SearchFilters],
+        model_id: Optional[str] = None,
+        rerank_model_id: Optional[str] = None,
+        vector_weights: Optional[Dict[str, float]] = None,
+    ) -> List[Dict]:
         # Legacy hybrid flag maps to SPLADE
         if hybrid is not None:
             use_splade = hybrid
+
+        # LLM query expansion (only when explicitly requested)
+        if analyze_query and settings.get("search.query_analysis.use_llm", False):
+            from src.services.search.query_analyzer import analyze_for_search
+            try:
+                analysis = await analyze_for_search(query)
+                query = analysis.processed_query
+            except Exception as e:
+                logger.warning(f"Query analysis failed, using raw query: {e}")
         
         retriever = Retriever.get_multi_retriever()
         return await retriever.search(
@@ -468,4 +984,37 @@ class Retriever:
             use_splade=use_splade,
             use_bm42=use_bm42,
             rerank=rerank,
+            rrf_k=rrf_k,
+            explain=explain,
+            weights=weights,
+            filters=filters,
+            model_id=model_id,
+            rerank_model_id=rerank_model_id,
+            vector_weights=vector_weights,
         )
+
+    @staticmethod
+    async def search_batch(
+        queries: List[str],
+        limit: int = 5,
+        org_id: str = "public",
+        filters: Optional[List[Optional[SearchFilters]]] = None,
+        timeout: Optional[float] = None,
+        **options: Any,
+    ) -> List[Any]:
+        """
+        Search many queries against one store with shared query encoding.
+
+        No LLM query analysis; results are per query, in order (an
+        exception in place of a query's results means it failed, e.g.
+        ``SearchTimeoutError`` past the per-query deadline).
+        """
+        retriever = Retriever.get_multi_retriever()
+        outcomes = await retriever.search_batch(
+            queries, limit=limit, org_id=org_id, filters=filters,
+            timeout=resolve_timeout(timeout), **options
+        )
+        return [
+            outcome if isinstance(outcome, Exception) else await asyncio.to_thread(hydrate, outcome)
+            for outcome in outcomes
+        ]
@@ -0,0 +1,162 @@
+"""
+Streaming Search.
+
+A buffered search answers once reranking has finished, so a client that
+only reads the first few results still pays for reranking all of them.
+A search stream answers in stages, one JSON object per line:
+
+1. ``candidates``: fused results (filters and cold-tier fallback applied),
+   before reranking
+2. ``rerank``: score patches (``{"chunk_id", "rerank_score"}``) for
+   ``search.stream.rerank_batch`` candidates at a time, best fused first
+3. ``done``: the final order and how many candidates were reranked
+
+When the client disconnects the stream stops between batches and the
+rerank call in flight is cancelled, so no further ML work is done.
+"""
+
+import asyncio
+import json
+import logging
+import time
+from typing import Any, AsyncIterator, Awaitable, Callable, Dict, List, Optional
+
+from fastapi import HTTPException
+
+from src.core.config import settings
+
+logger = logging.getLogger(__name__)
+
+
+def rerank_enabled(org_id: str) -> bool:
+    """Whether searches of a store are reranked (store default, then settings)."""
+    from src.services.ingestion.privacy import is_private_store
+
+    # Privacy-mode stores have no text to rerank
+    if is_private_store(org_id):
+        return False
+    try:
+        from src.services.admin.admin_store import get_admin_store
+        config = get_admin_store().get_store_search_config(org_id)
+    except Exception as e:
+        logger.debug(f"No store search config for {org_id}: {e}")
+        config = {}
+    return bool(config.get("rerank", settings.RERANK_ENABLED))
+
+
+class SearchStream:
+    """One streamed search: candidates first, then rerank score patches."""
+
+    def __init__(
+        self,
+        search: Callable[[], Awaitable[List[Dict[str, Any]]]],
+        query: str,
+        rerank: bool = True,
+        present: Optional[Callable[[Dict[str, Any]], Dict[str, Any]]] = None,
+        is_disconnected: Optional[Callable[[], Awaitable[bool]]] = None,
+        rerank_batch: Optional[int] = None,
+        scorer: Optional[Callable[[str, List[str]], Awaitable[List[float]]]] = None,
+    ):
+        """
+        Args:
+            search: Runs the search without reranking (results carry text)
+            query: Query the candidates are reranked against
+            rerank: Stream rerank patches after the candidates
+            present: Shapes candidates for the client (e.g. previews)
+            is_disconnected: Checked before every rerank batch
+            rerank_batch: Candidates per rerank call (default: search.stream.rerank_batch)
+            scorer: Reranker (default: src.services.search.reranker.rerank_results)
+        """
+        self.search = search
+        self.query = query
+        self.rerank = rerank
+        self.present = present or (lambda result: result)
+        self.is_disconnected = is_disconnected
+        self.rerank_batch = max(1, int(rerank_batch or settings.get("search.stream.rerank_batch", 8)))
+        self.scorer = scorer
+        self.cancelled = False
+        self.count = 0
+
+    async def messages(self) -> AsyncIterator[Dict[str, Any]]:
+        """Stream messages; errors end the stream with an ``error`` message."""
+        started = time.perf_counter()
+        try:
+            results = await self.search()
+        except asyncio.CancelledError:
+            raise
+        except Exception as e:
+            yield self._error(e)
+            return
+
+        self.count = len(results)
+        yield {"type": "candidates", "count": len(results), "results": [self.present(r) for r in results]}
+
+        reranked: List[Dict[str, Any]] = []
+        remainder = results
+        if self.rerank and results:
+            # Results may be shared with the query cache; score copies
+            results = [dict(r) for r in results]
+            remainder = results
+            async for patch in self._rerank(results, reranked):
+                yield patch
+            if self.cancelled:
+                return
+            reranked_ids = {id(r) for r in reranked}
+            remainder = [r for r in results if id(r) not in reranked_ids]
+
+        ordered = sorted(reranked, key=lambda r: r.get("rerank_score", 0), reverse=True) + remainder
+        yield {
+            "type": "done",
+            "order": [r.get("chunk_id") for r in ordered],
+            "reranked": len(reranked),
+            "took_ms": round((time.perf_counter() - started) * 1000, 1),
+        }
+
+    async def _rerank(self, results: List[Dict[str, Any]], reranked: List[Dict[str, Any]]) -> AsyncIterator[Dict[str, Any]]:
+        """Score patches per batch; reranked candidates are appended to ``reranked``."""
+        from src.services.inference.watchdog import InferenceTimeoutError
+        from src.services.search.rerank_sampling import sample_candidates
+
+        scorer = self.scorer
+        if scorer is None:
+            from src.services.search.reranker import rerank_results as scorer
+
+        candidates, _ = sample_candidates(results)
+        for start in range(0, len(candidates), self.rerank_batch):
+            if self.is_disconnected and await self.is_disconnected():
+                self.cancelled = True
+                logger.info(f"Search stream closed by client after reranking {start}/{len(candidates)}")
+                return
+            batch = candidates[start:start + self.rerank_batch]
+            try:
+                scores = await scorer(self.query, [r.get("text", "") for r in batch])
+            except InferenceTimeoutError as e:
+                # Keep fused order for the rest rather than failing the stream
+                logger.warning(f"Stopping streamed rerank: {e}")
+                return
+            patches = []
+            for result, score in zip(batch, scores):
+                result["rerank_score"] = score
+                reranked.append(result)
+                patches.append({"chunk_id": result.get("chunk_id"), "rerank_score": score})
+            yield {"type": "rerank", "patches": patches, "reranked": len(reranked), "total": len(candidates)}
+
+    async def ndjson(self) -> AsyncIterator[bytes]:
+        """Messages as NDJSON lines (for a StreamingResponse)."""
+        try:
+            async for message in self.messages():
+                yield (json.dumps(message, default=str) + "\n").encode()
+        except asyncio.CancelledError:
+            self.cancelled = True
+            logger.info("Search stream cancelled by client")
+            raise
+
+    @staticmethod
+    def _error(e: Exception) -> Dict[str, Any]:
+        if isinstance(e, HTTPException):
+            return {"type": "error", "status": e.status_code, "detail": e.detail}
+        from src.services.search.deadline import SearchTimeoutError
+        if isinstance(e, SearchTimeoutError):
+            return {"type": "error", "status": 504, "detail": e.to_dict()}
+        logger.warning(f"Streamed search failed: {e}")
+        return {"type": "error", "status": 500, "detail": str(e)}
@@ -0,0 +1,289 @@
+"""
+Query Suggestions.
+
+Typo correction and search-box autocomplete from the words a store
+actually contains. Each store has a vocabulary in Redis
+(``rice:suggest:<store>``, term -> number of chunks using it) built from
+chunk text, symbol names and file names with the BM25 tokenizer, so
+``HandleFunc`` contributes ``handlefunc``, ``handle`` and ``func``.
+
+Indexing adds every new chunk's terms. Counts only grow (re-indexed and
+deleted chunks are not subtracted); ``rebuild`` recounts the store from
+Qdrant and keeps the ``search.suggest.max_terms`` most used terms. Each
+process caches a store's vocabulary for ``search.suggest.cache_seconds``.
+
+- ``complete``: terms starting with the last typed word, most used first,
+  plus matching symbol names
+- ``correct``: replaces words the store doesn't contain with the most used
+  term within ``search.suggest.max_distance`` edits (insertions, deletions,
+  substitutions and swapped neighbours: "authetnication" ->
+  "authentication"). Filter tokens (``lang:go``, ``-vendor``, phrases) are
+  left alone
+"""
+
+import bisect
+import logging
+import re
+import threading
+import time
+from collections import Counter
+from typing import Any, Dict, Iterable, List, Optional, Tuple
+
+import redis
+from qdrant_client.models import FieldCondition, Filter, MatchValue
+
+from src.core.config import settings
+from src.db.content_store import payload_text
+from src.services.retrieval.bm25_index import tokenize
+
+logger = logging.getLogger(__name__)
+
+# Vocabulary terms: identifiers and their parts, not numbers or hashes
+_TERM = re.compile(r"^[a-z][a-z0-9_]{2,39}$")
+_WORD = re.compile(r"^[A-Za-z]+$")
+_LETTERS = "abcdefghijklmnopqrstuvwxyz"
+
+PAYLOAD_FIELDS = ["text", "content_ref", "symbols", "filename"]
+
+
+def _setting(name: str, default):
+    return type(default)(settings.get(f"search.suggest.{name}", default))
+
+
+def chunk_terms(payload: Dict[str, Any]) -> List[str]:
+    """Distinct vocabulary terms of one chunk payload."""
+    parts = [payload_text(payload), payload.get("filename") or "", *(payload.get("symbols") or [])]
+    return sorted({t for t in tokenize(" ".join(parts), 3) if _TERM.match(t)})
+
+
+def edit_distance(a: str, b: str) -> int:
+    """Damerau-Levenshtein distance (optimal string alignment)."""
+    rows = [list(range(len(b) + 1))]
+    for i in range(1, len(a) + 1):
+        row = [i] + [0] * len(b)
+        for j in range(1, len(b) + 1):
+            cost = 0 if a[i - 1] == b[j - 1] else 1
+            row[j] = min(rows[-1][j] + 1, row[j - 1] + 1, rows[-1][j - 1] + cost)
+            if i > 1 and j > 1 and a[i - 1] == b[j - 2] and a[i - 2] == b[j - 1]:
+                row[j] = min(row[j], rows[-2][j - 2] + 1)
+        rows.append(row)
+    return rows[-1][-1]
+
+
+def edits1(word: str) -> set:
+    """Every string one edit away."""
+    splits = [(word[:i], word[i:]) for i in range(len(word) + 1)]
+    return (
+        {a + b[1:] for a, b in splits if b}
+        | {a + b[1] + b[0] + b[2:] for a, b in splits if len(b) > 1}
+        | {a + c + b[1:] for a, b in splits if b for c in _LETTERS}
+        | {a + c + b for a, b in splits for c in _LETTERS}
+    )
+
+
+def _match_case(original: str, word: str) -> str:
+    if original.isupper():
+        return word.upper()
+    if original[:1].isupper():
+        return word.capitalize()
+    return word
+
+
+class Vocabulary:
+    """One store's terms, sorted for prefix lookups."""
+
+    def __init__(self, counts: Dict[str, int]):
+        self.counts = counts
+        self.terms = sorted(counts)
+
+    def __len__(self) -> int:
+        return len(self.terms)
+
+    def with_prefix(self, prefix: str, limit: Optional[int] = None) -> List[str]:
+        start = bisect.bisect_left(self.terms, prefix)
+        end = bisect.bisect_left(self.terms, prefix + "\uffff")
+        if limit is not None:
+            end = min(end, start + limit)
+        return self.terms[start:end]
+
+    def closest(self, word: str, max_distance: int, scan_limit: int, min_count: int) -> Optional[Tuple[str, int]]:
+        """Most used known term within ``max_distance`` edits, preferring fewer edits."""
+        known = [(t, self.counts[t]) for t in edits1(word) if self.counts.get(t, 0) >= min_count]
+        if known:
+            return max(known, key=lambda tc: (tc[1], tc[0]))
+        if max_distance < 2:
+            return None
+        # Typos rarely change the first letter; scan terms sharing it
+        best = None
+        for term in self.with_prefix(word[0], scan_limit):
+            count = self.counts[term]
+            if count < min_count or abs(len(term) - len(word)) > max_distance:
+                continue
+            distance = edit_distance(word, term)
+            if distance <= max_distance and (best is None or (distance, -count) < (best[2], -best[1])):
+                best = (term, count, distance)
+        return (best[0], best[1]) if best else None
+
+
+class QuerySuggester:
+    """Per-store vocabularies for autocomplete and typo correction."""
+
+    PREFIX = "rice:suggest"
+
+    def __init__(self, redis_client=None, qdrant_client=None):
+        self._redis = redis_client
+        self._qdrant = qdrant_client
+        self._lock = threading.Lock()
+        self._cache: Dict[str, Tuple[float, Vocabulary]] = {}
+
+    @property
+    def redis(self) -> redis.Redis:
+        """Lazy Redis connection."""
+        if self._redis is None:
+            self._redis = redis.from_url(settings.REDIS_URL, decode_responses=True)
+        return self._redis
+
+    @property
+    def qdrant(self):
+        """Lazy Qdrant client."""
+        if self._qdrant is None:
+            from src.db.qdrant import get_qdrant_client
+            self._qdrant = get_qdrant_client()
+        return self._qdrant
+
+    def _key(self, store_id: str) -> str:
+        return f"{self.PREFIX}:{store_id}"
+
+    def add(self, store_id: str, payloads: Iterable[Dict[str, Any]]) -> int:
+        """
+        Count newly indexed chunks' terms.
+
+        Returns:
+            Number of distinct terms touched
+        """
+        counts = Counter()
+        for payload in payloads:
+            counts.update(chunk_terms(payload))
+        if not counts:
+            return 0
+        pipe = self.redis.pipeline()
+        for term, count in counts.items():
+            pipe.hincrby(self._key(store_id), term, count)
+        pipe.execute()
+        return len(counts)
+
+    def vocabulary(self, store_id: str) -> Vocabulary:
+        ttl = _setting("cache_seconds", 60)
+        with self._lock:
+            cached = self._cache.get(store_id)
+            if cached and time.monotonic() - cached[0] < ttl:
+                return cached[1]
+        raw = self.redis.hgetall(self._key(store_id)) or {}
+        vocabulary = Vocabulary({term: int(count) for term, count in raw.items()})
+        with self._lock:
+            self._cache[store_id] = (time.monotonic(), vocabulary)
+        return vocabulary
+
+    def rebuild(self, store_id: str) -> Dict[str, Any]:
+        """Recount a store's vocabulary from its indexed chunks."""
+        from src.services.ingestion.migration import store_collection
+
+        max_chunks = _setting("rebuild_max_chunks", 200000)
+        store_filter = Filter(must=[FieldCondition(key="org_id", match=MatchValue(value=store_id))])
+        counts = Counter()
+        offset = None
+        scanned = 0
+        while scanned < max_chunks:
+            points, offset = self.qdrant.scroll(
+                collection_name=store_collection(store_id),
+                scroll_filter=store_filter,
+                limit=min(256, max_chunks - scanned),
+                offset=offset,
+                with_payload=PAYLOAD_FIELDS,
+                with_vectors=False,
+            )
+            for point in points:
+                counts.update(chunk_terms(point.payload or {}))
+            scanned += len(points)
+            if offset is None or not points:
+                break
+
+        kept = dict(counts.most_common(_setting("max_terms", 100000)))
+        key = self._key(store_id)
+        pipe = self.redis.pipeline()
+        pipe.delete(key)
+        if kept:
+            pipe.hset(key, mapping=kept)
+        pipe.execute()
+        self.invalidate(store_id)
+        logger.info(f"Rebuilt suggestion vocabulary for {store_id}: {len(kept)} terms from {scanned} chunks")
+        return {"store": store_id, "chunks": scanned, "terms": len(kept), "truncated": scanned >= max_chunks}
+
+    def invalidate(self, store_id: str):
+        with self._lock:
+            self._cache.pop(store_id, None)
+
+    def drop(self, store_id: str):
+        """Forget a store's vocabulary (store purge)."""
+        self.redis.delete(self._key(store_id))
+        self.invalidate(store_id)
+
+    def complete(self, store_id: str, prefix: str, limit: int = 10) -> List[Dict[str, Any]]:
+        """Known terms starting with ``prefix``, most used first."""
+        prefix = prefix.lower()
+        if len(prefix) < _setting("min_prefix_length", 2):
+            return []
+        vocabulary = self.vocabulary(store_id)
+        matches = vocabulary.with_prefix(prefix, _setting("scan_limit", 2000))
+        matches.sort(key=lambda t: (-vocabulary.counts[t], t))
+        return [{"text": t, "kind": "term", "count": vocabulary.counts[t]} for t in matches[:limit]]
+
+    def correct(self, store_id: str, query: str) -> Dict[str, Any]:
+        """
+        Spelling corrections for a query.
+
+        Returns:
+            {"suggestion": corrected query or None, "corrections":
+            [{"word", "suggestion", "count"}]}
+        """
+        vocabulary = self.vocabulary(store_id)
+        min_length = _setting("min_word_length", 4)
+        corrections = []
+        words = []
+        for word in query.split(" "):
+            lowered = word.lower()
+            if (
+                not vocabulary
+                or len(word) < min_length
+                or not _WORD.match(word)
+                or lowered in vocabulary.counts
+            ):
+                words.append(word)
+                continue
+            match = vocabulary.closest(
+                lowered,
+                _setting("max_distance", 2),
+                _setting("scan_limit", 2000),
+                _setting("min_count", 2),
+            )
+            if not match:
+                words.append(word)
+                continue
+            replacement = _match_case(word, match[0])
+            corrections.append({"word": word, "suggestion": replacement, "count": match[1]})
+            words.append(replacement)
+        return {
+            "suggestion": " ".join(words) if corrections else None,
+            "corrections": corrections,
+        }
+
+
+# Singleton instance
+_suggester: Optional[QuerySuggester] = None
+
+def get_query_suggester() -> QuerySuggester:
+    """Get global query suggester instance."""
+    global _suggester
+    if _suggester is None:
+        _suggester = QuerySuggester()
+    return _suggester
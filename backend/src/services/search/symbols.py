@@ -0,0 +1,79 @@
+"""
+Symbol Autocomplete.
+
+Suggests function/class names indexed in a store so the search bar can
+complete ``symbol:`` filters. Names come from a Qdrant facet over the
+keyword-indexed ``symbols`` payload field, most common first.
+"""
+
+import logging
+from typing import Dict, List, Optional
+
+from src.core.config import settings
+from src.services.ingestion.migration import store_collection
+from src.services.search.filters import build_filter
+
+logger = logging.getLogger(__name__)
+
+
+def matches_prefix(symbol: str, prefix: str) -> bool:
+    """Case-insensitive prefix match on the name or any dotted part (``Config.parse``)."""
+    if not prefix:
+        return True
+    prefix = prefix.lower()
+    return any(part.lower().startswith(prefix) for part in [symbol, *symbol.split(".")[1:]])
+
+
+class SymbolSuggester:
+    """Looks up known symbols for a store."""
+
+    def __init__(self, qdrant_client=None):
+        self._qdrant = qdrant_client
+
+    @property
+    def qdrant(self):
+        """Lazy Qdrant client."""
+        if self._qdrant is None:
+            from src.db.qdrant import get_qdrant_client
+            self._qdrant = get_qdrant_client()
+        return self._qdrant
+
+    @property
+    def facet_limit(self) -> int:
+        return int(settings.get("search.symbols.facet_limit", 10000))
+
+    def suggest(self, org_id: str, prefix: str = "", limit: int = 20) -> List[Dict]:
+        """
+        Symbols in a store starting with a prefix.
+
+        Args:
+            org_id: Store
+            prefix: Typed text (matches the full name or a dotted part)
+            limit: Maximum suggestions
+
+        Returns:
+            List of {"symbol", "chunks"}, most common first
+        """
+        response = self.qdrant.facet(
+            collection_name=store_collection(org_id),
+            key="symbols",
+            facet_filter=build_filter(org_id),
+            limit=self.facet_limit,
+            exact=False,
+        )
+        hits = [h for h in response.hits if matches_prefix(str(h.value), prefix)]
+        # Names that start with the prefix beat dotted-part matches
+        lowered = prefix.lower()
+        hits.sort(key=lambda h: (not str(h.value).lower().startswith(lowered), -h.count, str(h.value)))
+        return [{"symbol": str(h.value), "chunks": h.count} for h in hits[:limit]]
+
+
+# Singleton instance
+_suggester: Optional[SymbolSuggester] = None
+
+def get_symbol_suggester() -> SymbolSuggester:
+    """Get global symbol suggester instance."""
+    global _suggester
+    if _suggester is None:
+        _suggester = SymbolSuggester()
+    return _suggester
@@ -0,0 +1,457 @@
+"""
+Hot/Cold Tier Management.
+
+Chunks that have not been matched by a search or re-indexed within
+``search.tiering.cold_after_days`` are moved out of the hot collection into
+a cold collection whose vectors live on disk with int8 scalar quantization.
+
+The cold tier is only queried when the hot tier returns fewer than
+``search.tiering.min_hot_results`` results. Cold chunks that get matched are
+queued for promotion and moved back to the hot tier on the next maintenance run.
+
+Stores migrated to their own collection (see migration) have their own
+cold collection, ``<collection><cold_suffix>``, since their vectors may
+have another dimension than the shared collection's.
+"""
+
+import time
+import logging
+from typing import Dict, List, Optional, Any
+
+import redis
+from qdrant_client.models import (
+    PointStruct,
+    PointIdsList,
+    VectorParams,
+    SparseVectorParams,
+    SparseIndexParams,
+    Distance,
+    ScalarQuantization,
+    ScalarQuantizationConfig,
+    ScalarType,
+    Filter,
+    FieldCondition,
+    MatchValue,
+)
+
+from src.core.config import settings
+from src.db.content_store import payload_text
+from src.services.ingestion.doc_vectors import DOC_VECTOR, fit_points
+from src.services.ingestion.migration import collection_dimension, store_collection, store_collections
+
+logger = logging.getLogger(__name__)
+
+SECONDS_PER_DAY = 86400
+
+
+class TierManager:
+    """
+    Moves chunks between the hot and cold Qdrant collections.
+
+    Last-match timestamps are kept in a Redis hash so search stays cheap;
+    the index time is stored in the point payload as ``indexed_at``.
+    """
+
+    HITS_KEY = "rice:tiering:last_hit"
+    PROMOTE_KEY = "rice:tiering:promote"
+    STATS_KEY = "rice:tiering:last_run"
+
+    def __init__(self, qdrant_client=None):
+        self._qdrant = qdrant_client
+        self._redis: Optional[redis.Redis] = None
+
+    @property
+    def redis(self) -> redis.Redis:
+        """Lazy Redis connection."""
+        if self._redis is None:
+            self._redis = redis.from_url(settings.REDIS_URL, decode_responses=True)
+        return self._redis
+
+    @property
+    def qdrant(self):
+        """Lazy Qdrant client."""
+        if self._qdrant is None:
+            from src.db.qdrant import get_qdrant_client
+            self._qdrant = get_qdrant_client()
+        return self._qdrant
+
+    @property
+    def enabled(self) -> bool:
+        return bool(settings.get("search.tiering.enabled", False))
+
+    @property
+    def cold_after_days(self) -> float:
+        return float(settings.get("search.tiering.cold_after_days", 30))
+
+    @property
+    def min_hot_results(self) -> int:
+        return int(settings.get("search.tiering.min_hot_results", 3))
+
+    @property
+    def batch_size(self) -> int:
+        return int(settings.get("search.tiering.batch_size", 256))
+
+    @property
+    def hot_collection(self) -> str:
+        return settings.COLLECTION_PREFIX
+
+    @property
+    def cold_collection(self) -> str:
+        return get_cold_collection_name()
+
+    def hot_collections(self, org_id: Optional[str] = None) -> List[str]:
+        """Hot collections to maintain: a store's, or the shared one and every migrated store's."""
+        return store_collections(org_id)
+
+    # ============== Decisions ==============
+
+    @staticmethod
+    def last_activity(payload: Dict[str, Any], last_hit: Optional[float] = None) -> Optional[float]:
+        """
+        Most recent of index time and last search match (epoch seconds).
+
+        Points indexed before tiering existed have no ``indexed_at`` and are
+        never considered stale until they are re-indexed or matched.
+        """
+        indexed_at = payload.get("indexed_at")
+        candidates = [float(t) for t in (indexed_at, last_hit) if t is not None]
+        return max(candidates) if candidates else None
+
+    @staticmethod
+    def is_stale(last_active: Optional[float], cold_after_days: float, now: float = None) -> bool:
+        """Check whether a chunk has been idle longer than the cold threshold."""
+        if last_active is None:
+            return False
+        now = now if now is not None else time.time()
+        return (now - last_active) > cold_after_days * SECONDS_PER_DAY
+
+    def should_query_cold(self, hot_count: int, limit: int) -> bool:
+        """Cold tier is searched only when the hot tier comes back thin."""
+        if not self.enabled:
+            return False
+        return hot_count < min(self.min_hot_results, limit)
+
+    # ============== Search Hooks ==============
+
+    def record_hits(self, chunk_ids: List[str]):
+        """Record that chunks were returned by a search."""
+        if not chunk_ids:
+            return
+        try:
+            now = time.time()
+            self.redis.hset(self.HITS_KEY, mapping={cid: now for cid in chunk_ids})
+        except Exception as e:
+            logger.warning(f"Failed to record tier hits: {e}")
+
+    def queue_promotion(self, chunk_ids: List[str]):
+        """Queue matched cold chunks to move back to the hot tier."""
+        if not chunk_ids:
+            return
+        try:
+            self.redis.sadd(self.PROMOTE_KEY, *chunk_ids)
+        except Exception as e:
+            logger.warning(f"Failed to queue tier promotion: {e}")
+
+    # ============== Collections ==============
+
+    def ensure_cold_collection(self, hot_collection: Optional[str] = None):
+        """
+        Create the cold collection of a hot collection (default: the shared
+        one) if missing: on-disk vectors, int8 quantized.
+        """
+        cold_collection = cold_collection_for(hot_collection) if hot_collection else self.cold_collection
+        try:
+            self.qdrant.get_collection(cold_collection)
+        except Exception:
+            logger.info(f"Creating cold tier collection {cold_collection}")
+            embedding_dim = (
+                (hot_collection and collection_dimension(self.qdrant, hot_collection))
+                or settings.get("models.embedding.fallback_dimension", settings.EMBEDDING_DIM)
+            )
+            self.qdrant.create_collection(
+                collection_name=cold_collection,
+                vectors_config={
+                    "dense": VectorParams(
+                        size=embedding_dim,
+                        distance=Distance.COSINE,
+                        on_disk=True
+                    ),
+                    DOC_VECTOR: VectorParams(
+                        size=embedding_dim,
+                        distance=Distance.COSINE,
+                        on_disk=True
+                    )
+                },
+                sparse_vectors_config={
+                    "splade": SparseVectorParams(
+                        index=SparseIndexParams(on_disk=True)
+                    ),
+                    "bm42": SparseVectorParams(
+                        index=SparseIndexParams(on_disk=True)
+                    )
+                },
+                quantization_config=ScalarQuantization(
+                    scalar=ScalarQuantizationConfig(
+                        type=ScalarType.INT8,
+                        always_ram=False
+                    )
+                ),
+                on_disk_payload=True
+            )
+
+    def delete_file(self, full_path: str, org_id: str):
+        """Drop cold copies of a file so re-indexed content cannot resurface."""
+        self.delete_matching(
+            Filter(
+                must=[
+                    FieldCondition(key="full_path", match=MatchValue(value=full_path)),
+                    FieldCondition(key="org_id", match=MatchValue(value=org_id))
+                ]
+            ),
+            org_id,
+        )
+
+    def delete_matching(self, points_filter: Filter, org_id: Optional[str] = None):
+        """Drop cold copies matching a payload filter (also in the store's own cold collection)."""
+        for cold_collection in dict.fromkeys([self.cold_collection, store_cold_collection(org_id)]):
+            try:
+                self.qdrant.delete(
+                    collection_name=cold_collection,
+                    points_selector=points_filter
+                )
+            except Exception as e:
+                logger.debug(f"Cold tier delete in {cold_collection} skipped: {e}")
+
+    # ============== Maintenance ==============
+
+    def _move(self, points, source: str, target: str, tier: str) -> int:
+        """Copy points (with vectors) into target and remove them from source."""
+        if not points:
+            return 0
+        self.qdrant.upsert(
+            collection_name=target,
+            points=fit_points(self.qdrant, target, [
+                PointStruct(
+                    id=p.id,
+                    vector=p.vector,
+                    payload={**(p.payload or {}), "tier": tier}
+                )
+                for p in points
+            ])
+        )
+        self.qdrant.delete(
+            collection_name=source,
+            points_selector=PointIdsList(points=[p.id for p in points])
+        )
+        return len(points)
+
+    def _tantivy(self):
+        try:
+            from src.services.retrieval.tantivy_client import get_tantivy_client
+            return get_tantivy_client()
+        except Exception as e:
+            logger.warning(f"Tantivy client not available: {e}")
+            return None
+
+    def promote_pending(self) -> int:
+        """Move queued cold chunks back into their hot tier."""
+        try:
+            chunk_ids = list(self.redis.smembers(self.PROMOTE_KEY))
+        except Exception as e:
+            logger.warning(f"Failed to read promotion queue: {e}")
+            return 0
+        if not chunk_ids:
+            return 0
+
+        moved = 0
+        promoted = []
+        for hot_collection in self.hot_collections():
+            cold_collection = cold_collection_for(hot_collection)
+            try:
+                points = self.qdrant.retrieve(
+                    collection_name=cold_collection,
+                    ids=chunk_ids,
+                    with_payload=True,
+                    with_vectors=True
+                )
+            except Exception as e:
+                logger.debug(f"No promotions from {cold_collection}: {e}")
+                continue
+            moved += self._move(points, cold_collection, hot_collection, "hot")
+            promoted += points
+
+        # Cold chunks are dropped from BM25; put them back
+        tantivy = self._tantivy()
+        if tantivy and promoted:
+            try:
+                tantivy.batch_index([(str(p.id), payload_text(p.payload)) for p in promoted])
+            except Exception as e:
+                logger.warning(f"Tantivy re-index on promotion failed: {e}")
+
+        self.redis.srem(self.PROMOTE_KEY, *chunk_ids)
+        self.record_hits([str(p.id) for p in promoted])
+        return moved
+
+    def demote_stale(self, org_id: Optional[str] = None) -> Dict[str, int]:
+        """
+        Scan the hot collections and move idle chunks to their cold tier.
+
+        Args:
+            org_id: Restrict the scan to a single store
+
+        Returns:
+            Dict with scanned and demoted counts
+        """
+        scroll_filter = None
+        if org_id:
+            scroll_filter = Filter(
+                must=[FieldCondition(key="org_id", match=MatchValue(value=org_id))]
+            )
+
+        now = time.time()
+        threshold = self.cold_after_days
+        tantivy = self._tantivy()
+        scanned = 0
+        demoted = 0
+
+        for hot_collection in self.hot_collections(org_id):
+            if hot_collection == self.hot_collection:
+                self.ensure_cold_collection()
+            else:
+                self.ensure_cold_collection(hot_collection)
+            cold_collection = cold_collection_for(hot_collection)
+            offset = None
+            while True:
+                points, offset = self.qdrant.scroll(
+                    collection_name=hot_collection,
+                    scroll_filter=scroll_filter,
+                    limit=self.batch_size,
+                    offset=offset,
+                    with_payload=True,
+                    with_vectors=True
+                )
+                if not points:
+                    break
+                scanned += len(points)
+
+                ids = [str(p.id) for p in points]
+                hits = self.redis.hmget(self.HITS_KEY, ids)
+                stale = []
+                for point, hit in zip(points, hits):
+                    last_active = self.last_activity(point.payload or {}, float(hit) if hit else None)
+                    if self.is_stale(last_active, threshold, now):
+                        stale.append(point)
+
+                if stale:
+                    demoted += self._move(stale, hot_collection, cold_collection, "cold")
+                    stale_ids = [str(p.id) for p in stale]
+                    self.redis.hdel(self.HITS_KEY, *stale_ids)
+                    if tantivy:
+                        for cid in stale_ids:
+                            try:
+                                tantivy.delete(cid)
+                            except Exception as e:
+                                logger.warning(f"Failed to delete chunk {cid} from Tantivy: {e}")
+
+                if offset is None:
+                    break
+
+        return {"scanned": scanned, "demoted": demoted}
+
+    def run_maintenance(self, org_id: Optional[str] = None) -> Dict[str, Any]:
+        """Promote matched cold chunks, then demote idle hot chunks."""
+        promoted = self.promote_pending()
+        result = self.demote_stale(org_id)
+        stats = {
+            "promoted": promoted,
+            **result,
+            "finished_at": time.time(),
+        }
+        try:
+            import json
+            self.redis.set(self.STATS_KEY, json.dumps(stats))
+        except Exception as e:
+            logger.warning(f"Failed to store tiering stats: {e}")
+        logger.info(f"Tier maintenance: {stats}")
+        return stats
+
+    def get_status(self) -> Dict[str, Any]:
+        """Point counts per tier and the last maintenance run."""
+        import json
+
+        def _count(name: str) -> int:
+            try:
+                return self.qdrant.count(collection_name=name, exact=False).count
+            except Exception:
+                return 0
+
+        last_run = None
+        try:
+            data = self.redis.get(self.STATS_KEY)
+            last_run = json.loads(data) if data else None
+        except Exception as e:
+            logger.warning(f"Failed to read tiering stats: {e}")
+
+        return {
+            "enabled": self.enabled,
+            "cold_after_days": self.cold_after_days,
+            "min_hot_results": self.min_hot_results,
+            "hot_collection": self.hot_collection,
+            "cold_collection": self.cold_collection,
+            "hot_points": _count(self.hot_collection),
+            "cold_points": _count(self.cold_collection),
+            "pending_promotions": self._pending_count(),
+            "last_run": last_run,
+        }
+
+    def _pending_count(self) -> int:
+        try:
+            return self.redis.scard(self.PROMOTE_KEY)
+        except Exception:
+            return 0
+
+
+def get_cold_collection_name() -> str:
+    """Name of the cold tier collection."""
+    suffix = settings.get("search.tiering.cold_suffix", "_cold")
+    return f"{settings.COLLECTION_PREFIX}{suffix}"
+
+
+def cold_collection_for(hot_collection: str) -> str:
+    """Cold tier of a hot collection (the shared cold collection for the shared one)."""
+    if hot_collection == settings.COLLECTION_PREFIX:
+        return get_cold_collection_name()
+    return f"{hot_collection}{settings.get('search.tiering.cold_suffix', '_cold')}"
+
+
+def store_cold_collection(store_id: Optional[str]) -> str:
+    """Cold tier collection holding a store's idle chunks."""
+    return cold_collection_for(store_collection(store_id))
+
+
+def tier_collections(qdrant, store_id: Optional[str] = None) -> List[str]:
+    """
+    Collections to scan for a store's chunks (without a store: every
+    store's): the hot collections, each followed by its cold tier if present.
+    """
+    names = []
+    for hot_collection in store_collections(store_id):
+        names.append(hot_collection)
+        cold_collection = cold_collection_for(hot_collection)
+        try:
+            qdrant.get_collection(cold_collection)
+            names.append(cold_collection)
+        except Exception:
+            pass
+    return names
+
+
+# Singleton instance
+_tier_manager: Optional[TierManager] = None
+
+def get_tier_manager() -> TierManager:
+    """Get global tier manager instance."""
+    global _tier_manager
+    if _tier_manager is None:
+        _tier_manager = TierManager()
+    return _tier_manager
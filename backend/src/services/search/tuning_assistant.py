@@ -0,0 +1,193 @@
+"""
+Search Quality Tuning Assistant.
+
+Takes a few labeled query -> expected-file pairs for a store and grid-searches
+fusion weights, RRF k and reranking with the eval harness. The best
+configuration is proposed and can be applied to the store, where
+``MultiRetriever.search`` picks it up as the store's defaults.
+
+The grid comes from ``search.tuning_assistant``:
+
+- ``sparse_shares``: share of the fusion weight given to BM25/SPLADE
+  (the rest goes to BM42), weights sum to 2.0 like the click tuner
+- ``rrf_k``: RRF k values to try
+- ``rerank``: rerank on/off options
+"""
+
+import itertools
+import logging
+from datetime import datetime
+from typing import Any, Dict, List, Optional
+
+from src.core.config import settings
+from src.services.search.evaluation import run_eval
+from src.services.search.fusion_tuning import GROUPS, SPARSE
+
+logger = logging.getLogger(__name__)
+
+METRICS = ("ndcg", "mrr", "recall")
+
+
+def _as_list(value, cast) -> List:
+    if isinstance(value, str):
+        value = [v.strip() for v in value.split(",") if v.strip()]
+    if not isinstance(value, (list, tuple)):
+        value = [value]
+    return [cast(v) for v in value]
+
+
+def _as_bool(value) -> bool:
+    if isinstance(value, str):
+        return value.lower() in ("1", "true", "yes", "on")
+    return bool(value)
+
+
+def weights_for_share(share: float) -> Dict[str, float]:
+    """Per-retriever weights for a sparse share (sum of group weights is 2.0)."""
+    groups = {SPARSE: round(2.0 * share, 4)}
+    return {
+        retriever: groups.get(group, round(2.0 * (1.0 - share), 4))
+        for retriever, group in GROUPS.items()
+    }
+
+
+def candidate_grid() -> List[Dict[str, Any]]:
+    """Every configuration to evaluate."""
+    prefix = "search.tuning_assistant"
+    shares = _as_list(settings.get(f"{prefix}.sparse_shares", [0.3, 0.4, 0.5, 0.6, 0.7]), float)
+    rrf_ks = _as_list(settings.get(f"{prefix}.rrf_k", [settings.RRF_K]), int)
+    reranks = _as_list(settings.get(f"{prefix}.rerank", [False, True]), _as_bool)
+    return [
+        {"sparse_share": share, "rrf_k": k, "rerank": rerank}
+        for share, k, rerank in itertools.product(shares, rrf_ks, reranks)
+    ]
+
+
+def metric_key(metric: str, k: int) -> str:
+    """Aggregate key for a metric name (ndcg -> ndcg@10)."""
+    return "mrr" if metric == "mrr" else f"{metric}@{k}"
+
+
+class TuningAssistant:
+    """Grid-searches per-store search settings against labeled queries."""
+
+    def __init__(self, admin_store=None, tuner=None):
+        self._admin_store = admin_store
+        self._tuner = tuner
+
+    @property
+    def admin_store(self):
+        if self._admin_store is None:
+            from src.services.admin.admin_store import get_admin_store
+            self._admin_store = get_admin_store()
+        return self._admin_store
+
+    @property
+    def tuner(self):
+        if self._tuner is None:
+            from src.services.search.fusion_tuning import get_fusion_tuner
+            self._tuner = get_fusion_tuner()
+        return self._tuner
+
+    async def propose(
+        self,
+        org_id: str,
+        queries: List[Dict[str, Any]],
+        k: int = 10,
+        metric: str = "ndcg",
+        search=None,
+    ) -> Dict[str, Any]:
+        """
+        Evaluate the current configuration and every grid candidate.
+
+        Args:
+            org_id: Store to tune
+            queries: Labeled queries ("query" and "relevant")
+            k: Eval cutoff
+            metric: Metric to optimize (ndcg, mrr or recall)
+            search: Async search function (default: Retriever.search)
+
+        Returns:
+            Dict with the baseline, ranked candidates and the best config
+        """
+        if metric not in METRICS:
+            raise ValueError(f"Unknown metric '{metric}' (expected one of {', '.join(METRICS)})")
+        key = metric_key(metric, k)
+
+        baseline = await run_eval(queries, org_id=org_id, k=k, search=search)
+
+        candidates = []
+        for config in candidate_grid():
+            report = await run_eval(
+                queries,
+                org_id=org_id,
+                k=k,
+                search=search,
+                weights=weights_for_share(config["sparse_share"]),
+                rrf_k=config["rrf_k"],
+                rerank=config["rerank"],
+            )
+            candidates.append({"config": config, "metrics": report["metrics"]})
+
+        # Ties go to the cheaper config (no rerank) and a balanced share
+        candidates.sort(key=lambda c: (
+            -c["metrics"][key],
+            c["config"]["rerank"],
+            abs(c["config"]["sparse_share"] - 0.5),
+        ))
+        best = candidates[0] if candidates else None
+        gain = round(best["metrics"][key] - baseline["metrics"][key], 4) if best else 0.0
+
+        return {
+            "store": org_id,
+            "k": k,
+            "metric": key,
+            "query_count": len(queries),
+            "baseline": {"config": self.current_config(org_id), "metrics": baseline["metrics"]},
+            "best": best,
+            "improvement": gain,
+            "candidates": candidates,
+        }
+
+    def current_config(self, org_id: str) -> Dict[str, Any]:
+        """Search settings the store uses today."""
+        return self.admin_store.get_store_search_config(org_id)
+
+    def apply(self, org_id: str, config: Dict[str, Any], user: str = "admin") -> Dict[str, Any]:
+        """
+        Save a configuration as the store's search defaults.
+
+        The click tuner (if enabled) continues from the applied weights.
+        """
+        weights = weights_for_share(config["sparse_share"])
+        search_config = {
+            "weights": weights,
+            "rrf_k": int(config["rrf_k"]),
+            "rerank": bool(config["rerank"]),
+            "tuned_at": datetime.now().isoformat(),
+        }
+        if not self.admin_store.set_store_search_config(org_id, search_config):
+            raise RuntimeError(f"Failed to save search config for {org_id}")
+
+        if self.tuner.enabled:
+            self.tuner.set_weights(org_id, weights["bm25"], weights["bm42"])
+
+        self.admin_store.log_audit(
+            "store_search_tuned",
+            f"Applied search config to {org_id}: share={config['sparse_share']} "
+            f"rrf_k={config['rrf_k']} rerank={config['rerank']}",
+            user
+        )
+        logger.info(f"Applied tuned search config to {org_id}: {search_config}")
+        return search_config
+
+
+# Singleton instance
+_tuning_assistant: Optional[TuningAssistant] = None
+
+def get_tuning_assistant() -> TuningAssistant:
+    """Get global tuning assistant instance."""
+    global _tuning_assistant
+    if _tuning_assistant is None:
+        _tuning_assistant = TuningAssistant()
+    return _tuning_assistant
@@ -0,0 +1 @@
+"""Webhook integration services package."""
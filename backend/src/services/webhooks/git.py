@@ -0,0 +1,241 @@
+"""
+Git Push Webhook Service.
+
+Validates GitHub and GitLab push webhooks, works out which files changed,
+and fetches their contents from the provider API so they can be indexed
+incrementally into the store configured for the repository.
+
+GitHub signs the raw body with HMAC-SHA256 (``X-Hub-Signature-256``);
+GitLab sends the shared secret verbatim (``X-Gitlab-Token``).
+"""
+
+import hmac
+import hashlib
+import logging
+import os
+from dataclasses import dataclass, field
+from typing import Dict, List, Optional, Tuple
+from urllib.parse import quote
+
+import httpx
+
+from src.core.config import settings
+
+logger = logging.getLogger(__name__)
+
+GITHUB = "github"
+GITLAB = "gitlab"
+
+
+class WebhookError(Exception):
+    """Raised when a webhook request is invalid or not authorized."""
+
+    def __init__(self, message: str, status_code: int = 400):
+        super().__init__(message)
+        self.status_code = status_code
+
+
+@dataclass
+class PushEvent:
+    """Normalized push event from either provider."""
+    provider: str
+    repository: str          # owner/name or group/project path
+    project_id: Optional[str]
+    ref: str
+    sha: str
+    default_branch: Optional[str]
+    changed: List[str] = field(default_factory=list)
+    removed: List[str] = field(default_factory=list)
+
+    @property
+    def branch(self) -> str:
+        return self.ref.split("refs/heads/", 1)[-1]
+
+
+# ============== Provider Detection & Validation ==============
+
+def detect_provider(headers: Dict[str, str]) -> str:
+    """Identify the provider from request headers."""
+    lowered = {k.lower(): v for k, v in headers.items()}
+    if "x-github-event" in lowered:
+        return GITHUB
+    if "x-gitlab-event" in lowered:
+        return GITLAB
+    raise WebhookError("Unknown webhook provider (expected GitHub or GitLab headers)")
+
+
+def verify_github_signature(secret: str, body: bytes, signature: Optional[str]) -> bool:
+    """Check the X-Hub-Signature-256 header against the raw body."""
+    if not signature or not signature.startswith("sha256="):
+        return False
+    expected = hmac.new(secret.encode(), body, hashlib.sha256).hexdigest()
+    return hmac.compare_digest(f"sha256={expected}", signature)
+
+
+def verify_gitlab_token(secret: str, token: Optional[str]) -> bool:
+    """Check the X-Gitlab-Token header against the shared secret."""
+    if not token:
+        return False
+    return hmac.compare_digest(secret, token)
+
+
+def verify_request(provider: str, headers: Dict[str, str], body: bytes, secret: str):
+    """
+    Validate the request signature for the provider.
+
+    Raises:
+        WebhookError: 401 when no secret is configured or validation fails
+    """
+    if not secret:
+        raise WebhookError("Webhook secret not configured", status_code=401)
+
+    lowered = {k.lower(): v for k, v in headers.items()}
+    if provider == GITHUB:
+        valid = verify_github_signature(secret, body, lowered.get("x-hub-signature-256"))
+    else:
+        valid = verify_gitlab_token(secret, lowered.get("x-gitlab-token"))
+
+    if not valid:
+        raise WebhookError("Invalid webhook signature", status_code=401)
+
+
+def is_push_event(provider: str, headers: Dict[str, str]) -> bool:
+    """Only push events trigger indexing."""
+    lowered = {k.lower(): v for k, v in headers.items()}
+    if provider == GITHUB:
+        return lowered.get("x-github-event") == "push"
+    return lowered.get("x-gitlab-event") == "Push Hook"
+
+
+# ============== Payload Parsing ==============
+
+def collect_changes(commits: List[dict]) -> Tuple[List[str], List[str]]:
+    """
+    Fold a list of commits into the final set of changed and removed paths.
+
+    Commits are applied in order, so a file added then removed in the same
+    push ends up removed, and a file removed then re-added ends up changed.
+    """
+    state: Dict[str, str] = {}
+    for commit in commits:
+        for path in commit.get("added", []) + commit.get("modified", []):
+            state[path] = "changed"
+        for path in commit.get("removed", []):
+            state[path] = "removed"
+
+    changed = sorted(p for p, s in state.items() if s == "changed")
+    removed = sorted(p for p, s in state.items() if s == "removed")
+    return changed, removed
+
+
+def parse_push(provider: str, payload: dict) -> PushEvent:
+    """Normalize a push payload from GitHub or GitLab."""
+    changed, removed = collect_changes(payload.get("commits", []))
+
+    if provider == GITHUB:
+        repo = payload.get("repository", {})
+        return PushEvent(
+            provider=GITHUB,
+            repository=repo.get("full_name", ""),
+            project_id=None,
+            ref=payload.get("ref", ""),
+            sha=payload.get("after", ""),
+            default_branch=repo.get("default_branch"),
+            changed=changed,
+            removed=removed,
+        )
+
+    project = payload.get("project", {})
+    return PushEvent(
+        provider=GITLAB,
+        repository=project.get("path_with_namespace", ""),
+        project_id=str(project.get("id") or payload.get("project_id") or ""),
+        ref=payload.get("ref", ""),
+        sha=payload.get("checkout_sha") or payload.get("after", ""),
+        default_branch=project.get("default_branch"),
+        changed=changed,
+        removed=removed,
+    )
+
+
+# ============== Routing ==============
+
+def resolve_store(repository: str) -> Optional[str]:
+    """Store configured for a repository (falls back to the default store)."""
+    # List of {repository, store} mappings (a list so repo names with dots survive flattening)
+    for mapping in settings.get("webhooks.git.repositories", []) or []:
+        if mapping.get("repository") == repository:
+            return mapping.get("store")
+    return settings.get("webhooks.git.default_store") or None
+
+
+def should_index_branch(event: PushEvent) -> bool:
+    """Index configured branches, or the default branch when none are set."""
+    branches = settings.get("webhooks.git.branches", []) or []
+    if branches:
+        return event.branch in branches
+    if event.default_branch:
+        return event.branch == event.default_branch
+    return True
+
+
+def is_indexable(path: str) -> bool:
+    """Skip files the indexer does not support."""
+    extensions = settings.get("indexing.file.supported_extensions", []) or []
+    if not extensions:
+        return True
+    return os.path.splitext(path)[1].lower() in extensions
+
+
+def display_path(repository: str, path: str) -> str:
+    """Path stored in chunk metadata for a repository file."""
+    return f"{repository}/{path}"
+
+
+# ============== Content Fetching ==============
+
+class GitFileFetcher:
+    """Fetch raw file contents at a commit from the provider API."""
+
+    def __init__(self, timeout: float = None):
+        self.timeout = timeout if timeout is not None else settings.get("webhooks.git.timeout", 30.0)
+
+    def fetch(self, event: PushEvent, path: str) -> Optional[bytes]:
+        """
+        Download a file at the pushed commit.
+
+        Returns:
+            File contents, or None if the file no longer exists at that commit
+        """
+        if event.provider == GITHUB:
+            url, headers = self._github_request(event, path)
+        else:
+            url, headers = self._gitlab_request(event, path)
+
+        response = httpx.get(url, headers=headers, timeout=self.timeout, follow_redirects=True)
+        if response.status_code == 404:
+            return None
+        response.raise_for_status()
+        return response.content
+
+    def _github_request(self, event: PushEvent, path: str) -> Tuple[str, Dict[str, str]]:
+        api_url = settings.get("webhooks.git.github_api_url", "https://api.github.com").rstrip("/")
+        headers = {"Accept": "application/vnd.github.raw"}
+        token = settings.get("webhooks.git.github_token")
+        if token:
+            headers["Authorization"] = f"Bearer {token}"
+        url = f"{api_url}/repos/{event.repository}/contents/{quote(path)}?ref={event.sha}"
+        return url, headers
+
+    def _gitlab_request(self, event: PushEvent, path: str) -> Tuple[str, Dict[str, str]]:
+        base_url = settings.get("webhooks.git.gitlab_url", "https://gitlab.com").rstrip("/")
+        headers = {}
+        token = settings.get("webhooks.git.gitlab_token")
+        if token:
+            headers["PRIVATE-TOKEN"] = token
+        project = event.project_id or quote(event.repository, safe="")
+        url = (
+            f"{base_url}/api/v4/projects/{project}/repository/files/"
+            f"{quote(path, safe='')}/raw?ref={event.sha}"
+        )
+        return url, headers
@@ -0,0 +1,182 @@
+"""
+Git source health.
+
+With git webhooks enabled, pushes are only as good as the provider API the
+changed files are fetched from. This checks every configured source in one
+pass (in parallel, bounded by ``webhooks.git.health.timeout``):
+
+- ``source:github`` / ``source:gitlab``: the API answers and the configured
+  token is accepted (providers with a token or a mapped repository).
+- ``source:<provider>:<repository>``: each mapped repository
+  (``webhooks.git.repositories``, ``provider`` defaults to github) is
+  readable with that token.
+
+Each result carries a message saying what to fix. Results are cached for
+``webhooks.git.health.cache_seconds`` so dashboard polling doesn't spend
+the provider's rate limit.
+"""
+
+import logging
+import threading
+import time
+from concurrent.futures import ThreadPoolExecutor
+from typing import Any, Dict, List, Optional, Tuple
+from urllib.parse import quote
+
+import httpx
+
+from src.core.config import settings
+from src.services.webhooks.git import GITHUB, GITLAB
+
+logger = logging.getLogger(__name__)
+
+UP = "up"
+DOWN = "down"
+
+# (checked_at, sources checked, results)
+_cache: Optional[Tuple[float, List[Dict[str, str]], Dict[str, Dict[str, Any]]]] = None
+_lock = threading.Lock()
+
+
+def _github() -> Tuple[str, Dict[str, str]]:
+    api_url = settings.get("webhooks.git.github_api_url", "https://api.github.com").rstrip("/")
+    headers = {"Accept": "application/vnd.github+json"}
+    token = settings.get("webhooks.git.github_token")
+    if token:
+        headers["Authorization"] = f"Bearer {token}"
+    return api_url, headers
+
+
+def _gitlab() -> Tuple[str, Dict[str, str]]:
+    base_url = settings.get("webhooks.git.gitlab_url", "https://gitlab.com").rstrip("/")
+    token = settings.get("webhooks.git.gitlab_token")
+    return f"{base_url}/api/v4", {"PRIVATE-TOKEN": token} if token else {}
+
+
+def configured_sources() -> List[Dict[str, str]]:
+    """Providers and repositories to check (empty when webhooks are off)."""
+    if not settings.get("webhooks.git.enabled", False):
+        return []
+    repositories = [
+        {"provider": (m.get("provider") or GITHUB).lower(), "repository": m["repository"]}
+        for m in settings.get("webhooks.git.repositories", []) or []
+        if m.get("repository")
+    ]
+    providers = {r["provider"] for r in repositories}
+    if settings.get("webhooks.git.github_token"):
+        providers.add(GITHUB)
+    if settings.get("webhooks.git.gitlab_token"):
+        providers.add(GITLAB)
+    return [{"provider": p} for p in sorted(providers)] + repositories
+
+
+def _get(url: str, headers: Dict[str, str], timeout: float) -> httpx.Response:
+    return httpx.get(url, headers=headers, timeout=timeout, follow_redirects=True)
+
+
+def _unreachable(target: str, setting: str, error: Exception) -> Dict[str, Any]:
+    return {
+        "status": DOWN,
+        "target": target,
+        "message": f"Cannot reach {target} ({error}); check {setting} and the network/proxy from the API",
+    }
+
+
+def check_provider(provider: str, timeout: float) -> Dict[str, Any]:
+    """API reachability and token validity of a provider."""
+    if provider == GITLAB:
+        api_url, headers = _gitlab()
+        setting, token_setting = "webhooks.git.gitlab_url", "webhooks.git.gitlab_token"
+        url = f"{api_url}/user" if headers else f"{api_url}/version"
+    else:
+        api_url, headers = _github()
+        setting, token_setting = "webhooks.git.github_api_url", "webhooks.git.github_token"
+        url = f"{api_url}/user" if "Authorization" in headers else f"{api_url}/rate_limit"
+    has_token = bool(settings.get(token_setting))
+
+    try:
+        response = _get(url, headers, timeout)
+    except Exception as e:
+        return _unreachable(api_url, setting, e)
+
+    result = {"target": api_url}
+    if response.status_code == 401 and has_token:
+        return {**result, "status": DOWN, "message": f"{provider} rejected {token_setting} (401); issue a new token"}
+    if response.status_code == 403 and response.headers.get("x-ratelimit-remaining") == "0":
+        return {**result, "status": DOWN, "message": f"{provider} API rate limit exhausted; set {token_setting} or wait for the reset"}
+    if response.status_code >= 500:
+        return {**result, "status": DOWN, "message": f"{provider} API error {response.status_code}; retry later"}
+    if not has_token:
+        return {**result, "status": UP, "message": f"Reachable without a token (public repositories only); set {token_setting} for private ones"}
+    if response.status_code != 200:
+        return {**result, "status": DOWN, "message": f"{provider} answered {response.status_code} to the token check; verify {token_setting}"}
+    return {**result, "status": UP, "message": "Reachable, token accepted"}
+
+
+def check_repository(provider: str, repository: str, timeout: float) -> Dict[str, Any]:
+    """Whether a mapped repository is readable with the configured token."""
+    if provider == GITLAB:
+        api_url, headers = _gitlab()
+        url = f"{api_url}/projects/{quote(repository, safe='')}"
+        setting, token_setting = "webhooks.git.gitlab_url", "webhooks.git.gitlab_token"
+    else:
+        api_url, headers = _github()
+        url = f"{api_url}/repos/{repository}"
+        setting, token_setting = "webhooks.git.github_api_url", "webhooks.git.github_token"
+
+    try:
+        response = _get(url, headers, timeout)
+    except Exception as e:
+        return _unreachable(api_url, setting, e)
+
+    result = {"target": url}
+    if response.status_code == 200:
+        return {**result, "status": UP, "message": "Repository readable"}
+    if response.status_code in (401, 403):
+        return {**result, "status": DOWN, "message": f"Access denied ({response.status_code}); check {token_setting} and its scopes"}
+    if response.status_code == 404:
+        return {
+            **result,
+            "status": DOWN,
+            "message": f"Not found: check the name in webhooks.git.repositories, or give {token_setting} read access",
+        }
+    return {**result, "status": DOWN, "message": f"{provider} answered {response.status_code}; retry later"}
+
+
+def _check(source: Dict[str, str], timeout: float) -> Tuple[str, Dict[str, Any]]:
+    provider = source["provider"]
+    if "repository" in source:
+        name = f"source:{provider}:{source['repository']}"
+        return name, check_repository(provider, source["repository"], timeout)
+    return f"source:{provider}", check_provider(provider, timeout)
+
+
+def check_sources(force: bool = False) -> Dict[str, Dict[str, Any]]:
+    """
+    Health of every configured git source, by component name.
+
+    Returns:
+        Dict of ``source:...`` -> {status: up|down, target, message, checked_at}
+    """
+    global _cache
+    sources = configured_sources()
+    if not sources:
+        return {}
+
+    ttl = float(settings.get("webhooks.git.health.cache_seconds", 300))
+    with _lock:
+        if not force and _cache and _cache[1] == sources and time.time() - _cache[0] < ttl:
+            return _cache[2]
+
+    timeout = float(settings.get("webhooks.git.health.timeout", 5))
+    checked_at = time.time()
+    with ThreadPoolExecutor(max_workers=min(8, len(sources))) as pool:
+        results = dict(pool.map(lambda s: _check(s, timeout), sources))
+    for name, result in results.items():
+        result["checked_at"] = checked_at
+        if result["status"] != UP:
+            logger.warning(f"Git source {name} is down: {result['message']}")
+
+    with _lock:
+        _cache = (checked_at, sources, results)
+    return results
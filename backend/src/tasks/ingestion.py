@@ -7,6 +7,10 @@ from src.db.qdrant import get_qdrant_client
 from src.core.config import settings
 from src.services.ingestion.indexer import Indexer
 from src.services.ingestion.chunker import DocumentChunker
+from src.services.ingestion.pipeline import PipelineBusy
+from src.services.events import emit
+from src.services.admin.timeseries import get_time_series
+from src.services.events.dead_letters import INDEX_TOPIC, KIND_INDEX_TASK, get_dead_letter_store
 
 # Lazy load models/clients
 
@@ -24,7 +28,7 @@ def get_sparse_embedder():
 
 
 @celery_app.task(bind=True)
-def ingest_file_task(self, file_path: str, original_path: str = None, repo_name: str = "default", org_id: str = "public"):
+def ingest_file_task(self, file_path: str, original_path: str = None, repo_name: str = "default", org_id: str = "public", connection_id: str = None, language: str = None, enforce_owner: bool = False):
     """
     Full pipeline: Parse -> Chunk -> Embed -> Upsert.
     Delegates to Indexer.
@@ -34,16 +38,49 @@ def ingest_file_task(self, file_path: str, original_path: str = None, repo_name:
         original_path: Original client-side path for metadata storage
         repo_name: Repository name
         org_id: Organization ID
+        connection_id: CLI connection that uploaded the file
+        language: Language reported by the client (detected if omitted)
+        enforce_owner: Refuse to replace files another connection indexed
     """
     self.update_state(state='STARTED', meta={'step': 'Indexing'})
     
     # Use original_path if provided, otherwise fall back to file_path
     display_path = original_path or file_path
     
+    emit("index.file.started", path=display_path, org_id=org_id, connection_id=connection_id, task_id=self.request.id)
+
     # Indexer now uses BentoML internally - no model needed here
     indexer = Indexer(qdrant_client=get_qdrant())
     
-    return indexer.ingest_file(file_path, display_path, repo_name, org_id)
+    try:
+        result = indexer.ingest_file(
+            file_path, display_path, repo_name, org_id,
+            connection_id=connection_id, language=language, enforce_owner=enforce_owner
+        )
+    except PipelineBusy as e:
+        # Stage queue full: back off and run again (see src/services/ingestion/pipeline.py)
+        raise self.retry(
+            exc=e, countdown=e.retry_after,
+            max_retries=int(settings.get("indexing.pipeline.max_retries", 20))
+        )
+    if result.get("status") == "error":
+        # Keep the upload so it can be requeued once the dependency is back
+        get_dead_letter_store().add(
+            KIND_INDEX_TASK, INDEX_TOPIC,
+            {
+                "file_path": file_path, "original_path": original_path, "repo_name": repo_name,
+                "org_id": org_id, "connection_id": connection_id, "language": language,
+                "enforce_owner": enforce_owner,
+            },
+            result.get("message") or "Indexing failed", self.request.retries + 1,
+        )
+    get_time_series().record_index(org_id, result.get("status", "unknown"), result.get("chunks_indexed") or 0)
+    emit(
+        f"index.file.{result.get('status', 'unknown')}",
+        path=display_path, org_id=org_id, connection_id=connection_id, task_id=self.request.id,
+        chunks_indexed=result.get("chunks_indexed"), message=result.get("message")
+    )
+    return result
 
 @celery_app.task(bind=True, name="src.tasks.ingestion.rebuild_index_task")
 def rebuild_index_task(self):
@@ -59,3 +96,141 @@ def rebuild_index_task(self):
     self.update_state(state='STARTED')
     # Simple placeholder to pass syntax check.
     return {"status": "success", "message": "Rebuild not fully implemented in refactor"}
+
+
+@celery_app.task(bind=True, name="src.tasks.ingestion.delete_connection_chunks_task")
+def delete_connection_chunks_task(self, connection_id: str, org_id: str = None):
+    """
+    Remove every chunk a connection contributed, optionally within one store.
+
+    Args:
+        connection_id: Connection whose chunks should be removed
+        org_id: Restrict to a single store (default: every store)
+    """
+    from src.services.admin.admin_store import get_admin_store
+
+    self.update_state(state='STARTED', meta={'step': 'Deleting chunks'})
+    result = Indexer(qdrant_client=get_qdrant()).delete_by_connection(connection_id, org_id)
+
+    # Keep the connection's stats in line with what is left in the index
+    store = get_admin_store()
+    connection = store.get_connections().get(connection_id)
+    if connection:
+        removed_files = sum(len(paths) for paths in result["files"].values())
+        if org_id:
+            connection["stores"] = [s for s in connection.get("stores", []) if s != org_id]
+            connection["indexed_files"] = max(0, connection.get("indexed_files", 0) - removed_files)
+        else:
+            connection["stores"] = []
+            connection["indexed_files"] = 0
+        store.set_connection(connection_id, connection)
+
+    emit("index.connection.deleted", connection_id=connection_id, org_id=org_id, chunks_removed=result["chunks_removed"])
+    store.log_audit(
+        "connection_chunks_deleted",
+        f"Removed {result['chunks_removed']} chunks from connection {connection_id} in {org_id or 'all stores'}",
+        "admin"
+    )
+    return result
+
+
+@celery_app.task(bind=True, name="src.tasks.ingestion.migrate_store_task")
+def migrate_store_task(self, store_id: str):
+    """
+    Re-embed a store's chunks with the default embedding model into a new
+    collection and switch the store over (see services.ingestion.migration).
+
+    Args:
+        store_id: Store whose migration was started by the API
+    """
+    from src.services.admin.admin_store import get_admin_store
+    from src.services.ingestion.migration import StoreMigration
+
+    def progress(current: int, total: int):
+        self.update_state(state='STARTED', meta={'step': 'Re-embedding', 'current': current, 'total': total})
+
+    self.update_state(state='STARTED', meta={'step': 'Re-embedding', 'current': 0})
+    emit("store.migration.started", store_id=store_id, task_id=self.request.id)
+    try:
+        result = StoreMigration(qdrant_client=get_qdrant()).run(store_id, progress=progress)
+    except Exception as e:
+        emit("store.migration.failed", store_id=store_id, task_id=self.request.id, error=str(e))
+        raise
+    emit("store.migration.complete", store_id=store_id, task_id=self.request.id, migrated=result["migrated"])
+    get_admin_store().log_audit(
+        "store_migrated",
+        f"Store {store_id} re-embedded with {result['model']} ({result['migrated']} chunks) into {result['target']}",
+        "admin"
+    )
+    return {"status": "success", **result}
+
+
+@celery_app.task(bind=True, name="src.tasks.ingestion.git_ingest_task")
+def git_ingest_task(self, store_id: str):
+    """
+    Clone the repository queued for a store and index it (see
+    services.ingestion.git_source).
+
+    Args:
+        store_id: Store whose git ingest was started by the API
+    """
+    from src.services.admin.admin_store import get_admin_store
+    from src.services.ingestion.git_source import get_git_ingestion
+
+    def progress(current: int, total: int):
+        self.update_state(state='STARTED', meta={'step': 'Indexing', 'current': current, 'total': total})
+
+    self.update_state(state='STARTED', meta={'step': 'Cloning'})
+    emit("store.git.started", store_id=store_id, task_id=self.request.id)
+    try:
+        result = get_git_ingestion().run(store_id, self.request.id, progress=progress)
+    except Exception as e:
+        emit("store.git.failed", store_id=store_id, task_id=self.request.id, error=str(e))
+        raise
+    emit(
+        "store.git.complete", store_id=store_id, task_id=self.request.id,
+        repository=result["repository"], sha=result["sha"], mode=result["mode"], indexed=result["indexed"]
+    )
+    get_admin_store().log_audit(
+        "store_git_ingested",
+        f"Indexed {result['repository']}@{result['sha'][:12]} into {store_id} "
+        f"({result['mode']}: {result['indexed']} indexed, {result['removed']} removed)",
+        "admin"
+    )
+    return {"status": "success" if not result["failed"] else "partial", **result}
+
+
+@celery_app.task(bind=True, name="src.tasks.ingestion.archive_ingest_task")
+def archive_ingest_task(self, archive_path: str, store_id: str, prefix: str = "", filename: str = None):
+    """
+    Extract an uploaded zip/tar archive and index its files as one batch
+    (see services.ingestion.archive).
+
+    Args:
+        archive_path: Upload in the shared temp dir (removed afterwards)
+        store_id: Store to index into
+        prefix: Path prefix stored with the archive's files
+        filename: Uploaded file name, for events and the audit log
+    """
+    from src.services.admin.admin_store import get_admin_store
+    from src.services.ingestion.archive import ingest_archive
+
+    def progress(current: int, total: int):
+        self.update_state(state='STARTED', meta={'step': 'Indexing', 'current': current, 'total': total})
+
+    self.update_state(state='STARTED', meta={'step': 'Extracting'})
+    try:
+        result = ingest_archive(archive_path, store_id, prefix, qdrant_client=get_qdrant(), progress=progress)
+    except Exception as e:
+        emit("index.archive.failed", org_id=store_id, file=filename, task_id=self.request.id, error=str(e))
+        raise
+    emit(
+        "index.archive.complete", org_id=store_id, file=filename, task_id=self.request.id,
+        files=result["files"], indexed=result["indexed"], failed=result["failed"]
+    )
+    get_admin_store().log_audit(
+        "archive_ingested",
+        f"Indexed archive {filename or archive_path} into {store_id} ({result['indexed']} of {result['files']} files)",
+        "admin"
+    )
+    return {"status": "success" if not result["failed"] else "partial", **result}
@@ -0,0 +1,127 @@
+"""
+Maintenance Tasks.
+Periodic housekeeping that runs on the Celery worker.
+"""
+from src.worker.celery_app import app as celery_app
+from src.services.search.tiering import get_tier_manager
+
+
+@celery_app.task(bind=True, name="src.tasks.maintenance.tier_maintenance_task")
+def tier_maintenance_task(self, org_id: str = None):
+    """
+    Move idle chunks to the cold tier and promote matched cold chunks.
+
+    Args:
+        org_id: Restrict demotion to a single store (default: all stores)
+    """
+    tier_manager = get_tier_manager()
+    if not tier_manager.enabled:
+        return {"status": "skipped", "message": "Tiering disabled"}
+
+    self.update_state(state='STARTED', meta={'step': 'Tiering'})
+    return {"status": "success", **tier_manager.run_maintenance(org_id)}
+
+
+@celery_app.task(name="src.tasks.maintenance.health_check_task")
+def health_check_task():
+    """Sample component health so history has data between dashboard visits."""
+    from src.services.admin.admin_store import get_admin_store
+    from src.services.admin.health_history import check_components, get_health_history
+
+    components = check_components(get_admin_store())
+    transitions = get_health_history().record(components)
+    return {"components": components, "transitions": len(transitions)}
+
+
+@celery_app.task(bind=True, name="src.tasks.maintenance.backfill_connections_task")
+def backfill_connections_task(self, dry_run: bool = False):
+    """
+    Rebuild connection indexed-file counts and stores from chunk payloads.
+
+    Args:
+        dry_run: Report what would be written without changing connections
+    """
+    from src.services.admin.connection_backfill import ConnectionBackfill
+
+    self.update_state(state='STARTED', meta={'step': 'Scanning payloads'})
+    return {"status": "success", **ConnectionBackfill().run(dry_run=dry_run)}
+
+
+@celery_app.task(bind=True, name="src.tasks.maintenance.backfill_language_task")
+def backfill_language_task(self, org_id: str = None, dry_run: bool = False):
+    """
+    Detect and store the language of chunks indexed without one.
+
+    Args:
+        org_id: Restrict to one store (default: every store)
+        dry_run: Report what would be written without changing payloads
+    """
+    from src.services.admin.language_backfill import LanguageBackfill
+
+    self.update_state(state='STARTED', meta={'step': 'Scanning payloads'})
+    return {"status": "success", **LanguageBackfill().run(org_id=org_id, dry_run=dry_run)}
+
+
+@celery_app.task(bind=True, name="src.tasks.maintenance.rebuild_store_stats_task")
+def rebuild_store_stats_task(self, org_id: str = None):
+    """
+    Recount store file, chunk, byte and language stats from chunk payloads.
+
+    Args:
+        org_id: Restrict to one store (default: every store)
+    """
+    from src.services.admin.admin_store import get_admin_store
+    from src.services.admin.store_stats import get_store_stats
+
+    self.update_state(state='STARTED', meta={'step': 'Scanning payloads'})
+    store_ids = [org_id] if org_id else sorted(get_admin_store().get_stores())
+    stats = get_store_stats()
+    rebuilt = {store_id: stats.rebuild(store_id) for store_id in store_ids}
+    return {
+        "status": "success",
+        "stores": len(rebuilt),
+        "files": sum(s["files"] for s in rebuilt.values()),
+        "chunks": sum(s["chunks"] for s in rebuilt.values()),
+    }
+
+
+@celery_app.task(name="src.tasks.maintenance.fusion_tuning_task")
+def fusion_tuning_task():
+    """Adjust per-store fusion weights from click feedback."""
+    from src.services.search.fusion_tuning import get_fusion_tuner
+
+    tuner = get_fusion_tuner()
+    if not tuner.enabled:
+        return {"status": "skipped", "message": "Fusion tuning disabled"}
+    return {"status": "success", "stores": tuner.tune_all()}
+
+
+@celery_app.task(name="src.tasks.maintenance.purge_trash_task")
+def purge_trash_task():
+    """Purge deleted stores whose retention window has passed."""
+    from src.services.admin.store_trash import purge_expired
+
+    return {"status": "success", "purged": purge_expired()}
+
+
+@celery_app.task(bind=True, name="src.tasks.maintenance.duplicate_report_task")
+def duplicate_report_task(self, store_id: str):
+    """Find near-duplicate chunks across a store's files."""
+    from src.services.admin.duplicates import get_duplicate_reports
+
+    self.update_state(state='STARTED', meta={'step': 'Comparing chunks'})
+    report = get_duplicate_reports().run(store_id)
+    return {
+        "status": "success",
+        "clusters": report["total_clusters"],
+        "file_pairs": report["total_file_pairs"],
+    }
+
+
+@celery_app.task(bind=True, name="src.tasks.maintenance.suggest_vocabulary_task")
+def suggest_vocabulary_task(self, store_id: str):
+    """Recount a store's query suggestion vocabulary."""
+    from src.services.search.suggestions import get_query_suggester
+
+    self.update_state(state='STARTED', meta={'step': 'Counting terms'})
+    return {"status": "success", **get_query_suggester().rebuild(store_id)}
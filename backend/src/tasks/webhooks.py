@@ -0,0 +1,84 @@
+"""
+Webhook Tasks.
+Incremental indexing for git push webhooks.
+"""
+import os
+import uuid
+import logging
+
+from src.worker.celery_app import app as celery_app
+from src.db.qdrant import get_qdrant_client
+from src.services.ingestion.indexer import Indexer
+from src.services.webhooks.git import PushEvent, GitFileFetcher, display_path
+from src.services.ingestion.generated import GENERATED_KINDS
+from src.services.ingestion.exclusion import EXCLUSION_REASONS
+
+logger = logging.getLogger(__name__)
+
+
+@celery_app.task(bind=True, name="src.tasks.webhooks.git_push_task")
+def git_push_task(self, event: dict, org_id: str):
+    """
+    Index changed files and drop removed files for a push.
+
+    Args:
+        event: Serialized PushEvent
+        org_id: Store to index into
+    """
+    push = PushEvent(**event)
+    indexer = Indexer(qdrant_client=get_qdrant_client())
+    fetcher = GitFileFetcher()
+
+    base_tmp = os.getenv("SHARED_TMP_DIR", "/tmp/ingest")
+    os.makedirs(base_tmp, exist_ok=True)
+
+    indexed, removed, failed, skipped = [], [], [], []
+
+    for path in push.removed:
+        indexer.delete_file(display_path(push.repository, path), org_id)
+        removed.append(path)
+
+    total = len(push.changed)
+    for i, path in enumerate(push.changed):
+        self.update_state(state='STARTED', meta={'step': 'Indexing', 'current': i, 'total': total})
+        target = display_path(push.repository, path)
+        temp_path = None
+        try:
+            content = fetcher.fetch(push, path)
+            if content is None:
+                # Gone at the pushed commit (e.g. renamed later in the push)
+                indexer.delete_file(target, org_id)
+                removed.append(path)
+                continue
+
+            temp_path = os.path.join(base_tmp, f"{uuid.uuid4()}{os.path.splitext(path)[1]}")
+            with open(temp_path, "wb") as f:
+                f.write(content)
+
+            result = indexer.ingest_file(temp_path, target, push.repository, org_id)
+            if result.get("status") == "error":
+                failed.append({"path": path, "error": result.get("message")})
+            elif result.get("status") == "skipped":
+                skipped.append({"path": path, "reason": result.get("generated") or result.get("excluded") or result.get("message")})
+            elif result.get("status") == "unchanged":
+                skipped.append({"path": path, "reason": "unchanged"})
+            else:
+                indexed.append(path)
+        except Exception as e:
+            logger.warning(f"Webhook indexing failed for {target}: {e}")
+            failed.append({"path": path, "error": str(e)})
+        finally:
+            if temp_path and os.path.exists(temp_path):
+                os.remove(temp_path)
+
+    return {
+        "status": "success" if not failed else "partial",
+        "repository": push.repository,
+        "sha": push.sha,
+        "indexed": indexed,
+        "removed": removed,
+        "failed": failed,
+        "skipped": skipped,
+        "generated_skipped": sum(1 for s in skipped if s["reason"] in GENERATED_KINDS),
+        "excluded": sum(1 for s in skipped if s["reason"] in EXCLUSION_REASONS),
+    }
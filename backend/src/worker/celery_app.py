@@ -1,4 +1,5 @@
 from celery import Celery
+from celery.signals import worker_shutdown
 from src.core.config import settings
 from src.services.admin.admin_store import get_admin_store
 
@@ -31,12 +32,44 @@ app.conf.update(
     worker_concurrency=worker_concurrency
 )
 
+# Periodic maintenance (picked up when the worker runs with --beat)
+beat_schedule = {}
+if settings.get("search.tiering.enabled", False):
+    beat_schedule["tier-maintenance"] = {
+        "task": "src.tasks.maintenance.tier_maintenance_task",
+        "schedule": float(settings.get("search.tiering.maintenance_interval_seconds", 3600)),
+    }
+if settings.get("health.history.enabled", True):
+    beat_schedule["health-check"] = {
+        "task": "src.tasks.maintenance.health_check_task",
+        "schedule": float(settings.get("health.history.check_interval_seconds", 60)),
+    }
+if settings.get("search.fusion_tuning.enabled", False):
+    beat_schedule["fusion-tuning"] = {
+        "task": "src.tasks.maintenance.fusion_tuning_task",
+        "schedule": float(settings.get("search.fusion_tuning.interval_seconds", 3600)),
+    }
+if settings.get("stores.trash.auto_purge", True):
+    beat_schedule["trash-purge"] = {
+        "task": "src.tasks.maintenance.purge_trash_task",
+        "schedule": float(settings.get("stores.trash.purge_interval_seconds", 3600)),
+    }
+app.conf.beat_schedule = beat_schedule
+
 # Explicitly Auto-discovery source
 app.autodiscover_tasks(['src.tasks'])
 
+@worker_shutdown.connect
+def shutdown_background_tasks(**kwargs):
+    # Model TTL monitor, alert delivery, bus transport (src/core/supervisor.py)
+    from src.core.supervisor import get_supervisor
+    get_supervisor().shutdown()
+
 @app.task
 def echo_task(message):
     return message
 
 # Import tasks to ensure registration
 import src.tasks.ingestion
+import src.tasks.maintenance
+import src.tasks.webhooks
@@ -0,0 +1,185 @@
+#!/usr/bin/env python
+"""
+ML worker entry point (``rice-search-ml-worker``).
+
+Loads the inference models and serves embed, sparse and rerank requests from
+the event bus, so the API server runs without them and inference scales
+horizontally across GPU machines. Point the API at it with
+``inference.remote.embed`` / ``.sparse`` / ``.rerank`` and the same
+``events.backend`` (Redis or NATS).
+
+Workers publish ``ml.worker.heartbeat`` events (topics, device, request
+counts) that the API's health check reads back.
+
+Usage:
+    rice-search-ml-worker --topics embed,sparse,rerank
+"""
+import argparse
+import asyncio
+import logging
+import os
+import signal
+import socket
+import sys
+import threading
+import time
+import uuid
+from datetime import datetime
+
+sys.path.insert(0, os.getcwd())
+
+from src.core.config import settings
+from src.core.supervisor import ORDER_CONSUMERS, get_supervisor
+from src.services.events.bus import emit, get_event_bus
+from src.services.inference.ml_workers import HEARTBEAT_TOPIC, heartbeat_interval
+from src.services.inference.ollama_client import OllamaClient
+from src.services.inference.remote_client import EMBED_TOPIC, RERANK_TOPIC, SPARSE_TOPIC
+
+logger = logging.getLogger(__name__)
+
+
+def handle_embed(request: dict) -> dict:
+    # Always local here: this process is the remote end
+    client = OllamaClient()
+    if request.get("usage"):
+        return asyncio.run(client.embed_with_usage(request["texts"], request.get("model")))
+    return {"embeddings": asyncio.run(client.embed(request["texts"], request.get("model")))}
+
+
+def handle_sparse(request: dict) -> dict:
+    from src.services.retrieval.splade_encoder import get_splade_encoder
+
+    vectors = get_splade_encoder(local=True).encode(request["texts"])
+    return {"vectors": [{"indices": list(v.indices), "values": list(v.values)} for v in vectors]}
+
+
+def handle_rerank(request: dict) -> dict:
+    from src.services.inference.local_reranker import get_local_reranker
+
+    results = asyncio.run(get_local_reranker().rerank(
+        request["query"], request["documents"], request.get("top_n")
+    ))
+    return {"results": results}
+
+
+def warm_up(name: str):
+    """Load a topic's model before taking requests."""
+    if name == "sparse":
+        from src.services.retrieval.splade_encoder import get_splade_encoder
+        get_splade_encoder(local=True)
+    elif name == "rerank":
+        from src.services.inference.local_reranker import get_local_reranker
+        get_local_reranker()._load_model()
+
+
+HANDLERS = {
+    "embed": (EMBED_TOPIC, handle_embed),
+    "sparse": (SPARSE_TOPIC, handle_sparse),
+    "rerank": (RERANK_TOPIC, handle_rerank),
+}
+
+
+def detect_device() -> dict:
+    """Device summary for heartbeats (device, name, fallback_reason)."""
+    try:
+        from src.core.device import get_device_info
+        info = get_device_info()
+    except Exception as e:
+        return {"device": "cpu", "device_error": str(e)}
+    return {
+        "device": info["device"] if info["device"] == "cpu" else f"cuda:{info['name']}",
+        "fallback_reason": info["fallback_reason"],
+        "vram": info["vram"],
+    }
+
+
+class WorkerStats:
+    """Request counters reported in heartbeats."""
+
+    def __init__(self):
+        self.requests = {}
+        self.errors = {}
+        self.total_seconds = {}
+        self._lock = threading.Lock()
+
+    def wrap(self, topic, handler):
+        def counted(request):
+            started = time.perf_counter()
+            ok = False
+            try:
+                result = handler(request)
+                ok = True
+                return result
+            finally:
+                with self._lock:
+                    self.requests[topic] = self.requests.get(topic, 0) + 1
+                    self.total_seconds[topic] = self.total_seconds.get(topic, 0.0) + time.perf_counter() - started
+                    if not ok:
+                        self.errors[topic] = self.errors.get(topic, 0) + 1
+        return counted
+
+    def snapshot(self) -> dict:
+        with self._lock:
+            return {
+                "requests": dict(self.requests),
+                "errors": dict(self.errors),
+                "avg_ms": {
+                    t: round(self.total_seconds[t] / n * 1000, 1)
+                    for t, n in self.requests.items() if n
+                },
+            }
+
+
+def main(argv=None):
+    parser = argparse.ArgumentParser(description="Serve ML requests from the event bus")
+    parser.add_argument("--topics", default="embed,sparse,rerank", help="Comma-separated: embed, sparse, rerank")
+    parser.add_argument("--threads", type=int, default=1, help="Concurrent requests per topic")
+    args = parser.parse_args(argv)
+
+    logging.basicConfig(level=logging.INFO)
+    names = [t.strip() for t in args.topics.split(",") if t.strip()]
+    unknown = [t for t in names if t not in HANDLERS]
+    if unknown:
+        parser.error(f"unknown topics {unknown}; expected any of {sorted(HANDLERS)}")
+
+    # With lazy loading, models load (and warm up) on first request instead
+    if not settings.get("model_management.lazy_loading", True):
+        for name in names:
+            warm_up(name)
+
+    bus = get_event_bus()
+    stats = WorkerStats()
+    stop = threading.Event()
+    signal.signal(signal.SIGTERM, lambda *_: stop.set())
+    signal.signal(signal.SIGINT, lambda *_: stop.set())
+
+    supervisor = get_supervisor()
+    for name in names:
+        topic, handler = HANDLERS[name]
+        for i in range(max(1, args.threads)):
+            supervisor.spawn(
+                f"ml-{name}-{i}", bus.serve, topic, stats.wrap(topic, handler),
+                group="ml-worker", order=ORDER_CONSUMERS, pass_stop=True,
+            )
+
+    identity = {
+        "worker_id": f"{socket.gethostname()}-{os.getpid()}-{uuid.uuid4().hex[:6]}",
+        "host": socket.gethostname(),
+        "pid": os.getpid(),
+        "topics": [HANDLERS[n][0] for n in names],
+        "threads": max(1, args.threads),
+        **detect_device(),
+        "started_at": datetime.now().isoformat(),
+    }
+    logger.info(f"ML worker {identity['worker_id']} serving {', '.join(names)} over {bus.backend.name} on {identity['device']}")
+    while True:
+        emit(HEARTBEAT_TOPIC, **identity, **stats.snapshot())
+        if stop.wait(heartbeat_interval()):
+            break
+    emit(HEARTBEAT_TOPIC, **identity, **stats.snapshot(), stopping=True)
+    # Consumers first, then the bus transport they read from
+    supervisor.shutdown()
+
+
+if __name__ == '__main__':
+    main()
@@ -14,13 +14,19 @@ from src.worker.celery_app import app, worker_pool, worker_concurrency
 
 # IMPORT TASKS TO REGISTER THEM
 import src.tasks.ingestion
+import src.tasks.maintenance
+import src.tasks.webhooks
 
 if __name__ == '__main__':
     print(f"Starting Celery worker with pool={worker_pool}, concurrency={worker_concurrency}")
-    # Start worker with config from Redis
-    app.start(argv=[
+    argv = [
         'worker',
         f'--pool={worker_pool}',
         f'--concurrency={worker_concurrency}',
         '--loglevel=info'
-    ])
+    ]
+    # Embedded beat only when periodic maintenance is configured
+    if app.conf.beat_schedule:
+        argv.append('--beat')
+    # Start worker with config from Redis
+    app.start(argv=argv)
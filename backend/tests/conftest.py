@@ -0,0 +1,223 @@
+"""
+Shared test fixtures: in-memory Redis and Qdrant fakes.
+"""
+import fnmatch
+from types import SimpleNamespace
+
+import pytest
+
+
+class FakePipeline:
+    """Runs each command on the fake immediately; ``execute`` returns the replies."""
+
+    def __init__(self, redis):
+        self.redis = redis
+        self.results = []
+
+    def __getattr__(self, name):
+        command = getattr(self.redis, name)
+
+        def queued(*args, **kwargs):
+            self.results.append(command(*args, **kwargs))
+            return self
+        return queued
+
+    def execute(self):
+        results, self.results = self.results, []
+        return results
+
+
+class FakeRedis:
+    """Strings, hashes, lists, sets and sorted sets; values are stored as Redis returns them."""
+
+    def __init__(self):
+        self.values = {}
+        self.hashes = {}
+        self.lists = {}
+        self.sets = {}
+        self.zsets = {}
+        self.expiry = {}
+
+    def _keyspaces(self):
+        return (self.values, self.hashes, self.lists, self.sets, self.zsets)
+
+    def pipeline(self):
+        return FakePipeline(self)
+
+    def get(self, key):
+        return self.values.get(key)
+
+    def set(self, key, value, ex=None, nx=False):
+        if nx and key in self.values:
+            return None
+        self.values[key] = value
+        if ex:
+            self.expiry[key] = ex
+        return True
+
+    def exists(self, *keys):
+        return sum(any(key in space for space in self._keyspaces()) for key in keys)
+
+    def delete(self, *keys):
+        for key in keys:
+            for space in self._keyspaces():
+                space.pop(key, None)
+
+    def expire(self, key, seconds):
+        self.expiry[key] = seconds
+
+    def scan_iter(self, match="*"):
+        keys = {key for space in self._keyspaces() for key in space}
+        return [key for key in sorted(keys) if fnmatch.fnmatchcase(key, match)]
+
+    def hget(self, key, field):
+        return self.hashes.get(key, {}).get(field)
+
+    def hset(self, key, field=None, value=None, mapping=None):
+        values = self.hashes.setdefault(key, {})
+        values.update({k: str(v) for k, v in (mapping or {field: value}).items()})
+
+    def hmget(self, key, *fields):
+        if len(fields) == 1 and isinstance(fields[0], (list, tuple)):
+            fields = fields[0]
+        return [self.hashes.get(key, {}).get(f) for f in fields]
+
+    def hgetall(self, key):
+        return dict(self.hashes.get(key, {}))
+
+    def hdel(self, key, *fields):
+        for field in fields:
+            self.hashes.get(key, {}).pop(field, None)
+
+    def hincrby(self, key, field, amount=1):
+        values = self.hashes.setdefault(key, {})
+        values[field] = str(int(values.get(field, 0)) + amount)
+        return int(values[field])
+
+    def hincrbyfloat(self, key, field, amount=1.0):
+        values = self.hashes.setdefault(key, {})
+        values[field] = str(float(values.get(field, 0)) + amount)
+        return float(values[field])
+
+    def lpush(self, key, *values):
+        for value in values:
+            self.lists.setdefault(key, []).insert(0, value)
+
+    def ltrim(self, key, start, end):
+        self.lists[key] = self.lists.get(key, [])[start:end + 1 or None]
+
+    def lrange(self, key, start, end):
+        return list(self.lists.get(key, [])[start:end + 1 or None])
+
+    def sadd(self, key, *members):
+        self.sets.setdefault(key, set()).update(members)
+
+    def smembers(self, key):
+        return set(self.sets.get(key, set()))
+
+    def zincrby(self, key, amount, member):
+        zset = self.zsets.setdefault(key, {})
+        zset[member] = zset.get(member, 0) + amount
+        return zset[member]
+
+    def zcard(self, key):
+        return len(self.zsets.get(key, {}))
+
+    def _ranked(self, key):
+        return sorted(self.zsets.get(key, {}).items(), key=lambda item: (item[1], item[0]))
+
+    def zrange(self, key, start, end):
+        return [member for member, _ in self._ranked(key)[start:end + 1 or None]]
+
+    def zrevrange(self, key, start, end, withscores=False):
+        ranked = list(reversed(self._ranked(key)))[start:end + 1 or None]
+        return ranked if withscores else [member for member, _ in ranked]
+
+    def zrem(self, key, *members):
+        for member in members:
+            self.zsets.get(key, {}).pop(member, None)
+
+
+def _matches(point, flt):
+    """Payload match for ``Filter(must=[FieldCondition(...)])`` (MatchValue / MatchAny)."""
+    for condition in (flt.must if flt is not None else None) or []:
+        value = point.payload.get(condition.key)
+        allowed = getattr(condition.match, "any", None)
+        if allowed is not None:
+            if value not in allowed:
+                return False
+        elif value != condition.match.value:
+            return False
+    return True
+
+
+class FakeQdrant:
+    """
+    Named collections of points, scrolled a page at a time with integer
+    offsets. Unknown collections raise like the real client.
+    """
+
+    def __init__(self, collections=None, dimension=768):
+        self.collections = {name: list(points) for name, points in (collections or {}).items()}
+        self.dimension = dimension
+        self.dimensions = {}
+        self.scrolls = []
+
+    def _points(self, collection_name):
+        if collection_name not in self.collections:
+            raise ValueError(f"Collection {collection_name} not found")
+        return self.collections[collection_name]
+
+    def get_collection(self, name):
+        self._points(name)
+        dense = SimpleNamespace(size=self.dimensions.get(name, self.dimension))
+        return SimpleNamespace(config=SimpleNamespace(params=SimpleNamespace(vectors={"dense": dense})))
+
+    def create_collection(self, collection_name, vectors_config, sparse_vectors_config=None):
+        self.collections[collection_name] = []
+        self.dimensions[collection_name] = vectors_config["dense"].size
+
+    def delete_collection(self, name):
+        self._points(name)
+        del self.collections[name]
+
+    def create_payload_index(self, collection_name, field_name, field_schema):
+        pass
+
+    def count(self, collection_name, count_filter=None, exact=True):
+        return SimpleNamespace(count=sum(_matches(p, count_filter) for p in self._points(collection_name)))
+
+    def scroll(self, collection_name, scroll_filter=None, limit=10, offset=None,
+               with_payload=True, with_vectors=False):
+        self.scrolls.append(SimpleNamespace(
+            collection=collection_name, filter=scroll_filter, offset=offset,
+            with_payload=with_payload, with_vectors=with_vectors,
+        ))
+        points = [p for p in self._points(collection_name) if _matches(p, scroll_filter)]
+        start = offset or 0
+        end = start + limit
+        return points[start:end], (end if end < len(points) else None)
+
+    def retrieve(self, collection_name, ids, with_payload=True, with_vectors=False):
+        return [p for p in self._points(collection_name) if p.id in ids]
+
+    def upsert(self, collection_name, points):
+        ids = {p.id for p in points}
+        kept = [p for p in self._points(collection_name) if p.id not in ids]
+        self.collections[collection_name] = kept + list(points)
+
+    def delete(self, collection_name, points_selector):
+        self.collections[collection_name] = [
+            p for p in self._points(collection_name) if not _matches(p, points_selector)
+        ]
+
+
+@pytest.fixture
+def fake_redis():
+    return FakeRedis()
+
+
+@pytest.fixture
+def fake_qdrant():
+    """Empty fake; tests fill ``collections`` with the points they need."""
+    return FakeQdrant()
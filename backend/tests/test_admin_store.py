@@ -140,3 +140,32 @@ class TestAdminStore:
         
         config = admin_store.get_effective_config()
         assert config["rrf_k"] == 60  # Back to snapshot value
+
+    def test_store_usage_ordering(self, admin_store):
+        """Test search volume is tracked and ordered per store."""
+        admin_store.record_store_search("alpha")
+        admin_store.record_store_search("beta")
+        admin_store.record_store_search("beta")
+
+        usage = admin_store.get_store_usage()
+        assert list(usage)[:2] == ["beta", "alpha"]
+        assert usage["beta"]["search_count"] == 2
+        assert usage["beta"]["last_searched_at"] is not None
+
+    def test_connection_token(self, admin_store):
+        """Test connection tokens are issued once, hashed and verified."""
+        admin_store.set_connection("conn-test", {"id": "conn-test", "user_id": "u1"})
+
+        token = admin_store.issue_connection_token("conn-test")
+        assert token
+        assert admin_store.get_connections()["conn-test"]["token_hash"] != token
+        assert admin_store.verify_connection_token("conn-test", token) is True
+        assert admin_store.verify_connection_token("conn-test", "wrong") is False
+        assert admin_store.verify_connection_token("conn-test", None) is False
+
+        # Rotating invalidates the old token
+        rotated = admin_store.issue_connection_token("conn-test")
+        assert admin_store.verify_connection_token("conn-test", token) is False
+        assert admin_store.verify_connection_token("conn-test", rotated) is True
+
+        assert admin_store.issue_connection_token("conn-missing") is None
@@ -0,0 +1,74 @@
+"""
+Unit tests for alert sink routing and deduplication.
+"""
+import pytest
+from unittest.mock import MagicMock
+
+
+def _dispatcher(monkeypatch, sinks, dedup_window=300):
+    from src.services.admin import alert_sinks
+
+    values = {"alerts.sinks": sinks, "alerts.dedup_window_seconds": dedup_window}
+    monkeypatch.setattr(alert_sinks.settings, "get", lambda key, d=None: values.get(key, d))
+
+    seen = set()
+    deliveries = []
+    redis_client = MagicMock()
+    redis_client.set.side_effect = lambda key, value, nx, ex: (key not in seen, seen.add(key))[0]
+    redis_client.lpush.side_effect = lambda key, value: deliveries.insert(0, value)
+    redis_client.lrange.side_effect = lambda key, start, end: deliveries[start:end + 1]
+
+    dispatcher = alert_sinks.AlertDispatcher(redis_client=redis_client)
+    sent = []
+    monkeypatch.setattr(dispatcher, "deliver", lambda sink, alert: sent.append((sink["name"], alert["message"])))
+    return dispatcher, sent
+
+
+def _alert(severity="warning", source="connections.c1", message="Invalid token"):
+    return {"timestamp": "2024-01-01T00:00:00", "severity": severity, "source": source, "message": message}
+
+
+@pytest.mark.unit
+class TestAlertSinks:
+    """Test severity routing, source filters and dedup."""
+
+    def test_routes_by_severity_and_source(self, monkeypatch):
+        dispatcher, sent = _dispatcher(monkeypatch, [
+            {"name": "slack", "type": "slack", "url": "http://x", "min_severity": "warning"},
+            {"name": "pager", "type": "webhook", "url": "http://y", "min_severity": "critical"},
+            {"name": "sec", "type": "webhook", "url": "http://z", "min_severity": "info", "sources": ["connections."]},
+        ])
+
+        dispatcher.dispatch(_alert("warning"), background=False)
+        assert sorted(name for name, _ in sent) == ["sec", "slack"]
+
+        sent.clear()
+        dispatcher.dispatch(_alert("critical", source="inference.embed", message="reset"), background=False)
+        assert sorted(name for name, _ in sent) == ["pager", "slack"]
+
+    def test_duplicates_are_suppressed(self, monkeypatch):
+        dispatcher, sent = _dispatcher(monkeypatch, [{"name": "slack", "type": "slack", "url": "http://x"}])
+
+        dispatcher.dispatch(_alert(), background=False)
+        dispatcher.dispatch(_alert(), background=False)
+        dispatcher.dispatch(_alert(message="Unknown connection"), background=False)
+
+        assert len(sent) == 2
+        status = dispatcher.get_status()[0]
+        assert status["counts"] == {"delivered": 2, "failed": 0, "suppressed": 1}
+        assert status["last_status"] == "delivered"
+        # Sink URLs are never exposed
+        assert "url" not in status
+
+    def test_failed_delivery_is_recorded(self, monkeypatch):
+        dispatcher, _ = _dispatcher(monkeypatch, [{"name": "hook", "type": "webhook", "url": "http://x"}])
+
+        def fail(sink, alert):
+            raise RuntimeError("connection refused")
+        monkeypatch.setattr(dispatcher, "deliver", fail)
+
+        dispatcher.dispatch(_alert(), background=False)
+
+        delivery = dispatcher.get_deliveries()[0]
+        assert delivery["status"] == "failed"
+        assert delivery["error"] == "connection refused"
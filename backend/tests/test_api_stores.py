@@ -57,3 +57,27 @@ class TestStoresAPI:
         # Verify gone
         response = api_client.get(f"/api/v1/stores/{store_id}")
         assert response.status_code == 404
+
+    def test_upload_batch_queues_each_file(self, api_client):
+        store_id = f"upload-store-{uuid.uuid4()}"
+        api_client.post("/api/v1/stores", json={"id": store_id, "name": "Upload"})
+
+        files = [
+            ("files", ("src/app.py", b'print("hello")', "text/x-python")),
+            ("files", ("docs/guide.md", b"# Guide", "text/markdown")),
+        ]
+        with patch("src.api.v1.endpoints.ingest.ingest_file_task") as task, \
+                patch("src.services.ingestion.pipeline.check_admission", return_value=None):
+            task.delay.return_value.id = "task-1"
+            response = api_client.post(f"/api/v1/stores/{store_id}/upload", files=files)
+
+        assert response.status_code == 202, response.text
+        data = response.json()
+        assert data["queued"] == 2 and data["error"] == 0
+        assert [f["file"] for f in data["files"]] == ["src/app.py", "docs/guide.md"]
+        assert task.delay.call_args.kwargs["org_id"] == store_id
+
+    def test_upload_to_unknown_store(self, api_client):
+        files = [("files", ("a.py", b"x = 1", "text/x-python"))]
+        response = api_client.post(f"/api/v1/stores/missing-{uuid.uuid4()}/upload", files=files)
+        assert response.status_code == 404
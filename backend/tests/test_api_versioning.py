@@ -0,0 +1,60 @@
+"""
+Tests for API revision negotiation and deprecation headers.
+"""
+import asyncio
+
+import pytest
+from fastapi import HTTPException, Response
+
+from src.api import versioning
+from src.api.v1.endpoints.changes import api_changes
+from src.api.versioning import (
+    VERSION_HEADER,
+    changes_since,
+    current_version,
+    mark_deprecated,
+    negotiate,
+    version_headers,
+)
+
+
+def test_negotiate_accepts_known_revisions():
+    assert negotiate(None) is None
+    assert negotiate(current_version()) is None
+    assert negotiate("1.0") is None
+    assert "Unknown API version" in negotiate("9.9")
+
+
+def test_negotiate_refuses_below_minimum(monkeypatch):
+    monkeypatch.setattr(versioning.settings, "get", lambda key, default=None: "1.1" if key == "app.min_api_version" else default)
+    assert "no longer supported" in negotiate("1.0")
+    assert negotiate("1.1") is None
+
+
+def test_older_revision_links_to_changes():
+    headers = version_headers("1.0")
+    assert headers[VERSION_HEADER] == current_version()
+    assert "changes?since=1.0" in headers["Link"]
+    assert "Link" not in version_headers(current_version())
+    assert [v["version"] for v in changes_since("1.0")] == [v["version"] for v in versioning.VERSIONS[1:]]
+
+
+def test_deprecation_headers():
+    response = Response()
+    response.headers["Link"] = '<https://example.com>; rel="help"'
+    mark_deprecated(response, "search-hybrid")
+
+    assert response.headers["Deprecation"] == "@1792108800"
+    assert response.headers["Sunset"] == "Fri, 16 Apr 2027 00:00:00 GMT"
+    assert response.headers["Link"].startswith('<https://example.com>; rel="help", ')
+    assert 'rel="deprecation"' in response.headers["Link"]
+
+
+def test_changes_endpoint():
+    body = asyncio.run(api_changes(since="1.0"))
+    assert body["current"] == current_version()
+    assert all(v["version"] != "1.0" for v in body["versions"])
+    assert body["deprecations"][0]["id"] == "search-hybrid"
+
+    with pytest.raises(HTTPException):
+        asyncio.run(api_changes(since="latest"))
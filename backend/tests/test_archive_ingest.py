@@ -0,0 +1,119 @@
+"""
+Tests for zip/tar archive ingestion.
+"""
+import io
+import os
+import tarfile
+import zipfile
+
+import pytest
+
+from src.services.ingestion import archive
+from src.services.ingestion.archive import ArchiveError, archive_format, extract, ingest_archive, member_path
+
+
+@pytest.fixture
+def values(monkeypatch):
+    values = {}
+    real_get = archive.settings.get
+    monkeypatch.setattr(archive.settings, "get", lambda key, default=None: values.get(key, real_get(key, default)))
+    return values
+
+
+def _zip(path, members):
+    with zipfile.ZipFile(path, "w") as zf:
+        for name, data in members.items():
+            zf.writestr(name, data)
+    return str(path)
+
+
+def _tar(path, members, links=()):
+    with tarfile.open(path, "w:gz") as tf:
+        for name, data in members.items():
+            info = tarfile.TarInfo(name)
+            info.size = len(data)
+            tf.addfile(info, io.BytesIO(data))
+        for name, target in links:
+            info = tarfile.TarInfo(name)
+            info.type = tarfile.SYMTYPE
+            info.linkname = target
+            tf.addfile(info)
+    return str(path)
+
+
+def test_member_paths_and_formats():
+    assert member_path("./src\\app.py") == "src/app.py"
+    for bad in ("/etc/passwd", "../up.py", "src/../../up.py", "C:/win.py"):
+        with pytest.raises(ArchiveError):
+            member_path(bad)
+    assert archive_format(b"PK\x03\x04rest") == "zip"
+    assert archive_format(b"\x1f\x8b\x08") == "tar"
+    assert archive_format(b"just text") is None
+
+
+def test_zip_extracts_indexable_files_only(tmp_path, values):
+    path = _zip(tmp_path / "a.zip", {"src/app.py": "x = 1", "README.md": "# hi", "logo.png": "\x89PNG", "docs/": ""})
+    dest = tmp_path / "out"
+    dest.mkdir()
+    files, skipped = extract(path, str(dest))
+    assert sorted(files) == ["README.md", "src/app.py"] and skipped == 1
+    assert (dest / "src" / "app.py").read_text() == "x = 1"
+
+
+def test_unsafe_archives_are_refused(tmp_path, values):
+    dest = tmp_path / "out"
+    dest.mkdir()
+    with pytest.raises(ArchiveError):
+        extract(_zip(tmp_path / "evil.zip", {"../../escape.py": "x"}), str(dest))
+    assert not (tmp_path / "escape.py").exists()
+
+    # Links are skipped, never followed
+    path = _tar(tmp_path / "links.tar.gz", {"ok.py": b"y = 2"}, links=[("passwd.txt", "/etc/passwd")])
+    files, skipped = extract(path, str(dest))
+    assert files == ["ok.py"] and skipped == 1
+    assert not (dest / "passwd.txt").exists()
+
+    with pytest.raises(ArchiveError):
+        extract(_write(tmp_path / "x.bin", b"not an archive"), str(dest))
+
+
+def _write(path, data):
+    path.write_bytes(data)
+    return str(path)
+
+
+def test_limits_count_files_and_written_bytes(tmp_path, values):
+    dest = tmp_path / "out"
+    dest.mkdir()
+    values["indexing.archive.max_files"] = 1
+    with pytest.raises(ArchiveError, match="max_files"):
+        extract(_zip(tmp_path / "many.zip", {"a.py": "1", "b.py": "2"}), str(dest))
+
+    values["indexing.archive.max_files"] = 0
+    values["indexing.archive.max_extracted_mb"] = 1
+    big = b"x" * (archive.MB + 1)
+    with pytest.raises(ArchiveError, match="max_extracted_mb"):
+        extract(_tar(tmp_path / "big.tar.gz", {"big.py": big}), str(dest))
+
+
+def test_ingest_archive_indexes_under_prefix_and_cleans_up(tmp_path, values, monkeypatch):
+    from src.services.ingestion import indexer
+
+    calls = []
+
+    class FakeIndexer:
+        def __init__(self, qdrant_client=None):
+            pass
+
+        def ingest_file(self, file_path, display_path, repo_name, org_id):
+            calls.append((display_path, open(file_path).read(), org_id))
+            return {"status": "success"}
+
+    monkeypatch.setattr(indexer, "Indexer", FakeIndexer)
+    values["indexing.archive.work_dir"] = str(tmp_path)
+    path = _tar(tmp_path / "build.tar.gz", {"src/app.py": b"x = 1", "bin/tool": b"\x00\x01"})
+
+    result = ingest_archive(path, "backend", prefix="billing", qdrant_client=object())
+    assert calls == [("billing/src/app.py", "x = 1", "backend")]
+    assert (result["files"], result["indexed"], result["skipped"]) == (1, 1, 1)
+    assert os.listdir(tmp_path) == []
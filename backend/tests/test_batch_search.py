@@ -0,0 +1,73 @@
+"""Tests for batch search (shared query encoding, per-query results)."""
+
+import asyncio
+from unittest.mock import MagicMock
+
+from src.services.search import retriever as retriever_module
+from src.services.search.filters import SearchFilters
+from src.services.search.retriever import MultiRetriever
+
+
+def _retriever(monkeypatch, calls):
+    async def fake_embed(texts):
+        calls.append(("dense", list(texts)))
+        return [[float(i)] for i in range(len(texts))]
+
+    monkeypatch.setattr(retriever_module, "embed_texts_async", fake_embed)
+    monkeypatch.setattr(
+        "src.services.retrieval.bm25_index.get_store_sparse_backend", lambda org_id: "splade"
+    )
+
+    r = MultiRetriever()
+    r._splade_encoder = MagicMock()
+    r._splade_encoder.encode.side_effect = lambda texts: [f"splade:{t}" for t in texts]
+    r._bm42_encoder = MagicMock()
+    r._bm42_encoder.encode.side_effect = lambda texts: [f"bm42:{t}" for t in texts]
+    return r
+
+
+def test_encode_queries_batches_each_representation(monkeypatch):
+    calls = []
+    r = _retriever(monkeypatch, calls)
+
+    encoded = asyncio.run(r.encode_queries(["a", "b"], "acme"))
+
+    assert calls == [("dense", ["a", "b"])]
+    r._splade_encoder.encode.assert_called_once_with(["a", "b"])
+    r._bm42_encoder.encode.assert_called_once_with(["a", "b"])
+    assert encoded == [
+        {"dense": [0.0], "bm42": "bm42:a", "splade": "splade:a"},
+        {"dense": [1.0], "bm42": "bm42:b", "splade": "splade:b"},
+    ]
+
+
+def test_encode_queries_falls_back_when_a_batch_fails(monkeypatch):
+    r = _retriever(monkeypatch, [])
+    r._bm42_encoder.encode.side_effect = RuntimeError("model not loaded")
+
+    encoded = asyncio.run(r.encode_queries(["a"], "acme", use_splade=False))
+
+    assert encoded == [{"dense": [0.0]}]
+
+
+def test_search_batch_passes_vectors_and_filters_per_query(monkeypatch):
+    r = _retriever(monkeypatch, [])
+    seen = []
+
+    async def fake_search(query, limit, org_id, filters, encoded, **options):
+        seen.append((query, filters, encoded["splade"], options))
+        if query == "boom":
+            raise ValueError("bad query")
+        return [{"chunk_id": query}]
+
+    r.search = fake_search
+    only_go = SearchFilters(extensions=["go"])
+
+    results = asyncio.run(r.search_batch(
+        ["a", "boom"], limit=3, org_id="acme", filters=[only_go, None], use_bm25=False
+    ))
+
+    assert results[0] == [{"chunk_id": "a"}]
+    assert isinstance(results[1], ValueError)
+    assert seen[0] == ("a", only_go, "splade:a", {"use_bm25": False})
+    assert seen[1][1] is None
@@ -0,0 +1,80 @@
+"""
+Unit tests for the BM25 sparse backend inverted index.
+"""
+import pytest
+
+
+def _index():
+    import redis
+    from src.services.retrieval.bm25_index import BM25Index
+
+    client = redis.Redis()
+    client.flushdb()
+    return BM25Index(redis_client=client)
+
+
+@pytest.mark.unit
+class TestTokenize:
+    """Test code-aware tokenization."""
+
+    def test_splits_identifiers(self):
+        from src.services.retrieval.bm25_index import tokenize
+
+        tokens = tokenize("func (mux *ServeMux) HandleFunc(pattern string)")
+
+        assert "handlefunc" in tokens
+        assert "handle" in tokens and "func" in tokens
+        assert "servemux" in tokens and "serve" in tokens and "mux" in tokens
+
+    def test_snake_case_and_short_tokens(self):
+        from src.services.retrieval.bm25_index import tokenize
+
+        tokens = tokenize("max_retry_count = 3 if x")
+
+        assert "max_retry_count" in tokens and "retry" in tokens
+        assert "x" not in tokens and "3" not in tokens
+
+
+@pytest.mark.unit
+class TestBM25Index:
+    """Test indexing, scoring and removal."""
+
+    def test_ranks_matching_chunk_first(self):
+        index = _index()
+        index.add("docs", [
+            ("a", "mutex lock unlock mutex"),
+            ("b", "wait group done wait"),
+            ("c", "lock free queue"),
+        ])
+
+        results = index.search("docs", "mutex lock", limit=10)
+
+        assert [chunk_id for chunk_id, _ in results] == ["a", "c"]
+        assert results[0][1] > results[1][1]
+
+    def test_stores_are_isolated(self):
+        index = _index()
+        index.add("docs", [("a", "mutex lock")])
+        index.add("other", [("b", "mutex lock")])
+
+        assert [c for c, _ in index.search("docs", "mutex")] == ["a"]
+        assert index.stats("docs") == {"chunks": 1, "avg_chunk_tokens": 2.0}
+
+    def test_remove_drops_postings_and_length(self):
+        index = _index()
+        index.add("docs", [("a", "mutex lock"), ("b", "mutex unlock unlock")])
+
+        assert index.remove(["a", "never-indexed"]) == 1
+
+        assert [c for c, _ in index.search("docs", "mutex lock")] == ["b"]
+        assert index.stats("docs") == {"chunks": 1, "avg_chunk_tokens": 3.0}
+
+    def test_clear_store(self):
+        index = _index()
+        index.add("docs", [("a", "mutex lock")])
+        index.add("other", [("b", "mutex lock")])
+
+        index.clear("docs")
+
+        assert index.search("docs", "mutex") == []
+        assert index.stats("other")["chunks"] == 1
@@ -0,0 +1,110 @@
+"""
+Tests for per-store query-time boosting rules.
+"""
+import pytest
+
+from src.services.retrieval.fusion import FusedResult
+from src.services.search.boosting import (
+    DAY_SECONDS,
+    InvalidRule,
+    apply_boosts,
+    normalize_recency,
+    normalize_rules,
+    query_language,
+    recency_decay,
+)
+from src.services.search.explain import SearchExplainer
+
+
+RULES = normalize_rules([
+    {"type": "path_prefix", "value": "src/", "factor": 1.2},
+    {"type": "path_glob", "value": "*_test.go", "factor": 0.5},
+    {"type": "query_language", "value": "ignored", "factor": 2},
+])
+
+
+def _results():
+    return [
+        FusedResult("test", 0.030, payload={"full_path": "src/retry_test.go", "language": "go"}),
+        FusedResult("docs", 0.029, payload={"full_path": "docs/retry.md", "language": "markdown"}),
+        FusedResult("impl", 0.028, payload={"full_path": "src/retry.go", "language": "go"}),
+        FusedResult("py", 0.027, payload={"full_path": "tools/retry.py", "language": "python"}),
+    ]
+
+
+def test_rules_are_validated():
+    assert RULES[2] == {"type": "query_language", "value": None, "factor": 2.0}
+    assert normalize_rules([{"type": "language", "value": " Go ", "factor": "1.1"}])[0]["value"] == "go"
+    for rule in (
+        {"type": "owner", "value": "x", "factor": 1.2},
+        {"type": "path_prefix", "value": " ", "factor": 1.2},
+        {"type": "path_glob", "value": "*.go", "factor": 0},
+        {"type": "language", "value": "go", "factor": "high"},
+    ):
+        with pytest.raises(InvalidRule):
+            normalize_rules([rule])
+
+
+def test_boosts_reorder_and_multiply():
+    results = _results()
+    applied = apply_boosts(results, RULES, "retry with backoff")
+    assert [r.chunk_id for r in results] == ["impl", "docs", "py", "test"]
+    assert results[0].fused_score == pytest.approx(0.028 * 1.2)
+    # Both path rules match the test file
+    assert results[3].fused_score == pytest.approx(0.030 * 1.2 * 0.5)
+    assert [rule["type"] for rule in applied["test"]] == ["path_prefix", "path_glob"]
+    assert applied["test"][0]["score_before"] == 0.030
+    assert "docs" not in applied
+
+    assert apply_boosts(_results(), [], "retry") == {}
+
+
+def test_query_language_boosts_the_language_asked_for():
+    assert query_language("retry helper in python") == "python"
+    assert query_language("retry helper") is None
+
+    results = _results()
+    applied = apply_boosts(results, [RULES[2]], "retry helper in python")
+    assert results[0].chunk_id == "py"
+    assert applied["py"] == [{"type": "query_language", "value": "python", "factor": 2.0, "score_before": 0.027}]
+
+
+def test_explanation_lists_applied_rules():
+    results = _results()
+    applied = apply_boosts(results, RULES, "retry")
+    output = [{"chunk_id": r.chunk_id, "score": r.fused_score, **r.payload} for r in results]
+
+    explainer = SearchExplainer(rrf_k=60)
+    explainer.record_fusion(results, output)
+    explainer.record_boosts(applied)
+    explainer.annotate(output, reranked=False)
+
+    explanation = output[3]["explanation"]
+    assert [b["factor"] for b in explanation["boosts"]] == [1.2, 0.5]
+    assert "boost: x1.2 (path_prefix src/)" in explanation["stages"]
+    assert "boost: x0.5 (path_glob *_test.go)" in explanation["stages"]
+    assert output[1]["explanation"]["boosts"] == []
+
+
+def test_recency_favours_fresh_files():
+    now = 1_800_000_000.0
+    results = [
+        FusedResult("stale", 0.030, payload={"indexed_at": now - 200 * DAY_SECONDS}),
+        FusedResult("fresh", 0.028, payload={"indexed_at": now - 1}),
+        FusedResult("legacy", 0.027, payload={}),
+        FusedResult("week", 0.026, payload={"indexed_at": now - 7 * DAY_SECONDS}),
+    ]
+    applied = recency_decay(results, normalize_recency(7, 0.5), now=now)
+
+    assert [r.chunk_id for r in results] == ["fresh", "week", "stale", "legacy"]
+    assert results[0].fused_score == pytest.approx(0.028 * 1.5)
+    # One half-life old: half the boost
+    assert applied["week"][0]["factor"] == pytest.approx(1.25)
+    assert applied["stale"][0]["factor"] == pytest.approx(1.0, abs=1e-4)
+    assert "legacy" not in applied
+
+    assert recency_decay(results, None) == {}
+    assert normalize_recency(None) is None
+    for bad in ((0, 0.5), (7, -1), (7, 11)):
+        with pytest.raises(InvalidRule):
+            normalize_recency(*bad)
@@ -0,0 +1,119 @@
+"""
+Unit tests for event bus backends and request/reply work topics.
+"""
+import queue
+import threading
+
+import pytest
+
+from src.services.events import backends
+from src.services.events.backends import BusBackend, NatsBackend, RedisStreamsBackend
+from src.services.events.bus import EventBus
+
+
+class InMemoryBackend(BusBackend):
+    """Single-process stand-in for Redis/NATS."""
+
+    name = "memory"
+
+    def __init__(self):
+        self.entries = []
+        self.requests = {}
+
+    def add(self, topic, fields, max_len):
+        self.entries.append((str(len(self.entries) + 1), fields))
+        return self.entries[-1][0]
+
+    def latest_id(self):
+        return self.entries[-1][0] if self.entries else "0"
+
+    def rev_range(self, max_id, count):
+        entries = [e for e in self.entries if max_id == "+" or int(e[0]) <= int(max_id)]
+        return list(reversed(entries))[:count]
+
+    def read_after(self, last_id, count, block_ms):
+        return [e for e in self.entries if int(e[0]) > int(last_id)][:count]
+
+    def request(self, topic, data, timeout):
+        reply = queue.Queue()
+        self.requests.setdefault(topic, queue.Queue()).put((data, reply))
+        try:
+            return reply.get(timeout=timeout)
+        except queue.Empty:
+            raise TimeoutError(topic)
+
+    def serve(self, topic, handler, stop):
+        requests = self.requests.setdefault(topic, queue.Queue())
+        while not stop.is_set():
+            try:
+                data, reply = requests.get(timeout=0.05)
+            except queue.Empty:
+                continue
+            reply.put(handler(data))
+
+
+def _serving_bus(topic, handler):
+    bus = EventBus(backend=InMemoryBackend())
+    stop = threading.Event()
+    threading.Thread(target=bus.serve, args=(topic, handler, stop), daemon=True).start()
+    return bus, stop
+
+
+@pytest.mark.unit
+class TestBusBackends:
+    """Test backend selection, event reads and request/reply."""
+
+    def test_create_backend_from_config(self, monkeypatch):
+        values = {"events.backend": "nats"}
+        monkeypatch.setattr(backends.settings, "get", lambda key, d=None: values.get(key, d))
+        assert isinstance(backends.create_backend(), NatsBackend)
+
+        values["events.backend"] = "kafka"
+        assert isinstance(backends.create_backend(), RedisStreamsBackend)
+
+    def test_events_round_trip_through_backend(self, monkeypatch):
+        from src.services.events import bus as bus_module
+
+        monkeypatch.setattr(bus_module.settings, "get", lambda key, d=None: False if key == "events.journal.enabled" else d)
+        bus = EventBus(backend=InMemoryBackend())
+        start = bus.backend.latest_id()
+        bus.publish("index.file.success", {"path": "a.py"})
+        bus.publish("alert.warning", {"message": "x"})
+
+        _, events = bus.read(start, ["index.*"], block_ms=0)
+        assert [e["payload"]["path"] for e in events] == ["a.py"]
+        assert [e["topic"] for e in bus.recent(limit=10)] == ["index.file.success", "alert.warning"]
+
+    def test_request_reply(self):
+        bus, stop = _serving_bus(
+            "inference.rerank", lambda req: {"scores": [len(d) for d in req["documents"]]}
+        )
+        try:
+            assert bus.request("inference.rerank", {"documents": ["a", "abc"]}, timeout=2) == {"scores": [1, 3]}
+        finally:
+            stop.set()
+
+    def test_worker_errors_raise(self, monkeypatch):
+        from src.services.events import dead_letters
+
+        def fail(req):
+            raise ValueError("model not loaded")
+
+        # Fail on the first attempt, into a throwaway dead-letter store
+        monkeypatch.setattr(dead_letters.settings, "get", lambda key, d=None: 1 if key == "events.retry.max_attempts" else d)
+        monkeypatch.setattr(dead_letters, "get_dead_letter_store", lambda: type("Store", (), {"add": lambda *a: None})())
+        bus, stop = _serving_bus("inference.embed", fail)
+        try:
+            with pytest.raises(RuntimeError, match="model not loaded"):
+                bus.request("inference.embed", {"texts": ["x"]}, timeout=2)
+        finally:
+            stop.set()
+
+    def test_request_without_worker_times_out(self):
+        bus = EventBus(backend=InMemoryBackend())
+        with pytest.raises(TimeoutError):
+            bus.request("inference.embed", {"texts": ["x"]}, timeout=0.1)
+
+    def test_nats_ids_accept_redis_style(self):
+        assert NatsBackend._seq("42") == 42
+        assert NatsBackend._seq("0-0") == 0
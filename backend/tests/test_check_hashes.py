@@ -0,0 +1,52 @@
+"""
+Tests for the pre-flight hash check that lets clients skip unchanged uploads.
+"""
+from types import SimpleNamespace
+
+from src.services.ingestion import reindex_plan
+from src.services.ingestion.hashing import HASH_VERSION
+from src.services.ingestion.reindex_plan import ReindexPlanner
+
+
+def _chunk(path, file_hash, hash_version=HASH_VERSION, store="backend"):
+    return SimpleNamespace(payload={
+        "org_id": store, "full_path": path, "file_hash": file_hash, "hash_version": hash_version,
+    })
+
+
+def _planner(monkeypatch, qdrant, points, **config):
+    monkeypatch.setattr(reindex_plan.settings, "get", lambda key, default=None: config.get(key, default))
+    qdrant.collections["rice_chunks"] = points
+    return ReindexPlanner(qdrant)
+
+
+def test_only_new_and_changed_files_are_uploaded(monkeypatch, fake_qdrant):
+    planner = _planner(monkeypatch, fake_qdrant, [
+        _chunk("/src/a.py", "aaa"), _chunk("/src/a.py", "aaa"),
+        _chunk("/src/b.py", "old"),
+        _chunk("/src/c.py", "ccc", hash_version=HASH_VERSION - 1),
+        _chunk("/src/d.py", "ddd", store="other"),
+    ])
+    result = planner.check_hashes("backend", [
+        {"path": "/src/a.py", "hash": "aaa"},
+        {"path": "/src/b.py", "hash": "new"},
+        {"path": "/src/c.py", "hash": "ccc"},
+        {"path": "/src/d.py", "hash": "ddd"},
+    ])
+    assert result["upload"] == ["/src/b.py", "/src/c.py", "/src/d.py"]
+    assert result["unchanged"] == 1
+    assert result["hash_version"] == HASH_VERSION
+
+
+def test_paths_are_looked_up_in_batches(monkeypatch, fake_qdrant):
+    planner = _planner(monkeypatch, fake_qdrant, [_chunk(f"/f{i}.py", str(i)) for i in range(5)])
+    stored = planner.stored_hashes("backend", [f"/f{i}.py" for i in range(5)], batch_size=2)
+    assert len(planner.qdrant.scrolls) == 3
+    assert stored["/f4.py"] == ("4", HASH_VERSION)
+
+
+def test_skip_unchanged_disabled_uploads_everything(monkeypatch, fake_qdrant):
+    planner = _planner(monkeypatch, fake_qdrant, [_chunk("/src/a.py", "aaa")], **{"indexing.skip_unchanged": False})
+    result = planner.check_hashes("backend", [{"path": "/src/a.py", "hash": "aaa"}])
+    assert result["upload"] == ["/src/a.py"] and result["unchanged"] == 0
+    assert planner.qdrant.scrolls == []
@@ -0,0 +1,122 @@
+"""
+Tests for content-addressed chunk IDs and redirects of replaced chunks.
+"""
+import json
+import time
+from types import SimpleNamespace
+
+from src.services.ingestion.chunk_redirects import ChunkRedirects, plan_redirects
+from src.services.ingestion.hashing import chunk_content_hash, chunk_id
+from src.services.search import chunks as chunks_module
+
+
+class FakePipeline:
+    def __init__(self, redis):
+        self.redis = redis
+        self.ops = []
+
+    def hdel(self, key, *fields):
+        self.ops.append(lambda: self.redis.hdel(key, *fields))
+
+    def hset(self, key, mapping):
+        self.ops.append(lambda: self.redis.hashes.setdefault(key, {}).update(mapping))
+
+    def execute(self):
+        for op in self.ops:
+            op()
+
+
+class FakeRedis:
+    def __init__(self):
+        self.hashes = {}
+
+    def pipeline(self):
+        return FakePipeline(self)
+
+    def hget(self, key, field):
+        return self.hashes.get(key, {}).get(field)
+
+    def hdel(self, key, *fields):
+        for field in fields:
+            self.hashes.get(key, {}).pop(field, None)
+
+    def delete(self, key):
+        self.hashes.pop(key, None)
+
+
+def _chunk(path, index, content, start, end, store="backend"):
+    digest = chunk_content_hash(content)
+    return {
+        "chunk_id": chunk_id(store, path, digest, index),
+        "chunk_index": index,
+        "content_hash": digest,
+        "start_line": start,
+        "end_line": end,
+    }
+
+
+def test_chunk_ids_are_stable_and_scoped():
+    first = _chunk("src/retry.py", 0, "def retry(): ...", 1, 10)
+    assert first == _chunk("src/retry.py", 0, "def retry(): ...", 1, 10)
+    assert first["chunk_id"] != _chunk("src/retry.py", 0, "def retry(): ...", 1, 10, store="frontend")["chunk_id"]
+    assert first["chunk_id"] != _chunk("src/retry.py", 1, "def retry(): ...", 1, 10)["chunk_id"]
+    assert first["chunk_id"] != _chunk("src/retry.py", 0, "def retry(): pass", 1, 10)["chunk_id"]
+
+
+def test_replaced_chunks_go_to_the_nearest_new_chunk():
+    old = [
+        _chunk("a.py", 0, "imports", 1, 5),
+        _chunk("a.py", 1, "def helper", 6, 20),
+        _chunk("a.py", 2, "def main", 21, 40),
+    ]
+    new = [
+        old[0],
+        _chunk("a.py", 1, "def main", 6, 25),          # main moved up, helper deleted
+        _chunk("a.py", 2, "def main2", 26, 60),
+    ]
+    redirects = plan_redirects(old, new)
+    assert old[0]["chunk_id"] not in redirects
+    # Same content wins over position
+    assert redirects[old[2]["chunk_id"]] == new[1]["chunk_id"]
+    # Otherwise the chunk overlapping the old lines most
+    assert redirects[old[1]["chunk_id"]] == new[1]["chunk_id"]
+    assert plan_redirects(old, []) == {}
+
+
+def test_redirects_chain_expire_and_clear_on_revert():
+    redirects = ChunkRedirects(redis_client=FakeRedis())
+    v1 = [_chunk("a.py", 0, "v1", 1, 10)]
+    v2 = [_chunk("a.py", 0, "v2", 1, 10)]
+    v3 = [_chunk("a.py", 0, "v3", 1, 10)]
+
+    assert redirects.record("backend", v1, v2) == 1
+    redirects.record("backend", v2, v3)
+    assert redirects.resolve("backend", v1[0]["chunk_id"]) == v3[0]["chunk_id"]
+    assert redirects.resolve("backend", v3[0]["chunk_id"]) is None
+    assert redirects.resolve("frontend", v1[0]["chunk_id"]) is None
+
+    # Reverting to v1 makes its ID live again
+    redirects.record("backend", v3, v1)
+    assert redirects.resolve("backend", v1[0]["chunk_id"]) is None
+
+    key = "rice:chunk_redirects:backend"
+    redirects.redis.hashes[key][v2[0]["chunk_id"]] = json.dumps({"to": v3[0]["chunk_id"], "at": time.time() - 91 * 86400})
+    assert redirects.resolve("backend", v2[0]["chunk_id"]) is None
+    assert v2[0]["chunk_id"] not in redirects.redis.hashes[key]
+
+
+def test_get_chunk_follows_redirects(monkeypatch):
+    payload = {"org_id": "backend", "text": "v2", "full_path": "a.py"}
+
+    class FakeQdrant:
+        def retrieve(self, collection_name, ids, with_payload, with_vectors):
+            return [SimpleNamespace(id="new", payload=payload)] if ids == ["new"] else []
+
+    monkeypatch.setattr(
+        "src.services.ingestion.chunk_redirects.get_chunk_redirects",
+        lambda: SimpleNamespace(resolve=lambda org_id, chunk_id: "new" if chunk_id == "old" else None),
+    )
+    assert chunks_module.get_chunk("new", "backend", FakeQdrant())["chunk_id"] == "new"
+    chunk = chunks_module.get_chunk("old", "backend", FakeQdrant())
+    assert chunk["chunk_id"] == "new" and chunk["redirected_from"] == "old"
+    assert chunks_module.get_chunk("gone", "backend", FakeQdrant()) is None
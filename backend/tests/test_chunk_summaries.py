@@ -0,0 +1,106 @@
+"""Tests for LLM chunk summaries at index time."""
+
+import asyncio
+
+from src.services.ingestion.doc_vectors import doc_texts
+from src.services.ingestion.privacy import strip_content
+from src.services.ingestion.summaries import ChunkSummarizer, clean_summary, store_summaries
+
+
+class FakeAdminStore:
+    def __init__(self, stores):
+        self.stores = stores
+
+    def get_stores(self):
+        return self.stores
+
+
+class FakeLLM:
+    def __init__(self, answers=None, delay=0.01):
+        self.answers = answers or {}
+        self.delay = delay
+        self.prompts = []
+        self.active = 0
+        self.peak = 0
+
+    async def complete(self, prompt, max_tokens=None, temperature=None, timeout=None):
+        self.prompts.append(prompt)
+        self.active += 1
+        self.peak = max(self.peak, self.active)
+        await asyncio.sleep(self.delay)
+        self.active -= 1
+        for key, answer in self.answers.items():
+            if key in prompt:
+                return answer
+        return "Summary: Uploads a file again after a network failure. It gives up after three tries."
+
+
+def _stores(monkeypatch, stores):
+    from src.services.admin import admin_store
+    monkeypatch.setattr(admin_store, "get_admin_store", lambda: FakeAdminStore(stores))
+
+
+def _chunk(content, language="python", symbols=None):
+    return {"content": content, "metadata": {"language": language, "symbols": symbols or [], "file_path": "up.py"}}
+
+
+CODE = "def retry_upload(path):\n" + "    send(path)\n" * 10
+
+
+def test_summaries_are_off_unless_the_store_turns_them_on(monkeypatch):
+    _stores(monkeypatch, {
+        "backend": {"summaries": {"enabled": True, "max_concurrency": 4}},
+        "secret": {"summaries": {"enabled": True}, "privacy_mode": True},
+        "docs": {},
+    })
+    assert store_summaries("backend") == {"enabled": True, "max_concurrency": 4}
+    assert store_summaries("docs") == {"enabled": False, "max_concurrency": 2}
+    # Summaries are derived content: never for privacy-mode stores
+    assert store_summaries("secret")["enabled"] is False
+
+
+def test_clean_summary_keeps_the_first_sentence():
+    assert clean_summary('Summary: "Parses the config file. Then validates it."') == "Parses the config file."
+    assert clean_summary("  Here is a summary:\nReads   rows\nfrom the cache") == "Reads rows from the cache"
+    assert clean_summary("") is None
+    assert clean_summary(None) is None
+
+
+def test_summarize_limits_concurrency_and_skips_short_chunks():
+    llm = FakeLLM()
+    chunks = [_chunk(CODE, symbols=["retry_upload"]) for _ in range(6)] + [_chunk("x = 1")]
+    summaries = ChunkSummarizer(llm).summarize(chunks, max_concurrency=2)
+
+    assert summaries[:6] == ["Uploads a file again after a network failure."] * 6
+    assert summaries[6] is None
+    assert len(llm.prompts) == 6
+    assert llm.peak == 2
+    assert "python code (retry_upload) from up.py" in llm.prompts[0]
+
+
+def test_a_failed_call_stops_summarizing_the_file():
+    llm = FakeLLM(answers={"broken": None})
+    chunks = [_chunk("# broken\n" + CODE)] + [_chunk(CODE) for _ in range(4)]
+    summaries = ChunkSummarizer(llm).summarize(chunks, max_concurrency=1)
+    assert summaries == [None] * 5
+    assert len(llm.prompts) == 1
+
+
+def test_summaries_lead_the_doc_vector_text():
+    chunks = [
+        _chunk("def f(x):\n    return x + 1\n"),
+        _chunk("# Install\n\nRun make.", language="markdown"),
+        _chunk("def g(): pass"),
+    ]
+    texts = doc_texts(chunks, ["Adds one to a number for the counter.", "Explains how to install.", None])
+    # Code without prose gets a doc vector from its summary
+    assert texts[0] == "Adds one to a number for the counter."
+    # Docs embed summary and text instead of reusing the dense vector
+    assert texts[1] == "Explains how to install.\n# Install\n\nRun make."
+    assert texts[2] is None
+    assert doc_texts(chunks) == [None, "", None]
+
+
+def test_private_payloads_drop_summaries():
+    payload = {"text": "x", "summary": "Adds one.", "full_path": "a.py"}
+    assert strip_content(payload) == {"full_path": "a.py"}
@@ -0,0 +1,194 @@
+"""
+Unit tests for dependency circuit breakers and degraded searches.
+"""
+import asyncio
+import pytest
+from unittest.mock import MagicMock
+
+
+def _settings(monkeypatch, **values):
+    from src.core import circuit_breaker
+
+    values = {f"resilience.circuit_breaker.{k.replace('__', '.')}": v for k, v in values.items()}
+    monkeypatch.setattr(circuit_breaker.settings, "get", lambda key, default=None: values.get(key, default))
+
+
+def _clock(monkeypatch, start=1000.0):
+    from src.core import circuit_breaker
+
+    now = [start]
+    monkeypatch.setattr(circuit_breaker.time, "monotonic", lambda: now[0])
+    return now
+
+
+async def _fail():
+    raise ConnectionError("connection refused")
+
+
+async def _fast():
+    return "ok"
+
+
+@pytest.mark.unit
+class TestCircuitBreaker:
+    """Test breaker state transitions."""
+
+    def test_opens_after_threshold_and_fails_fast(self, monkeypatch):
+        """Consecutive failures open the breaker; calls are then refused."""
+        from src.core.circuit_breaker import CircuitBreaker, CircuitOpenError, OPEN
+
+        _settings(monkeypatch, failure_threshold=3, reset_seconds=30)
+        _clock(monkeypatch)
+        breaker = CircuitBreaker("qdrant")
+
+        for _ in range(2):
+            breaker.before_call()
+            breaker.record_failure(ConnectionError("down"))
+        breaker.before_call()
+        breaker.record_success()
+        assert breaker.failures == 0
+
+        for _ in range(3):
+            breaker.before_call()
+            breaker.record_failure(ConnectionError("down"))
+
+        assert breaker.state == OPEN
+        assert breaker.is_open
+        with pytest.raises(CircuitOpenError) as exc:
+            breaker.before_call()
+        assert exc.value.name == "qdrant"
+        assert breaker.snapshot()["retry_in_seconds"] == 30
+
+    def test_probe_closes_or_reopens(self, monkeypatch):
+        """After reset_seconds one probe goes through; its outcome decides."""
+        from src.core.circuit_breaker import CircuitBreaker, CircuitOpenError, CLOSED, HALF_OPEN, OPEN
+
+        _settings(monkeypatch, failure_threshold=1, reset_seconds=10)
+        now = _clock(monkeypatch)
+        breaker = CircuitBreaker("rerank")
+        breaker.record_failure(TimeoutError("slow"))
+
+        now[0] += 10
+        breaker.before_call()
+        assert breaker.state == HALF_OPEN
+        with pytest.raises(CircuitOpenError):
+            breaker.before_call()
+
+        breaker.record_failure(TimeoutError("still slow"))
+        assert breaker.state == OPEN
+        assert breaker.trips == 2
+
+        now[0] += 10
+        breaker.before_call()
+        breaker.record_success()
+        assert breaker.state == CLOSED
+        assert not breaker.is_open
+
+    def test_per_dependency_override_and_disable(self, monkeypatch):
+        """Per-name keys override the global ones; disabled breakers never refuse."""
+        from src.core.circuit_breaker import CircuitBreaker
+
+        _settings(monkeypatch, failure_threshold=5, rerank__failure_threshold=2, embed__enabled=False)
+        assert CircuitBreaker("rerank").failure_threshold == 2
+        assert CircuitBreaker("qdrant").failure_threshold == 5
+
+        breaker = CircuitBreaker("embed")
+        for _ in range(10):
+            breaker.record_failure(ConnectionError("down"))
+        breaker.before_call()
+        assert not breaker.is_open
+
+    def test_registry_metrics(self, monkeypatch):
+        """Open breakers are listed and exported as a gauge."""
+        from src.core.circuit_breaker import BreakerRegistry
+
+        _settings(monkeypatch, failure_threshold=1)
+        registry = BreakerRegistry()
+        registry.get("qdrant").record_failure(ConnectionError("down"))
+        registry.get("embed")
+
+        assert registry.open_names() == ["qdrant"]
+        lines = registry.prometheus_lines()
+        assert 'rice_search_circuit_breaker_state{dependency="qdrant"} 2' in lines
+        assert 'rice_search_circuit_breaker_state{dependency="embed"} 0' in lines
+
+
+@pytest.mark.unit
+class TestWatchdogBreaker:
+    """Test the breaker in front of inference calls."""
+
+    def test_failing_model_fails_fast(self, monkeypatch):
+        """Once open, the model is not called until the reset time."""
+        from src.core import circuit_breaker
+        from src.core.circuit_breaker import CircuitOpenError
+        from src.services.inference import watchdog
+
+        _settings(monkeypatch, failure_threshold=2, reset_seconds=30)
+        monkeypatch.setattr(circuit_breaker, "_breakers", circuit_breaker.BreakerRegistry())
+        dog = watchdog.InferenceWatchdog()
+        monkeypatch.setattr(dog, "_store", lambda: MagicMock())
+
+        for _ in range(2):
+            with pytest.raises(ConnectionError):
+                asyncio.run(dog.run(watchdog.RERANK, _fail, timeout=1))
+
+        call = MagicMock(side_effect=_fast)
+        with pytest.raises(CircuitOpenError):
+            asyncio.run(dog.run(watchdog.RERANK, call, timeout=1))
+        call.assert_not_called()
+        # Other kinds are unaffected
+        assert asyncio.run(dog.run(watchdog.EMBED, _fast, timeout=1)) == "ok"
+
+
+@pytest.mark.unit
+class TestDegradation:
+    """Test degradation notes."""
+
+    def test_notes_collected_inside_track_only(self):
+        """Notes reach the enclosing (outermost) track block and nothing else."""
+        from src.services.search import degradation
+
+        degradation.note("ignored outside a search")
+        with degradation.track() as outer:
+            with degradation.track() as inner:
+                degradation.note("reranking skipped")
+                degradation.note("reranking skipped")
+            assert inner is outer
+        assert outer == ["reranking skipped"]
+
+        with degradation.track() as fresh:
+            assert fresh == []
+
+    def test_concurrent_retrievers_share_notes(self):
+        """Tasks gathered inside a track block add to its list."""
+        from src.services.search import degradation
+
+        async def retriever(name):
+            degradation.note(f"{name} retrieval skipped")
+
+        async def search():
+            with degradation.track() as notes:
+                await asyncio.gather(retriever("bm42"), retriever("splade"))
+                return notes
+
+        assert sorted(asyncio.run(search())) == ["bm42 retrieval skipped", "splade retrieval skipped"]
+
+    def test_open_rerank_breaker_keeps_fused_order(self, monkeypatch):
+        """An open rerank breaker degrades the search instead of failing it."""
+        from src.core.circuit_breaker import CircuitOpenError
+        from src.services.search import degradation, reranker
+
+        async def rerank_results(query, documents):
+            raise CircuitOpenError("rerank", 12)
+
+        monkeypatch.setattr(reranker, "rerank_results", rerank_results)
+        monkeypatch.setattr(reranker.settings, "get", lambda key, default=None: default)
+        fused = [{"chunk_id": "a", "text": "x"}, {"chunk_id": "b", "text": "y"}]
+
+        async def search():
+            with degradation.track() as notes:
+                return await reranker.rerank_search_results("q", fused), notes
+
+        results, notes = asyncio.run(search())
+        assert [r["chunk_id"] for r in results] == ["a", "b"]
+        assert notes and notes[0].startswith("reranking skipped")
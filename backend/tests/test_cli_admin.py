@@ -0,0 +1,70 @@
+"""
+Tests for the ricesearch admin command group.
+"""
+import pytest
+import typer
+
+from src.cli.ricesearch import admin
+from src.cli.ricesearch.api_client import APIError
+
+
+class FakeClient:
+    def __init__(self, responses=None):
+        self.responses = responses or {}
+        self.calls = []
+
+    def request(self, method, path, **kwargs):
+        self.calls.append((method, path, kwargs))
+        response = self.responses.get((method, path), {})
+        if isinstance(response, Exception):
+            raise response
+        return response
+
+
+@pytest.fixture
+def client(monkeypatch):
+    client = FakeClient()
+    monkeypatch.setattr(admin, "get_api_client", lambda: client)
+    printed = []
+    monkeypatch.setattr(admin.console, "print", lambda *args, **kwargs: printed.append(" ".join(map(str, args))))
+    client.printed = printed
+    return client
+
+
+def test_settings_set_keeps_yaml_types(client):
+    client.responses[("PUT", "/api/v1/settings/search.hybrid.rrf_k")] = {"key": "search.hybrid.rrf_k", "value": 70}
+    admin.settings_set("search.hybrid.rrf_k", "70")
+    admin.settings_set("search.hybrid.rrf_k", "[a, b]")
+    assert [call[2]["json"] for call in client.calls] == [{"value": 70}, {"value": ["a", "b"]}]
+    assert admin._parse_value("true") is True
+    assert admin._parse_value("plain text") == "plain text"
+
+
+def test_toggle_gpu_flips_the_registry_value(client):
+    client.responses[("GET", f"{admin.ADMIN}/models/registry")] = {"models": [{"id": "splade", "gpu_enabled": True}]}
+    client.responses[("PUT", f"{admin.ADMIN}/models/splade")] = {"model": {"id": "splade", "gpu_enabled": False}}
+
+    admin.models_toggle_gpu("splade", enable=None)
+    assert client.calls[-1] == ("PUT", f"{admin.ADMIN}/models/splade", {"json": {"gpu_enabled": False}})
+
+    with pytest.raises(typer.Exit):
+        admin.models_toggle_gpu("missing", enable=None)
+
+
+def test_connection_and_job_commands_call_the_admin_api(client):
+    admin.connections_disable("conn-1")
+    admin.connections_enable("conn-1")
+    admin.jobs_cancel("task-1", terminate=True)
+    assert client.calls == [
+        ("PUT", f"{admin.ADMIN}/connections/conn-1/enabled", {"json": {"enabled": False}}),
+        ("PUT", f"{admin.ADMIN}/connections/conn-1/enabled", {"json": {"enabled": True}}),
+        ("POST", f"{admin.ADMIN}/jobs/task-1/cancel", {"params": {"terminate": True}}),
+    ]
+
+
+def test_api_errors_exit_with_the_detail(client):
+    client.responses[("GET", f"{admin.ADMIN}/jobs")] = APIError("403: Insufficient permissions. Required: admin")
+    with pytest.raises(typer.Exit) as exited:
+        admin.jobs_list()
+    assert exited.value.exit_code == 1
+    assert "Insufficient permissions" in client.printed[-1]
@@ -0,0 +1,190 @@
+"""
+Tests for CLI connection registration and per-connection activity.
+"""
+import json
+
+import pytest
+
+from src.services.admin.admin_store import AdminStore
+
+
+class FakeRedis:
+    def __init__(self):
+        self.values = {}
+
+    def get(self, key):
+        return self.values.get(key)
+
+    def set(self, key, value):
+        self.values[key] = value
+
+    def lpush(self, key, value):
+        pass
+
+    def ltrim(self, key, start, end):
+        pass
+
+
+def test_touch_connection_records_activity():
+    store = AdminStore()
+    store._redis = FakeRedis()
+    store._initialized = True
+    store.set_connection("conn-1", {"id": "conn-1", "last_seen": None, "stores": []})
+
+    assert store.touch_connection("conn-1", store="backend")
+    assert store.touch_connection("conn-1", store="backend", searches=1)
+    assert store.touch_connection("conn-1", searches=1)
+    connection = json.loads(store._redis.values[store.CONNECTIONS_KEY])["conn-1"]
+    assert connection["stores"] == ["backend"] and connection["searches"] == 2
+    assert connection["last_seen"]
+
+    assert not store.touch_connection("conn-missing")
+
+
+@pytest.fixture
+def cli(monkeypatch, tmp_path):
+    pytest.importorskip("httpx")
+    from src.cli.ricesearch import api_client
+    from src.cli.ricesearch.config import RicesearchConfig
+
+    monkeypatch.setattr(RicesearchConfig, "CONFIG_DIR", tmp_path)
+    monkeypatch.setattr(RicesearchConfig, "CONFIG_FILE", tmp_path / "config.yaml")
+    monkeypatch.delenv("RICESEARCH_PROFILE", raising=False)
+    config = RicesearchConfig()
+    monkeypatch.setattr(api_client, "get_config", lambda: config)
+    client = api_client.APIClient()
+    calls = []
+
+    def request(method, path, **kwargs):
+        calls.append((method, path, kwargs["json"]))
+        return {"connection": {"id": f"conn-{len(calls)}"}, "token": f"tok-{len(calls)}"}
+
+    monkeypatch.setattr(client, "request", request)
+    return client, config, calls
+
+
+def test_registers_once_and_persists(cli):
+    from src.cli.ricesearch.config import RicesearchConfig
+
+    client, config, calls = cli
+
+    assert client.connection() == {"id": "conn-1", "token": "tok-1"}
+    assert client.connection() == {"id": "conn-1", "token": "tok-1"}
+    assert len(calls) == 1
+    body = calls[0][2]
+    assert body["user_id"] == "admin-1" and body["hostname"] and body["os"] and body["arch"]
+    saved = RicesearchConfig()
+    assert saved.get("connection_id") == "conn-1" and saved.get("connection_token") == "tok-1"
+
+    # Deleted on the server: the next call registers again
+    client.forget_connection()
+    assert client.connection()["id"] == "conn-2"
+
+
+def test_registration_can_be_disabled_or_fail(cli, monkeypatch):
+    from src.cli.ricesearch.api_client import APIError
+
+    client, config, calls = cli
+    config.set("register_connection", False)
+    assert client.connection() is None and calls == []
+
+    config.set("register_connection", True)
+
+    def refuse(method, path, **kwargs):
+        calls.append(path)
+        raise APIError("404: Not Found")
+
+    monkeypatch.setattr(client, "request", refuse)
+    assert client.connection() is None
+    # Not retried for every file
+    assert client.connection() is None and len(calls) == 1
+
+
+class FakeSDK:
+    """Stands in for ``RiceSearchClient`` behind the CLI."""
+
+    uploads = []
+
+    def __init__(self, base_url, connection_id=None, connection_token=None, **kwargs):
+        self.connection_id = connection_id
+        self.kwargs = kwargs
+
+    def __enter__(self):
+        return self
+
+    def __exit__(self, *exc):
+        pass
+
+    def index_file(self, path, store, display_path=None):
+        from types import SimpleNamespace
+        from src.client import RiceSearchError
+
+        FakeSDK.uploads.append((self.connection_id, store, display_path, self.kwargs["max_retries"]))
+        if self.connection_id == "conn-1":
+            raise RiceSearchError("403: Unknown connection", 403, "Unknown connection")
+        return SimpleNamespace(raw={"status": "queued", "task_id": "t-1"})
+
+    def request(self, method, path, **kwargs):
+        from src.client import RiceSearchError
+
+        raise RiceSearchError("403: Insufficient permissions", 403, "Insufficient permissions")
+
+
+def test_uploads_go_through_the_sdk_and_re_register(cli, monkeypatch, tmp_path):
+    from src.cli.ricesearch import api_client
+
+    client, config, calls = cli
+    monkeypatch.setattr(api_client, "RiceSearchClient", FakeSDK)
+    FakeSDK.uploads = []
+    path = tmp_path / "a.py"
+    path.write_text("x = 1\n")
+
+    assert client.index_file(path, "backend") == {"status": "queued", "task_id": "t-1"}
+    # The deleted connection is forgotten and the upload resent as a new one
+    assert FakeSDK.uploads == [
+        ("conn-1", "backend", "a.py", api_client.MAX_BUSY_RETRIES),
+        ("conn-2", "backend", "a.py", api_client.MAX_BUSY_RETRIES),
+    ]
+    assert config.get("connection_id") == "conn-2"
+
+
+def test_request_errors_are_api_errors(cli, monkeypatch):
+    from src.cli.ricesearch import api_client
+
+    monkeypatch.setattr(api_client, "RiceSearchClient", FakeSDK)
+    with pytest.raises(api_client.APIError, match="Insufficient permissions") as error:
+        api_client.APIClient().request("GET", "/api/v1/admin/public/jobs")
+    assert error.value.status == 403
+
+
+def test_searches_count_only_for_a_proven_connection(monkeypatch):
+    import asyncio
+    from fastapi import Response
+    from src.api.v1.endpoints import search
+    from src.services.admin import admin_store
+
+    touched, searched = [], []
+
+    class FakeAdminStore:
+        def verify_connection_token(self, connection_id, token):
+            return token == "tok-1"
+
+        def touch_connection(self, connection_id, store=None, searches=0):
+            touched.append((connection_id, store, searches))
+
+    async def perform_search(**kwargs):
+        searched.append(kwargs["connection_id"])
+        return {"results": []}
+
+    monkeypatch.setattr(admin_store, "get_admin_store", lambda: FakeAdminStore())
+    monkeypatch.setattr(search, "_perform_search", perform_search)
+    request = search.SearchRequest(query="retry", store="backend")
+    user = {"sub": "alice"}
+
+    asyncio.run(search.search_post(request, Response(), user, "conn-1", "tok-1"))
+    # Someone else's connection ID, without its token
+    asyncio.run(search.search_post(request, Response(), user, "conn-1", None))
+    asyncio.run(search.search_post(request, Response(), user, "conn-1", "guess"))
+
+    assert touched == [("conn-1", "backend", 1)]
+    assert searched == ["conn-1", None, None]
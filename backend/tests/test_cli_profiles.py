@@ -0,0 +1,69 @@
+"""
+Tests for named server profiles in the ricesearch CLI config.
+"""
+import pytest
+import yaml
+
+from src.cli.ricesearch.config import ProfileError, RicesearchConfig
+
+
+@pytest.fixture
+def config_file(tmp_path, monkeypatch):
+    monkeypatch.setattr(RicesearchConfig, "CONFIG_DIR", tmp_path)
+    monkeypatch.setattr(RicesearchConfig, "CONFIG_FILE", tmp_path / "config.yaml")
+    monkeypatch.delenv("RICESEARCH_PROFILE", raising=False)
+    (tmp_path / "config.yaml").write_text(yaml.safe_dump({
+        "backend_url": "http://localhost:8000",
+        "org_id": "public",
+        "profiles": {
+            "team": {"backend_url": "https://rice.example.com", "org_id": "platform", "api_key": "rs_secret"},
+            "staging": {"backend_url": "https://staging.example.com", "verify_tls": False},
+        },
+    }))
+    return tmp_path / "config.yaml"
+
+
+def test_profile_overrides_defaults(config_file):
+    cfg = RicesearchConfig()
+    assert cfg.profile is None
+    assert cfg.backend_url == "http://localhost:8000" and cfg.api_key is None and cfg.tls_verify is True
+
+    cfg.select("team")
+    assert cfg.backend_url == "https://rice.example.com"
+    assert cfg.org_id == "platform" and cfg.api_key == "rs_secret"
+    assert cfg.show()["api_key"] == "rs_s..."
+
+    staging = RicesearchConfig(profile="staging")
+    # Unset keys fall back to the defaults
+    assert staging.org_id == "public" and staging.tls_verify is False
+
+    with pytest.raises(ProfileError):
+        cfg.select("prod")
+
+
+def test_use_and_env_select_profile(config_file, monkeypatch):
+    RicesearchConfig().use_profile("team")
+    assert yaml.safe_load(config_file.read_text())["active_profile"] == "team"
+    assert RicesearchConfig().backend_url == "https://rice.example.com"
+
+    monkeypatch.setenv("RICESEARCH_PROFILE", "staging")
+    assert RicesearchConfig().backend_url == "https://staging.example.com"
+
+
+def test_set_add_and_remove_profiles(config_file):
+    cfg = RicesearchConfig(profile="team")
+    cfg.set("org_id", "infra")
+    saved = yaml.safe_load(config_file.read_text())
+    assert saved["profiles"]["team"]["org_id"] == "infra" and saved["org_id"] == "public"
+
+    cfg.add_profile("local", {"backend_url": "http://localhost:9000", "ca_cert": "~/ca.pem", "api_key": None})
+    local = RicesearchConfig(profile="local")
+    assert local.backend_url == "http://localhost:9000"
+    assert local.tls_verify.endswith("ca.pem") and not local.tls_verify.startswith("~")
+    assert "api_key" not in local.profiles["local"]
+
+    cfg.use_profile("local")
+    cfg.remove_profile("local")
+    saved = yaml.safe_load(config_file.read_text())
+    assert "local" not in saved["profiles"] and "active_profile" not in saved
+    assert cfg.profile is None
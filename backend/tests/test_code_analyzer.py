@@ -0,0 +1,51 @@
+"""
+Unit tests for the code-aware sparse analyzer.
+"""
+import pytest
+
+
+def _analyzer(monkeypatch, **values):
+    from src.services.retrieval import analyzer
+
+    values = {"indexing.analyzer.enabled": True, **{f"indexing.analyzer.{k}": v for k, v in values.items()}}
+    monkeypatch.setattr(analyzer.settings, "get", lambda key, d=None: values.get(key, d))
+    return analyzer
+
+
+@pytest.mark.unit
+class TestCodeAnalyzer:
+    """Test identifier splitting, abbreviations and comment stripping."""
+
+    def test_disabled_returns_text_unchanged(self, monkeypatch):
+        analyzer = _analyzer(monkeypatch, enabled=False)
+
+        assert analyzer.analyze("getUserById(cfg)") == "getUserById(cfg)"
+
+    def test_splits_identifiers_and_expands_abbreviations(self, monkeypatch):
+        analyzer = _analyzer(monkeypatch)
+
+        assert analyzer.split_identifier("getHTTPResponse_code") == ["get", "http", "response", "code"]
+        assert analyzer.analyze("getUserById(cfg)") == "getUserById get user by id(cfg config)"
+        assert analyzer.analyze("max_conn_retries") == "max_conn_retries max conn retries connection"
+
+    def test_split_override_keeps_expansions(self, monkeypatch):
+        analyzer = _analyzer(monkeypatch)
+
+        assert analyzer.analyze("parseCtx", split_identifiers=False) == "parseCtx context"
+
+    def test_per_language_comment_stripping(self, monkeypatch):
+        analyzer = _analyzer(monkeypatch, languages={"python": {"strip_comments": True}}, expand_abbreviations=False)
+
+        code = 'x = "# kept"  # dropped\n'
+        assert analyzer.analyze(code, "python") == 'x = "# kept"  \n'
+        # Other languages and queries keep comments
+        assert "dropped" in analyzer.analyze(code, "go")
+        assert "dropped" in analyzer.analyze(code, "python", query=True)
+
+    def test_strip_c_style_comments(self, monkeypatch):
+        _analyzer(monkeypatch)
+        from src.services.retrieval.analyzer import strip_comments
+
+        code = 'url := "http://x" // tail\n/* block\ncomment */ y'
+        assert strip_comments(code, "go") == 'url := "http://x" \n y'
+        assert strip_comments(code, "unknown") == code
@@ -0,0 +1,67 @@
+"""
+Tests for per-store Qdrant collection tuning.
+"""
+from types import SimpleNamespace
+
+from src.services.ingestion import collection_config
+from src.services.ingestion.collection_config import create_collection, live_config, update_collection
+
+
+class FakeQdrant:
+    def __init__(self):
+        self.created = {}
+        self.updates = []
+        self.indexes = []
+
+    def create_collection(self, collection_name, vectors_config, sparse_vectors_config, **params):
+        self.created[collection_name] = params
+
+    def create_payload_index(self, collection_name, field_name, field_schema):
+        self.indexes.append(field_name)
+
+    def update_collection(self, collection_name, collection_params=None, hnsw_config=None):
+        self.updates.append((collection_name, collection_params, hnsw_config))
+
+    def get_collection(self, name):
+        params = SimpleNamespace(shard_number=2, replication_factor=1, on_disk_payload=True)
+        hnsw = SimpleNamespace(m=16, ef_construct=100)
+        return SimpleNamespace(config=SimpleNamespace(params=params, hnsw_config=hnsw))
+
+
+def test_create_with_store_config():
+    qdrant = FakeQdrant()
+    create_collection(qdrant, "rice_chunks_big", 768, {"shard_number": 4, "on_disk_payload": True, "hnsw_m": 32})
+
+    params = qdrant.created["rice_chunks_big"]
+    assert params["shard_number"] == 4 and params["on_disk_payload"] is True
+    assert params["hnsw_config"].m == 32
+    assert "replication_factor" not in params
+    assert "symbols" in qdrant.indexes
+
+
+def test_create_with_defaults_passes_nothing():
+    qdrant = FakeQdrant()
+    create_collection(qdrant, "rice_chunks_small", 768)
+    assert qdrant.created["rice_chunks_small"] == {}
+
+
+def test_update_skips_shard_number():
+    qdrant = FakeQdrant()
+    applied = update_collection(qdrant, "c", {"shard_number": 8, "replication_factor": 3, "hnsw_ef_construct": 200})
+
+    assert applied == ["replication_factor", "hnsw_ef_construct"]
+    name, params, hnsw = qdrant.updates[0]
+    assert params.replication_factor == 3
+    assert hnsw.ef_construct == 200
+
+    assert update_collection(qdrant, "c", {"shard_number": 8}) == []
+    assert len(qdrant.updates) == 1
+
+
+def test_live_config_and_dedicated_name(monkeypatch):
+    assert live_config(FakeQdrant(), "c") == {
+        "shard_number": 2, "replication_factor": 1, "on_disk_payload": True,
+        "hnsw_m": 16, "hnsw_ef_construct": 100,
+    }
+    monkeypatch.setattr(collection_config.settings, "COLLECTION_PREFIX", "rice_chunks")
+    assert collection_config.dedicated_collection("big") == "rice_chunks_big"
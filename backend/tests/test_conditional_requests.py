@@ -0,0 +1,62 @@
+"""
+Tests for ETag / If-None-Match handling on polled endpoints.
+"""
+from fastapi import Response
+
+from src.api.conditional import conditional, etag_matches, make_etag
+from src.services.admin.admin_store import AdminStore
+
+
+class BrokenRedis:
+    def hget(self, name, field):
+        raise ConnectionError("redis down")
+
+
+def test_make_etag():
+    etag = make_etag("stores", 3, "usage")
+    assert etag.startswith('W/"') and etag == make_etag("stores", 3, "usage")
+    assert etag != make_etag("stores", 4, "usage")
+    assert make_etag("files", None) is None
+
+
+def test_etag_matches():
+    etag = make_etag("stores", 1)
+    assert etag_matches(etag, etag)
+    assert etag_matches(etag[2:], etag)  # weak comparison ignores W/
+    assert etag_matches(f'W/"other", {etag}', etag)
+    assert etag_matches("*", etag)
+    assert not etag_matches(None, etag)
+    assert not etag_matches('W/"other"', etag)
+
+
+def test_conditional_tags_then_answers_304():
+    response = Response()
+    assert conditional(response, None, "stores", 1) is None
+    etag = response.headers["ETag"]
+    assert response.headers["Cache-Control"] == "no-cache"
+
+    not_modified = conditional(Response(), etag, "stores", 1)
+    assert not_modified.status_code == 304
+    assert not_modified.headers["ETag"] == etag
+
+    response = Response()
+    assert conditional(response, etag, "stores", 2) is None
+    assert response.headers["ETag"] != etag
+
+
+def test_unknown_version_sends_no_etag():
+    response = Response()
+    assert conditional(response, "*", "files", "public", None) is None
+    assert "ETag" not in response.headers
+
+
+def test_store_version_counter(fake_redis):
+    store = AdminStore()
+    store._redis = fake_redis
+    assert store.get_version("stores") == 0
+    store.record_store_search("public")
+    store.bump_version("stores")
+    assert store.get_version("stores") == 2
+
+    store._redis = BrokenRedis()
+    assert store.get_version("stores") is None
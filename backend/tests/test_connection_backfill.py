@@ -0,0 +1,83 @@
+"""
+Unit tests for rebuilding connection stats from index payloads.
+"""
+import pytest
+from types import SimpleNamespace
+from unittest.mock import MagicMock
+
+
+@pytest.mark.unit
+class TestAggregatePayloads:
+    """Test grouping chunk payloads by connection."""
+
+    def test_counts_unique_files_per_connection(self):
+        """Multiple chunks of one file count as a single indexed file."""
+        from src.services.admin.connection_backfill import aggregate_payloads
+
+        stats = aggregate_payloads([
+            {"connection_id": "conn-a", "org_id": "backend", "full_path": "src/main.py"},
+            {"connection_id": "conn-a", "org_id": "backend", "full_path": "src/main.py"},
+            {"connection_id": "conn-a", "org_id": "docs", "full_path": "README.md"},
+            {"connection_id": "conn-b", "org_id": "backend", "full_path": "src/main.py"},
+        ])
+
+        assert stats["conn-a"] == {"indexed_files": 2, "stores": ["backend", "docs"]}
+        assert stats["conn-b"] == {"indexed_files": 1, "stores": ["backend"]}
+
+    def test_skips_chunks_without_connection(self):
+        """Legacy chunks without connection_id are ignored."""
+        from src.services.admin.connection_backfill import aggregate_payloads
+
+        stats = aggregate_payloads([
+            {"org_id": "public", "full_path": "a.py"},
+            {"connection_id": None, "org_id": "public", "full_path": "b.py"},
+        ])
+
+        assert stats == {}
+
+
+@pytest.mark.unit
+class TestConnectionBackfill:
+    """Test applying recovered stats to connection records."""
+
+    def _backfill(self, payloads, connections):
+        from src.services.admin.connection_backfill import ConnectionBackfill
+
+        qdrant = MagicMock()
+        qdrant.get_collection.side_effect = Exception("not found")
+        qdrant.scroll.return_value = ([SimpleNamespace(payload=p) for p in payloads], None)
+
+        store = MagicMock()
+        store.get_connections.return_value = connections
+        return ConnectionBackfill(qdrant_client=qdrant, admin_store=store), store
+
+    def test_updates_known_and_recovers_unknown(self):
+        """Known connections are updated; unknown ids get placeholder records."""
+        backfill, store = self._backfill(
+            [
+                {"connection_id": "conn-a", "org_id": "backend", "full_path": "x.py"},
+                {"connection_id": "conn-gone", "org_id": "backend", "full_path": "y.py"},
+            ],
+            {"conn-a": {"id": "conn-a", "device_name": "laptop"}},
+        )
+
+        result = backfill.run()
+
+        assert result["updated"] == 1
+        assert result["recovered"] == 1
+        written = {c.args[0]: c.args[1] for c in store.set_connection.call_args_list}
+        assert written["conn-a"]["device_name"] == "laptop"
+        assert written["conn-a"]["indexed_files"] == 1
+        assert written["conn-gone"]["recovered"] is True
+
+    def test_dry_run_writes_nothing(self):
+        """Dry runs only report the aggregated stats."""
+        backfill, store = self._backfill(
+            [{"connection_id": "conn-a", "org_id": "backend", "full_path": "x.py"}],
+            {},
+        )
+
+        result = backfill.run(dry_run=True)
+
+        assert result["stats"]["conn-a"]["indexed_files"] == 1
+        store.set_connection.assert_not_called()
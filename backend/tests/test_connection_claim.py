@@ -0,0 +1,72 @@
+"""
+Tests for claiming a first token on connections registered before tokens.
+"""
+import asyncio
+
+import pytest
+from fastapi import HTTPException
+
+from src.api.v1.dependencies import authorize_connection
+
+
+class FakeAdminStore:
+    def __init__(self, connections):
+        self.connections = connections
+        self.audit = []
+
+    def get_connections(self):
+        return self.connections
+
+    def issue_connection_token(self, connection_id):
+        self.connections[connection_id]["token_hash"] = "hash-of-new-token"
+        return "new-token"
+
+    def verify_connection_token(self, connection_id, token):
+        return token == "new-token" and bool(self.connections[connection_id].get("token_hash"))
+
+    def raise_alert(self, *args):
+        pass
+
+    def log_audit(self, *args):
+        self.audit.append(args)
+
+
+@pytest.fixture
+def store(monkeypatch):
+    from src.api.v1.endpoints.admin import public
+    from src.services.admin import admin_store
+
+    store = FakeAdminStore({"conn-old": {"id": "conn-old", "user_id": "alice"}})
+    monkeypatch.setattr(admin_store, "get_admin_store", lambda: store)
+    monkeypatch.setattr(public, "get_admin_store", lambda: store)
+    return store
+
+
+def _claim(connection_id, user):
+    from src.api.v1.endpoints.admin import public
+    return asyncio.run(public.claim_connection_token(connection_id, user=user))
+
+
+def test_legacy_connection_is_refused_until_claimed(store):
+    alice = {"sub": "alice", "role": "member"}
+    with pytest.raises(HTTPException) as refused:
+        authorize_connection(alice, "conn-old", None)
+    assert refused.value.status_code == 401 and "/connections/conn-old/claim" in refused.value.detail
+
+    assert _claim("conn-old", alice) == {"connection_id": "conn-old", "token": "new-token"}
+    authorize_connection(alice, "conn-old", "new-token")
+    assert store.audit[0][0] == "connection_token_claimed"
+
+
+def test_only_the_owner_or_an_admin_claims_once(store):
+    with pytest.raises(HTTPException) as other:
+        _claim("conn-old", {"id": "mallory", "role": "member"})
+    assert other.value.status_code == 403
+
+    assert _claim("conn-old", {"id": "root", "role": "admin"})["token"] == "new-token"
+    with pytest.raises(HTTPException) as again:
+        _claim("conn-old", {"sub": "alice"})
+    assert again.value.status_code == 409
+    with pytest.raises(HTTPException) as missing:
+        _claim("conn-gone", {"id": "root", "role": "admin"})
+    assert missing.value.status_code == 404
@@ -0,0 +1,69 @@
+"""
+Tests for the disk content store and search-time hydration.
+"""
+from unittest.mock import patch
+
+from src.db import content_store
+from src.db.content_store import ContentStore, content_ref, hydrate, payload_text
+from src.services.search.chunks import preview_result
+
+TEXT = "def handler(event):\n    return process(event)\n"
+
+
+def _store(tmp_path):
+    store = ContentStore(root=str(tmp_path))
+    return store, patch.object(content_store, "_content_store", store)
+
+
+def test_put_is_content_addressed(tmp_path):
+    store, _ = _store(tmp_path)
+    ref = store.put(TEXT)
+    assert ref == content_ref(TEXT)
+    assert store.put(TEXT) == ref
+    assert store.get(ref) == TEXT
+    assert store.get(ref, 3) == "def"
+    assert len(list(tmp_path.rglob("*"))) == 2  # one shard directory, one blob
+
+
+def test_missing_or_invalid_refs(tmp_path):
+    store, _ = _store(tmp_path)
+    assert store.get(content_ref("never stored")) is None
+    assert store.get("../../etc/passwd") is None
+
+
+def test_hydrate_fills_referenced_results(tmp_path):
+    store, active = _store(tmp_path)
+    ref = store.put(TEXT)
+    results = [
+        {"chunk_id": "a", "text": "", "content_ref": ref},
+        {"chunk_id": "b", "text": "inline"},
+    ]
+    with active:
+        hydrated = hydrate(results)
+        assert payload_text({"content_ref": ref}) == TEXT
+    assert [r["text"] for r in hydrated] == [TEXT, "inline"]
+    # Inputs (e.g. cached results) are left as they were
+    assert results[0]["text"] == ""
+
+
+def test_preview_reads_prefix_only(tmp_path):
+    store, active = _store(tmp_path)
+    ref = store.put(TEXT)
+    with active:
+        preview = preview_result({"chunk_id": "a", "content_ref": ref, "content_length": len(TEXT)}, chars=11)
+    assert preview["preview"] == "def handler"
+    assert preview["content_length"] == len(TEXT)
+    assert "text" not in preview
+
+
+def test_referenced_text_feeds_derived_views(tmp_path):
+    from src.services.admin.store_stats import aggregate_payloads
+    from src.services.search.experiments import embedding_text
+    from src.services.search.suggestions import chunk_terms
+
+    store, active = _store(tmp_path)
+    payload = {"full_path": "/h.py", "filename": "h.py", "content_ref": store.put(TEXT)}
+    with active:
+        assert embedding_text(payload).endswith(TEXT)
+        assert aggregate_payloads([payload])["/h.py"]["bytes"] == len(TEXT)
+        assert "handler" in chunk_terms(payload)
@@ -0,0 +1,181 @@
+"""
+Tests for bus handler retries and the dead-letter queue.
+"""
+import itertools
+import json
+import queue
+import threading
+from types import SimpleNamespace
+
+import pytest
+
+from src.services.events import dead_letters
+from src.services.events.backends import BusBackend
+from src.services.events.bus import EventBus
+from src.services.events.dead_letters import (
+    KIND_INDEX_TASK, KIND_REQUEST, DeadLetterStore, RetriesExhausted, call_with_retry, retry_policy,
+)
+
+
+class RequestBackend(BusBackend):
+    """In-process request/reply transport."""
+
+    name = "memory"
+
+    def __init__(self):
+        self.requests = queue.Queue()
+        self.claimed = set()
+
+    def set_once(self, key, ttl_seconds):
+        if key in self.claimed:
+            return False
+        self.claimed.add(key)
+        return True
+
+    def request(self, topic, data, timeout):
+        reply = queue.Queue()
+        self.requests.put((data, reply))
+        return reply.get(timeout=timeout)
+
+    def serve(self, topic, handler, stop):
+        while not stop.is_set():
+            try:
+                data, reply = self.requests.get(timeout=0.05)
+            except queue.Empty:
+                continue
+            reply.put(handler(data))
+
+
+class FakeRedis:
+    def __init__(self):
+        self.hashes = {}
+        self.index = {}
+
+    def pipeline(self):
+        return self
+
+    def execute(self):
+        return [True, True]
+
+    def hset(self, key, field, value):
+        self.hashes[field] = value
+
+    def hget(self, key, field):
+        return self.hashes.get(field)
+
+    def hdel(self, key, *fields):
+        return sum(self.hashes.pop(f, None) is not None for f in fields)
+
+    def zadd(self, key, mapping):
+        self.index.update(mapping)
+
+    def zrem(self, key, *members):
+        for m in members:
+            self.index.pop(m, None)
+
+    def zcard(self, key):
+        return len(self.index)
+
+    def zrange(self, key, start, end):
+        return sorted(self.index, key=self.index.get)[start:end + 1]
+
+    def zrevrange(self, key, start, end):
+        ordered = sorted(self.index, key=self.index.get, reverse=True)
+        return ordered[start:] if end == -1 else ordered[start:end + 1]
+
+
+def _settings(monkeypatch, **overrides):
+    config = {"events.retry.backoff_seconds": 1, **overrides}
+    monkeypatch.setattr(dead_letters.settings, "get", lambda key, default=None: config.get(key, default))
+
+
+@pytest.fixture
+def store(monkeypatch):
+    monkeypatch.setattr("src.services.events.bus.emit", lambda topic, **payload: None)
+    clock = itertools.count(1000)
+    monkeypatch.setattr(dead_letters, "time", SimpleNamespace(time=lambda: next(clock)))
+    store = DeadLetterStore(redis_client=FakeRedis())
+    monkeypatch.setattr(dead_letters, "get_dead_letter_store", lambda: store)
+    return store
+
+
+def test_retry_policy_backs_off_exponentially_per_topic(monkeypatch):
+    _settings(monkeypatch, **{"events.retry.topics": [{"topic": "inference.embed", "max_attempts": 5}]})
+    assert retry_policy("inference.embed").max_attempts == 5
+    assert retry_policy("inference.rerank").max_attempts == 3
+    assert [retry_policy("x").delay(n) for n in (1, 2, 3, 5, 6)] == [1, 2, 4, 10, 10]
+
+    calls, slept = [], []
+
+    def flaky():
+        calls.append(1)
+        if len(calls) < 3:
+            raise ConnectionError("qdrant down")
+        return "ok"
+
+    assert call_with_retry("inference.rerank", flaky, sleep=slept.append) == "ok"
+    assert slept == [1, 2]
+
+    with pytest.raises(RetriesExhausted) as exhausted:
+        call_with_retry("inference.embed", lambda: 1 / 0, sleep=slept.append)
+    assert exhausted.value.attempts == 5
+
+
+def test_failed_requests_are_dead_lettered_and_requeued(monkeypatch, store):
+    _settings(monkeypatch, **{"events.retry.max_attempts": 2, "events.retry.backoff_seconds": 0})
+    healthy = threading.Event()
+
+    def embed(request):
+        if not healthy.is_set():
+            raise RuntimeError("model not loaded")
+        return {"embeddings": [[1.0]]}
+
+    bus = EventBus(backend=RequestBackend())
+    stop = threading.Event()
+    threading.Thread(target=bus.serve, args=("inference.embed", embed, stop), daemon=True).start()
+    monkeypatch.setattr("src.services.events.bus.get_event_bus", lambda: bus)
+    try:
+        with pytest.raises(RuntimeError, match="model not loaded"):
+            bus.request("inference.embed", {"texts": ["x"]}, timeout=2)
+        [entry] = store.list()["entries"]
+        assert entry["kind"] == KIND_REQUEST and entry["attempts"] == 2
+        assert entry["payload"] == {"texts": ["x"]}
+
+        # Still failing: the same entry is updated, not duplicated
+        assert store.requeue(entry["id"])["status"] == "failed"
+        assert [e["requeues"] for e in store.list()["entries"]] == [1]
+
+        healthy.set()
+        assert store.requeue(entry["id"]) == {"status": "requeued", "id": entry["id"]}
+        assert store.list() == {"entries": [], "total": 0}
+        assert store.requeue(entry["id"]) is None
+    finally:
+        stop.set()
+
+
+def test_a_redelivery_attempt_runs_once(monkeypatch, store):
+    calls = []
+    bus = EventBus(backend=RequestBackend())
+    stop = threading.Event()
+    threading.Thread(target=bus.serve, args=("inference.embed", calls.append, stop), daemon=True).start()
+    try:
+        bus.request("inference.embed", {"texts": ["x"], "_dlq_id": "abc:0"}, timeout=2)
+        with pytest.raises(RuntimeError, match="Duplicate redelivery"):
+            bus.request("inference.embed", {"texts": ["x"], "_dlq_id": "abc:0"}, timeout=2)
+        # The next attempt has its own id
+        bus.request("inference.embed", {"texts": ["x"], "_dlq_id": "abc:1"}, timeout=2)
+        assert calls == [{"texts": ["x"]}, {"texts": ["x"]}]
+    finally:
+        stop.set()
+
+
+def test_list_filters_topics_and_caps_entries(monkeypatch, store):
+    _settings(monkeypatch, **{"events.dlq.max_entries": 2})
+    store.add(KIND_INDEX_TASK, "index.file", {"original_path": "a.py"}, "Dense embedding failed", 1)
+    store.add(KIND_REQUEST, "inference.rerank", {}, "timeout", 3)
+    store.add(KIND_REQUEST, "inference.embed", {}, "timeout", 3)
+
+    listing = store.list(["inference.*"])
+    assert [e["topic"] for e in listing["entries"]] == ["inference.embed", "inference.rerank"]
+    assert listing["total"] == 2
+    assert all(json.loads(v)["topic"] != "index.file" for v in store.redis.hashes.values())
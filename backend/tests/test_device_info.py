@@ -0,0 +1,64 @@
+"""
+Unit tests for compute device telemetry and CPU fallback reasons.
+"""
+import pytest
+from types import SimpleNamespace
+
+from src.core import device
+
+
+def _fake_torch(available=False, cuda_version=None, count=0):
+    cuda = SimpleNamespace(
+        is_available=lambda: available,
+        device_count=lambda: count,
+        current_device=lambda: 0,
+        get_device_name=lambda idx: "NVIDIA L4",
+        memory_reserved=lambda: 512 * 1024 * 1024,
+        get_device_properties=lambda idx: SimpleNamespace(total_memory=24 * 1024 ** 3),
+    )
+    return SimpleNamespace(cuda=cuda, version=SimpleNamespace(cuda=cuda_version))
+
+
+@pytest.fixture(autouse=True)
+def _reset_fallbacks():
+    device._fallbacks.clear()
+    yield
+    device._fallbacks.clear()
+
+
+@pytest.mark.unit
+class TestDeviceInfo:
+    """Test device selection reporting."""
+
+    def test_reason_for_cpu_only_torch_build(self, monkeypatch):
+        monkeypatch.setattr(device, "torch", _fake_torch(cuda_version=None))
+        assert "without CUDA" in device.cuda_unavailable_reason()
+
+    def test_reason_for_missing_device(self, monkeypatch):
+        monkeypatch.setattr(device, "torch", _fake_torch(cuda_version="12.1", count=0))
+        assert "No CUDA device" in device.cuda_unavailable_reason()
+
+    def test_forced_gpu_fallback_is_reported(self, monkeypatch):
+        monkeypatch.setattr(device, "torch", _fake_torch(cuda_version="12.1"))
+        monkeypatch.setattr(device.settings, "FORCE_GPU", True, raising=False)
+        monkeypatch.setattr(device, "_component_devices", lambda: {"splade": "cpu"})
+
+        assert device.get_device() == "cpu"
+        info = device.get_device_info()
+        assert info["device"] == "cpu"
+        assert info["gpu_requested"] is True
+        assert "No CUDA device" in info["fallback_reason"]
+        assert [f["component"] for f in info["fallbacks"]] == ["default"]
+        assert info["vram"] is None
+
+    def test_gpu_memory_without_nvidia_smi(self, monkeypatch):
+        monkeypatch.setattr(device, "torch", _fake_torch(available=True, cuda_version="12.1", count=1))
+
+        def no_smi(*args, **kwargs):
+            raise FileNotFoundError("nvidia-smi")
+
+        monkeypatch.setattr(device.subprocess, "run", no_smi)
+        memory = device.get_gpu_memory()
+        assert memory["process_mb"] == 512
+        assert memory["total_mb"] == 24 * 1024
+        assert memory["used_mb"] is None
@@ -0,0 +1,105 @@
+"""Tests for docstring/comment vectors and their blending at search time."""
+
+from types import SimpleNamespace
+
+import pytest
+from qdrant_client.models import PointStruct
+
+from src.services.ingestion import doc_vectors
+from src.services.ingestion.doc_vectors import (
+    blend_weights,
+    doc_text,
+    doc_texts,
+    fit_points,
+    vector_weights,
+)
+from src.services.retrieval.fusion import rrf_fusion
+
+PYTHON = '''
+def retry_upload(path, attempts=3):
+    """Upload a file again after transient network failures.
+
+    Gives up after ``attempts`` tries.
+    """
+    # noqa: E501
+    url = "http://example.com/#anchor"  # exponential backoff between tries
+    return send(path, url)
+'''
+
+GO = '''
+// ParseConfig reads the YAML configuration file and fills in defaults
+// for every missing field.
+/* Deprecated: use LoadConfig. */
+func ParseConfig(path string) (*Config, error) {
+    s := "// not a comment"
+    return load(path)
+}
+'''
+
+
+def test_doc_text_collects_docstrings_and_comments():
+    text = doc_text(PYTHON, {"language": "python", "symbols": ["retry_upload"]})
+    assert text.splitlines() == [
+        "retry upload",
+        "Upload a file again after transient network failures.",
+        "Gives up after ``attempts`` tries.",
+        "exponential backoff between tries",
+    ]
+
+    text = doc_text(GO, {"language": "go", "symbols": ["Config.ParseConfig"]})
+    assert text.splitlines()[0] == "config parse config"
+    assert "ParseConfig reads the YAML configuration file and fills in defaults" in text
+    assert "Deprecated: use LoadConfig." in text
+    assert "not a comment" not in text
+
+
+def test_chunks_without_prose_get_no_doc_vector():
+    assert doc_text("def f(x):\n    return x + 1\n", {"language": "python"}) is None
+    assert doc_text("x = 1  # short\n", {"language": "python"}) is None
+    texts = doc_texts([
+        {"content": "# Install\n\nRun make.", "metadata": {"language": "markdown"}},
+        {"content": "def f(): pass", "metadata": {"language": "python"}},
+        {"content": PYTHON, "metadata": {"language": "python"}},
+    ])
+    # Docs reuse the dense vector ("")
+    assert texts[0] == "" and texts[1] is None and texts[2]
+
+
+def test_fit_points_drops_doc_vectors_for_older_collections(monkeypatch):
+    class FakeQdrant:
+        def __init__(self, vectors):
+            self.vectors = vectors
+
+        def get_collection(self, name):
+            return SimpleNamespace(config=SimpleNamespace(params=SimpleNamespace(vectors=self.vectors)))
+
+    monkeypatch.setattr(doc_vectors, "_vector_names", {})
+    point = PointStruct(id=1, vector={"dense": [0.1, 0.2], "doc": [0.3, 0.4]}, payload={"a": 1})
+    assert fit_points(FakeQdrant({"dense": None, "doc": None}), "new", [point]) == [point]
+    fitted = fit_points(FakeQdrant({"dense": None}), "old", [point])
+    assert fitted[0].vector == {"dense": [0.1, 0.2]}
+    assert fitted[0].payload == {"a": 1}
+
+
+def test_vector_weights_layer_request_over_store_config():
+    assert vector_weights() == {"code": 1.0, "doc": 1.0}
+    store = {"vector_weights": {"doc": 0.5}}
+    assert vector_weights(store) == {"code": 1.0, "doc": 0.5}
+    assert vector_weights(store, {"doc": 2, "code": 0.5}) == {"code": 0.5, "doc": 2.0}
+    with pytest.raises(ValueError, match="expected code or doc"):
+        vector_weights(override={"summary": 1.0})
+    with pytest.raises(ValueError, match="negative"):
+        vector_weights(override={"doc": -1})
+
+
+def test_blended_weights_decide_between_code_and_doc_matches():
+    result_sets = {
+        "bm42": [{"chunk_id": "code-match", "score": 1.0}, {"chunk_id": "doc-match", "score": 0.5}],
+        "doc": [{"chunk_id": "doc-match", "score": 0.9}],
+    }
+    weights = blend_weights({"bm25": 1.0, "bm42": 1.2}, {"code": 1.0, "doc": 1.0})
+    assert weights == {"bm25": 1.0, "bm42": 1.2, "doc": 1.2}
+    assert rrf_fusion(result_sets, weights=weights)[0].chunk_id == "doc-match"
+
+    code_only = blend_weights(None, {"code": 1.0, "doc": 0.0})
+    assert rrf_fusion(result_sets, weights=code_only)[0].chunk_id == "code-match"
@@ -0,0 +1,92 @@
+"""
+Tests for heading-based docs chunking and the heading boost.
+"""
+from src.services.ingestion.docs_chunker import chunk_document, split_section
+from src.services.retrieval.fusion import FusedResult, heading_boost
+
+README = """Intro paragraph before any heading.
+
+# Rice Search
+
+## Installation
+
+Install the package.
+
+### Docker
+
+```bash
+# not a heading
+docker compose up
+```
+
+Usage
+-----
+
+Run `ricesearch search`.
+"""
+
+
+def _sections(chunks):
+    return [(c["metadata"]["heading"], c["metadata"]["breadcrumb"], c["metadata"]["start_line"]) for c in chunks]
+
+
+def test_markdown_sections_carry_breadcrumbs():
+    chunks = chunk_document(README, {"language": "markdown"}, "docs/README.md", "markdown")
+    assert _sections(chunks) == [
+        (None, "README", 1),
+        ("Installation", "README > Rice Search > Installation", 5),
+        ("Docker", "README > Rice Search > Installation > Docker", 9),
+        ("Usage", "README > Rice Search > Usage", 16),
+    ]
+    docker = chunks[2]
+    assert "docker compose up" in docker["content"] and docker["metadata"]["heading_level"] == 3
+    assert docker["metadata"]["chunk_type"] == "section" and docker["metadata"]["language"] == "markdown"
+    assert [c["chunk_index"] for c in chunks] == [0, 1, 2, 3]
+
+
+def test_front_matter_and_files_without_headings():
+    text = "---\ntitle: Notes\n---\nJust text.\n"
+    assert chunk_document(text, {}, "notes.md", "markdown") == []
+    assert chunk_document("plain text", {}, "a.rst", "restructuredtext") == []
+
+
+def test_rst_levels_follow_adornment_order():
+    text = "=====\nGuide\n=====\n\nIntro.\n\nSetup\n-----\n\nSteps.\n\nLinux\n~~~~~\n\nApt.\n\nUsage\n-----\n\nRun.\n"
+    chunks = chunk_document(text, {}, "guide.rst", "restructuredtext")
+    assert [(c["metadata"]["heading"], c["metadata"]["heading_level"]) for c in chunks] == [
+        ("Guide", 1), ("Setup", 2), ("Linux", 3), ("Usage", 2),
+    ]
+    assert chunks[2]["metadata"]["breadcrumb"] == "guide > Guide > Setup > Linux"
+    assert chunks[0]["metadata"]["start_line"] == 1
+
+
+def test_yaml_top_level_keys():
+    text = "# service config\nserver:\n  port: 8000\nretries: 3\n"
+    chunks = chunk_document(text, {}, "deploy/app.yaml", "yaml")
+    assert [(c["metadata"]["heading"], c["content"]) for c in chunks] == [
+        (None, "# service config"),
+        ("server", "server:\n  port: 8000"),
+        ("retries", "retries: 3"),
+    ]
+
+
+def test_long_sections_split_on_paragraphs(monkeypatch):
+    lines = ["para one line"] * 3 + [""] + ["para two line"] * 3
+    assert split_section(lines, 10, 60) == [(10, 14), (14, 17)]
+
+    from src.services.ingestion import docs_chunker
+    monkeypatch.setattr(docs_chunker.settings, "get", lambda key, default=None: 60 if key.endswith("max_section_chars") else default)
+    chunks = chunk_document("# Big\n" + "\n".join(lines), {}, "big.md", "markdown")
+    assert len(chunks) == 2 and all(c["metadata"]["heading"] == "Big" for c in chunks)
+
+
+def test_heading_boost_reorders_matching_sections():
+    results = [
+        FusedResult("a", 0.030, payload={"heading": "Overview"}),
+        FusedResult("b", 0.029, payload={"heading": "Docker installation"}),
+        FusedResult("c", 0.028, payload={}),
+    ]
+    ranked = heading_boost(results, "docker install", 0.3)
+    assert [r.chunk_id for r in ranked] == ["b", "a", "c"]
+    assert ranked[0].fused_score == 0.029 * 1.15
+    assert heading_boost([FusedResult("x", 1.0, payload={"heading": "Docker"})], "docker", 0)[0].fused_score == 1.0
@@ -0,0 +1,86 @@
+"""
+Tests for graceful shutdown: readiness, in-flight tracking and the drain sequence.
+"""
+import asyncio
+import threading
+
+from src.core import draining
+from src.core.draining import DrainMiddleware, DrainState, drain_then_stop
+
+
+def _settings(monkeypatch, **overrides):
+    config = {
+        "server.shutdown.ready_grace_seconds": 0,
+        "server.shutdown.drain_seconds": 0.5,
+        "server.shutdown.hard_deadline_seconds": 0.1,
+        **overrides,
+    }
+    monkeypatch.setattr(draining.settings, "get", lambda key, default=None: config.get(key, default))
+
+
+def _call(app, path):
+    async def receive():
+        return {"type": "http.request", "body": b"", "more_body": False}
+
+    async def send(message):
+        pass
+
+    asyncio.run(app({"type": "http", "method": "POST", "path": path}, receive, send))
+
+
+def test_middleware_tracks_requests_but_not_probes(monkeypatch):
+    state = DrainState()
+    monkeypatch.setattr(draining, "get_drain_state", lambda: state)
+    seen = []
+
+    async def app(scope, receive, send):
+        seen.append(len(state.active()))
+
+    _call(DrainMiddleware(app), "/api/v1/ingest/file")
+    _call(DrainMiddleware(app), "/readyz")
+    assert seen == [1, 0]
+    assert state.active() == []
+
+
+def test_readiness_flips_once():
+    state = DrainState()
+    assert state.status()["status"] == "ready"
+    assert state.start_draining() is True
+    assert state.start_draining() is False
+    assert state.status()["status"] == "draining" and state.draining
+
+
+def test_drain_waits_for_in_flight_requests(monkeypatch):
+    _settings(monkeypatch)
+    state = DrainState()
+    monkeypatch.setattr(draining, "get_drain_state", lambda: state)
+    token = state.begin("POST", "/api/v1/ingest/file")
+    calls = []
+
+    def previous(signum, frame):
+        calls.append(len(state.active()))
+
+    threading.Timer(0.1, state.end, args=(token,)).start()
+    drain_then_stop(previous, 15)
+    # The server was only told to stop after the upload finished
+    assert calls == [0]
+
+
+def test_hard_deadline_forces_stop_and_logs_aborted(monkeypatch):
+    _settings(monkeypatch, **{"server.shutdown.drain_seconds": 0})
+    state = DrainState()
+    monkeypatch.setattr(draining, "get_drain_state", lambda: state)
+    state.begin("GET", "/api/v1/events/stream")
+    aborted = []
+    monkeypatch.setattr(draining, "log_aborted", lambda s, when: aborted.extend(r["path"] for r in s.active()))
+
+    class Server:
+        force_exit = False
+
+        def handle_exit(self, signum, frame):
+            pass
+
+    server = Server()
+    drain_then_stop(server.handle_exit, 15)
+    assert server.force_exit is True
+    assert aborted == ["/api/v1/events/stream"]
@@ -0,0 +1,92 @@
+"""
+Tests for the near-duplicate code report.
+"""
+import json
+from types import SimpleNamespace
+
+import pytest
+
+from src.services.admin.duplicates import DONE, DuplicateReports, cluster_pairs, file_pairs
+
+
+# Two copies of a retry helper plus a vendored one, a test that copies a
+# fixture within its own file, and a one-line import everyone shares
+CHUNKS = {
+    "a": ("api/retry.py", 10, 40, [1.0, 0.0, 0.0]),
+    "b": ("worker/retry.py", 8, 38, [0.99, 0.01, 0.0]),
+    "c": ("vendor/backoff.py", 1, 31, [0.97, 0.03, 0.0]),
+    "d": ("tests/test_retry.py", 1, 20, [0.0, 1.0, 0.0]),
+    "e": ("tests/test_retry.py", 30, 49, [0.0, 1.0, 0.0]),
+    "f": ("api/views.py", 1, 1, [0.0, 0.0, 1.0]),
+    "g": ("worker/jobs.py", 1, 1, [0.0, 0.0, 1.0]),
+}
+
+
+def _point(chunk_id, score=None):
+    path, start, end, vector = CHUNKS[chunk_id]
+    return SimpleNamespace(
+        id=chunk_id,
+        score=score,
+        vector={"dense": vector},
+        payload={"org_id": "backend", "full_path": path, "start_line": start, "end_line": end, "language": "python"},
+    )
+
+
+def _query_points(collection_name, query, using, query_filter, score_threshold, limit, with_payload):
+    scored = []
+    for chunk_id, (_, _, _, vector) in CHUNKS.items():
+        score = sum(x * y for x, y in zip(query, vector))
+        if score >= score_threshold:
+            scored.append(_point(chunk_id, score))
+    scored.sort(key=lambda p: -p.score)
+    return SimpleNamespace(points=scored[:limit])
+
+
+def _chunk(chunk_id):
+    path, start, end, _ = CHUNKS[chunk_id]
+    return {"chunk_id": chunk_id, "path": path, "start_line": start, "end_line": end, "language": "python"}
+
+
+def test_pairs_are_clustered_and_rolled_up_per_file():
+    pairs = {("a", "b"): 0.99, ("a", "c"): 0.96, ("x", "y"): 0.97}
+    chunks = {i: _chunk(i) for i in "abc"}
+    chunks["x"] = {**_chunk("a"), "chunk_id": "x", "start_line": 50, "end_line": 80}
+    chunks["y"] = {**_chunk("b"), "chunk_id": "y", "start_line": 60, "end_line": 90}
+
+    clusters = cluster_pairs(pairs, chunks)
+    assert [c["size"] for c in clusters] == [3, 2]
+    assert clusters[0]["id"] == 1
+    assert clusters[0]["files"] == 3
+    assert (clusters[0]["min_similarity"], clusters[0]["max_similarity"]) == (0.96, 0.99)
+    assert [c["path"] for c in clusters[0]["chunks"]] == ["api/retry.py", "vendor/backoff.py", "worker/retry.py"]
+
+    by_files = file_pairs(pairs, chunks)
+    assert by_files[0] == {
+        "file_a": "api/retry.py", "file_b": "worker/retry.py",
+        "chunks": 2, "max_similarity": 0.99, "mean_similarity": 0.98,
+    }
+    assert len(by_files) == 2
+
+
+def test_report_finds_copies_across_files_only(fake_redis, fake_qdrant):
+    fake_qdrant.collections["rice_chunks"] = [_point(i) for i in CHUNKS]
+    fake_qdrant.query_points = _query_points
+    reports = DuplicateReports(redis_client=fake_redis, qdrant_client=fake_qdrant)
+    assert reports.get("backend") == {"store": "backend", "state": None}
+
+    reports.start("backend", "task-1")
+    with pytest.raises(ValueError):
+        reports.start("backend", "task-2")
+
+    report = reports.run("backend")
+    assert report["state"] == DONE and report["task_id"] == "task-1"
+    # Same-file copies and one-line chunks are not reported
+    assert report["duplicate_chunks"] == 3
+    assert report["total_clusters"] == 1
+    assert {c["chunk_id"] for c in report["clusters"][0]["chunks"]} == {"a", "b", "c"}
+    assert report["total_file_pairs"] == 3
+    assert report["truncated"] is False
+
+    # Saved for GET, and a new scan can start once this one is done
+    assert json.loads(reports.redis.get("rice:duplicates:backend"))["state"] == DONE
+    assert reports.start("backend", "task-3")["clusters"] == report["clusters"]
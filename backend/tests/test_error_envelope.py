@@ -0,0 +1,79 @@
+"""
+Tests for the error envelope: codes, request IDs and middleware errors.
+"""
+import asyncio
+import json
+
+from src.core.errors import (
+    QUEUE_FULL,
+    AppError,
+    code_for_status,
+    current_request_id,
+    error_body,
+    new_request_id,
+)
+from src.core.request_middleware import RecoveryMiddleware, RequestIdMiddleware
+
+
+def _call(app, headers=None):
+    messages = []
+
+    async def receive():
+        return {"type": "http.request", "body": b"", "more_body": False}
+
+    async def send(message):
+        messages.append(message)
+
+    scope = {
+        "type": "http", "method": "GET", "path": "/api/v1/stores/backend", "client": ("10.0.0.1", 5000),
+        "headers": [(k.lower().encode(), v.encode()) for k, v in (headers or {}).items()],
+    }
+    asyncio.run(app(scope, receive, send))
+    return messages
+
+
+def test_codes_follow_status_unless_given():
+    assert code_for_status(404) == "not_found"
+    assert code_for_status(504) == "deadline_exceeded"
+    assert code_for_status(418) == "invalid_argument"
+    assert code_for_status(599) == "internal"
+    assert AppError(429, "Index queue is full", code=QUEUE_FULL).code == "queue_full"
+    assert AppError(403, "Not your file").code == "permission_denied"
+
+    body = error_body(400, "Invalid version 'x'")
+    assert body == {"detail": "Invalid version 'x'", "code": "invalid_argument", "request_id": None}
+
+
+def test_request_ids_are_accepted_only_when_plain():
+    assert new_request_id("client-trace.42") == "client-trace.42"
+    assert new_request_id("x" * 65) != "x" * 65
+    assert new_request_id("bad id\r\nSet-Cookie: a") != "bad id\r\nSet-Cookie: a"
+    assert len(new_request_id()) == 32
+
+
+def test_request_id_reaches_errors_and_response_headers():
+    seen = []
+
+    async def failing(scope, receive, send):
+        seen.append(current_request_id())
+        raise AppError(429, "Index queue is full", code=QUEUE_FULL)
+
+    messages = _call(RequestIdMiddleware(RecoveryMiddleware(failing)), headers={"X-Request-ID": "trace-1"})
+    assert messages[0]["status"] == 429
+    assert (b"x-request-id", b"trace-1") in messages[0]["headers"]
+    body = json.loads(messages[1]["body"])
+    assert body == {"detail": "Index queue is full", "code": "queue_full", "request_id": "trace-1"}
+    assert seen == ["trace-1"]
+    # Scoped to the request
+    assert current_request_id() is None
+
+
+def test_unhandled_errors_carry_code_and_request_id():
+    async def failing(scope, receive, send):
+        raise RuntimeError("boom")
+
+    messages = _call(RequestIdMiddleware(RecoveryMiddleware(failing)))
+    body = json.loads(messages[1]["body"])
+    request_id = dict(messages[0]["headers"])[b"x-request-id"].decode()
+    assert body["code"] == "internal" and body["request_id"] == request_id
+    assert body["detail"] == "Internal server error" and len(body["error_id"]) == 12
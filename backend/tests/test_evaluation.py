@@ -0,0 +1,74 @@
+"""
+Unit tests for the relevance evaluation harness.
+"""
+import asyncio
+import pytest
+
+
+@pytest.mark.unit
+class TestScoreQuery:
+    """Test per-query metrics."""
+
+    def test_perfect_ranking(self):
+        """Relevant file at rank 1 scores 1.0 everywhere."""
+        from src.services.search.evaluation import score_query
+
+        scores = score_query(["/repo/src/auth/jwt.py", "/repo/src/other.py"], ["src/auth/jwt.py"], k=10)
+
+        assert scores["ndcg"] == pytest.approx(1.0)
+        assert scores["mrr"] == 1.0
+        assert scores["recall"] == 1.0
+
+    def test_second_rank_and_partial_recall(self):
+        """MRR reflects the first hit; recall counts labels found within k."""
+        from src.services.search.evaluation import score_query
+
+        scores = score_query(["/repo/x.py", "/repo/a.py"], ["a.py", "b.py"], k=10)
+
+        assert scores["mrr"] == 0.5
+        assert scores["recall"] == 0.5
+        assert scores["missing"] == ["b.py"]
+
+    def test_graded_labels_and_cutoff(self):
+        """Hits beyond k are ignored; higher grades weigh more in NDCG."""
+        from src.services.search.evaluation import score_query
+
+        graded = {"best.py": 3, "ok.py": 1}
+        good = score_query(["/r/best.py", "/r/ok.py"], graded, k=2)
+        swapped = score_query(["/r/ok.py", "/r/best.py"], graded, k=2)
+        cut = score_query(["/r/x.py", "/r/best.py"], graded, k=1)
+
+        assert good["ndcg"] == pytest.approx(1.0)
+        assert swapped["ndcg"] < good["ndcg"]
+        assert cut["mrr"] == 0.0
+
+    def test_suffix_match_respects_segments(self):
+        """A label only matches whole path segments."""
+        from src.services.search.evaluation import match_label
+
+        assert match_label("/repo/src/jwt.py", {"jwt.py": 1}) == "jwt.py"
+        assert match_label("/repo/src/myjwt.py", {"jwt.py": 1}) is None
+
+
+@pytest.mark.unit
+def test_run_eval_aggregates_queries():
+    """Metrics are averaged across the query set."""
+    from src.services.search.evaluation import run_eval
+
+    index = {
+        "hit": [{"full_path": "/r/a.py"}],
+        "miss": [{"full_path": "/r/z.py"}],
+    }
+
+    async def search(query, limit, org_id):
+        return index[query]
+
+    report = asyncio.run(run_eval(
+        [{"query": "hit", "relevant": ["a.py"]}, {"query": "miss", "relevant": ["b.py"]}],
+        org_id="backend",
+        k=5,
+        search=search,
+    ))
+
+    assert report["metrics"] == {"ndcg@5": 0.5, "mrr": 0.5, "recall@5": 0.5}
+    assert report["queries"][1]["first_relevant_rank"] is None
@@ -0,0 +1,71 @@
+"""
+Unit tests for the event journal and replay idempotency.
+"""
+import pytest
+from datetime import datetime, timedelta
+from unittest.mock import MagicMock
+
+from src.services.events.journal import EventJournal
+
+
+def _journal(monkeypatch, tmp_path, **values):
+    from src.services.events import journal
+
+    monkeypatch.setattr(journal.settings, "get", lambda key, d=None: values.get(key, d))
+    return EventJournal(directory=str(tmp_path))
+
+
+def _event(topic, minutes_from_now, n=0):
+    at = datetime.now() + timedelta(minutes=minutes_from_now)
+    return {"event_id": f"{topic}-{n}", "topic": topic, "timestamp": at.isoformat(), "payload": {"n": n}}
+
+
+@pytest.mark.unit
+class TestEventJournal:
+    """Test journal rotation, range reads and consumer claims."""
+
+    def test_reads_time_range_in_order(self, monkeypatch, tmp_path):
+        journal = _journal(monkeypatch, tmp_path)
+        for n, minutes in enumerate([10, 20, 30]):
+            journal.append(_event("index.file.success", minutes, n))
+
+        since = (datetime.now() + timedelta(minutes=15)).timestamp()
+        until = (datetime.now() + timedelta(minutes=25)).timestamp()
+        assert [e["payload"]["n"] for e in journal.read()] == [0, 1, 2]
+        assert [e["payload"]["n"] for e in journal.read(since, until)] == [1]
+
+    def test_rotates_and_prunes_segments(self, monkeypatch, tmp_path):
+        # Tiny segments: every event starts a new one
+        journal = _journal(monkeypatch, tmp_path, **{
+            "events.journal.segment_mb": 0.00001,
+            "events.journal.max_segments": 3,
+        })
+        for n in range(5):
+            journal.append(_event("alert.warning", 0, n))
+
+        assert len(journal.segments()) == 3
+        # The oldest events went with their segments
+        assert [e["payload"]["n"] for e in journal.read()] == [2, 3, 4]
+        assert journal.get_stats()["segments"] == 3
+
+    def test_skips_torn_lines(self, monkeypatch, tmp_path):
+        journal = _journal(monkeypatch, tmp_path)
+        journal.append(_event("audit.login", 0))
+        with open(tmp_path / journal.segments()[-1], "a") as f:
+            f.write('{"event_id": "half')
+
+        assert len(list(journal.read())) == 1
+
+    def test_claim_is_idempotent_per_consumer(self, monkeypatch):
+        from src.services.events import bus
+
+        monkeypatch.setattr(bus.settings, "get", lambda key, d=None: d)
+        claimed = set()
+        redis_client = MagicMock()
+        redis_client.set.side_effect = lambda key, value, nx, ex: (key not in claimed, claimed.add(key))[0]
+        event_bus = bus.EventBus(redis_client=redis_client)
+
+        event = {"event_id": "abc", "topic": "index.file.success"}
+        assert event_bus.claim("metrics", event) is True
+        assert event_bus.claim("metrics", {**event, "replayed": True}) is False
+        assert event_bus.claim("reindexer", event) is True
@@ -0,0 +1,115 @@
+"""
+Tests for A/B model experiments (side-by-side search and overlap metrics).
+"""
+import asyncio
+from types import SimpleNamespace
+
+from src.services import inference
+from src.services.search import experiments, retriever
+from src.services.search.experiments import ExperimentRunner, experiment_collection, overlap_metrics
+
+EXPERIMENT = {"kind": "embedding", "model_a": "nomic-embed-text", "model_b": "mxbai-embed-large"}
+
+
+def _point(chunk_id, score=1.0, payload=None):
+    return SimpleNamespace(id=chunk_id, score=score, payload=payload or {})
+
+
+class FakeQdrant:
+    def __init__(self, hits, stored):
+        self.hits = hits
+        self.stored = stored
+        self.upserts = []
+
+    def query_points(self, collection_name, query, using, limit, query_filter=None, with_payload=False):
+        return SimpleNamespace(points=self.hits[using][:limit])
+
+    def retrieve(self, collection_name, ids, with_payload=True):
+        return [_point(i, payload=self.stored[i]) for i in ids if i in self.stored]
+
+    def upsert(self, collection_name, points):
+        self.upserts.append((collection_name, points))
+
+
+class FakeClient:
+    async def embed(self, texts, model=None):
+        return [[0.5, 0.5] for _ in texts]
+
+
+def _runner(monkeypatch, qdrant, experiment=EXPERIMENT):
+    monkeypatch.setattr(experiments, "get_store_experiment", lambda store_id: experiment)
+    monkeypatch.setattr(inference, "get_inference_client", lambda: FakeClient())
+
+    async def embed_a(texts):
+        return [[1.0, 0.0] for _ in texts]
+
+    monkeypatch.setattr(retriever, "embed_texts_async", embed_a)
+    return ExperimentRunner(qdrant_client=qdrant)
+
+
+def test_identical_rankings():
+    metrics = overlap_metrics(["a", "b", "c"], ["a", "b", "c"], 3)
+    assert metrics["overlap"] == 1.0
+    assert metrics["rbo"] == 1.0
+    assert metrics["top1_agree"] is True
+    assert metrics["mean_rank_shift"] == 0
+
+
+def test_partial_overlap():
+    metrics = overlap_metrics(["a", "b", "c", "d"], ["b", "a", "e", "f"], 4)
+    assert metrics["shared"] == 2
+    assert (metrics["only_a"], metrics["only_b"]) == (2, 2)
+    assert metrics["overlap"] == 0.5
+    assert metrics["jaccard"] == round(2 / 6, 4)
+    assert metrics["top1_agree"] is False
+    assert metrics["mean_rank_shift"] == 1.0
+    assert 0 < metrics["rbo"] < 1
+
+
+def test_disjoint_and_empty_rankings():
+    assert overlap_metrics(["a"], ["b"], 5)["rbo"] == 0.0
+    empty = overlap_metrics([], [], 5)
+    assert empty["overlap"] == 1.0 and empty["mean_rank_shift"] is None
+
+
+def test_embedding_compare_returns_both_variants(monkeypatch):
+    stored = {
+        "1": {"text": "def login()", "full_path": "auth.py"},
+        "2": {"text": "def logout()", "full_path": "auth.py"},
+        "3": {"text": "class Session", "full_path": "session.py"},
+    }
+    hits = {
+        "dense": [_point("1", 0.9, stored["1"]), _point("2", 0.8, stored["2"])],
+        # "9" was deleted from the main collection since it was embedded
+        "dense_b": [_point("3", 0.95), _point("9", 0.9), _point("1", 0.7)],
+    }
+    runner = _runner(monkeypatch, FakeQdrant(hits, stored))
+
+    comparison = asyncio.run(runner.compare("login", "docs", limit=3))
+
+    a, b = comparison["variants"]["a"], comparison["variants"]["b"]
+    assert a["model"] == "nomic-embed-text" and b["model"] == "mxbai-embed-large"
+    assert [r["chunk_id"] for r in a["results"]] == ["1", "2"]
+    assert [r["chunk_id"] for r in b["results"]] == ["3", "1"]
+    assert b["results"][0]["text"] == "class Session"
+    assert comparison["metrics"]["shared"] == 1
+
+
+def test_index_points_copies_payload_without_text(monkeypatch):
+    qdrant = FakeQdrant({}, {})
+    runner = _runner(monkeypatch, qdrant)
+
+    points = [_point("1", payload={"text": "secret", "org_id": "docs", "full_path": "a.py"})]
+    assert runner.index_points("docs", points, ["File: a.py\n\nsecret"]) == 1
+
+    collection, upserted = qdrant.upserts[0]
+    assert collection == experiment_collection("docs")
+    assert upserted[0].payload == {"org_id": "docs", "full_path": "a.py"}
+    assert upserted[0].vector == {"dense_b": [0.5, 0.5]}
+
+
+def test_rerank_experiments_index_nothing(monkeypatch):
+    qdrant = FakeQdrant({}, {})
+    runner = _runner(monkeypatch, qdrant, experiment={**EXPERIMENT, "kind": "rerank"})
+    assert runner.index_points("docs", [_point("1")], ["text"]) == 0
+    assert qdrant.upserts == []
@@ -0,0 +1,160 @@
+"""
+Unit tests for click-feedback fusion weight tuning.
+"""
+import pytest
+
+
+def _tuner(monkeypatch, **overrides):
+    import redis
+    from src.services.search import fusion_tuning
+
+    values = {"search.fusion_tuning.min_feedback": 2, "search.fusion_tuning.learning_rate": 1.0}
+    values.update(overrides)
+    monkeypatch.setattr(fusion_tuning.settings, "get", lambda key, default=None: values.get(key, default))
+    return fusion_tuning.FusionTuner(redis_client=redis.Redis())
+
+
+RESULTS = [
+    {"chunk_id": "a", "retriever_scores": {"bm25": 3.1, "splade": 0.8}},
+    {"chunk_id": "b", "retriever_scores": {"bm42": 0.6}},
+    {"chunk_id": "c", "retriever_scores": {"bm25": 1.0, "bm42": 0.4}},
+]
+
+
+@pytest.mark.unit
+class TestFeedback:
+    """Test attributing feedback to retriever groups."""
+
+    def test_click_credits_surfacing_groups(self, monkeypatch):
+        """A click credits only the groups that returned the result."""
+        tuner = _tuner(monkeypatch)
+        query_id = tuner.record_impression("backend", RESULTS)
+
+        applied = tuner.record_feedback(query_id, "b", "click")
+
+        assert applied == {"org_id": "backend", "rank": 1, "groups": ["dense"]}
+        stats = tuner.get_stats("backend")
+        assert stats["dense_clicks"] > 1.0  # deeper clicks weigh more
+        assert "sparse_clicks" not in stats
+
+    def test_unknown_result_is_rejected(self, monkeypatch):
+        """Feedback for results that were never returned is ignored."""
+        tuner = _tuner(monkeypatch)
+        query_id = tuner.record_impression("backend", RESULTS)
+
+        assert tuner.record_feedback(query_id, "zzz", "click") is None
+        assert tuner.record_feedback("missing", "a", "click") is None
+
+
+@pytest.mark.unit
+class TestTuning:
+    """Test weight updates from feedback stats."""
+
+    def test_target_share_follows_click_through(self):
+        """The group with the better click ratio gets the larger share."""
+        from src.services.search.fusion_tuning import target_sparse_share
+
+        share = target_sparse_share({
+            "sparse_clicks": 1, "sparse_ignores": 3,
+            "dense_clicks": 3, "dense_ignores": 1,
+        })
+        assert share == pytest.approx(0.25)
+        assert target_sparse_share({"sparse_clicks": 1}) is None
+
+    def test_tune_shifts_weights_toward_dense(self, monkeypatch):
+        """Dense-favoured feedback lowers the sparse weight within bounds."""
+        tuner = _tuner(monkeypatch)
+        tuner.reset("backend")
+        for _ in range(3):
+            query_id = tuner.record_impression("backend", RESULTS)
+            tuner.record_feedback(query_id, "a", "ignore")
+            tuner.record_feedback(query_id, "b", "click")
+
+        weights = tuner.tune("backend")
+
+        # Sparse was always ignored: share clamps to the lower bound
+        assert weights["sparse"] == pytest.approx(0.4)
+        assert weights["dense"] == pytest.approx(1.6)
+        applied = tuner.get_weights("backend")
+        assert applied["bm25"] == applied["splade"] == pytest.approx(0.4)
+        assert applied["bm42"] == pytest.approx(1.6)
+
+    def test_feedback_keeps_counting_after_tuning(self, monkeypatch):
+        """Halved (fractional) counters still accept new feedback."""
+        tuner = _tuner(monkeypatch)
+        tuner.reset("backend")
+        for _ in range(3):
+            query_id = tuner.record_impression("backend", RESULTS)
+            tuner.record_feedback(query_id, "c", "click")
+            tuner.record_feedback(query_id, "a", "ignore")
+        assert tuner.tune("backend") is not None
+
+        query_id = tuner.record_impression("backend", RESULTS)
+        tuner.record_feedback(query_id, "c", "click")
+
+        stats = tuner.get_stats("backend")
+        assert stats["events"] == pytest.approx(4.0)
+        assert stats["click_count"] == pytest.approx(2.5)
+        assert stats["click_rank_sum"] == pytest.approx(5.0)
+
+    def test_deep_clicks_lower_the_diversity_lambda(self, monkeypatch):
+        """Clicks far down the list move the lambda toward min_lambda."""
+        from src.services.search.fusion_tuning import target_diversity_lambda
+
+        assert target_diversity_lambda({"click_count": 4, "click_rank_sum": 0}, (0.5, 1.0), 10) == 1.0
+        assert target_diversity_lambda({"click_count": 2, "click_rank_sum": 10}, (0.5, 1.0), 10) == pytest.approx(0.75)
+        assert target_diversity_lambda({"click_count": 1, "click_rank_sum": 40}, (0.5, 1.0), 10) == 0.5
+        assert target_diversity_lambda({"events": 5}, (0.5, 1.0), 10) is None
+
+        tuner = _tuner(monkeypatch, **{"search.fusion_tuning.deep_click_rank": 2})
+        tuner.reset("backend")
+        assert tuner.get_diversity_lambda("backend") == 1.0
+        for _ in range(3):
+            query_id = tuner.record_impression("backend", RESULTS)
+            tuner.record_feedback(query_id, "c", "click")
+            tuner.record_feedback(query_id, "a", "ignore")
+
+        weights = tuner.tune("backend")
+
+        assert weights["diversity_lambda"] == pytest.approx(0.5)
+        assert tuner.get_diversity_lambda("backend") == pytest.approx(0.5)
+
+    def test_not_enough_feedback(self, monkeypatch):
+        """Stores below min_feedback keep their weights."""
+        tuner = _tuner(monkeypatch, **{"search.fusion_tuning.min_feedback": 100})
+        tuner.reset("docs")
+        query_id = tuner.record_impression("docs", RESULTS)
+        tuner.record_feedback(query_id, "a", "click")
+
+        assert tuner.tune("docs") is None
+        assert tuner.get_weights("docs")["bm25"] == 1.0
+
+
+@pytest.mark.unit
+def test_weighted_rrf_prefers_heavier_retriever():
+    """RRF weights change which retriever's top hit wins."""
+    from src.services.retrieval.fusion import rrf_fusion
+
+    result_sets = {
+        "bm25": [{"chunk_id": "lexical", "score": 1.0}],
+        "bm42": [{"chunk_id": "semantic", "score": 1.0}],
+    }
+
+    fused = rrf_fusion(result_sets, limit=2, weights={"bm25": 0.5, "bm42": 1.5})
+
+    assert [r.chunk_id for r in fused] == ["semantic", "lexical"]
+
+
+@pytest.mark.unit
+def test_diversify_spreads_results_over_files():
+    """A lower lambda moves a second file above a near-duplicate chunk."""
+    from src.services.retrieval.fusion import FusedResult, diversify
+
+    results = [
+        FusedResult("a1", 1.0, payload={"full_path": "a.py"}),
+        FusedResult("a2", 0.9, payload={"full_path": "a.py"}),
+        FusedResult("b1", 0.7, payload={"full_path": "b.py"}),
+    ]
+
+    assert [r.chunk_id for r in diversify(list(results), 1.0)] == ["a1", "a2", "b1"]
+    assert [r.chunk_id for r in diversify(list(results), 0.6)] == ["a1", "b1", "a2"]
@@ -0,0 +1,59 @@
+"""
+Unit tests for generated/minified file detection at index time.
+"""
+import pytest
+
+
+SETTINGS = {
+    "indexing.generated.lockfiles": ["package-lock.json", "Cargo.lock", "go.sum"],
+    "indexing.generated.path_patterns": ["*.min.js", "*.pb.go", "*_pb2.py"],
+    "indexing.generated.header_markers": ["code generated", "@generated"],
+}
+
+
+@pytest.fixture
+def detector(monkeypatch):
+    from src.services.ingestion import generated
+
+    monkeypatch.setattr(generated.settings, "get", lambda key, default=None: SETTINGS.get(key, default))
+    return generated.GeneratedFileDetector()
+
+
+@pytest.mark.unit
+class TestGeneratedFileDetector:
+    """Test each detection rule."""
+
+    def test_lockfiles(self, detector):
+        assert detector.detect("web/package-lock.json") == "lockfile"
+        assert detector.detect("go.sum") == "lockfile"
+        assert detector.detect("src/lock.go") is None
+
+    def test_path_patterns(self, detector):
+        assert detector.detect("static/vendor/jquery.min.js") == "generated_path"
+        assert detector.detect("api/user.pb.go") == "generated_path"
+        assert detector.detect("proto\\user_pb2.py") == "generated_path"
+
+    def test_generator_header(self, detector):
+        content = b"// Code generated by mockgen. DO NOT EDIT.\npackage mocks\n"
+
+        assert detector.detect("mocks/store.go", content) == "generated_header"
+
+    def test_header_marker_below_header_is_ignored(self, detector):
+        content = b"package main\n" * 20 + b"// code generated elsewhere\n"
+
+        assert detector.detect("main.go", content) is None
+
+    def test_minified(self, detector):
+        bundle = b"var a=" + b"function(){return 1},".join([b""] * 400) + b";\n"
+
+        assert detector.detect("dist/app.js", bundle) == "minified"
+
+    def test_regular_source_is_kept(self, detector):
+        source = b"def handler(event):\n    return process(event)\n" * 200
+
+        assert detector.detect("src/handler.py", source) is None
+
+    def test_binary_documents_skip_content_checks(self, detector):
+        pdf = b"%PDF-1.7 " + b"x" * 5000
+
+        assert detector.detect("docs/spec.pdf", pdf) is None
@@ -0,0 +1,198 @@
+"""
+Tests for clone-and-index git ingestion.
+"""
+import base64
+import os
+
+import pytest
+
+from src.services.ingestion import git_source
+from src.services.ingestion.git_source import (
+    COMPLETE, FULL, INCREMENTAL, GitIngestBusy, GitIngestError, GitIngestion,
+    git_env, list_files, parse_name_status, repository_name, validate_url,
+)
+
+
+class FakeRedis:
+    def __init__(self):
+        self.values = {}
+
+    def set(self, key, value, ex=None):
+        self.values[key] = value
+
+    def pipeline(self):
+        return FakePipeline(self)
+
+
+class FakePipeline:
+    def __init__(self, redis):
+        self.redis = redis
+        self.results = []
+
+    def get(self, key):
+        self.results.append(self.redis.values.get(key))
+
+    def delete(self, key):
+        self.results.append(int(self.redis.values.pop(key, None) is not None))
+
+    def execute(self):
+        return self.results
+
+
+class FakeAdminStore:
+    def __init__(self):
+        self.stores = {"billing": {"id": "billing", "name": "Billing"}}
+
+    def get_stores(self):
+        return {k: dict(v) for k, v in self.stores.items()}
+
+    def set_store(self, store_id, store):
+        self.stores[store_id] = store
+        return True
+
+
+class FakeIndexer:
+    calls = []
+
+    def __init__(self, qdrant_client=None):
+        pass
+
+    def ingest_file(self, file_path, display_path, repo_name, org_id):
+        FakeIndexer.calls.append(("ingest", display_path))
+        return {"status": "success"}
+
+    def delete_file(self, display_path, org_id):
+        FakeIndexer.calls.append(("delete", display_path))
+        return 1
+
+
+RESOLVE_HOST = git_source.resolve_host
+
+
+@pytest.fixture(autouse=True)
+def public_dns(monkeypatch):
+    # No network in tests: every name is a public host unless a test says otherwise
+    monkeypatch.setattr(git_source, "resolve_host", lambda host: ["140.82.112.3"])
+
+
+def test_urls_are_https_without_credentials():
+    assert validate_url(" https://github.com/acme/billing.git ") == "https://github.com/acme/billing.git"
+    for bad in ("http://github.com/acme/x", "file:///etc", "git@github.com:acme/x.git",
+                "https://user:pw@github.com/acme/x", "https://github.com/", "--upload-pack=x"):
+        with pytest.raises(GitIngestError):
+            validate_url(bad)
+    assert repository_name("https://gitlab.com/group/sub/project.git") == "group/sub/project"
+
+
+def test_allowed_hosts(monkeypatch):
+    monkeypatch.setattr(git_source.settings, "get", lambda key, default=None: (
+        ["github.com"] if key == "indexing.git.allowed_hosts" else default
+    ))
+    validate_url("https://GitHub.com/acme/x")
+    with pytest.raises(GitIngestError):
+        validate_url("https://evil.example/acme/x")
+
+
+def test_internal_hosts_need_an_allow_list(monkeypatch):
+    addresses = {
+        "intranet.corp": ["10.0.0.5"],
+        "metadata.example": ["169.254.169.254"],
+        "rebind.example": ["140.82.112.3", "127.0.0.1"],
+        "mapped.example": ["::ffff:192.168.1.1"],
+    }
+    monkeypatch.setattr(git_source, "resolve_host", lambda host: addresses.get(host) or RESOLVE_HOST(host))
+    for host in (*addresses, "127.0.0.1", "[::1]"):
+        with pytest.raises(GitIngestError, match="not a public address"):
+            validate_url(f"https://{host}/acme/x")
+
+    monkeypatch.setattr(git_source.settings, "get", lambda key, default=None: (
+        ["intranet.corp"] if key == "indexing.git.allowed_hosts" else default
+    ))
+    assert validate_url("https://intranet.corp/acme/x")
+
+
+def test_git_env_sandboxes_and_carries_the_token_out_of_argv(tmp_path):
+    env = git_env(str(tmp_path), token="s3cret")
+    config = {env[f"GIT_CONFIG_KEY_{i}"]: env[f"GIT_CONFIG_VALUE_{i}"] for i in range(int(env["GIT_CONFIG_COUNT"]))}
+    assert env["GIT_ALLOW_PROTOCOL"] == "https" and env["GIT_TERMINAL_PROMPT"] == "0"
+    assert config["core.hooksPath"] == "/dev/null" and config["core.symlinks"] == "false"
+    header = config["http.extraHeader"].split("Basic ", 1)[1]
+    assert base64.b64decode(header).decode() == "x-access-token:s3cret"
+    assert "http.extraHeader" not in git_env(str(tmp_path)).values()
+
+
+def test_diff_parsing_and_file_listing(tmp_path):
+    changed, removed = parse_name_status("M\tsrc/app.py\nA\tREADME.md\nD\told.py\nT\tlink.py\n\n")
+    assert (changed, removed) == (["README.md", "link.py", "src/app.py"], ["old.py"])
+
+    (tmp_path / "src").mkdir()
+    (tmp_path / "src" / "app.py").write_text("x = 1")
+    (tmp_path / "logo.png").write_bytes(b"\x89PNG")
+    (tmp_path / ".git").mkdir()
+    (tmp_path / ".git" / "config.py").write_text("")
+    os.symlink("/etc/hostname", tmp_path / "escape.txt")
+    assert list_files(str(tmp_path)) == ["src/app.py"]
+
+
+def _ingestion(monkeypatch, clone, changes=None):
+    from src.services.ingestion import indexer
+
+    FakeIndexer.calls = []
+    monkeypatch.setattr(indexer, "Indexer", FakeIndexer)
+    ingestion = GitIngestion(redis_client=FakeRedis(), admin_store=FakeAdminStore(), qdrant_client=object())
+    monkeypatch.setattr(ingestion, "_clone", clone)
+    monkeypatch.setattr(ingestion, "_changes", lambda checkout, since, env: changes)
+    return ingestion
+
+
+def test_first_run_indexes_everything_then_pulls_incrementally(monkeypatch):
+    envs = []
+
+    def clone(url, branch, checkout, env):
+        os.makedirs(checkout)
+        with open(os.path.join(checkout, "app.py"), "w") as f:
+            f.write("x = 1")
+        envs.append(env)
+        return "main", "a" * 40
+
+    ingestion = _ingestion(monkeypatch, clone)
+    plan = ingestion.start("billing", "https://github.com/acme/billing.git", "task-1", token="tok")
+    assert plan == {"repository": "acme/billing", "branch": None, "mode": FULL}
+    with pytest.raises(GitIngestBusy):
+        ingestion.start("billing", "https://github.com/acme/billing.git", "task-2")
+
+    result = ingestion.run("billing", "task-1")
+    assert (result["mode"], result["indexed"], result["sha"]) == (FULL, 1, "a" * 40)
+    assert "http.extraHeader" in envs[0].values()
+    assert FakeIndexer.calls == [("ingest", "acme/billing/app.py")]
+    state = ingestion.admin_store.stores["billing"]["git"]
+    assert (state["state"], state["branch"], state["sha"]) == (COMPLETE, "main", "a" * 40)
+    assert "token" not in str(state) and ingestion.redis.values == {}
+
+    # Same URL again: only the diff since the recorded commit
+    def clone_next(url, branch, checkout, env):
+        assert branch == "main"
+        clone(url, branch, checkout, env)
+        return "main", "b" * 40
+
+    ingestion = _ingestion(monkeypatch, clone_next, changes=(["app.py", "gone.py"], ["old.py"]))
+    ingestion._admin_store.stores["billing"]["git"] = state
+    assert ingestion.start("billing", "https://github.com/acme/billing.git", "task-3")["mode"] == INCREMENTAL
+    result = ingestion.run("billing", "task-3")
+    assert (result["mode"], result["indexed"], result["removed"]) == (INCREMENTAL, 1, 2)
+    assert FakeIndexer.calls == [
+        ("delete", "acme/billing/old.py"), ("ingest", "acme/billing/app.py"), ("delete", "acme/billing/gone.py")
+    ]
+    assert ingestion.admin_store.stores["billing"]["git"]["previous_sha"] == "a" * 40
+
+
+def test_failed_clone_marks_the_run_failed(monkeypatch):
+    def clone(url, branch, checkout, env):
+        raise GitIngestError("git clone failed: repository not found")
+
+    ingestion = _ingestion(monkeypatch, clone)
+    ingestion.start("billing", "https://github.com/acme/missing", "task-1")
+    with pytest.raises(GitIngestError):
+        ingestion.run("billing", "task-1")
+    state = ingestion.admin_store.stores["billing"]["git"]
+    assert state["state"] == "failed" and "not found" in state["error"]
@@ -0,0 +1,96 @@
+"""
+Unit tests for git push webhook handling.
+"""
+import hmac
+import hashlib
+import pytest
+
+
+@pytest.mark.unit
+class TestWebhookValidation:
+    """Test provider detection and signature validation."""
+
+    def test_detect_provider(self):
+        """Test provider is detected from event headers."""
+        from src.services.webhooks.git import detect_provider, WebhookError
+
+        assert detect_provider({"X-GitHub-Event": "push"}) == "github"
+        assert detect_provider({"x-gitlab-event": "Push Hook"}) == "gitlab"
+        with pytest.raises(WebhookError):
+            detect_provider({"content-type": "application/json"})
+
+    def test_github_signature(self):
+        """Test HMAC-SHA256 signature validation."""
+        from src.services.webhooks.git import verify_github_signature
+
+        body = b'{"ref": "refs/heads/main"}'
+        digest = hmac.new(b"s3cret", body, hashlib.sha256).hexdigest()
+
+        assert verify_github_signature("s3cret", body, f"sha256={digest}") is True
+        assert verify_github_signature("wrong", body, f"sha256={digest}") is False
+        assert verify_github_signature("s3cret", body, None) is False
+
+    def test_verify_request_requires_secret(self):
+        """Test unsigned requests are rejected when no secret is set."""
+        from src.services.webhooks.git import verify_request, WebhookError
+
+        with pytest.raises(WebhookError):
+            verify_request("gitlab", {"X-Gitlab-Token": "x"}, b"{}", "")
+
+    def test_gitlab_token(self):
+        """Test GitLab shared-token validation."""
+        from src.services.webhooks.git import verify_request, WebhookError
+
+        verify_request("gitlab", {"X-Gitlab-Token": "tok"}, b"{}", "tok")
+        with pytest.raises(WebhookError):
+            verify_request("gitlab", {"X-Gitlab-Token": "nope"}, b"{}", "tok")
+
+
+@pytest.mark.unit
+class TestPushParsing:
+    """Test push payload normalization."""
+
+    def test_collect_changes_applies_commits_in_order(self):
+        """Test later commits win over earlier ones."""
+        from src.services.webhooks.git import collect_changes
+
+        commits = [
+            {"added": ["a.py", "b.py"], "modified": [], "removed": ["c.py"]},
+            {"added": ["c.py"], "modified": ["a.py"], "removed": ["b.py"]},
+        ]
+        changed, removed = collect_changes(commits)
+
+        assert changed == ["a.py", "c.py"]
+        assert removed == ["b.py"]
+
+    def test_parse_github_push(self):
+        """Test GitHub payload fields are mapped."""
+        from src.services.webhooks.git import parse_push
+
+        event = parse_push("github", {
+            "ref": "refs/heads/main",
+            "after": "abc123",
+            "repository": {"full_name": "acme/api", "default_branch": "main"},
+            "commits": [{"added": [], "modified": ["src/app.py"], "removed": []}],
+        })
+
+        assert event.repository == "acme/api"
+        assert event.sha == "abc123"
+        assert event.branch == "main"
+        assert event.changed == ["src/app.py"]
+
+    def test_parse_gitlab_push(self):
+        """Test GitLab payload fields are mapped."""
+        from src.services.webhooks.git import parse_push
+
+        event = parse_push("gitlab", {
+            "ref": "refs/heads/develop",
+            "checkout_sha": "def456",
+            "project": {"id": 42, "path_with_namespace": "group/svc", "default_branch": "main"},
+            "commits": [{"added": ["x.go"], "modified": [], "removed": ["y.go"]}],
+        })
+
+        assert event.project_id == "42"
+        assert event.repository == "group/svc"
+        assert event.branch == "develop"
+        assert event.removed == ["y.go"]
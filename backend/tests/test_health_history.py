@@ -0,0 +1,92 @@
+"""
+Unit tests for component health history.
+"""
+import pytest
+
+HOUR = 3600
+
+
+@pytest.mark.unit
+class TestHealthSummary:
+    """Test uptime and incident computation."""
+
+    def test_uptime_with_single_incident(self):
+        """Test one hour down in a four hour window."""
+        from src.services.admin.health_history import summarize
+
+        transitions = [
+            {"component": "qdrant", "from": None, "to": "healthy", "timestamp": 0},
+            {"component": "qdrant", "from": "healthy", "to": "down", "timestamp": 1 * HOUR},
+            {"component": "qdrant", "from": "down", "to": "healthy", "timestamp": 2 * HOUR},
+        ]
+        result = summarize(transitions, start=0, end=4 * HOUR, buckets=4)["qdrant"]
+
+        assert result["uptime_percent"] == 75.0
+        assert result["status"] == "healthy"
+        assert len(result["incidents"]) == 1
+        assert result["incidents"][0]["duration_seconds"] == HOUR
+        assert result["timeline"] == ["up", "down", "up", "up"]
+
+    def test_state_before_window_carries_in(self):
+        """Test the window starts in the last known state."""
+        from src.services.admin.health_history import summarize
+
+        transitions = [
+            {"component": "redis", "from": None, "to": "down", "timestamp": -5 * HOUR},
+        ]
+        result = summarize(transitions, start=0, end=2 * HOUR, buckets=2)["redis"]
+
+        assert result["uptime_percent"] == 0.0
+        assert result["incidents"][0]["end"] is None  # ongoing
+        assert result["timeline"] == ["down", "down"]
+
+    def test_unknown_is_excluded_from_uptime(self):
+        """Test periods without data do not count against uptime."""
+        from src.services.admin.health_history import summarize
+
+        transitions = [
+            {"component": "minio", "from": None, "to": "healthy", "timestamp": 1 * HOUR},
+        ]
+        result = summarize(transitions, start=0, end=2 * HOUR, buckets=2)["minio"]
+
+        assert result["uptime_percent"] == 100.0
+        assert result["timeline"] == ["unknown", "up"]
+
+
+@pytest.mark.unit
+class TestHealthRecording:
+    """Test only transitions are persisted."""
+
+    def test_record_writes_changes_only(self):
+        """Test repeated identical samples are not stored."""
+        import redis
+        from src.services.admin.health_history import HealthHistory
+
+        history = HealthHistory(redis_client=redis.Redis())
+
+        assert len(history.record({"redis": "healthy", "qdrant": "healthy"}, now=100)) == 2
+        assert history.record({"redis": "healthy", "qdrant": "healthy"}, now=160) == []
+
+        written = history.record({"redis": "healthy", "qdrant": "down"}, now=220)
+        assert written == [{"component": "qdrant", "from": "healthy", "to": "down", "timestamp": 220}]
+        assert len(history.get_transitions()) == 3
+
+    def test_retention_keeps_each_components_starting_state(self, monkeypatch):
+        """Test a flapping component can't push out another's last transition."""
+        import redis
+        from src.services.admin import health_history
+        from src.services.admin.health_history import HealthHistory
+
+        config = {"health.history.retention_days": 1}
+        monkeypatch.setattr(health_history.settings, "get", lambda key, default=None: config.get(key, default))
+        history = HealthHistory(redis_client=redis.Redis())
+        day = 86400
+
+        history.record({"minio": "down"}, now=0)
+        for n in range(1, 6):
+            history.record({"qdrant": "down" if n % 2 else "healthy"}, now=n)
+        history.record({"qdrant": "healthy"}, now=2 * day)
+
+        kept = [(t["component"], t["timestamp"]) for t in history.get_transitions()]
+        assert kept == [("qdrant", 2 * day), ("qdrant", 5), ("minio", 0)]
+        assert history.get_history(hours=1, now=2 * day)["components"]["minio"]["status"] == "down"
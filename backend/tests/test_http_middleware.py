@@ -0,0 +1,159 @@
+"""
+Tests for response compression and request body limits.
+"""
+import asyncio
+import gzip
+import json
+import zlib
+
+from src.core import http_middleware
+from src.core.http_middleware import BodyLimitMiddleware, CompressionMiddleware, choose_encoding
+
+PAYLOAD = json.dumps({"results": [{"text": "def handler(event): pass"}] * 200}).encode()
+
+
+def _settings(monkeypatch, **overrides):
+    config = {"server.compression.minimum_size": 1024, **overrides}
+    monkeypatch.setattr(http_middleware.settings, "get", lambda key, default=None: config.get(key, default))
+
+
+def _json_app(body: bytes, content_type: bytes = b"application/json", chunks: int = 1):
+    async def app(scope, receive, send):
+        await send({
+            "type": "http.response.start",
+            "status": 200,
+            "headers": [(b"content-type", content_type), (b"content-length", str(len(body)).encode())],
+        })
+        size = -(-len(body) // chunks)
+        for i in range(chunks):
+            part = body[i * size:(i + 1) * size]
+            await send({"type": "http.response.body", "body": part, "more_body": i < chunks - 1})
+    return app
+
+
+def _call(app, headers=None, method="GET", path="/api/v1/search/query", body_parts=(b"",)):
+    messages = []
+    parts = list(body_parts)
+
+    async def receive():
+        part = parts.pop(0) if parts else b""
+        return {"type": "http.request", "body": part, "more_body": bool(parts)}
+
+    async def send(message):
+        messages.append(message)
+
+    scope = {
+        "type": "http",
+        "method": method,
+        "path": path,
+        "headers": [(k.lower().encode(), v.encode()) for k, v in (headers or {}).items()],
+    }
+    asyncio.run(app(scope, receive, send))
+    start = messages[0]
+    body = b"".join(m.get("body", b"") for m in messages[1:])
+    return start["status"], {k.decode(): v.decode() for k, v in start["headers"]}, body
+
+
+def test_choose_encoding():
+    assert choose_encoding("gzip, deflate, br") == "gzip"
+    assert choose_encoding("deflate") == "deflate"
+    assert choose_encoding("gzip;q=0, deflate;q=0.5") == "deflate"
+    assert choose_encoding("br") is None
+    assert choose_encoding("") is None
+
+
+def test_gzip_and_deflate(monkeypatch):
+    _settings(monkeypatch)
+    app = CompressionMiddleware(_json_app(PAYLOAD))
+
+    status, headers, body = _call(app, {"Accept-Encoding": "gzip"})
+    assert headers["content-encoding"] == "gzip"
+    assert "Accept-Encoding" in headers["vary"]
+    assert int(headers["content-length"]) == len(body) < len(PAYLOAD)
+    assert gzip.decompress(body) == PAYLOAD
+
+    status, headers, body = _call(app, {"Accept-Encoding": "deflate"})
+    assert headers["content-encoding"] == "deflate"
+    assert zlib.decompress(body) == PAYLOAD
+
+
+def test_streamed_body_is_compressed_per_chunk(monkeypatch):
+    _settings(monkeypatch)
+    app = CompressionMiddleware(_json_app(PAYLOAD, b"application/x-ndjson", chunks=4))
+    status, headers, body = _call(app, {"Accept-Encoding": "gzip"})
+    assert "content-length" not in headers
+    assert gzip.decompress(body) == PAYLOAD
+
+
+def test_small_and_streaming_responses_pass_through(monkeypatch):
+    _settings(monkeypatch)
+    status, headers, body = _call(CompressionMiddleware(_json_app(b'{"ok": true}')), {"Accept-Encoding": "gzip"})
+    assert "content-encoding" not in headers and body == b'{"ok": true}'
+
+    events = CompressionMiddleware(_json_app(PAYLOAD, b"text/event-stream", chunks=2))
+    status, headers, body = _call(events, {"Accept-Encoding": "gzip"})
+    assert "content-encoding" not in headers and body == PAYLOAD
+
+
+def _echo_body_app():
+    async def app(scope, receive, send):
+        body = b""
+        while True:
+            message = await receive()
+            body += message.get("body", b"")
+            if not message.get("more_body"):
+                break
+        await send({"type": "http.response.start", "status": 200, "headers": []})
+        await send({"type": "http.response.body", "body": body})
+    return app
+
+
+def test_declared_length_over_limit_is_refused(monkeypatch):
+    _settings(monkeypatch, **{"indexing.file.max_size_mb": 1})
+    app = BodyLimitMiddleware(_echo_body_app())
+    status, headers, body = _call(
+        app, {"Content-Length": str(2 * 1024 * 1024)}, method="POST", path="/api/v1/ingest/file"
+    )
+    assert status == 413
+    assert "limit 1 MB" in json.loads(body)["detail"]
+
+
+def test_streamed_body_is_cut_off(monkeypatch):
+    _settings(monkeypatch, **{"server.limits.body_mb": 0.001})  # ~1KB
+    app = BodyLimitMiddleware(_echo_body_app())
+    status, _, _ = _call(app, method="POST", body_parts=[b"x" * 600, b"x" * 600])
+    assert status == 413
+
+    status, _, body = _call(app, method="POST", body_parts=[b"x" * 600])
+    assert status == 200 and body == b"x" * 600
+
+
+def test_archive_uploads_get_their_own_limit(monkeypatch):
+    _settings(monkeypatch, **{"indexing.archive.max_upload_mb": 512})
+    app = BodyLimitMiddleware(_echo_body_app())
+    archive = b"x" * (20 * 1024 * 1024)
+    status, _, body = _call(
+        app, {"Content-Length": str(len(archive))}, method="POST",
+        path="/api/v1/stores/docs/ingest/archive", body_parts=[archive],
+    )
+    assert status == 200 and len(body) == len(archive)
+
+    # Other store routes keep the default JSON limit
+    status, _, _ = _call(
+        app, {"Content-Length": str(len(archive))}, method="POST", path="/api/v1/stores/docs/ingest/git"
+    )
+    assert status == 413
+
+
+def test_upload_batches_are_limited_by_batch_size(monkeypatch):
+    _settings(monkeypatch, **{"indexing.upload.max_batch_mb": 64})
+    app = BodyLimitMiddleware(_echo_body_app())
+    batch = 40 * 1024 * 1024
+    status, _, _ = _call(app, {"Content-Length": str(batch)}, method="POST", path="/api/v1/stores/docs/upload")
+    assert status == 200
+
+    status, _, body = _call(
+        app, {"Content-Length": str(2 * batch)}, method="POST", path="/api/v1/stores/docs/upload"
+    )
+    assert status == 413
+    assert "limit 64 MB" in json.loads(body)["detail"]
@@ -0,0 +1,129 @@
+"""
+Tests for Idempotency-Key handling on index and delete endpoints.
+"""
+import asyncio
+import io
+
+import pytest
+
+from src.api import idempotency
+from src.api.idempotency import IdempotencyStore, idempotent
+from src.core.errors import AppError
+
+
+class FakeRedis:
+    def __init__(self):
+        self.values = {}
+
+    def set(self, key, value, nx=False, ex=None):
+        if nx and key in self.values:
+            return None
+        self.values[key] = value
+        return True
+
+    def get(self, key):
+        return self.values.get(key)
+
+    def delete(self, key):
+        self.values.pop(key, None)
+
+
+class FakeResponse:
+    def __init__(self):
+        self.headers = {}
+
+
+@pytest.fixture
+def store(monkeypatch):
+    store = IdempotencyStore(FakeRedis())
+    monkeypatch.setattr(idempotency, "get_idempotency_store", lambda: store)
+    return store
+
+
+def _handler(calls, fail=None):
+    @idempotent("ingest.delete")
+    async def delete_file(response, path, idempotency_key=None, admin=None):
+        calls.append(path)
+        if fail:
+            raise fail
+        return {"status": "deleted", "file": path, "chunks_removed": 3}
+    return delete_file
+
+
+def _call(handler, key="key-1", path="a.py", user="alice", response=None):
+    return asyncio.run(handler(
+        response=response or FakeResponse(), path=path, idempotency_key=key, admin={"id": user},
+    ))
+
+
+def test_retry_replays_first_response(store):
+    calls = []
+    handler = _handler(calls)
+    first = _call(handler)
+    response = FakeResponse()
+    again = _call(handler, response=response)
+
+    assert calls == ["a.py"]
+    assert again == first
+    assert response.headers[idempotency.REPLAYED_HEADER] == "true"
+
+    # Without a key, or for another caller, requests run
+    _call(handler, key=None)
+    _call(handler, user="bob")
+    assert calls == ["a.py", "a.py", "a.py"]
+
+
+def test_key_reused_for_other_request_or_in_flight(store):
+    handler = _handler([])
+    _call(handler)
+    with pytest.raises(AppError) as reused:
+        _call(handler, path="b.py")
+    assert reused.value.status_code == 422 and reused.value.code == idempotency.KEY_REUSED
+
+    claimed, _ = store.claim("alice:ingest.delete", "key-2", idempotency.request_fingerprint("ingest.delete", {"path": "a.py"}))
+    assert claimed
+    with pytest.raises(AppError) as running:
+        _call(handler, key="key-2")
+    assert running.value.status_code == 409 and running.value.code == idempotency.IN_PROGRESS
+
+
+def test_failed_requests_release_the_key(store):
+    calls = []
+    failing = _handler(calls, fail=AppError(404, "File not indexed: a.py"))
+    with pytest.raises(AppError):
+        _call(failing)
+    assert store._redis.values == {}
+
+    # The retry runs and its success is kept
+    _call(_handler(calls))
+    assert calls == ["a.py", "a.py"]
+    assert len(store._redis.values) == 1
+
+
+def test_redis_failure_runs_without_idempotency(monkeypatch):
+    class DownRedis(FakeRedis):
+        def set(self, *args, **kwargs):
+            raise ConnectionError("redis down")
+
+    monkeypatch.setattr(idempotency, "get_idempotency_store", lambda: IdempotencyStore(DownRedis()))
+    calls = []
+    handler = _handler(calls)
+    _call(handler)
+    _call(handler)
+    assert calls == ["a.py", "a.py"]
+
+
+def test_uploads_are_fingerprinted_by_content():
+    from fastapi import UploadFile
+
+    def upload(content):
+        return UploadFile(file=io.BytesIO(content), filename="src/a.py")
+
+    same = [idempotency.request_fingerprint("ingest.file", {"file": upload(b"print(1)")}) for _ in range(2)]
+    other = idempotency.request_fingerprint("ingest.file", {"file": upload(b"print(2)")})
+    assert same[0] == same[1] != other
+
+    # The upload is rewound for the handler
+    file = upload(b"print(1)")
+    idempotency.request_fingerprint("ingest.file", {"file": file})
+    assert file.file.read() == b"print(1)"
@@ -0,0 +1,100 @@
+"""
+Tests for index pipeline stage limits, upload admission and depth gauges.
+"""
+import threading
+
+import pytest
+
+from src.services.ingestion import pipeline
+from src.services.ingestion.pipeline import PipelineBusy, StageLimiter
+
+
+class FakeRedis:
+    def __init__(self, queued=0):
+        self.hashes = {}
+        self.queued = queued
+
+    def pipeline(self):
+        return self
+
+    def hset(self, key, mapping):
+        self.hashes.setdefault(key, {}).update({k: str(v) for k, v in mapping.items()})
+
+    def expire(self, key, seconds):
+        pass
+
+    def execute(self):
+        pass
+
+    def llen(self, key):
+        assert key == "celery"
+        return self.queued
+
+    def scan_iter(self, pattern):
+        return [k for k in self.hashes if k.startswith(pattern.rstrip("*"))]
+
+    def hgetall(self, key):
+        return self.hashes[key]
+
+
+def _settings(monkeypatch, **overrides):
+    config = {"indexing.pipeline.embedding.workers": 1, "indexing.pipeline.embedding.queue": 1, **overrides}
+    monkeypatch.setattr(pipeline.settings, "get", lambda key, default=None: config.get(key, default))
+
+
+def test_stage_bounds_workers_and_refuses_past_queue(monkeypatch):
+    _settings(monkeypatch)
+    redis = FakeRedis()
+    limiter = StageLimiter(redis_client=redis)
+    entered, release = threading.Event(), threading.Event()
+
+    def hold():
+        with limiter.stage("embedding"):
+            entered.set()
+            release.wait(5)
+
+    holder = threading.Thread(target=hold)
+    holder.start()
+    entered.wait(5)
+    waiter = threading.Thread(target=hold)
+    waiter.start()
+    while limiter.depths()["embedding"]["waiting"] == 0:
+        pass
+
+    # One running, one queued: the next file is refused
+    with pytest.raises(PipelineBusy) as busy:
+        with limiter.stage("embedding"):
+            pass
+    assert busy.value.stage == "embedding" and busy.value.retry_after == 5
+    assert pipeline.stage_depths(redis)["embedding"] == {"active": 1, "waiting": 1}
+
+    release.set()
+    holder.join(5)
+    waiter.join(5)
+    assert limiter.depths()["embedding"] == {"active": 0, "waiting": 0}
+    # Other stages are independent
+    with limiter.stage("upsert"):
+        assert limiter.depths()["upsert"]["active"] == 1
+
+
+def test_admission_refuses_uploads_over_queue_limit(monkeypatch):
+    _settings(monkeypatch, **{"indexing.pipeline.max_queued_files": 10})
+    assert pipeline.check_admission(FakeRedis(queued=9)) is None
+    assert pipeline.check_admission(FakeRedis(queued=10)) == 5
+
+    _settings(monkeypatch, **{"indexing.pipeline.max_queued_files": 0})
+    assert pipeline.check_admission(FakeRedis(queued=10_000)) is None
+
+
+def test_gauges_sum_worker_processes(monkeypatch):
+    _settings(monkeypatch)
+    redis = FakeRedis(queued=42)
+    redis.hashes = {
+        "rice:pipeline:stages:w1:1": {"embedding:active": "2", "embedding:waiting": "3"},
+        "rice:pipeline:stages:w2:7": {"embedding:active": "1", "upsert:active": "1"},
+    }
+    text = "\n".join(pipeline.prometheus_lines(redis))
+    assert "rice_search_index_queue_depth 42" in text
+    assert 'rice_search_index_stage_files{stage="embedding",state="active"} 3' in text
+    assert 'rice_search_index_stage_files{stage="embedding",state="waiting"} 3' in text
+    assert 'rice_search_index_stage_files{stage="upsert",state="active"} 1' in text
@@ -0,0 +1,74 @@
+"""
+Unit tests for inference timeouts and the watchdog.
+"""
+import asyncio
+import pytest
+from unittest.mock import MagicMock
+
+
+def _watchdog(monkeypatch, max_timeouts=3):
+    from src.services.inference import watchdog
+
+    values = {"inference.watchdog.max_consecutive_timeouts": max_timeouts}
+    monkeypatch.setattr(watchdog.settings, "get", lambda key, default=None: values.get(key, default))
+
+    store = MagicMock()
+    store.get_counter.return_value = 0
+    dog = watchdog.InferenceWatchdog()
+    monkeypatch.setattr(dog, "_store", lambda: store)
+    return dog, store
+
+
+async def _slow():
+    await asyncio.sleep(1)
+
+
+async def _fast():
+    return "ok"
+
+
+@pytest.mark.unit
+class TestInferenceWatchdog:
+    """Test timeout enforcement and handler resets."""
+
+    def test_timeout_raises_typed_error(self, monkeypatch):
+        """Slow calls raise InferenceTimeoutError and count a timeout."""
+        from src.services.inference.watchdog import InferenceTimeoutError, EMBED
+
+        dog, store = _watchdog(monkeypatch)
+
+        with pytest.raises(InferenceTimeoutError) as exc:
+            asyncio.run(dog.run(EMBED, _slow, timeout=0.01))
+
+        assert exc.value.kind == EMBED
+        store.increment_counter.assert_called_with("inference_timeouts_embed")
+
+    def test_success_returns_result_and_clears_streak(self, monkeypatch):
+        """A successful call resets the consecutive timeout count."""
+        from src.services.inference.watchdog import InferenceTimeoutError, RERANK
+
+        dog, _ = _watchdog(monkeypatch)
+
+        with pytest.raises(InferenceTimeoutError):
+            asyncio.run(dog.run(RERANK, _slow, timeout=0.01))
+        assert dog.get_stats()[RERANK]["consecutive_timeouts"] == 1
+
+        assert asyncio.run(dog.run(RERANK, _fast, timeout=1)) == "ok"
+        assert dog.get_stats()[RERANK]["consecutive_timeouts"] == 0
+
+    def test_reset_after_repeated_timeouts(self, monkeypatch):
+        """The handler is rebuilt and an alert raised after the threshold."""
+        from src.services.inference.watchdog import InferenceTimeoutError, SPARSE
+
+        dog, store = _watchdog(monkeypatch, max_timeouts=2)
+        handler = MagicMock()
+        dog.register_reset(SPARSE, handler)
+
+        for _ in range(2):
+            with pytest.raises(InferenceTimeoutError):
+                asyncio.run(dog.run(SPARSE, _slow, timeout=0.01))
+
+        handler.assert_called_once()
+        store.increment_counter.assert_any_call("inference_resets_sparse")
+        assert store.raise_alert.call_args.kwargs["source"] == "inference.sparse"
+        assert dog.get_stats()[SPARSE]["consecutive_timeouts"] == 0
@@ -23,3 +23,25 @@ def test_indexer_sanity():
         
         assert res["status"] == "success"
         mock_qdrant.upsert.assert_called_once()
+
+
+def test_delete_by_connection_removes_chunks_everywhere():
+    """Chunks from a connection are removed from Qdrant and Tantivy."""
+    from types import SimpleNamespace
+
+    mock_qdrant = MagicMock()
+    mock_qdrant.scroll.return_value = ([
+        SimpleNamespace(id="c1", payload={"org_id": "shared", "full_path": "a.py"}),
+        SimpleNamespace(id="c2", payload={"org_id": "shared", "full_path": "a.py"}),
+        SimpleNamespace(id="c3", payload={"org_id": "shared", "full_path": "b.py"}),
+    ], None)
+
+    indexer = Indexer(mock_qdrant)
+    indexer._tantivy_client = MagicMock()
+
+    res = indexer.delete_by_connection("conn-a", "shared")
+
+    assert res["chunks_removed"] == 3
+    assert res["files"] == {"shared": ["a.py", "b.py"]}
+    mock_qdrant.delete.assert_called_once()
+    assert indexer._tantivy_client.delete.call_count == 3
@@ -0,0 +1,89 @@
+"""
+Unit tests for server-side language detection and the language backfill.
+"""
+import pytest
+from types import SimpleNamespace
+from unittest.mock import MagicMock
+
+
+@pytest.mark.unit
+class TestDetectLanguage:
+    """Test each detection source."""
+
+    def test_extension(self):
+        from src.services.ingestion.language import detect_language
+
+        assert detect_language("src/main.py") == "python"
+        assert detect_language("web/App.TSX") == "tsx"
+        assert detect_language("infra/main.tf") == "hcl"
+
+    def test_well_known_file_names(self):
+        from src.services.ingestion.language import detect_language
+
+        assert detect_language("deploy/Dockerfile") == "dockerfile"
+        assert detect_language("Makefile") == "makefile"
+        assert detect_language("C:\\repo\\Gemfile") == "ruby"
+
+    def test_shebang(self):
+        from src.services.ingestion.language import detect_language
+
+        assert detect_language("bin/deploy", "#!/usr/bin/env python3.11\nprint('hi')\n") == "python"
+        assert detect_language("scripts/run", "#!/bin/bash\nset -e\n") == "shell"
+        assert detect_language("tool", "#!/usr/bin/env -S node --no-warnings\n") == "javascript"
+
+    def test_content_heuristics_for_extensionless_files(self):
+        from src.services.ingestion.language import detect_language
+
+        go = "package main\n\nfunc main() {\n\tx := 1\n}\n"
+        assert detect_language("snippet", go) == "go"
+        assert detect_language("notes", "just some words") is None
+
+    def test_ambiguous_header(self):
+        from src.services.ingestion.language import detect_language
+
+        assert detect_language("vec.h", "namespace geo {\ntemplate <typename T> class Vec {};\n}") == "cpp"
+        assert detect_language("list.h", "#include <stdlib.h>\nstruct node *next;\n") == "c"
+        assert detect_language("empty.h", "") == "c"
+
+
+@pytest.mark.unit
+class TestLanguageBackfill:
+    """Test labeling existing chunks."""
+
+    def _qdrant(self, points):
+        qdrant = MagicMock()
+        qdrant.get_collection.side_effect = Exception("no cold tier")
+        qdrant.scroll.return_value = (points, None)
+        return qdrant
+
+    def _point(self, pid, path, index, text=""):
+        return SimpleNamespace(id=pid, payload={"full_path": path, "chunk_index": index, "text": text})
+
+    def test_labels_chunks_per_file(self):
+        from src.services.admin.language_backfill import LanguageBackfill
+
+        qdrant = self._qdrant([
+            self._point(1, "repo/app.py", 0),
+            self._point(2, "repo/app.py", 1),
+            self._point(3, "repo/bin/run", 0, "#!/bin/sh\necho hi\n"),
+            self._point(4, "repo/LICENSE", 0, "Permission is hereby granted"),
+        ])
+
+        result = LanguageBackfill(qdrant_client=qdrant).run(org_id="backend")
+
+        assert result["files"] == 2
+        assert result["chunks_updated"] == 3
+        assert result["languages"] == {"python": 1, "shell": 1}
+        assert result["undetected"] == 1
+        written = {tuple(c.kwargs["points"]): c.kwargs["payload"]["language"] for c in qdrant.set_payload.call_args_list}
+        assert written == {(1, 2): "python", (3,): "shell"}
+
+    def test_dry_run_writes_nothing(self):
+        from src.services.admin.language_backfill import LanguageBackfill
+
+        qdrant = self._qdrant([self._point(1, "repo/app.go", 0)])
+
+        result = LanguageBackfill(qdrant_client=qdrant).run(dry_run=True)
+
+        assert result["chunks_matched"] == 1 and result["chunks_updated"] == 0
+        qdrant.set_payload.assert_not_called()
@@ -0,0 +1,75 @@
+"""
+Tests for debounced, cancel-on-supersede live search sessions.
+"""
+import asyncio
+
+from fastapi import HTTPException
+
+from src.services.search.live import LiveSearchSession
+
+
+def _session(delay: float = 0.0, debounce_ms: float = 20, fail: Exception = None):
+    searched, sent = [], []
+
+    async def search(message):
+        searched.append(message["query"])
+        await asyncio.sleep(delay)
+        if fail:
+            raise fail
+        return {"results": [{"text": message["query"]}]}
+
+    async def send(payload):
+        sent.append(payload)
+
+    session = LiveSearchSession(search, send, debounce_ms=debounce_ms, min_chars=2)
+    return session, searched, sent
+
+
+def test_typing_is_debounced():
+    async def run():
+        session, searched, sent = _session()
+        for seq, query in enumerate(["p", "pa", "par", "pars"], start=1):
+            await session.submit({"seq": seq, "query": query})
+            await asyncio.sleep(0.005)
+        await asyncio.sleep(0.05)
+        return session, searched, sent
+
+    session, searched, sent = asyncio.run(run())
+    assert searched == ["pars"]
+    assert [(m["seq"], m["type"]) for m in sent] == [(4, "results")]
+    assert sent[0]["results"] == [{"text": "pars"}]
+    assert session.superseded == 3
+
+
+def test_in_flight_search_is_cancelled():
+    async def run():
+        session, searched, sent = _session(delay=0.05, debounce_ms=0)
+        await session.submit({"seq": 1, "query": "retry"})
+        await asyncio.sleep(0.01)  # first search running
+        await session.submit({"seq": 2, "query": "retry backoff"})
+        await asyncio.sleep(0.1)
+        return searched, sent
+
+    searched, sent = asyncio.run(run())
+    assert searched == ["retry", "retry backoff"]
+    assert [m["seq"] for m in sent] == [2]
+    assert sent[0]["query"] == "retry backoff" and "took_ms" in sent[0]
+
+
+def test_short_query_and_errors():
+    async def run(fail):
+        session, searched, sent = _session(debounce_ms=0, fail=fail)
+        await session.submit({"seq": 1, "query": " a "})
+        await asyncio.sleep(0.01)
+        await session.submit({"seq": 2, "query": "auth"})
+        await asyncio.sleep(0.01)
+        await session.close()
+        return searched, sent
+
+    searched, sent = asyncio.run(run(HTTPException(status_code=400, detail="Invalid modified_since: x")))
+    assert searched == ["auth"]
+    assert sent[0] == {"type": "results", "seq": 1, "query": "a", "results": []}
+    assert sent[1] == {"type": "error", "seq": 2, "status": 400, "detail": "Invalid modified_since: x"}
+
+    _, sent = asyncio.run(run(RuntimeError("qdrant down")))
+    assert sent[1]["status"] == 500 and "qdrant down" in sent[1]["detail"]
@@ -0,0 +1,89 @@
+"""
+Tests for the SQLite metrics persistence backend.
+"""
+from src.services.admin.metrics_store import SqliteMetricsStore
+from src.services.admin.timeseries import MetricsTimeSeries
+
+HOUR = 3600
+DAY = 24 * HOUR
+NOW = 1792152000
+
+
+def test_counters_survive_a_restart(tmp_path):
+    path = str(tmp_path / "metrics.db")
+    store = SqliteMetricsStore(path, autoflush=False)
+    store.increment("searches", 3)
+    # Unflushed deltas are already visible to this process
+    assert store.get_counter("searches") == 3
+    assert store.flush() == 1
+    store.increment("searches")
+
+    restarted = SqliteMetricsStore(path, autoflush=False)
+    assert restarted.get_counter("searches") == 3
+    assert restarted.get_counter("missing") == 0
+    store.flush()
+    assert restarted.get_counter("searches") == 4
+
+
+def test_series_merge_file_and_pending_deltas(tmp_path):
+    store = SqliteMetricsStore(str(tmp_path / "metrics.db"), autoflush=False)
+    store.add_series(60, "_all", NOW, {"searches": 1, "latency_ms_sum": 40.0})
+    store.flush()
+    store.add_series(60, "_all", NOW, {"searches": 1, "latency_ms_sum": 20.0})
+    store.add_series(60, "backend", NOW, {"searches": 1})
+
+    buckets = store.read_series(60, "_all", [NOW - 60, NOW])
+    assert buckets == [{}, {"searches": 2, "latency_ms_sum": 60.0}]
+    assert store.read_series(900, "_all", [NOW]) == [{}]
+
+
+def test_compact_drops_expired_and_unconfigured_buckets(tmp_path):
+    store = SqliteMetricsStore(str(tmp_path / "metrics.db"), autoflush=False)
+    store.add_series(60, "_all", NOW - 2 * DAY, {"searches": 1})
+    store.add_series(60, "_all", NOW - HOUR, {"searches": 1})
+    store.add_series(300, "_all", NOW - HOUR, {"searches": 1})
+    store.flush()
+
+    assert store.compact([(60, DAY)], now=NOW) == 2
+    assert store.read_series(60, "_all", [NOW - 2 * DAY, NOW - HOUR]) == [{}, {"searches": 1}]
+    assert store.read_series(300, "_all", [NOW - HOUR]) == [{}]
+
+
+def test_time_series_queries_read_from_sqlite(tmp_path, monkeypatch):
+    from src.services.admin import timeseries
+
+    monkeypatch.setattr(timeseries.settings, "get", lambda key, default=None: default)
+    path = str(tmp_path / "metrics.db")
+    series = MetricsTimeSeries(metrics_store=SqliteMetricsStore(path, autoflush=False))
+    series.record_search("backend", 40.0, now=NOW - 30 * 60)
+    series.record_index("backend", "success", chunks=5, now=NOW - 10)
+    series._sqlite.flush()
+
+    restarted = MetricsTimeSeries(metrics_store=SqliteMetricsStore(path, autoflush=False))
+    result = restarted.query("1h", "1h", "backend", now=NOW)
+    assert result["totals"] == {"searches": 1, "files_indexed": 1, "index_errors": 0, "chunks_indexed": 5}
+
+
+def test_flushing_runs_as_a_supervised_task(tmp_path, monkeypatch):
+    import time
+    from src.core.supervisor import TaskSupervisor
+    from src.services.admin import metrics_store
+
+    supervisor = TaskSupervisor(history=10)
+    monkeypatch.setattr(metrics_store, "get_supervisor", lambda: supervisor)
+    monkeypatch.setattr(metrics_store.settings, "get", lambda key, default=None: (
+        0.01 if key == "metrics.sqlite.flush_interval_seconds" else default
+    ))
+    path = str(tmp_path / "metrics.db")
+    store = SqliteMetricsStore(path)
+    store.increment("searches", 2)
+    for _ in range(100):
+        if SqliteMetricsStore(path, autoflush=False).get_counter("searches") == 2:
+            break
+        time.sleep(0.01)
+
+    [task] = supervisor.tasks()
+    assert task["name"] == "metrics-flush" and task["group"] == "metrics"
+    assert SqliteMetricsStore(path, autoflush=False).get_counter("searches") == 2
+    store.close()
+    assert store._task.join(1)
@@ -0,0 +1,83 @@
+"""
+Tests for the metrics time series (tiers, ranges, granularity).
+"""
+import pytest
+
+from src.services.admin import timeseries
+from src.services.admin.timeseries import InvalidRange, MetricsTimeSeries, Tier, choose_tier, parse_duration
+
+HOUR = 3600
+DAY = 24 * HOUR
+# 2026-10-16T12:00:00Z
+NOW = 1792152000
+
+
+@pytest.fixture
+def values(monkeypatch):
+    values = {}
+    monkeypatch.setattr(timeseries.settings, "get", lambda key, default=None: values.get(key, default))
+    return values
+
+
+@pytest.fixture
+def series(fake_redis):
+    return MetricsTimeSeries(redis_client=fake_redis)
+
+
+def test_durations_and_tier_choice():
+    assert parse_duration("15m") == 900
+    assert parse_duration("7d") == 7 * DAY
+    for bad in ("", "0h", "1w", "h"):
+        with pytest.raises(InvalidRange):
+            parse_duration(bad)
+
+    tiers = [Tier(60, DAY), Tier(900, 7 * DAY), Tier(HOUR, 30 * DAY)]
+    assert choose_tier(tiers, DAY, 300) == Tier(60, DAY)
+    assert choose_tier(tiers, DAY, HOUR) == Tier(HOUR, 30 * DAY)
+    # 5m is finer than anything kept for 7 days
+    assert choose_tier(tiers, 7 * DAY, 300) == Tier(900, 7 * DAY)
+    with pytest.raises(InvalidRange):
+        choose_tier(tiers, 90 * DAY, HOUR)
+
+
+def test_events_land_in_every_tier_with_retention(values, series):
+    series.record_search("backend", 40.0, now=NOW)
+    series.record_index("backend", "success", chunks=12, now=NOW)
+
+    keys = series.redis.hashes
+    assert keys[f"rice:metrics:ts:60:backend:{NOW}"]["searches"] == "1"
+    assert keys[f"rice:metrics:ts:3600:_all:{NOW}"]["chunks_indexed"] == "12"
+    assert series.redis.expiry[f"rice:metrics:ts:900:_all:{NOW}"] == 7 * DAY + 900
+
+
+def test_query_slices_and_aggregates_by_granularity(values, series):
+    series.record_search("backend", 40.0, now=NOW - 30 * 60)
+    series.record_search("backend", 20.0, now=NOW - 50 * 60)
+    series.record_search("docs", 10.0, now=NOW - 10)
+    series.record_search("backend", 90.0, now=NOW - 3 * HOUR)
+    series.record_index("backend", "error", now=NOW - 5 * 60)
+
+    result = series.query("2h", "1h", "backend", now=NOW)
+    assert (result["granularity"], result["tier"]) == ("1h", "1h")
+    assert [p["searches"] for p in result["points"]] == [0, 2, 0]
+    assert result["points"][1]["avg_latency_ms"] == 30.0
+    assert result["totals"] == {"searches": 2, "files_indexed": 0, "index_errors": 1, "chunks_indexed": 0}
+
+    everything = series.query("24h", "15m", now=NOW)
+    assert everything["tier"] == "15m" and len(everything["points"]) == 97
+    assert everything["totals"]["searches"] == 4
+
+
+def test_coarse_ranges_widen_granularity_and_reject_past_retention(values, series):
+    series.record_search(None, 5.0, now=NOW - 6 * DAY)
+
+    result = series.query("7d", "5m", now=NOW)
+    assert result["granularity"] == "15m"
+    assert result["totals"]["searches"] == 1
+
+    assert series.query("30d", now=NOW)["granularity"] == "1h"
+    with pytest.raises(InvalidRange):
+        series.query("60d", now=NOW)
+    values["metrics.timeseries.max_points"] = 100
+    with pytest.raises(InvalidRange):
+        series.query("24h", "1m", now=NOW)
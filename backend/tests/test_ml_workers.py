@@ -0,0 +1,56 @@
+"""
+Unit tests for ML worker heartbeats and remote inference health.
+"""
+import pytest
+from datetime import datetime, timedelta
+from unittest.mock import MagicMock
+
+from src.services.inference import ml_workers
+
+
+def _heartbeat(worker_id, seconds_ago, topics, **extra):
+    at = datetime.now() - timedelta(seconds=seconds_ago)
+    return {
+        "topic": ml_workers.HEARTBEAT_TOPIC,
+        "timestamp": at.isoformat(),
+        "payload": {"worker_id": worker_id, "topics": topics, **extra},
+    }
+
+
+def _setup(monkeypatch, events, **values):
+    bus = MagicMock()
+    bus.recent.return_value = events
+    monkeypatch.setattr(ml_workers, "get_event_bus", lambda: bus)
+    monkeypatch.setattr(ml_workers.settings, "get", lambda key, d=None: values.get(key, d))
+
+
+@pytest.mark.unit
+class TestMLWorkers:
+    """Test worker listing and health from heartbeats."""
+
+    def test_latest_heartbeat_per_worker(self, monkeypatch):
+        _setup(monkeypatch, [
+            _heartbeat("gpu-1", 40, ["inference.embed"], requests={"inference.embed": 1}),
+            _heartbeat("gpu-1", 5, ["inference.embed"], requests={"inference.embed": 9}),
+            _heartbeat("gpu-2", 600, ["inference.rerank"]),
+        ])
+
+        workers = {w["worker_id"]: w for w in ml_workers.list_ml_workers()}
+        assert workers["gpu-1"]["requests"] == {"inference.embed": 9}
+        assert workers["gpu-1"]["live"] is True
+        assert workers["gpu-2"]["live"] is False
+
+    def test_health_disabled_without_remote_inference(self, monkeypatch):
+        _setup(monkeypatch, [])
+        assert ml_workers.ml_worker_health()["status"] == "disabled"
+
+    def test_health_down_when_topic_has_no_live_worker(self, monkeypatch):
+        _setup(monkeypatch, [
+            _heartbeat("gpu-1", 5, ["inference.embed"]),
+            _heartbeat("gpu-2", 5, ["inference.rerank"], stopping=True),
+        ], **{"inference.remote.embed": True, "inference.remote.rerank": True})
+
+        health = ml_workers.ml_worker_health()
+        assert health["status"] == "down"
+        assert health["topics"] == {"inference.embed": 1, "inference.rerank": 0}
+        assert "inference.rerank" in health["error"]
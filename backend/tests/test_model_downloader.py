@@ -0,0 +1,201 @@
+"""
+Tests for resumable, verified model downloads.
+"""
+import hashlib
+import json
+import os
+import time
+from contextlib import contextmanager
+
+from src.services.inference import model_downloader
+from src.services.inference.model_downloader import (
+    MANIFEST,
+    ChecksumError,
+    ModelDownloader,
+    select_files,
+)
+
+WEIGHTS = bytes(range(256)) * 40  # 10240 bytes
+CONFIG = b'{"hidden_size": 8}'
+
+
+def _blob_id(data: bytes) -> str:
+    return hashlib.sha1(f"blob {len(data)}\0".encode() + data).hexdigest()
+
+
+def _listing(weights_sha=None):
+    return {
+        "sha": "abc123def456",
+        "siblings": [
+            {"rfilename": "config.json", "size": len(CONFIG), "blobId": _blob_id(CONFIG)},
+            {
+                "rfilename": "model.safetensors",
+                "size": len(WEIGHTS),
+                "blobId": "lfs-pointer",
+                "lfs": {"sha256": weights_sha or hashlib.sha256(WEIGHTS).hexdigest(), "size": len(WEIGHTS)},
+            },
+        ],
+    }
+
+
+class FakeResponse:
+    def __init__(self, status_code=200, data=b"", payload=None, headers=None):
+        self.status_code = status_code
+        self.data = data
+        self.payload = payload
+        self.headers = headers or {}
+
+    def json(self):
+        return self.payload
+
+    def raise_for_status(self):
+        if self.status_code >= 400:
+            raise RuntimeError(f"HTTP {self.status_code}")
+
+    def iter_bytes(self, size):
+        for i in range(0, len(self.data), size):
+            yield self.data[i:i + size]
+
+
+class FakeHub:
+    """Serves a listing and file bodies; records the Range headers it saw."""
+
+    def __init__(self, listing, files, ranges=True):
+        self.listing = listing
+        self.files = files
+        self.ranges = ranges
+        self.requests = []
+
+    def __enter__(self):
+        return self
+
+    def __exit__(self, *exc):
+        return False
+
+    def get(self, url, params=None):
+        return FakeResponse(payload=self.listing)
+
+    def head(self, url):
+        return FakeResponse(headers={"accept-ranges": "bytes"} if self.ranges else {})
+
+    @contextmanager
+    def stream(self, method, url, headers=None):
+        data = self.files[url.rsplit("/", 1)[-1]]
+        header = (headers or {}).get("Range")
+        self.requests.append((url.rsplit("/", 1)[-1], header))
+        if header and self.ranges:
+            start, _, end = header[len("bytes="):].partition("-")
+            start = int(start)
+            if start >= len(data):
+                yield FakeResponse(status_code=416)
+                return
+            end = int(end) if end else len(data) - 1
+            yield FakeResponse(status_code=206, data=data[start:end + 1])
+        else:
+            yield FakeResponse(status_code=200, data=data)
+
+
+def _downloader(monkeypatch, tmp_path, hub, chunk_bytes=None):
+    monkeypatch.setattr(model_downloader.settings, "get", lambda key, d=None: d)
+    downloader = ModelDownloader(root=str(tmp_path), endpoint="https://hub.test")
+    if chunk_bytes:
+        downloader.chunk_bytes = chunk_bytes
+    downloader._client = lambda: hub
+    return downloader
+
+
+def test_select_files_prefers_safetensors_and_skips_onnx():
+    siblings = [
+        {"rfilename": "config.json", "size": 10, "blobId": "a"},
+        {"rfilename": "model.safetensors", "size": 100, "lfs": {"sha256": "s", "size": 100}},
+        {"rfilename": "pytorch_model.bin", "size": 100, "lfs": {"sha256": "b", "size": 100}},
+        {"rfilename": "onnx/model.onnx", "size": 100, "lfs": {"sha256": "o", "size": 100}},
+        {"rfilename": "README.md", "size": 5, "blobId": "r"},
+    ]
+    files = select_files(siblings)
+    assert [f["path"] for f in files] == ["config.json", "model.safetensors"]
+    assert files[0]["blob_id"] == "a" and files[0]["sha256"] is None
+    assert files[1]["sha256"] == "s" and files[1]["blob_id"] is None
+
+
+def test_download_verifies_and_records_manifest(monkeypatch, tmp_path):
+    hub = FakeHub(_listing(), {"config.json": CONFIG, "model.safetensors": WEIGHTS})
+    downloader = _downloader(monkeypatch, tmp_path, hub)
+
+    path = downloader.download("org/model")
+
+    assert path == os.path.join(str(tmp_path), "org--model")
+    with open(os.path.join(path, "model.safetensors"), "rb") as f:
+        assert f.read() == WEIGHTS
+    with open(os.path.join(path, MANIFEST)) as f:
+        assert json.load(f)["sha"] == "abc123def456"
+    status = downloader.status()[0]
+    assert status["state"] == "complete"
+    assert status["files_done"] == 2
+    assert status["bytes_done"] == status["bytes_total"]
+
+    # Same revision again: nothing is fetched
+    hub.requests.clear()
+    assert downloader.download("org/model") == path
+    assert hub.requests == []
+
+
+def test_download_resumes_partial_file(monkeypatch, tmp_path):
+    hub = FakeHub(_listing(), {"config.json": CONFIG, "model.safetensors": WEIGHTS})
+    downloader = _downloader(monkeypatch, tmp_path, hub)
+    target = tmp_path / "org--model"
+    target.mkdir()
+    (target / "model.safetensors.part").write_bytes(WEIGHTS[:4000])
+
+    downloader.download("org/model")
+
+    assert ("model.safetensors", "bytes=4000-") in hub.requests
+    assert (target / "model.safetensors").read_bytes() == WEIGHTS
+    assert not (target / "model.safetensors.part").exists()
+
+
+def test_checksum_mismatch_removes_partial(monkeypatch, tmp_path):
+    hub = FakeHub(_listing(weights_sha="0" * 64), {"config.json": CONFIG, "model.safetensors": WEIGHTS})
+    downloader = _downloader(monkeypatch, tmp_path, hub)
+
+    try:
+        downloader.download("org/model")
+    except ChecksumError as e:
+        assert "model.safetensors" in str(e)
+    else:
+        raise AssertionError("expected ChecksumError")
+
+    target = tmp_path / "org--model"
+    assert not (target / "model.safetensors").exists()
+    assert not (target / "model.safetensors.part").exists()
+    assert not (target / MANIFEST).exists()
+    assert downloader.status()[0]["state"] == "failed"
+
+
+def test_large_files_download_in_ranged_chunks(monkeypatch, tmp_path):
+    hub = FakeHub(_listing(), {"config.json": CONFIG, "model.safetensors": WEIGHTS})
+    downloader = _downloader(monkeypatch, tmp_path, hub, chunk_bytes=3000)
+
+    path = downloader.download("org/model")
+
+    chunk_requests = sorted(h for name, h in hub.requests if name == "model.safetensors")
+    assert chunk_requests == sorted(["bytes=0-2999", "bytes=3000-5999", "bytes=6000-8999", "bytes=9000-10239"])
+    with open(os.path.join(path, "model.safetensors"), "rb") as f:
+        assert f.read() == WEIGHTS
+    assert not [p for p in os.listdir(path) if ".part" in p]
+
+
+def test_cleanup_partials_only_removes_stale_files(monkeypatch, tmp_path):
+    downloader = _downloader(monkeypatch, tmp_path, FakeHub(_listing(), {}))
+    target = tmp_path / "org--model"
+    target.mkdir()
+    stale = target / "model.safetensors.part"
+    fresh = target / "tokenizer.json.part"
+    stale.write_bytes(b"x")
+    fresh.write_bytes(b"y")
+    old = time.time() - 3 * 24 * 3600
+    os.utime(stale, (old, old))
+
+    assert downloader.cleanup_partials() == 1
+    assert not stale.exists()
+    assert fresh.exists()
@@ -0,0 +1,92 @@
+"""
+Tests for lazy model loading, warm-up and idle unloading.
+"""
+import time
+from unittest.mock import MagicMock
+
+from src.services import model_manager
+from src.services.model_manager import ModelManager
+
+
+def _manager(monkeypatch):
+    events = []
+    monkeypatch.setattr(model_manager, "emit", lambda topic, **payload: events.append((topic, payload)))
+    monkeypatch.setattr(ModelManager, "_sync_model_status_to_redis", lambda self, model_id: None)
+    ModelManager._instance = None
+    manager = ModelManager()
+    ModelManager._instance = manager
+    return manager, events
+
+
+def test_tracked_model_is_unloaded_when_idle(monkeypatch):
+    manager, events = _manager(monkeypatch)
+    unloader = MagicMock()
+    model_manager.track_loaded_model("splade", "sparse_embedding", MagicMock(), unloader, 2.5, 120.0)
+    assert events[-1] == ("model.loaded", {
+        "model_id": "splade", "type": "sparse_embedding", "load_seconds": 2.5, "warmup_ms": 120.0,
+    })
+
+    manager._models["splade"]["last_accessed"] = time.time() - 400
+    assert manager.check_ttl(ttl_seconds=300) == 1
+
+    unloader.assert_called_once()
+    topic, payload = events[-1]
+    assert topic == "model.unloaded"
+    assert payload["reason"] == "idle"
+    assert payload["idle_seconds"] >= 400
+    ModelManager._instance = None
+
+
+def test_touch_keeps_model_loaded(monkeypatch):
+    manager, _ = _manager(monkeypatch)
+    model_manager.track_loaded_model("reranker", "reranker", MagicMock(), MagicMock(), 1.0)
+    manager._models["reranker"]["last_accessed"] = time.time() - 400
+
+    model_manager.touch_model("reranker")
+
+    assert manager.check_ttl(ttl_seconds=300) == 0
+    assert manager._models["reranker"]["loaded"] is True
+    ModelManager._instance = None
+
+
+def test_touch_before_any_model_is_a_noop():
+    ModelManager._instance = None
+    model_manager.touch_model("missing")
+    assert ModelManager._instance is None
+
+
+def test_health_reports_loaded_and_idle(monkeypatch):
+    manager, _ = _manager(monkeypatch)
+    model_manager.track_loaded_model("splade", "sparse_embedding", MagicMock(), MagicMock(), 3.14159, 50.0)
+    manager.register_model("bm42", "bm42")
+    manager._models["splade"]["last_accessed"] = time.time() - 30
+
+    health = manager.get_health()
+
+    assert health["loaded"] == ["splade"]
+    assert health["models"]["splade"]["idle_seconds"] == 30
+    assert health["models"]["splade"]["load_seconds"] == 3.14
+    assert health["models"]["bm42"] == {"type": "bm42", "loaded": False}
+    ModelManager._instance = None
+
+
+def test_warm_up_model(monkeypatch):
+    values = {"model_management.warmup.text": "hello"}
+    monkeypatch.setattr(model_manager.settings, "get", lambda key, d=None: values.get(key, d))
+    seen = []
+
+    assert model_manager.warm_up_model("m", seen.append) is not None
+    assert seen == ["hello"]
+
+    values["model_management.warmup.enabled"] = False
+    assert model_manager.warm_up_model("m", seen.append) is None
+    assert seen == ["hello"]
+
+
+def test_failed_warm_up_does_not_raise(monkeypatch):
+    monkeypatch.setattr(model_manager.settings, "get", lambda key, d=None: d)
+
+    def boom(text):
+        raise RuntimeError("out of memory")
+
+    assert model_manager.warm_up_model("m", boom) is None
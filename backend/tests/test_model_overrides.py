@@ -0,0 +1,130 @@
+"""Tests for per-request embedding and reranker overrides."""
+
+import asyncio
+from types import SimpleNamespace
+
+import pytest
+
+from src.core.config import settings
+from src.services.search import model_overrides, reranker
+from src.services.search.model_overrides import (
+    available_models,
+    resolve_embedding_model,
+    resolve_rerank_model,
+)
+from src.services.search.retriever import MultiRetriever
+
+
+class FakeAdminStore:
+    def __init__(self, stores, models=None):
+        self.stores = stores
+        self.models = models or {}
+
+    def get_stores(self):
+        return self.stores
+
+    def get_models(self):
+        return self.models
+
+
+STORES = {
+    "backend": {
+        "embedding_model": "Qwen/Qwen3-Embedding-4B",
+        "experiment": {"kind": "embedding", "model_a": "Qwen/Qwen3-Embedding-4B", "model_b": "BAAI/bge-m3"},
+    },
+    "docs": {"experiment": {"kind": "rerank", "model_b": "jinaai/jina-reranker-v2"}},
+}
+REGISTRY = {
+    "baai-bge-reranker-base": {"id": "baai-bge-reranker-base", "name": "BAAI/bge-reranker-base", "type": "reranker"},
+    "baai-bge-m3": {"id": "baai-bge-m3", "name": "BAAI/bge-m3", "type": "embedding"},
+}
+
+
+def _stores(monkeypatch):
+    from src.services.admin import admin_store
+    monkeypatch.setattr(admin_store, "get_admin_store", lambda: FakeAdminStore(STORES, REGISTRY))
+
+
+def test_embedding_models_must_be_indexed_in_the_store(monkeypatch):
+    _stores(monkeypatch)
+    assert resolve_embedding_model("backend", None) is None
+    # The store's own model is no override
+    assert resolve_embedding_model("backend", "qwen-qwen3-embedding-4b") is None
+
+    target = resolve_embedding_model("backend", "baai-bge-m3")
+    assert target["model"] == "BAAI/bge-m3"
+    assert target["vector"] == "dense_b"
+    assert target["collection"].endswith("_exp_backend")
+    assert resolve_embedding_model("backend", "BAAI/BGE-M3")["model"] == "BAAI/bge-m3"
+
+    # In the registry but not indexed in this store
+    with pytest.raises(ValueError, match="not indexed in store 'docs'"):
+        resolve_embedding_model("docs", "baai-bge-m3")
+
+
+def test_rerank_models_come_from_settings_registry_and_experiments(monkeypatch):
+    _stores(monkeypatch)
+    assert resolve_rerank_model("docs", settings.RERANK_MODEL) is None
+    assert resolve_rerank_model("docs", "baai-bge-reranker-base") == "BAAI/bge-reranker-base"
+    assert resolve_rerank_model("docs", "jinaai-jina-reranker-v2") == "jinaai/jina-reranker-v2"
+    with pytest.raises(ValueError, match="Unknown reranker"):
+        resolve_rerank_model("backend", "jinaai/jina-reranker-v2")
+
+    models = available_models("backend")
+    assert [m["source"] for m in models["embedding"]] == ["store", "experiment"]
+    assert models["rerank"][0] == {
+        "model": settings.RERANK_MODEL,
+        "id": model_overrides.model_slug(settings.RERANK_MODEL),
+        "default": True,
+    }
+
+
+def test_rerank_override_scores_with_the_named_model(monkeypatch):
+    calls = []
+
+    async def fake_rerank_with_model(query, texts, model):
+        calls.append(model)
+        return [0.1 * i for i in range(len(texts))]
+
+    async def configured(query, texts):
+        raise AssertionError("configured reranker used")
+
+    monkeypatch.setattr(reranker, "rerank_with_model", fake_rerank_with_model)
+    monkeypatch.setattr(reranker, "rerank_results", configured)
+    results = [{"chunk_id": str(i), "text": f"t{i}"} for i in range(3)]
+    ranked = asyncio.run(reranker.rerank_search_results("q", results, model="BAAI/bge-reranker-base"))
+    assert calls == ["BAAI/bge-reranker-base"]
+    assert [r["chunk_id"] for r in ranked] == ["2", "1", "0"]
+
+
+def test_dense_override_reads_payloads_from_the_store_collection(monkeypatch):
+    _stores(monkeypatch)
+
+    class FakeClient:
+        async def embed(self, texts, model=None):
+            assert model == "BAAI/bge-m3"
+            return [[0.1, 0.2]]
+
+    class FakeQdrant:
+        def __init__(self):
+            self.calls = []
+
+        def query_points(self, collection_name, using, **kwargs):
+            self.calls.append(("query", collection_name, using))
+            points = [SimpleNamespace(id="a", score=0.9, payload=None), SimpleNamespace(id="gone", score=0.5, payload=None)]
+            return SimpleNamespace(points=points)
+
+        def retrieve(self, collection_name, ids, with_payload):
+            self.calls.append(("retrieve", collection_name, None))
+            return [SimpleNamespace(id="a", payload={"text": "def a(): ...", "full_path": "a.py"})]
+
+    import src.services.inference as inference
+    monkeypatch.setattr(inference, "get_inference_client", lambda: FakeClient())
+    qdrant = FakeQdrant()
+    target = resolve_embedding_model("backend", "baai-bge-m3")
+    results = asyncio.run(
+        MultiRetriever()._search_dense_model("q", qdrant, 10, None, "rice_chunks", target)
+    )
+    assert [r["chunk_id"] for r in results] == ["a"]
+    assert results[0]["full_path"] == "a.py"
+    assert qdrant.calls == [("query", target["collection"], "dense_b"), ("retrieve", "rice_chunks", None)]
@@ -0,0 +1,43 @@
+"""
+Unit tests for docs content language detection and filtering.
+"""
+import pytest
+from src.services.ingestion.natural_language import detect_natural_language, is_docs_chunk
+from src.services.search.filters import SearchFilters
+
+
+@pytest.mark.unit
+class TestNaturalLanguage:
+    """Test natural language detection."""
+
+    def test_latin_languages(self):
+        assert detect_natural_language(
+            "This guide explains how to configure the server and what you can do when it fails to start.", 40
+        ) == "en"
+        assert detect_natural_language(
+            "Diese Anleitung erklärt, wie der Server konfiguriert wird und was zu tun ist, wenn er nicht startet.", 40
+        ) == "de"
+
+    def test_scripts(self):
+        assert detect_natural_language("このガイドでは、サーバーの設定方法と起動しない場合の対処法を説明します。", 10) == "ja"
+        assert detect_natural_language("Это руководство объясняет, как настроить сервер и что делать при ошибке.", 10) == "ru"
+
+    def test_code_and_short_text_undetected(self):
+        assert detect_natural_language("```python\nimport the_and_of\n```\nSee `it` here.", 40) is None
+        assert detect_natural_language("Install", 40) is None
+
+    def test_docs_languages(self):
+        assert is_docs_chunk("markdown")
+        assert is_docs_chunk("restructuredtext")
+        assert not is_docs_chunk("python")
+        assert not is_docs_chunk(None)
+
+    def test_filter_matches_content_language(self):
+        filters = SearchFilters(content_languages=["DE", " "])
+
+        assert filters.content_languages == ["de"]
+        assert filters.matches({"full_path": "docs/install.md", "content_language": "de"})
+        assert not filters.matches({"full_path": "docs/install.md", "content_language": "en"})
+        # Code chunks carry no content language
+        assert not filters.matches({"full_path": "src/main.py"})
+        assert filters.to_dict() == {"content_languages": ["de"]}
@@ -0,0 +1,54 @@
+"""
+Tests for Jupyter notebook chunking.
+"""
+import json
+
+import pytest
+
+from src.services.ingestion.language import detect_language
+from src.services.ingestion.notebook import NotebookError, chunk_notebook, is_notebook
+
+
+def _notebook(cells, metadata=None):
+    return json.dumps({"nbformat": 4, "metadata": metadata or {}, "cells": cells})
+
+
+def test_cells_become_chunks_with_kernel_language():
+    text = _notebook(
+        [
+            {"cell_type": "markdown", "source": ["# Load data\n", "Read the CSV."]},
+            {"cell_type": "code", "source": "df = read_csv('x.csv')",
+             "outputs": [{"data": {"image/png": "iVBORw0KGgo="}}]},
+            {"cell_type": "raw", "source": "ignored"},
+            {"cell_type": "code", "source": ["   \n"]},
+            {"cell_type": "code", "source": ["summary(df)"]},
+        ],
+        metadata={"kernelspec": {"name": "ir", "language": "R"}, "language_info": {"name": "R"}},
+    )
+    chunks = chunk_notebook(text, {"file_path": "analysis.ipynb", "language": "jupyter"})
+    assert [(c["metadata"]["cell_index"], c["metadata"]["language"], c["content"]) for c in chunks] == [
+        (0, "markdown", "# Load data\nRead the CSV."),
+        (1, "r", "df = read_csv('x.csv')"),
+        (4, "r", "summary(df)"),
+    ]
+    assert chunks[1]["metadata"]["chunk_type"] == "code_cell"
+    assert chunks[1]["metadata"]["file_path"] == "analysis.ipynb"
+    assert "iVBOR" not in "".join(c["content"] for c in chunks)
+    assert [c["chunk_index"] for c in chunks] == [0, 1, 2]
+
+
+def test_kernel_fallbacks_and_detection():
+    chunks = chunk_notebook(_notebook([{"cell_type": "code", "source": "1 + 1"}]), {})
+    assert chunks[0]["metadata"]["language"] == "python"
+    chunks = chunk_notebook(_notebook([{"cell_type": "code", "source": "1 + 1"}],
+                                      {"kernelspec": {"language": "julia"}}), {})
+    assert chunks[0]["metadata"]["notebook_language"] == "julia"
+    assert detect_language("notebooks/Analysis.ipynb") == "jupyter"
+    assert is_notebook("notebooks/Analysis.IPYNB", "json") and not is_notebook("a.json", "json")
+
+
+def test_invalid_notebooks_raise():
+    with pytest.raises(NotebookError):
+        chunk_notebook("{not json", {})
+    with pytest.raises(NotebookError):
+        chunk_notebook(json.dumps({"worksheets": []}), {})
@@ -0,0 +1,75 @@
+"""
+Unit tests for the OpenAI-compatible embeddings helpers.
+"""
+import base64
+import struct
+import pytest
+
+
+@pytest.mark.unit
+class TestEmbeddingHelpers:
+    """Test input handling and vector shaping."""
+
+    def test_normalize_input(self):
+        """Test string and list inputs are accepted."""
+        from src.services.inference.openai_compat import normalize_input
+
+        assert normalize_input("hello") == ["hello"]
+        assert normalize_input(["a", "b"]) == ["a", "b"]
+
+    def test_normalize_input_rejects_tokens_and_empty(self):
+        """Test token arrays and empty input are rejected."""
+        from src.services.inference.openai_compat import normalize_input
+
+        with pytest.raises(ValueError):
+            normalize_input([1, 2, 3])
+        with pytest.raises(ValueError):
+            normalize_input([])
+        with pytest.raises(ValueError):
+            normalize_input(["ok", ""])
+
+    def test_make_batches(self):
+        """Test batching preserves order."""
+        from src.services.inference.openai_compat import make_batches
+
+        assert make_batches(["a", "b", "c", "d", "e"], 2) == [["a", "b"], ["c", "d"], ["e"]]
+
+    def test_truncate_dimensions_renormalizes(self):
+        """Test shortened vectors are unit length."""
+        from src.services.inference.openai_compat import truncate_dimensions
+
+        vec = truncate_dimensions([3.0, 4.0, 12.0], 2)
+        assert vec == pytest.approx([0.6, 0.8])
+        assert truncate_dimensions([1.0, 2.0], None) == [1.0, 2.0]
+
+    def test_encode_base64(self):
+        """Test base64 encoding matches float32 little-endian."""
+        from src.services.inference.openai_compat import encode_base64
+
+        raw = base64.b64decode(encode_base64([1.0, -2.5]))
+        assert struct.unpack("<2f", raw) == (1.0, -2.5)
+
+
+@pytest.mark.unit
+class TestCreateEmbeddings:
+    """Test batched embedding and usage reporting."""
+
+    def test_usage_and_indices(self, monkeypatch):
+        """Test indices span batches and tokens are summed."""
+        import asyncio
+        from src.services.inference import openai_compat
+
+        class FakeClient:
+            async def embed_with_usage(self, texts, model=None):
+                return {"embeddings": [[float(len(t))] for t in texts], "prompt_tokens": 10 * len(texts)}
+
+        values = {"embeddings_api.batch_size": 2}
+        monkeypatch.setattr(openai_compat.settings, "get", lambda key, default=None: values.get(key, default))
+        monkeypatch.setattr("src.services.inference.get_inference_client", lambda: FakeClient())
+
+        response = asyncio.run(openai_compat.create_embeddings(["a", "bb", "ccc"], model="m"))
+
+        assert [d["index"] for d in response["data"]] == [0, 1, 2]
+        assert [d["embedding"] for d in response["data"]] == [[1.0], [2.0], [3.0]]
+        assert response["usage"] == {"prompt_tokens": 30, "total_tokens": 30}
+        assert response["model"] == "m"
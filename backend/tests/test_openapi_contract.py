@@ -0,0 +1,106 @@
+"""
+Tests for the OpenAPI document and response contract validation.
+"""
+import pytest
+
+from src.api.openapi import ERROR_SCHEMA, find_operation, validate, validate_response
+
+SPEC = {
+    "paths": {
+        "/api/v1/stores/{store_id}": {
+            "get": {
+                "responses": {
+                    "200": {"content": {"application/json": {"schema": {"$ref": "#/components/schemas/Store"}}}},
+                    "default": {"content": {"application/json": {"schema": {"$ref": "#/components/schemas/ErrorResponse"}}}},
+                },
+            },
+        },
+    },
+    "components": {
+        "schemas": {
+            "ErrorResponse": ERROR_SCHEMA,
+            "Store": {
+                "type": "object",
+                "properties": {
+                    "id": {"type": "string"},
+                    "doc_count": {"anyOf": [{"type": "integer"}, {"type": "null"}]},
+                    "tags": {"type": "array", "items": {"type": "string"}},
+                    "type": {"type": "string", "enum": ["production", "staging", "dev"]},
+                },
+                "required": ["id"],
+            },
+        },
+    },
+}
+
+
+def test_find_operation_matches_path_templates():
+    assert find_operation(SPEC, "GET", "/api/v1/stores/backend") is not None
+    assert find_operation(SPEC, "DELETE", "/api/v1/stores/backend") is None
+    assert find_operation(SPEC, "GET", "/api/v1/stores/backend/stats") is None
+
+
+def test_valid_responses_pass():
+    body = {"id": "backend", "doc_count": None, "tags": ["a"], "type": "dev", "extra": 1}
+    assert validate_response(SPEC, "get", "/api/v1/stores/backend", 200, body) == []
+    # Errors are checked against the default response
+    assert validate_response(SPEC, "get", "/api/v1/stores/missing", 404, {"detail": "Store not found", "code": "not_found"}) == []
+
+
+def test_drifted_responses_are_reported():
+    errors = validate_response(
+        SPEC, "get", "/api/v1/stores/backend", 200,
+        {"doc_count": "12", "tags": ["a", 3], "type": "prod"},
+    )
+    assert "$: missing required 'id'" in errors
+    assert any(e.startswith("$.doc_count") for e in errors)
+    assert any(e.startswith("$.tags[1]") for e in errors)
+    assert any(e.startswith("$.type") for e in errors)
+
+    assert validate_response(SPEC, "get", "/api/v1/unknown", 200, {}) == [
+        "GET /api/v1/unknown is not in the OpenAPI document"
+    ]
+    # Booleans are not integers
+    assert validate(SPEC, {"type": "integer"}, True)
+
+
+@pytest.fixture
+def api(monkeypatch):
+    pytest.importorskip("fastapi")
+    from fastapi.testclient import TestClient
+    from src.main import app
+
+    app.openapi_schema = None
+    return app, TestClient(app)
+
+
+def test_spec_is_served_with_auth_and_error_schemas(api):
+    from src.api.versioning import current_version
+
+    _, client = api
+    spec = client.get("/api/v1/openapi.json").json()
+    assert spec["openapi"].startswith("3.")
+    assert spec["info"]["version"] == current_version()
+    assert set(spec["components"]["securitySchemes"]) == {"bearerAuth", "userId"}
+    assert "results" in spec["components"]["schemas"]["SearchQueryResponse"]["properties"]
+
+    docs = client.get("/api/v1/docs")
+    assert docs.status_code == 200 and "swagger-ui" in docs.text
+
+
+def test_every_route_is_documented(api):
+    app, client = api
+    spec = client.get("/api/v1/openapi.json").json()
+    for route in app.routes:
+        if not getattr(route, "include_in_schema", False) or not hasattr(route, "methods"):
+            continue
+        for method in route.methods - {"HEAD", "OPTIONS"}:
+            assert find_operation(spec, method, route.path), f"{method} {route.path}"
+
+
+def test_changes_responses_match_the_spec(api):
+    _, client = api
+    spec = client.get("/api/v1/openapi.json").json()
+    for url in ("/api/v1/changes", "/api/v1/changes?since=1.0", "/api/v1/changes?since=x"):
+        resp = client.get(url)
+        assert validate_response(spec, "get", url.split("?")[0], resp.status_code, resp.json()) == []
@@ -0,0 +1,55 @@
+"""
+Tests for store privacy mode (no content stored).
+"""
+from src.services.ingestion import privacy
+from src.services.ingestion.privacy import is_private_store, purge_store_content, strip_content
+
+
+class FakeAdminStore:
+    def __init__(self, stores):
+        self.stores = stores
+
+    def get_stores(self):
+        return self.stores
+
+
+class FakeQdrant:
+    def __init__(self):
+        self.calls = []
+
+    def delete_payload(self, collection_name, keys, points):
+        self.calls.append(("delete", collection_name, keys))
+
+    def set_payload(self, collection_name, payload, points):
+        self.calls.append(("set", collection_name, payload))
+
+
+def _stores(monkeypatch, stores):
+    from src.services.admin import admin_store
+    monkeypatch.setattr(admin_store, "get_admin_store", lambda: FakeAdminStore(stores))
+
+
+def test_is_private_store(monkeypatch):
+    _stores(monkeypatch, {"secret": {"privacy_mode": True}, "docs": {}})
+    assert is_private_store("secret") is True
+    assert is_private_store("docs") is False
+    assert is_private_store("missing") is False
+    assert is_private_store(None) is False
+
+
+def test_strip_content_keeps_locations():
+    payload = {"text": "password = 1", "full_path": "a.py", "start_line": 3, "end_line": 4}
+    assert strip_content(payload) == {"full_path": "a.py", "start_line": 3, "end_line": 4}
+    assert payload["text"] == "password = 1"
+
+
+def test_purge_removes_text_from_both_tiers(monkeypatch):
+    monkeypatch.setattr("src.services.search.tiering.get_cold_collection_name", lambda: "rice_cold")
+    qdrant = FakeQdrant()
+
+    purge_store_content(qdrant, "secret")
+
+    collections = {c[1] for c in qdrant.calls}
+    assert collections == {privacy.settings.COLLECTION_PREFIX, "rice_cold"}
+    assert ("delete", "rice_cold", ["text"]) in qdrant.calls
+    assert ("set", "rice_cold", {"content_stored": False}) in qdrant.calls
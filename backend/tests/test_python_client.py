@@ -0,0 +1,92 @@
+"""
+Tests for the Python client SDK (against a mock transport).
+"""
+import json
+
+import pytest
+
+httpx = pytest.importorskip("httpx")
+
+from src.client import client as client_module
+from src.client import NotFoundError, RateLimitedError, RiceSearchClient
+
+
+def make_client(monkeypatch, handler, **kwargs):
+    monkeypatch.setattr(client_module.time, "sleep", lambda seconds: None)
+    return RiceSearchClient(
+        "http://rice.test", api_key="key-1", transport=httpx.MockTransport(handler), **kwargs
+    )
+
+
+def test_search_returns_typed_results(monkeypatch):
+    seen = []
+
+    def handler(request):
+        seen.append(request)
+        return httpx.Response(200, json={
+            "query": "retry",
+            "query_id": "q-1",
+            "results": [{
+                "chunk_id": "c-1", "score": 0.9, "full_path": "src/retry.py",
+                "text": "def retry():", "start_line": 3, "end_line": 9, "symbols": ["retry"],
+            }],
+        })
+
+    with make_client(monkeypatch, handler) as rice:
+        response = rice.search("retry", store="backend", limit=5, context_lines=2)
+
+    body = json.loads(seen[0].content)
+    assert seen[0].url.path == "/api/v1/search/query"
+    assert seen[0].headers["Authorization"] == "Bearer key-1"
+    assert body == {"query": "retry", "mode": "search", "limit": 5, "store": "backend", "context_lines": 2}
+    assert response.query_id == "q-1"
+    result = response.results[0]
+    assert (result.path, result.start_line, result.symbols) == ("src/retry.py", 3, ["retry"])
+
+
+def test_retries_busy_and_unavailable_responses(monkeypatch):
+    statuses = iter([503, 429, 200])
+
+    def handler(request):
+        status = next(statuses)
+        if status == 200:
+            return httpx.Response(200, json={"status": "queued", "task_id": "t-1", "file": "a.py"})
+        return httpx.Response(status, headers={"Retry-After": "1"}, json={"detail": "busy"})
+
+    with make_client(monkeypatch, handler) as rice:
+        result = rice.request("POST", "/api/v1/ingest/file", data={"org_id": "default"})
+    assert result["task_id"] == "t-1"
+
+
+def test_errors_raise_after_retries(monkeypatch):
+    calls = []
+
+    def handler(request):
+        calls.append(request.url.path)
+        if request.url.path.endswith("/missing"):
+            return httpx.Response(404, json={"detail": "Store not found"})
+        return httpx.Response(429, json={"detail": "Index queue is full"})
+
+    with make_client(monkeypatch, handler, max_retries=2) as rice:
+        with pytest.raises(NotFoundError) as missing:
+            rice.get_store("missing")
+        with pytest.raises(RateLimitedError):
+            rice.list_stores()
+
+    assert missing.value.status == 404 and missing.value.detail == "Store not found"
+    # 404 is not retried; 429 is tried 1 + max_retries times
+    assert calls.count("/api/v1/stores/missing") == 1
+    assert calls.count("/api/v1/stores/") == 3
+
+
+def test_search_stream_yields_events(monkeypatch):
+    events = [
+        {"event": "candidates", "results": []},
+        {"event": "done", "results": [], "degraded": False},
+    ]
+
+    def handler(request):
+        return httpx.Response(200, content="\n".join(json.dumps(e) for e in events) + "\n")
+
+    with make_client(monkeypatch, handler) as rice:
+        assert [e["event"] for e in rice.search_stream("retry")] == ["candidates", "done"]
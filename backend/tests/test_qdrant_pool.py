@@ -0,0 +1,137 @@
+"""
+Tests for the pooled Qdrant store (pools, timeout budgets, retries).
+"""
+import threading
+import pytest
+
+from src.db import qdrant_pool
+from src.db.qdrant_pool import PooledQdrantStore, QdrantPoolExhausted, is_transient
+
+
+class FakeClient:
+    def __init__(self, pool, failures):
+        self.pool = pool
+        self.failures = failures
+        self.calls = []
+
+    def query_points(self, collection_name, **kwargs):
+        self.calls.append(("query_points", collection_name))
+        if self.failures:
+            raise self.failures.pop(0)
+        return "points"
+
+    def create_collection(self, collection_name, **kwargs):
+        self.calls.append(("create_collection", collection_name))
+        if self.failures:
+            raise self.failures.pop(0)
+        return True
+
+
+class UnexpectedResponse(Exception):
+    def __init__(self, status_code):
+        super().__init__(f"HTTP {status_code}")
+        self.status_code = status_code
+
+
+@pytest.fixture
+def configure(monkeypatch):
+    def apply(**values):
+        values = {f"infrastructure.qdrant.{k.replace('__', '.')}": v for k, v in values.items()}
+        monkeypatch.setattr(qdrant_pool.settings, "get", lambda key, default=None: values.get(key, default))
+    apply()
+    return apply
+
+
+def _store(failures=None):
+    clients = []
+    sleeps = []
+
+    def factory(pool):
+        def create():
+            client = FakeClient(pool, failures if failures is not None else [])
+            clients.append(client)
+            return client
+        return create
+
+    return PooledQdrantStore(factory=factory, sleep=sleeps.append), clients, sleeps
+
+
+def test_transient_errors():
+    assert is_transient(ConnectionRefusedError("refused"))
+    assert is_transient(UnexpectedResponse(503))
+    assert not is_transient(UnexpectedResponse(400))
+    assert not is_transient(ValueError("bad filter"))
+
+    wrapped = RuntimeError("request failed")
+    wrapped.source = TimeoutError("read timed out")
+    assert is_transient(wrapped)
+
+
+def test_idempotent_call_retried_with_jittered_backoff(configure):
+    configure(retry__max_attempts=3, retry__backoff_seconds=0.1)
+    store, clients, sleeps = _store([ConnectionResetError("reset"), UnexpectedResponse(502)])
+
+    assert store.query_points("chunks", query=[0.1], limit=5) == "points"
+    assert len(clients[0].calls) == 3
+    assert clients[0].pool == "search"
+    assert len(sleeps) == 2 and 0 <= sleeps[0] <= 0.1 and 0 <= sleeps[1] <= 0.2
+    assert store.stats()["retries"] == {"query_points": 2}
+
+
+def test_permanent_and_non_idempotent_errors_not_retried(configure):
+    store, clients, sleeps = _store([ValueError("bad filter")])
+    with pytest.raises(ValueError):
+        store.query_points("chunks")
+
+    store, clients, sleeps = _store([ConnectionResetError("reset")])
+    with pytest.raises(ConnectionResetError):
+        store.create_collection("chunks", vectors_config={})
+    assert clients[0].pool == "admin"
+    assert len(clients[0].calls) == 1 and sleeps == []
+
+
+def test_retries_stop_at_attempt_limit_and_count_failures(configure):
+    configure(retry__max_attempts=2)
+    store, clients, _ = _store([TimeoutError("slow")] * 5)
+    with pytest.raises(TimeoutError):
+        store.query_points("chunks")
+    assert len(clients[0].calls) == 2
+    assert store.stats()["retry_failures"] == {"query_points": 1}
+    lines = store.prometheus_lines()
+    assert 'rice_search_qdrant_retries_total{operation="query_points"} 1' in lines
+    assert 'rice_search_qdrant_retry_failures_total{operation="query_points"} 1' in lines
+
+
+def test_pool_size_bounds_clients_and_waits(configure):
+    configure(pool__search__size=1, pool__acquire_timeout_seconds=0.05)
+    store, clients, _ = _store()
+    pool = store.pool("search")
+
+    with pool.lease() as client:
+        # A second caller waits, then gives up
+        with pytest.raises(QdrantPoolExhausted):
+            with pool.lease():
+                pass
+        assert pool.stats()["in_use"] == 1
+
+    # A caller that waits less than acquire_timeout gets the released client
+    held, release = threading.Event(), threading.Event()
+
+    def hold():
+        with pool.lease():
+            held.set()
+            release.wait(1)
+
+    holder = threading.Thread(target=hold)
+    holder.start()
+    held.wait(1)
+    pool.acquire_timeout = 1
+    threading.Timer(0.01, release.set).start()
+    with pool.lease() as again:
+        assert again is client
+    holder.join()
+
+    stats = pool.stats()
+    assert stats["created"] == 1
+    assert stats["waits_total"] == 2 and stats["exhausted_total"] == 1
+    assert 'rice_search_qdrant_pool_exhausted_total{pool="search"} 1' in store.prometheus_lines()
@@ -0,0 +1,58 @@
+"""
+Tests for model precision selection and benchmark metrics.
+"""
+from src.services.inference import quantization
+from src.services.retrieval.splade_encoder import SparseVector
+
+
+def _settings(monkeypatch, values, registry=None):
+    monkeypatch.setattr(quantization.settings, "get", lambda key, d=None: values.get(key, d))
+    monkeypatch.setattr(quantization, "registry_precision", lambda model_type: registry)
+
+
+def test_configured_precision_prefers_registry(monkeypatch):
+    _settings(monkeypatch, {"models.sparse.precision": "fp16"}, registry="int8")
+    assert quantization.configured_precision("sparse_embedding") == "int8"
+
+
+def test_configured_precision_falls_back_to_settings(monkeypatch):
+    _settings(monkeypatch, {"models.reranker.precision": "fp16"})
+    assert quantization.configured_precision("reranker") == "fp16"
+    assert quantization.configured_precision("sparse_embedding") == "fp32"
+
+
+def test_unknown_precision_uses_fp32(monkeypatch):
+    _settings(monkeypatch, {"models.sparse.precision": "int4"})
+    assert quantization.configured_precision("sparse_embedding") == "fp32"
+
+
+def test_effective_precision_depends_on_device():
+    assert quantization.effective_precision("fp16", "cuda") == "fp16"
+    assert quantization.effective_precision("fp16", "cpu") == "fp32"
+    assert quantization.effective_precision("int8", "cpu") == "int8"
+    assert quantization.effective_precision("int8", "cuda") == "fp16"
+    assert quantization.effective_precision("fp32", "cuda") == "fp32"
+
+
+def test_sparse_similarity():
+    reference = [SparseVector([1, 2], [1.0, 1.0]), SparseVector([3], [2.0])]
+    assert round(quantization.sparse_similarity(reference, reference), 6) == 1.0
+
+    drifted = [SparseVector([1, 2], [1.0, 1.0]), SparseVector([4], [2.0])]
+    assert round(quantization.sparse_similarity(reference, drifted), 6) == 0.5
+
+
+def test_ranking_agreement():
+    assert quantization.ranking_agreement([3.0, 2.0, 1.0], [0.9, 0.5, 0.1]) == 1.0
+    # One of three pairs swapped
+    assert round(quantization.ranking_agreement([3.0, 2.0, 1.0], [0.9, 0.1, 0.5]), 4) == 0.6667
+    assert quantization.ranking_agreement([1.0], [1.0]) == 1.0
+
+
+def test_benchmark_rejects_remote_model_types():
+    try:
+        quantization.benchmark("embedding")
+    except ValueError as e:
+        assert "embedding" in str(e)
+    else:
+        raise AssertionError("expected ValueError")
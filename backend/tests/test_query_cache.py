@@ -0,0 +1,105 @@
+"""
+Tests for the search result cache.
+"""
+from types import SimpleNamespace
+
+import pytest
+
+from src.services.search import query_cache
+from src.services.search.query_cache import QueryCache, cache_key
+
+
+class FakeRedis:
+    def __init__(self):
+        self.generations = {}
+        self.down = False
+
+    def hmget(self, key, *fields):
+        if self.down:
+            raise ConnectionError("redis down")
+        return [self.generations.get(f) for f in fields]
+
+    def hincrby(self, key, field, amount):
+        self.generations[field] = self.generations.get(field, 0) + amount
+
+
+@pytest.fixture
+def cache(monkeypatch):
+    config = {"search.cache.ttl_seconds": 60, "search.cache.max_entries": 2}
+    monkeypatch.setattr(query_cache.settings, "get", lambda key, default=None: config.get(key, default))
+    clock = SimpleNamespace(now=1000.0)
+    monkeypatch.setattr(query_cache, "time", SimpleNamespace(monotonic=lambda: clock.now))
+    cache = QueryCache(redis_client=FakeRedis())
+    cache.clock = clock
+    return cache
+
+
+def _put(cache, store, query, results):
+    key = cache_key(store, query)
+    cache.put(key, results, cache.generations(store))
+    return key
+
+
+def test_keys_ignore_whitespace_but_not_filters():
+    assert cache_key("docs", "retry  backoff ") == cache_key("docs", "retry backoff")
+    assert cache_key("docs", "retry", {"paths": ["a"]}) != cache_key("docs", "retry")
+    assert cache_key("docs", "retry", limit=5) != cache_key("docs", "retry", limit=10)
+
+
+def test_writes_to_a_store_invalidate_only_that_store(cache):
+    docs = _put(cache, "docs", "retry", [{"id": 1}])
+    backend = _put(cache, "backend", "retry", [{"id": 2}])
+
+    cache.invalidate("docs")
+    assert cache.get(docs, cache.generations("docs")) is None
+    assert cache.get(backend, cache.generations("backend")) == [{"id": 2}]
+
+    # Unscoped deletes move the global generation: every store misses
+    cache.invalidate()
+    assert cache.get(backend, cache.generations("backend")) is None
+
+
+def test_a_write_during_the_search_invalidates_its_entry(cache):
+    key = cache_key("docs", "retry")
+    generations = cache.generations("docs")
+    cache.invalidate("docs")
+    cache.put(key, [{"id": 1}], generations)
+    assert cache.get(key, cache.generations("docs")) is None
+
+
+def test_entries_expire_after_the_ttl(cache):
+    key = _put(cache, "docs", "retry", [{"id": 1}])
+    cache.clock.now += 59
+    assert cache.get(key, cache.generations("docs")) == [{"id": 1}]
+    cache.clock.now += 2
+    assert cache.get(key, cache.generations("docs")) is None
+    assert cache.get_stats()["entries"] == 0
+
+
+def test_least_recently_used_entry_is_evicted(cache):
+    first = _put(cache, "docs", "first", [{"id": 1}])
+    second = _put(cache, "docs", "second", [{"id": 2}])
+    # Reading "first" makes "second" the least recently used
+    assert cache.get(first, cache.generations("docs"))
+    third = _put(cache, "docs", "third", [{"id": 3}])
+
+    assert cache.get(second, cache.generations("docs")) is None
+    assert cache.get(first, cache.generations("docs")) == [{"id": 1}]
+    assert cache.get(third, cache.generations("docs")) == [{"id": 3}]
+    assert cache.get_stats()["entries"] == 2
+
+
+def test_cached_results_are_copies(cache):
+    key = _put(cache, "docs", "retry", [{"id": 1}])
+    cache.get(key, cache.generations("docs"))[0]["score"] = 0.5
+    assert cache.get(key, cache.generations("docs")) == [{"id": 1}]
+
+
+def test_redis_outage_bypasses_the_cache(cache):
+    key = _put(cache, "docs", "retry", [{"id": 1}])
+    cache.redis.down = True
+    assert cache.generations("docs") is None
+    assert cache.get(key, cache.generations("docs")) is None
+    cache.put(key, [{"id": 2}], cache.generations("docs"))
+    cache.redis.down = False
+    assert cache.get(key, cache.generations("docs")) == [{"id": 1}]
@@ -0,0 +1,130 @@
+"""
+Unit tests for query understanding LLM backends and fallback chain.
+"""
+import asyncio
+import pytest
+
+
+class _Backend:
+    """Fake backend returning a fixed answer, raising, or hanging."""
+
+    def __init__(self, name, answer=None, error=None, delay=0):
+        self.name = name
+        self.url = f"http://{name}"
+        self.model = "test"
+        self.answer = answer
+        self.error = error
+        self.delay = delay
+        self.calls = 0
+
+    async def complete(self, prompt, max_tokens, temperature, timeout):
+        self.calls += 1
+        if self.delay:
+            await asyncio.sleep(self.delay)
+        if self.error:
+            raise self.error
+        return self.answer
+
+
+@pytest.mark.unit
+class TestQueryLLMChain:
+    """Test backend fallback order and timeouts."""
+
+    def test_first_backend_answers(self):
+        """The first healthy backend wins and later ones are not called."""
+        from src.services.query.llm_backends import QueryLLM
+
+        first, second = _Backend("a", answer="debug"), _Backend("b", answer="lookup")
+        llm = QueryLLM(backends=[first, second])
+
+        assert asyncio.run(llm.complete("q", max_tokens=5, temperature=0, timeout=1)) == "debug"
+        assert second.calls == 0
+
+    def test_falls_back_on_error_and_timeout(self):
+        """Failing and slow backends are skipped in order."""
+        from src.services.query.llm_backends import QueryLLM
+
+        broken = _Backend("broken", error=RuntimeError("down"))
+        slow = _Backend("slow", answer="late", delay=1)
+        good = _Backend("good", answer="example")
+        llm = QueryLLM(backends=[broken, slow, good])
+
+        assert asyncio.run(llm.complete("q", max_tokens=5, temperature=0, timeout=0.05)) == "example"
+
+    def test_all_backends_fail(self):
+        """None signals the caller to use heuristics."""
+        from src.services.query.llm_backends import QueryLLM
+
+        llm = QueryLLM(backends=[_Backend("a", error=RuntimeError("down"))])
+
+        assert asyncio.run(llm.complete("q", max_tokens=5, temperature=0, timeout=1)) is None
+
+
+@pytest.mark.unit
+class TestQueryExpansion:
+    """Test parsing of LLM expansion output."""
+
+    def test_expansion_terms_are_cleaned(self, monkeypatch):
+        """Terms already in the query and duplicates are dropped."""
+        from src.services.query import llm_backends
+        from src.services.search import query_analyzer
+
+        llm = llm_backends.QueryLLM(backends=[_Backend("a", answer="- load_model,\n`auth`, loadModel, load_model")])
+        monkeypatch.setattr(llm_backends, "get_query_llm", lambda: llm)
+
+        assert query_analyzer.expand_with_llm("auth handler") == ["load_model", "loadModel"]
+
+
+@pytest.mark.unit
+class TestQueryPath:
+    """Test which path (model or heuristic) serves an analysis."""
+
+    def _analyzer(self, monkeypatch, backend, enabled=True):
+        from src.core import config
+        from src.services.query import llm_backends
+        from src.services.search import query_analyzer
+
+        llm = llm_backends.QueryLLM(backends=[backend])
+        monkeypatch.setattr(llm_backends, "get_query_llm", lambda: llm)
+        monkeypatch.setattr(query_analyzer, "query_model_enabled", lambda: enabled)
+        monkeypatch.setattr(config.settings, "get", lambda key, default=None: default)
+        return query_analyzer
+
+    def test_model_path(self, monkeypatch):
+        """A low-confidence query classified by the model is on the model path."""
+        analyzer = self._analyzer(monkeypatch, _Backend("a", answer="debug"))
+
+        analysis = analyzer.analyze_query("token refresh", use_llm=True)
+
+        assert (analysis.path, analysis.fallback_reason) == ("model", None)
+        assert analysis.to_metadata()["intent"] == "debug"
+
+    def test_unavailable_model_falls_back(self, monkeypatch):
+        """A failing backend leaves the heuristic result, with the reason."""
+        analyzer = self._analyzer(monkeypatch, _Backend("a", error=RuntimeError("down")))
+
+        analysis = analyzer.analyze_query("token refresh", use_llm=True)
+
+        assert (analysis.path, analysis.fallback_reason) == ("heuristic", "unavailable")
+        assert analysis.intent.value == "lookup"
+
+    def test_forced_and_disabled_skip_the_model(self, monkeypatch):
+        """Forcing the heuristic path or disabling the model never calls a backend."""
+        backend = _Backend("a", answer="debug")
+        analyzer = self._analyzer(monkeypatch, backend)
+        assert analyzer.analyze_query("token refresh", use_llm=True, force_heuristic=True).fallback_reason == "forced"
+
+        analyzer = self._analyzer(monkeypatch, backend, enabled=False)
+        assert analyzer.analyze_query("token refresh", use_llm=True).fallback_reason == "disabled"
+        assert analyzer.analyze_query("token refresh").fallback_reason == "not_configured"
+        assert backend.calls == 0
+
+    def test_confident_patterns_skip_the_model(self, monkeypatch):
+        """Confident pattern matches don't need the model."""
+        backend = _Backend("a", answer="lookup")
+        analyzer = self._analyzer(monkeypatch, backend)
+
+        analysis = analyzer.analyze_query("how does auth work", use_llm=True)
+
+        assert (analysis.path, analysis.fallback_reason) == ("heuristic", "confident")
+        assert backend.calls == 0
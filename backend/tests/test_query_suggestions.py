@@ -0,0 +1,66 @@
+"""Tests for spelling correction and autocomplete from store vocabularies."""
+
+from types import SimpleNamespace
+
+import pytest
+
+from src.services.search.suggestions import QuerySuggester, chunk_terms, edit_distance
+
+
+CHUNKS = [
+    {"text": "def authenticate(user): check authentication token", "symbols": ["authenticate"], "filename": "auth.py"},
+    {"text": "authentication middleware for requests", "symbols": ["AuthMiddleware"], "filename": "middleware.py"},
+    {"text": "the authentication flow and middleware", "filename": "README.md"},
+]
+
+
+@pytest.fixture
+def suggester(fake_redis, fake_qdrant):
+    fake_qdrant.collections["rice_chunks"] = [SimpleNamespace(payload={"org_id": "backend", **c}) for c in CHUNKS]
+    suggester = QuerySuggester(redis_client=fake_redis, qdrant_client=fake_qdrant)
+    suggester.add("backend", CHUNKS)
+    return suggester
+
+
+def test_chunk_terms_split_identifiers():
+    terms = chunk_terms({"text": "HandleFunc(w)", "symbols": ["Server.ListenAndServe"], "filename": "server.go"})
+    assert {"handlefunc", "handle", "func", "listenandserve", "listen", "serve", "server"} <= set(terms)
+    assert "w" not in terms and "go" not in terms
+
+
+def test_edit_distance_counts_swaps_as_one_edit():
+    assert edit_distance("authetnication", "authentication") == 1
+    assert edit_distance("midleware", "middleware") == 1
+    assert edit_distance("flow", "flows") == 1
+
+
+def test_correct_replaces_unknown_words_and_keeps_filters(suggester):
+    result = suggester.correct("backend", "Authetnication midleware lang:go -vendor flow")
+    assert result["suggestion"] == "Authentication middleware lang:go -vendor flow"
+    assert [c["word"] for c in result["corrections"]] == ["Authetnication", "midleware"]
+
+    assert suggester.correct("backend", "authentication flow")["suggestion"] is None
+    # Nothing close enough, or too rare to trust
+    assert suggester.correct("backend", "zebra authenticte")["suggestion"] is None
+
+
+def test_complete_prefers_common_terms(suggester):
+    completions = suggester.complete("backend", "Auth")
+    assert completions[0] == {"text": "authentication", "kind": "term", "count": 3}
+    assert {"auth", "authenticate", "authmiddleware"} <= {c["text"] for c in completions}
+    assert suggester.complete("backend", "a") == []
+
+
+def test_rebuild_recounts_and_drop_forgets(suggester):
+    assert suggester.vocabulary("backend").counts["authentication"] == 3
+    suggester.add("backend", CHUNKS)
+    assert suggester.vocabulary("backend").counts["authentication"] == 3
+    suggester.invalidate("backend")
+    assert suggester.vocabulary("backend").counts["authentication"] == 6
+
+    result = suggester.rebuild("backend")
+    assert result["chunks"] == 3 and not result["truncated"]
+    assert suggester.vocabulary("backend").counts["authentication"] == 3
+
+    suggester.drop("backend")
+    assert len(suggester.vocabulary("backend")) == 0
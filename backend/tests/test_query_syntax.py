@@ -0,0 +1,69 @@
+"""Tests for the inline query syntax."""
+
+from src.services.search.filters import SearchFilters, build_filter
+from src.services.search.query_syntax import parse, path_globs
+
+
+def test_parse_compiles_filters_and_keeps_semantic_text():
+    parsed = parse('lang:go path:cmd/ symbol:NewServer "listen address" -vendor retry')
+    assert parsed.text == "listen address retry"
+    filters = parsed.filters
+    assert filters.languages == ["go"]
+    assert filters.include_paths == ["cmd/**"]
+    assert filters.symbols == ["NewServer"]
+    assert filters.phrases == ["listen address"]
+    assert filters.exclude_terms == ["vendor"]
+    assert [t["field"] for t in parsed.tokens] == ["language", "path", "symbol", "phrase", "exclude"]
+    assert parsed.to_dict()["filters"]["include_paths"] == ["cmd/**"]
+
+
+def test_parse_leaves_unknown_keys_and_flags_in_text():
+    parsed = parse("std::vector http://example.com --verbose a - b -1")
+    assert parsed.text == "std::vector http://example.com --verbose a - b -1"
+    assert parsed.filters.is_empty()
+    assert parsed.tokens == []
+
+
+def test_parse_normalizes_values_and_merges_request_filters():
+    parsed = parse('lang:py ext:.GO -ext:pb.go path:"my docs/" -lang:go x', SearchFilters(extensions=["go"]))
+    assert parsed.filters.languages == ["python"]
+    assert parsed.filters.extensions == [".go"]
+    assert parsed.filters.exclude_paths == ["*.pb.go"]
+    assert parsed.filters.include_paths == ["my docs/**"]
+    assert parsed.warnings == ["-lang:go: excluding by language is not supported"]
+    assert path_globs("cmd/api") == ["cmd/api", "cmd/api/**"]
+    assert path_globs("**/*.go") == ["**/*.go"]
+
+
+def test_filters_only_query_falls_back_to_filter_values():
+    assert parse("lang:go path:cmd/").text == "go cmd"
+    assert parse('"exact phrase" -vendor').text == "exact phrase"
+
+
+def test_phrases_and_excluded_terms_match_payloads():
+    filters = parse('"Retry Budget" -vendor').filters
+    assert filters.matches({"full_path": "src/retry.go", "text": "the retry budget is spent"})
+    assert not filters.matches({"full_path": "src/retry.go", "text": "budget"})
+    assert not filters.matches({"full_path": "vendor/x/retry.go", "text": "retry budget"})
+    # Whole words only, and chunks without any text miss the phrase
+    assert filters.matches({"full_path": "src/vendorid.go", "text": "retry budget vendorID"})
+    assert not filters.matches({"full_path": "src/retry.go", "content_ref": "abc"})
+    assert not filters.matches({"full_path": "src/retry.go"})
+
+
+def test_phrases_and_excluded_terms_read_the_content_store(tmp_path):
+    from unittest.mock import patch
+    from src.db import content_store
+
+    store = content_store.ContentStore(root=str(tmp_path))
+    matching = {"full_path": "src/retry.go", "content_ref": store.put("the retry budget is spent")}
+    excluded = {"full_path": "src/retry.go", "content_ref": store.put("retry budget from the vendor tree")}
+    filters = parse('"Retry Budget" -vendor').filters
+    with patch.object(content_store, "_content_store", store):
+        assert filters.matches(matching)
+        assert not filters.matches(excluded)
+        # Exclusions alone also check the referenced text
+        assert not parse("-vendor").filters.matches(excluded)
+
+    f = build_filter("acme", filters)
+    assert [c.key for c in f.must_not] == ["path_dirs"]
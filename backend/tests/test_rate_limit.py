@@ -0,0 +1,68 @@
+"""
+Tests for the fixed-window rate limiter.
+"""
+from types import SimpleNamespace
+
+from src.services.admin import rate_limit
+from src.services.admin.rate_limit import RateLimiter
+
+
+class FakeRedis:
+    def __init__(self):
+        self.counts = {}
+        self.expiries = {}
+        self.down = False
+        self.ops = []
+
+    def pipeline(self):
+        self.ops = []
+        return self
+
+    def incr(self, key):
+        self.ops.append(("incr", key))
+
+    def expire(self, key, seconds):
+        self.ops.append(("expire", key, seconds))
+
+    def execute(self):
+        if self.down:
+            raise ConnectionError("redis down")
+        results = []
+        for op in self.ops:
+            if op[0] == "incr":
+                self.counts[op[1]] = self.counts.get(op[1], 0) + 1
+                results.append(self.counts[op[1]])
+            else:
+                self.expiries[op[1]] = op[2]
+                results.append(True)
+        return results
+
+
+def _limiter(monkeypatch, now):
+    clock = SimpleNamespace(now=now)
+    monkeypatch.setattr(rate_limit, "time", SimpleNamespace(time=lambda: clock.now))
+    return RateLimiter(redis_client=FakeRedis()), clock
+
+
+def test_limits_hits_per_key_and_window(monkeypatch):
+    limiter, clock = _limiter(monkeypatch, 1000.0)
+
+    assert limiter.hit("export:alice", 2, 60) == (True, 21)
+    assert limiter.hit("export:alice", 2, 60)[0] is True
+    assert limiter.hit("export:alice", 2, 60) == (False, 21)
+    # Keys count separately
+    assert limiter.hit("export:bob", 2, 60)[0] is True
+    assert limiter.redis.expiries == {"rice:ratelimit:export:alice:16": 60, "rice:ratelimit:export:bob:16": 60}
+
+    # The next window starts from zero
+    clock.now = 1020.0
+    assert limiter.hit("export:alice", 2, 60) == (True, 61)
+
+
+def test_zero_limit_and_redis_errors_allow(monkeypatch):
+    limiter, _ = _limiter(monkeypatch, 1000.0)
+    assert limiter.hit("export:alice", 0, 60) == (True, 0)
+    assert limiter.redis.counts == {}
+
+    limiter.redis.down = True
+    assert limiter.hit("export:alice", 1, 60) == (True, 0)
@@ -54,6 +54,17 @@ class TestRBACEndpoints:
         finally:
              del app.dependency_overrides[get_current_user]
     
+    def test_viewer_cannot_list_or_read_jobs(self, api_client):
+        """Job arguments and results are admin-only."""
+        app.dependency_overrides[get_current_user] = lambda: {
+            "id": "viewer-1", "role": "viewer", "active": True
+        }
+        try:
+            assert api_client.get("/api/v1/admin/public/jobs").status_code == 403
+            assert api_client.get("/api/v1/admin/public/jobs/some-task").status_code == 403
+        finally:
+             del app.dependency_overrides[get_current_user]
+
     def test_unauthenticated_cannot_access_admin(self, api_client):
         """Test unauthenticated user cannot access admin endpoints."""
         response = api_client.put(
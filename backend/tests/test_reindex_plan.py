@@ -0,0 +1,97 @@
+"""
+Tests for reindex planning and applying plans.
+"""
+import asyncio
+from types import SimpleNamespace
+
+import pytest
+from fastapi import HTTPException
+
+from src.services.ingestion import reindex_plan
+from src.services.ingestion.hashing import HASH_VERSION
+from src.services.ingestion.reindex_plan import ReindexPlanner
+
+
+class FakeIndexer:
+    def __init__(self):
+        self.deleted = []
+
+    def delete_file(self, path, org_id):
+        self.deleted.append((org_id, path))
+        return 2
+
+
+def _chunk(path, file_hash="h", hash_version=HASH_VERSION, store="backend"):
+    return {"org_id": store, "full_path": path, "file_hash": file_hash, "hash_version": hash_version}
+
+
+@pytest.fixture
+def planner(monkeypatch, fake_qdrant):
+    payloads = [
+        _chunk("/a.py"), _chunk("/a.py"),
+        _chunk("/b.py", file_hash="old"),
+        _chunk("/c.py", hash_version=None),
+        _chunk("/gone.py"), _chunk("/gone.py"),
+        _chunk("/other.py", store="docs"),
+    ]
+    fake_qdrant.collections["rice_chunks"] = [SimpleNamespace(id=i, payload=p) for i, p in enumerate(payloads)]
+    planner = ReindexPlanner(qdrant_client=fake_qdrant)
+    monkeypatch.setattr(planner, "collections", lambda org_id=None: ["rice_chunks"])
+    return planner
+
+
+def test_plan_diffs_hashes_against_the_store(planner):
+    plan = planner.plan("backend", [
+        {"path": "/a.py", "hash": "h"},
+        {"path": "/b.py", "hash": "new"},
+        {"path": "/c.py", "hash": "h"},
+        {"path": "/new.py", "hash": "n", "size": 2500},
+    ])
+    assert plan["files"] == {"added": 1, "removed": 1, "changed": 2, "unchanged": 1}
+    assert plan["chunks"] == {"removed": 2, "replaced": 2, "unchanged": 2, "added_estimate": 3}
+    assert plan["paths"] == {"added": ["/new.py"], "removed": ["/gone.py"], "changed": ["/b.py", "/c.py"]}
+    assert plan["noop"] is False
+
+
+def test_unchanged_file_list_is_a_noop(planner):
+    plan = planner.plan("docs", [{"path": "/other.py", "hash": "h"}])
+    assert plan["noop"] is True
+
+
+def test_apply_drops_removed_files(planner):
+    indexer = FakeIndexer()
+    plan = planner.plan("backend", [{"path": "/a.py", "hash": "h"}], max_paths=None)
+    assert planner.apply("backend", plan, indexer) == {"files_removed": 3, "chunks_removed": 6}
+    assert sorted(path for _, path in indexer.deleted) == ["/b.py", "/c.py", "/gone.py"]
+
+
+def test_apply_refuses_an_empty_file_list(planner):
+    indexer = FakeIndexer()
+    plan = planner.plan("backend", [], max_paths=None)
+    assert plan["files"]["removed"] == 4
+    with pytest.raises(ValueError, match="file list is empty"):
+        planner.apply("backend", plan, indexer)
+    assert indexer.deleted == []
+
+
+def test_reindex_endpoint_only_deletes_when_asked(planner, monkeypatch):
+    from src.api.v1.endpoints import stores as endpoints
+
+    monkeypatch.setattr(endpoints, "get_admin_store", lambda: SimpleNamespace(
+        get_stores=lambda: {"backend": {}}, log_audit=lambda *args: None
+    ))
+    monkeypatch.setattr(reindex_plan, "get_reindex_planner", lambda: planner)
+    applied = []
+    monkeypatch.setattr(planner, "apply", lambda org_id, plan: applied.append(plan) or {"files_removed": 1, "chunks_removed": 2})
+    files = [endpoints.ReindexFile(path="/a.py", hash="h")]
+
+    kept = asyncio.run(endpoints.reindex_store("backend", endpoints.ReindexRequest(files=files, preview=False)))
+    assert kept["applied"] == {"files_removed": 0, "chunks_removed": 0}
+    assert applied == []
+
+    request = endpoints.ReindexRequest(files=files, preview=False, delete_missing=True)
+    assert asyncio.run(endpoints.reindex_store("backend", request))["applied"]["files_removed"] == 1
+
+    with pytest.raises(HTTPException) as rejected:
+        asyncio.run(endpoints.reindex_store("backend", endpoints.ReindexRequest(files=[], preview=False, delete_missing=True)))
+    assert rejected.value.status_code == 400
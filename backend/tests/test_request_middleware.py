@@ -0,0 +1,135 @@
+"""
+Tests for the request chain: recovery, per-route metrics and rate limiting.
+"""
+import asyncio
+import json
+from types import SimpleNamespace
+
+from src.core import request_middleware
+from src.core.request_middleware import (
+    RateLimitMiddleware, RecoveryMiddleware, RequestMetricsMiddleware, RouteMetrics,
+)
+
+
+def _settings(monkeypatch, **overrides):
+    config = {"server.rate_limit.exempt_paths": ["/health"], **overrides}
+    monkeypatch.setattr(request_middleware.settings, "get", lambda key, default=None: config.get(key, default))
+
+
+def _app(status=200, body=b"ok", fail=None, fail_after_start=False, route="/api/v1/stores/{store_id}"):
+    async def app(scope, receive, send):
+        scope["route"] = SimpleNamespace(path=route)
+        if fail and not fail_after_start:
+            raise fail
+        await send({"type": "http.response.start", "status": status, "headers": []})
+        await send({"type": "http.response.body", "body": body, "more_body": fail is not None})
+        if fail:
+            raise fail
+    return app
+
+
+def _call(app, path="/api/v1/stores/backend", headers=None, client=("10.0.0.1", 5000)):
+    messages = []
+
+    async def receive():
+        return {"type": "http.request", "body": b"", "more_body": False}
+
+    async def send(message):
+        messages.append(message)
+
+    scope = {
+        "type": "http", "method": "GET", "path": path, "client": client,
+        "headers": [(k.lower().encode(), v.encode()) for k, v in (headers or {}).items()],
+    }
+    asyncio.run(app(scope, receive, send))
+    return messages
+
+
+def test_recovery_answers_500_with_error_id():
+    messages = _call(RecoveryMiddleware(_app(fail=RuntimeError("boom"))))
+    assert messages[0]["status"] == 500
+    body = json.loads(messages[1]["body"])
+    assert body["detail"] == "Internal server error" and len(body["error_id"]) == 12
+
+
+def test_recovery_cuts_off_failed_stream():
+    messages = _call(RecoveryMiddleware(_app(body=b'{"type": "candidates"}\n', fail=RuntimeError("boom"), fail_after_start=True)))
+    # Started response is left incomplete, not closed as if it succeeded
+    assert messages[0]["status"] == 200
+    assert messages[-1]["more_body"] is True
+
+
+def test_metrics_by_route_template(monkeypatch):
+    _settings(monkeypatch)
+    metrics = RouteMetrics()
+    monkeypatch.setattr(request_middleware, "get_route_metrics", lambda: metrics)
+
+    _call(RequestMetricsMiddleware(_app()), path="/api/v1/stores/a")
+    _call(RequestMetricsMiddleware(_app()), path="/api/v1/stores/b")
+    _call(RequestMetricsMiddleware(RecoveryMiddleware(_app(fail=RuntimeError("boom")))))
+
+    text = "\n".join(metrics.prometheus_lines())
+    labels = 'method="GET",route="/api/v1/stores/{store_id}"'
+    assert f"rice_search_http_request_duration_seconds_count{{{labels}}} 3" in text
+    assert f'rice_search_http_request_duration_seconds_bucket{{{labels},le="+Inf"}} 3' in text
+    assert f'rice_search_http_responses_total{{{labels},status="200"}} 2' in text
+    assert f'rice_search_http_responses_total{{{labels},status="500"}} 1' in text
+
+
+def test_rate_limit_per_caller(monkeypatch):
+    from src.services.admin import rate_limit
+
+    _settings(monkeypatch, **{"server.rate_limit.enabled": True, "server.rate_limit.requests": 2})
+    counts = {}
+
+    class FakeLimiter:
+        def hit(self, key, limit, window_seconds):
+            counts[key] = counts.get(key, 0) + 1
+            return counts[key] <= limit, 42
+
+    monkeypatch.setattr(rate_limit, "get_rate_limiter", lambda: FakeLimiter())
+    app = RateLimitMiddleware(_app())
+
+    statuses = [_call(app, headers={"X-User-ID": "alice"})[0]["status"] for _ in range(3)]
+    assert statuses == [200, 200, 429]
+    limited = _call(app, headers={"X-User-ID": "alice"})[0]
+    assert (b"retry-after", b"42") in limited["headers"]
+
+    # Other callers and exempt paths are unaffected
+    assert _call(app, headers={"X-User-ID": "bob"})[0]["status"] == 200
+    assert _call(app, path="/health", headers={"X-User-ID": "alice"})[0]["status"] == 200
+
+    # Without a user ID the client address is the key
+    _call(app)
+    assert counts["api:ip:10.0.0.1"] == 1
+
+
+def test_rate_limit_trusts_only_verified_identities_with_auth_on(monkeypatch):
+    from src.services.admin import rate_limit
+
+    _settings(monkeypatch, **{"server.rate_limit.enabled": True})
+    monkeypatch.setattr(request_middleware.settings, "AUTH_ENABLED", True, raising=False)
+    keys = []
+
+    class FakeLimiter:
+        def hit(self, key, limit, window_seconds):
+            keys.append(key)
+            return True, 0
+
+    async def token_subject(token):
+        return "alice" if token == "valid" else None
+
+    monkeypatch.setattr(rate_limit, "get_rate_limiter", lambda: FakeLimiter())
+    monkeypatch.setattr(request_middleware, "token_subject", token_subject)
+    app = RateLimitMiddleware(_app())
+
+    _call(app, headers={"X-User-ID": "spoofed"})
+    _call(app, headers={"Authorization": "Bearer valid", "X-User-ID": "spoofed"})
+    _call(app, headers={"Authorization": "Bearer forged"}, client=("10.0.0.2", 5000))
+    assert keys == ["api:ip:10.0.0.1", "api:user:alice", "api:ip:10.0.0.2"]
+
+
+def test_rate_limit_disabled_by_default(monkeypatch):
+    _settings(monkeypatch)
+    app = RateLimitMiddleware(_app())
+    assert all(_call(app)[0]["status"] == 200 for _ in range(5))
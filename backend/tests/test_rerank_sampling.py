@@ -0,0 +1,66 @@
+"""
+Tests for rerank candidate sampling above the candidate cap.
+"""
+import asyncio
+
+from src.services.search import rerank_sampling, reranker
+from src.services.search.rerank_sampling import sample_candidates
+
+
+def _result(i, path=None, **scores):
+    return {"id": i, "full_path": path or f"/f{i}.py", "text": f"chunk {i}", "retriever_scores": scores}
+
+
+def _ids(results):
+    return [r["id"] for r in results]
+
+
+def test_under_cap_keeps_everything():
+    results = [_result(i) for i in range(3)]
+    candidates, remainder = sample_candidates(results, cap=5)
+    assert _ids(candidates) == [0, 1, 2] and remainder == []
+    assert sample_candidates(results, cap=0)[1] == []
+
+
+def test_truncate_takes_fused_head():
+    results = [_result(i) for i in range(6)]
+    candidates, remainder = sample_candidates(results, cap=4, strategy="truncate")
+    assert _ids(candidates) == [0, 1, 2, 3]
+    assert _ids(remainder) == [4, 5]
+
+
+def test_smart_keeps_each_retrievers_best():
+    # Fusion ranked the best dense-only and sparse-only hits last
+    results = [_result(i, bm25=1.0 - i / 10) for i in range(6)]
+    results.append(_result(6, bm42=0.9))
+    results.append(_result(7, splade=0.8))
+
+    candidates, remainder = sample_candidates(results, cap=4, strategy="smart", top_per_retriever=1)
+
+    assert {6, 7} <= set(_ids(candidates))
+    assert 0 in _ids(candidates)
+    assert len(candidates) == 4
+    assert len(candidates) + len(remainder) == len(results)
+
+
+def test_smart_tail_prefers_unsampled_files():
+    results = [_result(0, "/a.py", bm25=1.0)]
+    results += [_result(i, "/a.py") for i in range(1, 4)]
+    results += [_result(4, "/b.py"), _result(5, "/c.py")]
+
+    candidates, _ = sample_candidates(results, cap=3, strategy="smart", top_per_retriever=1)
+    assert _ids(candidates) == [0, 4, 5]
+
+
+def test_rerank_appends_unsampled_remainder(monkeypatch):
+    monkeypatch.setattr(rerank_sampling.settings, "get", lambda key, default=None: 2 if key.endswith("max_candidates") else "truncate")
+
+    async def fake_rerank(query, texts):
+        return [0.1 * i for i in range(len(texts))]
+
+    monkeypatch.setattr(reranker, "rerank_results", fake_rerank)
+    results = [_result(i) for i in range(4)]
+
+    ranked = asyncio.run(reranker.rerank_search_results("q", results))
+    assert _ids(ranked) == [1, 0, 2, 3]
+    assert "rerank_score" not in ranked[2]
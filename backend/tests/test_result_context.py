@@ -0,0 +1,105 @@
+"""
+Tests for search result context expansion (context_lines).
+"""
+from types import SimpleNamespace
+from unittest.mock import patch
+
+from src.db import content_store
+from src.db.content_store import ContentStore
+from src.services.search.context import ContextExpander, expand_context, file_header, lines_from_chunks
+
+GO_FILE = """// Package api serves requests.
+package api
+
+import (
+\t"net/http"
+\t"strings"
+)
+
+const prefix = "/v1"
+
+func Handle(w http.ResponseWriter, r *http.Request) {
+\tpath := strings.TrimPrefix(r.URL.Path, prefix)
+\tserve(w, path)
+}
+
+func serve(w http.ResponseWriter, path string) {}
+"""
+
+
+class FakeQdrant:
+    def __init__(self, payloads):
+        self.payloads = payloads
+        self.scrolls = 0
+
+    def scroll(self, **kwargs):
+        self.scrolls += 1
+        return [SimpleNamespace(payload=p) for p in self.payloads], None
+
+
+def test_file_headers_by_language():
+    lines = GO_FILE.split("\n")
+    assert file_header(lines, "go", 200) == {
+        "start_line": 2, "end_line": 7, "text": 'package api\n\nimport (\n\t"net/http"\n\t"strings"\n)',
+    }
+    python = ['"""Doc."""', "import os", "from typing import (", "    List,", ")", "", "X = 1"]
+    assert file_header(python, "python", 200)["end_line"] == 5
+    assert file_header(python, "python", 2)["end_line"] == 2
+    assert file_header(["body"], "python", 200) is None
+    assert file_header(lines, "markdown", 200) is None
+
+
+def test_context_from_stored_file(tmp_path):
+    store = ContentStore(root=str(tmp_path))
+    ref = store.put(GO_FILE)
+    result = {"chunk_id": "c", "full_path": "api/handler.go", "language": "go",
+              "start_line": 11, "end_line": 14, "file_ref": ref, "org_id": "default"}
+    with patch.object(content_store, "_content_store", store):
+        expanded = expand_context([result], "default", 2)
+
+    assert "context" not in result  # results are copied, cached ones stay untouched
+    context = expanded[0]["context"]
+    assert context["before"] == 'const prefix = "/v1"\n' and context["before_start_line"] == 9
+    assert context["after"] == "\nfunc serve(w http.ResponseWriter, path string) {}"
+    assert context["after_end_line"] == 16
+    assert context["header"]["start_line"] == 2 and context["source"] == "file"
+
+
+def test_context_pieced_from_chunks_and_skipped_for_private_results():
+    lines = GO_FILE.split("\n")
+    chunks = [
+        {"start_line": 11, "text": "\n".join(lines[10:14])},
+        {"start_line": 16, "text": lines[15]},
+    ]
+    assert lines_from_chunks(chunks)[:10] == [None] * 10
+
+    qdrant = FakeQdrant(chunks)
+    results = [
+        {"chunk_id": "a", "full_path": "api/handler.go", "language": "go", "start_line": 16, "end_line": 16},
+        {"chunk_id": "b", "full_path": "api/handler.go", "language": "go", "start_line": 11, "end_line": 14},
+        {"chunk_id": "p", "full_path": "secret.go", "start_line": 1, "end_line": 3, "content_stored": False},
+        {"chunk_id": "t", "full_path": "notes.pdf", "start_line": 0, "end_line": 0},
+    ]
+    expanded = ContextExpander(qdrant_client=qdrant).expand(results, "default", 5)
+    assert qdrant.scrolls == 1  # one read per file
+
+    # Line 15 is in no chunk, so context stops there
+    assert expanded[0]["context"]["before"] == "" and expanded[0]["context"]["source"] == "chunks"
+    assert expanded[1]["context"]["after"] == "" and expanded[1]["context"]["header"] is None
+    assert expanded[2]["context"] is None and expanded[3]["context"] is None
+
+
+def test_context_lines_are_capped(monkeypatch):
+    from src.services.search import context as context_module
+
+    seen = {}
+
+    class Recorder(ContextExpander):
+        def expand(self, results, org_id, context_lines):
+            seen["lines"] = context_lines
+            return results
+
+    monkeypatch.setattr(context_module, "ContextExpander", Recorder)
+    expand_context([{"chunk_id": "a"}], "default", 10_000)
+    assert seen["lines"] == 50
+    assert expand_context([{"chunk_id": "a"}], "default", 0) == [{"chunk_id": "a"}]
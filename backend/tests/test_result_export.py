@@ -0,0 +1,96 @@
+"""
+Tests for search result exports (audit records and watermarks).
+"""
+import csv
+import io
+import json
+
+import pytest
+
+from src.services.admin import result_export
+from src.services.admin.result_export import ResultExporter
+
+RESULTS = [
+    {"chunk_id": "c1", "score": 0.9, "full_path": "/repo/auth.py", "start_line": 1, "end_line": 9, "text": "def login()"},
+    {"chunk_id": "c2", "score": 0.5, "full_path": "/repo/session.py", "text": "class Session"},
+]
+
+
+class FakeRedis:
+    def __init__(self):
+        self.data = {}
+
+    def set(self, key, value, ex=None):
+        self.data[key] = value
+
+    def get(self, key):
+        return self.data.get(key)
+
+
+class FakeAdminStore:
+    def __init__(self):
+        self.audit = []
+
+    def log_audit(self, action, details=None, user="system"):
+        self.audit.append((action, details, user))
+
+
+@pytest.fixture
+def exporter(monkeypatch):
+    def _exporter(watermark=True):
+        admin_store = FakeAdminStore()
+        from src.services.admin import admin_store as admin_store_module
+        monkeypatch.setattr(admin_store_module, "get_admin_store", lambda: admin_store)
+        config = {"exports.watermark.enabled": watermark, "exports.watermark.secret": "s3cret"}
+        monkeypatch.setattr(result_export.settings, "get", lambda key, default=None: config.get(key, default))
+        return ResultExporter(redis_client=FakeRedis()), admin_store
+    return _exporter
+
+
+def test_csv_rows_carry_watermark(exporter):
+    runner, admin_store = exporter()
+    exported = runner.export(RESULTS, "csv", user="alice", store="backend", query="login")
+
+    header, *rows = exported["content"].splitlines()
+    assert header.startswith("# Exported by alice") and exported["request_id"] in header
+    table = list(csv.DictReader(io.StringIO("\n".join(rows))))
+    token = result_export.token(exported["watermark"])
+    assert [r["watermark"] for r in table] == [token, token]
+    assert table[0]["full_path"] == "/repo/auth.py" and table[1]["start_line"] == ""
+
+    action, details, user = admin_store.audit[0]
+    assert action == "search_results_exported" and user == "alice"
+    assert exported["request_id"] in details
+
+
+def test_json_without_watermark(exporter):
+    runner, admin_store = exporter(watermark=False)
+    exported = runner.export(RESULTS, "json", user="bob", store="backend", query="login")
+
+    document = json.loads(exported["content"])
+    assert "watermark" not in document
+    assert "watermark" not in document["results"][0]
+    assert document["request_id"] == exported["request_id"]
+    # Exports are audited either way
+    assert len(admin_store.audit) == 1
+    assert runner.lookup(exported["request_id"])["watermarked"] is False
+
+
+def test_lookup_verifies_signature(exporter):
+    runner, _ = exporter()
+    exported = runner.export(RESULTS, "json", user="alice", store="backend", query="login")
+    token = json.loads(exported["content"])["results"][0]["watermark"]
+
+    record = runner.lookup(token)
+    assert record["user"] == "alice" and record["results"] == 2
+    assert record["signature_valid"] is True
+
+    assert runner.lookup(exported["request_id"] + ".0000000000000000")["signature_valid"] is False
+    assert runner.lookup(exported["request_id"])["signature_valid"] is None
+    assert runner.lookup("unknown.abc") is None
+
+
+def test_unknown_format(exporter):
+    runner, _ = exporter()
+    with pytest.raises(ValueError, match="Unknown export format"):
+        runner.export(RESULTS, "xml", user="alice", store="backend", query="login")
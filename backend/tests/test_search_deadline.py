@@ -0,0 +1,54 @@
+"""
+Unit tests for search deadlines.
+"""
+import asyncio
+import pytest
+
+
+def _settings(monkeypatch, default=10.0, maximum=60.0):
+    from src.services.search import deadline
+
+    values = {"search.timeout.default_seconds": default, "search.timeout.max_seconds": maximum}
+    monkeypatch.setattr(deadline.settings, "get", lambda key, d=None: values.get(key, d))
+    monkeypatch.setattr(deadline, "_count_timeout", lambda: None)
+    return deadline
+
+
+@pytest.mark.unit
+class TestSearchDeadline:
+    """Test deadline resolution and enforcement."""
+
+    def test_resolve_uses_default_and_caps_override(self, monkeypatch):
+        deadline = _settings(monkeypatch)
+
+        assert deadline.resolve_timeout() == 10.0
+        assert deadline.resolve_timeout(2.5) == 2.5
+        assert deadline.resolve_timeout(600) == 60.0
+        # 0 asks for as long as allowed
+        assert deadline.resolve_timeout(0) == 60.0
+
+    def test_no_deadline_when_disabled(self, monkeypatch):
+        deadline = _settings(monkeypatch, default=0, maximum=0)
+
+        assert deadline.resolve_timeout() is None
+        assert deadline.resolve_timeout(5) == 5
+
+    def test_slow_search_raises_typed_error(self, monkeypatch):
+        deadline = _settings(monkeypatch)
+
+        async def slow():
+            await asyncio.sleep(1)
+
+        with pytest.raises(deadline.SearchTimeoutError) as exc:
+            asyncio.run(deadline.with_deadline(slow(), 0.01))
+        assert exc.value.timeout == 0.01
+        assert exc.value.to_dict()["error"] == "search_timeout"
+
+    def test_fast_search_returns_result(self, monkeypatch):
+        deadline = _settings(monkeypatch)
+
+        async def fast():
+            return ["hit"]
+
+        assert asyncio.run(deadline.with_deadline(fast(), 1.0)) == ["hit"]
+        assert asyncio.run(deadline.with_deadline(fast(), None)) == ["hit"]
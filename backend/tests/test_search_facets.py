@@ -0,0 +1,54 @@
+"""
+Tests for search facets and the language/connection filters they feed.
+"""
+from src.services.search.facets import common_directory, compute_facets
+from src.services.search.filters import SearchFilters
+
+
+def _result(path, language=None, connection_id=None):
+    return {"full_path": path, "language": language, "connection_id": connection_id}
+
+
+def test_facets_count_candidates_below_common_directory():
+    results = [
+        _result("/work/api/src/auth.py", "python", "laptop"),
+        _result("/work/api/src/db.py", "Python", "laptop"),
+        _result("/work/api/docs/auth.md", "markdown", "ci"),
+        _result("/work/api/setup.py", "python"),
+    ]
+    facets = compute_facets(results, max_values=10)
+
+    assert facets["language"] == [
+        {"value": "python", "count": 3, "filter": {"languages": ["python"]}},
+        {"value": "markdown", "count": 1, "filter": {"languages": ["markdown"]}},
+    ]
+    # setup.py sits directly in the shared directory and has no directory value
+    assert [(f["value"], f["count"]) for f in facets["directory"]] == [("src", 2), ("docs", 1)]
+    assert facets["directory"][0]["filter"] == {"paths": ["work/api/src/**"]}
+    assert [(f["value"], f["count"]) for f in facets["connection"]] == [("laptop", 2), ("ci", 1)]
+
+
+def test_facets_are_capped_and_empty_ones_omitted():
+    results = [_result(f"/repo/d{i}/f.go", "go") for i in range(5)] + [_result("/repo/d0/g.go", "go")]
+    facets = compute_facets(results, max_values=2)
+    assert [f["value"] for f in facets["directory"]] == ["d0", "d1"]
+    assert "connection" not in facets
+    assert compute_facets([]) == {}
+
+
+def test_common_directory():
+    assert common_directory(["/a/b/c.py", "/a/b/d/e.py"]) == "/a/b"
+    assert common_directory(["C:/work/x.py", "C:/work/y/z.py"]) == "C:/work"
+    assert common_directory(["/a/x.py", "/b/y.py"]) == ""
+
+
+def test_facet_filters_narrow_results():
+    filters = SearchFilters(languages=["Python "], connections=["laptop"])
+    assert filters.languages == ["python"]
+    assert not filters.is_empty()
+    assert filters.matches({"full_path": "/a.py", "language": "python", "connection_id": "laptop"})
+    assert not filters.matches({"full_path": "/a.go", "language": "go", "connection_id": "laptop"})
+    assert not filters.matches({"full_path": "/a.py", "language": "python", "connection_id": "ci"})
+    keys = [c.key for c in filters.conditions()]
+    assert "language" in keys and "connection_id" in keys
+    assert filters.to_dict() == {"languages": ["python"], "connections": ["laptop"]}
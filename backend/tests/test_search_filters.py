@@ -0,0 +1,136 @@
+"""Tests for search payload filters and symbol autocomplete."""
+
+from types import SimpleNamespace
+from unittest.mock import MagicMock
+
+from src.services.search.filters import (
+    SearchFilters,
+    build_filter,
+    glob_match,
+    parse_query,
+    parse_time,
+    path_fields,
+)
+from src.services.search.symbols import SymbolSuggester, matches_prefix
+
+
+def test_parse_query_extracts_symbol_tokens():
+    text, filters = parse_query("config loading symbol:ParseConfig")
+    assert text == "config loading"
+    assert filters.symbols == ["ParseConfig"]
+
+
+def test_parse_query_merges_request_symbols_without_duplicates():
+    text, filters = parse_query("symbol:Load x", SearchFilters(symbols=["Load", "Save"]))
+    assert text == "x"
+    assert filters.symbols == ["Load", "Save"]
+
+
+def test_parse_query_only_filters_searches_symbol_names():
+    text, filters = parse_query("symbol:ParseConfig")
+    assert text == "ParseConfig"
+
+
+def test_parse_query_ignores_embedded_colons():
+    text, filters = parse_query("mysymbol:foo")
+    assert text == "mysymbol:foo"
+    assert filters.is_empty()
+
+
+def test_build_filter():
+    assert build_filter("public") is None
+    assert build_filter(None, SearchFilters()) is None
+
+    f = build_filter("acme", SearchFilters(symbols=["ParseConfig"]))
+    keys = [c.key for c in f.must]
+    assert keys == ["org_id", "symbols"]
+    assert f.must[1].match.any == ["ParseConfig"]
+
+
+def test_matches_payload():
+    filters = SearchFilters(symbols=["ParseConfig"])
+    assert filters.matches({"symbols": ["ParseConfig"]})
+    assert not filters.matches({"symbols": ["Other"]})
+    assert not filters.matches({})
+    assert SearchFilters().matches({})
+
+
+def test_glob_match():
+    assert glob_match("/home/me/repo/src/main.go", "src/**")
+    assert glob_match("/repo/pkg/test/util.go", "**/test/**")
+    assert glob_match("C:\\repo\\lib\\a.go", "**/*.go")
+    assert glob_match("a.go", "*.go")
+    assert not glob_match("/repo/mysrc/main.go", "src/**")
+    assert not glob_match("/repo/src/a/b.go", "src/*.go")
+    assert not glob_match("/repo/testdata/a.go", "**/test/**")
+
+
+def test_path_fields():
+    assert path_fields("/repo/src/Main.GO") == {"extension": ".go", "path_dirs": ["repo", "src"]}
+    assert path_fields(".bashrc") == {"extension": "", "path_dirs": []}
+
+
+def test_path_filters_match_payload():
+    filters = SearchFilters(
+        include_paths=["src/**"],
+        exclude_paths=["**/test/**"],
+        extensions=["GO"],
+        modified_since=100.0,
+    )
+    assert filters.extensions == [".go"]
+    assert filters.matches({"full_path": "/r/src/a.go", "indexed_at": 200})
+    assert not filters.matches({"full_path": "/r/src/test/a.go", "indexed_at": 200})
+    assert not filters.matches({"full_path": "/r/lib/a.go", "indexed_at": 200})
+    assert not filters.matches({"full_path": "/r/src/a.py", "indexed_at": 200})
+    assert not filters.matches({"full_path": "/r/src/a.go", "indexed_at": 50})
+    assert not filters.matches({"full_path": "/r/src/a.go"})
+
+
+def test_build_filter_translates_simple_globs():
+    filters = SearchFilters(include_paths=["**/*.go"], exclude_paths=["**/vendor/**", "src/gen/*"])
+    f = build_filter(None, filters)
+
+    include = f.must[0]
+    assert include.should[0].should[0].key == "extension"
+    assert include.should[0].should[0].match.any == [".go"]
+    # Chunks indexed before the field existed pass through to the post-filter
+    assert include.should[0].should[1].is_empty.key == "extension"
+    assert [(c.key, c.match.value) for c in f.must_not] == [("path_dirs", "vendor")]
+
+
+def test_build_filter_skips_untranslatable_includes():
+    f = build_filter(None, SearchFilters(include_paths=["**/*.go", "src/**"]))
+    assert f is None
+
+
+def test_modified_since_range():
+    f = build_filter(None, SearchFilters(modified_since=parse_time("1700000000")))
+    assert f.must[0].key == "indexed_at"
+    assert f.must[0].range.gte == 1700000000.0
+
+
+def test_matches_prefix_on_dotted_parts():
+    assert matches_prefix("Config.parse", "pars")
+    assert matches_prefix("ParseConfig", "parse")
+    assert not matches_prefix("Config.parse", "arse")
+    assert matches_prefix("anything", "")
+
+
+def test_suggest_orders_and_limits():
+    qdrant = MagicMock()
+    qdrant.facet.return_value = SimpleNamespace(hits=[
+        SimpleNamespace(value="Config.parse", count=5),
+        SimpleNamespace(value="ParseConfig", count=2),
+        SimpleNamespace(value="parseArgs", count=3),
+        SimpleNamespace(value="Render", count=9),
+    ])
+
+    suggestions = SymbolSuggester(qdrant_client=qdrant).suggest("acme", "parse", limit=2)
+
+    assert suggestions == [
+        {"symbol": "parseArgs", "chunks": 3},
+        {"symbol": "ParseConfig", "chunks": 2},
+    ]
+    kwargs = qdrant.facet.call_args.kwargs
+    assert kwargs["key"] == "symbols"
+    assert kwargs["facet_filter"].must[0].match.value == "acme"
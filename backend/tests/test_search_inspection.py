@@ -0,0 +1,95 @@
+"""
+Tests for the file and query inspection views behind `rice-search inspect`.
+"""
+import asyncio
+from types import SimpleNamespace
+
+from src.services.search import inspection
+from src.services.search.inspection import SearchInspector, vector_stats
+
+
+def _point(chunk_id, start, text, dense, splade=None):
+    return SimpleNamespace(
+        id=chunk_id,
+        payload={
+            "org_id": "backend", "full_path": "/src/app.py", "start_line": start, "end_line": start + 9,
+            "text": text, "symbols": ["main"], "chunk_type": "function", "language": "python",
+        },
+        vector={"dense": dense, "splade": splade},
+    )
+
+
+def test_vector_stats():
+    assert vector_stats([3.0, 4.0]) == {"dim": 2, "norm": 5.0}
+    assert vector_stats(SimpleNamespace(indices=[1, 7], values=[1.0, 0.0])) == {"nnz": 2, "norm": 1.0}
+    assert vector_stats({"indices": [], "values": []}) == {"nnz": 0, "norm": 0.0}
+    assert vector_stats(None) is None
+
+
+def test_inspect_file_lists_chunks_in_line_order(fake_qdrant):
+    fake_qdrant.collections["rice_chunks"] = [
+        _point("c2", 11, "def b(): pass", [0.0, 1.0]),
+        _point("c1", 1, "def main(): run()", [0.6, 0.8], SimpleNamespace(indices=[4], values=[2.0])),
+    ]
+    inspector = SearchInspector(qdrant=fake_qdrant)
+    report = inspector.inspect_file("backend", "/src/app.py")
+
+    assert [c["chunk_id"] for c in report["chunks"]] == ["c1", "c2"]
+    first = report["chunks"][0]
+    assert first["symbols"] == ["main"] and first["start_line"] == 1 and first["tokens"] > 0
+    assert first["vectors"] == {"dense": {"dim": 2, "norm": 1.0}, "splade": {"nnz": 1, "norm": 2.0}}
+    assert report["chunk_count"] == 2 and report["language"] == "python"
+    assert report["tokens"] == sum(c["tokens"] for c in report["chunks"])
+    assert all(scroll.with_vectors for scroll in fake_qdrant.scrolls)
+
+    assert inspector.inspect_file("backend", "/src/other.py")["chunks"] == []
+
+
+def test_inspect_query_reports_each_retriever(monkeypatch, fake_qdrant):
+    from src.services.retrieval import bm25_index
+    from src.services.search import query_analyzer, retriever as retriever_module
+
+    async def analyze_for_search(query, force_heuristic=False):
+        return SimpleNamespace(
+            processed_query=f"{query} authentication",
+            to_metadata=lambda: {"path": "heuristic", "intent": "lookup", "expansions": ["authentication"]},
+        )
+
+    async def embed(texts):
+        return [[0.6, 0.8]]
+
+    class FakeRetriever:
+        bm42_encoder = SimpleNamespace(encode_single=lambda text: SimpleNamespace(indices=[1], values=[1.0]))
+        splade_encoder = SimpleNamespace(encode_single=lambda text: (_ for _ in ()).throw(RuntimeError("splade down")))
+
+        async def _search_bm25(self, query, limit, collection_name=None):
+            return [{"chunk_id": "b1", "score": 7.5, "full_path": "/src/auth.py", "start_line": 3, "symbols": ["login"]}]
+
+        async def _search_bm42(self, query, qdrant, limit, search_filter, collection, encoded):
+            assert encoded["dense"] == [0.6, 0.8]
+            return [{"chunk_id": "h1", "score": 0.5, "full_path": "/src/test/auth_test.py", "symbols": ["login"]}]
+
+    monkeypatch.setattr(query_analyzer, "analyze_for_search", analyze_for_search)
+    monkeypatch.setattr(retriever_module, "embed_texts_async", embed)
+    monkeypatch.setattr(bm25_index, "get_store_sparse_backend", lambda org_id: "splade")
+    monkeypatch.setattr(inspection, "store_collection", lambda org_id: "rice_chunks")
+
+    hit = SimpleNamespace(id="d1", score=0.9, payload={"full_path": "/a.py", "symbols": ["login"]})
+    fake_qdrant.query_points = lambda **kwargs: SimpleNamespace(points=[hit])
+    inspector = SearchInspector(qdrant=fake_qdrant, retriever=FakeRetriever())
+    filters = inspection.SearchFilters(exclude_paths=["**/test/**"])
+    report = asyncio.run(inspector.inspect_query("auth symbol:login", "backend", limit=5, filters=filters))
+
+    assert report["parsed"]["text"] == "auth"
+    assert report["parsed"]["filters"]["symbols"] == ["login"]
+    assert report["processed_query"] == "auth authentication"
+    assert report["analysis"]["expansions"] == ["authentication"]
+    assert report["query_vectors"]["dense"] == {"dim": 2, "norm": 1.0}
+    assert "splade down" in report["errors"]["encode_splade"]
+    assert report["candidates"]["bm25"][0] == {
+        "rank": 1, "chunk_id": "b1", "score": 7.5, "path": "/src/auth.py", "start_line": 3, "end_line": None,
+    }
+    assert [c["chunk_id"] for c in report["candidates"]["dense"]] == ["d1"]
+    # Filtered like a real search
+    assert report["candidates"]["bm42"] == []
+    assert {"analysis", "encode_dense", "bm25", "dense"} <= set(report["timings_ms"])
@@ -0,0 +1,93 @@
+"""
+Tests for zero-result and slow-query tracking.
+"""
+import asyncio
+
+import pytest
+from fastapi import HTTPException
+
+from src.services.admin import search_quality
+from src.services.admin.search_quality import SearchQuality, normalize_query, percentiles, slow_queries
+
+
+@pytest.fixture
+def values(monkeypatch):
+    values = {}
+    monkeypatch.setattr(search_quality.settings, "get", lambda key, default=None: values.get(key, default))
+    return values
+
+
+def test_normalize_and_percentiles():
+    assert normalize_query("  Kafka   Consumer\tRetry ") == "kafka consumer retry"
+    assert percentiles([]) == {"p50": 0, "p95": 0, "p99": 0}
+    latencies = list(range(1, 101))
+    assert percentiles(latencies) == {"p50": 51, "p95": 96, "p99": 100}
+
+
+def test_zero_result_queries_are_counted_per_store(values, fake_redis):
+    quality = SearchQuality(redis_client=fake_redis)
+    quality.record("backend", "Kafka consumer", 0, 20.0)
+    quality.record("backend", "kafka  CONSUMER", 0, 25.0)
+    quality.record("docs", "onboarding", 0, 10.0)
+    quality.record("backend", "auth middleware", 7, 30.0)
+    quality.record("backend", "   ", 0, 5.0)
+
+    report = quality.report("backend")
+    assert (report["searches"], report["zero_results"], report["zero_result_rate"]) == (3, 2, 0.6667)
+    assert [(q["query"], q["count"]) for q in report["top_zero_result_queries"]] == [("kafka consumer", 2)]
+    assert report["top_zero_result_queries"][0]["last_seen"]
+    assert "stores" not in report
+
+    overall = quality.report()
+    assert [(q["query"], q["count"]) for q in overall["top_zero_result_queries"]] == [
+        ("kafka consumer", 2), ("onboarding", 1)
+    ]
+    assert [(s["store"], s["zero_results"]) for s in overall["stores"]] == [("backend", 2), ("docs", 1)]
+
+
+def test_only_the_most_frequent_zero_result_queries_are_kept(values, fake_redis):
+    values["metrics.quality.max_queries"] = 2
+    quality = SearchQuality(redis_client=fake_redis)
+    for query in ("common", "common", "also common", "also common", "rare"):
+        quality.record(None, query, 0, 1.0)
+
+    report = quality.report()
+    assert [q["query"] for q in report["top_zero_result_queries"]] == ["common", "also common"]
+    assert "rare" not in quality.redis.hashes["rice:search_quality:_all:zero_seen"]
+
+
+def test_slow_queries_are_recent_searches_at_or_above_p95(values, fake_redis):
+    values["metrics.quality.sample_size"] = 40
+    quality = SearchQuality(redis_client=fake_redis)
+    for i in range(50):
+        quality.record("backend", f"fast {i}", 3, 10.0 + i)
+    quality.record("backend", "slow regex", 3, 400.0)
+    quality.record("backend", "slow regex", 3, 900.0)
+
+    report = quality.report("backend", limit=5)
+    assert report["latency_ms"]["samples"] == 40
+    slow = report["slow_queries"]
+    assert (slow[0]["query"], slow[0]["latency_ms"], slow[0]["count"], slow[0]["percentile"]) == (
+        "slow regex", 900.0, 2, "p99"
+    )
+    assert all(entry["latency_ms"] >= report["latency_ms"]["p95"] for entry in slow)
+    assert slow_queries([], 0, 0, 5) == []
+
+
+def test_only_admins_see_queries_from_every_store(monkeypatch, fake_redis):
+    from src.api.v1.endpoints import stats
+    from src.services.admin import store_acl
+
+    monkeypatch.setattr(stats, "get_search_quality", lambda: SearchQuality(redis_client=fake_redis))
+    monkeypatch.setattr(store_acl, "can_access", lambda user, store, access: store == "backend")
+    viewer = {"sub": "v", "role": "viewer"}
+
+    with pytest.raises(HTTPException) as denied:
+        asyncio.run(stats.search_quality(store=None, limit=10, user=viewer))
+    assert denied.value.status_code == 403
+    with pytest.raises(HTTPException):
+        asyncio.run(stats.search_quality(store="secret", limit=10, user=viewer))
+
+    assert asyncio.run(stats.search_quality(store="backend", limit=10, user=viewer))["store"] == "backend"
+    overall = asyncio.run(stats.search_quality(store=None, limit=10, user={"sub": "a", "role": "admin"}))
+    assert overall["stores"] == []
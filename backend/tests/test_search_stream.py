@@ -0,0 +1,80 @@
+"""
+Tests for streamed search: candidates first, rerank score patches, cancellation.
+"""
+import asyncio
+import json
+
+from fastapi import HTTPException
+
+from src.services.search.streaming import SearchStream
+
+
+def _results(n):
+    return [{"chunk_id": f"c{i}", "text": f"doc {i}", "score": 1.0 / (i + 1)} for i in range(n)]
+
+
+def _stream(results, disconnect_after=None, **kwargs):
+    scored = []
+
+    async def search():
+        return results
+
+    async def scorer(query, texts):
+        scored.append(list(texts))
+        # Reverse the fused order: later docs score higher
+        return [float(text.split()[-1]) for text in texts]
+
+    async def is_disconnected():
+        return disconnect_after is not None and len(scored) >= disconnect_after
+
+    stream = SearchStream(
+        search, "doc", rerank_batch=2, scorer=scorer, is_disconnected=is_disconnected, **kwargs
+    )
+    return stream, scored
+
+
+def _collect(stream):
+    async def run():
+        return [json.loads(line) async for line in stream.ndjson()]
+    return asyncio.run(run())
+
+
+def test_candidates_then_patches_then_done():
+    results = _results(5)
+    stream, scored = _stream(results)
+    messages = _collect(stream)
+
+    assert [m["type"] for m in messages] == ["candidates", "rerank", "rerank", "rerank", "done"]
+    assert [r["chunk_id"] for r in messages[0]["results"]] == ["c0", "c1", "c2", "c3", "c4"]
+    assert messages[1]["patches"] == [{"chunk_id": "c0", "rerank_score": 0.0}, {"chunk_id": "c1", "rerank_score": 1.0}]
+    assert messages[3]["reranked"] == 5 and messages[3]["total"] == 5
+    assert messages[-1]["order"] == ["c4", "c3", "c2", "c1", "c0"]
+    assert scored == [["doc 0", "doc 1"], ["doc 2", "doc 3"], ["doc 4"]]
+    # Shared (cached) results are not modified
+    assert "rerank_score" not in results[0]
+
+
+def test_disconnect_stops_reranking():
+    stream, scored = _stream(_results(6), disconnect_after=1)
+    messages = _collect(stream)
+
+    assert [m["type"] for m in messages] == ["candidates", "rerank"]
+    assert len(scored) == 1
+    assert stream.cancelled
+
+
+def test_no_rerank_and_presenter():
+    stream, scored = _stream(_results(3), rerank=False, present=lambda r: {"chunk_id": r["chunk_id"]})
+    messages = _collect(stream)
+
+    assert messages[0]["results"] == [{"chunk_id": "c0"}, {"chunk_id": "c1"}, {"chunk_id": "c2"}]
+    assert messages[1] == {**messages[1], "type": "done", "order": ["c0", "c1", "c2"], "reranked": 0}
+    assert scored == []
+
+
+def test_search_error_is_a_message():
+    async def search():
+        raise HTTPException(status_code=403, detail="Not allowed to search store 'x'")
+
+    messages = _collect(SearchStream(search, "q"))
+    assert messages == [{"type": "error", "status": 403, "detail": "Not allowed to search store 'x'"}]
@@ -0,0 +1,75 @@
+"""
+Unit tests for seeding a demo store from a bundled dataset.
+"""
+import pytest
+from unittest.mock import MagicMock
+
+
+@pytest.mark.unit
+class TestSeedDataset:
+    """Test loading go-stdlib-sample into a store."""
+
+    def _admin_store(self, stores=None):
+        admin_store = MagicMock()
+        admin_store.get_stores.return_value = stores or {}
+        return admin_store
+
+    def test_indexes_every_file_and_creates_store(self):
+        """Every source file is ingested under the dataset prefix."""
+        from src.datasets import dataset_files
+        from src.services.ingestion.seed import seed_dataset
+
+        indexer = MagicMock()
+        indexer.ingest_file.return_value = {"status": "success", "chunks_indexed": 2}
+        admin_store = self._admin_store()
+
+        result = seed_dataset("go-stdlib-sample", "demo", indexer=indexer, admin_store=admin_store)
+
+        files = dataset_files("go-stdlib-sample")
+        assert result["files_indexed"] == len(files)
+        assert result["chunks_indexed"] == 2 * len(files)
+        assert result["store_created"] is True
+        admin_store.set_store.assert_called_once()
+        assert admin_store.set_store.call_args[0][1]["type"] == "demo"
+
+        display_paths = {c.args[1] for c in indexer.ingest_file.call_args_list}
+        assert "go-stdlib-sample/strings/strings.go" in display_paths
+        assert not any(p.endswith("queries.yaml") for p in display_paths)
+        assert all(c.args[3] == "demo" for c in indexer.ingest_file.call_args_list)
+
+    def test_existing_store_kept_and_failures_reported(self):
+        """An existing store is reused; per-file errors don't stop the seed."""
+        from src.services.ingestion.seed import seed_dataset
+
+        indexer = MagicMock()
+        indexer.ingest_file.side_effect = lambda path, display, *a: (
+            {"status": "error", "message": "boom"} if display.endswith("sort/sort.go")
+            else {"status": "success", "chunks_indexed": 1}
+        )
+        admin_store = self._admin_store({"demo": {"id": "demo"}})
+
+        result = seed_dataset("go-stdlib-sample", "demo", indexer=indexer, admin_store=admin_store)
+
+        assert result["store_created"] is False
+        admin_store.set_store.assert_not_called()
+        assert result["failed"] == [{"path": "go-stdlib-sample/sort/sort.go", "error": "boom"}]
+
+    def test_labels_match_seeded_paths(self):
+        """queries.yaml labels resolve to seeded display paths."""
+        import yaml
+        from src.datasets import dataset_path, dataset_files
+        from src.services.search.evaluation import match_label, normalize_labels
+
+        root = dataset_path("go-stdlib-sample")
+        seeded = [f"go-stdlib-sample/{p.relative_to(root).as_posix()}" for p in dataset_files("go-stdlib-sample")]
+        queries = yaml.safe_load((root / "queries.yaml").read_text())["queries"]
+
+        for item in queries:
+            for label in normalize_labels(item["relevant"]):
+                assert any(match_label(path, {label: 1.0}) for path in seeded), label
+
+    def test_unknown_dataset(self):
+        from src.services.ingestion.seed import seed_dataset
+
+        with pytest.raises(KeyError):
+            seed_dataset("nope", indexer=MagicMock(), admin_store=self._admin_store())
@@ -0,0 +1,107 @@
+"""
+Tests for read-only and maintenance server modes.
+"""
+import asyncio
+import json
+
+import pytest
+
+from src.core import server_mode
+from src.core.server_mode import (
+    MAINTENANCE,
+    NORMAL,
+    READ_ONLY,
+    ServerMode,
+    ServerModeMiddleware,
+    refused,
+)
+
+
+class FakeRedis:
+    def __init__(self):
+        self.values = {}
+        self.reads = 0
+
+    def get(self, key):
+        self.reads += 1
+        return self.values.get(key)
+
+    def set(self, key, value):
+        self.values[key] = value
+
+
+def _call(app, method, path):
+    sent = []
+
+    async def receive():
+        return {"type": "http.request", "body": b"", "more_body": False}
+
+    async def send(message):
+        sent.append(message)
+
+    asyncio.run(app({"type": "http", "method": method, "path": path}, receive, send))
+    return sent
+
+
+def test_read_only_refuses_writes_but_not_searches():
+    assert not refused(READ_ONLY, "GET", "/api/v1/stores/backend")
+    assert not refused(READ_ONLY, "POST", "/api/v1/search/query")
+    assert not refused(READ_ONLY, "POST", "/api/v1/search/batch")
+    assert not refused(READ_ONLY, "POST", "/api/v1/ingest/check-hashes")
+    assert refused(READ_ONLY, "POST", "/api/v1/search/feedback")
+    assert refused(READ_ONLY, "POST", "/api/v1/search/anything-new")
+    assert not refused(READ_ONLY, "OPTIONS", "/api/v1/ingest/file")
+    assert refused(READ_ONLY, "POST", "/api/v1/ingest/file")
+    assert refused(READ_ONLY, "DELETE", "/api/v1/stores/backend")
+    assert refused(READ_ONLY, "PUT", "/api/v1/settings/search.rrf_k")
+    # The switch itself always answers
+    assert not refused(READ_ONLY, "PUT", "/api/v1/admin/public/server/mode")
+    assert not refused(NORMAL, "DELETE", "/api/v1/stores/backend")
+
+
+def test_maintenance_refuses_all_but_health():
+    assert refused(MAINTENANCE, "GET", "/api/v1/stores/backend")
+    assert refused(MAINTENANCE, "POST", "/api/v1/search/query")
+    for path in ("/health", "/readyz", "/readyz/write", "/api/v1/health/history", "/api/v1/admin/public/server/mode"):
+        assert not refused(MAINTENANCE, "GET", path)
+
+
+def test_mode_is_shared_and_cached():
+    redis = FakeRedis()
+    mode = ServerMode(redis_client=redis)
+    assert mode.get()["mode"] == NORMAL
+
+    state = ServerMode(redis_client=redis).set(READ_ONLY, " Qdrant upgrade ", "alice")
+    assert state["reason"] == "Qdrant upgrade" and state["set_by"] == "alice"
+    assert json.loads(redis.values[ServerMode.KEY])["mode"] == READ_ONLY
+
+    # Another replica's switch shows up once the cache expires
+    assert mode.get()["mode"] == NORMAL
+    mode._cached_at = 0
+    assert mode.get()["mode"] == READ_ONLY
+    assert mode.readiness("read") and not mode.readiness("write")
+
+    with pytest.raises(ValueError):
+        mode.set("frozen")
+
+
+def test_middleware_answers_503_with_reason(monkeypatch):
+    mode = ServerMode(redis_client=FakeRedis())
+    mode.set(READ_ONLY, "Migrating backend")
+    monkeypatch.setattr(server_mode, "get_server_mode", lambda: mode)
+    served = []
+
+    async def app(scope, receive, send):
+        served.append(scope["path"])
+
+    middleware = ServerModeMiddleware(app)
+    _call(middleware, "GET", "/api/v1/stores")
+    sent = _call(middleware, "POST", "/api/v1/ingest/file")
+    assert served == ["/api/v1/stores"]
+
+    assert sent[0]["status"] == 503
+    assert (b"retry-after", b"60") in sent[0]["headers"]
+    body = json.loads(sent[1]["body"])
+    assert body["code"] == "read_only"
+    assert body["detail"] == "Server is read-only: Migrating backend"
+    assert body["mode"]["mode"] == READ_ONLY
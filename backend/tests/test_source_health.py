@@ -0,0 +1,99 @@
+"""
+Tests for git source health checks.
+"""
+from src.services.webhooks import source_health
+
+
+class FakeResponse:
+    def __init__(self, status_code, headers=None):
+        self.status_code = status_code
+        self.headers = headers or {}
+
+
+def _settings(monkeypatch, **overrides):
+    config = {"webhooks.git.enabled": True, **overrides}
+    monkeypatch.setattr(source_health.settings, "get", lambda key, default=None: config.get(key, default))
+
+
+def _responses(monkeypatch, by_url):
+    calls = []
+
+    def fake_get(url, headers, timeout):
+        calls.append(url)
+        response = by_url.get(url)
+        if isinstance(response, Exception):
+            raise response
+        return response or FakeResponse(200)
+
+    monkeypatch.setattr(source_health, "_get", fake_get)
+    return calls
+
+
+def test_configured_sources(monkeypatch):
+    _settings(monkeypatch, **{
+        "webhooks.git.gitlab_token": "glpat",
+        "webhooks.git.repositories": [
+            {"repository": "acme/api", "store": "backend"},
+            {"repository": "acme/infra", "provider": "GitLab", "store": "ops"},
+        ],
+    })
+    assert source_health.configured_sources() == [
+        {"provider": "github"},
+        {"provider": "gitlab"},
+        {"provider": "github", "repository": "acme/api"},
+        {"provider": "gitlab", "repository": "acme/infra"},
+    ]
+
+    _settings(monkeypatch, **{"webhooks.git.enabled": False, "webhooks.git.github_token": "ghp"})
+    assert source_health.configured_sources() == []
+
+
+def test_provider_token_rejected(monkeypatch):
+    _settings(monkeypatch, **{"webhooks.git.github_token": "ghp"})
+    _responses(monkeypatch, {"https://api.github.com/user": FakeResponse(401)})
+    result = source_health.check_provider("github", 5)
+    assert result["status"] == "down"
+    assert "webhooks.git.github_token" in result["message"]
+
+
+def test_provider_without_token_and_unreachable(monkeypatch):
+    _settings(monkeypatch)
+    calls = _responses(monkeypatch, {})
+    result = source_health.check_provider("github", 5)
+    assert result["status"] == "up" and "public repositories only" in result["message"]
+    assert calls == ["https://api.github.com/rate_limit"]
+
+    _settings(monkeypatch, **{"webhooks.git.gitlab_url": "https://git.example.com"})
+    _responses(monkeypatch, {"https://git.example.com/api/v4/version": ConnectionError("refused")})
+    result = source_health.check_provider("gitlab", 5)
+    assert result["status"] == "down"
+    assert "webhooks.git.gitlab_url" in result["message"]
+
+
+def test_repository_not_found(monkeypatch):
+    _settings(monkeypatch, **{"webhooks.git.gitlab_token": "glpat"})
+    calls = _responses(monkeypatch, {"https://gitlab.com/api/v4/projects/acme%2Finfra": FakeResponse(404)})
+    result = source_health.check_repository("gitlab", "acme/infra", 5)
+    assert result["status"] == "down"
+    assert "webhooks.git.repositories" in result["message"]
+    assert calls == ["https://gitlab.com/api/v4/projects/acme%2Finfra"]
+
+
+def test_check_sources_is_cached(monkeypatch):
+    monkeypatch.setattr(source_health, "_cache", None)
+    _settings(monkeypatch, **{
+        "webhooks.git.github_token": "ghp",
+        "webhooks.git.repositories": [{"repository": "acme/api", "store": "backend"}],
+    })
+    calls = _responses(monkeypatch, {"https://api.github.com/repos/acme/api": FakeResponse(403)})
+
+    results = source_health.check_sources()
+    assert results["source:github"]["status"] == "up"
+    assert results["source:github:acme/api"]["status"] == "down"
+    assert all("checked_at" in r for r in results.values())
+    assert len(calls) == 2
+
+    source_health.check_sources()
+    assert len(calls) == 2
+    source_health.check_sources(force=True)
+    assert len(calls) == 4
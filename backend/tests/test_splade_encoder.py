@@ -0,0 +1,60 @@
+"""
+Tests for the SPLADE encoder's idle unload.
+"""
+import threading
+
+import pytest
+
+from src.services.retrieval import splade_encoder
+from src.services.retrieval.splade_encoder import SparseVector, SpladeEncoder
+
+
+def _encoder():
+    # Skip model loading; only the unload bookkeeping is under test
+    encoder = SpladeEncoder.__new__(SpladeEncoder)
+    encoder.model_id = "splade"
+    encoder.model = object()
+    encoder.tokenizer = object()
+    encoder.batch_size = 8
+    encoder._in_use = 0
+    encoder._idle = threading.Condition()
+    return encoder
+
+
+def test_unload_waits_for_an_in_flight_encode(monkeypatch):
+    monkeypatch.setattr(splade_encoder, "touch_model", lambda model_id: None)
+    encoder = _encoder()
+    started, release = threading.Event(), threading.Event()
+
+    def encode_batch(texts):
+        started.set()
+        release.wait(2)
+        # The model is still there mid-batch
+        assert encoder.model is not None
+        return [SparseVector([1], [0.5]) for _ in texts]
+
+    monkeypatch.setattr(encoder, "_encode_batch", encode_batch)
+    results = []
+    encoding = threading.Thread(target=lambda: results.extend(encoder.encode(["a", "b"])))
+    encoding.start()
+    started.wait(2)
+
+    freeing = threading.Thread(target=encoder._free_model)
+    freeing.start()
+    freeing.join(0.1)
+    assert freeing.is_alive()
+
+    release.set()
+    encoding.join(2)
+    freeing.join(2)
+    assert len(results) == 2
+    assert encoder.model is None and encoder.tokenizer is None
+
+
+def test_encode_after_unload_fails_cleanly(monkeypatch):
+    monkeypatch.setattr(splade_encoder, "touch_model", lambda model_id: None)
+    encoder = _encoder()
+    encoder._free_model()
+    with pytest.raises(RuntimeError, match="not loaded"):
+        encoder.encode(["a"])
+    assert encoder._in_use == 0
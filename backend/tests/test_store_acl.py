@@ -0,0 +1,121 @@
+"""
+Tests for store access control lists.
+"""
+import asyncio
+
+import pytest
+from fastapi import HTTPException
+
+from src.services.admin import store_acl
+from src.services.admin.store_acl import InvalidACL, can_access, describe, grants, normalize_acl
+
+ACL = {"owner": "key:alice", "readers": ["key:bob"], "writers": ["connection:conn_1"]}
+STORES = {"hr-payroll": {"name": "HR", "acl": ACL}, "docs": {"name": "Docs"}}
+BOB = {"sub": "bob", "realm_access": {"roles": ["member"]}}
+CAROL = {"sub": "carol", "realm_access": {"roles": ["member"]}}
+
+
+def test_normalize_drops_duplicates_and_rejects_bad_principals():
+    acl = normalize_acl(
+        "key:alice",
+        readers=["key:bob", "key:bob", "connection:conn_1", "key:alice"],
+        writers=["connection:conn_1", " connection:conn_1 "],
+    )
+    assert acl == {"owner": "key:alice", "readers": ["key:bob"], "writers": ["connection:conn_1"]}
+
+    with pytest.raises(InvalidACL):
+        normalize_acl("alice")
+    with pytest.raises(InvalidACL):
+        normalize_acl("key:alice", readers=["key:"])
+
+
+def test_grants_by_role():
+    assert grants(None, {"key:anyone"}, store_acl.OWNER)
+
+    assert grants(ACL, {"key:alice"}, store_acl.OWNER)
+    assert grants(ACL, {"connection:conn_1"}, store_acl.WRITE)
+    assert not grants(ACL, {"connection:conn_1"}, store_acl.OWNER)
+    assert grants(ACL, {"key:bob"}, store_acl.READ)
+    assert not grants(ACL, {"key:bob"}, store_acl.WRITE)
+    assert not grants(ACL, {"key:carol"}, store_acl.READ)
+
+
+def test_can_access_with_connection_and_admin_bypass():
+    assert can_access(BOB, "hr-payroll", store_acl.READ, stores=STORES)
+    assert not can_access(CAROL, "hr-payroll", store_acl.READ, stores=STORES)
+    # A verified connection lends its grants to the caller
+    assert can_access(CAROL, "hr-payroll", store_acl.WRITE, connection_id="conn_1", stores=STORES)
+    # Stores without an ACL stay open
+    assert can_access(CAROL, "docs", store_acl.WRITE, stores=STORES)
+
+    admin = {"id": "root", "role": "admin"}
+    assert can_access(admin, "hr-payroll", store_acl.OWNER, stores=STORES)
+    assert describe(ACL, set(), admin=True)["access"] == {"read": True, "write": True, "owner": True}
+    assert describe(ACL, {"key:bob"}, admin=False)["access"] == {"read": True, "write": False, "owner": False}
+
+
+def test_unreadable_store_list_fails_closed(monkeypatch):
+    def broken(store_id, stores=None):
+        raise ConnectionError("redis down")
+
+    monkeypatch.setattr(store_acl, "store_acl", broken)
+    assert not can_access(BOB, "docs", store_acl.READ)
+    assert can_access({"role": "admin"}, "docs", store_acl.READ)
+
+
+class FakeAdminStore:
+    def __init__(self, stores):
+        self.stores = stores
+        self.audit = []
+
+    def get_stores(self):
+        return self.stores
+
+    def get_trashed_stores(self):
+        return {}
+
+    def set_store(self, store_id, data):
+        self.stores[store_id] = data
+        return True
+
+    def log_audit(self, action, details, user_id="admin"):
+        self.audit.append(action)
+
+
+def _endpoint(monkeypatch, stores):
+    from src.api.v1.endpoints import stores as endpoints
+    from src.services.admin import admin_store
+    fake = FakeAdminStore(stores)
+    monkeypatch.setattr(admin_store, "get_admin_store", lambda: fake)
+    monkeypatch.setattr(endpoints, "get_admin_store", lambda: fake)
+    monkeypatch.setattr(endpoints, "emit", lambda *a, **k: None)
+    monkeypatch.setattr("src.services.search.query_cache.invalidate_store", lambda store_id: None)
+    return endpoints, fake
+
+
+def test_open_store_can_only_be_claimed_by_its_org_or_an_admin(monkeypatch):
+    endpoints, fake = _endpoint(monkeypatch, {"acme": {"name": "Acme"}})
+    outsider = {"sub": "mallory", "org_id": "public", "realm_access": {"roles": ["member"]}}
+    with pytest.raises(HTTPException) as refused:
+        asyncio.run(endpoints.set_store_acl("acme", endpoints.StoreACL(), user=outsider))
+    assert refused.value.status_code == 403
+    assert "acl" not in fake.stores["acme"]
+
+    member = {"sub": "alice", "org_id": "acme", "realm_access": {"roles": ["member"]}}
+    result = asyncio.run(endpoints.set_store_acl("acme", endpoints.StoreACL(), user=member))
+    assert result["acl"]["owner"] == "key:alice"
+
+    # Once restricted, only the owner (not the rest of the org) changes it
+    colleague = {"sub": "bob", "org_id": "acme", "realm_access": {"roles": ["member"]}}
+    with pytest.raises(HTTPException) as refused:
+        asyncio.run(endpoints.set_store_acl("acme", endpoints.StoreACL(owner="key:bob"), user=colleague))
+    assert refused.value.status_code == 403
+
+
+def test_admin_can_restrict_any_open_store(monkeypatch):
+    endpoints, fake = _endpoint(monkeypatch, {"acme": {"name": "Acme"}})
+    admin = {"id": "root", "role": "admin", "org_id": "default"}
+    result = asyncio.run(endpoints.set_store_acl("acme", endpoints.StoreACL(owner="key:alice"), user=admin))
+    assert fake.stores["acme"]["acl"]["owner"] == "key:alice"
+    assert result["restricted"] is True
+    assert fake.audit == ["store_acl"]
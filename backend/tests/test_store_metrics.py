@@ -0,0 +1,56 @@
+"""
+Tests for live per-store metrics built from bus events.
+"""
+from datetime import datetime
+
+from src.services.admin.store_metrics import StoreMetricsWindow
+
+NOW = datetime(2026, 1, 5, 10, 0, 0).timestamp()
+
+
+def _event(topic, seconds_ago=0, event_id="1-0", **payload):
+    return {
+        "id": event_id,
+        "topic": topic,
+        "timestamp": datetime.fromtimestamp(NOW - seconds_ago).isoformat(),
+        "payload": payload,
+    }
+
+
+def test_search_rate_covers_only_the_window():
+    window = StoreMetricsWindow("docs", window_seconds=60)
+    window.seed([
+        _event("search.query", 120, org_id="docs", latency_ms=500),
+        _event("search.query", 30, org_id="docs", latency_ms=100),
+        _event("search.query", 10, org_id="docs", latency_ms=50),
+        _event("search.query", 5, org_id="other", latency_ms=900),
+    ])
+
+    snapshot = window.snapshot(now=NOW, total_searches=42)
+    assert snapshot["searches"] == 2
+    assert snapshot["qps"] == round(2 / 60, 3)
+    assert snapshot["avg_latency_ms"] == 75.0
+    assert snapshot["total_searches"] == 42
+
+
+def test_index_events_are_listed_newest_first_and_counted():
+    window = StoreMetricsWindow("docs", recent=2)
+    assert window.add(_event("index.file.started", 3, "1-0", org_id="docs", path="a.py"))
+    assert window.add(_event("index.file.success", 2, "2-0", org_id="docs", path="a.py", chunks_indexed=4))
+    assert window.add(_event("index.file.error", 1, "3-0", org_id="docs", path="b.py"))
+    assert not window.add(_event("index.file.success", 1, "4-0", org_id="other", path="c.py"))
+    # Replayed events are not live activity
+    assert not window.add({**_event("index.file.success", 1, "5-0", org_id="docs", path="a.py"), "replayed": True})
+
+    snapshot = window.snapshot(now=NOW)
+    assert [e["id"] for e in snapshot["recent_index_events"]] == ["3-0", "2-0"]
+    assert snapshot["recent_index_events"][1]["chunks"] == 4
+    assert snapshot["index_counts"] == {"started": 1, "success": 1, "error": 1}
+
+
+def test_empty_window():
+    snapshot = StoreMetricsWindow("docs").snapshot(now=NOW)
+    assert snapshot["searches"] == 0
+    assert snapshot["qps"] == 0
+    assert snapshot["avg_latency_ms"] is None
+    assert snapshot["recent_index_events"] == []
@@ -0,0 +1,124 @@
+"""
+Tests for embedding model mismatch detection and store migration.
+"""
+from types import SimpleNamespace
+
+import pytest
+
+from src.services.ingestion import migration
+from src.services.ingestion.migration import StoreMigration, embedding_status, store_collections, write_collections
+from src.services.search.tiering import store_cold_collection
+
+
+class FakeAdminStore:
+    def __init__(self, stores):
+        self.stores = stores
+
+    def get_stores(self):
+        return self.stores
+
+    def set_store(self, store_id, data):
+        self.stores[store_id] = data
+        return True
+
+
+def _point(point_id, store, text):
+    payload = {"org_id": store, "text": text, "full_path": f"/{point_id}.py", "filename": f"{point_id}.py"}
+    return SimpleNamespace(id=point_id, payload=payload, vector={"splade": "sparse", "bm42": "sparse"})
+
+
+@pytest.fixture
+def setup(monkeypatch, fake_qdrant):
+    def _setup(stores, dimension=768):
+        admin_store = FakeAdminStore(stores)
+        from src.services.admin import admin_store as admin_store_module
+        from src.services.search import query_cache
+        monkeypatch.setattr(admin_store_module, "get_admin_store", lambda: admin_store)
+        monkeypatch.setattr(query_cache, "invalidate_store", lambda org_id=None: None)
+        monkeypatch.setattr(migration.settings, "get", lambda key, default=None: default)
+        monkeypatch.setattr(migration, "configured_model", lambda: "new-model")
+        monkeypatch.setattr(migration, "configured_dimension", lambda: 4)
+        points = [_point(i, "docs" if i < 5 else "other", f"text {i}") for i in range(7)]
+        fake_qdrant.collections = {"rice_chunks": points}
+        fake_qdrant.dimension, fake_qdrant.dimensions = dimension, {}
+        runner = StoreMigration(qdrant_client=fake_qdrant, embed=lambda texts: [[0.5] * 4 for _ in texts])
+        return admin_store, fake_qdrant, runner
+    return _setup
+
+
+def test_dimension_and_model_mismatch(setup):
+    _, qdrant, _ = setup({"docs": {}, "recorded": {"embedding_model": "old-model"}})
+    status = embedding_status("docs", qdrant)
+    assert status["mismatch"] is True
+    assert status["dimension"] == 768 and status["current_dimension"] == 4
+
+    _, qdrant, _ = setup({"recorded": {"embedding_model": "old-model"}}, dimension=4)
+    status = embedding_status("recorded", qdrant)
+    assert status["mismatch"] is True
+    assert "old-model" in status["reason"]
+
+
+def test_migration_reembeds_and_switches(setup):
+    admin_store, qdrant, runner = setup({"docs": {}, "other": {}})
+    progress = []
+
+    plan = runner.start("docs", task_id="t1")
+    assert plan["total"] == 5 and plan["target"] == "rice_chunks_docs_new_model"
+    assert write_collections("docs") == ["rice_chunks", "rice_chunks_docs_new_model"]
+
+    result = runner.run("docs", progress=lambda done, total: progress.append((done, total)))
+
+    target = qdrant.collections["rice_chunks_docs_new_model"]
+    assert result["migrated"] == 5 and progress[-1] == (5, 5)
+    assert qdrant.dimensions["rice_chunks_docs_new_model"] == 4
+    assert target[0].vector == {"splade": "sparse", "bm42": "sparse", "dense": [0.5] * 4}
+    # Old vectors of the store are removed; other stores are untouched
+    assert {p.payload["org_id"] for p in qdrant.collections["rice_chunks"]} == {"other"}
+
+    store = admin_store.stores["docs"]
+    assert store["collection"] == "rice_chunks_docs_new_model"
+    assert (store["embedding_model"], store["embedding_dim"]) == ("new-model", 4)
+    assert store["migration"]["state"] == "complete"
+    assert embedding_status("docs", qdrant)["mismatch"] is False
+
+    # Readers follow the switch; the migrated store gets its own cold tier
+    assert store_collections("docs") == ["rice_chunks_docs_new_model"]
+    assert store_collections("other") == ["rice_chunks"]
+    assert store_collections() == ["rice_chunks", "rice_chunks_docs_new_model"]
+    assert store_cold_collection("docs") == "rice_chunks_docs_new_model_cold"
+
+
+def test_migration_refusals(setup):
+    _, _, runner = setup({"docs": {"privacy_mode": True}, "busy": {"migration": {"state": "running"}}})
+    with pytest.raises(ValueError, match="privacy mode"):
+        runner.plan("docs")
+    with pytest.raises(ValueError, match="already has a migration"):
+        runner.plan("busy")
+    with pytest.raises(ValueError, match="not found"):
+        runner.plan("missing")
+
+
+def test_doc_vectors_use_referenced_text(setup, monkeypatch, tmp_path):
+    from unittest.mock import patch
+    from src.db import content_store
+    from src.services.ingestion import doc_vectors
+
+    _, _, runner = setup({"docs": {}})
+    monkeypatch.setattr(doc_vectors, "doc_vectors_enabled", lambda: True)
+    store = content_store.ContentStore(root=str(tmp_path))
+    code = 'def retry(call):\n    """Call again after a failure, up to three times in total."""\n    return call()\n'
+    points = [
+        SimpleNamespace(id=1, payload={"org_id": "docs", "language": "python", "content_ref": store.put(code)}),
+        SimpleNamespace(id=2, payload={"org_id": "docs", "language": "python", "text": "x = 1",
+                                       "summary": "Sets x to one."}),
+        SimpleNamespace(id=3, payload={"org_id": "docs", "language": "python", "text": "x = 1"}),
+    ]
+    embedded = []
+    runner.embed = lambda texts: embedded.extend(texts) or [[0.1] * 4 for _ in texts]
+
+    with patch.object(content_store, "_content_store", store):
+        docs = runner._embed_docs(points, [[0.5] * 4] * 3)
+
+    assert "Call again after a failure, up to three times in total." in embedded[0]
+    assert embedded[1] == "Sets x to one."
+    assert docs[0] and docs[1] and docs[2] == {}
@@ -0,0 +1,75 @@
+"""
+Tests for incrementally maintained store stats.
+"""
+from types import SimpleNamespace
+
+from src.services.admin.store_stats import StoreStats, aggregate_payloads
+
+
+def _languages(stats):
+    return {l["language"]: (l["files"], l["chunks"], l["bytes"]) for l in stats["languages"]}
+
+
+def test_replacing_and_deleting_files_adjusts_totals(fake_redis):
+    stats = StoreStats(redis_client=fake_redis)
+    stats.mark_new("backend")
+    stats.record_file("backend", "src/app.py", chunks=4, size=1000, language="python")
+    stats.record_file("backend", "README.md", chunks=2, size=300, language="markdown")
+    stats.record_file("backend", "Makefile", chunks=1, size=50)
+
+    # Re-indexing a file replaces its earlier contribution
+    stats.record_file("backend", "src/app.py", chunks=6, size=1500, language="python")
+    result = stats.get("backend")
+    assert (result["files"], result["chunks"], result["bytes"]) == (3, 9, 1850)
+    assert _languages(result) == {"python": (1, 6, 1500), "markdown": (1, 2, 300), "unknown": (1, 1, 50)}
+    assert result["complete"]
+
+    stats.remove_file("backend", "README.md")
+    stats.remove_file("backend", "never-indexed.txt")
+    result = stats.get("backend")
+    assert (result["files"], result["chunks"], result["bytes"]) == (2, 7, 1550)
+    assert [l["language"] for l in result["languages"]] == ["python", "unknown"]
+
+
+def test_mark_new_keeps_counts_of_a_recreated_store(fake_redis):
+    stats = StoreStats(redis_client=fake_redis)
+    stats.record_file("docs", "a.md", chunks=1, size=10, language="markdown")
+    assert not stats.get("docs")["complete"]
+
+    stats.mark_new("docs")
+    result = stats.get("docs")
+    assert result["files"] == 1 and not result["complete"]
+
+
+def test_aggregate_payloads_prefers_file_size():
+    files = aggregate_payloads([
+        {"full_path": "a.py", "language": "python", "file_bytes": 900, "text": "x" * 500},
+        {"full_path": "a.py", "language": "python", "file_bytes": 900, "text": "y" * 500},
+        {"full_path": "b.md", "text": "héllo"},
+        {"full_path": "b.md", "content_length": 20},
+        {"text": "no path"},
+    ])
+    assert files == {
+        "a.py": {"language": "python", "chunks": 2, "bytes": 900},
+        "b.md": {"language": "unknown", "chunks": 2, "bytes": 26},
+    }
+
+
+def test_rebuild_replaces_drifted_counts(monkeypatch, fake_redis, fake_qdrant):
+    from src.services.ingestion import migration
+    from src.services.search import query_cache
+
+    monkeypatch.setattr(migration, "store_collection", lambda store_id: "rice_chunks")
+    invalidated = []
+    monkeypatch.setattr(query_cache, "invalidate_store", invalidated.append)
+    fake_qdrant.collections["rice_chunks"] = [
+        SimpleNamespace(payload={"org_id": "backend", "full_path": "a.py", "language": "python", "file_bytes": 100}),
+        SimpleNamespace(payload={"org_id": "backend", "full_path": "b.py", "language": "python", "file_bytes": 200}),
+    ]
+    stats = StoreStats(redis_client=fake_redis, qdrant_client=fake_qdrant)
+    stats.record_file("backend", "deleted-long-ago.go", chunks=3, size=999, language="go")
+
+    result = stats.rebuild("backend")
+    assert (result["files"], result["chunks"], result["bytes"]) == (2, 2, 300)
+    assert _languages(result) == {"python": (2, 2, 300)}
+    assert result["complete"] and invalidated == ["backend"]
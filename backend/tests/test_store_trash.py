@@ -0,0 +1,146 @@
+"""
+Tests for soft-deleted stores: trash, restore and purge.
+"""
+import json
+from datetime import datetime, timedelta
+from types import SimpleNamespace
+
+import pytest
+
+from src.core.config import settings
+from src.services.admin import admin_store as admin_store_module
+from src.services.admin import store_trash
+from src.services.admin.admin_store import AdminStore
+
+
+class FakeRedis:
+    def __init__(self):
+        self.values = {}
+
+    def get(self, key):
+        return self.values.get(key)
+
+    def set(self, key, value):
+        self.values[key] = value
+
+    def exists(self, key):
+        return key in self.values
+
+    def lpush(self, key, value):
+        pass
+
+    def ltrim(self, key, start, end):
+        pass
+
+    def hincrby(self, key, field, amount):
+        pass
+
+    def zrem(self, key, member):
+        pass
+
+    def hdel(self, key, field):
+        pass
+
+
+class FakeQdrant:
+    def __init__(self):
+        self.dropped = []
+        self.deleted = []
+
+    def delete_collection(self, name):
+        self.dropped.append(name)
+
+    def delete(self, collection_name, points_selector):
+        self.deleted.append((collection_name, points_selector.must[0].match.value))
+
+
+@pytest.fixture
+def admin(monkeypatch, tmp_path):
+    monkeypatch.setattr(AdminStore, "PERSIST_DIR", str(tmp_path))
+    store = AdminStore()
+    store._redis = FakeRedis()
+    store._initialized = True
+    store.set_store("public", {"id": "public", "name": "Public Index"})
+    store.set_store("backend", {"id": "backend", "name": "Backend", "collection": "rice_backend_e5"})
+    monkeypatch.setattr(admin_store_module, "get_admin_store", lambda: store)
+
+    from src.services.search import query_cache
+    monkeypatch.setattr(query_cache, "invalidate_store", lambda org_id=None: None)
+    return store
+
+
+def test_trashed_store_is_hidden_until_restored(admin):
+    record = store_trash.trash_store("backend", "alice")
+    assert "backend" not in admin.get_stores()
+    assert record["deleted_by"] == "alice"
+    deadline = datetime.fromisoformat(record["purge_after"])
+    assert timedelta(days=29) < deadline - datetime.now() <= timedelta(days=30)
+    assert store_trash.trash_store("backend") is None
+
+    # The trash survives losing Redis
+    with open(f"{admin.PERSIST_DIR}/stores_trash.json") as f:
+        assert "backend" in json.load(f)
+    admin._redis.values.pop(admin.TRASH_KEY)
+    admin._initialized = False
+    assert "backend" in admin.get_trashed_stores()
+
+    restored = admin.restore_store("backend")
+    assert restored == {"id": "backend", "name": "Backend", "collection": "rice_backend_e5"}
+    assert "backend" in admin.get_stores() and admin.get_trashed_stores() == {}
+    assert admin.restore_store("backend") is None
+
+
+def test_restore_refuses_a_reused_id(admin):
+    store_trash.trash_store("backend")
+    admin.set_store("backend", {"id": "backend", "name": "New Backend"})
+    with pytest.raises(ValueError):
+        admin.restore_store("backend")
+    assert "backend" in admin.get_trashed_stores()
+
+
+def test_trashed_stores_are_not_found(admin, monkeypatch):
+    from fastapi import HTTPException
+    from src.api.v1.dependencies import authorize_store
+
+    monkeypatch.setattr("src.services.admin.store_acl.can_access", lambda *args, **kwargs: True)
+    authorize_store({"id": "alice"}, "backend", "read")
+    store_trash.trash_store("backend")
+    with pytest.raises(HTTPException) as error:
+        authorize_store({"id": "alice"}, "backend", "read")
+    assert error.value.status_code == 404
+
+
+def test_purge_expired_removes_index_and_record(admin, monkeypatch):
+    qdrant = FakeQdrant()
+    cleared = []
+    monkeypatch.setattr("src.db.qdrant.get_qdrant_client", lambda: qdrant)
+    monkeypatch.setattr("src.services.search.experiments.get_experiment_runner",
+                        lambda: SimpleNamespace(drop=lambda s: cleared.append(("experiment", s))))
+    monkeypatch.setattr("src.services.retrieval.bm25_index.get_bm25_index",
+                        lambda: SimpleNamespace(clear=lambda s: cleared.append(("bm25", s))))
+    monkeypatch.setattr("src.services.search.fusion_tuning.get_fusion_tuner",
+                        lambda: SimpleNamespace(reset=lambda s: cleared.append(("fusion", s))))
+    monkeypatch.setattr("src.services.admin.store_stats.get_store_stats",
+                        lambda: SimpleNamespace(drop=lambda s: cleared.append(("stats", s))))
+    monkeypatch.setattr("src.services.admin.duplicates.get_duplicate_reports",
+                        lambda: SimpleNamespace(drop=lambda s: cleared.append(("duplicates", s))))
+    monkeypatch.setattr("src.services.ingestion.chunk_redirects.get_chunk_redirects",
+                        lambda: SimpleNamespace(drop=lambda s: cleared.append(("redirects", s))))
+    monkeypatch.setattr("src.services.search.suggestions.get_query_suggester",
+                        lambda: SimpleNamespace(drop=lambda s: cleared.append(("suggest", s))))
+
+    store_trash.trash_store("backend")
+    store_trash.trash_store("public")
+    assert store_trash.purge_expired() == []
+
+    later = datetime.now() + timedelta(days=31)
+    trash = admin.get_trashed_stores()
+    trash["public"]["purge_after"] = (later + timedelta(days=1)).isoformat()
+    admin._save_trash(trash)
+    assert store_trash.purge_expired(now=later) == ["backend"]
+
+    assert qdrant.dropped == ["rice_backend_e5", "rice_backend_e5_cold"]
+    assert (settings.COLLECTION_PREFIX, "backend") in qdrant.deleted
+    assert all(store == "backend" for _, store in qdrant.deleted)
+    assert {name for name, _ in cleared} == {"experiment", "bm25", "fusion", "stats", "duplicates", "redirects", "suggest"}
+    assert list(admin.get_trashed_stores()) == ["public"]
@@ -0,0 +1,106 @@
+"""
+Tests for the background task supervisor.
+"""
+import threading
+import time
+
+from src.core import supervisor as supervisor_module
+from src.core.supervisor import FAILED, FINISHED, STOPPED, TaskSupervisor
+
+
+def _wait_for(predicate, timeout=2.0):
+    deadline = time.monotonic() + timeout
+    while time.monotonic() < deadline:
+        if predicate():
+            return True
+        time.sleep(0.01)
+    return False
+
+
+def _supervisor(monkeypatch):
+    monkeypatch.setattr(supervisor_module.settings, "get", lambda key, d=None: d)
+    return TaskSupervisor(history=10)
+
+
+def test_one_shot_task_is_listed_then_finished(monkeypatch):
+    sup = _supervisor(monkeypatch)
+    release = threading.Event()
+    task = sup.spawn("job", release.wait, group="alerts")
+
+    running = sup.tasks(include_finished=False)
+    assert [t["name"] for t in running] == ["job"]
+    assert running[0]["group"] == "alerts"
+    assert sup.summary()["groups"] == {"alerts": 1}
+
+    release.set()
+    assert _wait_for(lambda: task.state == FINISHED)
+    assert sup.tasks(include_finished=False) == []
+    assert sup.tasks()[0]["state"] == FINISHED
+
+
+def test_crash_is_recorded(monkeypatch):
+    sup = _supervisor(monkeypatch)
+
+    def boom():
+        raise ValueError("bad input")
+
+    task = sup.spawn("crasher", boom)
+    assert _wait_for(lambda: task.state == FAILED)
+    assert task.last_error == "bad input"
+    assert sup.summary()["failed"] == 1
+
+
+def test_loop_keeps_running_after_errors(monkeypatch):
+    sup = _supervisor(monkeypatch)
+    calls = []
+
+    def flaky():
+        calls.append(1)
+        if len(calls) == 1:
+            raise RuntimeError("first run fails")
+
+    task = sup.spawn_loop("loop", flaky, interval=0.01, run_first=True)
+    assert _wait_for(lambda: len(calls) >= 3)
+    assert task.errors == 1
+
+    sup.shutdown(timeout=1)
+    assert task.state == STOPPED
+    assert not task.alive
+
+
+def test_shutdown_stops_lower_order_first(monkeypatch):
+    sup = _supervisor(monkeypatch)
+    stopped = []
+
+    def worker(label, stop):
+        stop.wait()
+        stopped.append(label)
+
+    sup.spawn("transport", worker, "transport", order=100, pass_stop=True)
+    sup.spawn("consumer", worker, "consumer", order=10, pass_stop=True)
+    sup.spawn("models", worker, "models", order=80, pass_stop=True)
+
+    assert sup.shutdown(timeout=1) == []
+    assert stopped == ["consumer", "models", "transport"]
+
+
+def test_shutdown_reports_stuck_tasks_and_refuses_new_ones(monkeypatch):
+    sup = _supervisor(monkeypatch)
+    release = threading.Event()
+    sup.spawn("stuck", release.wait)
+
+    assert sup.shutdown(timeout=0.05) == ["stuck"]
+    release.set()
+
+    late = sup.spawn("late", lambda: None)
+    assert late.state == STOPPED
+    assert late.thread is None
+
+
+def test_on_stop_hook_unblocks_task(monkeypatch):
+    sup = _supervisor(monkeypatch)
+    unblock = threading.Event()
+    task = sup.spawn("blocking", unblock.wait, on_stop=unblock.set)
+
+    assert sup.shutdown(timeout=1) == []
+    assert task.state == STOPPED
@@ -0,0 +1,141 @@
+"""
+Unit tests for hot/cold tier management.
+"""
+import pytest
+from types import SimpleNamespace
+from unittest.mock import MagicMock
+
+DAY = 86400
+
+
+@pytest.mark.unit
+class TestTierDecisions:
+    """Test staleness and cold-fallback decisions."""
+
+    def test_last_activity_prefers_most_recent(self):
+        """Last activity is the newer of indexed_at and last hit."""
+        from src.services.search.tiering import TierManager
+
+        assert TierManager.last_activity({"indexed_at": 100.0}, 250.0) == 250.0
+        assert TierManager.last_activity({"indexed_at": 300.0}, 250.0) == 300.0
+        assert TierManager.last_activity({"indexed_at": 100.0}) == 100.0
+
+    def test_last_activity_unknown(self):
+        """Legacy points without timestamps have no activity."""
+        from src.services.search.tiering import TierManager
+
+        assert TierManager.last_activity({}) is None
+
+    def test_is_stale(self):
+        """Chunks idle past the threshold are stale."""
+        from src.services.search.tiering import TierManager
+
+        now = 100 * DAY
+        assert TierManager.is_stale(now - 31 * DAY, 30, now) is True
+        assert TierManager.is_stale(now - 29 * DAY, 30, now) is False
+
+    def test_unknown_activity_never_stale(self):
+        """Points without timestamps are never demoted."""
+        from src.services.search.tiering import TierManager
+
+        assert TierManager.is_stale(None, 30) is False
+
+    def test_should_query_cold(self, monkeypatch):
+        """Cold tier is queried only when enabled and hot results are thin."""
+        from src.services.search import tiering
+
+        values = {"search.tiering.enabled": True, "search.tiering.min_hot_results": 3}
+        monkeypatch.setattr(tiering.settings, "get", lambda key, default=None: values.get(key, default))
+
+        manager = tiering.TierManager(qdrant_client=MagicMock())
+        assert manager.should_query_cold(1, 10) is True
+        assert manager.should_query_cold(3, 10) is False
+        # Small limits lower the threshold
+        assert manager.should_query_cold(1, 1) is False
+
+        values["search.tiering.enabled"] = False
+        assert manager.should_query_cold(0, 10) is False
+
+
+@pytest.mark.unit
+class TestTierMaintenance:
+    """Test demotion moves only stale points."""
+
+    def test_demote_stale_moves_idle_points(self, monkeypatch):
+        """Idle points are copied to cold and deleted from hot."""
+        import time
+        from src.services.search import tiering
+
+        now = time.time()
+        fresh = SimpleNamespace(id="fresh", vector={"dense": [0.1]}, payload={"indexed_at": now})
+        idle = SimpleNamespace(id="idle", vector={"dense": [0.2]}, payload={"indexed_at": now - 60 * DAY})
+
+        qdrant = MagicMock()
+        qdrant.scroll.return_value = ([fresh, idle], None)
+
+        manager = tiering.TierManager(qdrant_client=qdrant)
+        manager._redis = MagicMock()
+        manager._redis.hmget.return_value = [None, None]
+        monkeypatch.setattr(manager, "_tantivy", lambda: None)
+        monkeypatch.setattr(manager, "ensure_cold_collection", lambda: None)
+
+        result = manager.demote_stale()
+
+        assert result == {"scanned": 2, "demoted": 1}
+        upserted = qdrant.upsert.call_args.kwargs["points"]
+        assert [p.id for p in upserted] == ["idle"]
+        assert upserted[0].payload["tier"] == "cold"
+        assert qdrant.upsert.call_args.kwargs["collection_name"] == manager.cold_collection
+
+    def test_recent_hit_keeps_point_hot(self, monkeypatch):
+        """A recent search match overrides an old index time."""
+        import time
+        from src.services.search import tiering
+
+        now = time.time()
+        old = SimpleNamespace(id="old", vector={}, payload={"indexed_at": now - 60 * DAY})
+
+        qdrant = MagicMock()
+        qdrant.scroll.return_value = ([old], None)
+
+        manager = tiering.TierManager(qdrant_client=qdrant)
+        manager._redis = MagicMock()
+        manager._redis.hmget.return_value = [str(now - DAY)]
+        monkeypatch.setattr(manager, "_tantivy", lambda: None)
+        monkeypatch.setattr(manager, "ensure_cold_collection", lambda: None)
+
+        assert manager.demote_stale() == {"scanned": 1, "demoted": 0}
+        qdrant.upsert.assert_not_called()
+
+
+@pytest.mark.unit
+class TestColdFallback:
+    """Test cold results are merged into thin hot results."""
+
+    def test_cold_results_merged_and_promoted(self, monkeypatch):
+        """New cold hits are appended, tagged and queued for promotion."""
+        import asyncio
+        from src.services.search import tiering
+        from src.services.search.retriever import MultiRetriever
+
+        manager = MagicMock()
+        monkeypatch.setattr(tiering, "get_tier_manager", lambda: manager)
+
+        async def cold_splade(query, qdrant, limit, search_filter, collection, encoded):
+            assert collection == "docs_cold"
+            return [
+                {"chunk_id": "hot-1", "score": 0.9, "full_path": "a.py"},
+                {"chunk_id": "cold-1", "score": 0.8, "full_path": "b.py"},
+            ]
+
+        retriever = MultiRetriever()
+        monkeypatch.setattr(retriever, "_search_splade", cold_splade)
+        hot = [{"chunk_id": "hot-1", "score": 1.0, "full_path": "a.py"}]
+
+        merged = asyncio.run(retriever._search_cold_tier(
+            "retry", MagicMock(), hot, 5, None, True, False, 60, collection_name="docs"
+        ))
+
+        assert [r["chunk_id"] for r in merged] == ["hot-1", "cold-1"]
+        assert merged[1]["tier"] == "cold"
+        manager.queue_promotion.assert_called_once_with(["cold-1"])
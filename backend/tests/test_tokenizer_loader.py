@@ -0,0 +1,73 @@
+"""
+Tests for tokenizer format detection and fallback loading.
+"""
+import json
+from unittest.mock import MagicMock, patch
+
+import pytest
+
+from src.services.inference.tokenizer_loader import detect_format, load_tokenizer, tokenizer_args
+
+
+def _model_dir(tmp_path, *names):
+    for name in names:
+        (tmp_path / name).write_text("{}")
+    return str(tmp_path)
+
+
+def test_detects_formats(tmp_path):
+    assert detect_format(_model_dir(tmp_path, "config.json")) is None
+
+    fast = tmp_path / "fast"
+    fast.mkdir()
+    assert detect_format(_model_dir(fast, "tokenizer.json", "sentencepiece.bpe.model")) == "tokenizer.json"
+
+    spm = tmp_path / "spm"
+    spm.mkdir()
+    assert detect_format(_model_dir(spm, "spiece.model", "config.json")) == "sentencepiece"
+
+    bpe = tmp_path / "bpe"
+    bpe.mkdir()
+    assert detect_format(_model_dir(bpe, "vocab.json", "merges.txt")) == "bpe"
+
+    wordpiece = tmp_path / "wordpiece"
+    wordpiece.mkdir()
+    assert detect_format(_model_dir(wordpiece, "vocab.txt")) == "wordpiece"
+
+
+def test_hub_ids_are_not_inspected():
+    assert detect_format("BAAI/bge-reranker-base") is None
+    assert tokenizer_args("BAAI/bge-reranker-base") == {}
+
+
+def test_sentencepiece_uses_slow_tokenizer(tmp_path):
+    path = _model_dir(tmp_path, "sentencepiece.bpe.model")
+    assert tokenizer_args(path) == {"use_fast": False}
+
+    with patch("transformers.AutoTokenizer.from_pretrained", return_value="tok") as auto:
+        assert load_tokenizer(path) == "tok"
+    assert auto.call_args.kwargs["use_fast"] is False
+
+
+def test_falls_back_to_family_tokenizer(tmp_path):
+    path = _model_dir(tmp_path, "spiece.model")
+    (tmp_path / "special_tokens_map.json").write_text(json.dumps({
+        "eos_token": {"content": "</s>", "lstrip": False},
+        "pad_token": "<pad>",
+        "additional_special_tokens": ["<extra>"],
+    }))
+    family = MagicMock(return_value="spm")
+
+    with patch("transformers.AutoTokenizer.from_pretrained", side_effect=ValueError("no config")), \
+         patch("transformers.XLMRobertaTokenizer", family, create=True):
+        assert load_tokenizer(path) == "spm"
+    family.assert_called_once_with(
+        vocab_file=str(tmp_path / "spiece.model"), eos_token="</s>", pad_token="<pad>"
+    )
+
+
+def test_tokenizer_json_errors_are_not_masked(tmp_path):
+    path = _model_dir(tmp_path, "tokenizer.json")
+    with patch("transformers.AutoTokenizer.from_pretrained", side_effect=OSError("corrupt")):
+        with pytest.raises(OSError):
+            load_tokenizer(path)
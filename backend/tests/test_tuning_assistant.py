@@ -0,0 +1,97 @@
+"""
+Unit tests for the search quality tuning assistant.
+"""
+import asyncio
+import pytest
+from unittest.mock import MagicMock
+
+
+GRID = {
+    "search.tuning_assistant.sparse_shares": [0.3, 0.5, 0.7],
+    "search.tuning_assistant.rrf_k": [60],
+    "search.tuning_assistant.rerank": [False, True],
+}
+
+QUERIES = [{"query": "mutex", "relevant": ["sync/mutex.go"]}]
+
+
+def _assistant(monkeypatch, stores=None):
+    from src.services.search import tuning_assistant
+
+    monkeypatch.setattr(tuning_assistant.settings, "get", lambda key, default=None: GRID.get(key, default))
+    admin_store = MagicMock()
+    admin_store.get_store_search_config.return_value = {}
+    admin_store.set_store_search_config.return_value = True
+    tuner = MagicMock(enabled=False)
+    return tuning_assistant.TuningAssistant(admin_store=admin_store, tuner=tuner)
+
+
+async def _sparse_friendly_search(query, limit, org_id, weights=None, **kwargs):
+    """The relevant file only ranks first when sparse retrievers dominate."""
+    files = ["sync/waitgroup.go", "sync/mutex.go"]
+    if weights and weights["bm25"] > weights["bm42"]:
+        files.reverse()
+    return [{"full_path": f} for f in files]
+
+
+@pytest.mark.unit
+class TestTuningAssistant:
+    """Test grid search and applying the best config."""
+
+    def test_weights_for_share(self):
+        from src.services.search.tuning_assistant import weights_for_share
+
+        assert weights_for_share(0.7) == {"bm25": 1.4, "splade": 1.4, "bm25_sparse": 1.4, "bm42": 0.6}
+        assert weights_for_share(0.5) == {"bm25": 1.0, "splade": 1.0, "bm25_sparse": 1.0, "bm42": 1.0}
+
+    def test_grid_from_settings(self, monkeypatch):
+        from src.services.search import tuning_assistant
+
+        _assistant(monkeypatch)
+        grid = tuning_assistant.candidate_grid()
+
+        assert len(grid) == 6
+        assert {"sparse_share": 0.7, "rrf_k": 60, "rerank": True} in grid
+
+    def test_proposes_best_config(self, monkeypatch):
+        """Picks the sparse-heavy share, preferring no rerank on ties."""
+        assistant = _assistant(monkeypatch)
+
+        proposal = asyncio.run(assistant.propose("demo", QUERIES, k=5, search=_sparse_friendly_search))
+
+        assert proposal["metric"] == "ndcg@5"
+        assert proposal["best"]["config"] == {"sparse_share": 0.7, "rrf_k": 60, "rerank": False}
+        assert proposal["best"]["metrics"]["mrr"] == 1.0
+        assert proposal["baseline"]["metrics"]["mrr"] == 0.5
+        assert proposal["improvement"] > 0
+        assert len(proposal["candidates"]) == 6
+
+    def test_unknown_metric(self, monkeypatch):
+        assistant = _assistant(monkeypatch)
+
+        with pytest.raises(ValueError):
+            asyncio.run(assistant.propose("demo", QUERIES, metric="precision", search=_sparse_friendly_search))
+
+    def test_apply_saves_store_config(self, monkeypatch):
+        """Applied config is stored on the store and seeds the click tuner."""
+        assistant = _assistant(monkeypatch)
+        assistant.tuner.enabled = True
+
+        saved = assistant.apply("demo", {"sparse_share": 0.7, "rrf_k": 30, "rerank": True})
+
+        assert saved["weights"]["bm25"] == 1.4
+        assert saved["rrf_k"] == 30 and saved["rerank"] is True
+        assistant.admin_store.set_store_search_config.assert_called_once_with("demo", saved)
+        assistant.tuner.set_weights.assert_called_once_with("demo", 1.4, 0.6)
+
+
+@pytest.mark.unit
+def test_tuned_weights_fall_back_to_store_default():
+    """Untuned stores use the applied weights instead of 1.0."""
+    import redis
+    from src.services.search.fusion_tuning import FusionTuner
+
+    tuner = FusionTuner(redis_client=redis.Redis())
+    weights = tuner.get_weights("fresh-store", default={"bm25": 1.4, "splade": 1.4, "bm25_sparse": 1.4, "bm42": 0.6})
+
+    assert weights == {"bm25": 1.4, "splade": 1.4, "bm25_sparse": 1.4, "bm42": 0.6}
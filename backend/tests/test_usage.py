@@ -0,0 +1,92 @@
+"""
+Tests for per-key resource usage accounting and the monthly export.
+"""
+import asyncio
+
+from src.services.admin import usage
+from src.services.admin.usage import UsageMeter, record_usage, report_csv, usage_scope
+
+
+def _meter(monkeypatch, redis, values=None):
+    values = values or {}
+    monkeypatch.setattr(usage.settings, "get", lambda key, d=None: values.get(key, d))
+    meter = UsageMeter(redis_client=redis)
+    monkeypatch.setattr(usage, "get_usage_meter", lambda: meter)
+    monkeypatch.setattr(usage, "current_month", lambda at=None: "2026-10")
+    return meter
+
+
+def test_usage_is_attributed_to_the_scope(monkeypatch, fake_redis):
+    meter = _meter(monkeypatch, fake_redis)
+
+    record_usage("qdrant_reads", 5)  # outside a scope: ignored
+    with usage_scope("alice", "docs"):
+        record_usage("embed_tokens", 12)
+        record_usage("qdrant_reads", 20)
+    with usage_scope("alice", "docs"):
+        record_usage("rerank_pairs", 8)
+    with usage_scope(None, None):
+        record_usage("storage_bytes", 1024)
+
+    report = meter.report("2026-10")
+    assert report["rows"] == [
+        {"key": "alice", "store": "docs", "requests": 2, "embed_tokens": 12,
+         "rerank_pairs": 8, "qdrant_reads": 20, "storage_bytes": 0},
+        {"key": "anonymous", "store": "public", "requests": 1, "embed_tokens": 0,
+         "rerank_pairs": 0, "qdrant_reads": 0, "storage_bytes": 1024},
+    ]
+    assert report["totals"]["requests"] == 3
+    assert report["keys"]["alice"]["qdrant_reads"] == 20
+    assert meter.redis.expiry["rice:usage:2026-10"] == 13 * 31 * 86400
+
+
+def test_scope_is_inherited_by_tasks(monkeypatch, fake_redis):
+    meter = _meter(monkeypatch, fake_redis)
+
+    async def search():
+        async def read():
+            record_usage("qdrant_reads", 3)
+        with usage_scope("bob", "code"):
+            await asyncio.gather(read(), asyncio.to_thread(record_usage, "qdrant_reads", 4))
+
+    asyncio.run(search())
+    assert meter.report("2026-10")["totals"]["qdrant_reads"] == 7
+
+
+def test_disabled_accounting_records_nothing(monkeypatch, fake_redis):
+    meter = _meter(monkeypatch, fake_redis, {"usage.enabled": False})
+    with usage_scope("alice", "docs"):
+        record_usage("embed_tokens", 12)
+    assert meter.report("2026-10")["rows"] == []
+
+
+def test_report_filters_by_key_and_exports_csv(monkeypatch, fake_redis):
+    meter = _meter(monkeypatch, fake_redis)
+    meter.add("alice", "docs", "embed_tokens", 10, month="2026-09")
+    meter.add("bob", "docs", "embed_tokens", 99, month="2026-09")
+    meter.add("alice", "docs", "requests", 1)
+
+    report = meter.report("2026-09", key="alice")
+    assert [r["key"] for r in report["rows"]] == ["alice"]
+    assert report_csv(report).splitlines() == [
+        "month,key,store,requests,embed_tokens,rerank_pairs,qdrant_reads,storage_bytes",
+        "2026-09,alice,docs,0,10,0,0,0",
+    ]
+    assert meter.months() == ["2026-10", "2026-09"]
+
+
+def test_endpoint_usage_lands_under_the_token_subject(monkeypatch, fake_redis):
+    from src.api.v1.endpoints import embeddings
+
+    meter = _meter(monkeypatch, fake_redis)
+
+    async def create_embeddings(texts, **kwargs):
+        return {"data": [], "usage": {"prompt_tokens": 6, "total_tokens": 6}}
+
+    monkeypatch.setattr(embeddings, "create_embeddings", create_embeddings)
+    request = embeddings.EmbeddingRequest(input=["hello"])
+    # Keycloak tokens carry the caller in "sub", not "id"
+    asyncio.run(embeddings.create_embedding(request, user={"sub": "alice", "org_id": "public"}))
+
+    rows = meter.report("2026-10")["rows"]
+    assert [(r["key"], r["store"], r["embed_tokens"]) for r in rows] == [("alice", "public", 6)]
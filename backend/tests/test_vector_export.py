@@ -0,0 +1,71 @@
+"""
+Tests for streaming vector exports.
+"""
+import json
+from types import SimpleNamespace
+
+import pytest
+
+from src.services.admin import vector_export
+from src.services.admin.vector_export import VectorExporter, serialize_vector
+
+
+def _sparse(indices, values):
+    return SimpleNamespace(indices=indices, values=values)
+
+
+def _point(n, store="docs"):
+    return SimpleNamespace(
+        id=n,
+        payload={"org_id": store, "full_path": f"/{n}.py"},
+        vector={"dense": [0.5, 0.25], "splade": _sparse([3, 9], [1.0, 0.5])},
+    )
+
+
+@pytest.fixture
+def exporter(monkeypatch, fake_qdrant):
+    config = {"exports.batch_size": 2}
+    monkeypatch.setattr(vector_export.settings, "get", lambda key, default=None: config.get(key, default))
+    fake_qdrant.collections = {"rice_chunks": [_point(1), _point(2), _point(3)], "rice_chunks_cold": [_point(4)]}
+    exporter = VectorExporter(qdrant_client=fake_qdrant)
+    monkeypatch.setattr(exporter, "collections", lambda org_id=None: ["rice_chunks", "rice_chunks_cold"])
+    return exporter
+
+
+def test_serialize_vector():
+    assert serialize_vector(_sparse((1, 2), (0.5, 0.25))) == {"indices": [1, 2], "values": [0.5, 0.25]}
+    assert serialize_vector((0.5, 0.25)) == [0.5, 0.25]
+    assert serialize_vector(None) is None
+
+
+def test_ndjson_pages_through_hot_and_cold_collections(exporter):
+    lines = [json.loads(line) for line in exporter.ndjson("docs", ["dense", "splade", "bm42"])]
+
+    assert [line["id"] for line in lines] == ["1", "2", "3", "4"]
+    assert lines[0] == {
+        "id": "1",
+        "payload": {"org_id": "docs", "full_path": "/1.py"},
+        "vectors": {"dense": [0.5, 0.25], "splade": {"indices": [3, 9], "values": [1.0, 0.5]}, "bm42": None},
+    }
+    assert [(s.collection, s.offset) for s in exporter.qdrant.scrolls] == [
+        ("rice_chunks", None), ("rice_chunks", 2), ("rice_chunks_cold", None),
+    ]
+
+
+def test_limit_and_payload_or_vectors_left_out(exporter):
+    records = list(exporter.iter_points("docs", [], include_payload=False, limit=3))
+    assert records == [{"id": str(n), "vectors": {}} for n in (1, 2, 3)]
+    # Nothing past the limit was fetched, and no vectors were asked for
+    assert [(s.collection, s.with_payload, s.with_vectors) for s in exporter.qdrant.scrolls] == [
+        ("rice_chunks", False, False), ("rice_chunks", False, False),
+    ]
+
+
+def test_arrow_stream_round_trips(exporter):
+    pa = pytest.importorskip("pyarrow")
+
+    data = b"".join(exporter.arrow("docs", ["dense", "splade"]))
+    table = pa.ipc.open_stream(data).read_all()
+    assert table.column("id").to_pylist() == ["1", "2", "3", "4"]
+    assert table.column("splade_indices").to_pylist()[0] == [3, 9]
+    assert json.loads(table.column("payload").to_pylist()[3])["full_path"] == "/4.py"
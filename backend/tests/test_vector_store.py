@@ -0,0 +1,150 @@
+"""
+Tests for the vector store backends (embedded SQLite store).
+"""
+import pytest
+from qdrant_client.models import (
+    Distance,
+    FieldCondition,
+    Filter,
+    FilterSelector,
+    Fusion,
+    FusionQuery,
+    IsEmptyCondition,
+    MatchAny,
+    MatchValue,
+    PayloadField,
+    PointStruct,
+    Prefetch,
+    Range,
+    SparseVector,
+    SparseVectorParams,
+    VectorParams,
+)
+
+from src.db import vector_store
+from src.db.sqlite_store import SqliteStore
+
+
+def _org(org_id):
+    return Filter(must=[FieldCondition(key="org_id", match=MatchValue(value=org_id))])
+
+
+@pytest.fixture
+def store(tmp_path):
+    store = SqliteStore(path=str(tmp_path / "vectors.db"))
+    store.create_collection(
+        collection_name="chunks",
+        vectors_config={"dense": VectorParams(size=2, distance=Distance.COSINE)},
+        sparse_vectors_config={"splade": SparseVectorParams()},
+    )
+    points = [
+        ("a", [1.0, 0.0], [1, 2], "docs", {"symbols": ["login"], "indexed_at": 10}),
+        ("b", [0.7, 0.7], [2, 3], "docs", {"symbols": ["logout"], "indexed_at": 20}),
+        ("c", [0.0, 1.0], [4], "docs", {"indexed_at": 30}),
+        ("d", [1.0, 0.1], [1], "other", {"symbols": ["login"], "indexed_at": 40}),
+    ]
+    store.upsert(collection_name="chunks", points=[
+        PointStruct(
+            id=pid,
+            vector={"dense": dense, "splade": SparseVector(indices=terms, values=[1.0] * len(terms))},
+            payload={"org_id": org, "full_path": f"/{pid}.py", **extra},
+        )
+        for pid, dense, terms, org, extra in points
+    ])
+    return store
+
+
+def _ids(points):
+    return [p.id for p in points]
+
+
+def test_dense_search_is_scoped_and_ranked(store):
+    response = store.query_points(
+        collection_name="chunks", query=[2.0, 0.0], using="dense", limit=2, query_filter=_org("docs")
+    )
+    assert _ids(response.points) == ["a", "b"]
+    assert response.points[0].score == pytest.approx(1.0)
+    assert response.points[0].payload["full_path"] == "/a.py"
+
+
+def test_sparse_search_only_returns_overlapping_points(store):
+    response = store.query_points(
+        collection_name="chunks", query=SparseVector(indices=[2], values=[0.5]), using="splade",
+        limit=10, query_filter=_org("docs"), with_payload=False
+    )
+    assert sorted(_ids(response.points)) == ["a", "b"]
+    assert response.points[0].payload is None
+
+
+def test_rrf_fusion_of_prefetches(store):
+    response = store.query_points(
+        collection_name="chunks",
+        prefetch=[
+            Prefetch(query=[0.0, 1.0], using="dense", limit=3, filter=_org("docs")),
+            Prefetch(query=SparseVector(indices=[3, 4], values=[1.0, 1.0]), using="splade", limit=3, filter=_org("docs")),
+        ],
+        query=FusionQuery(fusion=Fusion.RRF),
+        limit=2,
+    )
+    # "c" is first for both the dense and the sparse query
+    assert _ids(response.points)[0] == "c"
+
+
+def test_filters(store):
+    def count(flt):
+        return store.count(collection_name="chunks", count_filter=flt).count
+
+    assert count(None) == 4
+    assert count(Filter(must=[FieldCondition(key="symbols", match=MatchAny(any=["login"]))])) == 2
+    assert count(Filter(must=[FieldCondition(key="indexed_at", range=Range(gte=20, lt=40))])) == 2
+    assert count(Filter(must=[_org("docs"), IsEmptyCondition(is_empty=PayloadField(key="symbols"))])) == 1
+    assert count(Filter(must_not=[FieldCondition(key="org_id", match=MatchValue(value="docs"))])) == 1
+
+
+def test_scroll_pages_and_vectors(store):
+    page, offset = store.scroll(collection_name="chunks", scroll_filter=_org("docs"), limit=2, with_vectors=["splade"])
+    assert _ids(page) == ["a", "b"] and offset == "c"
+    assert page[0].vector["splade"].indices == [1, 2]
+    page, offset = store.scroll(collection_name="chunks", scroll_filter=_org("docs"), limit=2, offset=offset)
+    assert _ids(page) == ["c"] and offset is None
+
+
+def test_payload_updates_and_deletes(store):
+    store.set_payload(collection_name="chunks", payload={"tier": "cold"}, points=["a"])
+    store.delete_payload(collection_name="chunks", keys=["full_path"], points=_org("other"))
+    records = {r.id: r.payload for r in store.retrieve(collection_name="chunks", ids=["a", "d", "missing"])}
+    assert records["a"]["tier"] == "cold"
+    assert "full_path" not in records["d"]
+
+    store.delete(collection_name="chunks", points_selector=FilterSelector(filter=_org("docs")))
+    assert store.count(collection_name="chunks").count == 1
+
+
+def test_facet_and_collection_info(store):
+    hits = store.facet(collection_name="chunks", key="symbols", limit=5).hits
+    assert (hits[0].value, hits[0].count) == ("login", 2)
+
+    store.create_payload_index(collection_name="chunks", field_name="symbols", field_schema="keyword")
+    info = store.get_collection("chunks")
+    assert info.points_count == 4
+    assert info.config.params.vectors["dense"].size == 2
+    assert "symbols" in info.payload_schema
+    assert [c.name for c in store.get_collections().collections] == ["chunks"]
+
+
+def test_wrong_dimension_and_missing_collection(store):
+    with pytest.raises(ValueError, match="dimension"):
+        store.upsert(collection_name="chunks", points=[PointStruct(id="x", vector={"dense": [1.0]}, payload={})])
+    with pytest.raises(ValueError, match="not found"):
+        store.get_collection("missing")
+    assert store.delete_collection("chunks") is True
+    assert store.delete_collection("chunks") is False
+
+
+def test_backend_selection(monkeypatch, tmp_path):
+    monkeypatch.setattr(vector_store.settings, "get", lambda key, default=None: {
+        "infrastructure.vector_store.backend": "sqlite",
+        "infrastructure.vector_store.sqlite.path": str(tmp_path / "v.db"),
+    }.get(key, default))
+    store = vector_store.create_vector_store()
+    assert store.name == "sqlite" and store.path == str(tmp_path / "v.db")
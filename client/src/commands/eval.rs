@@ -0,0 +1,167 @@
+use crate::core::api::ApiClient;
+use crate::core::config::load_config;
+use crate::core::output::Output;
+use anyhow::{Context, Result};
+use colored::*;
+use config::{Config, File, FileFormat};
+use serde::{Deserialize, Serialize};
+use std::collections::BTreeMap;
+
+/// Labeled query set, e.g.
+///
+/// ```yaml
+/// store: backend
+/// k: 10
+/// queries:
+///   - query: where is the jwt validated
+///     relevant: [src/auth/jwt.py]
+///   - query: retry policy
+///     relevant:
+///       src/http/retry.py: 3
+///       docs/retry.md: 1
+/// ```
+#[derive(Debug, Deserialize, Serialize)]
+struct QuerySet {
+    store: Option<String>,
+    k: Option<usize>,
+    queries: Vec<LabeledQuery>,
+}
+
+#[derive(Debug, Deserialize, Serialize)]
+struct LabeledQuery {
+    query: String,
+    relevant: Relevant,
+}
+
+#[derive(Debug, Deserialize, Serialize)]
+#[serde(untagged)]
+enum Relevant {
+    Paths(Vec<String>),
+    Graded(BTreeMap<String, f64>),
+}
+
+pub struct EvalOptions {
+    pub store: Option<String>,
+    pub k: Option<usize>,
+    pub baseline: Option<String>,
+    pub write_baseline: Option<String>,
+    pub tolerance: f64,
+    pub output: Output,
+}
+
+/// Run a labeled query set and exit non-zero if any metric regressed
+/// past the tolerance compared to the baseline file.
+pub async fn run(queries_path: &str, opts: EvalOptions) -> Result<()> {
+    let set: QuerySet = Config::builder()
+        .add_source(File::new(queries_path, FileFormat::Yaml))
+        .build()
+        .and_then(|c| c.try_deserialize())
+        .with_context(|| format!("Failed to read query set {}", queries_path))?;
+
+    let config = load_config()?;
+    let client = ApiClient::new(&config.backend_url);
+
+    let body = serde_json::json!({
+        "queries": set.queries,
+        "store": opts.store.or(set.store),
+        "k": opts.k.or(set.k).unwrap_or(10),
+    });
+    let report = client.eval(&body).await?;
+    let metrics: BTreeMap<String, f64> = serde_json::from_value(report["metrics"].clone())
+        .context("Invalid eval response")?;
+
+    if opts.output.is_json() {
+        opts.output.json(&report)?;
+    } else if opts.output.is_text() {
+        print_report(&report);
+    }
+
+    if let Some(path) = &opts.write_baseline {
+        std::fs::write(path, serde_json::to_string_pretty(&metrics)?)
+            .with_context(|| format!("Failed to write baseline {}", path))?;
+        if opts.output.is_text() {
+            println!("Baseline written to {}", path);
+        }
+    }
+
+    if let Some(path) = &opts.baseline {
+        let raw = std::fs::read_to_string(path)
+            .with_context(|| format!("Failed to read baseline {}", path))?;
+        let baseline: BTreeMap<String, f64> = serde_json::from_str(&raw)?;
+
+        let regressions = compare(&baseline, &metrics, opts.tolerance);
+        if regressions.is_empty() {
+            if opts.output.is_text() {
+                println!("{}", "No regressions against baseline".green());
+            }
+        } else {
+            for (name, before, after) in &regressions {
+                eprintln!(
+                    "{} {}: {:.4} -> {:.4} ({:+.4})",
+                    "REGRESSION".red().bold(),
+                    name,
+                    before,
+                    after,
+                    after - before
+                );
+            }
+            std::process::exit(1);
+        }
+    }
+
+    Ok(())
+}
+
+/// Metrics that dropped by more than `tolerance` (name, baseline, current).
+fn compare(
+    baseline: &BTreeMap<String, f64>,
+    current: &BTreeMap<String, f64>,
+    tolerance: f64,
+) -> Vec<(String, f64, f64)> {
+    baseline
+        .iter()
+        .filter_map(|(name, before)| {
+            let after = current.get(name).copied().unwrap_or(0.0);
+            (after < before - tolerance).then(|| (name.clone(), *before, after))
+        })
+        .collect()
+}
+
+fn print_report(report: &serde_json::Value) {
+    let k = report["k"].as_u64().unwrap_or(10);
+    if let Some(queries) = report["queries"].as_array() {
+        for q in queries {
+            let query = q["query"].as_str().unwrap_or("");
+            let rank = q["first_relevant_rank"]
+                .as_u64()
+                .map(|r| format!("#{}", r))
+                .unwrap_or_else(|| "miss".to_string());
+            let line = format!(
+                "{:<6} ndcg={:.3} recall={:.3}  {}",
+                rank,
+                q["ndcg"].as_f64().unwrap_or(0.0),
+                q["recall"].as_f64().unwrap_or(0.0),
+                query
+            );
+            if q["first_relevant_rank"].is_null() {
+                println!("{}", line.red());
+            } else {
+                println!("{}", line);
+            }
+        }
+        println!();
+    }
+
+    println!(
+        "{} queries against {} (k={})",
+        report["query_count"],
+        report["store"].as_str().unwrap_or("?").magenta(),
+        k
+    );
+    if let Some(metrics) = report["metrics"].as_object() {
+        for (name, value) in metrics {
+            println!("  {:<10} {:.4}", name, value.as_f64().unwrap_or(0.0));
+        }
+    }
+}
+
@@ -0,0 +1,37 @@
+use crate::core::api::ApiClient;
+use crate::core::config::load_config;
+use crate::core::output::Output;
+use anyhow::Result;
+use colored::*;
+
+/// Show backend health; exits 1 unless every component is up.
+pub async fn run(output: Output) -> Result<()> {
+    let config = load_config()?;
+    let client = ApiClient::new(&config.backend_url);
+    let health = client.health().await?;
+    let status = health["status"].as_str().unwrap_or("unknown");
+
+    if output.is_json() {
+        output.json(&health)?;
+    } else if output.is_text() {
+        let label = if status == "ok" {
+            status.green().bold()
+        } else {
+            status.red().bold()
+        };
+        println!("{} {}", config.backend_url, label);
+        for (name, component) in health["components"].as_object().into_iter().flatten() {
+            let state = component["status"].as_str().unwrap_or("unknown");
+            let state = if state == "up" { state.green() } else { state.red() };
+            match component["error"].as_str() {
+                Some(error) => println!("  {:<12} {} ({})", name, state, error),
+                None => println!("  {:<12} {}", name, state),
+            }
+        }
+    }
+
+    if status != "ok" {
+        std::process::exit(1);
+    }
+    Ok(())
+}
@@ -0,0 +1,183 @@
+use crate::core::api::ApiClient;
+use crate::core::config::load_config;
+use crate::core::output::Output;
+use anyhow::Result;
+use colored::*;
+use serde_json::Value;
+
+/// Retrievers in the order they are printed.
+const RETRIEVERS: [&str; 5] = ["bm25", "bm25_sparse", "splade", "dense", "bm42"];
+
+pub struct InspectOptions {
+    pub store: Option<String>,
+    pub path: Option<String>,
+    pub query: Option<String>,
+    pub limit: usize,
+    pub output: Output,
+}
+
+/// Show how a file was chunked, or how a query was parsed and retrieved.
+pub async fn run(opts: InspectOptions) -> Result<()> {
+    let config = load_config()?;
+    let client = ApiClient::new(&config.backend_url);
+
+    let report = match (&opts.query, &opts.path) {
+        (Some(query), _) => {
+            let body = serde_json::json!({
+                "query": query,
+                "store": opts.store,
+                "limit": opts.limit,
+            });
+            client.inspect_query(&body).await?
+        }
+        (None, Some(path)) => {
+            let store = opts.store.as_deref().unwrap_or("public");
+            client.inspect_file(store, path).await?
+        }
+        (None, None) => anyhow::bail!("Give a <STORE> <PATH> to inspect a file, or --query"),
+    };
+
+    if opts.output.is_json() {
+        opts.output.json(&report)?;
+    } else if opts.output.quiet {
+        opts.output.ids(chunk_ids(&report));
+    } else if opts.query.is_some() {
+        print_query(&report);
+    } else {
+        print_file(&report);
+    }
+    Ok(())
+}
+
+/// Chunk IDs of a file, or of every candidate (deduplicated) for a query.
+fn chunk_ids(report: &Value) -> Vec<&str> {
+    let mut ids: Vec<&str> = Vec::new();
+    let lists = match report["candidates"].as_object() {
+        Some(candidates) => candidates.values().collect(),
+        None => vec![&report["chunks"]],
+    };
+    for item in lists.into_iter().filter_map(|l| l.as_array()).flatten() {
+        if let Some(id) = item["chunk_id"].as_str() {
+            if !ids.contains(&id) {
+                ids.push(id);
+            }
+        }
+    }
+    ids
+}
+
+fn print_file(report: &Value) {
+    println!(
+        "{} in {} ({}, {} chunks, ~{} tokens)",
+        report["path"].as_str().unwrap_or("?").cyan(),
+        report["store"].as_str().unwrap_or("?").magenta(),
+        report["language"].as_str().unwrap_or("unknown"),
+        report["chunk_count"],
+        report["tokens"]
+    );
+
+    for chunk in report["chunks"].as_array().into_iter().flatten() {
+        let symbols: Vec<&str> = chunk["symbols"]
+            .as_array()
+            .into_iter()
+            .flatten()
+            .filter_map(|s| s.as_str())
+            .collect();
+        println!(
+            "  {:>5}-{:<5} {:<10} {:>5} tok  {}",
+            chunk["start_line"].as_u64().unwrap_or(0),
+            chunk["end_line"].as_u64().unwrap_or(0),
+            chunk["chunk_type"].as_str().unwrap_or("-"),
+            chunk["tokens"].as_u64().unwrap_or(0),
+            symbols.join(", ").green()
+        );
+        if let Some(vectors) = chunk["vectors"].as_object() {
+            let stats: Vec<String> = vectors
+                .iter()
+                .filter(|(_, v)| !v.is_null())
+                .map(|(name, v)| format_vector(name, v))
+                .collect();
+            println!("               {}", stats.join("  ").dimmed());
+        }
+    }
+
+    if report["truncated"].as_bool().unwrap_or(false) {
+        println!("{}", "(truncated)".yellow());
+    }
+}
+
+fn print_query(report: &Value) {
+    let parsed = &report["parsed"];
+    println!("{} {}", "Query:".bold(), parsed["text"].as_str().unwrap_or(""));
+    if let Some(filters) = parsed["filters"].as_object().filter(|f| !f.is_empty()) {
+        println!("{} {}", "Filters:".bold(), Value::Object(filters.clone()));
+    }
+    println!(
+        "{} {}",
+        "Processed:".bold(),
+        report["processed_query"].as_str().unwrap_or("")
+    );
+
+    let analysis = &report["analysis"];
+    if !analysis.is_null() {
+        let expansions: Vec<&str> = analysis["expansions"]
+            .as_array()
+            .into_iter()
+            .flatten()
+            .filter_map(|s| s.as_str())
+            .collect();
+        println!(
+            "{} {} ({})",
+            "Intent:".bold(),
+            analysis["intent"].as_str().unwrap_or("?"),
+            analysis["path"].as_str().unwrap_or("?")
+        );
+        if !expansions.is_empty() {
+            println!("{} {}", "Expansions:".bold(), expansions.join(", ").green());
+        }
+    }
+
+    if let Some(vectors) = report["query_vectors"].as_object() {
+        let stats: Vec<String> = vectors.iter().map(|(n, v)| format_vector(n, v)).collect();
+        println!("{} {}", "Vectors:".bold(), stats.join("  "));
+    }
+    if let Some(timings) = report["timings_ms"].as_object() {
+        let timings: Vec<String> = timings
+            .iter()
+            .map(|(name, ms)| format!("{}={}ms", name, ms))
+            .collect();
+        println!("{} {}", "Timings:".bold(), timings.join(" ").dimmed());
+    }
+
+    for name in RETRIEVERS {
+        let Some(candidates) = report["candidates"][name].as_array() else {
+            continue;
+        };
+        println!("\n{} ({})", name.magenta().bold(), candidates.len());
+        for c in candidates {
+            println!(
+                "  {:>3}. {:>8.4}  {}:{}",
+                c["rank"].as_u64().unwrap_or(0),
+                c["score"].as_f64().unwrap_or(0.0),
+                c["path"].as_str().unwrap_or("unknown").cyan(),
+                c["start_line"].as_u64().unwrap_or(0)
+            );
+        }
+    }
+
+    if let Some(errors) = report["errors"].as_object().filter(|e| !e.is_empty()) {
+        println!();
+        for (name, error) in errors {
+            eprintln!("{} {}: {}", "ERROR".red().bold(), name, error.as_str().unwrap_or(""));
+        }
+    }
+}
+
+/// `dense dim=768 norm=1.0000` or `splade nnz=112 norm=4.2100`.
+fn format_vector(name: &str, stats: &Value) -> String {
+    let size = match stats.get("dim") {
+        Some(dim) => format!("dim={}", dim),
+        None => format!("nnz={}", stats["nnz"]),
+    };
+    format!("{} {} norm={:.4}", name, size, stats["norm"].as_f64().unwrap_or(0.0))
+}
@@ -1,2 +1,6 @@
+pub mod eval;
+pub mod health;
+pub mod inspect;
 pub mod search;
+pub mod stores;
 pub mod watch;
@@ -1,20 +1,59 @@
 use crate::core::api::ApiClient;
 use crate::core::config::load_config;
+use crate::core::output::Output;
 use anyhow::Result;
 use colored::*;
+use serde_json::{Map, Value};
 
-pub async fn run(query: &str, limit: usize, json: bool) -> Result<()> {
+/// Search filters passed through to the backend.
+pub struct Filters {
+    pub paths: Vec<String>,
+    pub exclude_paths: Vec<String>,
+    pub extensions: Vec<String>,
+    pub modified_since: Option<String>,
+}
+
+impl Filters {
+    fn to_json(&self) -> Map<String, Value> {
+        let mut map = Map::new();
+        if !self.paths.is_empty() {
+            map.insert("paths".into(), self.paths.clone().into());
+        }
+        if !self.exclude_paths.is_empty() {
+            map.insert("exclude_paths".into(), self.exclude_paths.clone().into());
+        }
+        if !self.extensions.is_empty() {
+            map.insert("extensions".into(), self.extensions.clone().into());
+        }
+        if let Some(since) = &self.modified_since {
+            map.insert("modified_since".into(), since.clone().into());
+        }
+        map
+    }
+}
+
+pub async fn run(query: &str, limit: usize, filters: &Filters, output: Output) -> Result<()> {
     let config = load_config()?;
     let client = ApiClient::new(&config.backend_url);
 
-    let result = client.search(query, limit, true).await?;
+    let result = client.search(query, limit, true, filters.to_json()).await?;
 
-    if json {
-        println!("{}", serde_json::to_string_pretty(&result)?);
+    if output.is_json() {
+        return output.json(&result);
+    }
+    if output.quiet {
+        let results = result.get("results").and_then(|v| v.as_array());
+        output.ids(
+            results
+                .into_iter()
+                .flatten()
+                .filter_map(|item| item.get("chunk_id").and_then(|s| s.as_str())),
+        );
         return Ok(());
     }
 
     // Pretty Print
+    print_interpretation(&result);
     if let Some(results) = result.get("results").and_then(|v| v.as_array()) {
         if results.is_empty() {
             println!("No results found.");
@@ -27,7 +66,12 @@ pub async fn run(query: &str, limit: usize, json: bool) -> Result<()> {
                 .and_then(|s| s.as_str())
                 .unwrap_or("unknown");
             let line = item.get("start_line").and_then(|n| n.as_u64()).unwrap_or(0);
-            let snippet = item.get("content").and_then(|s| s.as_str()).unwrap_or("");
+            let stored = item.get("content").or_else(|| item.get("text"));
+            let snippet = match stored.and_then(|s| s.as_str()) {
+                Some(s) if !s.is_empty() => s.to_string(),
+                // Privacy-mode stores return locations only
+                _ => read_local_lines(item).unwrap_or_default(),
+            };
             let score = item.get("score").and_then(|f| f.as_f64()).unwrap_or(0.0);
 
             println!(
@@ -48,3 +92,62 @@ pub async fn run(query: &str, limit: usize, json: bool) -> Result<()> {
 
     Ok(())
 }
+
+/// Shows how the server read inline query syntax (`lang:go path:cmd/ -vendor`).
+fn print_interpretation(result: &Value) {
+    let Some(parsed) = result.get("parsed_query") else {
+        return;
+    };
+    let tokens = parsed.get("tokens").and_then(|v| v.as_array());
+    if tokens.map_or(true, |t| t.is_empty()) {
+        return;
+    }
+    let text = parsed.get("text").and_then(|s| s.as_str()).unwrap_or("");
+    let filters: Vec<String> = tokens
+        .into_iter()
+        .flatten()
+        .filter_map(|t| {
+            let field = t.get("field")?.as_str()?;
+            let value = t.get("value")?.as_str()?;
+            let negated = t.get("negated").and_then(|b| b.as_bool()).unwrap_or(false);
+            Some(format!("{}{}:{}", if negated { "-" } else { "" }, field, value))
+        })
+        .collect();
+    println!(
+        "{} {} {}",
+        "Interpreted as:".dimmed(),
+        text,
+        filters.join(" ").cyan()
+    );
+    for warning in parsed
+        .get("warnings")
+        .and_then(|v| v.as_array())
+        .into_iter()
+        .flatten()
+        .filter_map(|w| w.as_str())
+    {
+        println!("{} {}", "warning:".yellow(), warning);
+    }
+    println!();
+}
+
+/// Reads a result's line range from the local checkout.
+fn read_local_lines(item: &Value) -> Option<String> {
+    let path = item
+        .get("full_path")
+        .or_else(|| item.get("path"))
+        .and_then(|s| s.as_str())?;
+    let start = item.get("start_line").and_then(|n| n.as_u64())? as usize;
+    let end = item
+        .get("end_line")
+        .and_then(|n| n.as_u64())
+        .map(|n| n as usize)
+        .unwrap_or(start);
+    let content = std::fs::read_to_string(path).ok()?;
+    let lines: Vec<&str> = content
+        .lines()
+        .skip(start.saturating_sub(1))
+        .take(end.saturating_sub(start) + 1)
+        .collect();
+    Some(lines.join("\n"))
+}
@@ -0,0 +1,82 @@
+use crate::core::api::ApiClient;
+use crate::core::config::load_config;
+use crate::core::output::Output;
+use anyhow::Result;
+use colored::*;
+use serde_json::Value;
+
+/// List stores, most searched first.
+pub async fn list(output: Output) -> Result<()> {
+    let config = load_config()?;
+    let client = ApiClient::new(&config.backend_url);
+    let stores = client.list_stores().await?;
+    let items = stores.as_array().cloned().unwrap_or_default();
+
+    if output.is_json() {
+        return output.json(&stores);
+    }
+    if output.quiet {
+        output.ids(items.iter().filter_map(|s| s["id"].as_str()));
+        return Ok(());
+    }
+
+    if items.is_empty() {
+        println!("No stores found.");
+    }
+    for store in &items {
+        println!(
+            "{:<20} {:<12} {:>8} searches  {}",
+            store["id"].as_str().unwrap_or("?").magenta(),
+            store["type"].as_str().unwrap_or("-"),
+            store["search_count"].as_u64().unwrap_or(0),
+            store["name"].as_str().unwrap_or("").dimmed()
+        );
+    }
+    Ok(())
+}
+
+/// Show a store's index and usage statistics.
+pub async fn stats(store: &str, output: Output) -> Result<()> {
+    let config = load_config()?;
+    let client = ApiClient::new(&config.backend_url);
+    let stats = client.get_store(store).await?;
+
+    if output.is_json() {
+        return output.json(&stats);
+    }
+    if output.quiet {
+        output.ids(stats["id"].as_str());
+        return Ok(());
+    }
+
+    println!(
+        "{} ({})",
+        stats["id"].as_str().unwrap_or(store).magenta().bold(),
+        stats["name"].as_str().unwrap_or("")
+    );
+    // -1 means the index could not be counted
+    let chunks = match stats["doc_count"].as_i64() {
+        Some(n) if n >= 0 => n.to_string(),
+        _ => "unknown".to_string(),
+    };
+    print_field("type", &stats["type"]);
+    println!("  {:<16} {}", "chunks", chunks);
+    print_field("searches", &stats["search_count"]);
+    print_field("last searched", &stats["last_searched_at"]);
+    print_field("embedding model", &stats["embedding_model"]);
+    print_field("embedding dim", &stats["embedding_dim"]);
+    print_field("sparse backend", &stats["sparse_backend"]);
+    print_field("collection", &stats["collection"]);
+    if stats["privacy_mode"].as_bool().unwrap_or(false) {
+        println!("  {:<16} {}", "privacy mode", "on".yellow());
+    }
+    Ok(())
+}
+
+fn print_field(name: &str, value: &Value) {
+    match value {
+        Value::Null => {}
+        Value::String(s) => println!("  {:<16} {}", name, s),
+        other => println!("  {:<16} {}", name, other),
+    }
+}
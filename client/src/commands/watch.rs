@@ -1,6 +1,6 @@
 use crate::core::api::ApiClient;
 use crate::core::config::load_config;
-use crate::watcher::scanner::Scanner;
+use crate::watcher::scanner::{ScanOptions, Scanner};
 use anyhow::Result;
 use colored::*;
 use notify::{Config, RecommendedWatcher, RecursiveMode, Watcher};
@@ -12,14 +12,15 @@ use std::time::{Duration, Instant};
 
 const DEBOUNCE_DELAY: Duration = Duration::from_secs(3);
 
-pub async fn run(path: &str, org_id: Option<String>, full_index: bool) -> Result<()> {
+pub async fn run(path: &str, org_id: Option<String>, full_index: bool, opts: ScanOptions) -> Result<()> {
     let config = load_config()?;
     let client = ApiClient::new(&config.backend_url);
     
     // Check health before starting
+    let output = opts.output;
     if !client.health_check().await {
         eprintln!("{} Backend at {} seems down or unhealthy.", "Warning:".yellow(), config.backend_url);
-    } else {
+    } else if output.is_text() {
         println!("{} Backend connected successfully.", "✓".green());
     }
 
@@ -28,11 +29,14 @@ pub async fn run(path: &str, org_id: Option<String>, full_index: bool) -> Result
     // Use the path as provided (relative like ".")
     let root_path = Path::new(path);
     
-    let scanner = Scanner::new(ApiClient::new(&config.backend_url), oid.clone());
+    let scanner = Scanner::new(ApiClient::new(&config.backend_url), oid.clone(), opts);
 
     // Initial Scan
     if full_index {
-        scanner.scan(root_path).await;
+        let summary = scanner.scan(root_path).await;
+        if output.is_json() {
+            output.event(&serde_json::json!({ "event": "scan", "summary": summary }));
+        }
     }
 
     // Build Ignore Matcher from the root path (works with relative paths)
@@ -41,7 +45,9 @@ pub async fn run(path: &str, org_id: Option<String>, full_index: bool) -> Result
     builder.add(root_path.join(".riceignore"));
     let ignore_matcher = builder.build().unwrap();
 
-    println!("Starting watcher on: {} (debounce: {}s)", path, DEBOUNCE_DELAY.as_secs());
+    if output.is_text() {
+        println!("Starting watcher on: {} (debounce: {}s)", path, DEBOUNCE_DELAY.as_secs());
+    }
 
     let (tx, rx) = channel();
 
@@ -101,8 +107,25 @@ pub async fn run(path: &str, org_id: Option<String>, full_index: bool) -> Result
                 };
                 let upload_name = clean_path.replace("\\", "/");
 
-                println!("Indexing: {} (hash: {})", upload_name, &hash[..8]);
-                let _ = c.index_file(&abs_path, &upload_name, &o).await;
+                if output.is_text() {
+                    println!("Indexing: {} (hash: {})", upload_name, &hash[..8]);
+                }
+                let result = c.index_file(&abs_path, &upload_name, &o).await;
+                match result {
+                    Ok(_) if output.quiet => println!("{}", upload_name),
+                    Ok(_) if output.is_json() => output.event(&serde_json::json!({
+                        "event": "indexed",
+                        "path": upload_name,
+                        "hash": hash,
+                    })),
+                    Ok(_) => {}
+                    Err(e) if output.is_json() => output.event(&serde_json::json!({
+                        "event": "failed",
+                        "path": upload_name,
+                        "error": format!("{:#}", e),
+                    })),
+                    Err(e) => eprintln!("{} {} ({})", "[ERROR]".red(), upload_name, e),
+                }
             }
         }
     });
@@ -1,7 +1,20 @@
+use crate::core::hashing::HASH_VERSION;
 use anyhow::{Context, Result};
 use reqwest::{multipart, Client};
 use serde_json::Value;
+use log::warn;
 use std::path::Path;
+use std::time::Duration;
+use tokio_util::io::ReaderStream;
+
+/// Files larger than this are streamed from disk instead of buffered.
+const STREAM_THRESHOLD_BYTES: u64 = 8 * 1024 * 1024;
+
+/// Times an upload is resent while the server's index queue is full.
+const MAX_BUSY_RETRIES: u32 = 10;
+
+/// Wait before resending when a 429 carries no Retry-After.
+const DEFAULT_RETRY_AFTER_SECS: u64 = 5;
 
 pub struct ApiClient {
     client: Client,
@@ -28,38 +41,87 @@ impl ApiClient {
         }
     }
 
+    /// Upload a file for indexing.
+    ///
+    /// While the server's index queue is full (429) the upload waits for
+    /// the Retry-After period and is sent again, up to `MAX_BUSY_RETRIES`
+    /// times.
     pub async fn index_file(&self, path: &Path, upload_path: &str, org_id: &str) -> Result<Value> {
-        // Read file content eagerly for simplicity/robustness (<10MB files usually)
-        let content = tokio::fs::read(path).await.context("Failed to read file")?;
-        
-        // Use provided upload_path (relative) as filename
-        let part = multipart::Part::bytes(content).file_name(upload_path.to_string());
-        let form = multipart::Form::new()
-            .part("file", part)
-            .text("org_id", org_id.to_string());
+        let mut attempt = 0;
+        loop {
+            let form = upload_form(path, upload_path, org_id).await?;
+            let resp = self
+                .client
+                .post(format!("{}/api/v1/ingest/file", self.base_url))
+                .multipart(form)
+                .send()
+                .await?;
+
+            if resp.status() == reqwest::StatusCode::TOO_MANY_REQUESTS && attempt < MAX_BUSY_RETRIES {
+                attempt += 1;
+                let wait = retry_after(&resp);
+                warn!(
+                    "Server is busy, retrying {} in {}s ({}/{})",
+                    upload_path, wait, attempt, MAX_BUSY_RETRIES
+                );
+                tokio::time::sleep(Duration::from_secs(wait)).await;
+                continue;
+            }
+            if !resp.status().is_success() {
+                anyhow::bail!("Server returned error: {}", resp.status());
+            }
+
+            let json: Value = resp.json().await?;
+            return Ok(json);
+        }
+    }
+
+    /// Which of the (upload path, normalized hash) pairs the server still
+    /// needs; files it already has with the same hash are left out.
+    pub async fn check_hashes(&self, org_id: &str, files: &[(String, String)]) -> Result<Vec<String>> {
+        let body = serde_json::json!({
+            "org_id": org_id,
+            "hash_version": HASH_VERSION,
+            "files": files
+                .iter()
+                .map(|(path, hash)| serde_json::json!({"path": path, "hash": hash}))
+                .collect::<Vec<_>>(),
+        });
 
         let resp = self
             .client
-            .post(format!("{}/api/v1/ingest/file", self.base_url))
-            .multipart(form)
+            .post(format!("{}/api/v1/ingest/check-hashes", self.base_url))
+            .json(&body)
             .send()
             .await?;
 
         if !resp.status().is_success() {
-            anyhow::bail!("Server returned error: {}", resp.status());
+            anyhow::bail!("Hash check failed: {}", resp.status());
         }
 
         let json: Value = resp.json().await?;
-        Ok(json)
+        let upload = json["upload"]
+            .as_array()
+            .context("Hash check response has no upload list")?;
+        Ok(upload.iter().filter_map(|p| p.as_str().map(String::from)).collect())
     }
 
-    pub async fn search(&self, query: &str, limit: usize, hybrid: bool) -> Result<Value> {
-        let body = serde_json::json!({
+    pub async fn search(
+        &self,
+        query: &str,
+        limit: usize,
+        hybrid: bool,
+        filters: serde_json::Map<String, Value>,
+    ) -> Result<Value> {
+        let mut body = serde_json::json!({
             "query": query,
             "mode": "search",
             "hybrid": hybrid,
             "limit": limit
         });
+        if let Some(map) = body.as_object_mut() {
+            map.extend(filters);
+        }
 
         let resp = self
             .client
@@ -75,4 +137,129 @@ impl ApiClient {
         let json: Value = resp.json().await?;
         Ok(json)
     }
+
+    pub async fn eval(&self, body: &Value) -> Result<Value> {
+        let resp = self
+            .client
+            .post(format!("{}/api/v1/search/eval", self.base_url))
+            .json(body)
+            .send()
+            .await?;
+
+        if !resp.status().is_success() {
+            anyhow::bail!("Eval failed: {}", resp.status());
+        }
+
+        let json: Value = resp.json().await?;
+        Ok(json)
+    }
+
+    pub async fn inspect_file(&self, store: &str, path: &str) -> Result<Value> {
+        let url = reqwest::Url::parse_with_params(
+            &format!("{}/api/v1/stores/{}/inspect", self.base_url, store),
+            &[("path", path)],
+        )?;
+        let resp = self.client.get(url).send().await?;
+
+        if !resp.status().is_success() {
+            anyhow::bail!("Inspect failed: {}", resp.status());
+        }
+
+        let json: Value = resp.json().await?;
+        Ok(json)
+    }
+
+    pub async fn inspect_query(&self, body: &Value) -> Result<Value> {
+        let resp = self
+            .client
+            .post(format!("{}/api/v1/search/inspect", self.base_url))
+            .json(body)
+            .send()
+            .await?;
+
+        if !resp.status().is_success() {
+            anyhow::bail!("Inspect failed: {}", resp.status());
+        }
+
+        let json: Value = resp.json().await?;
+        Ok(json)
+    }
+
+    pub async fn health(&self) -> Result<Value> {
+        let resp = self
+            .client
+            .get(format!("{}/health", self.base_url))
+            .send()
+            .await?;
+
+        if !resp.status().is_success() {
+            anyhow::bail!("Health check failed: {}", resp.status());
+        }
+
+        let json: Value = resp.json().await?;
+        Ok(json)
+    }
+
+    pub async fn list_stores(&self) -> Result<Value> {
+        let resp = self
+            .client
+            .get(format!("{}/api/v1/stores/", self.base_url))
+            .send()
+            .await?;
+
+        if !resp.status().is_success() {
+            anyhow::bail!("Listing stores failed: {}", resp.status());
+        }
+
+        let json: Value = resp.json().await?;
+        Ok(json)
+    }
+
+    pub async fn get_store(&self, store: &str) -> Result<Value> {
+        let resp = self
+            .client
+            .get(format!("{}/api/v1/stores/{}", self.base_url, store))
+            .send()
+            .await?;
+
+        if resp.status() == reqwest::StatusCode::NOT_FOUND {
+            anyhow::bail!("Store not found: {}", store);
+        }
+        if !resp.status().is_success() {
+            anyhow::bail!("Store lookup failed: {}", resp.status());
+        }
+
+        let json: Value = resp.json().await?;
+        Ok(json)
+    }
+}
+
+/// Multipart body for an upload; small files are read eagerly, large ones
+/// streamed in chunks.
+async fn upload_form(path: &Path, upload_path: &str, org_id: &str) -> Result<multipart::Form> {
+    let size = tokio::fs::metadata(path).await.context("Failed to stat file")?.len();
+
+    let part = if size > STREAM_THRESHOLD_BYTES {
+        let file = tokio::fs::File::open(path).await.context("Failed to open file")?;
+        let body = reqwest::Body::wrap_stream(ReaderStream::new(file));
+        multipart::Part::stream_with_length(body, size)
+    } else {
+        let content = tokio::fs::read(path).await.context("Failed to read file")?;
+        multipart::Part::bytes(content)
+    };
+
+    // Use provided upload_path (relative) as filename
+    let part = part.file_name(upload_path.to_string());
+    Ok(multipart::Form::new()
+        .part("file", part)
+        .text("org_id", org_id.to_string()))
+}
+
+/// Seconds from a response's Retry-After header (delta-seconds form).
+fn retry_after(resp: &reqwest::Response) -> u64 {
+    resp.headers()
+        .get(reqwest::header::RETRY_AFTER)
+        .and_then(|v| v.to_str().ok())
+        .and_then(|v| v.trim().parse().ok())
+        .unwrap_or(DEFAULT_RETRY_AFTER_SECS)
 }
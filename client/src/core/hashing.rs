@@ -1,22 +1,56 @@
 use anyhow::Result;
 use sha2::{Digest, Sha256};
-use std::fs::File;
-use std::io::{BufReader, Read};
+use std::fs;
 use std::path::Path;
 
-pub fn compute_file_hash(path: &Path) -> Result<String> {
-    let file = File::open(path)?;
-    let mut reader = BufReader::new(file);
-    let mut hasher = Sha256::new();
-    let mut buffer = [0; 8192];
+/// Normalization version of `compute_file_hash`; must match the server's `HASH_VERSION`.
+pub const HASH_VERSION: u32 = 2;
+
+/// Normalize line endings (CRLF/CR -> LF), strip trailing whitespace from
+/// each line and drop trailing blank lines, so Windows and Unix checkouts of
+/// the same file hash identically. Binary content (any NUL byte) is returned
+/// unchanged. Keep in sync with the server's `hash_version` 2 normalization.
+pub fn normalize_content(data: &[u8]) -> Vec<u8> {
+    if data.contains(&0) {
+        return data.to_vec();
+    }
 
-    loop {
-        let count = reader.read(&mut buffer)?;
-        if count == 0 {
-            break;
+    let mut out = Vec::with_capacity(data.len());
+    let mut line: Vec<u8> = Vec::new();
+    let mut i = 0;
+    while i < data.len() {
+        match data[i] {
+            b'\r' | b'\n' => {
+                if data[i] == b'\r' && data.get(i + 1) == Some(&b'\n') {
+                    i += 1;
+                }
+                push_trimmed(&mut out, &line);
+                out.push(b'\n');
+                line.clear();
+            }
+            b => line.push(b),
         }
-        hasher.update(&buffer[..count]);
+        i += 1;
     }
+    push_trimmed(&mut out, &line);
 
+    while out.last() == Some(&b'\n') {
+        out.pop();
+    }
+    out
+}
+
+fn push_trimmed(out: &mut Vec<u8>, line: &[u8]) {
+    let end = line
+        .iter()
+        .rposition(|b| !matches!(b, b' ' | b'\t' | 0x0b | 0x0c))
+        .map_or(0, |p| p + 1);
+    out.extend_from_slice(&line[..end]);
+}
+
+pub fn compute_file_hash(path: &Path) -> Result<String> {
+    let data = fs::read(path)?;
+    let mut hasher = Sha256::new();
+    hasher.update(normalize_content(&data));
     Ok(hex::encode(hasher.finalize()))
 }
@@ -0,0 +1,80 @@
+use clap::ValueEnum;
+use colored::*;
+use serde_json::Value;
+use std::fmt::Display;
+
+/// Output format selected with the global `--format` flag.
+///
+/// JSON output is a contract for scripts (schemas in docs/cli.md): fields
+/// may be added in later versions but are never renamed or removed.
+#[derive(Clone, Copy, Debug, Default, PartialEq, Eq, ValueEnum)]
+pub enum Format {
+    /// Human-readable, colored output
+    #[default]
+    Text,
+    /// One JSON document (one JSON object per line for `watch`)
+    Json,
+}
+
+/// How a command reports its results.
+#[derive(Clone, Copy, Debug, Default)]
+pub struct Output {
+    pub format: Format,
+    /// Print only identifiers, one per line (takes precedence over `format`)
+    pub quiet: bool,
+}
+
+impl Output {
+    /// Human-readable output: neither JSON nor quiet.
+    pub fn is_text(&self) -> bool {
+        self.format == Format::Text && !self.quiet
+    }
+
+    /// Full JSON documents.
+    pub fn is_json(&self) -> bool {
+        self.format == Format::Json && !self.quiet
+    }
+
+    /// Apply a command's `--json` shorthand for `--format json`.
+    pub fn with_json(self, json: bool) -> Self {
+        if json {
+            Self {
+                format: Format::Json,
+                ..self
+            }
+        } else {
+            self
+        }
+    }
+
+    /// A complete JSON document.
+    pub fn json(&self, value: &Value) -> anyhow::Result<()> {
+        println!("{}", serde_json::to_string_pretty(value)?);
+        Ok(())
+    }
+
+    /// A single-line JSON object, for commands that stream events.
+    pub fn event(&self, value: &Value) {
+        println!("{}", value);
+    }
+
+    /// Identifiers for `--quiet`, one per line.
+    pub fn ids<I, T>(&self, ids: I)
+    where
+        I: IntoIterator<Item = T>,
+        T: Display,
+    {
+        for id in ids {
+            println!("{}", id);
+        }
+    }
+
+    /// A failed command, on stderr (`{"error": "..."}` in JSON mode).
+    pub fn error(&self, e: &anyhow::Error) {
+        if self.format == Format::Json {
+            eprintln!("{}", serde_json::json!({ "error": format!("{:#}", e) }));
+        } else {
+            eprintln!("{} {:#}", "Error:".red().bold(), e);
+        }
+    }
+}
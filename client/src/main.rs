@@ -3,8 +3,9 @@ mod core;
 mod watcher;
 
 use anyhow::Result;
-use clap::{Parser, Subcommand};
-use commands::{search, watch};
+use clap::{CommandFactory, Parser, Subcommand};
+use commands::{eval, health, inspect, search, stores, watch};
+use crate::core::output::{Format, Output};
 
 #[derive(Parser)]
 #[command(name = "ricesearch")]
@@ -12,6 +13,14 @@ use commands::{search, watch};
 struct Cli {
     #[command(subcommand)]
     command: Commands,
+
+    /// Output format; JSON output is stable for scripts (see docs/cli.md)
+    #[arg(long, value_enum, global = true, default_value_t = Format::Text)]
+    format: Format,
+
+    /// Print only IDs (chunk IDs, indexed file names, store IDs), one per line
+    #[arg(long, global = true, default_value_t = false)]
+    quiet: bool,
 }
 
 #[derive(Subcommand)]
@@ -29,18 +38,95 @@ enum Commands {
         /// Perform full initial index
         #[arg(long, short = 'f', default_value_t = false)]
         full_index: bool,
+
+        /// Files walked and uploaded in parallel during the initial index (default: CPU cores, max 8)
+        #[arg(short, long)]
+        jobs: Option<usize>,
+
+        /// Upload every file in the initial index, even if the server has it unchanged
+        #[arg(long, default_value_t = false)]
+        force: bool,
     },
 
     /// Search indexed code
     Search {
-        /// Search query
+        /// Search query; inline filters are parsed by the server,
+        /// e.g. 'lang:go path:cmd/ symbol:NewServer "exact phrase" -vendor'
         query: String,
 
         /// Limit results
         #[arg(short, long, default_value_t = 10)]
         limit: usize,
 
-        /// Output as JSON
+        /// Path glob to include, e.g. "src/**" (repeatable)
+        #[arg(short, long)]
+        path: Vec<String>,
+
+        /// Path glob to exclude, e.g. "**/test/**" (repeatable)
+        #[arg(short = 'x', long)]
+        exclude_path: Vec<String>,
+
+        /// File extension to include, e.g. "go" (repeatable)
+        #[arg(long)]
+        ext: Vec<String>,
+
+        /// Only files indexed since (ISO 8601 or epoch seconds)
+        #[arg(long)]
+        since: Option<String>,
+
+        /// Output as JSON (same as --format json)
+        #[arg(long, default_value_t = false)]
+        json: bool,
+    },
+
+    /// Evaluate relevance against a labeled query set (exits 1 on regression)
+    Eval {
+        /// YAML file with labeled queries
+        queries: String,
+
+        /// Store to evaluate (overrides the file)
+        #[arg(short, long)]
+        store: Option<String>,
+
+        /// Cutoff for NDCG and recall (overrides the file)
+        #[arg(short, long)]
+        k: Option<usize>,
+
+        /// Baseline metrics file to compare against
+        #[arg(long)]
+        baseline: Option<String>,
+
+        /// Write the current metrics as a new baseline
+        #[arg(long)]
+        write_baseline: Option<String>,
+
+        /// Allowed drop per metric before failing
+        #[arg(long, default_value_t = 0.01)]
+        tolerance: f64,
+
+        /// Output full report as JSON (same as --format json)
+        #[arg(long, default_value_t = false)]
+        json: bool,
+    },
+
+    /// Show a file's chunks, or how a query is parsed and retrieved
+    #[command(alias = "chunks")]
+    Inspect {
+        /// Store to inspect (default: public for files, your org for queries)
+        store: Option<String>,
+
+        /// Indexed file path, as shown in search results
+        path: Option<String>,
+
+        /// Inspect a query instead of a file
+        #[arg(short, long)]
+        query: Option<String>,
+
+        /// Candidates shown per retriever
+        #[arg(short, long, default_value_t = 10)]
+        limit: usize,
+
+        /// Output as JSON (same as --format json)
         #[arg(long, default_value_t = false)]
         json: bool,
     },
@@ -50,6 +136,30 @@ enum Commands {
         /// Directory to index
         #[arg(default_value = ".")]
         path: String,
+
+        /// Files walked and uploaded in parallel (default: CPU cores, max 8)
+        #[arg(short, long)]
+        jobs: Option<usize>,
+
+        /// Upload every file, even if the server has it unchanged
+        #[arg(long, default_value_t = false)]
+        force: bool,
+    },
+
+    /// Show backend health (exits 1 unless every component is up)
+    Health,
+
+    /// List stores and show their statistics
+    Stores {
+        #[command(subcommand)]
+        action: StoresAction,
+    },
+
+    /// Print a shell completion script, e.g. `ricesearch completions bash > /etc/bash_completion.d/ricesearch`
+    Completions {
+        /// Shell to generate completions for
+        #[arg(value_enum)]
+        shell: clap_complete::Shell,
     },
 
     /// Manage configuration
@@ -59,6 +169,17 @@ enum Commands {
     },
 }
 
+#[derive(Subcommand)]
+enum StoresAction {
+    /// List stores, most searched first
+    List,
+    /// Show a store's chunk count, usage and index configuration
+    Stats {
+        /// Store ID
+        store: String,
+    },
+}
+
 #[derive(Subcommand)]
 enum ConfigAction {
     /// Show current configuration
@@ -68,40 +189,135 @@ enum ConfigAction {
 }
 
 #[tokio::main]
-async fn main() -> Result<()> {
+async fn main() {
+    let cli = Cli::parse();
+    let output = Output {
+        format: cli.format,
+        quiet: cli.quiet,
+    };
+
     // env_logger::init(); // Ensure not initialized twice if we move it or use another logger config
     if std::env::var("RUST_LOG").is_err() {
-        std::env::set_var("RUST_LOG", "info");
+        // Keep stderr quiet for scripts
+        let level = if output.is_text() { "info" } else { "warn" };
+        std::env::set_var("RUST_LOG", level);
     }
     env_logger::init();
 
-    let cli = Cli::parse();
+    if let Err(e) = run(&cli, output).await {
+        output.error(&e);
+        std::process::exit(1);
+    }
+}
 
+async fn run(cli: &Cli, output: Output) -> Result<()> {
     match &cli.command {
         Commands::Watch {
             path,
             org_id,
             full_index,
+            jobs,
+            force,
+        } => {
+            let opts = watcher::scanner::ScanOptions {
+                jobs: jobs.unwrap_or_else(watcher::scanner::default_jobs),
+                force: *force,
+                output,
+            };
+            watch::run(path, org_id.clone(), *full_index, opts).await?;
+        }
+        Commands::Search {
+            query,
+            limit,
+            path,
+            exclude_path,
+            ext,
+            since,
+            json,
         } => {
-            watch::run(path, org_id.clone(), *full_index).await?;
+            let filters = search::Filters {
+                paths: path.clone(),
+                exclude_paths: exclude_path.clone(),
+                extensions: ext.clone(),
+                modified_since: since.clone(),
+            };
+            search::run(query, *limit, &filters, output.with_json(*json)).await?;
         }
-        Commands::Search { query, limit, json } => {
-            search::run(query, *limit, *json).await?;
+        Commands::Eval {
+            queries,
+            store,
+            k,
+            baseline,
+            write_baseline,
+            tolerance,
+            json,
+        } => {
+            eval::run(
+                queries,
+                eval::EvalOptions {
+                    store: store.clone(),
+                    k: *k,
+                    baseline: baseline.clone(),
+                    write_baseline: write_baseline.clone(),
+                    tolerance: *tolerance,
+                    output: output.with_json(*json),
+                },
+            )
+            .await?;
+        }
+        Commands::Inspect {
+            store,
+            path,
+            query,
+            limit,
+            json,
+        } => {
+            inspect::run(inspect::InspectOptions {
+                store: store.clone(),
+                path: path.clone(),
+                query: query.clone(),
+                limit: *limit,
+                output: output.with_json(*json),
+            })
+            .await?;
         }
-        Commands::Index { path } => {
+        Commands::Index { path, jobs, force } => {
             // Re-use watch logic but exit after initial scan?
             // Or explicit scan function.
             // For MVP re-use logic part or just scan:
             // Let's call the scanner directly for Index
             let config = core::config::load_config()?;
             let client = core::api::ApiClient::new(&config.backend_url);
-            let scanner = watcher::scanner::Scanner::new(client, "public".to_string());
-            scanner.scan(std::path::Path::new(path)).await;
+            let opts = watcher::scanner::ScanOptions {
+                jobs: jobs.unwrap_or_else(watcher::scanner::default_jobs),
+                force: *force,
+                output,
+            };
+            let scanner = watcher::scanner::Scanner::new(client, "public".to_string(), opts);
+            let summary = scanner.scan(std::path::Path::new(path)).await;
+            if output.is_json() {
+                output.json(&serde_json::to_value(&summary)?)?;
+            }
+            if summary.failed > 0 {
+                std::process::exit(1);
+            }
+        }
+        Commands::Health => health::run(output).await?,
+        Commands::Stores { action } => match action {
+            StoresAction::List => stores::list(output).await?,
+            StoresAction::Stats { store } => stores::stats(store, output).await?,
+        },
+        Commands::Completions { shell } => {
+            clap_complete::generate(*shell, &mut Cli::command(), "ricesearch", &mut std::io::stdout());
         }
         Commands::Config { action } => match action {
             ConfigAction::Show => {
                 let c = core::config::load_config()?;
-                println!("{:#?}", c);
+                if output.is_json() {
+                    output.json(&serde_json::to_value(&c)?)?;
+                } else if !output.quiet {
+                    println!("{:#?}", c);
+                }
             }
             ConfigAction::Set { key, value } => {
                 println!("Set {} = {} (Not implemented persistence yet)", key, value)
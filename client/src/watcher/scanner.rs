@@ -1,70 +1,314 @@
 use crate::core::api::ApiClient;
+use crate::core::hashing::compute_file_hash;
+use crate::core::output::Output;
 use colored::*;
-use ignore::WalkBuilder;
+use ignore::{WalkBuilder, WalkState};
 use log::{debug, info, warn};
-use std::path::Path;
+use serde::Serialize;
+use std::collections::HashSet;
+use std::path::{Path, PathBuf};
+use std::sync::atomic::{AtomicUsize, Ordering};
+use std::sync::{Arc, Mutex};
+use std::time::Instant;
+use tokio::sync::Semaphore;
+use tokio::task::JoinSet;
+
+/// Cap for the default job count; more concurrent uploads mostly queue on the server.
+const MAX_DEFAULT_JOBS: usize = 8;
+
+/// Files per hash pre-flight request (the server's default cap is 5000).
+const CHECK_BATCH: usize = 1000;
+
+/// Default parallelism for walking and uploading: available cores, capped.
+pub fn default_jobs() -> usize {
+    std::thread::available_parallelism()
+        .map(|n| n.get())
+        .unwrap_or(1)
+        .min(MAX_DEFAULT_JOBS)
+}
+
+pub struct ScanOptions {
+    /// Files walked, hashed and uploaded in parallel
+    pub jobs: usize,
+    /// Upload every file instead of only those the server reports changed
+    pub force: bool,
+    /// Per-file progress in text mode, uploaded names with `--quiet`
+    pub output: Output,
+}
+
+/// Result of a scan (the `index --format json` document).
+#[derive(Debug, Default, Serialize)]
+pub struct ScanSummary {
+    pub path: String,
+    pub store: String,
+    /// Files found by the walk
+    pub found: usize,
+    /// Skipped because the server already has them unchanged
+    pub unchanged: usize,
+    pub indexed: usize,
+    pub failed: usize,
+    pub errors: Vec<ScanError>,
+    pub took_ms: u64,
+}
+
+#[derive(Debug, Serialize)]
+pub struct ScanError {
+    pub path: String,
+    pub error: String,
+}
 
 pub struct Scanner {
-    client: ApiClient,
-    org_id: String,
+    client: Arc<ApiClient>,
+    org_id: Arc<str>,
+    jobs: usize,
+    force: bool,
+    output: Output,
 }
 
 impl Scanner {
-    pub fn new(client: ApiClient, org_id: String) -> Self {
-        Self { client, org_id }
+    pub fn new(client: ApiClient, org_id: String, opts: ScanOptions) -> Self {
+        Self {
+            client: Arc::new(client),
+            org_id: org_id.into(),
+            jobs: opts.jobs.max(1),
+            force: opts.force,
+            output: opts.output,
+        }
     }
 
-    pub async fn scan(&self, path: &Path) {
+    pub async fn scan(&self, path: &Path) -> ScanSummary {
         // Use the path as provided (relative) - WalkBuilder handles gitignore properly
-        info!("Starting initial scan of: {:?}", path);
-
-        let walker = WalkBuilder::new(path)
-            .hidden(false) 
-            .ignore(true)        // Respect .ignore files
-            .git_ignore(true)    // Respect .gitignore
-            .add_custom_ignore_filename(".riceignore")
-            .filter_entry(|entry| {
-                // Only filter .git explicitly, let gitignore handle the rest
-                entry.file_name() != ".git"
-            })
-            .build();
-
-        for result in walker {
-            match result {
-                Ok(entry) => {
-                    let entry_path = entry.path();
-                    if entry_path.is_file() {
-                        self.process_file(entry_path).await;
+        info!("Starting initial scan of: {:?} ({} jobs)", path, self.jobs);
+        let started = Instant::now();
+        let mut summary = ScanSummary {
+            path: path.to_string_lossy().replace("\\", "/"),
+            store: self.org_id.to_string(),
+            ..Default::default()
+        };
+
+        // The walk is blocking filesystem work; keep it off the runtime threads
+        let root = path.to_path_buf();
+        let jobs = self.jobs;
+        let files = match tokio::task::spawn_blocking(move || walk(&root, jobs)).await {
+            Ok(files) => files,
+            Err(e) => {
+                warn!("Walking {:?} failed: {}", path, e);
+                return summary;
+            }
+        };
+        summary.found = files.len();
+        if self.output.is_text() {
+            println!(
+                "{} {} files found in {:.1}s",
+                "[SCAN]".blue(),
+                files.len(),
+                started.elapsed().as_secs_f64()
+            );
+        }
+
+        let files = if self.force {
+            files
+        } else {
+            self.skip_unchanged(files).await
+        };
+        let total = files.len();
+        summary.unchanged = summary.found.saturating_sub(total);
+
+        // At most `jobs` files are read and uploaded at once
+        let permits = Arc::new(Semaphore::new(self.jobs));
+        let done = Arc::new(AtomicUsize::new(0));
+        let errors = Arc::new(Mutex::new(Vec::new()));
+        let mut tasks = JoinSet::new();
+
+        for file in files {
+            let permit = permits
+                .clone()
+                .acquire_owned()
+                .await
+                .expect("scanner semaphore closed");
+            let client = self.client.clone();
+            let org_id = self.org_id.clone();
+            let done = done.clone();
+            let errors = errors.clone();
+            let output = self.output;
+
+            tasks.spawn(async move {
+                let result = process_file(&client, &org_id, &file).await;
+                drop(permit);
+
+                let n = done.fetch_add(1, Ordering::SeqCst) + 1;
+                let rel_display = file.to_string_lossy().replace("\\", "/");
+                match result {
+                    Ok(name) if output.quiet => println!("{}", name),
+                    Ok(_) if output.is_text() => {
+                        println!("{} [{}/{}] {}", "[OK]".green(), n, total, rel_display)
+                    }
+                    Ok(_) => {}
+                    Err(e) => {
+                        if output.is_text() {
+                            println!("{} [{}/{}] {} ({})", "[ERROR]".red(), n, total, rel_display, e);
+                        }
+                        errors.lock().unwrap().push(ScanError {
+                            path: rel_display,
+                            error: format!("{:#}", e),
+                        });
                     }
                 }
-                Err(err) => warn!("Error walking path: {}", err),
+            });
+        }
+
+        while let Some(result) = tasks.join_next().await {
+            if let Err(e) = result {
+                warn!("Indexing task failed: {}", e);
+            }
+        }
+
+        summary.errors = std::mem::take(&mut *errors.lock().unwrap());
+        summary.failed = summary.errors.len();
+        summary.indexed = total - summary.failed;
+        summary.took_ms = started.elapsed().as_millis() as u64;
+        info!(
+            "Scan complete: {} indexed, {} failed in {:.1}s.",
+            summary.indexed,
+            summary.failed,
+            started.elapsed().as_secs_f64()
+        );
+        summary
+    }
+
+    /// Drop the files the server already has with the same normalized hash.
+    /// If the pre-flight fails (e.g. an older server) every file is uploaded.
+    async fn skip_unchanged(&self, files: Vec<PathBuf>) -> Vec<PathBuf> {
+        let started = Instant::now();
+        let jobs = self.jobs;
+        let hashed = match tokio::task::spawn_blocking(move || hash_files(files, jobs)).await {
+            Ok(hashed) => hashed,
+            Err(e) => {
+                warn!("Hashing failed: {}", e);
+                return Vec::new();
+            }
+        };
+
+        let mut needed: HashSet<String> = HashSet::new();
+        for batch in hashed.chunks(CHECK_BATCH) {
+            let pairs: Vec<(String, String)> = batch
+                .iter()
+                .filter_map(|(_, name, hash)| hash.as_ref().map(|h| (name.clone(), h.clone())))
+                .collect();
+            match self.client.check_hashes(&self.org_id, &pairs).await {
+                Ok(upload) => needed.extend(upload),
+                Err(e) => {
+                    warn!("Hash pre-flight failed, uploading all files: {}", e);
+                    return hashed.into_iter().map(|(path, _, _)| path).collect();
+                }
             }
         }
-        info!("Scan complete.");
+
+        let found = hashed.len();
+        let files: Vec<PathBuf> = hashed
+            .into_iter()
+            // Unreadable files are uploaded so the error is reported
+            .filter(|(_, name, hash)| hash.is_none() || needed.contains(name))
+            .map(|(path, _, _)| path)
+            .collect();
+        if self.output.is_text() {
+            println!(
+                "{} {} new or changed, {} unchanged (checked in {:.1}s)",
+                "[CHECK]".blue(),
+                files.len(),
+                found - files.len(),
+                started.elapsed().as_secs_f64()
+            );
+        }
+        files
+    }
+}
+
+/// (path, upload name, normalized hash) per file, hashed on `jobs` threads.
+fn hash_files(files: Vec<PathBuf>, jobs: usize) -> Vec<(PathBuf, String, Option<String>)> {
+    if files.is_empty() {
+        return Vec::new();
     }
+    let per_thread = files.len().div_ceil(jobs.max(1));
+    std::thread::scope(|scope| {
+        let handles: Vec<_> = files
+            .chunks(per_thread)
+            .map(|part| {
+                scope.spawn(move || {
+                    part.iter()
+                        .map(|path| {
+                            let (_, name) = upload_name(path);
+                            (path.clone(), name, compute_file_hash(path).ok())
+                        })
+                        .collect::<Vec<_>>()
+                })
+            })
+            .collect();
+        handles
+            .into_iter()
+            .flat_map(|handle| handle.join().expect("hashing thread panicked"))
+            .collect()
+    })
+}
 
-    async fn process_file(&self, path: &Path) {
-        // Get relative path for display
-        let rel_display = path.to_string_lossy().replace("\\", "/");
-        debug!("Processing: {}", rel_display);
+/// Absolute path of a file and the name it is uploaded (and indexed) under.
+fn upload_name(path: &Path) -> (PathBuf, String) {
+    // Only resolve to absolute when sending to server
+    let abs_path = std::fs::canonicalize(path).unwrap_or_else(|_| path.to_path_buf());
 
-        // Only resolve to absolute when sending to server
-        let abs_path = std::fs::canonicalize(path).unwrap_or_else(|_| path.to_path_buf());
-        
-        // Clean UNC prefix for server
+    // Clean UNC prefix for server
+    let name = {
         let abs_str = abs_path.to_string_lossy();
         let clean_path = if abs_str.starts_with("\\\\?\\") {
             &abs_str[4..]
         } else {
             &abs_str
         };
-        let upload_name = clean_path.replace("\\", "/");
+        clean_path.replace("\\", "/")
+    };
+    (abs_path, name)
+}
 
-        println!("{} {}", "[INDEXING]".blue(), rel_display);
+/// Files under `root` (honoring .gitignore, .ignore and .riceignore),
+/// walked with `jobs` threads and sorted for a stable upload order.
+fn walk(root: &Path, jobs: usize) -> Vec<PathBuf> {
+    let files = Mutex::new(Vec::new());
 
-        match self.client.index_file(&abs_path, &upload_name, &self.org_id).await {
-            Ok(_) => println!("{} {}", "[OK]".green(), rel_display),
-            Err(e) => println!("{} {} ({})", "[ERROR]".red(), rel_display, e),
-        }
-    }
+    WalkBuilder::new(root)
+        .hidden(false)
+        .ignore(true)        // Respect .ignore files
+        .git_ignore(true)    // Respect .gitignore
+        .add_custom_ignore_filename(".riceignore")
+        .filter_entry(|entry| {
+            // Only filter .git explicitly, let gitignore handle the rest
+            entry.file_name() != ".git"
+        })
+        .threads(jobs)
+        .build_parallel()
+        .run(|| {
+            let files = &files;
+            Box::new(move |result| {
+                match result {
+                    Ok(entry) => {
+                        if entry.path().is_file() {
+                            files.lock().unwrap().push(entry.into_path());
+                        }
+                    }
+                    Err(err) => warn!("Error walking path: {}", err),
+                }
+                WalkState::Continue
+            })
+        });
+
+    let mut files = files.into_inner().unwrap();
+    files.sort();
+    files
+}
+
+/// Upload a file; returns the name it was indexed under.
+async fn process_file(client: &ApiClient, org_id: &str, path: &Path) -> anyhow::Result<String> {
+    debug!("Processing: {}", path.to_string_lossy().replace("\\", "/"));
+    let (abs_path, name) = upload_name(path);
+    client.index_file(&abs_path, &name, org_id).await?;
+    Ok(name)
 }
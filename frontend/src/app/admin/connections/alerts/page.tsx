@@ -0,0 +1,206 @@
+'use client';
+
+import { useState, useEffect } from 'react';
+import Link from 'next/link';
+import { RefreshCw, Bell, ArrowLeft, Send, CheckCircle, XCircle, MinusCircle, AlertTriangle } from 'lucide-react';
+import { api } from '@/lib/api';
+
+interface Alert {
+  timestamp: string;
+  severity: string;
+  source: string;
+  message: string;
+}
+
+interface Sink {
+  name: string;
+  type: string;
+  enabled: boolean;
+  min_severity: string;
+  sources: string[];
+  counts: { delivered: number; failed: number; suppressed: number };
+  last_status: string | null;
+  last_error: string | null;
+  last_attempt: string | null;
+}
+
+interface Delivery {
+  timestamp: string;
+  sink: string;
+  type: string;
+  status: string;
+  severity: string;
+  source: string;
+  message: string;
+  error: string | null;
+}
+
+const SEVERITY_STYLES: Record<string, string> = {
+  info: 'bg-blue-500/10 text-blue-400',
+  warning: 'bg-amber-500/10 text-amber-400',
+  critical: 'bg-red-500/10 text-red-400',
+};
+
+function StatusIcon({ status }: { status: string | null }) {
+  if (status === 'delivered') return <CheckCircle size={16} className="text-green-400" />;
+  if (status === 'failed') return <XCircle size={16} className="text-red-400" />;
+  return <MinusCircle size={16} className="text-slate-500" />;
+}
+
+export default function ConnectionAlertsPage() {
+  const [alerts, setAlerts] = useState<Alert[]>([]);
+  const [sinks, setSinks] = useState<Sink[]>([]);
+  const [deliveries, setDeliveries] = useState<Delivery[]>([]);
+  const [loading, setLoading] = useState(true);
+  const [error, setError] = useState<string | null>(null);
+
+  const fetchAll = async () => {
+    try {
+      setLoading(true);
+      setError(null);
+      const [a, s, d] = await Promise.all([
+        api.listAlerts(50),
+        api.listAlertSinks(),
+        api.listAlertDeliveries(100),
+      ]);
+      setAlerts((a.alerts || []).filter((x: Alert) => x.source.startsWith('connections.')));
+      setSinks(s.sinks || []);
+      setDeliveries(d.deliveries || []);
+    } catch (e) {
+      console.error(e);
+      setError('Failed to load alerts');
+    } finally {
+      setLoading(false);
+    }
+  };
+
+  const sendTest = async () => {
+    try {
+      await api.sendTestAlert('warning');
+      // Delivery happens in the background
+      setTimeout(fetchAll, 1500);
+    } catch (e) {
+      console.error(e);
+      alert('Failed to send test alert');
+    }
+  };
+
+  useEffect(() => {
+    fetchAll();
+  }, []);
+
+  return (
+    <div>
+      <div className="flex items-center justify-between mb-8">
+        <div>
+          <Link href="/admin/connections" className="text-sm text-slate-400 hover:text-white flex items-center gap-1 mb-2">
+            <ArrowLeft size={14} /> Connections
+          </Link>
+          <h1 className="text-3xl font-bold text-white flex items-center gap-3">
+             <Bell className="text-primary" /> Security Alerts
+          </h1>
+          <p className="text-slate-400 mt-1">Connection alerts and their delivery to Slack, webhooks and email.</p>
+        </div>
+        <div className="flex items-center gap-2">
+          <button
+             onClick={sendTest}
+             disabled={sinks.length === 0}
+             className="p-2 bg-slate-800 rounded-lg text-slate-400 hover:text-white transition-colors disabled:opacity-50"
+             title="Send test alert"
+          >
+             <Send size={20} />
+          </button>
+          <button
+             onClick={fetchAll}
+             className="p-2 bg-slate-800 rounded-lg text-slate-400 hover:text-white transition-colors"
+          >
+             <RefreshCw size={20} className={loading ? "animate-spin" : ""} />
+          </button>
+        </div>
+      </div>
+
+      {error && (
+        <div className="mb-6 p-4 bg-red-500/10 border border-red-500/20 rounded-lg text-red-400">
+           {error}
+        </div>
+      )}
+
+      <h2 className="text-lg font-semibold text-white mb-3">Sinks</h2>
+      {sinks.length === 0 && !loading ? (
+        <div className="mb-8 p-6 bg-slate-800/50 rounded-xl border border-dashed border-slate-700 text-slate-400 text-sm">
+          No sinks configured. Add them under <code className="text-slate-300">alerts.sinks</code> in settings.yaml.
+        </div>
+      ) : (
+        <div className="grid gap-3 mb-8">
+          {sinks.map((sink) => (
+            <div key={sink.name} className="bg-slate-800 p-4 rounded-xl border border-slate-700 flex items-center justify-between">
+              <div className="flex items-center gap-3">
+                <StatusIcon status={sink.last_status} />
+                <div>
+                  <h4 className="font-semibold text-white">{sink.name}</h4>
+                  <div className="flex items-center gap-3 text-xs text-slate-400 mt-1">
+                    <span className="bg-slate-700 px-1.5 py-0.5 rounded text-slate-300 font-mono">{sink.type}</span>
+                    <span>&ge; {sink.min_severity}</span>
+                    {sink.sources.length > 0 && <span>{sink.sources.join(', ')}</span>}
+                    {!sink.enabled && <span className="text-slate-500">disabled</span>}
+                    {sink.last_error && <span className="text-red-400 truncate max-w-md">{sink.last_error}</span>}
+                  </div>
+                </div>
+              </div>
+              <div className="flex items-center gap-4 text-xs text-slate-400">
+                <span className="text-green-400">{sink.counts.delivered} delivered</span>
+                <span className="text-red-400">{sink.counts.failed} failed</span>
+                <span>{sink.counts.suppressed} suppressed</span>
+              </div>
+            </div>
+          ))}
+        </div>
+      )}
+
+      <h2 className="text-lg font-semibold text-white mb-3">Recent Deliveries</h2>
+      <div className="mb-8 bg-slate-800 rounded-xl border border-slate-700 overflow-hidden">
+        {deliveries.length === 0 ? (
+          <p className="p-4 text-sm text-slate-500">No deliveries yet.</p>
+        ) : (
+          <table className="w-full text-sm">
+            <tbody>
+              {deliveries.map((d, i) => (
+                <tr key={i} className="border-t border-slate-700 first:border-t-0">
+                  <td className="p-3 w-8"><StatusIcon status={d.status} /></td>
+                  <td className="p-3 text-slate-400 whitespace-nowrap">{new Date(d.timestamp).toLocaleString()}</td>
+                  <td className="p-3 text-white">{d.sink}</td>
+                  <td className="p-3">
+                    <span className={`px-1.5 py-0.5 rounded text-xs ${SEVERITY_STYLES[d.severity] || SEVERITY_STYLES.info}`}>{d.severity}</span>
+                  </td>
+                  <td className="p-3 text-slate-300">
+                    {d.message}
+                    {d.error && <div className="text-xs text-red-400 mt-1">{d.error}</div>}
+                  </td>
+                </tr>
+              ))}
+            </tbody>
+          </table>
+        )}
+      </div>
+
+      <h2 className="text-lg font-semibold text-white mb-3">Connection Alerts</h2>
+      {alerts.length === 0 && !loading ? (
+        <div className="text-center py-12 bg-slate-800/50 rounded-xl border border-dashed border-slate-700">
+           <AlertTriangle size={36} className="mx-auto text-slate-600 mb-3" />
+           <p className="text-slate-500">No connection security alerts.</p>
+        </div>
+      ) : (
+        <div className="grid gap-2">
+          {alerts.map((a, i) => (
+            <div key={i} className="bg-slate-800 p-3 rounded-lg border border-slate-700 flex items-center gap-3 text-sm">
+              <span className={`px-1.5 py-0.5 rounded text-xs ${SEVERITY_STYLES[a.severity] || SEVERITY_STYLES.info}`}>{a.severity}</span>
+              <span className="text-slate-400 font-mono">{a.source.replace('connections.', '')}</span>
+              <span className="text-slate-300 flex-1">{a.message}</span>
+              <span className="text-xs text-slate-500">{new Date(a.timestamp).toLocaleString()}</span>
+            </div>
+          ))}
+        </div>
+      )}
+    </div>
+  );
+}
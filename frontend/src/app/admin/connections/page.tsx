@@ -1,7 +1,8 @@
 'use client';
 
 import { useState, useEffect } from 'react';
-import { RefreshCw, Monitor, Trash2, Shield, Calendar } from 'lucide-react';
+import Link from 'next/link';
+import { RefreshCw, Monitor, Trash2, Shield, Calendar, FileText, DatabaseBackup, Eraser, Bell, Search } from 'lucide-react';
 import { api } from '@/lib/api';
 
 interface Connection {
@@ -11,6 +12,14 @@ interface Connection {
   version: string;
   last_seen: string;
   ip: string;
+  indexed_files?: number;
+  searches?: number;
+  stores?: string[];
+  hostname?: string;
+  os?: string;
+  arch?: string;
+  username?: string;
+  recovered?: boolean;
 }
 
 export default function ConnectionsPage() {
@@ -44,6 +53,28 @@ export default function ConnectionsPage() {
     }
   };
 
+  const purgeChunks = async (conn: Connection) => {
+    if(!confirm(`Remove every chunk indexed by ${conn.device_name} from all stores?`)) return;
+    try {
+      await api.deleteConnectionChunks(conn.id);
+      alert("Cleanup queued. Chunks will disappear from search shortly.");
+    } catch (e) {
+      console.error(e);
+      alert("Failed to start cleanup");
+    }
+  };
+
+  const backfill = async () => {
+    if(!confirm("Rebuild connection stats from the index? This scans all chunks.")) return;
+    try {
+      await api.backfillConnections();
+      alert("Backfill queued. Refresh in a moment to see updated stats.");
+    } catch (e) {
+      console.error(e);
+      alert("Failed to start backfill");
+    }
+  };
+
   useEffect(() => {
     fetchConnections();
   }, []);
@@ -57,12 +88,28 @@ export default function ConnectionsPage() {
           </h1>
           <p className="text-slate-400 mt-1">Manage active CLI sessions and integrations.</p>
         </div>
-        <button 
-           onClick={fetchConnections}
-           className="p-2 bg-slate-800 rounded-lg text-slate-400 hover:text-white transition-colors"
-        >
-           <RefreshCw size={20} className={loading ? "animate-spin" : ""} />
-        </button>
+        <div className="flex items-center gap-2">
+          <Link
+             href="/admin/connections/alerts"
+             className="p-2 bg-slate-800 rounded-lg text-slate-400 hover:text-white transition-colors"
+             title="Security alerts"
+          >
+             <Bell size={20} />
+          </Link>
+          <button 
+             onClick={backfill}
+             className="p-2 bg-slate-800 rounded-lg text-slate-400 hover:text-white transition-colors"
+             title="Rebuild stats from index"
+          >
+             <DatabaseBackup size={20} />
+          </button>
+          <button 
+             onClick={fetchConnections}
+             className="p-2 bg-slate-800 rounded-lg text-slate-400 hover:text-white transition-colors"
+          >
+             <RefreshCw size={20} className={loading ? "animate-spin" : ""} />
+          </button>
+        </div>
       </div>
 
       {error && (
@@ -92,9 +139,32 @@ export default function ConnectionsPage() {
                          <span className="flex items-center gap-1"><Calendar size={12}/> {new Date(conn.last_seen).toLocaleString()}</span>
                          <span className="bg-slate-700 px-1.5 py-0.5 rounded text-slate-300 font-mono">v{conn.version}</span>
                          <span>{conn.ip}</span>
+                         {conn.os && (
+                           <span>{conn.username ? `${conn.username}@` : ''}{conn.os}/{conn.arch}</span>
+                         )}
+                         {conn.indexed_files !== undefined && (
+                           <span className="flex items-center gap-1"><FileText size={12}/> {conn.indexed_files} files</span>
+                         )}
+                         {conn.searches !== undefined && (
+                           <span className="flex items-center gap-1"><Search size={12}/> {conn.searches} searches</span>
+                         )}
+                         {conn.stores && conn.stores.length > 0 && (
+                           <span>{conn.stores.join(', ')}</span>
+                         )}
+                         {conn.recovered && (
+                           <span className="bg-amber-500/10 text-amber-400 px-1.5 py-0.5 rounded">recovered</span>
+                         )}
                       </div>
                    </div>
                 </div>
+                <div className="flex items-center gap-1">
+                <button 
+                  onClick={() => purgeChunks(conn)}
+                  className="p-2 text-slate-500 hover:text-amber-400 hover:bg-slate-700/50 rounded-lg transition-colors opacity-0 group-hover:opacity-100 focus:opacity-100"
+                  title="Remove Indexed Chunks"
+                >
+                  <Eraser size={20} />
+                </button>
                 <button 
                   onClick={() => revokeConnection(conn.id)}
                   className="p-2 text-slate-500 hover:text-red-400 hover:bg-slate-700/50 rounded-lg transition-colors opacity-0 group-hover:opacity-100 focus:opacity-100"
@@ -102,6 +172,7 @@ export default function ConnectionsPage() {
                 >
                   <Trash2 size={20} />
                 </button>
+                </div>
              </div>
            ))}
         </div>
@@ -0,0 +1,156 @@
+'use client';
+
+import { useState, useEffect, useRef } from 'react';
+import { Radio, Pause, Play, Trash2, ChevronDown, ChevronRight } from 'lucide-react';
+import { api, BusEvent } from '@/lib/api';
+
+// Keep the page responsive during large indexing runs
+const MAX_EVENTS = 500;
+
+const TOPIC_STYLES: Record<string, string> = {
+  index: 'bg-blue-500/10 text-blue-400',
+  alert: 'bg-red-500/10 text-red-400',
+  audit: 'bg-purple-500/10 text-purple-400',
+};
+
+const PRESETS = ['', 'index.*', 'alert.*', 'audit.*'];
+
+function EventRow({ event }: { event: BusEvent }) {
+  const [open, setOpen] = useState(false);
+  const family = event.topic.split('.')[0];
+  const summary = event.payload.path || event.payload.message || event.payload.details || '';
+
+  return (
+    <div className="border-t border-slate-700 first:border-t-0">
+      <button
+        onClick={() => setOpen(!open)}
+        className="w-full flex items-center gap-3 p-3 text-left text-sm hover:bg-slate-700/30"
+      >
+        {open ? <ChevronDown size={14} className="text-slate-500" /> : <ChevronRight size={14} className="text-slate-500" />}
+        <span className="text-xs text-slate-500 font-mono whitespace-nowrap">
+          {new Date(event.timestamp).toLocaleTimeString()}
+        </span>
+        <span className={`px-1.5 py-0.5 rounded text-xs font-mono ${TOPIC_STYLES[family] || 'bg-slate-700 text-slate-300'}`}>
+          {event.topic}
+        </span>
+        {event.replayed && (
+          <span className="px-1.5 py-0.5 rounded text-xs bg-amber-500/10 text-amber-400">replayed</span>
+        )}
+        <span className="text-slate-300 truncate">{String(summary)}</span>
+      </button>
+      {open && (
+        <pre className="mx-3 mb-3 p-3 bg-slate-900 rounded-lg text-xs text-slate-300 overflow-x-auto">
+          {JSON.stringify(event.payload, null, 2)}
+        </pre>
+      )}
+    </div>
+  );
+}
+
+export default function EventsPage() {
+  const [events, setEvents] = useState<BusEvent[]>([]);
+  const [topics, setTopics] = useState('');
+  const [knownTopics, setKnownTopics] = useState<string[]>([]);
+  const [paused, setPaused] = useState(false);
+  const [connected, setConnected] = useState(false);
+  const lastId = useRef<string | undefined>(undefined);
+
+  // Load history whenever the filter changes
+  useEffect(() => {
+    let cancelled = false;
+    api.listEvents(topics, 200)
+      .then(res => {
+        if (cancelled) return;
+        setEvents(res.events.reverse());
+        lastId.current = res.events.length ? res.events[0].id : undefined;
+      })
+      .catch(e => console.error(e));
+    api.listEventTopics()
+      .then(res => !cancelled && setKnownTopics(res.topics))
+      .catch(() => {});
+    return () => { cancelled = true; };
+  }, [topics]);
+
+  // Live stream; pausing closes it and resuming replays from the last seen id
+  useEffect(() => {
+    if (paused) return;
+    const source = new EventSource(api.eventStreamUrl(topics, lastId.current));
+    source.onopen = () => setConnected(true);
+    source.onerror = () => setConnected(false);
+    source.onmessage = (msg) => {
+      const event: BusEvent = JSON.parse(msg.data);
+      lastId.current = event.id;
+      setEvents(prev => prev.some(e => e.id === event.id) ? prev : [event, ...prev].slice(0, MAX_EVENTS));
+    };
+    return () => {
+      source.close();
+      setConnected(false);
+    };
+  }, [topics, paused]);
+
+  return (
+    <div>
+      <div className="flex items-center justify-between mb-8">
+        <div>
+          <h1 className="text-3xl font-bold text-white flex items-center gap-3">
+             <Radio className="text-primary" /> Live Events
+          </h1>
+          <p className="text-slate-400 mt-1 flex items-center gap-2">
+            <span className={`inline-block w-2 h-2 rounded-full ${connected ? 'bg-green-400' : paused ? 'bg-amber-400' : 'bg-slate-500'}`} />
+            {paused ? 'Paused' : connected ? 'Streaming' : 'Connecting...'}
+          </p>
+        </div>
+        <div className="flex items-center gap-2">
+          <button
+             onClick={() => setPaused(!paused)}
+             className="p-2 bg-slate-800 rounded-lg text-slate-400 hover:text-white transition-colors"
+             title={paused ? 'Resume' : 'Pause'}
+          >
+             {paused ? <Play size={20} /> : <Pause size={20} />}
+          </button>
+          <button
+             onClick={() => setEvents([])}
+             className="p-2 bg-slate-800 rounded-lg text-slate-400 hover:text-white transition-colors"
+             title="Clear"
+          >
+             <Trash2 size={20} />
+          </button>
+        </div>
+      </div>
+
+      <div className="flex items-center gap-2 mb-4">
+        {PRESETS.map(p => (
+          <button
+            key={p || 'all'}
+            onClick={() => setTopics(p)}
+            className={`px-3 py-1.5 rounded-lg text-sm transition-colors ${
+              topics === p ? 'bg-primary/20 text-primary border border-primary/30' : 'bg-slate-800 text-slate-400 hover:text-white'
+            }`}
+          >
+            {p || 'All'}
+          </button>
+        ))}
+        <input
+          list="event-topics"
+          placeholder="Topic filter, e.g. index.file.*,alert.*"
+          defaultValue=""
+          onKeyDown={(e) => {
+            if (e.key === 'Enter') setTopics((e.target as HTMLInputElement).value.trim());
+          }}
+          className="flex-1 px-3 py-1.5 bg-slate-800 border border-slate-700 rounded-lg text-sm text-white placeholder-slate-500 focus:outline-none focus:border-primary"
+        />
+        <datalist id="event-topics">
+          {knownTopics.map(t => <option key={t} value={t} />)}
+        </datalist>
+      </div>
+
+      <div className="bg-slate-800 rounded-xl border border-slate-700 overflow-hidden">
+        {events.length === 0 ? (
+          <p className="p-6 text-center text-slate-500">No events yet. Start an indexing run to see it here.</p>
+        ) : (
+          events.map(event => <EventRow key={event.id} event={event} />)
+        )}
+      </div>
+    </div>
+  );
+}
@@ -9,6 +9,7 @@ const navItems = [
   // Models page removed - will be redone based on Ollama in future version
   { href: '/admin/users', label: 'Users', icon: '👥', enterprise: true },
   { href: '/admin/observability', label: 'Observability', icon: '📈' },
+  { href: '/admin/events', label: 'Events', icon: '📡' },
 ];
 
 export default function AdminLayout({
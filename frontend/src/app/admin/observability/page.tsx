@@ -24,21 +24,34 @@ interface Metrics {
   components: Record<string, string>;
 }
 
+interface SearchQuality {
+  searches: number;
+  zero_results: number;
+  zero_result_rate: number;
+  latency_ms: { p50: number; p95: number; p99: number; samples: number };
+  top_zero_result_queries: { query: string; count: number; last_seen: string | null }[];
+  slow_queries: { query: string; store: string | null; latency_ms: number; percentile: 'p95' | 'p99' }[];
+}
+
 const API_BASE = 'http://localhost:8000/api/v1/admin/public';
+const STATS_BASE = 'http://localhost:8000/api/v1/stats';
 
 export default function ObservabilityPage() {
   const [metrics, setMetrics] = useState<Metrics | null>(null);
   const [logs, setLogs] = useState<AuditLog[]>([]);
+  const [quality, setQuality] = useState<SearchQuality | null>(null);
   const [loading, setLoading] = useState(true);
 
   const fetchData = async () => {
     try {
-      const [metricsRes, logsRes] = await Promise.all([
+      const [metricsRes, logsRes, qualityRes] = await Promise.all([
         fetch(`${API_BASE}/metrics`),
-        fetch(`${API_BASE}/audit-log?limit=10`)
+        fetch(`${API_BASE}/audit-log?limit=10`),
+        fetch(`${STATS_BASE}/quality?limit=10`)
       ]);
       
       if (metricsRes.ok) setMetrics(await metricsRes.json());
+      if (qualityRes.ok) setQuality(await qualityRes.json());
       if (logsRes.ok) {
         const data = await logsRes.json();
         setLogs(data.logs || []);
@@ -188,6 +201,57 @@ export default function ObservabilityPage() {
         </div>
       </div>
 
+      {/* Search Quality */}
+      <div className="bg-slate-800 rounded-xl p-6 border border-slate-700 mb-8">
+        <div className="flex items-center justify-between mb-4">
+          <h3 className="text-lg font-semibold text-white">Search Quality</h3>
+          <span className="text-sm text-slate-400">
+            {quality?.zero_results ?? 0} of {quality?.searches ?? 0} searches found nothing
+            ({((quality?.zero_result_rate ?? 0) * 100).toFixed(1)}%)
+          </span>
+        </div>
+        <div className="grid grid-cols-1 md:grid-cols-2 gap-6">
+          <div>
+            <h4 className="text-sm font-medium text-slate-300 mb-2">Top Zero-Result Queries</h4>
+            {quality?.top_zero_result_queries.length ? (
+              <div className="space-y-2">
+                {quality.top_zero_result_queries.map((q) => (
+                  <div key={q.query} className="flex items-center gap-4 text-sm">
+                    <span className="text-white font-mono flex-1 truncate" title={q.query}>{q.query}</span>
+                    <span className="text-slate-400 font-mono">{q.count}×</span>
+                  </div>
+                ))}
+              </div>
+            ) : (
+              <p className="text-sm text-slate-500">No zero-result queries yet</p>
+            )}
+          </div>
+          <div>
+            <h4 className="text-sm font-medium text-slate-300 mb-2">
+              Slow Queries
+              <span className="ml-2 text-slate-500 font-normal">
+                p95 {quality?.latency_ms.p95 ?? 0}ms · p99 {quality?.latency_ms.p99 ?? 0}ms
+              </span>
+            </h4>
+            {quality?.slow_queries.length ? (
+              <div className="space-y-2">
+                {quality.slow_queries.map((q) => (
+                  <div key={q.query} className="flex items-center gap-4 text-sm">
+                    <span className="text-white font-mono flex-1 truncate" title={q.query}>{q.query}</span>
+                    {q.store && <span className="text-slate-500 text-xs">{q.store}</span>}
+                    <span className={`font-mono ${q.percentile === 'p99' ? 'text-red-400' : 'text-yellow-400'}`}>
+                      {Math.round(q.latency_ms)}ms
+                    </span>
+                  </div>
+                ))}
+              </div>
+            ) : (
+              <p className="text-sm text-slate-500">No slow queries recorded</p>
+            )}
+          </div>
+        </div>
+      </div>
+
       {/* Audit Log */}
       <div className="bg-slate-800 rounded-xl p-6 border border-slate-700">
         <h3 className="text-lg font-semibold text-white mb-4">Recent Activity</h3>
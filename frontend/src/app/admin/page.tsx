@@ -1,6 +1,6 @@
 'use client';
 
-import { useState, useEffect } from 'react';
+import { useState, useEffect, useRef, type ChangeEvent, type DragEvent } from 'react';
 import Image from 'next/image';
 import Link from 'next/link';
 
@@ -14,12 +14,52 @@ interface AdminStatus {
     redis?: { status: string };
     minio?: { status: string };
   };
+  device?: DeviceInfo;
+}
+
+interface DeviceInfo {
+  device: string;
+  name: string;
+  cuda_available: boolean;
+  cuda_version?: string | null;
+  gpu_requested: boolean;
+  fallback_reason?: string | null;
+  fallbacks: { component: string; reason: string; at: string }[];
+  components: Record<string, string>;
+  vram?: { used_mb: number | null; total_mb: number | null; process_mb: number; utilization_percent: number | null } | null;
+}
+
+interface QueryModelStatus {
+  enabled: boolean;
+  use_llm: boolean;
+  backends: { name: string; url: string; model: string }[];
+  last_warmup: { ok: boolean; latency_ms: number; at: number } | null;
+  paths: Record<string, number>;
+  fallbacks: Record<string, number>;
+}
+
+interface StoreUsage {
+  id: string;
+  name: string;
+  search_count: number;
+  last_searched_at?: string;
+}
+
+interface ComponentHistory {
+  status: string;
+  uptime_percent: number | null;
+  incidents: { start: number; end: number | null; duration_seconds: number; status: string }[];
+  timeline: string[];
 }
 
 const API_BASE = 'http://localhost:8000/api/v1/admin/public';
+const STORES_API = 'http://localhost:8000/api/v1/stores';
+const HEALTH_API = 'http://localhost:8000/api/v1/health';
 
 export default function AdminDashboard() {
   const [adminStatus, setAdminStatus] = useState<AdminStatus | null>(null);
+  const [topStores, setTopStores] = useState<StoreUsage[]>([]);
+  const [healthHistory, setHealthHistory] = useState<Record<string, ComponentHistory>>({});
   const [loading, setLoading] = useState(true);
   const [message, setMessage] = useState<{ type: 'success' | 'error'; text: string } | null>(null);
 
@@ -31,6 +71,16 @@ export default function AdminDashboard() {
       if (statusRes.ok) {
          setAdminStatus(await statusRes.json());
       }
+      const historyRes = await fetch(`${HEALTH_API}/history?hours=24&buckets=24`);
+      if (historyRes.ok) {
+         const data = await historyRes.json();
+         setHealthHistory(data.components || {});
+      }
+      const usageRes = await fetch(`${STORES_API}/usage/top?limit=5`);
+      if (usageRes.ok) {
+         const data = await usageRes.json();
+         setTopStores(data.stores || []);
+      }
     } catch (e) {
       console.error(e);
     }
@@ -126,6 +176,23 @@ export default function AdminDashboard() {
         />
       </div>
 
+      {/* Uptime & Incidents (last 24h) */}
+      {Object.keys(healthHistory).length > 0 && (
+        <div className="bg-slate-800 rounded-xl p-6 border border-slate-700 mb-8">
+          <div className="flex justify-between items-center mb-4">
+            <h2 className="text-xl font-semibold text-white">Uptime (24h)</h2>
+            <span className="text-xs text-slate-500">1 bar = 1 hour</span>
+          </div>
+          <div className="space-y-3">
+            {Object.entries(healthHistory).map(([name, h]) => (
+              <UptimeStrip key={name} name={name} history={h} />
+            ))}
+          </div>
+        </div>
+      )}
+
+      {adminStatus?.device && <DevicePanel device={adminStatus.device} />}
+
       {/* Quick Actions & Info */}
       <div className="grid grid-cols-1 md:grid-cols-2 gap-6">
         <div className="bg-slate-800 rounded-xl p-6 border border-slate-700">
@@ -165,6 +232,46 @@ export default function AdminDashboard() {
         </div>
       </div>
 
+      <QueryModelPanel onMessage={showMessage} />
+
+      <QuickIndexPanel onMessage={showMessage} />
+
+      <div className="bg-slate-800 rounded-xl p-6 border border-slate-700 mt-6">
+        <div className="flex justify-between items-center mb-4">
+          <h2 className="text-xl font-semibold text-white">Most Used Stores</h2>
+          <Link href="/stores" className="text-xs text-primary hover:text-white transition-colors uppercase font-bold tracking-wider">
+            All Stores →
+          </Link>
+        </div>
+        {topStores.length === 0 ? (
+          <p className="text-slate-500 text-sm">No searches recorded yet.</p>
+        ) : (
+          <div className="space-y-2">
+            {topStores.map((store) => {
+              const max = topStores[0]?.search_count || 1;
+              return (
+                <Link
+                  key={store.id}
+                  href={`/stores/${store.id}`}
+                  className="block p-3 bg-slate-900/50 rounded-lg border border-slate-700/50 hover:border-primary/50 transition-colors"
+                >
+                  <div className="flex items-center justify-between mb-2">
+                    <span className="text-slate-300">{store.name}</span>
+                    <span className="text-xs text-slate-400 font-mono">{store.search_count} searches</span>
+                  </div>
+                  <div className="h-1.5 bg-slate-700 rounded-full overflow-hidden">
+                    <div
+                      className="h-full bg-primary"
+                      style={{ width: `${Math.max(4, (store.search_count / max) * 100)}%` }}
+                    />
+                  </div>
+                </Link>
+              );
+            })}
+          </div>
+        )}
+      </div>
+
       {showSettings && (
         <SettingsModal onClose={() => { setShowSettings(false); fetchData(); }} />
       )}
@@ -172,6 +279,490 @@ export default function AdminDashboard() {
   );
 }
 
+function UptimeStrip({ name, history }: { name: string; history: ComponentHistory }) {
+  const colors: Record<string, string> = {
+    up: 'bg-green-500',
+    degraded: 'bg-yellow-500',
+    down: 'bg-red-500',
+    unknown: 'bg-slate-700',
+  };
+  const lastIncident = history.incidents[history.incidents.length - 1];
+
+  return (
+    <div className="flex items-center gap-4">
+      <span className="w-20 text-sm text-slate-300 capitalize">{name}</span>
+      <div className="flex-1 flex gap-0.5 h-6">
+        {history.timeline.map((bucket, i) => (
+          <div key={i} className={`flex-1 rounded-sm ${colors[bucket] || colors.unknown}`} title={bucket} />
+        ))}
+      </div>
+      <span className="w-16 text-right text-sm font-mono text-slate-300">
+        {history.uptime_percent === null ? '—' : `${history.uptime_percent.toFixed(1)}%`}
+      </span>
+      <span className="w-40 text-right text-xs text-slate-500">
+        {history.incidents.length === 0
+          ? 'No incidents'
+          : lastIncident.end === null
+            ? `Down since ${new Date(lastIncident.start * 1000).toLocaleTimeString()}`
+            : `${history.incidents.length} incident${history.incidents.length > 1 ? 's' : ''}`}
+      </span>
+    </div>
+  );
+}
+
+function DevicePanel({ device }: { device: DeviceInfo }) {
+  const vram = device.vram;
+  const usedPercent = vram?.total_mb ? Math.min(100, ((vram.used_mb ?? vram.process_mb) / vram.total_mb) * 100) : 0;
+  const warnings = [
+    ...(device.fallback_reason ? [{ component: 'GPU', reason: device.fallback_reason }] : []),
+    ...device.fallbacks,
+  ];
+
+  return (
+    <div className="bg-slate-800 rounded-xl p-6 border border-slate-700 mb-8">
+      <div className="flex justify-between items-center mb-4">
+        <h2 className="text-xl font-semibold text-white">Compute Device</h2>
+        <span className={`px-3 py-1 rounded-full text-sm border ${
+          device.device === 'cuda'
+            ? 'bg-green-500/20 text-green-400 border-green-500/30'
+            : device.gpu_requested
+              ? 'bg-yellow-500/20 text-yellow-400 border-yellow-500/30'
+              : 'bg-slate-700 text-slate-300 border-slate-600'
+        }`}>
+          {device.device.toUpperCase()}
+        </span>
+      </div>
+      <div className="grid grid-cols-1 md:grid-cols-2 gap-6 text-sm">
+        <div className="space-y-1">
+          <div className="text-white font-medium">{device.name}</div>
+          {device.cuda_version && <div className="text-slate-400">CUDA {device.cuda_version}</div>}
+          {Object.entries(device.components).map(([component, dev]) => (
+            <div key={component} className="text-slate-400">
+              {component}: <span className="text-slate-200">{dev}</span>
+            </div>
+          ))}
+        </div>
+        {vram && (
+          <div>
+            <div className="flex justify-between mb-1">
+              <span className="text-slate-400">VRAM</span>
+              <span className="text-slate-200">
+                {((vram.used_mb ?? vram.process_mb) / 1024).toFixed(1)}
+                {vram.total_mb ? ` / ${(vram.total_mb / 1024).toFixed(0)} GB` : ' GB'}
+              </span>
+            </div>
+            <div className="h-2 bg-slate-700 rounded-full overflow-hidden">
+              <div className="h-full bg-primary" style={{ width: `${usedPercent}%` }} />
+            </div>
+            <div className="text-xs text-slate-500 mt-1">
+              This service: {(vram.process_mb / 1024).toFixed(1)} GB
+              {vram.utilization_percent != null && ` · Utilization ${vram.utilization_percent}%`}
+            </div>
+          </div>
+        )}
+      </div>
+      {warnings.length > 0 && (
+        <div className="mt-4 space-y-1">
+          {warnings.map((w, i) => (
+            <div key={i} className="text-sm p-2 rounded bg-yellow-500/10 border border-yellow-500/30 text-yellow-300">
+              {w.component} running on CPU: {w.reason}
+            </div>
+          ))}
+        </div>
+      )}
+    </div>
+  );
+}
+
+// Quick index: files dropped or picked in the browser, uploaded in batches
+const UPLOAD_BATCH_FILES = 50;
+const UPLOAD_BATCH_BYTES = 8 * 1024 * 1024;
+// Indexed as documents even though their bytes look binary
+const DOCUMENT_EXTENSIONS = ['.pdf', '.docx', '.doc', '.pptx', '.xlsx', '.odt'];
+const FINISHED_STATES = ['SUCCESS', 'FAILURE', 'REVOKED'];
+
+type QuickIndexStatus = 'pending' | 'uploading' | 'queued' | 'indexed' | 'failed' | 'excluded' | 'skipped';
+
+interface QuickIndexItem {
+  file: File;
+  path: string;
+  status: QuickIndexStatus;
+  taskId?: string;
+  reason?: string;
+}
+
+// Same test as the server's exclusion policy: NUL bytes or >30% non-text bytes in the first 8 KB
+async function looksBinary(file: File): Promise<boolean> {
+  if (DOCUMENT_EXTENSIONS.some((ext) => file.name.toLowerCase().endsWith(ext))) return false;
+  const sample = new Uint8Array(await file.slice(0, 8192).arrayBuffer());
+  if (sample.length === 0) return false;
+  let nonText = 0;
+  for (const byte of sample) {
+    if (byte === 0) return true;
+    const text = [7, 8, 9, 10, 12, 13, 27].includes(byte) || (byte >= 0x20 && byte !== 0x7f);
+    if (!text) nonText++;
+  }
+  return nonText / sample.length > 0.3;
+}
+
+// Files under a dropped directory entry, with their paths relative to the drop
+async function readEntry(entry: any, prefix = ''): Promise<{ file: File; path: string }[]> {
+  if (entry.isFile) {
+    const file: File = await new Promise((resolve, reject) => entry.file(resolve, reject));
+    return [{ file, path: prefix + file.name }];
+  }
+  const reader = entry.createReader();
+  const children: any[] = [];
+  // readEntries returns at most ~100 entries per call
+  for (;;) {
+    const batch: any[] = await new Promise((resolve, reject) => reader.readEntries(resolve, reject));
+    if (batch.length === 0) break;
+    children.push(...batch);
+  }
+  const nested = await Promise.all(children.map((child) => readEntry(child, `${prefix}${entry.name}/`)));
+  return nested.flat();
+}
+
+function uploadBatches(items: QuickIndexItem[]): QuickIndexItem[][] {
+  const batches: QuickIndexItem[][] = [];
+  let batch: QuickIndexItem[] = [];
+  let bytes = 0;
+  for (const item of items) {
+    if (batch.length && (batch.length >= UPLOAD_BATCH_FILES || bytes + item.file.size > UPLOAD_BATCH_BYTES)) {
+      batches.push(batch);
+      batch = [];
+      bytes = 0;
+    }
+    batch.push(item);
+    bytes += item.file.size;
+  }
+  if (batch.length) batches.push(batch);
+  return batches;
+}
+
+function QuickIndexPanel({ onMessage }: { onMessage: (type: 'success' | 'error', text: string) => void }) {
+  const [stores, setStores] = useState<{ id: string; name: string }[]>([]);
+  const [store, setStore] = useState('');
+  const [items, setItems] = useState<QuickIndexItem[]>([]);
+  const [dragging, setDragging] = useState(false);
+  const [busy, setBusy] = useState(false);
+  const dirInput = useRef<HTMLInputElement>(null);
+
+  useEffect(() => {
+    fetch(`${STORES_API}/?sort=name`)
+      .then((res) => (res.ok ? res.json() : []))
+      .then((data) => {
+        setStores(data);
+        if (data.length) setStore((current) => current || data[0].id);
+      })
+      .catch((e) => console.error('Failed to list stores', e));
+  }, []);
+
+  // React has no typed prop for directory pickers
+  useEffect(() => {
+    dirInput.current?.setAttribute('webkitdirectory', '');
+  }, []);
+
+  const update = (paths: string[], patch: Partial<QuickIndexItem>) =>
+    setItems((current) => current.map((item) => (paths.includes(item.path) ? { ...item, ...patch } : item)));
+
+  // Poll queued files' jobs until they finish
+  const pending = items.filter((item) => item.status === 'queued' && item.taskId);
+  useEffect(() => {
+    if (pending.length === 0) return;
+    const timer = setTimeout(async () => {
+      for (const item of pending.slice(0, 20)) {
+        try {
+          const res = await fetch(`${API_BASE}/jobs/${item.taskId}`);
+          if (!res.ok) continue;
+          const job = await res.json();
+          if (!FINISHED_STATES.includes(job.state)) continue;
+          // The task result is the indexer's {status, message, ...}
+          const result = job.result && typeof job.result === 'object' ? job.result : {};
+          const failed = job.state !== 'SUCCESS' || ['error', 'forbidden'].includes(result.status);
+          update([item.path], {
+            status: failed ? 'failed' : 'indexed',
+            reason: failed ? String(result.message ?? job.result ?? job.state) : result.status,
+          });
+        } catch (e) {
+          console.error('Failed to poll job', e);
+        }
+      }
+    }, 2000);
+    return () => clearTimeout(timer);
+  }, [items]);
+
+  const addFiles = async (files: { file: File; path: string }[]) => {
+    const added: QuickIndexItem[] = [];
+    for (const { file, path } of files) {
+      // Repository internals are never worth indexing
+      if (path.split('/').includes('.git')) continue;
+      const binary = await looksBinary(file);
+      added.push({ file, path, status: binary ? 'skipped' : 'pending', reason: binary ? 'binary' : undefined });
+    }
+    setItems((current) => [...current.filter((c) => !added.some((a) => a.path === c.path)), ...added]);
+  };
+
+  const onDrop = async (e: DragEvent) => {
+    e.preventDefault();
+    setDragging(false);
+    const entries = Array.from(e.dataTransfer.items)
+      .map((item) => item.webkitGetAsEntry?.())
+      .filter(Boolean);
+    if (entries.length) {
+      const nested = await Promise.all(entries.map((entry) => readEntry(entry)));
+      await addFiles(nested.flat());
+    } else {
+      await addFiles(Array.from(e.dataTransfer.files).map((file) => ({ file, path: file.name })));
+    }
+  };
+
+  const onPick = async (e: ChangeEvent<HTMLInputElement>) => {
+    const files = Array.from(e.target.files || []);
+    await addFiles(files.map((file) => ({ file, path: file.webkitRelativePath || file.name })));
+    e.target.value = '';
+  };
+
+  const upload = async () => {
+    if (!store) return;
+    setBusy(true);
+    let failed = 0;
+    for (const batch of uploadBatches(items.filter((item) => item.status === 'pending'))) {
+      const paths = batch.map((item) => item.path);
+      update(paths, { status: 'uploading' });
+      const form = new FormData();
+      batch.forEach((item) => form.append('files', item.file, item.path));
+      try {
+        let res = await fetch(`${STORES_API}/${store}/upload`, { method: 'POST', body: form });
+        // Index queue full: wait as told, then retry the batch
+        while (res.status === 429) {
+          const wait = Number(res.headers.get('Retry-After') || 5);
+          await new Promise((resolve) => setTimeout(resolve, wait * 1000));
+          res = await fetch(`${STORES_API}/${store}/upload`, { method: 'POST', body: form });
+        }
+        const data = await res.json();
+        if (!res.ok) throw new Error(data.detail || res.statusText);
+        for (const result of data.files) {
+          update([result.file], {
+            status: result.status === 'error' ? 'failed' : result.status,
+            taskId: result.task_id,
+            reason: result.reason || result.error,
+          });
+        }
+      } catch (e: any) {
+        failed += batch.length;
+        update(paths, { status: 'failed', reason: e.message });
+      }
+    }
+    setBusy(false);
+    onMessage(failed ? 'error' : 'success', failed ? `${failed} files failed to upload` : `Files queued for indexing in ${store}`);
+  };
+
+  const count = (status: QuickIndexStatus) => items.filter((item) => item.status === status).length;
+  const uploaded = items.filter((item) => ['queued', 'indexed', 'failed', 'excluded'].includes(item.status)).length;
+  const total = items.filter((item) => item.status !== 'skipped').length;
+  const statusColors: Record<QuickIndexStatus, string> = {
+    pending: 'text-slate-400',
+    uploading: 'text-blue-400',
+    queued: 'text-yellow-400',
+    indexed: 'text-green-400',
+    failed: 'text-red-400',
+    excluded: 'text-slate-500',
+    skipped: 'text-slate-500',
+  };
+
+  return (
+    <div className="bg-slate-800 rounded-xl p-6 border border-slate-700 mt-6">
+      <div className="flex justify-between items-center mb-4">
+        <h2 className="text-xl font-semibold text-white">Quick Index</h2>
+        <select
+          value={store}
+          onChange={(e) => setStore(e.target.value)}
+          className="bg-slate-900 text-slate-200 rounded px-3 py-1.5 border border-slate-700 text-sm"
+        >
+          {stores.map((s) => (
+            <option key={s.id} value={s.id}>{s.name}</option>
+          ))}
+        </select>
+      </div>
+
+      <div
+        onDragOver={(e) => { e.preventDefault(); setDragging(true); }}
+        onDragLeave={() => setDragging(false)}
+        onDrop={onDrop}
+        className={`p-8 rounded-lg border-2 border-dashed text-center transition-colors ${
+          dragging ? 'border-primary bg-primary/10' : 'border-slate-600'
+        }`}
+      >
+        <p className="text-slate-300 mb-3">Drop files or folders here</p>
+        <div className="flex justify-center gap-3 text-sm">
+          <label className="px-3 py-1.5 bg-slate-700 text-white rounded-lg hover:bg-slate-600 cursor-pointer">
+            Choose Files
+            <input type="file" multiple className="hidden" onChange={onPick} />
+          </label>
+          <label className="px-3 py-1.5 bg-slate-700 text-white rounded-lg hover:bg-slate-600 cursor-pointer">
+            Choose Folder
+            <input ref={dirInput} type="file" multiple className="hidden" onChange={onPick} />
+          </label>
+        </div>
+      </div>
+
+      {items.length > 0 && (
+        <div className="mt-4">
+          <div className="flex items-center justify-between text-sm mb-2">
+            <span className="text-slate-400">
+              {uploaded}/{total} uploaded · {count('indexed')} indexed · {count('failed')} failed
+              {count('skipped') > 0 && ` · ${count('skipped')} binary skipped`}
+              {count('excluded') > 0 && ` · ${count('excluded')} excluded`}
+            </span>
+            <div className="flex gap-2">
+              <button
+                onClick={() => setItems([])}
+                disabled={busy}
+                className="px-3 py-1.5 bg-slate-700 text-white rounded-lg hover:bg-slate-600 disabled:opacity-50"
+              >
+                Clear
+              </button>
+              <button
+                onClick={upload}
+                disabled={busy || !store || count('pending') === 0}
+                className="px-3 py-1.5 bg-primary text-white rounded-lg hover:bg-accent disabled:opacity-50"
+              >
+                {busy ? 'Uploading...' : `Index ${count('pending')} files`}
+              </button>
+            </div>
+          </div>
+          <div className="h-1.5 bg-slate-700 rounded-full overflow-hidden mb-3">
+            <div
+              className="h-full bg-primary transition-all"
+              style={{ width: `${total ? ((count('indexed') + count('failed') + count('excluded')) / total) * 100 : 0}%` }}
+            />
+          </div>
+          <div className="max-h-64 overflow-y-auto space-y-1">
+            {items.map((item) => (
+              <div key={item.path} className="flex items-center gap-4 text-sm">
+                <span className="text-slate-300 font-mono flex-1 truncate" title={item.path}>{item.path}</span>
+                {item.reason && <span className="text-xs text-slate-500 truncate max-w-xs">{item.reason}</span>}
+                <span className={`w-20 text-right capitalize ${statusColors[item.status]}`}>{item.status}</span>
+              </div>
+            ))}
+          </div>
+        </div>
+      )}
+    </div>
+  );
+}
+
+function QueryModelPanel({ onMessage }: { onMessage: (type: 'success' | 'error', text: string) => void }) {
+  const [status, setStatus] = useState<QueryModelStatus | null>(null);
+  const [warming, setWarming] = useState(false);
+
+  const load = async () => {
+    try {
+      const res = await fetch(`${API_BASE}/query-model`);
+      if (res.ok) setStatus(await res.json());
+    } catch (e) {
+      console.error('Failed to fetch query model status', e);
+    }
+  };
+
+  useEffect(() => { load(); }, []);
+
+  const toggle = async () => {
+    if (!status) return;
+    try {
+      const res = await fetch(`${API_BASE}/query-model`, {
+        method: 'PUT',
+        headers: { 'Content-Type': 'application/json' },
+        body: JSON.stringify({ enabled: !status.enabled }),
+      });
+      if (!res.ok) throw new Error();
+      onMessage('success', `Query model ${status.enabled ? 'disabled' : 'enabled'}`);
+      load();
+    } catch (e) {
+      onMessage('error', 'Failed to update query model');
+    }
+  };
+
+  const warmUp = async () => {
+    setWarming(true);
+    try {
+      const res = await fetch(`${API_BASE}/query-model/warmup`, { method: 'POST' });
+      const data = await res.json();
+      if (!res.ok) throw new Error(data.detail);
+      onMessage('success', `Query model warm in ${data.latency_ms} ms`);
+      load();
+    } catch (e) {
+      onMessage('error', 'Query model warm-up failed');
+    } finally {
+      setWarming(false);
+    }
+  };
+
+  if (!status) return null;
+  const total = (status.paths.model || 0) + (status.paths.heuristic || 0);
+  const modelShare = total ? ((status.paths.model || 0) / total) * 100 : 0;
+  const fallbacks = Object.entries(status.fallbacks).filter(([, count]) => count > 0);
+
+  return (
+    <div className="bg-slate-800 rounded-xl p-6 border border-slate-700 mt-6">
+      <div className="flex justify-between items-center mb-4">
+        <h2 className="text-xl font-semibold text-white">Query Understanding</h2>
+        <div className="flex gap-2">
+          <button
+            onClick={warmUp}
+            disabled={warming || !status.enabled}
+            className="px-3 py-1.5 text-sm bg-slate-700 text-white rounded-lg hover:bg-slate-600 transition-colors disabled:opacity-50"
+          >
+            {warming ? 'Warming up...' : 'Warm Up'}
+          </button>
+          <button
+            onClick={toggle}
+            className={`px-3 py-1.5 text-sm rounded-lg transition-colors ${
+              status.enabled ? 'bg-red-900/50 text-red-200 hover:bg-red-900/80' : 'bg-primary text-white hover:bg-accent'
+            }`}
+          >
+            {status.enabled ? 'Disable Model' : 'Enable Model'}
+          </button>
+        </div>
+      </div>
+      <div className="grid grid-cols-1 md:grid-cols-2 gap-6 text-sm">
+        <div className="space-y-1">
+          <FeatureStatus label="Query Model" enabled={status.enabled && status.use_llm} />
+          {!status.use_llm && (
+            <div className="text-xs text-slate-500">search.query_analysis.use_llm is off; heuristics only</div>
+          )}
+          {status.backends.map((b) => (
+            <div key={b.name} className="text-slate-400">
+              {b.name}: <span className="text-slate-200">{b.model}</span>
+            </div>
+          ))}
+          {status.last_warmup && (
+            <div className="text-xs text-slate-500">
+              Last warm-up {status.last_warmup.ok ? `${status.last_warmup.latency_ms} ms` : 'failed'} at{' '}
+              {new Date(status.last_warmup.at * 1000).toLocaleTimeString()}
+            </div>
+          )}
+        </div>
+        <div>
+          <div className="flex justify-between mb-1">
+            <span className="text-slate-400">Served by model</span>
+            <span className="text-slate-200">{(status.paths.model || 0)} / {total}</span>
+          </div>
+          <div className="h-2 bg-slate-700 rounded-full overflow-hidden">
+            <div className="h-full bg-primary" style={{ width: `${modelShare}%` }} />
+          </div>
+          {fallbacks.length > 0 && (
+            <div className="text-xs text-slate-500 mt-2">
+              Heuristic fallbacks: {fallbacks.map(([reason, count]) => `${reason} ${count}`).join(' · ')}
+            </div>
+          )}
+        </div>
+      </div>
+    </div>
+  );
+}
+
 function FeatureStatus({ label, enabled }: { label: string; enabled?: boolean }) {
   return (
     <div className="flex items-center justify-between p-3 bg-slate-900/50 rounded-lg border border-slate-700/50">
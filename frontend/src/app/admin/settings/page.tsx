@@ -11,6 +11,19 @@ interface SettingsData {
   version: number;
 }
 
+interface BoostRule {
+  type: 'path_prefix' | 'path_glob' | 'language' | 'query_language';
+  value: string | null;
+  factor: number;
+}
+
+const BOOST_TYPES: { type: BoostRule['type']; label: string; placeholder: string }[] = [
+  { type: 'path_prefix', label: 'Path prefix', placeholder: 'src/' },
+  { type: 'path_glob', label: 'Path glob', placeholder: '*_test.go' },
+  { type: 'language', label: 'Language', placeholder: 'go' },
+  { type: 'query_language', label: 'Language named in query', placeholder: '' },
+];
+
 interface SettingValue {
   key: string;
   value: any;
@@ -292,6 +305,214 @@ export default function SettingsPage() {
           <p className="text-slate-400">No settings found matching your search.</p>
         </div>
       )}
+
+      <BoostRulesPanel onMessage={showMessage} />
+    </div>
+  );
+}
+
+function BoostRulesPanel({ onMessage }: { onMessage: (type: 'success' | 'error', text: string) => void }) {
+  const [stores, setStores] = useState<{ id: string; name: string }[]>([]);
+  const [storeId, setStoreId] = useState('');
+  const [rules, setRules] = useState<BoostRule[]>([]);
+  const [savedRules, setSavedRules] = useState<BoostRule[]>([]);
+  // Recency half-life in days and weight ('' half-life: off)
+  const [recency, setRecency] = useState({ halfLife: '', weight: '' });
+  const [savedRecency, setSavedRecency] = useState({ halfLife: '', weight: '' });
+  const [saving, setSaving] = useState(false);
+
+  useEffect(() => {
+    fetch(`${API_BASE}/stores/?sort=name`)
+      .then(res => res.ok ? res.json() : [])
+      .then(data => {
+        setStores(data);
+        if (data.length > 0) setStoreId(data[0].id);
+      })
+      .catch(e => console.error('Failed to fetch stores:', e));
+  }, []);
+
+  useEffect(() => {
+    if (!storeId) return;
+    fetch(`${API_BASE}/stores/${storeId}/boosts`)
+      .then(res => res.ok ? res.json() : { rules: [] })
+      .then(data => {
+        setRules(data.rules);
+        setSavedRules(data.rules);
+      })
+      .catch(e => console.error('Failed to fetch boosting rules:', e));
+    fetch(`${API_BASE}/stores/${storeId}/recency`)
+      .then(res => res.ok ? res.json() : { recency: null })
+      .then(data => {
+        const value = data.recency
+          ? { halfLife: String(data.recency.half_life_days), weight: String(data.recency.weight) }
+          : { halfLife: '', weight: '' };
+        setRecency(value);
+        setSavedRecency(value);
+      })
+      .catch(e => console.error('Failed to fetch recency config:', e));
+  }, [storeId]);
+
+  const updateRule = (idx: number, change: Partial<BoostRule>) => {
+    setRules(prev => prev.map((rule, i) => i === idx ? { ...rule, ...change } : rule));
+  };
+
+  const saveRules = async () => {
+    const res = await fetch(`${API_BASE}/stores/${storeId}/boosts`, {
+      method: 'PUT',
+      headers: { 'Content-Type': 'application/json' },
+      body: JSON.stringify({ rules }),
+    });
+    const data = await res.json();
+    if (!res.ok) throw new Error(typeof data.detail === 'string' ? data.detail : 'Invalid boosting rules');
+    setRules(data.rules);
+    setSavedRules(data.rules);
+  };
+
+  const saveRecency = async () => {
+    const res = await fetch(`${API_BASE}/stores/${storeId}/recency`, {
+      method: 'PUT',
+      headers: { 'Content-Type': 'application/json' },
+      body: JSON.stringify({
+        half_life_days: recency.halfLife ? parseFloat(recency.halfLife) : null,
+        weight: recency.weight ? parseFloat(recency.weight) : null,
+      }),
+    });
+    const data = await res.json();
+    if (!res.ok) throw new Error(typeof data.detail === 'string' ? data.detail : 'Invalid recency settings');
+    const value = data.recency
+      ? { halfLife: String(data.recency.half_life_days), weight: String(data.recency.weight) }
+      : { halfLife: '', weight: '' };
+    setRecency(value);
+    setSavedRecency(value);
+  };
+
+  const rulesChanged = JSON.stringify(rules) !== JSON.stringify(savedRules);
+  const recencyChanged = JSON.stringify(recency) !== JSON.stringify(savedRecency);
+  const hasChanges = rulesChanged || recencyChanged;
+
+  const save = async () => {
+    setSaving(true);
+    try {
+      if (rulesChanged) await saveRules();
+      if (recencyChanged) await saveRecency();
+      onMessage('success', `Ranking rules for ${storeId} saved`);
+    } catch (e: any) {
+      onMessage('error', e.message || 'Error saving ranking rules');
+    }
+    setSaving(false);
+  };
+
+  return (
+    <div className="mt-6 bg-slate-800 rounded-xl border border-slate-700 overflow-hidden">
+      <div className="bg-slate-900/50 px-6 py-4 border-b border-slate-700 flex items-center justify-between gap-4">
+        <div>
+          <h2 className="text-xl font-semibold text-white flex items-center gap-3">
+            <span className="text-2xl">🎯</span>
+            <span>Store Ranking Rules</span>
+          </h2>
+          <p className="text-sm text-slate-500 mt-1">
+            Scale fused scores at query time: factors above 1 boost matching results, below 1 demote them.
+            Recency favours recently indexed files.
+          </p>
+        </div>
+        <select
+          value={storeId}
+          onChange={(e) => setStoreId(e.target.value)}
+          className="bg-slate-900 border border-slate-700 rounded-lg px-4 py-2 text-white focus:outline-none focus:ring-2 focus:ring-primary"
+        >
+          {stores.map(store => (
+            <option key={store.id} value={store.id}>{store.name}</option>
+          ))}
+        </select>
+      </div>
+
+      <div className="p-4 space-y-2">
+        {rules.length === 0 && (
+          <p className="text-slate-400 text-sm">No boosting rules for this store.</p>
+        )}
+        {rules.map((rule, idx) => {
+          const kind = BOOST_TYPES.find(t => t.type === rule.type);
+          return (
+            <div key={idx} className="flex items-center gap-2">
+              <select
+                value={rule.type}
+                onChange={(e) => updateRule(idx, { type: e.target.value as BoostRule['type'] })}
+                className="bg-slate-900 border border-slate-700 rounded-lg px-3 py-2 text-white text-sm focus:outline-none focus:ring-2 focus:ring-primary"
+              >
+                {BOOST_TYPES.map(t => (
+                  <option key={t.type} value={t.type}>{t.label}</option>
+                ))}
+              </select>
+              <input
+                type="text"
+                value={rule.value ?? ''}
+                placeholder={kind?.placeholder}
+                disabled={rule.type === 'query_language'}
+                onChange={(e) => updateRule(idx, { value: e.target.value })}
+                className="flex-1 bg-slate-900 border border-slate-700 rounded-lg px-3 py-2 text-white text-sm font-mono focus:outline-none focus:ring-2 focus:ring-primary disabled:opacity-50"
+              />
+              <input
+                type="number"
+                step="0.1"
+                min="0.01"
+                value={rule.factor}
+                onChange={(e) => updateRule(idx, { factor: parseFloat(e.target.value) })}
+                className="w-24 bg-slate-900 border border-slate-700 rounded-lg px-3 py-2 text-white text-sm focus:outline-none focus:ring-2 focus:ring-primary"
+              />
+              <button
+                onClick={() => setRules(prev => prev.filter((_, i) => i !== idx))}
+                className="px-3 py-1.5 bg-slate-700 hover:bg-slate-600 text-white text-sm rounded transition-colors"
+              >
+                Remove
+              </button>
+            </div>
+          );
+        })}
+
+        <div className="flex items-center gap-2 pt-2 border-t border-slate-700 mt-2">
+          <span className="text-sm text-slate-300 w-40">Recency half-life (days)</span>
+          <input
+            type="number"
+            min="0"
+            step="1"
+            placeholder="off"
+            value={recency.halfLife}
+            onChange={(e) => setRecency(prev => ({ ...prev, halfLife: e.target.value }))}
+            className="w-24 bg-slate-900 border border-slate-700 rounded-lg px-3 py-2 text-white text-sm focus:outline-none focus:ring-2 focus:ring-primary"
+          />
+          <span className="text-sm text-slate-300">weight</span>
+          <input
+            type="number"
+            min="0"
+            step="0.1"
+            placeholder="default"
+            value={recency.weight}
+            disabled={!recency.halfLife}
+            onChange={(e) => setRecency(prev => ({ ...prev, weight: e.target.value }))}
+            className="w-24 bg-slate-900 border border-slate-700 rounded-lg px-3 py-2 text-white text-sm focus:outline-none focus:ring-2 focus:ring-primary disabled:opacity-50"
+          />
+          <span className="text-xs text-slate-500">Files indexed today score up to 1 + weight times higher</span>
+        </div>
+
+        <div className="flex items-center gap-2 pt-2">
+          <button
+            onClick={() => setRules(prev => [...prev, { type: 'path_prefix', value: '', factor: 1.2 }])}
+            disabled={!storeId}
+            className="px-3 py-1.5 bg-slate-700 hover:bg-slate-600 text-white text-sm rounded transition-colors disabled:opacity-50"
+          >
+            Add Rule
+          </button>
+          {hasChanges && (
+            <button
+              onClick={save}
+              disabled={saving}
+              className="px-3 py-1.5 bg-primary hover:bg-accent text-white text-sm rounded transition-colors disabled:opacity-50"
+            >
+              {saving ? 'Saving...' : 'Save'}
+            </button>
+          )}
+        </div>
+      </div>
     </div>
   );
 }
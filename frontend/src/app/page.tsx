@@ -1,6 +1,6 @@
 "use client";
 
-import { useState, memo } from "react";
+import { useState, useEffect, useCallback, useRef, memo } from "react";
 import Image from "next/image";
 import { Button, Input, Card } from "@/components/ui-elements";
 import {
@@ -17,11 +17,52 @@ import {
   Loader2,
   Minimize2,
   Maximize2,
+  SlidersHorizontal,
+  Download,
 } from "lucide-react";
 import ReactMarkdown from "react-markdown";
 import { Prism as SyntaxHighlighter } from "react-syntax-highlighter";
 import { oneDark } from "react-syntax-highlighter/dist/esm/styles/prism";
-import { api, type SearchResult } from "@/lib/api";
+import {
+  api,
+  type SearchResult,
+  type SearchExplanation,
+  type SearchFilters,
+  type LiveSearchReply,
+  type FacetValue,
+  type SearchFacets,
+  type ParsedQuery,
+} from "@/lib/api";
+
+// Results per page in search mode (more load on scroll)
+const PAGE_SIZE = 20;
+
+type PagedSearch = {
+  query: string;
+  store: string;
+  explain: boolean;
+  filters: SearchFilters;
+};
+
+// Comma or whitespace separated filter input -> list
+function splitList(value: string): string[] {
+  return value
+    .split(/[\s,]+/)
+    .map((v) => v.trim())
+    .filter(Boolean);
+}
+
+// Add an item to a filter input unless it is already there
+function appendToList(value: string, item: string): string {
+  const items = splitList(value);
+  return items.includes(item) ? value : [...items, item].join(", ");
+}
+
+const FACET_LABELS: Record<keyof SearchFacets, string> = {
+  language: "Language",
+  directory: "Directory",
+  connection: "Connection",
+};
 
 // Helper to get file extension for syntax highlighting
 function getLanguage(filepath?: string): string {
@@ -87,13 +128,119 @@ function formatRelevance(score: number): { label: string; color: string } {
   return { label: `Low (${pct}%)`, color: "text-slate-400 bg-slate-500/20" };
 }
 
+// Collapsible score breakdown of a result (explain mode)
+function ExplainPanel({ explanation }: { explanation: SearchExplanation }) {
+  const [open, setOpen] = useState(false);
+  const retrievers = Object.entries(explanation.retrievers);
+
+  return (
+    <div className="rounded-lg border border-slate-700 text-xs">
+      <button
+        onClick={() => setOpen(!open)}
+        className="w-full flex items-center justify-between px-3 py-2 text-slate-300 hover:bg-slate-800/50"
+      >
+        <span>
+          Why #{explanation.final_rank}
+          {explanation.fused_rank !== null &&
+            explanation.fused_rank !== explanation.final_rank &&
+            ` (fused #${explanation.fused_rank})`}
+        </span>
+        {open ? <ChevronUp size={14} /> : <ChevronDown size={14} />}
+      </button>
+      {open && (
+        <div className="px-3 pb-3 space-y-3 text-slate-400">
+          <table className="w-full font-mono">
+            <thead>
+              <tr className="text-slate-500 text-left">
+                <th className="font-normal">retriever</th>
+                <th className="font-normal">rank</th>
+                <th className="font-normal">score</th>
+                <th className="font-normal">weight</th>
+                <th className="font-normal">rrf</th>
+              </tr>
+            </thead>
+            <tbody>
+              {retrievers.map(([name, r]) => (
+                <tr key={name}>
+                  <td className="text-slate-300">{name}</td>
+                  <td>{r.rank}</td>
+                  <td>{r.score.toFixed(3)}</td>
+                  <td>{r.weight.toFixed(2)}</td>
+                  <td>{r.rrf_contribution.toFixed(4)}</td>
+                </tr>
+              ))}
+              {explanation.missing_from.map((name) => (
+                <tr key={name} className="text-slate-600">
+                  <td>{name}</td>
+                  <td colSpan={4}>not returned</td>
+                </tr>
+              ))}
+            </tbody>
+          </table>
+          <div className="font-mono">
+            fused score {explanation.fused_score.toFixed(4)}
+            {explanation.rerank && (
+              <>
+                {" · "}rerank {explanation.rerank.score?.toFixed(3) ?? "n/a"} (#
+                {explanation.rerank.rank_before} → #
+                {explanation.rerank.rank_after})
+              </>
+            )}
+          </div>
+          {explanation.stages.length > 0 && (
+            <ul className="list-disc list-inside space-y-1">
+              {explanation.stages.map((stage) => (
+                <li key={stage}>{stage}</li>
+              ))}
+            </ul>
+          )}
+          {explanation.dedup_removed.length > 0 && (
+            <div className="text-slate-500">
+              Dropped duplicates:{" "}
+              {explanation.dedup_removed
+                .map((d) => `fused #${d.fused_rank}`)
+                .join(", ")}
+            </div>
+          )}
+        </div>
+      )}
+    </div>
+  );
+}
+
 // Expandable result card component
-const ResultCard = memo(function ResultCard({ hit, index }: { hit: SearchResult; index: number }) {
+const ResultCard = memo(function ResultCard({
+  hit,
+  index,
+  store,
+  onOpen,
+}: {
+  hit: SearchResult;
+  index: number;
+  store?: string;
+  onOpen?: (hit: SearchResult) => void;
+}) {
   const [expanded, setExpanded] = useState(false);
   const [copied, setCopied] = useState(false);
   const [rawMarkdown, setRawMarkdown] = useState(false);
   const [fullContent, setFullContent] = useState<string | null>(null);
   const [loadingFull, setLoadingFull] = useState(false);
+  // Paged results arrive as previews; chunk text is fetched on first expand
+  const [content, setContent] = useState<string | null>(hit.text ?? null);
+  const [loadingContent, setLoadingContent] = useState(false);
+
+  useEffect(() => {
+    if (!expanded || content !== null || !hit.chunk_id) return;
+    setLoadingContent(true);
+    api
+      .getChunk(hit.chunk_id, store)
+      .then((chunk) => setContent(chunk.text || ""))
+      .catch((err) => {
+        console.error("Failed to load result:", err);
+        setContent(hit.preview || "");
+      })
+      .finally(() => setLoadingContent(false));
+  }, [expanded, content, hit.chunk_id, hit.preview, store]);
 
   const filePath =
     hit.file_path ||
@@ -107,7 +254,7 @@ const ResultCard = memo(function ResultCard({ hit, index }: { hit: SearchResult;
   const isMarkdown = filePath.toLowerCase().endsWith(".md");
 
   const handleCopy = async () => {
-    await navigator.clipboard.writeText(hit.text || "");
+    await navigator.clipboard.writeText(content ?? hit.preview ?? "");
     setCopied(true);
     setTimeout(() => setCopied(false), 2000);
   };
@@ -133,7 +280,10 @@ const ResultCard = memo(function ResultCard({ hit, index }: { hit: SearchResult;
   return (
     <Card
       className="hover:border-slate-700 transition-colors group cursor-pointer"
-      onClick={() => setExpanded(!expanded)}
+      onClick={() => {
+        if (!expanded) onOpen?.(hit);
+        setExpanded(!expanded);
+      }}
     >
       <div className="flex items-start gap-4">
         <div className="mt-1 p-2 bg-slate-800 rounded-lg text-indigo-400 group-hover:text-indigo-300">
@@ -161,9 +311,16 @@ const ResultCard = memo(function ResultCard({ hit, index }: { hit: SearchResult;
             </div>
           </div>
 
+          {/* LLM chunk summary */}
+          {hit.summary && (
+            <p className="text-slate-300 text-sm italic">{hit.summary}</p>
+          )}
+
           {/* Preview snippet */}
           {!expanded && (
-            <p className="text-slate-400 text-sm line-clamp-2">{hit.text}</p>
+            <p className="text-slate-400 text-sm line-clamp-2">
+              {hit.text ?? hit.preview}
+            </p>
           )}
 
           {/* Expanded content */}
@@ -182,6 +339,10 @@ const ResultCard = memo(function ResultCard({ hit, index }: { hit: SearchResult;
                 )}
               </div>
 
+              {hit.explanation && (
+                <ExplainPanel explanation={hit.explanation} />
+              )}
+
               {/* Actions */}
               <div className="flex gap-2">
                 <button
@@ -238,7 +399,12 @@ const ResultCard = memo(function ResultCard({ hit, index }: { hit: SearchResult;
 
               {/* Content preview or Full File view */}
               <div className="rounded-lg overflow-hidden border border-slate-700">
-                  {fullContent ? (
+                  {loadingContent ? (
+                  <div className="flex items-center gap-2 p-4 text-xs text-slate-400 bg-slate-900">
+                    <Loader2 size={14} className="animate-spin" />
+                    Loading content...
+                  </div>
+                ) : fullContent ? (
                   (() => {
                     // Safety check for large files
                     const MAX_LINES = 2000;
@@ -308,11 +474,11 @@ const ResultCard = memo(function ResultCard({ hit, index }: { hit: SearchResult;
                         overflow: "auto",
                       }}
                     >
-                      {hit.text || ""}
+                      {content || ""}
                     </SyntaxHighlighter>
                   ) : (
                     <div className="prose prose-invert prose-sm max-w-none p-4 bg-slate-900">
-                      <ReactMarkdown>{hit.text}</ReactMarkdown>
+                      <ReactMarkdown>{content || ""}</ReactMarkdown>
                     </div>
                   )
                 ) : (
@@ -329,7 +495,7 @@ const ResultCard = memo(function ResultCard({ hit, index }: { hit: SearchResult;
                     showLineNumbers={hit.start_line !== undefined}
                     startingLineNumber={hit.start_line || 1}
                   >
-                    {hit.text || ""}
+                    {content || ""}
                   </SyntaxHighlighter>
                 )}
               </div>
@@ -368,19 +534,150 @@ export default function Home() {
   const [answer, setAnswer] = useState<string | null>(null);
   const [stepsTaken, setStepsTaken] = useState<number>(0);
   const [searchTime, setSearchTime] = useState<number>(0);
+  const [stores, setStores] = useState<any[]>([]);
+  const [store, setStore] = useState<string>("");
+  const [queryId, setQueryId] = useState<string | null>(null);
+  const [explain, setExplain] = useState(false);
+  const [suggestions, setSuggestions] = useState<string[]>([]);
+  const [didYouMean, setDidYouMean] = useState<string | null>(null);
+  const [showFilters, setShowFilters] = useState(false);
+  const [includePaths, setIncludePaths] = useState("");
+  const [excludePaths, setExcludePaths] = useState("");
+  const [extensions, setExtensions] = useState("");
+  const [modifiedSince, setModifiedSince] = useState("");
+  const [contentLanguages, setContentLanguages] = useState("");
+  const [languages, setLanguages] = useState("");
+  const [connections, setConnections] = useState("");
+  const [facets, setFacets] = useState<SearchFacets>({});
+  const [parsedQuery, setParsedQuery] = useState<ParsedQuery | null>(null);
+  const [pagedSearch, setPagedSearch] = useState<PagedSearch | null>(null);
+  const [hasMore, setHasMore] = useState(false);
+  const [loadingMore, setLoadingMore] = useState(false);
+  const sentinelRef = useRef<HTMLDivElement>(null);
+  const liveSocket = useRef<WebSocket | null>(null);
+  const liveSeq = useRef(0);
+  const renderedSeq = useRef(0);
+
+  useEffect(() => {
+    // Most used stores first
+    api.listStores("usage").then(setStores).catch(console.error);
+  }, []);
+
+  // Suggest known symbols while the last word is a symbol: filter, else
+  // complete the last word from the store's vocabulary
+  useEffect(() => {
+    const match = query.match(/(?:^|\s)symbol:(\S*)$/);
+    const lastWord = query.match(/(?:^|\s)([A-Za-z_]\w+)$/);
+    if (!match && !lastWord) {
+      setSuggestions([]);
+      return;
+    }
+    const timer = setTimeout(() => {
+      if (match) {
+        const head = query.slice(0, query.length - match[1].length);
+        api
+          .listSymbols(store || "public", match[1])
+          .then((res) => setSuggestions(res.symbols.map((s) => head + s.symbol)))
+          .catch(() => setSuggestions([]));
+      } else {
+        api
+          .suggest(store || "public", query)
+          .then((res) => setSuggestions(res.completions.map((c) => c.query)))
+          .catch(() => setSuggestions([]));
+      }
+    }, 200);
+    return () => clearTimeout(timer);
+  }, [query, store]);
+
+  const currentFilters = (): SearchFilters => ({
+    paths: splitList(includePaths),
+    exclude_paths: splitList(excludePaths),
+    extensions: splitList(extensions),
+    modified_since: modifiedSince || undefined,
+    content_languages: splitList(contentLanguages),
+    languages: splitList(languages),
+    connections: splitList(connections),
+  });
+
+  // Narrow to a facet value by adding its filter to the filter inputs
+  const applyFacet = (facet: FacetValue) => {
+    const add = (setter: (update: (prev: string) => string) => void, items?: string[]) =>
+      items?.forEach((item) => setter((prev) => appendToList(prev, item)));
+    add(setIncludePaths, facet.filter.paths);
+    add(setLanguages, facet.filter.languages);
+    add(setConnections, facet.filter.connections);
+    setShowFilters(true);
+  };
+
+  // Search as you type in search mode. The server debounces and drops
+  // superseded queries; replies older than what is shown are ignored.
+  useEffect(() => {
+    if (mode !== "search") return;
+    const socket = new WebSocket(api.liveSearchUrl());
+    socket.onmessage = (event) => {
+      const reply: LiveSearchReply = JSON.parse(event.data);
+      if (reply.type !== "results" || reply.seq == null || reply.seq <= renderedSeq.current) return;
+      renderedSeq.current = reply.seq;
+      setAnswer(null);
+      setResults(reply.results || []);
+      setFacets(reply.facets || {});
+      setParsedQuery(reply.parsed_query || null);
+      setDidYouMean(reply.did_you_mean || null);
+      setQueryId(reply.query_id || null);
+      setSearchTime((reply.took_ms || 0) / 1000);
+      setPagedSearch(null);
+      setHasMore(false);
+    };
+    liveSocket.current = socket;
+    return () => {
+      socket.close();
+      liveSocket.current = null;
+    };
+  }, [mode]);
+
+  useEffect(() => {
+    const socket = liveSocket.current;
+    if (mode !== "search" || !socket || socket.readyState !== WebSocket.OPEN) return;
+    socket.send(
+      JSON.stringify({
+        seq: ++liveSeq.current,
+        query,
+        store: store || undefined,
+        limit: PAGE_SIZE,
+        facets: true,
+        ...currentFilters(),
+      }),
+    );
+  }, [query, store, mode, includePaths, excludePaths, extensions, modifiedSince, contentLanguages, languages, connections]);
 
   const handleSearch = async (e?: React.FormEvent) => {
     e?.preventDefault();
     if (!query.trim()) return;
 
+    // A full search replaces live results still on their way
+    renderedSeq.current = liveSeq.current;
     setLoading(true);
     setAnswer(null);
     setResults([]);
+    setFacets({});
+    setParsedQuery(null);
+    setDidYouMean(null);
     setStepsTaken(0);
+    setQueryId(null);
+    setPagedSearch(null);
+    setHasMore(false);
     const startTime = Date.now();
 
     try {
-      const res = await api.search(query, mode);
+      const filters = currentFilters();
+      const res = await api.search(
+        query,
+        mode,
+        store,
+        mode === "search" && explain,
+        mode === "search" ? filters : {},
+        mode === "search" ? { offset: 0, limit: PAGE_SIZE } : undefined,
+      );
       setSearchTime((Date.now() - startTime) / 1000);
 
       if (mode === "rag") {
@@ -390,6 +687,12 @@ export default function Home() {
         if (res.steps_taken) setStepsTaken(res.steps_taken);
       } else {
         setResults(res.results || []);
+        setFacets(res.facets || {});
+        setParsedQuery(res.parsed_query || null);
+        setDidYouMean(res.did_you_mean || null);
+        setQueryId(res.query_id || null);
+        setHasMore(!!res.page?.has_more);
+        setPagedSearch({ query, store, explain, filters });
       }
     } catch (err) {
       console.error(err);
@@ -399,6 +702,72 @@ export default function Home() {
     }
   };
 
+  const handleExport = async (format: "csv" | "json") => {
+    if (!pagedSearch) return;
+    try {
+      const { blob, filename } = await api.exportResults(
+        pagedSearch.query,
+        format,
+        pagedSearch.store,
+        pagedSearch.filters,
+      );
+      const url = URL.createObjectURL(blob);
+      const link = document.createElement("a");
+      link.href = url;
+      link.download = filename;
+      link.click();
+      URL.revokeObjectURL(url);
+    } catch (err) {
+      console.error(err);
+      alert(err instanceof Error ? err.message : "Export failed");
+    }
+  };
+
+  const loadMore = useCallback(async () => {
+    if (!pagedSearch || !hasMore || loadingMore) return;
+    setLoadingMore(true);
+    try {
+      const res = await api.search(
+        pagedSearch.query,
+        "search",
+        pagedSearch.store,
+        pagedSearch.explain,
+        pagedSearch.filters,
+        { offset: results.length, limit: PAGE_SIZE },
+      );
+      setResults((prev) => [...prev, ...(res.results || [])]);
+      setHasMore(!!res.page?.has_more);
+    } catch (err) {
+      console.error(err);
+      setHasMore(false);
+    } finally {
+      setLoadingMore(false);
+    }
+  }, [pagedSearch, hasMore, loadingMore, results.length]);
+
+  // Load the next page when the end of the list scrolls into view
+  useEffect(() => {
+    const sentinel = sentinelRef.current;
+    if (!sentinel || !hasMore) return;
+    const observer = new IntersectionObserver(
+      (entries) => {
+        if (entries[0].isIntersecting) loadMore();
+      },
+      { rootMargin: "400px" },
+    );
+    observer.observe(sentinel);
+    return () => observer.disconnect();
+  }, [hasMore, loadMore]);
+
+  const handleOpen = useCallback(
+    (hit: SearchResult) => {
+      if (queryId && hit.chunk_id) {
+        api.sendFeedback(queryId, hit.chunk_id, "click");
+      }
+    },
+    [queryId],
+  );
+
   return (
     <main className="flex min-h-screen flex-col items-center px-4 pt-24 pb-12">
       {/* Hero */}
@@ -433,10 +802,47 @@ export default function Home() {
                 value={query}
                 onChange={(e) => setQuery(e.target.value)}
                 placeholder={
-                  mode === "rag" ? "Ask anything..." : "Search documents..."
+                  mode === "rag"
+                    ? "Ask anything..."
+                    : 'Search code... (lang:go path:cmd/ "exact phrase" -vendor)'
                 }
+                list="query-suggestions"
                 className="border-0 bg-transparent focus:ring-0 text-lg h-12"
               />
+              <datalist id="query-suggestions">
+                {suggestions.map((s) => (
+                  <option key={s} value={s} />
+                ))}
+              </datalist>
+              {stores.length > 1 && (
+                <select
+                  value={store}
+                  onChange={(e) => setStore(e.target.value)}
+                  title="Store"
+                  className="bg-slate-800 text-slate-300 text-xs rounded-lg px-2 py-2 border border-slate-700 focus:outline-none focus:border-indigo-500 max-w-[9rem]"
+                >
+                  <option value="">Default store</option>
+                  {stores.map((s) => (
+                    <option key={s.id} value={s.id}>
+                      {s.name}
+                    </option>
+                  ))}
+                </select>
+              )}
+              {mode === "search" && (
+                <label
+                  className="flex items-center gap-1 text-xs text-slate-400 cursor-pointer select-none"
+                  title="Show how each result's score was composed"
+                >
+                  <input
+                    type="checkbox"
+                    checked={explain}
+                    onChange={(e) => setExplain(e.target.checked)}
+                    className="accent-indigo-500"
+                  />
+                  Explain
+                </label>
+              )}
               <div className="flex bg-slate-800 rounded-lg p-1 gap-1">
                 <button
                   type="button"
@@ -475,6 +881,86 @@ export default function Home() {
               </Button>
             </div>
           </div>
+          {mode === "search" && (
+            <div className="mt-3 text-left">
+              <button
+                type="button"
+                onClick={() => setShowFilters(!showFilters)}
+                className="flex items-center gap-1 text-xs text-slate-400 hover:text-white"
+              >
+                <SlidersHorizontal size={14} />
+                Filters
+                {showFilters ? <ChevronUp size={14} /> : <ChevronDown size={14} />}
+              </button>
+              {showFilters && (
+                <div className="mt-2 grid grid-cols-2 gap-2 bg-slate-900 border border-slate-800 rounded-lg p-3 text-xs text-slate-400">
+                  <label className="flex flex-col gap-1">
+                    Include paths
+                    <input
+                      value={includePaths}
+                      onChange={(e) => setIncludePaths(e.target.value)}
+                      placeholder="src/**, **/*.go"
+                      className="bg-slate-800 text-slate-200 rounded px-2 py-1 border border-slate-700 focus:outline-none focus:border-indigo-500"
+                    />
+                  </label>
+                  <label className="flex flex-col gap-1">
+                    Exclude paths
+                    <input
+                      value={excludePaths}
+                      onChange={(e) => setExcludePaths(e.target.value)}
+                      placeholder="**/test/**, **/vendor/**"
+                      className="bg-slate-800 text-slate-200 rounded px-2 py-1 border border-slate-700 focus:outline-none focus:border-indigo-500"
+                    />
+                  </label>
+                  <label className="flex flex-col gap-1">
+                    Extensions
+                    <input
+                      value={extensions}
+                      onChange={(e) => setExtensions(e.target.value)}
+                      placeholder="go, py"
+                      className="bg-slate-800 text-slate-200 rounded px-2 py-1 border border-slate-700 focus:outline-none focus:border-indigo-500"
+                    />
+                  </label>
+                  <label className="flex flex-col gap-1">
+                    Modified since
+                    <input
+                      type="date"
+                      value={modifiedSince}
+                      onChange={(e) => setModifiedSince(e.target.value)}
+                      className="bg-slate-800 text-slate-200 rounded px-2 py-1 border border-slate-700 focus:outline-none focus:border-indigo-500"
+                    />
+                  </label>
+                  <label className="flex flex-col gap-1">
+                    Docs language
+                    <input
+                      value={contentLanguages}
+                      onChange={(e) => setContentLanguages(e.target.value)}
+                      placeholder="en, de, ja"
+                      className="bg-slate-800 text-slate-200 rounded px-2 py-1 border border-slate-700 focus:outline-none focus:border-indigo-500"
+                    />
+                  </label>
+                  <label className="flex flex-col gap-1">
+                    Languages
+                    <input
+                      value={languages}
+                      onChange={(e) => setLanguages(e.target.value)}
+                      placeholder="python, go"
+                      className="bg-slate-800 text-slate-200 rounded px-2 py-1 border border-slate-700 focus:outline-none focus:border-indigo-500"
+                    />
+                  </label>
+                  <label className="flex flex-col gap-1">
+                    Connections
+                    <input
+                      value={connections}
+                      onChange={(e) => setConnections(e.target.value)}
+                      placeholder="laptop-1"
+                      className="bg-slate-800 text-slate-200 rounded px-2 py-1 border border-slate-700 focus:outline-none focus:border-indigo-500"
+                    />
+                  </label>
+                </div>
+              )}
+            </div>
+          )}
         </form>
 
         {/* Loading indicator for Ask AI */}
@@ -491,8 +977,96 @@ export default function Home() {
         <div className="w-full max-w-3xl mx-auto text-left space-y-6 mt-12 pb-20">
           {/* Search stats */}
           {!loading && results.length > 0 && (
-            <div className="text-xs text-slate-500 px-1">
-              Found {results.length} results in {searchTime.toFixed(2)}s
+            <div className="flex items-center justify-between text-xs text-slate-500 px-1">
+              <span>
+                {hasMore ? "Showing first" : "Found"} {results.length} results
+                in {searchTime.toFixed(2)}s
+              </span>
+              {pagedSearch && (
+                <span className="flex items-center gap-2">
+                  <Download size={12} />
+                  {(["csv", "json"] as const).map((format) => (
+                    <button
+                      key={format}
+                      onClick={() => handleExport(format)}
+                      className="uppercase hover:text-slate-300 transition-colors"
+                    >
+                      {format}
+                    </button>
+                  ))}
+                </span>
+              )}
+            </div>
+          )}
+
+          {/* Spelling fix when few results came back */}
+          {!loading && mode === "search" && didYouMean && (
+            <div className="text-sm text-slate-400 px-1">
+              Did you mean{" "}
+              <button
+                onClick={() => {
+                  setQuery(didYouMean);
+                  setDidYouMean(null);
+                }}
+                className="text-indigo-400 hover:text-indigo-300 underline underline-offset-2"
+              >
+                {didYouMean}
+              </button>
+              ?
+            </div>
+          )}
+
+          {/* How inline filters in the query were read */}
+          {!loading && mode === "search" && parsedQuery && parsedQuery.tokens.length > 0 && (
+            <div className="flex flex-wrap items-center gap-1.5 text-xs px-1">
+              <span className="text-slate-500">Interpreted as</span>
+              {parsedQuery.text && (
+                <span className="px-2 py-0.5 rounded bg-slate-800 text-slate-200 border border-slate-700">
+                  {parsedQuery.text}
+                </span>
+              )}
+              {parsedQuery.tokens.map((token) => (
+                <span
+                  key={token.token}
+                  title={token.token}
+                  className={`px-2 py-0.5 rounded-full border ${
+                    token.negated
+                      ? "bg-red-500/10 text-red-300 border-red-500/30"
+                      : "bg-indigo-500/10 text-indigo-300 border-indigo-500/30"
+                  }`}
+                >
+                  {token.negated ? "not " : ""}
+                  {token.field}: {token.value}
+                </span>
+              ))}
+              {parsedQuery.warnings?.map((warning) => (
+                <span key={warning} className="text-yellow-400">
+                  {warning}
+                </span>
+              ))}
+            </div>
+          )}
+
+          {/* Facets: click a value to filter on it */}
+          {!loading && mode === "search" && results.length > 0 && (
+            <div className="space-y-1 px-1">
+              {(Object.keys(FACET_LABELS) as (keyof SearchFacets)[])
+                .filter((name) => facets[name]?.length)
+                .map((name) => (
+                  <div key={name} className="flex flex-wrap items-center gap-1.5 text-xs">
+                    <span className="text-slate-500 w-20">{FACET_LABELS[name]}</span>
+                    {facets[name]!.map((facet) => (
+                      <button
+                        key={facet.value}
+                        onClick={() => applyFacet(facet)}
+                        className="px-2 py-0.5 rounded-full bg-slate-800 hover:bg-indigo-500/30 text-slate-300 border border-slate-700 transition-colors"
+                      >
+                        {facet.value}
+                        <span className="ml-1 text-slate-500">{facet.count}</span>
+                      </button>
+                    ))}
+                  </div>
+                ))}
             </div>
           )}
 
@@ -550,8 +1124,26 @@ export default function Home() {
                 </span>
               </div>
               {results.map((hit, i) => (
-                <ResultCard key={i} hit={hit} index={i} />
+                <ResultCard
+                  key={hit.chunk_id ?? i}
+                  hit={hit}
+                  index={i}
+                  store={pagedSearch?.store || undefined}
+                  onOpen={handleOpen}
+                />
               ))}
+              {hasMore && (
+                <div ref={sentinelRef} className="flex justify-center pt-2">
+                  <button
+                    onClick={loadMore}
+                    disabled={loadingMore}
+                    className="flex items-center gap-2 text-xs px-3 py-2 bg-slate-800 hover:bg-slate-700 rounded-lg text-slate-300"
+                  >
+                    {loadingMore && <Loader2 size={14} className="animate-spin" />}
+                    {loadingMore ? "Loading..." : "Load more results"}
+                  </button>
+                </div>
+              )}
             </div>
           )}
         </div>
@@ -1,7 +1,17 @@
 "use client";
 
+import { useEffect } from "react";
 import { SessionProvider } from "next-auth/react";
+import { watchCacheInvalidation } from "@/lib/api";
+import { ServerModeBanner } from "@/components/server-mode-banner";
 
 export default function Providers({ children }: { children: React.ReactNode }) {
-  return <SessionProvider>{children}</SessionProvider>;
+  useEffect(() => watchCacheInvalidation(), []);
+
+  return (
+    <SessionProvider>
+      <ServerModeBanner />
+      {children}
+    </SessionProvider>
+  );
 }
@@ -0,0 +1,187 @@
+"use client";
+
+import { useEffect, useState } from "react";
+import { useParams } from "next/navigation";
+import Link from "next/link";
+import { api, DuplicateChunk, DuplicateReport } from "@/lib/api";
+import { Button, Card } from "@/components/ui-elements";
+import { ArrowLeft, Copy, RefreshCw } from "lucide-react";
+
+const lines = (chunk: DuplicateChunk) =>
+  chunk.start_line ? `:${chunk.start_line}-${chunk.end_line}` : "";
+
+const percent = (similarity: number) => `${(similarity * 100).toFixed(1)}%`;
+
+export default function DuplicateCode() {
+  const params = useParams();
+  const id = params.id as string;
+
+  const [report, setReport] = useState<DuplicateReport | null>(null);
+  const [error, setError] = useState<string | null>(null);
+  const [starting, setStarting] = useState(false);
+  const [view, setView] = useState<"clusters" | "files">("clusters");
+
+  const load = async () => {
+    try {
+      setReport(await api.getDuplicateReport(id));
+      setError(null);
+    } catch (err) {
+      setError((err as Error).message);
+    }
+  };
+
+  useEffect(() => {
+    load();
+  }, [id]);
+
+  // Poll while a scan is queued or running
+  const pending = report?.state === "queued" || report?.state === "running";
+  useEffect(() => {
+    if (!pending) return;
+    const timer = setInterval(load, 3000);
+    return () => clearInterval(timer);
+  }, [pending, id]);
+
+  const handleScan = async () => {
+    try {
+      setStarting(true);
+      await api.buildDuplicateReport(id);
+      await load();
+    } catch (err) {
+      alert((err as Error).message);
+    } finally {
+      setStarting(false);
+    }
+  };
+
+  return (
+    <main className="min-h-screen bg-dark p-8">
+      <div className="max-w-6xl mx-auto mb-8">
+        <Link href={`/stores/${id}`} className="inline-flex items-center text-slate-400 hover:text-white mb-6 transition-colors">
+          <ArrowLeft className="w-4 h-4 mr-2" />
+          Back to Store
+        </Link>
+
+        <div className="flex flex-col md:flex-row md:items-start justify-between gap-6">
+          <div>
+            <h1 className="text-3xl font-bold text-white tracking-tight flex items-center gap-3">
+              <Copy className="w-7 h-7 text-primary" /> Duplicate Code
+            </h1>
+            <p className="text-slate-400 max-w-2xl mt-2">
+              Near-identical chunks in different files of <span className="font-mono">{id}</span>: copied
+              helpers, vendored libraries and generated code.
+            </p>
+          </div>
+          <Button onClick={handleScan} disabled={starting || pending} className="shrink-0">
+            {pending ? "Scanning..." : report?.state ? "Scan Again" : "Scan Store"}
+            <RefreshCw className={`w-4 h-4 ml-2 ${pending ? "animate-spin" : ""}`} />
+          </Button>
+        </div>
+      </div>
+
+      <div className="max-w-6xl mx-auto space-y-6">
+        {error && <div className="text-red-400">{error}</div>}
+
+        {report?.state === "failed" && (
+          <div className="p-4 rounded-lg border border-red-800 bg-red-900/20 text-sm text-red-300">
+            Last scan failed: {report.error}
+          </div>
+        )}
+
+        {report && !report.state && (
+          <Card className="p-12 bg-dark-secondary border-border text-center text-slate-500">
+            No duplicate report yet. Scanning compares every chunk with its most similar chunks and runs on the worker.
+          </Card>
+        )}
+
+        {report?.clusters && (
+          <>
+            <div className="grid grid-cols-2 md:grid-cols-4 gap-4">
+              {[
+                ["Duplicate Chunks", report.duplicate_chunks],
+                ["Clusters", report.total_clusters],
+                ["File Pairs", report.total_file_pairs],
+                ["Threshold", report.threshold !== undefined ? percent(report.threshold) : "-"],
+              ].map(([label, value]) => (
+                <Card key={label as string} className="p-4 bg-dark-secondary border-border">
+                  <div className="text-2xl font-mono text-white">{value}</div>
+                  <div className="text-xs text-slate-500">{label}</div>
+                </Card>
+              ))}
+            </div>
+
+            <div className="text-xs text-slate-500">
+              {report.finished_at && `Scanned ${new Date(report.finished_at).toLocaleString()}`}
+              {report.truncated && " (store is larger than the scan limit; only part of it was compared)"}
+            </div>
+
+            <div className="flex gap-2">
+              <Button variant={view === "clusters" ? "primary" : "secondary"} onClick={() => setView("clusters")}>
+                Clusters
+              </Button>
+              <Button variant={view === "files" ? "primary" : "secondary"} onClick={() => setView("files")}>
+                File Pairs
+              </Button>
+            </div>
+
+            {view === "clusters" ? (
+              report.clusters.length === 0 ? (
+                <Card className="p-12 bg-dark-secondary border-border text-center text-slate-500">
+                  No duplicates found.
+                </Card>
+              ) : (
+                report.clusters.map((cluster) => (
+                  <Card key={cluster.id} className="bg-dark-secondary border-border overflow-hidden">
+                    <div className="p-4 border-b border-border bg-slate-800/50 flex items-center justify-between text-sm">
+                      <span className="text-white font-medium">
+                        {cluster.size} chunks in {cluster.files} files
+                      </span>
+                      <span className="text-slate-400 font-mono">
+                        {cluster.min_similarity === cluster.max_similarity
+                          ? percent(cluster.max_similarity)
+                          : `${percent(cluster.min_similarity)} - ${percent(cluster.max_similarity)}`}
+                      </span>
+                    </div>
+                    <div className="divide-y divide-border">
+                      {cluster.chunks.map((chunk) => (
+                        <div key={chunk.chunk_id} className="px-4 py-2 text-sm font-mono text-slate-300 flex justify-between">
+                          <span className="truncate">{chunk.path}{lines(chunk)}</span>
+                          <span className="text-slate-500 ml-4">{chunk.language}</span>
+                        </div>
+                      ))}
+                    </div>
+                  </Card>
+                ))
+              )
+            ) : (
+              <Card className="bg-dark-secondary border-border overflow-hidden">
+                <table className="w-full text-sm">
+                  <thead className="bg-slate-800/50 text-slate-400 text-left">
+                    <tr>
+                      <th className="p-3 font-medium">File</th>
+                      <th className="p-3 font-medium">File</th>
+                      <th className="p-3 font-medium text-right">Shared Chunks</th>
+                      <th className="p-3 font-medium text-right">Max</th>
+                      <th className="p-3 font-medium text-right">Mean</th>
+                    </tr>
+                  </thead>
+                  <tbody className="divide-y divide-border font-mono text-slate-300">
+                    {report.file_pairs!.map((pair) => (
+                      <tr key={`${pair.file_a}|${pair.file_b}`}>
+                        <td className="p-3 truncate max-w-xs" title={pair.file_a}>{pair.file_a}</td>
+                        <td className="p-3 truncate max-w-xs" title={pair.file_b}>{pair.file_b}</td>
+                        <td className="p-3 text-right">{pair.chunks}</td>
+                        <td className="p-3 text-right">{percent(pair.max_similarity)}</td>
+                        <td className="p-3 text-right">{percent(pair.mean_similarity)}</td>
+                      </tr>
+                    ))}
+                  </tbody>
+                </table>
+              </Card>
+            )}
+          </>
+        )}
+      </div>
+    </main>
+  );
+}
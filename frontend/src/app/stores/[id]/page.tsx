@@ -3,9 +3,9 @@
 import { useEffect, useState } from "react";
 import { useParams, useRouter } from "next/navigation";
 import Link from "next/link";
-import { api } from "@/lib/api";
+import { api, StoreAclStatus, StoreMetrics, StoreStats } from "@/lib/api";
 import { Button, Card, Input } from "@/components/ui-elements";
-import { ArrowLeft, File as FileIcon, Search, Trash2, Database, Shield, Server } from "lucide-react";
+import { ArrowLeft, File as FileIcon, Search, Trash2, Database, Shield, Server, Activity, AlertTriangle, Lock, Unlock, Copy } from "lucide-react";
 
 type Store = {
   id: string;
@@ -15,8 +15,150 @@ type Store = {
   type: "prod" | "staging" | "dev";
   doc_count: number;
   created_at?: string;
+  stats?: StoreStats;
 };
 
+type EmbeddingStatus = {
+  mismatch: boolean;
+  reason: string | null;
+  current_model: string;
+  migration: { state: string; task_id?: string; error?: string } | null;
+};
+
+const formatBytes = (bytes: number) => {
+  const units = ["B", "KB", "MB", "GB", "TB"];
+  let value = bytes;
+  let unit = 0;
+  while (value >= 1024 && unit < units.length - 1) {
+    value /= 1024;
+    unit++;
+  }
+  return `${unit === 0 ? value : value.toFixed(1)} ${units[unit]}`;
+};
+
+// One principal per line ("key:alice", "connection:conn_1a2b")
+const toLines = (entries: string[] = []) => entries.join("\n");
+const fromLines = (text: string) =>
+  text.split("\n").map((line) => line.trim()).filter(Boolean);
+
+function StoreAccessCard({ storeId }: { storeId: string }) {
+  const [status, setStatus] = useState<StoreAclStatus | null>(null);
+  const [owner, setOwner] = useState("");
+  const [readers, setReaders] = useState("");
+  const [writers, setWriters] = useState("");
+  const [saving, setSaving] = useState(false);
+  const [error, setError] = useState<string | null>(null);
+
+  const show = (data: StoreAclStatus) => {
+    setStatus(data);
+    setOwner(data.acl?.owner ?? "");
+    setReaders(toLines(data.acl?.readers));
+    setWriters(toLines(data.acl?.writers));
+  };
+
+  useEffect(() => {
+    api.getStoreAcl(storeId).then(show).catch(() => setStatus(null));
+  }, [storeId]);
+
+  const save = async () => {
+    setSaving(true);
+    setError(null);
+    try {
+      show(
+        await api.setStoreAcl(storeId, {
+          owner: owner.trim() || undefined,
+          readers: fromLines(readers),
+          writers: fromLines(writers),
+        })
+      );
+    } catch (err) {
+      setError((err as Error).message);
+    } finally {
+      setSaving(false);
+    }
+  };
+
+  const open = async () => {
+    if (!confirm("Remove the access list? Everyone will be able to search and index this store.")) return;
+    setSaving(true);
+    setError(null);
+    try {
+      show(await api.deleteStoreAcl(storeId));
+    } catch (err) {
+      setError((err as Error).message);
+    } finally {
+      setSaving(false);
+    }
+  };
+
+  if (!status) return null;
+  const editable = status.access.owner;
+
+  return (
+    <Card className="p-4 bg-dark-secondary border-border">
+      <h3 className="text-sm font-semibold text-slate-400 mb-4 uppercase tracking-wider flex items-center gap-2">
+        {status.restricted ? <Lock className="w-4 h-4" /> : <Unlock className="w-4 h-4" />} Access
+        <span className={`ml-auto text-xs normal-case ${status.restricted ? "text-yellow-400" : "text-slate-500"}`}>
+          {status.restricted ? "Restricted" : "Open"}
+        </span>
+      </h3>
+      <div className="space-y-3 text-xs">
+        <label className="block">
+          <span className="text-slate-500">Owner</span>
+          <Input
+            value={owner}
+            onChange={(e) => setOwner(e.target.value)}
+            placeholder="key:you (default)"
+            disabled={!editable}
+            className="mt-1 font-mono text-xs"
+          />
+        </label>
+        <label className="block">
+          <span className="text-slate-500">Writers (index and delete)</span>
+          <textarea
+            value={writers}
+            onChange={(e) => setWriters(e.target.value)}
+            placeholder="connection:conn_1a2b"
+            disabled={!editable}
+            rows={3}
+            className="mt-1 w-full rounded-lg border border-slate-700 bg-slate-900 px-3 py-2 font-mono text-white placeholder:text-slate-500 focus:outline-none focus:ring-2 focus:ring-indigo-500 disabled:opacity-50"
+          />
+        </label>
+        <label className="block">
+          <span className="text-slate-500">Readers (search)</span>
+          <textarea
+            value={readers}
+            onChange={(e) => setReaders(e.target.value)}
+            placeholder="key:bob"
+            disabled={!editable}
+            rows={3}
+            className="mt-1 w-full rounded-lg border border-slate-700 bg-slate-900 px-3 py-2 font-mono text-white placeholder:text-slate-500 focus:outline-none focus:ring-2 focus:ring-indigo-500 disabled:opacity-50"
+          />
+        </label>
+        <p className="text-slate-500">
+          One <span className="font-mono">key:&lt;user&gt;</span> or{" "}
+          <span className="font-mono">connection:&lt;id&gt;</span> per line. Admins always have access.
+        </p>
+        {error && <div className="text-red-400">{error}</div>}
+        {editable ? (
+          <div className="flex gap-2">
+            <Button onClick={save} disabled={saving} className="flex-1">
+              {saving ? "Saving..." : status.restricted ? "Save" : "Restrict"}
+            </Button>
+            {status.restricted && (
+              <Button variant="secondary" onClick={open} disabled={saving}>
+                Open
+              </Button>
+            )}
+          </div>
+        ) : (
+          <div className="text-slate-500">Only the owner or an admin can change access.</div>
+        )}
+      </div>
+    </Card>
+  );
+}
+
 export default function StoreDetail() {
   const params = useParams();
   const router = useRouter();
@@ -28,16 +170,65 @@ export default function StoreDetail() {
   const [error, setError] = useState<string | null>(null);
   const [searchQuery, setSearchQuery] = useState("");
   const [isDeleting, setIsDeleting] = useState(false);
+  const [metrics, setMetrics] = useState<StoreMetrics | null>(null);
+  const [live, setLive] = useState(false);
+  const [embedding, setEmbedding] = useState<EmbeddingStatus | null>(null);
+  const [migration, setMigration] = useState<{ current: number; total: number } | null>(null);
 
   useEffect(() => {
     fetchData();
   }, [id]);
 
+  // Live metrics pushed by the backend (no manual refresh)
+  useEffect(() => {
+    const source = new EventSource(api.storeMetricsUrl(id));
+    source.onopen = () => setLive(true);
+    source.onerror = () => setLive(false);
+    source.addEventListener("metrics", (msg) => {
+      setMetrics(JSON.parse((msg as MessageEvent).data));
+    });
+    return () => {
+      source.close();
+      setLive(false);
+    };
+  }, [id]);
+
+  // Poll the migration job while one is running
+  useEffect(() => {
+    const taskId = embedding?.migration?.state === "running" ? embedding.migration.task_id : undefined;
+    if (!taskId) return;
+    const timer = setInterval(async () => {
+      try {
+        const job = await api.getJob(taskId);
+        if (job.progress?.total) setMigration({ current: job.progress.current, total: job.progress.total });
+        if (job.state === "SUCCESS" || job.state === "FAILURE") {
+          setMigration(null);
+          fetchData();
+        }
+      } catch (err) {
+        console.error(err);
+      }
+    }, 2000);
+    return () => clearInterval(timer);
+  }, [embedding?.migration?.state, embedding?.migration?.task_id]);
+
+  const handleMigrate = async () => {
+    if (!embedding || !confirm(`Re-embed every chunk of this store with ${embedding.current_model}?`)) return;
+    try {
+      const job = await api.migrateStore(id);
+      setMigration({ current: 0, total: job.total });
+      setEmbedding(await api.getStoreMigration(id));
+    } catch (err) {
+      alert((err as Error).message);
+    }
+  };
+
   const fetchData = async () => {
     try {
       setLoading(true);
       const storeData = await api.getStore(id);
       setStore(storeData);
+      api.getStoreMigration(id).then(setEmbedding).catch(() => setEmbedding(null));
       
       // Fetch files for this store (org_id)
       // Assuming store.org_id corresponds to the org_id used in file listing
@@ -64,7 +255,7 @@ export default function StoreDetail() {
   };
 
   const handleDelete = async () => {
-    if (!confirm("Are you sure you want to delete this store? This action cannot be undone.")) return;
+    if (!confirm("Move this store to the trash? It can be restored from the Store Gallery until it is purged.")) return;
     
     try {
       setIsDeleting(true);
@@ -134,18 +325,50 @@ export default function StoreDetail() {
             </div>
           </div>
 
-          <Button 
-            variant="secondary" 
-            onClick={handleDelete} 
-            disabled={isDeleting}
-            className="shrink-0 bg-red-900/20 text-red-500 hover:bg-red-900/40 border-red-900/50"
-          >
-            {isDeleting ? "Deleting..." : "Delete Store"}
-            <Trash2 className="w-4 h-4 ml-2" />
-          </Button>
+          <div className="flex gap-2 shrink-0">
+            <Link href={`/stores/${id}/duplicates`}>
+              <Button variant="secondary">
+                Duplicate Code
+                <Copy className="w-4 h-4 ml-2" />
+              </Button>
+            </Link>
+            <Button 
+              variant="secondary" 
+              onClick={handleDelete} 
+              disabled={isDeleting}
+              className="bg-red-900/20 text-red-500 hover:bg-red-900/40 border-red-900/50"
+            >
+              {isDeleting ? "Deleting..." : "Delete Store"}
+              <Trash2 className="w-4 h-4 ml-2" />
+            </Button>
+          </div>
         </div>
       </div>
 
+      {embedding && (embedding.mismatch || embedding.migration?.state === "running") && (
+        <div className="max-w-6xl mx-auto mb-8 p-4 rounded-lg border border-yellow-800 bg-yellow-900/20 flex items-center gap-4">
+          <AlertTriangle className="w-5 h-5 text-yellow-400 shrink-0" />
+          <div className="flex-1 text-sm">
+            {embedding.migration?.state === "running" ? (
+              <span className="text-yellow-200">
+                Re-embedding with {embedding.current_model}
+                {migration && `: ${migration.current} / ${migration.total} chunks`}
+              </span>
+            ) : (
+              <span className="text-yellow-200">
+                {embedding.reason}. Search quality is degraded until the store is migrated.
+                {embedding.migration?.state === "failed" && ` Last migration failed: ${embedding.migration.error}`}
+              </span>
+            )}
+          </div>
+          {embedding.migration?.state !== "running" && (
+            <Button variant="secondary" onClick={handleMigrate} className="shrink-0">
+              Migrate
+            </Button>
+          )}
+        </div>
+      )}
+
       {/* Content Area */}
       <div className="max-w-6xl mx-auto grid grid-cols-1 lg:grid-cols-4 gap-8">
         
@@ -155,15 +378,100 @@ export default function StoreDetail() {
             <h3 className="text-sm font-semibold text-slate-400 mb-4 uppercase tracking-wider">Store Stats</h3>
             <div className="space-y-4">
               <div>
-                <div className="text-2xl font-mono text-white">{files.length}</div>
+                <div className="text-2xl font-mono text-white">{store.stats?.files ?? files.length}</div>
                 <div className="text-xs text-slate-500">Indexed Files</div>
               </div>
               <div>
-                <div className="text-2xl font-mono text-white">{store.doc_count}</div>
+                <div className="text-2xl font-mono text-white">{store.stats?.chunks ?? store.doc_count}</div>
                 <div className="text-xs text-slate-500">Total Chunks</div>
               </div>
+              {store.stats && (
+                <div>
+                  <div className="text-2xl font-mono text-white">{formatBytes(store.stats.bytes)}</div>
+                  <div className="text-xs text-slate-500">Indexed Size</div>
+                </div>
+              )}
+              {store.stats && store.stats.languages.length > 0 && (
+                <div className="space-y-2">
+                  <div className="text-xs text-slate-500">Languages</div>
+                  {store.stats.languages.slice(0, 8).map((lang) => (
+                    <div key={lang.language} className="text-xs">
+                      <div className="flex justify-between font-mono text-slate-300">
+                        <span>{lang.language}</span>
+                        <span>{lang.files}</span>
+                      </div>
+                      <div className="h-1 mt-1 rounded bg-slate-800">
+                        <div
+                          className="h-1 rounded bg-primary"
+                          style={{ width: `${(lang.files / Math.max(store.stats!.files, 1)) * 100}%` }}
+                        />
+                      </div>
+                    </div>
+                  ))}
+                </div>
+              )}
+              {store.stats && !store.stats.complete && (
+                <div className="text-xs text-yellow-400">
+                  Counts cover files indexed since stats were added; an admin can rebuild them.
+                </div>
+              )}
             </div>
           </Card>
+
+          <Card className="p-4 bg-dark-secondary border-border">
+            <h3 className="text-sm font-semibold text-slate-400 mb-4 uppercase tracking-wider flex items-center gap-2">
+              <Activity className="w-4 h-4" /> Live
+              <span className={`ml-auto inline-block w-2 h-2 rounded-full ${live ? 'bg-green-400' : 'bg-slate-500'}`} />
+            </h3>
+            {!metrics ? (
+              <div className="text-xs text-slate-500">Connecting...</div>
+            ) : (
+              <div className="space-y-4">
+                <div>
+                  <div className="text-2xl font-mono text-white">{metrics.qps.toFixed(2)}</div>
+                  <div className="text-xs text-slate-500">Searches/s (last {metrics.window_seconds}s)</div>
+                </div>
+                <div>
+                  <div className="text-2xl font-mono text-white">
+                    {metrics.avg_latency_ms === null ? '-' : `${metrics.avg_latency_ms} ms`}
+                  </div>
+                  <div className="text-xs text-slate-500">Avg Search Latency</div>
+                </div>
+                <div>
+                  <div className="text-2xl font-mono text-white">{metrics.total_searches ?? 0}</div>
+                  <div className="text-xs text-slate-500">Total Searches</div>
+                </div>
+                {Object.keys(metrics.index_counts).length > 0 && (
+                  <div className="text-xs text-slate-400 font-mono space-y-1">
+                    {Object.entries(metrics.index_counts).map(([status, count]) => (
+                      <div key={status} className="flex justify-between">
+                        <span>{status}</span>
+                        <span className={status === 'error' ? 'text-red-400' : 'text-slate-300'}>{count}</span>
+                      </div>
+                    ))}
+                  </div>
+                )}
+              </div>
+            )}
+          </Card>
+
+          <StoreAccessCard storeId={id} />
+
+          {metrics && metrics.recent_index_events.length > 0 && (
+            <Card className="p-4 bg-dark-secondary border-border">
+              <h3 className="text-sm font-semibold text-slate-400 mb-4 uppercase tracking-wider">Recent Indexing</h3>
+              <div className="space-y-2">
+                {metrics.recent_index_events.slice(0, 10).map((event) => (
+                  <div key={event.id} className="text-xs">
+                    <div className={`font-mono ${event.topic.endsWith('error') ? 'text-red-400' : 'text-slate-300'}`}>
+                      {event.topic.replace('index.', '')}
+                    </div>
+                    <div className="text-slate-500 truncate" title={event.path}>{event.path}</div>
+                  </div>
+                ))}
+              </div>
+            </Card>
+          )}
         </div>
 
         {/* Main: File Browser */}
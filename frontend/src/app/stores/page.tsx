@@ -2,17 +2,22 @@
 
 import { useEffect, useState } from 'react';
 import Link from 'next/link';
-import { api } from '@/lib/api';
+import { api, TrashedStore } from '@/lib/api';
 import { Button, Card } from '@/components/ui-elements';
-import { Database, Plus, ArrowLeft, Loader2, HardDrive, Settings } from 'lucide-react';
+import { Database, Plus, ArrowLeft, Loader2, HardDrive, Settings, Search, Trash2, RotateCcw } from 'lucide-react';
 
 export default function StoreGallery() {
   const [stores, setStores] = useState<any[]>([]);
   const [loading, setLoading] = useState(true);
   const [error, setError] = useState<string | null>(null);
+  const [trash, setTrash] = useState<TrashedStore[]>([]);
+  const [retentionDays, setRetentionDays] = useState<number | null>(null);
+  const [showTrash, setShowTrash] = useState(false);
+  const [busy, setBusy] = useState<string | null>(null);
 
   useEffect(() => {
     loadStores();
+    loadTrash();
   }, []);
 
   const loadStores = async () => {
@@ -28,6 +33,41 @@ export default function StoreGallery() {
     }
   };
 
+  const loadTrash = async () => {
+    try {
+      const res = await api.listTrash();
+      setTrash(res.stores);
+      setRetentionDays(res.retention_days);
+    } catch (err) {
+      console.error(err);
+    }
+  };
+
+  const restore = async (id: string) => {
+    try {
+      setBusy(id);
+      await api.restoreStore(id);
+      await Promise.all([loadStores(), loadTrash()]);
+    } catch (err: any) {
+      alert(err.message);
+    } finally {
+      setBusy(null);
+    }
+  };
+
+  const purge = async (id: string) => {
+    if (!confirm(`Permanently delete store "${id}" and its index? This cannot be undone.`)) return;
+    try {
+      setBusy(id);
+      await api.purgeStore(id);
+      await loadTrash();
+    } catch (err: any) {
+      alert(err.message);
+    } finally {
+      setBusy(null);
+    }
+  };
+
   return (
     <main className="min-h-screen bg-dark p-8">
       {/* Header */}
@@ -44,11 +84,71 @@ export default function StoreGallery() {
           </div>
         </div>
         
-        <Button onClick={() => alert("Create Store functionality coming next!")}>
-          <Plus size={18} className="mr-2" /> New Store
-        </Button>
+        <div className="flex items-center gap-3">
+          <Button variant="outline" onClick={() => setShowTrash(!showTrash)}>
+            <Trash2 size={18} className="mr-2" /> Trash ({trash.length})
+          </Button>
+          <Button onClick={() => alert("Create Store functionality coming next!")}>
+            <Plus size={18} className="mr-2" /> New Store
+          </Button>
+        </div>
       </div>
 
+      {/* Trash */}
+      {showTrash && (
+        <div className="max-w-6xl mx-auto mb-8">
+          <Card>
+            <h2 className="text-lg font-bold text-text mb-1 flex items-center gap-2">
+              <Trash2 size={18} className="text-text-muted" /> Deleted Stores
+            </h2>
+            <p className="text-text-muted text-sm mb-4">
+              Deleted stores keep their index{retentionDays !== null ? ` for ${retentionDays} days` : ''} and can be restored until they are purged.
+            </p>
+            {trash.length === 0 ? (
+              <div className="text-text-muted text-sm">The trash is empty.</div>
+            ) : (
+              <table className="w-full text-sm">
+                <thead>
+                  <tr className="text-left text-text-muted border-b border-border">
+                    <th className="py-2 font-medium">Store</th>
+                    <th className="py-2 font-medium">Deleted</th>
+                    <th className="py-2 font-medium">Purged after</th>
+                    <th className="py-2" />
+                  </tr>
+                </thead>
+                <tbody>
+                  {trash.map((store) => (
+                    <tr key={store.id} className="border-b border-border last:border-0">
+                      <td className="py-2">
+                        <div className="text-text">{store.name}</div>
+                        <div className="font-mono text-xs text-text-muted">{store.id}</div>
+                      </td>
+                      <td className="py-2 text-text-secondary">
+                        {store.deleted_at ? new Date(store.deleted_at).toLocaleString() : '-'}
+                        {store.deleted_by && <span className="text-text-muted"> by {store.deleted_by}</span>}
+                      </td>
+                      <td className="py-2 text-text-secondary">
+                        {store.purge_after ? new Date(store.purge_after).toLocaleDateString() : '-'}
+                      </td>
+                      <td className="py-2">
+                        <div className="flex justify-end gap-2">
+                          <Button variant="ghost" size="sm" disabled={busy === store.id} onClick={() => restore(store.id)}>
+                            <RotateCcw size={14} className="mr-1" /> Restore
+                          </Button>
+                          <Button variant="ghost" size="sm" disabled={busy === store.id} onClick={() => purge(store.id)} className="text-error">
+                            <Trash2 size={14} className="mr-1" /> Purge
+                          </Button>
+                        </div>
+                      </td>
+                    </tr>
+                  ))}
+                </tbody>
+              </table>
+            )}
+          </Card>
+        </div>
+      )}
+
       {/* Grid */}
       <div className="max-w-6xl mx-auto">
         {loading ? (
@@ -85,7 +185,12 @@ export default function StoreGallery() {
                 </p>
                 
                 <div className="flex items-center justify-between text-xs text-text-secondary pt-4 border-t border-border">
-                   <div className="font-mono">{store.id}</div>
+                   <div className="flex items-center gap-3">
+                     <span className="font-mono">{store.id}</span>
+                     <span className="flex items-center gap-1 text-text-muted" title="Searches">
+                       <Search size={12} /> {store.search_count ?? 0}
+                     </span>
+                   </div>
                    {/* Link to detail page (future) */}
                    <Link href={`/stores/${store.id}`}>
                      <Button variant="ghost" size="sm" className="h-8 gap-2 hover:bg-dark-tertiary">
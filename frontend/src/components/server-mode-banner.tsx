@@ -0,0 +1,48 @@
+"use client";
+
+import { useEffect, useState } from "react";
+import { AlertTriangle, Wrench } from "lucide-react";
+import { api, ServerMode } from "@/lib/api";
+
+const POLL_MS = 15_000;
+
+// Announces read-only and maintenance modes on every page
+export function ServerModeBanner() {
+  const [mode, setMode] = useState<ServerMode | null>(null);
+
+  useEffect(() => {
+    let active = true;
+    const load = () =>
+      api
+        .getServerMode()
+        .then((state) => active && setMode(state))
+        .catch(() => {});
+    load();
+    const timer = setInterval(load, POLL_MS);
+    return () => {
+      active = false;
+      clearInterval(timer);
+    };
+  }, []);
+
+  if (!mode || mode.mode === "normal") return null;
+
+  const maintenance = mode.mode === "maintenance";
+  return (
+    <div
+      className={`w-full px-4 py-2 text-sm flex items-center justify-center gap-2 border-b ${
+        maintenance
+          ? "bg-red-900/40 border-red-800 text-red-200"
+          : "bg-yellow-900/40 border-yellow-800 text-yellow-200"
+      }`}
+    >
+      {maintenance ? <Wrench className="w-4 h-4" /> : <AlertTriangle className="w-4 h-4" />}
+      <span>
+        {maintenance
+          ? "Rice Search is down for maintenance."
+          : "Rice Search is read-only: searching works, indexing and changes are paused."}
+        {mode.reason && ` ${mode.reason}`}
+      </span>
+    </div>
+  );
+}
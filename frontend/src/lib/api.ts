@@ -1,34 +1,274 @@
 const API_BASE = "http://localhost:8000/api/v1";
 
 export type SearchResult = {
+  chunk_id?: string;
+  redirected_from?: string; // Old chunk ID from api.getChunk after the file was re-indexed
   score: number;
   rerank_score?: number; // Rerank score from backend (0-1)
-  text: string;
+  text?: string; // Omitted with include_content=false (see api.getChunk)
+  preview?: string;
+  content_length?: number;
   file_path?: string;
   start_line?: number;
   end_line?: number;
   chunk_index?: number;
+  summary?: string; // One-sentence LLM summary (stores with chunk summaries on)
   metadata?: Record<string, any>;
+  explanation?: SearchExplanation;
+};
+
+export type SearchExplanation = {
+  final_rank: number;
+  fused_rank: number | null;
+  fused_score: number;
+  retrievers: Record<
+    string,
+    { rank: number; score: number; weight: number; rrf_contribution: number }
+  >;
+  missing_from: string[];
+  rerank: {
+    score: number | null;
+    rank_before: number;
+    rank_after: number;
+    delta: number;
+  } | null;
+  // Store boosting rules that scaled the fused score
+  boosts?: { type: string; value: string | null; factor: number; score_before: number }[];
+  dedup_removed: { chunk_id: string; fused_rank: number; fused_score: number }[];
+  stages: string[];
+};
+
+// Advanced search filters (path globs like "src/**", "**/test/**")
+export type SearchFilters = {
+  paths?: string[];
+  exclude_paths?: string[];
+  extensions?: string[];
+  modified_since?: string;
+  // Docs written in these languages (ISO 639-1: "en", "de", "ja")
+  content_languages?: string[];
+  // Programming languages ("python", "go") and uploading connections
+  languages?: string[];
+  connections?: string[];
+};
+
+// One facet value with the filter that narrows results to it
+export type FacetValue = {
+  value: string;
+  count: number;
+  filter: SearchFilters;
+};
+
+export type SearchFacets = Partial<
+  Record<"language" | "directory" | "connection", FacetValue[]>
+>;
+
+export type SearchPage = {
+  offset: number;
+  limit: number;
+};
+
+// How the server read inline query syntax (lang:go path:cmd/ "phrase" -vendor)
+export type ParsedQuery = {
+  raw: string;
+  text: string;
+  filters: Record<string, any>;
+  tokens: { token: string; field: string; value: string; negated: boolean }[];
+  warnings?: string[];
+};
+
+// GET /stores/{id}/suggest
+export type QuerySuggestions = {
+  query: string;
+  completions: { text: string; query: string; kind: "term" | "symbol"; count: number }[];
+  did_you_mean: string | null;
+  corrections: { word: string; suggestion: string; count: number }[];
 };
 
 export type SearchResponse = {
+  query_id?: string;
+  parsed_query?: ParsedQuery;
+  // Spelling-corrected query when few results were found
+  did_you_mean?: string;
   answer?: string;
   sources?: SearchResult[];
   results?: SearchResult[];
+  page?: SearchPage & { has_more: boolean };
+  facets?: SearchFacets;
+};
+
+// Reply from the live search WebSocket (/search/live)
+export type LiveSearchReply = {
+  type: "results" | "error";
+  seq: number | null;
+  query?: string;
+  took_ms?: number;
+  query_id?: string;
+  parsed_query?: ParsedQuery;
+  did_you_mean?: string;
+  results?: SearchResult[];
+  facets?: SearchFacets;
+  status?: number;
+  detail?: any;
+};
+
+// Live store metrics (GET /stores/{id}/metrics/stream, "metrics" events)
+export type StoreMetrics = {
+  store_id: string;
+  window_seconds: number;
+  searches: number;
+  qps: number;
+  avg_latency_ms: number | null;
+  total_searches: number | null;
+  index_counts: Record<string, number>;
+  recent_index_events: {
+    id: string;
+    topic: string;
+    timestamp: string;
+    path?: string;
+    chunks?: number | null;
+  }[];
+  at: string;
+};
+
+// Store access control (principals are "key:<user id>" or "connection:<id>")
+export type StoreAcl = {
+  owner: string;
+  readers: string[];
+  writers: string[];
+};
+
+export type StoreAclStatus = {
+  store: string;
+  acl: StoreAcl | null;
+  restricted: boolean;
+  access: { read: boolean; write: boolean; owner: boolean };
+};
+
+// Indexed content of a store, kept up to date by the indexer
+export type StoreStats = {
+  store: string;
+  files: number;
+  chunks: number;
+  bytes: number;
+  languages: { language: string; files: number; chunks: number; bytes: number }[];
+  // False until stores indexed before the counts existed are rebuilt
+  complete: boolean;
+  built_at: string | null;
+};
+
+// Deleted store kept in the trash until purge_after
+export type TrashedStore = {
+  id: string;
+  name: string;
+  type: string | null;
+  description: string | null;
+  deleted_at: string | null;
+  deleted_by: string | null;
+  purge_after: string | null;
+};
+
+// Near-duplicate chunks across a store's files
+export type DuplicateChunk = {
+  chunk_id: string;
+  path: string;
+  start_line: number | null;
+  end_line: number | null;
+  language: string | null;
+};
+
+export type DuplicateReport = {
+  store: string;
+  state: "queued" | "running" | "done" | "failed" | null;
+  task_id?: string;
+  finished_at?: string;
+  error?: string | null;
+  threshold?: number;
+  truncated?: boolean;
+  duplicate_chunks?: number;
+  total_clusters?: number;
+  total_file_pairs?: number;
+  clusters?: {
+    id: number;
+    size: number;
+    files: number;
+    max_similarity: number;
+    min_similarity: number;
+    chunks: DuplicateChunk[];
+  }[];
+  file_pairs?: {
+    file_a: string;
+    file_b: string;
+    chunks: number;
+    max_similarity: number;
+    mean_similarity: number;
+  }[];
+};
+
+// normal, read_only (searches only) or maintenance (nothing but health)
+export type ServerMode = {
+  mode: "normal" | "read_only" | "maintenance";
+  reason: string | null;
+  since: string | null;
+  set_by: string | null;
 };
 
+export type BusEvent = {
+  id: string;
+  event_id?: string;
+  replayed?: boolean;
+  topic: string;
+  timestamp: string;
+  payload: Record<string, any>;
+};
+
+// Short-lived cache for data almost every page asks for (health, stores).
+// Concurrent callers share one request; failures are not cached. Store
+// entries are dropped on local changes and on store.* bus events (see
+// watchCacheInvalidation).
+const HEALTH_TTL_MS = 10_000;
+const STORES_TTL_MS = 30_000;
+
+const cache = new Map<string, { expires: number; value: Promise<any> }>();
+
+function cached<T>(key: string, ttlMs: number, load: () => Promise<T>): Promise<T> {
+  const hit = cache.get(key);
+  if (hit && hit.expires > Date.now()) return hit.value;
+  const value = load();
+  cache.set(key, { expires: Date.now() + ttlMs, value });
+  value.catch(() => {
+    if (cache.get(key)?.value === value) cache.delete(key);
+  });
+  return value;
+}
+
+export function invalidateCache(prefix = "") {
+  for (const key of Array.from(cache.keys())) {
+    if (key.startsWith(prefix)) cache.delete(key);
+  }
+}
+
+// Drop cached stores when any client creates or deletes one. Returns a
+// function that closes the stream.
+export function watchCacheInvalidation(): () => void {
+  if (typeof EventSource === "undefined") return () => {};
+  const source = new EventSource(api.eventStreamUrl("store.*"));
+  source.onmessage = () => invalidateCache("stores:");
+  return () => source.close();
+}
+
 export const api = {
-  health: async () => {
-    try {
-      const res = await fetch("http://localhost:8000/health");
-      if (!res.ok) return false;
-      const data = await res.json();
-      return data.status === "healthy";
-    } catch (e) {
-      console.error(e);
-      return false;
-    }
-  },
+  health: async () =>
+    cached("health", HEALTH_TTL_MS, async () => {
+      try {
+        const res = await fetch("http://localhost:8000/health");
+        if (!res.ok) return false;
+        const data = await res.json();
+        return data.status === "healthy";
+      } catch (e) {
+        console.error(e);
+        return false;
+      }
+    }),
 
   ingest: async (file: File, token?: string) => {
     const formData = new FormData();
@@ -53,14 +293,31 @@ export const api = {
 
   search: async (
     query: string,
-    mode: "search" | "rag" = "search"
+    mode: "search" | "rag" = "search",
+    store?: string,
+    explain = false,
+    filters: SearchFilters = {},
+    page?: SearchPage
   ): Promise<SearchResponse> => {
     const res = await fetch(`${API_BASE}/search/query`, {
       method: "POST",
       headers: {
         "Content-Type": "application/json",
       },
-      body: JSON.stringify({ query, mode }),
+      body: JSON.stringify({
+        query,
+        mode,
+        store: store || undefined,
+        explain,
+        facets: mode === "search",
+        ...filters,
+        // Paged results carry previews; content is fetched per result
+        ...(page && {
+          offset: page.offset,
+          limit: page.limit,
+          include_content: false,
+        }),
+      }),
     });
 
     if (!res.ok) {
@@ -69,6 +326,61 @@ export const api = {
     return res.json();
   },
 
+  // Search results as a file (audited; watermarked when the server says so)
+  exportResults: async (
+    query: string,
+    format: "csv" | "json",
+    store?: string,
+    filters: SearchFilters = {},
+    limit = 100
+  ): Promise<{ blob: Blob; filename: string }> => {
+    const res = await fetch(`${API_BASE}/search/export`, {
+      method: "POST",
+      headers: {
+        "Content-Type": "application/json",
+      },
+      body: JSON.stringify({
+        query,
+        format,
+        store: store || undefined,
+        limit,
+        ...filters,
+      }),
+    });
+    if (!res.ok) {
+      throw new Error(
+        res.status === 429 ? "Export limit reached, try again later" : `Export failed: ${res.statusText}`
+      );
+    }
+    const disposition = res.headers.get("Content-Disposition") || "";
+    const filename = /filename="([^"]+)"/.exec(disposition)?.[1] || `search-results.${format}`;
+    return { blob: await res.blob(), filename };
+  },
+
+  getChunk: async (chunkId: string, store?: string): Promise<SearchResult> => {
+    const params = store ? `?store=${encodeURIComponent(store)}` : "";
+    const res = await fetch(
+      `${API_BASE}/search/chunks/${encodeURIComponent(chunkId)}${params}`
+    );
+    if (!res.ok) throw new Error("Failed to load result content");
+    return res.json();
+  },
+
+  sendFeedback: async (
+    queryId: string,
+    resultId: string,
+    action: "click" | "ignore"
+  ): Promise<void> => {
+    // Best effort: feedback only tunes ranking, never block the UI on it
+    await fetch(`${API_BASE}/search/feedback`, {
+      method: "POST",
+      headers: {
+        "Content-Type": "application/json",
+      },
+      body: JSON.stringify({ query_id: queryId, result_id: resultId, action }),
+    }).catch(() => undefined);
+  },
+
   listFiles: async (
     pattern?: string,
     orgId?: string
@@ -94,11 +406,22 @@ export const api = {
   },
 
   // Stores (Phase 16 P2)
-  listStores: async (): Promise<any[]> => {
-    const res = await fetch(`${API_BASE}/stores/`);
-    if (!res.ok) throw new Error("Failed to list stores");
-    return res.json();
-  },
+  // Sorted by search volume (most used first) unless another order is requested
+  listStores: async (
+    sort: "usage" | "name" | "created" = "usage"
+  ): Promise<any[]> =>
+    cached(`stores:list:${sort}`, STORES_TTL_MS, async () => {
+      const res = await fetch(`${API_BASE}/stores/?sort=${sort}`);
+      if (!res.ok) throw new Error("Failed to list stores");
+      return res.json();
+    }),
+
+  topStores: async (limit = 5): Promise<{ stores: any[] }> =>
+    cached(`stores:top:${limit}`, STORES_TTL_MS, async () => {
+      const res = await fetch(`${API_BASE}/stores/usage/top?limit=${limit}`);
+      if (!res.ok) throw new Error("Failed to load store usage");
+      return res.json();
+    }),
 
   createStore: async (data: any): Promise<any> => {
     const res = await fetch(`${API_BASE}/stores/`, {
@@ -107,12 +430,108 @@ export const api = {
       body: JSON.stringify(data),
     });
     if (!res.ok) throw new Error("Failed to create store");
+    invalidateCache("stores:");
+    return res.json();
+  },
+
+  getStore: async (id: string): Promise<any> =>
+    cached(`stores:get:${id}`, STORES_TTL_MS, async () => {
+      const res = await fetch(`${API_BASE}/stores/${id}`);
+      if (!res.ok) throw new Error("Failed to get store");
+      return res.json();
+    }),
+
+  // Known function/class names for symbol: filter suggestions
+  listSymbols: async (
+    storeId: string,
+    prefix: string,
+    limit = 10
+  ): Promise<{ symbols: { symbol: string; chunks: number }[] }> => {
+    const url = new URL(`${API_BASE}/stores/${storeId}/symbols`);
+    url.searchParams.append("prefix", prefix);
+    url.searchParams.append("limit", String(limit));
+
+    const res = await fetch(url.toString());
+    if (!res.ok) throw new Error("Failed to list symbols");
+    return res.json();
+  },
+
+  // Completions for the last typed word and a spelling fix for the query
+  suggest: async (storeId: string, query: string, limit = 8): Promise<QuerySuggestions> => {
+    const url = new URL(`${API_BASE}/stores/${storeId}/suggest`);
+    url.searchParams.append("q", query);
+    url.searchParams.append("limit", String(limit));
+
+    const res = await fetch(url.toString());
+    if (!res.ok) throw new Error("Failed to load suggestions");
+    return res.json();
+  },
+
+  // Store ACL (owner/readers/writers) and what the caller may do
+  getStoreAcl: async (id: string): Promise<StoreAclStatus> => {
+    const res = await fetch(`${API_BASE}/stores/${id}/acl`);
+    if (!res.ok) throw new Error("Failed to get store access");
+    return res.json();
+  },
+
+  setStoreAcl: async (id: string, acl: Partial<StoreAcl>): Promise<StoreAclStatus> => {
+    const res = await fetch(`${API_BASE}/stores/${id}/acl`, {
+      method: "PUT",
+      headers: { "Content-Type": "application/json" },
+      body: JSON.stringify(acl),
+    });
+    const data = await res.json();
+    if (!res.ok) throw new Error(data.detail || "Failed to update store access");
+    invalidateCache("stores:");
+    return data;
+  },
+
+  deleteStoreAcl: async (id: string): Promise<StoreAclStatus> => {
+    const res = await fetch(`${API_BASE}/stores/${id}/acl`, { method: "DELETE" });
+    const data = await res.json();
+    if (!res.ok) throw new Error(data.detail || "Failed to open store");
+    invalidateCache("stores:");
+    return data;
+  },
+
+  // Embedding model mismatch and migration state
+  getStoreMigration: async (id: string): Promise<any> => {
+    const res = await fetch(`${API_BASE}/stores/${id}/migration`);
+    if (!res.ok) throw new Error("Failed to get migration status");
     return res.json();
   },
 
-  getStore: async (id: string): Promise<any> => {
-    const res = await fetch(`${API_BASE}/stores/${id}`);
-    if (!res.ok) throw new Error("Failed to get store");
+  migrateStore: async (id: string): Promise<{ task_id: string; total: number }> => {
+    const res = await fetch(`${API_BASE}/stores/${id}/migrate`, { method: "POST" });
+    const data = await res.json();
+    if (!res.ok) throw new Error(data.detail || "Failed to start migration");
+    invalidateCache(`stores:get:${id}`);
+    return data;
+  },
+
+  getDuplicateReport: async (id: string): Promise<DuplicateReport> => {
+    const res = await fetch(`${API_BASE}/stores/${id}/duplicates`);
+    if (!res.ok) throw new Error("Failed to get duplicate report");
+    return res.json();
+  },
+
+  buildDuplicateReport: async (id: string): Promise<{ task_id: string }> => {
+    const res = await fetch(`${API_BASE}/stores/${id}/duplicates`, { method: "POST" });
+    const data = await res.json();
+    if (!res.ok) throw new Error(data.detail || "Failed to start duplicate scan");
+    return data;
+  },
+
+  // Answers in every mode, so the banner keeps working during maintenance
+  getServerMode: async (): Promise<ServerMode> => {
+    const res = await fetch(`${API_BASE}/admin/public/server/mode`);
+    if (!res.ok) throw new Error("Failed to get server mode");
+    return res.json();
+  },
+
+  getJob: async (taskId: string): Promise<any> => {
+    const res = await fetch(`${API_BASE}/admin/public/jobs/${taskId}`);
+    if (!res.ok) throw new Error("Failed to get job");
     return res.json();
   },
 
@@ -121,9 +540,31 @@ export const api = {
       method: "DELETE",
     });
     if (!res.ok) throw new Error("Failed to delete store");
+    invalidateCache("stores:");
     return res.json();
   },
 
+  listTrash: async (): Promise<{ stores: TrashedStore[]; retention_days: number }> => {
+    const res = await fetch(`${API_BASE}/stores/trash`);
+    if (!res.ok) throw new Error("Failed to list deleted stores");
+    return res.json();
+  },
+
+  restoreStore: async (id: string): Promise<any> => {
+    const res = await fetch(`${API_BASE}/stores/trash/${id}/restore`, { method: "POST" });
+    const data = await res.json();
+    if (!res.ok) throw new Error(data.detail || "Failed to restore store");
+    invalidateCache("stores:");
+    return data;
+  },
+
+  purgeStore: async (id: string): Promise<any> => {
+    const res = await fetch(`${API_BASE}/stores/trash/${id}`, { method: "DELETE" });
+    const data = await res.json();
+    if (!res.ok) throw new Error(data.detail || "Failed to purge store");
+    return data;
+  },
+
   // Connections (Phase 16 P2)
   listConnections: async (): Promise<{ connections: any[] }> => {
     const res = await fetch(`${API_BASE}/admin/public/connections`);
@@ -131,6 +572,25 @@ export const api = {
     return res.json();
   },
 
+  backfillConnections: async (dryRun = false): Promise<any> => {
+    const res = await fetch(
+      `${API_BASE}/admin/public/connections/backfill?dry_run=${dryRun}`,
+      { method: "POST" },
+    );
+    if (!res.ok) throw new Error("Failed to start connection backfill");
+    return res.json();
+  },
+
+  deleteConnectionChunks: async (id: string, orgId?: string): Promise<any> => {
+    const query = orgId ? `?org_id=${encodeURIComponent(orgId)}` : "";
+    const res = await fetch(
+      `${API_BASE}/admin/public/connections/${id}/chunks${query}`,
+      { method: "DELETE" },
+    );
+    if (!res.ok) throw new Error("Failed to delete connection chunks");
+    return res.json();
+  },
+
   deleteConnection: async (id: string): Promise<any> => {
     const res = await fetch(`${API_BASE}/admin/public/connections/${id}`, {
       method: "DELETE",
@@ -138,4 +598,62 @@ export const api = {
     if (!res.ok) throw new Error("Failed to delete connection");
     return res.json();
   },
+
+  // Alerts
+  listAlerts: async (limit = 50): Promise<{ alerts: any[] }> => {
+    const res = await fetch(`${API_BASE}/admin/public/alerts?limit=${limit}`);
+    if (!res.ok) throw new Error("Failed to list alerts");
+    return res.json();
+  },
+
+  listAlertSinks: async (): Promise<{ sinks: any[] }> => {
+    const res = await fetch(`${API_BASE}/admin/public/alerts/sinks`);
+    if (!res.ok) throw new Error("Failed to list alert sinks");
+    return res.json();
+  },
+
+  listAlertDeliveries: async (limit = 100): Promise<{ deliveries: any[] }> => {
+    const res = await fetch(
+      `${API_BASE}/admin/public/alerts/deliveries?limit=${limit}`,
+    );
+    if (!res.ok) throw new Error("Failed to list alert deliveries");
+    return res.json();
+  },
+
+  sendTestAlert: async (severity = "warning"): Promise<any> => {
+    const res = await fetch(`${API_BASE}/admin/public/alerts/test`, {
+      method: "POST",
+      headers: { "Content-Type": "application/json" },
+      body: JSON.stringify({ severity }),
+    });
+    if (!res.ok) throw new Error("Failed to send test alert");
+    return res.json();
+  },
+
+  // Events
+  listEvents: async (topics = "", limit = 200): Promise<{ events: BusEvent[] }> => {
+    const params = new URLSearchParams({ limit: String(limit) });
+    if (topics) params.set("topics", topics);
+    const res = await fetch(`${API_BASE}/events?${params}`);
+    if (!res.ok) throw new Error("Failed to list events");
+    return res.json();
+  },
+
+  listEventTopics: async (): Promise<{ topics: string[] }> => {
+    const res = await fetch(`${API_BASE}/events/topics`);
+    if (!res.ok) throw new Error("Failed to list event topics");
+    return res.json();
+  },
+
+  // Search as you type; send {seq, query, ...filters} per keystroke
+  liveSearchUrl: (): string => `${API_BASE.replace(/^http/, "ws")}/search/live`,
+
+  storeMetricsUrl: (id: string): string => `${API_BASE}/stores/${id}/metrics/stream`,
+
+  eventStreamUrl: (topics = "", since?: string): string => {
+    const params = new URLSearchParams();
+    if (topics) params.set("topics", topics);
+    if (since) params.set("since", since);
+    return `${API_BASE}/events/stream?${params}`;
+  },
 };